@@ -131,7 +131,7 @@ func TestFailuresNodeJsFn(t *testing.T) {
 			Name:      "function fail without valid GOOGLE_FUNCTION_SOURCE",
 			App:       "no_package",
 			Env:       []string{"GOOGLE_FUNCTION_TARGET=testFunction", "GOOGLE_FUNCTION_SOURCE=sub_dir"},
-			MustMatch: "GOOGLE_FUNCTION_SOURCE is not currently supported for Node.js buildpacks",
+			MustMatch: "GOOGLE_FUNCTION_SOURCE must be a directory of the application for Node.js buildpacks",
 		},
 	}
 
@@ -51,6 +51,21 @@ func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
 		return nil, err
 	}
 	if procExists {
+		b, err := ctx.ReadFile("Procfile")
+		if err != nil {
+			return nil, err
+		}
+		// PHP apps may only declare worker processes in the Procfile, the web process is provided by
+		// nginx and php-fpm. Other apps fail the build without a web process.
+		if !hasWebProcess(string(b)) {
+			php, err := isPHPApp(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if php {
+				return gcp.OptOut("Procfile of the PHP app does not declare a web process"), nil
+			}
+		}
 		return gcp.OptInFileFound("Procfile"), nil
 	}
 	if entrypoint, _ := appyaml.EntrypointIfExists(ctx.ApplicationRoot()); entrypoint != "" {
@@ -107,6 +122,25 @@ func buildFn(ctx *gcp.Context) error {
 		"%s not set, no valid entrypoint config in Procfile or app.yaml.", env.Entrypoint))
 }
 
+// hasWebProcess returns true if the given Procfile contents declare a web process.
+func hasWebProcess(content string) bool {
+	for _, match := range processRe.FindAllStringSubmatch(content, -1) {
+		if match[1] == gcp.WebProcess {
+			return true
+		}
+	}
+	return false
+}
+
+// isPHPApp returns true if the application is detected by the PHP runtime buildpack.
+func isPHPApp(ctx *gcp.Context) (bool, error) {
+	composerJSONExists, err := ctx.FileExists("composer.json")
+	if err != nil || composerJSONExists {
+		return composerJSONExists, err
+	}
+	return ctx.HasAtLeastOneOutsideDependencyDirectories("*.php")
+}
+
 // addProcfileProcesses adds all processes from the given Procfile contents.
 func addProcfileProcesses(ctx *gcp.Context, content string) error {
 	matches := processRe.FindAllStringSubmatch(content, -1)
@@ -42,6 +42,39 @@ func TestDetect(t *testing.T) {
 			},
 			want: 0,
 		},
+		{
+			name: "with Procfile without web process",
+			files: map[string]string{
+				"Procfile": "worker: python worker.py",
+				"main.py":  "",
+			},
+			want: 0,
+		},
+		{
+			name: "with Procfile without web process in PHP app",
+			files: map[string]string{
+				"Procfile":  "worker: php artisan queue:work",
+				"index.php": "",
+			},
+			want: 100,
+		},
+		{
+			name: "with Procfile without web process in PHP app with composer.json",
+			files: map[string]string{
+				"Procfile":      "worker: php artisan queue:work",
+				"composer.json": "{}",
+			},
+			want: 100,
+		},
+		{
+			name: "with Procfile without web process in PHP app and GOOGLE_ENTRYPOINT",
+			env:  []string{"GOOGLE_ENTRYPOINT=my entrypoint"},
+			files: map[string]string{
+				"Procfile":  "worker: php artisan queue:work",
+				"index.php": "",
+			},
+			want: 0,
+		},
 		{
 			name: "with app.yaml",
 			env:  []string{"GAE_APPLICATION_YAML_PATH=app.yaml"},
@@ -79,7 +79,7 @@ type fnInfo struct {
 }
 
 func main() {
-	gcp.Main(detectFn, buildFn)
+	gcp.Main(detectFn, buildFn, gcp.WithFunctionSourceDir())
 }
 
 func hasCppCode(ctx *gcp.Context) (bool, error) {
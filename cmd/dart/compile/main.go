@@ -20,12 +20,22 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/dart"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
 )
 
+const (
+	// defaultBuildable is the script that provides the web process by default.
+	defaultBuildable = "bin/server.dart"
+	// webExecutable is the name of the executable that provides the web process.
+	webExecutable = "server"
+)
+
 func main() {
 	gcp.Main(detectFn, buildFn)
 }
@@ -42,47 +52,169 @@ func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
 }
 
 func buildFn(ctx *gcp.Context) error {
-	br, err := dart.HasBuildRunner(ctx.ApplicationRoot())
+	buildables, err := dartBuildables(ctx)
 	if err != nil {
-		return err
+		return fmt.Errorf("unable to find a valid buildable: %w", err)
 	}
-	if br {
-		// Run build runner.
-		if _, err := ctx.Exec([]string{"dart", "run", "build_runner", "build", "--delete-conflicting-outputs"}, gcp.WithUserAttribution); err != nil {
-			return err
-		}
+	if err := generateCode(ctx, buildables); err != nil {
+		return err
 	}
-	// Create a layer for the compiled binary.  Add it to PATH in case
-	// users wish to invoke the binary manually.
+
+	// Create a layer for the compiled binaries.  Add it to PATH in case
+	// users wish to invoke the binaries manually.
 	bl, err := ctx.Layer("bin", gcp.LaunchLayer)
 	if err != nil {
 		return fmt.Errorf("creating layer: %w", err)
 	}
 	bl.LaunchEnvironment.Prepend("PATH", string(os.PathListSeparator), bl.Path)
-	outBin := filepath.Join(bl.Path, "server")
 
-	buildable, err := dartBuildable(ctx)
+	exes, err := executables(buildables, bl.Path)
 	if err != nil {
-		return fmt.Errorf("unable to find a valid buildable: %w", err)
+		return err
 	}
 
 	// Build the application.
-	bld := []string{"dart", "compile", "exe", buildable, "-o", outBin}
-	if _, err := ctx.Exec(bld, gcp.WithUserAttribution); err != nil {
+	var flags []string
+	if args := os.Getenv(env.BuildArgs); args != "" {
+		flags = strings.Fields(args)
+	}
+	for _, e := range exes {
+		bld := append(append([]string{"dart", "compile", "exe"}, flags...), e.source, "-o", e.path)
+		if _, err := ctx.Exec(bld, gcp.WithUserAttribution); err != nil {
+			return err
+		}
+	}
+
+	// Additional executables, e.g. workers, are added as processes named after the executable.
+	for _, e := range exes[1:] {
+		ctx.AddProcess(e.name, []string{e.path}, gcp.AsDirectProcess())
+	}
+	ctx.AddWebProcess([]string{"/bin/bash", "-c", exes[0].path})
+	return nil
+}
+
+// generateCode runs build_runner if the application depends on it and the generated code is
+// missing, i.e. the files of part directives or the scripts to compile, which build_runner can
+// generate, e.g. bin/server.dart for the Dart Functions Framework. Generated code that is
+// committed with the application is used as is, unless GOOGLE_DART_BUILD_RUNNER is true.
+func generateCode(ctx *gcp.Context, buildables []buildable) error {
+	br, err := dart.HasBuildRunner(ctx.ApplicationRoot())
+	if err != nil || !br {
+		return err
+	}
+	if val, ok := os.LookupEnv(env.DartBuildRunner); ok {
+		run, err := strconv.ParseBool(val)
+		if err != nil {
+			return gcp.UserErrorf("invalid value %q for %s, must be true or false", val, env.DartBuildRunner)
+		}
+		if !run {
+			ctx.Logf("Skipping build_runner, %s is false.", env.DartBuildRunner)
+			return nil
+		}
+		return runBuildRunner(ctx)
+	}
+	missing, err := dart.MissingParts(ctx.ApplicationRoot())
+	if err != nil {
 		return err
 	}
+	for _, b := range buildables {
+		exists, err := ctx.FileExists(filepath.Join(ctx.ApplicationRoot(), b.source))
+		if err != nil {
+			return err
+		}
+		if !exists {
+			missing = append(missing, b.source)
+		}
+	}
+	if len(missing) == 0 {
+		ctx.Logf("Skipping build_runner, the generated code is part of the application.")
+		return nil
+	}
+	ctx.Logf("Running build_runner to generate %s.", strings.Join(missing, ", "))
+	return runBuildRunner(ctx)
+}
 
-	ctx.AddWebProcess([]string{"/bin/bash", "-c", outBin})
+// runBuildRunner generates the code of the application with build_runner.
+func runBuildRunner(ctx *gcp.Context) error {
+	if _, err := ctx.Exec([]string{"dart", "run", "build_runner", "build", "--delete-conflicting-outputs"}, gcp.WithUserAttribution); err != nil {
+		return err
+	}
 	return nil
 }
 
-func dartBuildable(ctx *gcp.Context) (string, error) {
+// executable is a Dart script compiled into a self-contained executable.
+type executable struct {
+	source string
+	name   string
+	path   string
+}
+
+// processNameRegexp matches the process types allowed by the buildpacks spec.
+var processNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// executables returns the executables compiled from the buildables in binDir. The first one is the
+// web process, named server, and the others are named after their script or pubspec.yaml entry.
+func executables(buildables []buildable, binDir string) ([]executable, error) {
+	exes := []executable{{source: buildables[0].source, name: webExecutable, path: filepath.Join(binDir, webExecutable)}}
+	seen := map[string]string{webExecutable: buildables[0].source, gcp.WebProcess: buildables[0].source}
+	for _, b := range buildables[1:] {
+		name := b.name
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(b.source), ".dart")
+		}
+		if !processNameRegexp.MatchString(name) {
+			return nil, gcp.UserErrorf("cannot name the executable of %q: the name must only contain letters, digits, dashes and underscores", b.source)
+		}
+		if other, ok := seen[name]; ok {
+			return nil, gcp.UserErrorf("%q and %q would both compile an executable named %q, the names must be unique", other, b.source, name)
+		}
+		seen[name] = b.source
+		exes = append(exes, executable{source: b.source, name: name, path: filepath.Join(binDir, name)})
+	}
+	return exes, nil
+}
+
+// buildable is a Dart script to compile, with the name of its executable if it is declared in
+// pubspec.yaml.
+type buildable struct {
+	source string
+	name   string
+}
 
+// dartBuildables returns the Dart scripts to compile. The first one provides the web process.
+// GOOGLE_BUILDABLE is a comma-separated list of scripts, which defaults to bin/server.dart and
+// the other executables declared in pubspec.yaml.
+func dartBuildables(ctx *gcp.Context) ([]buildable, error) {
 	// The user tells us what to build.
-	if buildable, ok := os.LookupEnv(env.Buildable); ok {
-		return buildable, nil
+	if val, ok := os.LookupEnv(env.Buildable); ok {
+		var buildables []buildable
+		for _, b := range strings.Split(val, ",") {
+			if b = strings.TrimSpace(b); b != "" {
+				buildables = append(buildables, buildable{source: b})
+			}
+		}
+		if len(buildables) > 0 {
+			return buildables, nil
+		}
 	}
 
-	// Default to bin/server.dart in the application root.
-	return "bin/server.dart", nil
+	// Default to bin/server.dart in the application root, or the script of the server executable.
+	exes, err := dart.Executables(ctx.ApplicationRoot())
+	if err != nil {
+		return nil, err
+	}
+	web := defaultBuildable
+	for _, e := range exes {
+		if e.Name == webExecutable {
+			web = e.Script
+		}
+	}
+	buildables := []buildable{{source: web}}
+	for _, e := range exes {
+		if e.Name == webExecutable || e.Script == web {
+			continue
+		}
+		buildables = append(buildables, buildable{source: e.Script, name: e.Name})
+	}
+	return buildables, nil
 }
@@ -15,9 +15,15 @@
 package main
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/GoogleCloudPlatform/buildpacks/internal/buildpacktest"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestDetect(t *testing.T) {
@@ -45,3 +51,160 @@ func TestDetect(t *testing.T) {
 		})
 	}
 }
+
+func TestDartBuildables(t *testing.T) {
+	testCases := []struct {
+		name      string
+		pubspec   string
+		buildable string
+		want      []buildable
+	}{
+		{
+			name: "default",
+			want: []buildable{{source: "bin/server.dart"}},
+		},
+		{
+			name:      "GOOGLE_BUILDABLE",
+			buildable: "bin/api.dart, bin/worker.dart",
+			pubspec:   "name: app\nexecutables:\n  migrate:\n",
+			want:      []buildable{{source: "bin/api.dart"}, {source: "bin/worker.dart"}},
+		},
+		{
+			name:    "pubspec executables",
+			pubspec: "name: app\nexecutables:\n  worker: queue_worker\n  server:\n",
+			want:    []buildable{{source: "bin/server.dart"}, {source: "bin/queue_worker.dart", name: "worker"}},
+		},
+		{
+			name:    "pubspec server executable",
+			pubspec: "name: app\nexecutables:\n  server: main\n  migrate:\n",
+			want:    []buildable{{source: "bin/main.dart"}, {source: "bin/migrate.dart", name: "migrate"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			if tc.pubspec != "" {
+				if err := ioutil.WriteFile(filepath.Join(root, "pubspec.yaml"), []byte(tc.pubspec), 0644); err != nil {
+					t.Fatalf("writing pubspec.yaml: %v", err)
+				}
+			}
+			if tc.buildable != "" {
+				t.Setenv(env.Buildable, tc.buildable)
+			}
+
+			got, err := dartBuildables(gcp.NewContext(gcp.WithApplicationRoot(root)))
+			if err != nil {
+				t.Fatalf("dartBuildables() got error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(buildable{})); diff != "" {
+				t.Errorf("dartBuildables() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestExecutables(t *testing.T) {
+	testCases := []struct {
+		name       string
+		buildables []buildable
+		want       []executable
+		wantErr    bool
+	}{
+		{
+			name:       "single",
+			buildables: []buildable{{source: "bin/api.dart"}},
+			want:       []executable{{source: "bin/api.dart", name: "server", path: "/bin/server"}},
+		},
+		{
+			name:       "multiple",
+			buildables: []buildable{{source: "bin/server.dart"}, {source: "bin/worker.dart"}, {source: "bin/queue.dart", name: "jobs"}},
+			want: []executable{
+				{source: "bin/server.dart", name: "server", path: "/bin/server"},
+				{source: "bin/worker.dart", name: "worker", path: "/bin/worker"},
+				{source: "bin/queue.dart", name: "jobs", path: "/bin/jobs"},
+			},
+		},
+		{
+			name:       "duplicate name",
+			buildables: []buildable{{source: "bin/server.dart"}, {source: "tool/worker.dart"}, {source: "bin/worker.dart"}},
+			wantErr:    true,
+		},
+		{
+			name:       "web process name",
+			buildables: []buildable{{source: "bin/api.dart"}, {source: "bin/web.dart"}},
+			wantErr:    true,
+		},
+		{
+			name:       "invalid name",
+			buildables: []buildable{{source: "bin/server.dart"}, {source: "bin/my.worker.dart"}},
+			wantErr:    true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := executables(tc.buildables, "/bin")
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("executables() got error: %v, want error: %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(executable{})); diff != "" {
+				t.Errorf("executables() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGenerateCodeSkipped(t *testing.T) {
+	const pubspec = "name: app\ndev_dependencies:\n  build_runner: ^2.4.0\n"
+	testCases := []struct {
+		name        string
+		files       map[string]string
+		buildRunner string
+		wantErr     bool
+	}{
+		{
+			name:  "no build_runner",
+			files: map[string]string{"pubspec.yaml": "name: app\n", "bin/server.dart": "part 'server.g.dart';"},
+		},
+		{
+			name: "generated code committed",
+			files: map[string]string{
+				"pubspec.yaml":      pubspec,
+				"bin/server.dart":   "part 'server.g.dart';",
+				"bin/server.g.dart": "part of 'server.dart';",
+			},
+		},
+		{
+			name:        "disabled",
+			files:       map[string]string{"pubspec.yaml": pubspec},
+			buildRunner: "false",
+		},
+		{
+			name:        "invalid value",
+			files:       map[string]string{"pubspec.yaml": pubspec},
+			buildRunner: "sometimes",
+			wantErr:     true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			for name, content := range tc.files {
+				path := filepath.Join(root, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("creating directory of %s: %v", path, err)
+				}
+				if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatalf("writing %s: %v", path, err)
+				}
+			}
+			if tc.buildRunner != "" {
+				t.Setenv(env.DartBuildRunner, tc.buildRunner)
+			}
+
+			err := generateCode(gcp.NewContext(gcp.WithApplicationRoot(root)), []buildable{{source: "bin/server.dart"}})
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("generateCode() got error: %v, want error: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
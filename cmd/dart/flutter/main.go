@@ -0,0 +1,157 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Implements dart/flutter buildpack.
+// The flutter buildpack installs the Flutter SDK, builds Flutter web applications and serves them
+// with nginx.
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/dart"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/nginx"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/runtime"
+)
+
+const (
+	flutterLayer   = "flutter"
+	webConfigLayer = "webconfig"
+
+	// webDir is the output directory of `flutter build web`.
+	webDir = "build/web"
+	// nginxAppConf is the nginx server config of the application that is included in the generated
+	// config, e.g. to set custom headers.
+	nginxAppConf = "nginx-app.conf"
+	// nginxConf is the config template in the webconfig layer, in which the port is replaced at
+	// launch time.
+	nginxConf = "nginx.conf"
+	// portPlaceholder is replaced by the port in the config template at launch time.
+	portPlaceholder = "__PORT__"
+	// defaultNginxRoot is the nginx layer of the utils/nginx buildpack, if NGINX_ROOT is not set.
+	defaultNginxRoot = "/layers/google.utils.nginx/nginx"
+	// launchTempDir is the directory of the generated config and temporary files of nginx at
+	// launch, as the layers are read-only.
+	launchTempDir = "/tmp/nginx"
+)
+
+func main() {
+	gcp.Main(detectFn, buildFn)
+}
+
+func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
+	flutter, err := dart.IsFlutter(ctx.ApplicationRoot())
+	if err != nil {
+		return nil, err
+	}
+	if !flutter {
+		return gcp.OptOut("no Flutter SDK dependency found in pubspec.yaml"), nil
+	}
+	return gcp.OptIn("found Flutter SDK dependency in pubspec.yaml"), nil
+}
+
+func buildFn(ctx *gcp.Context) error {
+	// The Flutter SDK is only required at build time. It is not included in the run image.
+	fl, err := ctx.Layer(flutterLayer, gcp.BuildLayer, gcp.CacheLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", flutterLayer, err)
+	}
+	version, cached, err := runtime.InstallFlutterSDKIfNotCached(ctx, fl, os.Getenv(env.FlutterVersion))
+	if err != nil {
+		return err
+	}
+	if cached {
+		ctx.CacheHit(flutterLayer)
+	} else {
+		ctx.CacheMiss(flutterLayer)
+	}
+	ctx.Logf("Using Flutter SDK version %s", version)
+	fl.BuildEnvironment.Prepend("PATH", string(os.PathListSeparator), filepath.Join(fl.Path, "bin"))
+
+	build := []string{filepath.Join(fl.Path, "bin", "flutter"), "build", "web", "--release"}
+	if args := os.Getenv(env.BuildArgs); args != "" {
+		build = append(build, strings.Fields(args)...)
+	}
+	if _, err := ctx.Exec(build, gcp.WithEnv("FLUTTER_SUPPRESS_ANALYTICS=true", "CI=true"), gcp.WithUserAttribution); err != nil {
+		return err
+	}
+
+	wl, err := ctx.Layer(webConfigLayer, gcp.LaunchLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", webConfigLayer, err)
+	}
+	conf, err := nginxConfig(ctx)
+	if err != nil {
+		return err
+	}
+	confPath := filepath.Join(wl.Path, nginxConf)
+	f, err := os.Create(confPath)
+	if err != nil {
+		return gcp.InternalErrorf("creating %s: %v", confPath, err)
+	}
+	defer f.Close()
+	if err := nginx.StaticTemplate.Execute(f, conf); err != nil {
+		return gcp.InternalErrorf("writing %s: %v", confPath, err)
+	}
+
+	// The web process can be replaced with GOOGLE_ENTRYPOINT or a Procfile, e.g. to use another
+	// static file server.
+	if _, ok := os.LookupEnv(env.Entrypoint); ok {
+		return nil
+	}
+	procfile, err := ctx.FileExists("Procfile")
+	if err != nil {
+		return err
+	}
+	if procfile {
+		return nil
+	}
+	ctx.AddProcess(gcp.WebProcess, []string{"/bin/bash", "-c", serveCommand(confPath)}, gcp.AsDefaultProcess())
+	return nil
+}
+
+// nginxConfig returns the config that serves the output of `flutter build web`.
+func nginxConfig(ctx *gcp.Context) (nginx.StaticConfig, error) {
+	nginxRoot := os.Getenv("NGINX_ROOT")
+	if nginxRoot == "" {
+		nginxRoot = defaultNginxRoot
+	}
+	conf := nginx.StaticConfig{
+		Port:          portPlaceholder,
+		Root:          filepath.Join(ctx.ApplicationRoot(), webDir),
+		MimeTypesPath: filepath.Join(nginxRoot, "conf", "mime.types"),
+		TempDir:       launchTempDir,
+	}
+	appConf, err := ctx.FileExists(nginxAppConf)
+	if err != nil {
+		return nginx.StaticConfig{}, err
+	}
+	if appConf {
+		ctx.Logf("Including %s in the web server configuration.", nginxAppConf)
+		conf.AppConfig = filepath.Join(ctx.ApplicationRoot(), nginxAppConf)
+	}
+	return conf, nil
+}
+
+// serveCommand returns the shell command that runs nginx with the config template, listening on
+// PORT.
+func serveCommand(confPath string) string {
+	launchConf := filepath.Join(launchTempDir, nginxConf)
+	return fmt.Sprintf(`mkdir -p %[1]s && sed "s/%[2]s/${PORT:-8080}/g" %[3]s > %[4]s && exec nginx -c %[4]s`, launchTempDir, portPlaceholder, confPath, launchConf)
+}
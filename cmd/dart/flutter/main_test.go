@@ -0,0 +1,63 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/buildpacks/internal/buildpacktest"
+)
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		want  int
+	}{
+		{
+			name: "flutter application",
+			files: map[string]string{
+				"pubspec.yaml":  "name: app\ndependencies:\n  flutter:\n    sdk: flutter\n",
+				"lib/main.dart": "",
+			},
+			want: 0,
+		},
+		{
+			name: "dart server",
+			files: map[string]string{
+				"pubspec.yaml":    "name: server\ndependencies:\n  shelf: ^1.4.0\n",
+				"bin/server.dart": "",
+			},
+			want: 100,
+		},
+		{
+			name:  "no files",
+			files: map[string]string{},
+			want:  100,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buildpacktest.TestDetect(t, detectFn, tc.name, tc.files, []string{}, tc.want)
+		})
+	}
+}
+
+func TestServeCommand(t *testing.T) {
+	want := `mkdir -p /tmp/nginx && sed "s/__PORT__/${PORT:-8080}/g" /layers/google.dart.flutter/webconfig/nginx.conf > /tmp/nginx/nginx.conf && exec nginx -c /tmp/nginx/nginx.conf`
+	if got := serveCommand("/layers/google.dart.flutter/webconfig/nginx.conf"); got != want {
+		t.Errorf("serveCommand() = %q, want %q", got, want)
+	}
+}
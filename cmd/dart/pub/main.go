@@ -19,15 +19,25 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/cache"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
 )
 
 const (
-	pubLayer    = "pub"
-	pubCacheEnv = "PUB_CACHE"
+	pubLayer          = "pub"
+	pubCacheEnv       = "PUB_CACHE"
+	dependencyHashKey = "dependency_hash"
+	// vendoredCache is the directory of the application with packages that are copied into the pub
+	// cache, e.g. for offline builds.
+	vendoredCache = ".pub-cache"
 )
 
+// lockInputs are the files that determine the resolved dependencies.
+var lockInputs = []string{"pubspec.yaml", "pubspec.lock"}
+
 func main() {
 	gcp.Main(detectFn, buildFn)
 }
@@ -44,6 +54,10 @@ func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
 }
 
 func buildFn(ctx *gcp.Context) error {
+	offline, err := env.IsPresentAndTrue(env.DartPubOffline)
+	if err != nil {
+		return gcp.UserErrorf("%v", err)
+	}
 	ml, err := ctx.Layer(pubLayer, gcp.BuildLayer, gcp.CacheLayer)
 	if err != nil {
 		return fmt.Errorf("creating %v layer: %w", pubLayer, err)
@@ -52,8 +66,81 @@ func buildFn(ctx *gcp.Context) error {
 	if err := os.Setenv(pubCacheEnv, ml.Path); err != nil {
 		return fmt.Errorf("setting env %s=%s: %w", pubCacheEnv, pubLayer, err)
 	}
+
+	// The pub cache is kept across builds, so that only changed dependencies are downloaded. If the
+	// locked dependencies did not change, they are all in the cache.
+	locked, err := lockedDependenciesCached(ctx, ctx.GetMetadata(ml, dependencyHashKey))
+	if err != nil {
+		return err
+	}
+	if locked.cached {
+		ctx.CacheHit(pubLayer)
+	} else {
+		ctx.CacheMiss(pubLayer)
+		ctx.SetMetadata(ml, dependencyHashKey, locked.hash)
+	}
+
+	if err := copyVendoredPackages(ctx, ml.Path); err != nil {
+		return err
+	}
+
+	if offline {
+		ctx.Logf("%s is set, resolving dependencies from the pub cache only.", env.DartPubOffline)
+		_, err := ctx.Exec([]string{"dart", "pub", "get", "--offline"}, gcp.WithUserAttribution)
+		return err
+	}
+	if locked.cached {
+		if _, err := ctx.Exec([]string{"dart", "pub", "get", "--offline"}, gcp.WithUserAttribution); err == nil {
+			return nil
+		}
+		ctx.Warnf("Resolving dependencies from the pub cache failed, downloading them.")
+	}
 	if _, err := ctx.Exec([]string{"dart", "pub", "get"}, gcp.WithUserAttribution); err != nil {
 		return err
 	}
 	return nil
 }
+
+// lockState is the hash of the lock inputs and whether the locked dependencies are cached.
+type lockState struct {
+	hash   string
+	cached bool
+}
+
+// lockedDependenciesCached returns the hash of the lock inputs and whether it matches the hash of
+// the previous build. Dependencies are only considered cached if they are locked by pubspec.lock.
+func lockedDependenciesCached(ctx *gcp.Context, prevHash string) (lockState, error) {
+	var names, files []string
+	for _, f := range lockInputs {
+		path := filepath.Join(ctx.ApplicationRoot(), f)
+		exists, err := ctx.FileExists(path)
+		if err != nil {
+			return lockState{}, err
+		}
+		if exists {
+			names = append(names, f)
+			files = append(files, path)
+		}
+	}
+	hash, err := cache.Hash(ctx, cache.WithStrings(names...), cache.WithFiles(files...))
+	if err != nil {
+		return lockState{}, fmt.Errorf("computing dependency hash: %w", err)
+	}
+	hasLock := len(files) == len(lockInputs)
+	return lockState{hash: hash, cached: hasLock && hash == prevHash}, nil
+}
+
+// copyVendoredPackages copies the packages vendored in the .pub-cache directory of the application
+// into the pub cache.
+func copyVendoredPackages(ctx *gcp.Context, cacheDir string) error {
+	vendored := filepath.Join(ctx.ApplicationRoot(), vendoredCache)
+	exists, err := ctx.FileExists(vendored)
+	if err != nil || !exists {
+		return err
+	}
+	ctx.Logf("Adding the packages vendored in %s to the pub cache.", vendoredCache)
+	if _, err := ctx.Exec([]string{"cp", "--recursive", "--no-target-directory", vendored, cacheDir}, gcp.WithUserAttribution); err != nil {
+		return err
+	}
+	return nil
+}
@@ -15,9 +15,12 @@
 package main
 
 import (
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 
 	buildpacktest "github.com/GoogleCloudPlatform/buildpacks/internal/buildpacktest"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
 )
 
 func TestDetect(t *testing.T) {
@@ -48,3 +51,69 @@ func TestDetect(t *testing.T) {
 		})
 	}
 }
+
+func TestLockedDependenciesCached(t *testing.T) {
+	const (
+		pubspec = "name: app\ndependencies:\n  shelf: ^1.4.0\n"
+		lock    = "packages:\n  shelf:\n    version: \"1.4.1\"\n"
+	)
+	testCases := []struct {
+		name       string
+		files      map[string]string
+		prevFiles  map[string]string
+		wantCached bool
+	}{
+		{
+			name:       "unchanged lock",
+			files:      map[string]string{"pubspec.yaml": pubspec, "pubspec.lock": lock},
+			prevFiles:  map[string]string{"pubspec.yaml": pubspec, "pubspec.lock": lock},
+			wantCached: true,
+		},
+		{
+			name:      "changed lock",
+			files:     map[string]string{"pubspec.yaml": pubspec, "pubspec.lock": lock + "  args:\n    version: \"2.4.2\"\n"},
+			prevFiles: map[string]string{"pubspec.yaml": pubspec, "pubspec.lock": lock},
+		},
+		{
+			name:      "no lock",
+			files:     map[string]string{"pubspec.yaml": pubspec},
+			prevFiles: map[string]string{"pubspec.yaml": pubspec},
+		},
+		{
+			name:  "first build",
+			files: map[string]string{"pubspec.yaml": pubspec, "pubspec.lock": lock},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var prevHash string
+			if tc.prevFiles != nil {
+				prev, err := lockedDependenciesCached(appContext(t, tc.prevFiles), "")
+				if err != nil {
+					t.Fatalf("lockedDependenciesCached() got error: %v", err)
+				}
+				prevHash = prev.hash
+			}
+
+			got, err := lockedDependenciesCached(appContext(t, tc.files), prevHash)
+			if err != nil {
+				t.Fatalf("lockedDependenciesCached() got error: %v", err)
+			}
+			if got.cached != tc.wantCached {
+				t.Errorf("lockedDependenciesCached() cached = %v, want %v", got.cached, tc.wantCached)
+			}
+		})
+	}
+}
+
+// appContext returns a context for an application with the given files.
+func appContext(t *testing.T, files map[string]string) *gcp.Context {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+	}
+	return gcp.NewContext(gcp.WithApplicationRoot(root))
+}
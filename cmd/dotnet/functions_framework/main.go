@@ -29,7 +29,7 @@ const (
 )
 
 func main() {
-	gcp.Main(detectFn, buildFn)
+	gcp.Main(detectFn, buildFn, gcp.WithFunctionSourceDir())
 }
 
 func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
@@ -13,7 +13,8 @@
 // limitations under the License.
 
 // Implements dotnet/publish buildpack.
-// The publish buildpack runs dotnet publish.
+// The publish buildpack runs dotnet publish. At launch, it configures ASP.NET Core to listen on the
+// port of the container and to honor the forwarded headers of the serving infrastructure.
 package main
 
 import (
@@ -35,10 +36,24 @@ const (
 	cacheTag          = "prod dependencies"
 	dependencyHashKey = "dependency_hash"
 	versionKey        = "version"
+	// aspNetCoreExecD is the name of the exec.d helper that configures ASP.NET Core at launch time.
+	aspNetCoreExecD = "aspnetcore-env"
+
+	// portEnv is the port that the serving infrastructure sends requests to.
+	portEnv = "PORT"
+	// defaultPort is the port that Kestrel listens on if PORT is not set.
+	defaultPort = "8080"
+	// forwardedHeadersEnv enables the forwarded headers middleware of ASP.NET Core, so that the
+	// scheme and client address of requests are those seen by the load balancer.
+	forwardedHeadersEnv = "ASPNETCORE_FORWARDEDHEADERS_ENABLED"
 )
 
+// endpointEnvs are the environment variables that configure the Kestrel endpoints. If any is set,
+// the endpoints are left as configured.
+var endpointEnvs = []string{"ASPNETCORE_URLS", "DOTNET_URLS", "ASPNETCORE_HTTP_PORTS", "ASPNETCORE_HTTPS_PORTS"}
+
 func main() {
-	gcp.Main(detectFn, buildFn)
+	gcp.Main(detectFn, buildFn, gcp.WithExecD(aspNetCoreExecD, configureASPNetCore), gcp.WithFunctionSourceDir())
 }
 
 func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
@@ -61,28 +76,63 @@ func buildFn(ctx *gcp.Context) error {
 	if err != nil {
 		return fmt.Errorf("finding project: %w", err)
 	}
+	opts, err := dotnet.GetPublishOptions(ctx, proj)
+	if err != nil {
+		return err
+	}
+	mode := opts.Mode
+	ctx.Logf("Publishing %s as a %s deployment.", proj, mode)
+	if mode == dotnet.NativeAOT {
+		if err := dotnet.CheckAOTToolchain(ctx); err != nil {
+			return err
+		}
+	}
 	ctx.Logf("Installing application dependencies.")
 	pkgLayer, err := ctx.Layer("packages", gcp.BuildLayer, gcp.CacheLayer)
 	if err != nil {
 		return fmt.Errorf("creating layer: %w", err)
 	}
+	pkgLayer.BuildEnvironment.Override(dotnet.EnvNuGetPackages, pkgLayer.Path)
 
-	cached, err := checkCache(ctx, pkgLayer)
+	cached, err := checkCache(ctx, pkgLayer, opts)
 	if err != nil {
 		return fmt.Errorf("checking cache: %w", err)
 	}
-	// Print cache status for testing/debugging only, `dotnet restore` reuses any existing artifacts.
+	// Print cache status for testing/debugging only. The layer is the NuGet global packages folder,
+	// which is kept on a cache miss so that `dotnet restore` only downloads the packages that changed.
 	if cached {
 		ctx.CacheHit(cacheTag)
 	} else {
 		ctx.CacheMiss(cacheTag)
 	}
 
+	// Restore the solution that lists the project, so that all of its projects share the restore.
+	restoreTarget, err := dotnet.SolutionFile(ctx, proj)
+	if err != nil {
+		return err
+	}
+	if restoreTarget != "" {
+		ctx.Logf("Restoring the dependencies of solution %s.", restoreTarget)
+	} else {
+		restoreTarget = proj
+	}
+
+	credentialsEnv, cleanup, err := dotnet.NuGetCredentialsEnv(ctx, proj)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
 	// Run restore regardless of cache status because it generates files expected by publish.
-	cmd := []string{"dotnet", "restore", "--packages", pkgLayer.Path, proj}
-	if _, err := ctx.Exec(cmd, gcp.WithEnv("DOTNET_CLI_TELEMETRY_OPTOUT=true"), gcp.WithUserAttribution); err != nil {
+	cmd := []string{"dotnet", "restore", "--packages", pkgLayer.Path}
+	cmd = append(cmd, opts.Args()...)
+	cmd = append(cmd, restoreTarget)
+	restoreEnv := append([]string{"DOTNET_CLI_TELEMETRY_OPTOUT=true"}, credentialsEnv...)
+	if _, err := ctx.Exec(cmd, gcp.WithEnv(restoreEnv...), gcp.WithUserAttribution); err != nil {
 		return err
 	}
+	// Remove the credentials before publish runs the build targets of the project.
+	cleanup()
 
 	binLayer, err := ctx.Layer(dotnet.PublishLayerName, gcp.BuildLayer, gcp.LaunchLayer)
 	if err != nil {
@@ -109,8 +159,9 @@ func buildFn(ctx *gcp.Context) error {
 		"--output", outputDirectory,
 		"--no-restore",
 		"--packages", pkgLayer.Path,
-		proj,
 	}
+	cmd = append(cmd, opts.Args()...)
+	cmd = append(cmd, proj)
 
 	if args := os.Getenv(env.BuildArgs); args != "" {
 		// Use bash to excute the command to avoid havnig to parse the build arguments.
@@ -122,12 +173,20 @@ func buildFn(ctx *gcp.Context) error {
 		return err
 	}
 
-	// Set GOOGLE_ASP_NET_CORE_VERSION, so subsequent buildpacks know which runtime version to install
-	runtimeVersion, err := dotnet.GetRuntimeVersion(ctx, outputDirectory)
-	if err != nil {
-		return gcp.InternalErrorf("getting runtime version: %v", err)
+	// Tell the runtime buildpack whether the application requires the .NET runtime.
+	binLayer.BuildEnvironment.Override(dotnet.EnvPublishMode, string(mode))
+	if !mode.RequiresRuntime() {
+		if dotnet.RequiresGlobalizationInvariant(ctx) {
+			binLayer.LaunchEnvironment.Default("DOTNET_SYSTEM_GLOBALIZATION_INVARIANT", "1")
+		}
+	} else {
+		// Set GOOGLE_ASP_NET_CORE_VERSION, so subsequent buildpacks know which runtime version to install
+		runtimeVersion, err := dotnet.GetRuntimeVersion(ctx, outputDirectory)
+		if err != nil {
+			return gcp.InternalErrorf("getting runtime version: %v", err)
+		}
+		binLayer.BuildEnvironment.Default(dotnet.EnvRuntimeVersion, runtimeVersion)
 	}
-	binLayer.BuildEnvironment.Default(dotnet.EnvRuntimeVersion, runtimeVersion)
 
 	// `dotnet publish` output originally went to ctx.ApplicationRoot()/bin/.  This was moved into a
 	// layer, but we create a symlink in the original location for backwards compatability.
@@ -140,7 +199,7 @@ func buildFn(ctx *gcp.Context) error {
 	if entrypoint != "" {
 		entrypoint = "exec " + entrypoint
 	} else {
-		ep, err := getEntrypoint(ctx, outputDirectory, proj)
+		ep, err := getEntrypoint(ctx, outputDirectory, proj, !mode.RequiresRuntime())
 		if err != nil {
 			return fmt.Errorf("getting entrypoint: %w", err)
 		}
@@ -148,6 +207,10 @@ func buildFn(ctx *gcp.Context) error {
 		binLayer.BuildEnvironment.Default(env.Entrypoint, entrypoint)
 	}
 	binLayer.LaunchEnvironment.Default("DOTNET_RUNNING_IN_CONTAINER", "true")
+	// The Kestrel endpoints are set at launch, when PORT is known, rather than in the entrypoint.
+	if err := ctx.AddExecD(binLayer, aspNetCoreExecD); err != nil {
+		return err
+	}
 
 	// Configure the entrypoint for production.
 	if !devmode.Enabled(ctx) {
@@ -161,15 +224,16 @@ func buildFn(ctx *gcp.Context) error {
 	return nil
 }
 
-// getEntrypoint retrieves the appropriate entrypoint for this build.
+// getEntrypoint retrieves the appropriate entrypoint for this build. Self-contained and Native AOT
+// applications are started with the native executable, framework-dependent ones with the library.
 // * Check the output directory for a binary or a library with the same name as the project file (e.g. app.csproj --> app or app.dll).
 // * If not found, parse the project file for an AssemblyName field and check for the associated binary or library file in the output directory.
 // * If not found, return user error.
-func getEntrypoint(ctx *gcp.Context, bin, proj string) (string, error) {
+func getEntrypoint(ctx *gcp.Context, bin, proj string, native bool) (string, error) {
 	ctx.Logf("Determining entrypoint from output directory %s and project file %s", bin, proj)
 	p := strings.TrimSuffix(filepath.Base(proj), filepath.Ext(proj))
 
-	ep, err := getEntrypointCmd(ctx, filepath.Join(bin, p))
+	ep, err := getEntrypointCmd(ctx, filepath.Join(bin, p), native)
 	if err != nil {
 		return "", err
 	}
@@ -182,7 +246,7 @@ func getEntrypoint(ctx *gcp.Context, bin, proj string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("getting assembly name: %w", err)
 	}
-	ep, err = getEntrypointCmd(ctx, filepath.Join(bin, an))
+	ep, err = getEntrypointCmd(ctx, filepath.Join(bin, an), native)
 	if err != nil {
 		return "", err
 	}
@@ -194,7 +258,17 @@ func getEntrypoint(ctx *gcp.Context, bin, proj string) (string, error) {
 	return "", gcp.UserErrorf("unable to find executable produced from %s, try setting the AssemblyName property", proj)
 }
 
-func getEntrypointCmd(ctx *gcp.Context, ep string) (string, error) {
+func getEntrypointCmd(ctx *gcp.Context, ep string, native bool) (string, error) {
+	if native {
+		exeExists, err := ctx.FileExists(ep)
+		if err != nil {
+			return "", err
+		}
+		if exeExists {
+			return fmt.Sprintf("cd %s && exec ./%s", path.Dir(ep), path.Base(ep)), nil
+		}
+		return "", nil
+	}
 	dll := ep + ".dll"
 	dllExists, err := ctx.FileExists(dll)
 	if err != nil {
@@ -206,31 +280,34 @@ func getEntrypointCmd(ctx *gcp.Context, ep string) (string, error) {
 	return "", nil
 }
 
-func checkCache(ctx *gcp.Context, l *libcnb.Layer) (bool, error) {
-	// We cache all *.*proj files, as if we just cache just the main one, we would miss any changes
-	// to other libraries implemented as part of the app. As many apps are structured such that the
-	// main app only depends on the local binaries, that root project file would change very
-	// infrequently while the associated library files would change significantly more often, as
-	// that's where the primary implementation is done.
-	projectFiles, err := dotnet.ProjectFiles(ctx, ".")
-	if err != nil {
-		return false, err
-	}
-	globalJSON := filepath.Join(ctx.ApplicationRoot(), "global.json")
-	globalJSONExists, err := ctx.FileExists(globalJSON)
+func checkCache(ctx *gcp.Context, l *libcnb.Layer, opts dotnet.PublishOptions) (bool, error) {
+	// We hash the inputs of the restore of all projects, as if we just hash the main project file,
+	// we would miss any changes to other libraries implemented as part of the app. As many apps are
+	// structured such that the main app only depends on the local binaries, that root project file
+	// would change very infrequently while the associated library files would change significantly
+	// more often, as that's where the primary implementation is done.
+	inputFiles, err := dotnet.RestoreInputFiles(ctx, ctx.ApplicationRoot())
 	if err != nil {
 		return false, err
 	}
-	if globalJSONExists {
-		projectFiles = append(projectFiles, globalJSON)
-	}
 	result, err := ctx.Exec([]string{"dotnet", "--version"})
 	if err != nil {
 		return false, err
 	}
 	currentVersion := result.Stdout
 
-	hash, err := cache.Hash(ctx, cache.WithStrings(currentVersion), cache.WithFiles(projectFiles...))
+	// The publish options determine the runtime packs and compilers that are restored. The paths
+	// of the files are hashed too, as they determine which project each file applies to.
+	var relFiles []string
+	for _, f := range inputFiles {
+		rel, err := filepath.Rel(ctx.ApplicationRoot(), f)
+		if err != nil {
+			return false, gcp.InternalErrorf("finding relative path of %s: %v", f, err)
+		}
+		relFiles = append(relFiles, rel)
+	}
+	strs := append(append([]string{currentVersion}, opts.Args()...), relFiles...)
+	hash, err := cache.Hash(ctx, cache.WithStrings(strs...), cache.WithFiles(inputFiles...))
 	if err != nil {
 		return false, fmt.Errorf("computing dependency hash: %w", err)
 	}
@@ -246,6 +323,15 @@ func checkCache(ctx *gcp.Context, l *libcnb.Layer) (bool, error) {
 
 	if metaDependencyHash == "" {
 		ctx.Debugf("No metadata found from a previous build, skipping cache.")
+	} else if metaVersion := ctx.GetMetadata(l, versionKey); metaVersion != currentVersion {
+		// Packages restored by a different SDK, e.g. runtime packs, are not reused, so clear them to
+		// keep the layer from growing across SDK upgrades.
+		ctx.Debugf(".NET SDK version changed from %q to %q, clearing the package cache.", metaVersion, currentVersion)
+		if err := ctx.ClearLayer(l); err != nil {
+			return false, fmt.Errorf("clearing layer: %w", err)
+		}
+	} else {
+		ctx.Logf("Dependencies changed, restoring incrementally from the package cache.")
 	}
 	// Update the layer metadata.
 	ctx.SetMetadata(l, dependencyHashKey, hash)
@@ -301,3 +387,36 @@ func configureBinSymlink(ctx *gcp.Context, binLayerPath string) error {
 	}
 	return nil
 }
+
+// configureASPNetCore is an exec.d helper that configures ASP.NET Core to serve on PORT behind a
+// load balancer.
+func configureASPNetCore() (map[string]string, error) {
+	return aspNetCoreEnv(), nil
+}
+
+// aspNetCoreEnv returns the launch environment for ASP.NET Core, leaving the settings of the user
+// intact, including those set for a single process.
+func aspNetCoreEnv() map[string]string {
+	e := map[string]string{}
+	if !anyEnvSet(endpointEnvs) {
+		port := os.Getenv(portEnv)
+		if port == "" {
+			port = defaultPort
+		}
+		e["ASPNETCORE_URLS"] = "http://0.0.0.0:" + port
+	}
+	if !anyEnvSet([]string{forwardedHeadersEnv}) {
+		e[forwardedHeadersEnv] = "true"
+	}
+	return e
+}
+
+// anyEnvSet returns true if any of the environment variables is set to a non-empty value.
+func anyEnvSet(names []string) bool {
+	for _, n := range names {
+		if os.Getenv(n) != "" {
+			return true
+		}
+	}
+	return false
+}
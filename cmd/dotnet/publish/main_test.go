@@ -24,6 +24,7 @@ import (
 
 	buildpacktest "github.com/GoogleCloudPlatform/buildpacks/internal/buildpacktest"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestGetAssemblyName(t *testing.T) {
@@ -109,11 +110,12 @@ func TestGetAssemblyName(t *testing.T) {
 
 func TestGetEntrypoint(t *testing.T) {
 	tcs := []struct {
-		name string
-		exe  string
-		proj string
-		data string
-		want string
+		name   string
+		exe    string
+		proj   string
+		data   string
+		native bool
+		want   string
 	}{
 		{
 			name: "dll from project file",
@@ -153,6 +155,28 @@ func TestGetEntrypoint(t *testing.T) {
 	</Project>`,
 			want: "cd {{.Tmp}} && exec dotnet customapp.dll",
 		},
+		{
+			name:   "native executable from project file",
+			exe:    "myapp",
+			proj:   "myapp.proj",
+			native: true,
+			want:   "cd {{.Tmp}} && exec ./myapp",
+		},
+		{
+			name: "native executable from assembly name",
+			exe:  "customapp",
+			proj: "myapp.proj",
+			data: `<Project Sdk="Microsoft.NET.Sdk.Web">
+
+		<PropertyGroup>
+			<AssemblyName>customapp</AssemblyName>
+			<PublishAot>true</PublishAot>
+		</PropertyGroup>
+
+	</Project>`,
+			native: true,
+			want:   "cd {{.Tmp}} && exec ./customapp",
+		},
 	}
 
 	for _, tc := range tcs {
@@ -181,7 +205,7 @@ func TestGetEntrypoint(t *testing.T) {
 				t.Fatalf("writing proj file: %v", err)
 			}
 
-			ep, err := getEntrypoint(ctx, tmpDir, proj)
+			ep, err := getEntrypoint(ctx, tmpDir, proj, tc.native)
 			if err != nil {
 				t.Fatalf("getting entrypoint: %v", err)
 			}
@@ -338,3 +362,46 @@ func TestDeleteFolder(t *testing.T) {
 		})
 	}
 }
+
+func TestASPNetCoreEnv(t *testing.T) {
+	testCases := []struct {
+		name string
+		env  map[string]string
+		want map[string]string
+	}{
+		{
+			name: "defaults",
+			want: map[string]string{"ASPNETCORE_URLS": "http://0.0.0.0:8080", "ASPNETCORE_FORWARDEDHEADERS_ENABLED": "true"},
+		},
+		{
+			name: "PORT",
+			env:  map[string]string{"PORT": "9000"},
+			want: map[string]string{"ASPNETCORE_URLS": "http://0.0.0.0:9000", "ASPNETCORE_FORWARDEDHEADERS_ENABLED": "true"},
+		},
+		{
+			name: "user URLs",
+			env:  map[string]string{"PORT": "9000", "ASPNETCORE_URLS": "http://+:5000"},
+			want: map[string]string{"ASPNETCORE_FORWARDEDHEADERS_ENABLED": "true"},
+		},
+		{
+			name: "user HTTP ports",
+			env:  map[string]string{"ASPNETCORE_HTTP_PORTS": "5000"},
+			want: map[string]string{"ASPNETCORE_FORWARDEDHEADERS_ENABLED": "true"},
+		},
+		{
+			name: "forwarded headers disabled",
+			env:  map[string]string{"ASPNETCORE_FORWARDEDHEADERS_ENABLED": "false"},
+			want: map[string]string{"ASPNETCORE_URLS": "http://0.0.0.0:8080"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, name := range append([]string{portEnv, forwardedHeadersEnv}, endpointEnvs...) {
+				t.Setenv(name, tc.env[name])
+			}
+			if diff := cmp.Diff(tc.want, aspNetCoreEnv()); diff != "" {
+				t.Errorf("aspNetCoreEnv() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -35,7 +35,7 @@ const (
 )
 
 func main() {
-	gcp.Main(detectFn, buildFn)
+	gcp.Main(detectFn, buildFn, gcp.WithFunctionSourceDir())
 }
 
 func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
@@ -72,6 +72,15 @@ func buildFn(ctx *gcp.Context) error {
 		return nil
 	}
 
+	mode, err := dotnet.PublishModeFromEnv()
+	if err != nil {
+		return err
+	}
+	if !mode.RequiresRuntime() {
+		ctx.Logf("Skipping the .NET runtime, the application is published as a %s deployment.", mode)
+		return nil
+	}
+
 	runtimeVersion, err := dotnet.GetRuntimeVersion(ctx, ctx.ApplicationRoot())
 	if err != nil {
 		return fmt.Errorf("getting runtime version: %w", err)
@@ -35,7 +35,7 @@ const (
 )
 
 func main() {
-	gcp.Main(detectFn, buildFn)
+	gcp.Main(detectFn, buildFn, gcp.WithFunctionSourceDir())
 }
 
 func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
@@ -55,7 +55,9 @@ func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
 }
 
 func buildFn(ctx *gcp.Context) error {
-	sdkVersion, err := dotnet.GetSDKVersion(ctx)
+	sdkVersion, err := dotnet.ResolveSDKVersion(ctx, func() ([]string, error) {
+		return runtime.AvailableVersions(ctx, runtime.DotnetSDK)
+	})
 	if err != nil {
 		return err
 	}
@@ -17,24 +17,40 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/devmode"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/golang"
+	"github.com/buildpacks/libcnb"
 )
 
 const (
 	noGoFileError         = "no Go files in"
 	cannotFindModuleError = "cannot find module"
+
+	// buildVersionVar is the variable set to the build version with the -X linker flag. The linker
+	// ignores it when the application does not declare it.
+	buildVersionVar = "main.buildVersion"
+)
+
+var (
+	// buildVersionLabels are the labels used as build version, in order, when GOOGLE_GO_BUILD_VERSION is not set.
+	buildVersionLabels = []string{env.LabelPrefix + "VERSION", env.LabelPrefix + "COMMIT_SHA"}
 )
 
 func main() {
-	gcp.Main(detectFn, buildFn)
+	gcp.Main(detectFn, buildFn, gcp.WithFunctionSourceDir())
 }
 
 func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
@@ -49,51 +65,120 @@ func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
 }
 
 func buildFn(ctx *gcp.Context) error {
-	// Keep GOCACHE in Devmode for faster rebuilds.
-	cl, err := ctx.Layer("gocache", gcp.BuildLayer, gcp.LaunchLayerIfDevMode)
-	if err != nil {
-		return fmt.Errorf("creating layer: %w", err)
-	}
-	if devmode.Enabled(ctx) {
-		cl.LaunchEnvironment.Override("GOCACHE", cl.Path)
-	}
-
-	// Create a layer for the compiled binary.  Add it to PATH in case
-	// users wish to invoke the binary manually.
+	// Create a layer for the compiled binaries.  Add it to PATH in case
+	// users wish to invoke the binaries manually.
 	bl, err := ctx.Layer("bin", gcp.LaunchLayer)
 	if err != nil {
 		return fmt.Errorf("creating layer: %w", err)
 	}
 	bl.LaunchEnvironment.Prepend("PATH", string(os.PathListSeparator), bl.Path)
-	outBin := filepath.Join(bl.Path, golang.OutBin)
 
-	buildable, err := goBuildable(ctx)
+	buildables, err := goBuildables(ctx)
 	if err != nil {
 		return fmt.Errorf("unable to find a valid buildable: %w", err)
 	}
-
-	// Build the application.
-	bld := []string{"go", "build"}
-	bld = append(bld, goBuildFlags()...)
-	bld = append(bld, "-o", outBin)
-	bld = append(bld, buildable)
+	bins, err := binaries(buildables, bl.Path)
+	if err != nil {
+		return err
+	}
 	// BuildDirEnv should only be set by App Engine buildpacks.
 	workdir := os.Getenv(golang.BuildDirEnv)
 	if workdir == "" {
 		workdir = ctx.ApplicationRoot()
 	}
-	if _, err := ctx.Exec(bld, gcp.WithEnv("GOCACHE="+cl.Path), gcp.WithWorkDir(workdir), gcp.WithMessageProducer(printTipsAndKeepStderrTail(ctx)), gcp.WithUserAttribution); err != nil {
+	profiles, err := setPGOProfiles(ctx, bins, workdir)
+	if err != nil {
+		return err
+	}
+
+	// Keep GOCACHE between builds, and in Devmode, for faster rebuilds.
+	cl, err := golang.NewGoBuildCacheLayer(ctx, profiles...)
+	if err != nil {
+		return fmt.Errorf("creating layer: %w", err)
+	}
+	if devmode.Enabled(ctx) {
+		cl.LaunchEnvironment.Override("GOCACHE", cl.Path)
+	}
+
+	// Build the application.
+	cgo, err := golang.ReadCgoConfig(ctx)
+	if err != nil {
+		return err
+	}
+	debug, err := golang.DebugEnabled()
+	if err != nil {
+		return err
+	}
+	flags, err := goBuildFlags(cgo.LDFlags(), debug)
+	if err != nil {
+		return err
+	}
+	tagFlags, err := golang.BuildTagFlags(ctx, cgo.Tags()...)
+	if err != nil {
+		return err
+	}
+	flags = append(flags, tagFlags...)
+	buildEnv := append([]string{"GOCACHE=" + cl.Path}, cgo.Env()...)
+	goFlags, err := golang.GoFlags(ctx)
+	if err != nil {
+		return err
+	}
+	if goFlags != "" {
+		buildEnv = append(buildEnv, "GOFLAGS="+goFlags)
+		if devmode.Enabled(ctx) {
+			cl.LaunchEnvironment.Override("GOFLAGS", goFlags)
+		}
+	}
+	if err := embedAssets(ctx); err != nil {
+		return err
+	}
+
+	checkOpts := []gcp.ExecOption{gcp.WithEnv(buildEnv...), gcp.WithWorkDir(workdir), gcp.WithMessageProducer(gcp.KeepCombinedTail), gcp.WithUserAttribution}
+	if err := runChecks(ctx, tagFlags, checkOpts...); err != nil {
+		return err
+	}
+
+	var bld []string
+	for _, b := range bins {
+		bld = buildCommand(flags, b)
+		if _, err := ctx.Exec(bld, gcp.WithEnv(buildEnv...), gcp.WithWorkDir(workdir), gcp.WithMessageProducer(printTipsAndKeepStderrTail(ctx)), gcp.WithUserAttribution); err != nil {
+			return err
+		}
+	}
+
+	var paths []string
+	for _, b := range bins {
+		if err := reportBinary(ctx, b); err != nil {
+			return err
+		}
+		paths = append(paths, b.path)
+	}
+	if err := golang.WriteSBOM(ctx, bl, paths); err != nil {
 		return err
 	}
 
+	if debug {
+		if err := addDebugProcess(ctx, bins[0].path); err != nil {
+			return err
+		}
+	}
+
+	// Additional binaries, e.g. workers, are added as processes named after the binary.
+	for _, b := range bins[1:] {
+		ctx.AddProcess(b.name, []string{b.path}, gcp.AsDirectProcess())
+	}
+
 	// Configure the entrypoint for production. Use the full path to save `skaffold debug`
 	// from fetching the remote container image (tens to hundreds of megabytes), which is slow.
+	outBin := bins[0].path
 	if !devmode.Enabled(ctx) {
 		ctx.AddWebProcess([]string{outBin})
 		return nil
 	}
 
-	// Configure the entrypoint and metadata for dev mode.
+	// Configure the entrypoint and metadata for dev mode. Only the first buildable, which
+	// provides the web process, is rebuilt on changes.
+	bld = buildCommand(flags, bins[0])
 	if err := devmode.AddFileWatcherProcess(ctx, devmode.Config{
 		BuildCmd: bld,
 		RunCmd:   []string{outBin},
@@ -107,6 +192,285 @@ func buildFn(ctx *gcp.Context) error {
 	return nil
 }
 
+// embedAsset is a directory of assets that is copied where `//go:embed` expects it.
+type embedAsset struct {
+	// handoff is the directory produced by an earlier buildpack.
+	handoff string
+	// embed is the directory, relative to the application root, where the assets are embedded from.
+	embed string
+}
+
+// embedAssets copies the assets specified with GOOGLE_GO_EMBED_ASSETS into the application before
+// it is compiled.
+func embedAssets(ctx *gcp.Context) error {
+	assets, err := parseEmbedAssets(os.Getenv(env.GoEmbedAssets))
+	if err != nil {
+		return err
+	}
+	for _, a := range assets {
+		src := a.handoff
+		if !filepath.IsAbs(src) {
+			src = filepath.Join(ctx.ApplicationRoot(), src)
+		}
+		exists, err := ctx.FileExists(src)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return gcp.UserErrorf("%s directory %q does not exist, check that a buildpack earlier in the group produces it, e.g. with the gcp-build script of package.json", env.GoEmbedAssets, a.handoff)
+		}
+		dst := filepath.Join(ctx.ApplicationRoot(), a.embed)
+		if err := ctx.MkdirAll(dst, 0755); err != nil {
+			return err
+		}
+		ctx.Logf("Copying assets from %s to %s for //go:embed", a.handoff, a.embed)
+		// Trailing "/." copies the contents of src directory, but not src itself.
+		if _, err := ctx.Exec([]string{"cp", "--dereference", "-R", filepath.Clean(src) + string(filepath.Separator) + ".", dst}, gcp.WithUserTimingAttribution); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseEmbedAssets parses the comma-separated `<handoff dir>:<embed dir>` pairs of
+// GOOGLE_GO_EMBED_ASSETS.
+func parseEmbedAssets(val string) ([]embedAsset, error) {
+	var assets []embedAsset
+	for _, entry := range strings.Split(val, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, gcp.UserErrorf("invalid entry %q in %s, want <handoff dir>:<embed dir>", entry, env.GoEmbedAssets)
+		}
+		embed := filepath.Clean(parts[1])
+		if filepath.IsAbs(embed) || embed == "." || embed == ".." || strings.HasPrefix(embed, "../") {
+			return nil, gcp.UserErrorf("invalid embed directory %q in %s, it must be a subdirectory of the application", parts[1], env.GoEmbedAssets)
+		}
+		assets = append(assets, embedAsset{handoff: filepath.Clean(parts[0]), embed: embed})
+	}
+	return assets, nil
+}
+
+// runChecks runs `go vet` and `go test` when requested with GOOGLE_GO_VET and GOOGLE_GO_TEST,
+// using the same build tags and environment as the build.
+func runChecks(ctx *gcp.Context, tagFlags []string, opts ...gcp.ExecOption) error {
+	cmds, err := checkCommands(tagFlags)
+	if err != nil {
+		return err
+	}
+	for _, cmd := range cmds {
+		if _, err := ctx.Exec(cmd, opts...); err != nil {
+			return gcp.UserErrorf("%q failed, fix the reported issues or unset %s and %s: %v", strings.Join(cmd[:2], " "), env.GoVet, env.GoTest, err)
+		}
+	}
+	return nil
+}
+
+// checkCommands returns the `go vet` and `go test` commands requested with GOOGLE_GO_VET and
+// GOOGLE_GO_TEST.
+func checkCommands(tagFlags []string) ([][]string, error) {
+	vet, err := env.IsPresentAndTrue(env.GoVet)
+	if err != nil {
+		return nil, gcp.UserErrorf("%v", err)
+	}
+	test, err := env.IsPresentAndTrue(env.GoTest)
+	if err != nil {
+		return nil, gcp.UserErrorf("%v", err)
+	}
+	pkgs := strings.Fields(os.Getenv(env.GoTestPackages))
+	if len(pkgs) == 0 {
+		pkgs = []string{"./..."}
+	}
+	var cmds [][]string
+	if vet {
+		cmds = append(cmds, append(append([]string{"go", "vet"}, tagFlags...), pkgs...))
+	}
+	if test {
+		cmd := append([]string{"go", "test"}, tagFlags...)
+		cmd = append(cmd, strings.Fields(os.Getenv(env.GoTestFlags))...)
+		cmds = append(cmds, append(cmd, pkgs...))
+	}
+	return cmds, nil
+}
+
+// reportBinary logs the SHA-256 hash of the binary and adds it to the bill of materials, so that
+// the binary can be compared with the one from another build of the same source.
+func reportBinary(ctx *gcp.Context, b binary) error {
+	f, err := os.Open(b.path)
+	if err != nil {
+		return gcp.InternalErrorf("opening %s: %v", b.path, err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return gcp.InternalErrorf("hashing %s: %v", b.path, err)
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	ctx.Logf("Built %s from %s, sha256:%s", b.name, b.buildable, sum)
+	ctx.AddBOMEntry(libcnb.BOMEntry{
+		Name:     "go-binary-" + b.name,
+		Metadata: map[string]interface{}{"buildable": b.buildable, "sha256": sum},
+		Launch:   true,
+	})
+	return nil
+}
+
+// binary is a Go binary built from a buildable.
+type binary struct {
+	// buildable is the package or file that is built.
+	buildable string
+	// name is the name of the binary, and of its process unless it is the web process.
+	name string
+	// path is the path of the binary in the bin layer.
+	path string
+	// pgo is the value of the -pgo flag, or an empty string to use the go command default.
+	pgo string
+}
+
+// buildCommand returns the go build command of the binary.
+func buildCommand(flags []string, b binary) []string {
+	cmd := append([]string{"go", "build"}, flags...)
+	if b.pgo != "" {
+		cmd = append(cmd, "-pgo="+b.pgo)
+	}
+	return append(cmd, "-o", b.path, b.buildable)
+}
+
+// setPGOProfiles sets the profile used for profile-guided optimization of each binary: the one
+// from GOOGLE_GO_PGO_PROFILE, else the default.pgo file of its main package directory. It returns
+// the paths of the profiles in use.
+func setPGOProfiles(ctx *gcp.Context, bins []binary, workdir string) ([]string, error) {
+	val := strings.TrimSpace(os.Getenv(env.GoPGOProfile))
+	if val == "off" {
+		// Go 1.21+ uses default.pgo automatically, disable it explicitly.
+		supported, err := golang.VersionMatches(ctx, ">=1.20.0")
+		if err != nil {
+			return nil, err
+		}
+		if supported {
+			for i := range bins {
+				bins[i].pgo = "off"
+			}
+		}
+		return nil, nil
+	}
+
+	profiles := map[string]string{}
+	for _, b := range bins {
+		profile, err := pgoProfile(ctx, val, workdir, b.buildable)
+		if err != nil {
+			return nil, err
+		}
+		if profile != "" {
+			profiles[b.name] = profile
+		}
+	}
+	if len(profiles) == 0 {
+		return nil, nil
+	}
+	supported, err := golang.VersionMatches(ctx, ">=1.20.0")
+	if err != nil {
+		return nil, err
+	}
+	if !supported {
+		ctx.Warnf("Ignoring profile-guided optimization profiles, they require Go 1.20 or later.")
+		return nil, nil
+	}
+	var paths []string
+	for i, b := range bins {
+		if p, ok := profiles[b.name]; ok {
+			ctx.Logf("Using profile-guided optimization profile %s for %s", p, b.buildable)
+			bins[i].pgo = p
+			paths = append(paths, p)
+		}
+	}
+	return paths, nil
+}
+
+// pgoProfile returns the path of the profile for the buildable: the given path relative to the
+// application root, if any, else the default.pgo file in the directory of the main package, if it
+// exists.
+func pgoProfile(ctx *gcp.Context, path, workdir, buildable string) (string, error) {
+	if path != "" {
+		profile := filepath.Join(ctx.ApplicationRoot(), path)
+		exists, err := ctx.FileExists(profile)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return "", gcp.UserErrorf("profile %q from %s does not exist", path, env.GoPGOProfile)
+		}
+		return profile, nil
+	}
+	// Only local packages have a directory in the application, import paths are left to the go command.
+	if !strings.HasPrefix(buildable, ".") && !strings.HasSuffix(buildable, ".go") {
+		return "", nil
+	}
+	dir := buildable
+	if strings.HasSuffix(buildable, ".go") {
+		dir = filepath.Dir(buildable)
+	}
+	profile := filepath.Join(workdir, dir, "default.pgo")
+	exists, err := ctx.FileExists(profile)
+	if err != nil || !exists {
+		return "", err
+	}
+	return profile, nil
+}
+
+// processNameRegexp matches the process types allowed by the buildpacks spec.
+var processNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// binaries returns the binaries built from the buildables in binDir. The first one is the web
+// process, named main, and the others are named after the last element of their package path.
+func binaries(buildables []string, binDir string) ([]binary, error) {
+	bins := []binary{{buildable: buildables[0], name: golang.OutBin, path: filepath.Join(binDir, golang.OutBin)}}
+	seen := map[string]string{golang.OutBin: buildables[0], gcp.WebProcess: buildables[0]}
+	for _, b := range buildables[1:] {
+		name := strings.TrimSuffix(filepath.Base(filepath.Clean(b)), ".go")
+		if !processNameRegexp.MatchString(name) || name == "." {
+			return nil, gcp.UserErrorf("cannot name the binary of %q from %s: the last element of the path must only contain letters, digits, dashes and underscores", b, env.Buildable)
+		}
+		if other, ok := seen[name]; ok {
+			return nil, gcp.UserErrorf("%q and %q in %s would both build a binary named %q, the last element of each path must be unique", other, b, env.Buildable, name)
+		}
+		seen[name] = b
+		bins = append(bins, binary{buildable: b, name: name, path: filepath.Join(binDir, name)})
+	}
+	return bins, nil
+}
+
+// goBuildables returns the packages to build. The first one provides the web process.
+func goBuildables(ctx *gcp.Context) ([]string, error) {
+	val, ok := os.LookupEnv(env.Buildable)
+	if !ok {
+		buildable, err := goBuildable(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return []string{buildable}, nil
+	}
+	buildables := parseBuildables(val)
+	if len(buildables) == 0 {
+		// Let Go build the default package.
+		return []string{"."}, nil
+	}
+	return buildables, nil
+}
+
+// parseBuildables parses the comma-separated list of packages in GOOGLE_BUILDABLE.
+func parseBuildables(val string) []string {
+	var buildables []string
+	for _, b := range strings.Split(val, ",") {
+		if b = strings.TrimSpace(b); b != "" {
+			buildables = append(buildables, b)
+		}
+	}
+	return buildables
+}
+
 func goBuildable(ctx *gcp.Context) (string, error) {
 	// The user tells us what to build.
 	if buildable, ok := os.LookupEnv(env.Buildable); ok {
@@ -130,36 +494,135 @@ func goBuildable(ctx *gcp.Context) (string, error) {
 }
 
 // searchBuildables searches the source for all the files that contain
-// a `main()` entrypoint.
+// a `main()` entrypoint. In a Go workspace, all workspace modules are searched.
 func searchBuildables(ctx *gcp.Context) ([]string, error) {
-	result, err := ctx.Exec([]string{"go", "list", "-f", `{{if eq .Name "main"}}{{.Dir}}{{end}}`, "./..."}, gcp.WithUserAttribution)
+	dirs := []string{ctx.ApplicationRoot()}
+	workspace, err := golang.WorkspaceExists(ctx)
 	if err != nil {
 		return nil, err
 	}
+	if workspace {
+		modules, err := golang.WorkspaceModules(ctx)
+		if err != nil {
+			return nil, err
+		}
+		dirs = nil
+		for _, m := range modules {
+			dirs = append(dirs, filepath.Join(ctx.ApplicationRoot(), m))
+		}
+	}
 
 	var buildables []string
 
-	for _, dir := range strings.Fields(result.Stdout) {
-		rel, err := filepath.Rel(ctx.ApplicationRoot(), dir)
+	for _, searchDir := range dirs {
+		result, err := ctx.Exec([]string{"go", "list", "-f", `{{if eq .Name "main"}}{{.Dir}}{{end}}`, "./..."}, gcp.WithWorkDir(searchDir), gcp.WithUserAttribution)
 		if err != nil {
-			return nil, fmt.Errorf("unable to find relative path for %q: %w", dir, err)
+			return nil, err
 		}
 
-		buildables = append(buildables, "./"+rel)
+		for _, dir := range strings.Fields(result.Stdout) {
+			rel, err := filepath.Rel(ctx.ApplicationRoot(), dir)
+			if err != nil {
+				return nil, fmt.Errorf("unable to find relative path for %q: %w", dir, err)
+			}
+
+			buildables = append(buildables, "./"+rel)
+		}
 	}
 
 	return buildables, nil
 }
 
-func goBuildFlags() []string {
+// addDebugProcess installs Delve and adds the debug process, which runs the binary under Delve.
+func addDebugProcess(ctx *gcp.Context, bin string) error {
+	port, err := golang.DebugPort()
+	if err != nil {
+		return err
+	}
+	dlv, err := golang.InstallDelve(ctx)
+	if err != nil {
+		return err
+	}
+	ctx.Logf("Adding the %q process, Delve listens on port %d", golang.DebugProcess, port)
+	ctx.AddProcess(golang.DebugProcess, golang.DebugCommand(dlv, bin, port), gcp.AsDirectProcess())
+	return nil
+}
+
+// goBuildFlags returns the compiler and linker flags of go build. extraLDFlags are linker flags
+// required by the buildpack. Debug builds disable optimizations and keep the full source paths
+// for the debugger.
+func goBuildFlags(extraLDFlags []string, debug bool) ([]string, error) {
 	var flags []string
-	if v := os.Getenv(env.GoGCFlags); v != "" {
+	trimPath, err := trimPathEnabled()
+	if err != nil {
+		return nil, err
+	}
+	if trimPath && !debug {
+		flags = append(flags, "-trimpath")
+	}
+	if debug {
+		flags = append(flags, "-gcflags", golang.DebugGCFlags)
+	} else if v := os.Getenv(env.GoGCFlags); v != "" {
 		flags = append(flags, "-gcflags", v)
 	}
-	if v := os.Getenv(env.GoLDFlags); v != "" {
-		flags = append(flags, "-ldflags", v)
+	version, err := buildVersion()
+	if err != nil {
+		return nil, err
+	}
+	// Only the last -ldflags flag is used by go build, so all linker flags are combined. The build
+	// version comes first so that it can be overridden by a user provided -X flag.
+	var ldflags []string
+	if version != "" {
+		ldflags = append(ldflags, fmt.Sprintf("-X %s=%s", buildVersionVar, version))
+	}
+	ldflags = append(ldflags, extraLDFlags...)
+	for _, e := range []string{env.GoLDFlags, env.GoLDFlagsAlias} {
+		if v := strings.TrimSpace(os.Getenv(e)); v != "" {
+			ldflags = append(ldflags, v)
+		}
+	}
+	if len(ldflags) > 0 {
+		flags = append(flags, "-ldflags", strings.Join(ldflags, " "))
+	}
+	return flags, nil
+}
+
+// trimPathEnabled returns true unless -trimpath is disabled with GOOGLE_GO_TRIMPATH.
+func trimPathEnabled() (bool, error) {
+	v, ok := os.LookupEnv(env.GoTrimPath)
+	if !ok {
+		return true, nil
+	}
+	enabled, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, gcp.UserErrorf("parsing %s: %v", env.GoTrimPath, err)
+	}
+	return enabled, nil
+}
+
+// buildVersion returns the version stamped into the binary: GOOGLE_GO_BUILD_VERSION, else the
+// first version label, else the SOURCE_DATE_EPOCH timestamp. It returns an empty version if none
+// is set, so that the binary does not depend on when it was built.
+func buildVersion() (string, error) {
+	for _, e := range append([]string{env.GoBuildVersion}, buildVersionLabels...) {
+		v := strings.TrimSpace(os.Getenv(e))
+		if v == "" {
+			continue
+		}
+		if strings.ContainsAny(v, " \t\n'\"") {
+			return "", gcp.UserErrorf("invalid build version %q from %s: must not contain whitespace or quotes", v, e)
+		}
+		return v, nil
+	}
+	epoch := strings.TrimSpace(os.Getenv("SOURCE_DATE_EPOCH"))
+	if epoch == "" {
+		return "", nil
+	}
+	sec, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return "", gcp.UserErrorf("invalid SOURCE_DATE_EPOCH %q: %v", epoch, err)
 	}
-	return flags
+	return time.Unix(sec, 0).UTC().Format("20060102T150405Z"), nil
 }
 
 func printTipsAndKeepStderrTail(ctx *gcp.Context) gcp.MessageProducer {
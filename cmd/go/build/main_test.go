@@ -16,11 +16,14 @@ package main
 
 import (
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/GoogleCloudPlatform/buildpacks/internal/buildpacktest"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
 )
 
 func TestDetect(t *testing.T) {
@@ -55,34 +58,76 @@ func TestGoBuildFlags(t *testing.T) {
 		clearAndSetEnv(oldEnv)
 	})
 	testCases := []struct {
-		name     string
-		env      []string
-		expected []string
+		name         string
+		env          []string
+		extraLDFlags []string
+		debug        bool
+		expected     []string
 	}{
 		{
 			name:     "no GOOGLE_GOGCFLAGS or GOOGLE_GOLDFLAGS",
-			expected: nil,
+			env:      []string{"GOOGLE_GO_BUILD_VERSION=v1"},
+			expected: []string{"-trimpath", "-ldflags", "-X main.buildVersion=v1"},
 		},
 		{
 			name:     "with GOOGLE_GOGCFLAGS",
-			env:      []string{"GOOGLE_GOGCFLAGS=gcflags"},
-			expected: []string{"-gcflags", "gcflags"},
+			env:      []string{"GOOGLE_GOGCFLAGS=gcflags", "GOOGLE_GO_BUILD_VERSION=v1"},
+			expected: []string{"-trimpath", "-gcflags", "gcflags", "-ldflags", "-X main.buildVersion=v1"},
 		},
 		{
 			name:     "with GOOGLE_GOLDFLAGS",
-			env:      []string{"GOOGLE_GOLDFLAGS=ldflags"},
-			expected: []string{"-ldflags", "ldflags"},
+			env:      []string{"GOOGLE_GOLDFLAGS=ldflags", "GOOGLE_GO_BUILD_VERSION=v1"},
+			expected: []string{"-trimpath", "-ldflags", "-X main.buildVersion=v1 ldflags"},
+		},
+		{
+			name:     "with GOOGLE_GO_LDFLAGS",
+			env:      []string{"GOOGLE_GO_LDFLAGS=-s -w", "GOOGLE_GO_BUILD_VERSION=v1"},
+			expected: []string{"-trimpath", "-ldflags", "-X main.buildVersion=v1 -s -w"},
 		},
 		{
 			name:     "with GOOGLE_GOGCFLAGS and GOOGLE_GOLDFLAGS",
-			env:      []string{"GOOGLE_GOGCFLAGS=gcflags1 gcflags2", "GOOGLE_GOLDFLAGS=ldflags1 ldflags2"},
-			expected: []string{"-gcflags", "gcflags1 gcflags2", "-ldflags", "ldflags1 ldflags2"},
+			env:      []string{"GOOGLE_GOGCFLAGS=gcflags1 gcflags2", "GOOGLE_GOLDFLAGS=ldflags1 ldflags2", "GOOGLE_GO_BUILD_VERSION=v1"},
+			expected: []string{"-trimpath", "-gcflags", "gcflags1 gcflags2", "-ldflags", "-X main.buildVersion=v1 ldflags1 ldflags2"},
+		},
+		{
+			name:     "without -trimpath",
+			env:      []string{"GOOGLE_GO_TRIMPATH=false", "GOOGLE_GO_BUILD_VERSION=v1"},
+			expected: []string{"-ldflags", "-X main.buildVersion=v1"},
+		},
+		{
+			name:     "debug",
+			env:      []string{"GOOGLE_GOGCFLAGS=gcflags", "GOOGLE_GO_BUILD_VERSION=v1"},
+			debug:    true,
+			expected: []string{"-gcflags", "all=-N -l", "-ldflags", "-X main.buildVersion=v1"},
+		},
+		{
+			name:     "no build version",
+			expected: []string{"-trimpath"},
+		},
+		{
+			name:     "SOURCE_DATE_EPOCH",
+			env:      []string{"SOURCE_DATE_EPOCH=1600000000"},
+			expected: []string{"-trimpath", "-ldflags", "-X main.buildVersion=20200913T122640Z"},
+		},
+		{
+			name:     "GOOGLE_GOLDFLAGS without build version",
+			env:      []string{"GOOGLE_GOLDFLAGS=-s -w"},
+			expected: []string{"-trimpath", "-ldflags", "-s -w"},
+		},
+		{
+			name:         "with extra linker flags",
+			env:          []string{"GOOGLE_GOLDFLAGS=-s", "GOOGLE_GO_BUILD_VERSION=v1"},
+			extraLDFlags: []string{"-linkmode=external", "-extldflags=-static"},
+			expected:     []string{"-trimpath", "-ldflags", "-X main.buildVersion=v1 -linkmode=external -extldflags=-static -s"},
 		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			clearAndSetEnv(tc.env)
-			result := goBuildFlags()
+			result, err := goBuildFlags(tc.extraLDFlags, tc.debug)
+			if err != nil {
+				t.Fatalf("goBuildFlags() got error: %v", err)
+			}
 			if !reflect.DeepEqual(tc.expected, result) {
 				t.Errorf("goBuildFlags() = %v, want %v", result, tc.expected)
 			}
@@ -90,6 +135,327 @@ func TestGoBuildFlags(t *testing.T) {
 	}
 }
 
+func TestGoBuildFlagsReproducible(t *testing.T) {
+	oldEnv := os.Environ()
+	t.Cleanup(func() {
+		clearAndSetEnv(oldEnv)
+	})
+	clearAndSetEnv(nil)
+
+	first, err := goBuildFlags(nil, false)
+	if err != nil {
+		t.Fatalf("goBuildFlags() got error: %v", err)
+	}
+	// Builds of the same source at different times must produce the same binary.
+	time.Sleep(1100 * time.Millisecond)
+	second, err := goBuildFlags(nil, false)
+	if err != nil {
+		t.Fatalf("goBuildFlags() got error: %v", err)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("goBuildFlags() = %v, then %v, want identical flags", first, second)
+	}
+}
+
+func TestBuildVersion(t *testing.T) {
+	oldEnv := os.Environ()
+	t.Cleanup(func() {
+		clearAndSetEnv(oldEnv)
+	})
+	testCases := []struct {
+		name    string
+		env     []string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "not set",
+			want: "",
+		},
+		{
+			name: "SOURCE_DATE_EPOCH",
+			env:  []string{"SOURCE_DATE_EPOCH=1600000000"},
+			want: "20200913T122640Z",
+		},
+		{
+			name:    "invalid SOURCE_DATE_EPOCH",
+			env:     []string{"SOURCE_DATE_EPOCH=yesterday"},
+			wantErr: true,
+		},
+		{
+			name: "commit label",
+			env:  []string{"GOOGLE_LABEL_COMMIT_SHA=abc123"},
+			want: "abc123",
+		},
+		{
+			name: "version label takes precedence",
+			env:  []string{"GOOGLE_LABEL_COMMIT_SHA=abc123", "GOOGLE_LABEL_VERSION=1.2.3"},
+			want: "1.2.3",
+		},
+		{
+			name: "GOOGLE_GO_BUILD_VERSION takes precedence",
+			env:  []string{"GOOGLE_LABEL_VERSION=1.2.3", "GOOGLE_GO_BUILD_VERSION=v2"},
+			want: "v2",
+		},
+		{
+			name:    "whitespace",
+			env:     []string{"GOOGLE_GO_BUILD_VERSION=v2 beta"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clearAndSetEnv(tc.env)
+			got, err := buildVersion()
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("buildVersion() got error: %v, want error: %t", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("buildVersion() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBinaries(t *testing.T) {
+	testCases := []struct {
+		name       string
+		buildables string
+		want       []binary
+		wantErr    bool
+	}{
+		{
+			name:       "single buildable",
+			buildables: "./cmd/server",
+			want:       []binary{{buildable: "./cmd/server", name: "main", path: "/bin/main"}},
+		},
+		{
+			name:       "multiple buildables",
+			buildables: " ./cmd/server, ./cmd/worker ,./jobs/cleanup.go,",
+			want: []binary{
+				{buildable: "./cmd/server", name: "main", path: "/bin/main"},
+				{buildable: "./cmd/worker", name: "worker", path: "/bin/worker"},
+				{buildable: "./jobs/cleanup.go", name: "cleanup", path: "/bin/cleanup"},
+			},
+		},
+		{
+			name:       "duplicate names",
+			buildables: ".,./cmd/worker,./internal/worker/",
+			wantErr:    true,
+		},
+		{
+			name:       "reserved name",
+			buildables: ".,./cmd/web",
+			wantErr:    true,
+		},
+		{
+			name:       "invalid process name",
+			buildables: ".,./cmd/worker.v2",
+			wantErr:    true,
+		},
+		{
+			name:       "current directory is not first",
+			buildables: "./cmd/server,.",
+			wantErr:    true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := binaries(parseBuildables(tc.buildables), "/bin")
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("binaries(%q) got error: %v, want error: %t", tc.buildables, err, tc.wantErr)
+			}
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("binaries(%q) = %+v, want %+v", tc.buildables, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCheckCommands(t *testing.T) {
+	oldEnv := os.Environ()
+	t.Cleanup(func() {
+		clearAndSetEnv(oldEnv)
+	})
+	testCases := []struct {
+		name     string
+		env      []string
+		tagFlags []string
+		want     [][]string
+		wantErr  bool
+	}{
+		{
+			name: "no checks",
+		},
+		{
+			name: "vet",
+			env:  []string{"GOOGLE_GO_VET=true"},
+			want: [][]string{{"go", "vet", "./..."}},
+		},
+		{
+			name:     "vet and test with tags",
+			env:      []string{"GOOGLE_GO_VET=true", "GOOGLE_GO_TEST=1"},
+			tagFlags: []string{"-tags", "netgo"},
+			want: [][]string{
+				{"go", "vet", "-tags", "netgo", "./..."},
+				{"go", "test", "-tags", "netgo", "./..."},
+			},
+		},
+		{
+			name: "test with packages and flags",
+			env:  []string{"GOOGLE_GO_TEST=true", "GOOGLE_GO_TEST_PACKAGES=./internal/... ./cmd/...", "GOOGLE_GO_TEST_FLAGS=-race -count=1"},
+			want: [][]string{{"go", "test", "-race", "-count=1", "./internal/...", "./cmd/..."}},
+		},
+		{
+			name:    "invalid value",
+			env:     []string{"GOOGLE_GO_TEST=sure"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clearAndSetEnv(tc.env)
+			got, err := checkCommands(tc.tagFlags)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("checkCommands() got error: %v, want error: %t", err, tc.wantErr)
+			}
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("checkCommands() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseEmbedAssets(t *testing.T) {
+	testCases := []struct {
+		name    string
+		val     string
+		want    []embedAsset
+		wantErr bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "multiple entries",
+			val:  "web/dist:internal/ui/dist/, /layers/assets:static ,",
+			want: []embedAsset{
+				{handoff: "web/dist", embed: "internal/ui/dist"},
+				{handoff: "/layers/assets", embed: "static"},
+			},
+		},
+		{
+			name:    "missing embed directory",
+			val:     "web/dist",
+			wantErr: true,
+		},
+		{
+			name:    "absolute embed directory",
+			val:     "web/dist:/srv/static",
+			wantErr: true,
+		},
+		{
+			name:    "embed directory outside of the application",
+			val:     "web/dist:ui/../../static",
+			wantErr: true,
+		},
+		{
+			name:    "application root",
+			val:     "web/dist:.",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseEmbedAssets(tc.val)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("parseEmbedAssets(%q) got error: %v, want error: %t", tc.val, err, tc.wantErr)
+			}
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("parseEmbedAssets(%q) = %+v, want %+v", tc.val, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPGOProfile(t *testing.T) {
+	testCases := []struct {
+		name      string
+		files     []string
+		path      string
+		buildable string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "no profile",
+			buildable: ".",
+		},
+		{
+			name:      "default.pgo in main package",
+			files:     []string{"cmd/server/default.pgo"},
+			buildable: "./cmd/server",
+			want:      "cmd/server/default.pgo",
+		},
+		{
+			name:      "default.pgo next to main file",
+			files:     []string{"default.pgo"},
+			buildable: "main.go",
+			want:      "default.pgo",
+		},
+		{
+			name:      "import path",
+			files:     []string{"default.pgo"},
+			buildable: "example.com/app",
+		},
+		{
+			name:      "explicit profile",
+			files:     []string{"default.pgo", "profiles/cpu.pprof"},
+			path:      "profiles/cpu.pprof",
+			buildable: ".",
+			want:      "profiles/cpu.pprof",
+		},
+		{
+			name:      "missing explicit profile",
+			path:      "profiles/cpu.pprof",
+			buildable: ".",
+			wantErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, f := range tc.files {
+				if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(f)), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, f), []byte("profile"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := pgoProfile(gcp.NewContext(gcp.WithApplicationRoot(dir)), tc.path, dir, tc.buildable)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("pgoProfile() got error: %v, want error: %t", err, tc.wantErr)
+			}
+			want := ""
+			if tc.want != "" {
+				want = filepath.Join(dir, tc.want)
+			}
+			if got != want {
+				t.Errorf("pgoProfile() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestBuildCommand(t *testing.T) {
+	got := buildCommand([]string{"-trimpath"}, binary{buildable: "./cmd/server", path: "/bin/main", pgo: "/app/default.pgo"})
+	want := []string{"go", "build", "-trimpath", "-pgo=/app/default.pgo", "-o", "/bin/main", "./cmd/server"}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("buildCommand() = %v, want %v", got, want)
+	}
+}
+
 func clearAndSetEnv(env []string) {
 	os.Clearenv()
 	for _, p := range env {
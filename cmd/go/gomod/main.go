@@ -13,12 +13,14 @@
 // limitations under the License.
 
 // Implements go/gomod buildpack.
-// The gomod buildpack downloads modules specified in go.mod.
+// The gomod buildpack downloads modules specified in go.mod, or in the go.mod files of all modules
+// of a Go workspace defined by go.work.
 package main
 
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/golang"
@@ -36,7 +38,14 @@ func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
 	if goModExists {
 		return gcp.OptInFileFound("go.mod"), nil
 	}
-	return gcp.OptOutFileNotFound("go.mod"), nil
+	goWorkExists, err := ctx.FileExists("go.work")
+	if err != nil {
+		return nil, err
+	}
+	if goWorkExists {
+		return gcp.OptInFileFound("go.work"), nil
+	}
+	return gcp.OptOut("neither go.mod nor go.work found"), nil
 }
 
 func buildFn(ctx *gcp.Context) error {
@@ -45,6 +54,20 @@ func buildFn(ctx *gcp.Context) error {
 		return fmt.Errorf("creating GOPATH layer: %w", err)
 	}
 
+	privateEnv, cleanup, err := golang.PrivateModuleEnv(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	workspace, err := golang.WorkspaceExists(ctx)
+	if err != nil {
+		return err
+	}
+	if workspace {
+		return downloadWorkspaceModules(ctx, l.Path, privateEnv)
+	}
+
 	vendorExists, err := ctx.FileExists("vendor")
 	if err != nil {
 		return err
@@ -58,7 +81,7 @@ func buildFn(ctx *gcp.Context) error {
 		}
 		if avSupport {
 			ctx.Logf("Not downloading modules because there's a `vendor` directory")
-			return nil
+			return golang.VerifyVendor(ctx, ctx.ApplicationRoot(), []string{"GOPATH=" + l.Path, "GO111MODULE=on"})
 		}
 
 		ctx.Warnf(`Ignoring "vendor" directory: To use vendor directory, the Go runtime must be 1.14+ and go.mod must contain a "go 1.14"+ entry. See https://cloud.google.com/appengine/docs/standard/go/specifying-dependencies#vendoring_dependencies.`)
@@ -73,7 +96,7 @@ func buildFn(ctx *gcp.Context) error {
 		//     go: updates to go.sum needed, disabled by -mod=readonly
 		return gcp.UserErrorf("go.mod exists but is not writable")
 	}
-	env := []string{"GOPATH=" + l.Path, "GO111MODULE=on"}
+	env := append([]string{"GOPATH=" + l.Path, "GO111MODULE=on"}, privateEnv...)
 
 	// BuildDirEnv should only be set by App Engine buildpacks.
 	workdir := os.Getenv(golang.BuildDirEnv)
@@ -100,3 +123,40 @@ func buildFn(ctx *gcp.Context) error {
 
 	return nil
 }
+
+// downloadWorkspaceModules downloads the dependencies of every module of the Go workspace. Each
+// module is handled on its own, with workspace mode disabled, because `go mod tidy` does not
+// support workspace mode. The workspace build list only selects versions required by the modules,
+// so all of them are downloaded. privateEnv configures the download of private modules.
+func downloadWorkspaceModules(ctx *gcp.Context, gopath string, privateEnv []string) error {
+	modules, err := golang.WorkspaceModules(ctx)
+	if err != nil {
+		return err
+	}
+	env := append([]string{"GOPATH=" + gopath, "GO111MODULE=on", "GOWORK=off"}, privateEnv...)
+	for _, m := range modules {
+		dir := filepath.Join(ctx.ApplicationRoot(), m)
+		goModExists, err := ctx.FileExists(dir, "go.mod")
+		if err != nil {
+			return err
+		}
+		if !goModExists {
+			return gcp.UserErrorf("go.work uses %q which does not contain a go.mod file", m)
+		}
+		goSumExists, err := ctx.FileExists(dir, "go.sum")
+		if err != nil {
+			return err
+		}
+		if !goSumExists {
+			ctx.Logf(`go.sum not found in %s, generating using "go mod tidy"`, m)
+			if _, err := golang.ExecWithGoproxyFallback(ctx, []string{"go", "mod", "tidy"}, gcp.WithEnv(env...), gcp.WithWorkDir(dir), gcp.WithUserAttribution); err != nil {
+				return fmt.Errorf("running go mod tidy in %s: %w", m, err)
+			}
+		}
+		ctx.Logf("Downloading modules for workspace module %s", m)
+		if _, err := golang.ExecWithGoproxyFallback(ctx, []string{"go", "mod", "download"}, gcp.WithEnv(env...), gcp.WithWorkDir(dir), gcp.WithUserAttribution); err != nil {
+			return fmt.Errorf("running go mod download in %s: %w", m, err)
+		}
+	}
+	return nil
+}
@@ -33,6 +33,15 @@ func TestDetect(t *testing.T) {
 			},
 			want: 0,
 		},
+		{
+			name: "with go.work",
+			files: map[string]string{
+				"go.work":     "go 1.20\n\nuse ./app\n",
+				"app/go.mod":  "",
+				"app/main.go": "",
+			},
+			want: 0,
+		},
 		{
 			name:  "without go.mod",
 			files: map[string]string{},
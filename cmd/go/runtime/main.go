@@ -21,10 +21,12 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	goruntime "runtime"
 	"strings"
 
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/golang"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/runtime"
 	"github.com/buildpacks/libcnb"
 )
@@ -32,14 +34,14 @@ import (
 const (
 	// goVersionURL is a URL to a JSON file that contains the latest Go version names.
 	goVersionURL = "https://golang.org/dl/?mode=json"
-	goURL        = "https://dl.google.com/go/go%s.linux-amd64.tar.gz"
+	goURL        = "https://dl.google.com/go/go%s.linux-%s.tar.gz"
 	goLayer      = "go"
 	versionKey   = "version"
 	envGoVersion = "GOOGLE_GO_VERSION"
 )
 
 func main() {
-	gcp.Main(detectFn, buildFn)
+	gcp.Main(detectFn, buildFn, gcp.WithFunctionSourceDir())
 }
 
 func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
@@ -76,7 +78,7 @@ func buildFn(ctx *gcp.Context) error {
 			return fmt.Errorf("clearing layer %q: %w", grl.Name, err)
 		}
 
-		archiveURL := fmt.Sprintf(goURL, version)
+		archiveURL := fmt.Sprintf(goURL, version, goruntime.GOARCH)
 		code, err := ctx.HTTPStatus(archiveURL)
 		if err != nil {
 			return err
@@ -93,6 +95,9 @@ func buildFn(ctx *gcp.Context) error {
 		}
 		ctx.SetMetadata(grl, versionKey, version)
 	}
+	// The installed toolchain already satisfies go.mod, prevent Go 1.21+ from switching toolchains,
+	// which would require downloading another one during the build.
+	grl.BuildEnvironment.Override("GOTOOLCHAIN", "local")
 
 	ctx.AddBOMEntry(libcnb.BOMEntry{
 		Name:     goLayer,
@@ -114,6 +119,14 @@ func runtimeVersion(ctx *gcp.Context) (string, error) {
 		ctx.Logf("Using runtime version from %s: %s", env.RuntimeVersion, version)
 		return version, nil
 	}
+	toolchain, err := golang.GoModToolchain(ctx)
+	if err != nil {
+		return "", err
+	}
+	if toolchain != "" {
+		ctx.Logf("Using runtime version from the go.mod toolchain directive: %s", toolchain)
+		return toolchain, nil
+	}
 	version, err := latestGoVersion(ctx)
 	if err != nil {
 		return "", fmt.Errorf("getting latest version: %w", err)
@@ -0,0 +1,194 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Implements java/agent buildpack.
+// The agent buildpack installs Java agents and attaches them to the application at launch.
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/fetch"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/java"
+	"github.com/buildpacks/libcnb"
+)
+
+const (
+	agentsLayer = "java-agents"
+	// agentsExecD is the name of the exec.d helper that attaches the agents at launch time.
+	agentsExecD = "java-agents"
+
+	openTelemetry = "opentelemetry"
+
+	// serviceEnv is set by Cloud Run and Cloud Functions.
+	serviceEnv = "K_SERVICE"
+	// otelServiceNameEnv is read by the OpenTelemetry agent as the name of the service.
+	otelServiceNameEnv = "OTEL_SERVICE_NAME"
+)
+
+// agent is a Java agent installed into a directory of the agents layer. Agents are pinned to a
+// release so that the layer can be cached by version.
+type agent struct {
+	version string
+	url     string
+	// file is the path of the agent in its directory.
+	file string
+}
+
+var agents = map[string]agent{
+	openTelemetry: {
+		version: "1.31.0",
+		url:     "https://github.com/open-telemetry/opentelemetry-java-instrumentation/releases/download/v%s/opentelemetry-javaagent.jar",
+		file:    "opentelemetry-javaagent.jar",
+	},
+}
+
+func main() {
+	gcp.Main(detectFn, buildFn, gcp.WithExecD(agentsExecD, attachAgents))
+}
+
+func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
+	if os.Getenv(env.JavaAgents) == "" {
+		return gcp.OptOutEnvNotSet(env.JavaAgents), nil
+	}
+	return gcp.OptInEnvSet(env.JavaAgents), nil
+}
+
+func buildFn(ctx *gcp.Context) error {
+	names, err := requestedAgents(os.Getenv(env.JavaAgents))
+	if err != nil {
+		return err
+	}
+	l, err := ctx.Layer(agentsLayer, gcp.LaunchLayer, gcp.CacheLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", agentsLayer, err)
+	}
+	for _, name := range names {
+		if err := installAgent(ctx, l, name, agents[name]); err != nil {
+			return err
+		}
+		ctx.SetMetadata(l, name, agents[name].version)
+	}
+	// Remove agents that are no longer requested, the exec.d helper attaches all installed agents.
+	for name := range agents {
+		if contains(names, name) {
+			continue
+		}
+		if err := ctx.RemoveAll(l.Path, name); err != nil {
+			return err
+		}
+		delete(l.Metadata, name)
+	}
+	ctx.Logf("Java agents %s will be attached to the application at launch.", strings.Join(names, ", "))
+	return ctx.AddExecD(l, agentsExecD)
+}
+
+// requestedAgents parses the comma-separated list of agents in GOOGLE_JAVA_AGENTS.
+func requestedAgents(val string) ([]string, error) {
+	var names []string
+	for _, name := range strings.Split(val, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || contains(names, name) {
+			continue
+		}
+		if _, ok := agents[name]; !ok {
+			var supported []string
+			for n := range agents {
+				supported = append(supported, n)
+			}
+			sort.Strings(supported)
+			return nil, gcp.UserErrorf("unsupported Java agent %q in %s, supported agents are: %s", name, env.JavaAgents, strings.Join(supported, ", "))
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// installAgent downloads the agent into its directory of the layer, unless the same version is
+// already cached.
+func installAgent(ctx *gcp.Context, l *libcnb.Layer, name string, a agent) error {
+	dir := filepath.Join(l.Path, name)
+	cached, err := ctx.FileExists(dir, a.file)
+	if err != nil {
+		return err
+	}
+	if cached && ctx.GetMetadata(l, name) == a.version {
+		ctx.CacheHit(name)
+		return nil
+	}
+	ctx.CacheMiss(name)
+	if err := ctx.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := ctx.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	url := fmt.Sprintf(a.url, a.version)
+	ctx.Logf("Installing Java agent %s v%s from %s", name, a.version, url)
+	f, err := ctx.CreateFile(filepath.Join(dir, a.file))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return fetch.GetURL(url, f)
+}
+
+// attachAgents is an exec.d helper that attaches the agents installed in its layer to the JVM.
+func attachAgents() (map[string]string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	// The helper is installed in the exec.d directory of the agents layer.
+	return agentsEnv(filepath.Dir(filepath.Dir(exe)))
+}
+
+// agentsEnv returns the launch environment that attaches the agents installed in the layer,
+// leaving user-provided options intact.
+func agentsEnv(layer string) (map[string]string, error) {
+	var opts []string
+	e := map[string]string{}
+	if path := filepath.Join(layer, openTelemetry, agents[openTelemetry].file); exists(path) {
+		opts = append(opts, "-javaagent:"+path)
+		if os.Getenv(otelServiceNameEnv) == "" && os.Getenv(serviceEnv) != "" {
+			e[otelServiceNameEnv] = os.Getenv(serviceEnv)
+		}
+	}
+	if len(opts) == 0 {
+		return nil, nil
+	}
+	existing := os.Getenv(java.JavaToolOptionsEnv)
+	e[java.JavaToolOptionsEnv] = strings.TrimSpace(existing + " " + strings.Join(opts, " "))
+	return e, nil
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	buildpacktest "github.com/GoogleCloudPlatform/buildpacks/internal/buildpacktest"
+)
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		env   []string
+		want  int
+	}{
+		{
+			name: "agents set",
+			env:  []string{"GOOGLE_JAVA_AGENTS=opentelemetry"},
+			want: 0,
+		},
+		{
+			name: "env not set",
+			want: 100,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buildpacktest.TestDetect(t, detectFn, tc.name, tc.files, tc.env, tc.want)
+		})
+	}
+}
+
+func TestRequestedAgents(t *testing.T) {
+	testCases := []struct {
+		val     string
+		want    []string
+		wantErr bool
+	}{
+		{val: "opentelemetry", want: []string{"opentelemetry"}},
+		{val: " OpenTelemetry ,", want: []string{"opentelemetry"}},
+		{val: "opentelemetry,opentelemetry", want: []string{"opentelemetry"}},
+		{val: "cloud-profiler", wantErr: true},
+		{val: "opentelemetry,newrelic", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.val, func(t *testing.T) {
+			got, err := requestedAgents(tc.val)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("requestedAgents(%q) got error %v, want error %t", tc.val, err, tc.wantErr)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("requestedAgents(%q) = %v, want %v", tc.val, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAgentsEnv(t *testing.T) {
+	testCases := []struct {
+		name   string
+		agents []string
+		env    map[string]string
+		want   map[string]string
+	}{
+		{
+			name: "no agents",
+		},
+		{
+			name:   "opentelemetry",
+			agents: []string{"opentelemetry/opentelemetry-javaagent.jar"},
+			env:    map[string]string{"K_SERVICE": "my-service"},
+			want: map[string]string{
+				"JAVA_TOOL_OPTIONS": "-javaagent:LAYER/opentelemetry/opentelemetry-javaagent.jar",
+				"OTEL_SERVICE_NAME": "my-service",
+			},
+		},
+		{
+			name:   "user service name",
+			agents: []string{"opentelemetry/opentelemetry-javaagent.jar"},
+			env:    map[string]string{"K_SERVICE": "my-service", "OTEL_SERVICE_NAME": "custom"},
+			want: map[string]string{
+				"JAVA_TOOL_OPTIONS": "-javaagent:LAYER/opentelemetry/opentelemetry-javaagent.jar",
+			},
+		},
+		{
+			name:   "existing options",
+			agents: []string{"opentelemetry/opentelemetry-javaagent.jar"},
+			env:    map[string]string{"JAVA_TOOL_OPTIONS": "-Xmx512M", "K_SERVICE": "svc"},
+			want: map[string]string{
+				"JAVA_TOOL_OPTIONS": "-Xmx512M -javaagent:LAYER/opentelemetry/opentelemetry-javaagent.jar",
+				"OTEL_SERVICE_NAME": "svc",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, k := range []string{"JAVA_TOOL_OPTIONS", "K_SERVICE", "OTEL_SERVICE_NAME"} {
+				t.Setenv(k, tc.env[k])
+			}
+			layer := t.TempDir()
+			for _, a := range tc.agents {
+				path := filepath.Join(layer, a)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := agentsEnv(layer)
+			if err != nil {
+				t.Fatalf("agentsEnv() got error: %v", err)
+			}
+			var want map[string]string
+			if tc.want != nil {
+				want = map[string]string{}
+				for k, v := range tc.want {
+					want[k] = strings.ReplaceAll(v, "LAYER", layer)
+				}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("agentsEnv() = %v, want %v", got, want)
+			}
+		})
+	}
+}
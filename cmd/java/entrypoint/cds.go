@@ -0,0 +1,106 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+const (
+	cdsLayer   = "cds"
+	cdsArchive = "application.jsa"
+	// trainingSeconds is the maximum duration of the training run. Applications that do not exit by
+	// themselves are stopped with SIGTERM, the JVM writes the archive during the orderly shutdown.
+	trainingSeconds = 60
+	// killAfterSeconds is the time after SIGTERM that an application that has not exited is killed.
+	killAfterSeconds = 10
+)
+
+// cdsEnabled returns true if the class data sharing archive is requested with GOOGLE_JAVA_CDS.
+// The archive is specific to the JDK it is created with, so it is not created when the JDK is
+// replaced by a jlink runtime.
+func cdsEnabled(ctx *gcp.Context) (bool, error) {
+	enabled, err := env.IsPresentAndTrue(env.JavaCDS)
+	if err != nil {
+		return false, gcp.UserErrorf("%v", err)
+	}
+	if !enabled {
+		return false, nil
+	}
+	jlink, err := env.IsPresentAndTrue(env.JavaJlink)
+	if err != nil {
+		return false, gcp.UserErrorf("%v", err)
+	}
+	if jlink {
+		ctx.Warnf("%s is not supported with %s, the class data sharing archive is not created.", env.JavaCDS, env.JavaJlink)
+		return false, nil
+	}
+	return true, nil
+}
+
+// addCDSArchive runs the java command once with -XX:ArchiveClassesAtExit to create a dynamic
+// class data sharing archive of the classes loaded by the application, and returns the command
+// with the flags that use the archive. Spring Boot applications exit after the application
+// context is refreshed. The command is returned unchanged if the archive is not created, the
+// archive only reduces the startup time.
+func addCDSArchive(ctx *gcp.Context, command []string) ([]string, error) {
+	if len(command) == 0 || command[0] != "java" {
+		ctx.Warnf("The application is not started with the java command, the class data sharing archive is not created.")
+		return command, nil
+	}
+	l, err := ctx.Layer(cdsLayer, gcp.LaunchLayer)
+	if err != nil {
+		return nil, fmt.Errorf("creating %v layer: %w", cdsLayer, err)
+	}
+	if err := ctx.ClearLayer(l); err != nil {
+		return nil, fmt.Errorf("clearing layer %q: %w", l.Name, err)
+	}
+	archive := filepath.Join(l.Path, cdsArchive)
+
+	ctx.Logf("Creating the class data sharing archive with a training run of the application.")
+	if _, err := ctx.Exec(trainingCommand(command, archive), gcp.WithUserAttribution); err != nil {
+		ctx.Debugf("Training run failed: %v", err)
+	}
+	exists, err := ctx.FileExists(archive)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		ctx.Warnf("The training run did not create the class data sharing archive, the application starts without it.")
+		return command, nil
+	}
+	return cdsCommand(command, archive), nil
+}
+
+// trainingCommand returns the command of the training run, which is stopped if the application
+// does not exit by itself.
+func trainingCommand(command []string, archive string) []string {
+	training := []string{
+		"timeout", "--signal=TERM", "--kill-after=" + strconv.Itoa(killAfterSeconds), strconv.Itoa(trainingSeconds),
+		"java", "-XX:ArchiveClassesAtExit=" + archive, "-Dspring.context.exit=onRefresh",
+	}
+	return append(training, command[1:]...)
+}
+
+// cdsCommand returns the java command with the flags that use the archive. -Xshare:auto starts the
+// JVM without the archive if it cannot be used.
+func cdsCommand(command []string, archive string) []string {
+	return append([]string{"java", "-XX:SharedArchiveFile=" + archive, "-Xshare:auto"}, command[1:]...)
+}
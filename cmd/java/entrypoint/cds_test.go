@@ -0,0 +1,96 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+func TestCDSEnabled(t *testing.T) {
+	testCases := []struct {
+		name    string
+		cds     string
+		jlink   string
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "not set",
+		},
+		{
+			name: "enabled",
+			cds:  "true",
+			want: true,
+		},
+		{
+			name: "disabled",
+			cds:  "false",
+		},
+		{
+			name:  "enabled with jlink",
+			cds:   "true",
+			jlink: "true",
+		},
+		{
+			name:    "invalid",
+			cds:     "yes please",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			setenv(t, "GOOGLE_JAVA_CDS", tc.cds)
+			setenv(t, "GOOGLE_JAVA_JLINK", tc.jlink)
+
+			got, err := cdsEnabled(gcp.NewContext())
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("cdsEnabled() got error %v, want error %t", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("cdsEnabled() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+// setenv sets the env var for the test, or unsets it if the value is empty.
+func setenv(t *testing.T, name, value string) {
+	t.Helper()
+	t.Setenv(name, value)
+	if value == "" {
+		os.Unsetenv(name)
+	}
+}
+
+func TestCDSCommands(t *testing.T) {
+	command := []string{"java", "-jar", "/workspace/target/app.jar"}
+	archive := "/layers/google.java.entrypoint/cds/application.jsa"
+
+	wantTraining := []string{
+		"timeout", "--signal=TERM", "--kill-after=10", "60",
+		"java", "-XX:ArchiveClassesAtExit=" + archive, "-Dspring.context.exit=onRefresh", "-jar", "/workspace/target/app.jar",
+	}
+	if got := trainingCommand(command, archive); !reflect.DeepEqual(got, wantTraining) {
+		t.Errorf("trainingCommand() = %v, want %v", got, wantTraining)
+	}
+	want := []string{"java", "-XX:SharedArchiveFile=" + archive, "-Xshare:auto", "-jar", "/workspace/target/app.jar"}
+	if got := cdsCommand(command, archive); !reflect.DeepEqual(got, want) {
+		t.Errorf("cdsCommand() = %v, want %v", got, want)
+	}
+}
@@ -17,12 +17,29 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/cache"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/devmode"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/java"
 )
 
+const (
+	// layerPrefix is the prefix of the layers with the contents of Spring Boot layers.
+	layerPrefix = "spring-boot-"
+	// quarkusLayerPrefix and micronautLayerPrefix are the prefixes of the layers with the
+	// dependencies of Quarkus and Micronaut applications.
+	quarkusLayerPrefix   = "quarkus-lib-"
+	micronautLayerPrefix = "micronaut-"
+	digestKey            = "digest"
+	classesLayer         = "classes"
+)
+
 func main() {
 	gcp.Main(detectFn, buildFn)
 }
@@ -32,12 +49,40 @@ func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
 }
 
 func buildFn(ctx *gcp.Context) error {
-	executable, err := java.ExecutableJar(ctx)
+	quarkusApp, err := java.QuarkusApp(ctx)
 	if err != nil {
-		return fmt.Errorf("finding executable jar: %w", err)
+		return err
+	}
+	micronautLayers, err := java.MicronautLayers(ctx)
+	if err != nil {
+		return err
+	}
+	var executable string
+	var command []string
+	switch {
+	case quarkusApp != "":
+		executable = filepath.Join(quarkusApp, java.QuarkusRunJar)
+	case micronautLayers != "":
+		if executable, err = java.MicronautApplicationJar(ctx, micronautLayers); err != nil {
+			return err
+		}
+	default:
+		jar, err := java.ExecutableJar(ctx)
+		if err != nil {
+			app, appErr := applicationCommand(ctx)
+			if appErr != nil {
+				return appErr
+			}
+			if app == nil {
+				return fmt.Errorf("finding executable jar: %w", err)
+			}
+			command = app
+		}
+		executable = jar
+	}
+	if command == nil {
+		command = []string{"java", "-jar", executable}
 	}
-
-	command := []string{"java", "-jar", executable}
 
 	// Configure the entrypoint and metadata for dev mode.
 	if devmode.Enabled(ctx) {
@@ -54,6 +99,293 @@ func buildFn(ctx *gcp.Context) error {
 	}
 
 	// Configure the entrypoint for production.
+	cds, err := cdsEnabled(ctx)
+	if err != nil {
+		return err
+	}
+	var layered []string
+	switch {
+	case executable == "":
+		// The application is not run from an executable jar.
+	case quarkusApp != "":
+		layered, err = quarkusLayers(ctx, quarkusApp)
+	case micronautLayers != "":
+		layered, err = micronautLayersCommand(ctx, micronautLayers, executable)
+	default:
+		if err := addClassCount(ctx, executable); err != nil {
+			return err
+		}
+		layered, err = springBootLayers(ctx, executable)
+	}
+	if err != nil {
+		return err
+	}
+	if layered != nil {
+		command = layered
+	}
+	if cds {
+		if command, err = addCDSArchive(ctx, command); err != nil {
+			return err
+		}
+	}
 	ctx.AddWebProcess(command)
 	return nil
 }
+
+// applicationCommand returns the command that runs an application that does not build an
+// executable jar: the start script of the Gradle application plugin distribution, or the main
+// class declared in the build configuration. It returns nil if there is neither.
+func applicationCommand(ctx *gcp.Context) ([]string, error) {
+	script, err := java.ApplicationStartScript(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if script != "" {
+		ctx.Logf("Running the application with the start script %s.", script)
+		return []string{script}, nil
+	}
+	declared, err := java.DeclaredMainClass(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if declared == "" {
+		return nil, nil
+	}
+	jar, err := java.ApplicationJar(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if jar == "" {
+		return nil, nil
+	}
+	main, err := java.ResolveMainClass(jar, declared)
+	if err != nil {
+		return nil, err
+	}
+	if main == "" {
+		ctx.Warnf("Main class %s declared in the build configuration is not in %s.", declared, jar)
+		return nil, nil
+	}
+	ctx.Logf("Running the main class %s declared in the build configuration from %s.", main, jar)
+	return []string{"java", "-cp", jar, main}, nil
+}
+
+// addClassCount sets the number of classes of the application for the JVM memory calculator of
+// the google.java.runtime buildpack, which otherwise counts the classes at launch time.
+func addClassCount(ctx *gcp.Context, jars ...string) error {
+	count := 0
+	for _, jar := range jars {
+		n, err := java.CountClasses(jar)
+		if err != nil {
+			ctx.Warnf("Unable to count the classes of %s: %v", jar, err)
+			return nil
+		}
+		count += n
+	}
+	l, err := ctx.Layer(classesLayer, gcp.LaunchLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", classesLayer, err)
+	}
+	l.LaunchEnvironment.Default(env.JavaClassCount, strconv.Itoa(count))
+	return nil
+}
+
+// springBootLayers extracts the layers of a layered Spring Boot fat jar into separate image layers
+// and returns the command that runs the application from them. Layers are reused from the previous
+// image when their contents do not change, e.g. dependencies when only the application changes.
+// It returns nil if the jar is not a layered Spring Boot jar.
+func springBootLayers(ctx *gcp.Context, jar string) ([]string, error) {
+	if v, ok := os.LookupEnv(env.JavaSpringBootLayers); ok {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, gcp.UserErrorf("parsing %s: %v", env.JavaSpringBootLayers, err)
+		}
+		if !enabled {
+			return nil, nil
+		}
+	}
+	startClass, err := java.FindManifestValueFromJar(jar, java.SpringBootStartClassKey)
+	if err != nil {
+		return nil, err
+	}
+	if startClass == "" {
+		return nil, nil
+	}
+	index, err := java.JarEntry(jar, java.SpringBootLayersIndex)
+	if err != nil {
+		return nil, err
+	}
+	if index == nil {
+		ctx.Logf("Spring Boot jar %s is not layered, running it with java -jar.", jar)
+		return nil, nil
+	}
+
+	extracted, err := ctx.TempDir("spring-boot-layers")
+	if err != nil {
+		return nil, fmt.Errorf("creating temp directory: %w", err)
+	}
+	if _, err := ctx.Exec([]string{"java", "-Djarmode=layertools", "-jar", jar, "extract", "--destination", extracted}, gcp.WithUserAttribution); err != nil {
+		return nil, err
+	}
+
+	// The application classes come first, followed by the dependency jars of each layer. The layer
+	// contents are checked in the extracted directory, layers are not restored when they are reused.
+	var classes, libs []string
+	for _, name := range java.ParseLayersIndex(index) {
+		src := filepath.Join(extracted, name)
+		if exists, err := ctx.FileExists(src); err != nil {
+			return nil, err
+		} else if !exists {
+			// Layers without contents, e.g. snapshot-dependencies, are not extracted.
+			continue
+		}
+		path, err := contributeLayer(ctx, layerPrefix+name, src)
+		if err != nil {
+			return nil, err
+		}
+		if exists, err := ctx.FileExists(src, "BOOT-INF", "classes"); err != nil {
+			return nil, err
+		} else if exists {
+			classes = append(classes, filepath.Join(path, "BOOT-INF", "classes"))
+		}
+		if exists, err := ctx.FileExists(src, "BOOT-INF", "lib"); err != nil {
+			return nil, err
+		} else if exists {
+			libs = append(libs, filepath.Join(path, "BOOT-INF", "lib", "*"))
+		}
+	}
+	classpath := strings.Join(append(classes, libs...), string(filepath.ListSeparator))
+	return []string{"java", "-cp", classpath, startClass}, nil
+}
+
+// quarkusLayers moves the dependencies of the Quarkus fast-jar application into launch layers
+// that are reused from the previous image when the dependencies do not change, and returns the
+// command that runs the application. The dependency directories of the application are replaced
+// with links to the layers, the application resolves its dependencies relative to its directory.
+func quarkusLayers(ctx *gcp.Context, app string) ([]string, error) {
+	jars, err := ctx.Glob(filepath.Join(app, "*", "*.jar"))
+	if err != nil {
+		return nil, fmt.Errorf("finding jars: %w", err)
+	}
+	libJars, err := ctx.Glob(filepath.Join(app, "lib", "*", "*.jar"))
+	if err != nil {
+		return nil, fmt.Errorf("finding jars: %w", err)
+	}
+	if err := addClassCount(ctx, append(append(jars, libJars...), filepath.Join(app, java.QuarkusRunJar))...); err != nil {
+		return nil, err
+	}
+	for _, dir := range java.QuarkusLibDirs {
+		src := filepath.Join(app, dir)
+		if exists, err := ctx.FileExists(src); err != nil {
+			return nil, err
+		} else if !exists {
+			continue
+		}
+		path, err := contributeLayer(ctx, quarkusLayerPrefix+filepath.Base(dir), src)
+		if err != nil {
+			return nil, err
+		}
+		if err := ctx.RemoveAll(src); err != nil {
+			return nil, err
+		}
+		if err := os.Symlink(path, src); err != nil {
+			return nil, gcp.InternalErrorf("linking %s to %s: %v", src, path, err)
+		}
+	}
+	return []string{"java", "-jar", filepath.Join(app, java.QuarkusRunJar)}, nil
+}
+
+// micronautLayersCommand copies the dependencies of the Micronaut layers layout into launch
+// layers that are reused from the previous image when the dependencies do not change, and returns
+// the command that runs the application jar with the dependencies on the class path.
+func micronautLayersCommand(ctx *gcp.Context, layers, jar string) ([]string, error) {
+	mainClass, err := java.MainManifestEntry(jar)
+	if err != nil {
+		return nil, err
+	}
+	if mainClass == "" {
+		return nil, gcp.UserErrorf("%s does not have a Main-Class manifest entry", jar)
+	}
+	jars := []string{jar}
+	var classpath []string
+	if exists, err := ctx.FileExists(layers, "resources"); err != nil {
+		return nil, err
+	} else if exists {
+		classpath = append(classpath, filepath.Join(layers, "resources"))
+	}
+	classpath = append(classpath, jar)
+	for _, dir := range java.MicronautLibDirs {
+		src := filepath.Join(layers, dir)
+		if exists, err := ctx.FileExists(src); err != nil {
+			return nil, err
+		} else if !exists {
+			continue
+		}
+		libJars, err := ctx.Glob(filepath.Join(src, "*.jar"))
+		if err != nil {
+			return nil, fmt.Errorf("finding jars: %w", err)
+		}
+		jars = append(jars, libJars...)
+		path, err := contributeLayer(ctx, micronautLayerPrefix+strings.ReplaceAll(dir, "_", "-"), src)
+		if err != nil {
+			return nil, err
+		}
+		classpath = append(classpath, filepath.Join(path, "*"))
+	}
+	if err := addClassCount(ctx, jars...); err != nil {
+		return nil, err
+	}
+	return []string{"java", "-cp", strings.Join(classpath, string(filepath.ListSeparator)), mainClass}, nil
+}
+
+// contributeLayer copies the directory into a launch layer unless the previous image contains a
+// layer with the same contents, and returns the path of the layer.
+func contributeLayer(ctx *gcp.Context, layerName, src string) (string, error) {
+	l, err := ctx.Layer(layerName, gcp.LaunchLayer)
+	if err != nil {
+		return "", fmt.Errorf("creating %v layer: %w", layerName, err)
+	}
+	digest, err := dirDigest(ctx, src)
+	if err != nil {
+		return "", err
+	}
+	// The contents are needed at build time for the training run of the class data sharing archive.
+	cds, err := env.IsPresentAndTrue(env.JavaCDS)
+	if err != nil {
+		return "", gcp.UserErrorf("%v", err)
+	}
+	if ctx.GetMetadata(l, digestKey) == digest && !cds {
+		ctx.CacheHit(layerName)
+		return l.Path, nil
+	}
+	ctx.CacheMiss(layerName)
+	if err := ctx.ClearLayer(l); err != nil {
+		return "", fmt.Errorf("clearing layer %q: %w", l.Name, err)
+	}
+	if _, err := ctx.Exec([]string{"cp", "--archive", src + "/.", l.Path}); err != nil {
+		return "", err
+	}
+	ctx.SetMetadata(l, digestKey, digest)
+	return l.Path, nil
+}
+
+// dirDigest returns a hash of the names and contents of the files in a directory.
+func dirDigest(ctx *gcp.Context, dir string) (string, error) {
+	var names, files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		names = append(names, rel)
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		return "", gcp.InternalErrorf("walking %s: %v", dir, err)
+	}
+	return cache.Hash(ctx, cache.WithStrings(names...), cache.WithFiles(files...))
+}
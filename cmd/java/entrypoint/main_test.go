@@ -15,12 +15,189 @@
 package main
 
 import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 
 	buildpacktest "github.com/GoogleCloudPlatform/buildpacks/internal/buildpacktest"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/buildpacks/libcnb"
 )
 
 func TestDetect(t *testing.T) {
 	// The buildpack always opts in.
 	buildpacktest.TestDetect(t, detectFn, "no files", map[string]string{}, []string{}, 0)
 }
+
+func TestSpringBootLayersNotLayered(t *testing.T) {
+	testCases := []struct {
+		name     string
+		manifest string
+		files    map[string]string
+		env      string
+	}{
+		{
+			name:     "plain jar",
+			manifest: "Main-Class: com.example.Main\n",
+		},
+		{
+			name:     "spring boot jar without layers index",
+			manifest: "Main-Class: org.springframework.boot.loader.JarLauncher\nStart-Class: com.example.Main\n",
+		},
+		{
+			name:     "layers disabled",
+			manifest: "Main-Class: org.springframework.boot.loader.JarLauncher\nStart-Class: com.example.Main\n",
+			files:    map[string]string{"BOOT-INF/layers.idx": "- \"application\":\n"},
+			env:      "false",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.env != "" {
+				t.Setenv("GOOGLE_JAVA_SPRING_BOOT_LAYERS", tc.env)
+			}
+			jar := writeJar(t, tc.manifest, tc.files)
+
+			got, err := springBootLayers(gcp.NewContext(), jar)
+			if err != nil {
+				t.Fatalf("springBootLayers() got error: %v", err)
+			}
+			if got != nil {
+				t.Errorf("springBootLayers() = %v, want nil", got)
+			}
+		})
+	}
+}
+
+func TestDirDigest(t *testing.T) {
+	ctx := gcp.NewContext()
+	digest := func(files map[string]string) string {
+		t.Helper()
+		dir := t.TempDir()
+		for name, content := range files {
+			path := filepath.Join(dir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		d, err := dirDigest(ctx, dir)
+		if err != nil {
+			t.Fatalf("dirDigest() got error: %v", err)
+		}
+		return d
+	}
+
+	base := digest(map[string]string{"BOOT-INF/lib/a.jar": "a", "BOOT-INF/lib/b.jar": "b"})
+	if got := digest(map[string]string{"BOOT-INF/lib/a.jar": "a", "BOOT-INF/lib/b.jar": "b"}); got != base {
+		t.Errorf("dirDigest() of identical directories = %q, want %q", got, base)
+	}
+	if got := digest(map[string]string{"BOOT-INF/lib/a.jar": "a", "BOOT-INF/lib/c.jar": "b"}); got == base {
+		t.Errorf("dirDigest() of renamed file = %q, want a different digest", got)
+	}
+	if got := digest(map[string]string{"BOOT-INF/lib/a.jar": "a", "BOOT-INF/lib/b.jar": "changed"}); got == base {
+		t.Errorf("dirDigest() of changed file = %q, want a different digest", got)
+	}
+}
+
+func TestQuarkusLayers(t *testing.T) {
+	app := filepath.Join(t.TempDir(), "quarkus-app")
+	writeFiles(t, app, map[string]string{
+		"quarkus-run.jar":      "",
+		"app/app.jar":          "",
+		"lib/boot/boot.jar":    "",
+		"lib/main/library.jar": "",
+	})
+	layers := t.TempDir()
+	ctx := gcp.NewContext(gcp.WithBuildContext(libcnb.BuildContext{Layers: libcnb.Layers{Path: layers}}))
+
+	got, err := quarkusLayers(ctx, app)
+	if err != nil {
+		t.Fatalf("quarkusLayers() got error: %v", err)
+	}
+	want := []string{"java", "-jar", filepath.Join(app, "quarkus-run.jar")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("quarkusLayers() = %v, want %v", got, want)
+	}
+	for _, dir := range []string{"boot", "main"} {
+		link, err := os.Readlink(filepath.Join(app, "lib", dir))
+		if err != nil {
+			t.Fatalf("reading link of lib/%s: %v", dir, err)
+		}
+		if want := filepath.Join(layers, "quarkus-lib-"+dir); link != want {
+			t.Errorf("lib/%s links to %q, want %q", dir, link, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(app, "lib", "main", "library.jar")); err != nil {
+		t.Errorf("library.jar is missing from the layer: %v", err)
+	}
+}
+
+func TestMicronautLayersCommand(t *testing.T) {
+	layers := filepath.Join(t.TempDir(), "layers")
+	writeFiles(t, layers, map[string]string{
+		"libs/library.jar":          "",
+		"project_libs/project.jar":  "",
+		"resources/application.yml": "",
+	})
+	jar := writeJar(t, "Main-Class: com.example.Application\n", nil)
+	cnbLayers := t.TempDir()
+	ctx := gcp.NewContext(gcp.WithBuildContext(libcnb.BuildContext{Layers: libcnb.Layers{Path: cnbLayers}}))
+
+	got, err := micronautLayersCommand(ctx, layers, jar)
+	if err != nil {
+		t.Fatalf("micronautLayersCommand() got error: %v", err)
+	}
+	classpath := filepath.Join(layers, "resources") + ":" + jar + ":" + filepath.Join(cnbLayers, "micronaut-libs", "*") + ":" + filepath.Join(cnbLayers, "micronaut-project-libs", "*")
+	want := []string{"java", "-cp", classpath, "com.example.Application"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("micronautLayersCommand() = %v, want %v", got, want)
+	}
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func writeJar(t *testing.T, manifest string, files map[string]string) string {
+	t.Helper()
+	jar := filepath.Join(t.TempDir(), "app.jar")
+	f, err := os.Create(jar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	entries := map[string]string{"META-INF/MANIFEST.MF": manifest}
+	for name, content := range files {
+		entries[name] = content
+	}
+	for name, content := range entries {
+		e, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := e.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return jar
+}
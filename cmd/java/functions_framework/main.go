@@ -20,8 +20,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/cloudfunctions"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/java"
@@ -31,20 +33,24 @@ import (
 const (
 	layerName                     = "functions-framework"
 	javaFunctionInvokerURLBase    = "https://maven-central.storage-download.googleapis.com/maven2/com/google/cloud/functions/invoker/java-function-invoker/"
-	defaultFrameworkVersion       = "1.1.0"
 	functionsFrameworkURLTemplate = javaFunctionInvokerURLBase + "%[1]s/java-function-invoker-%[1]s.jar"
-	versionKey                    = "version"
 	invokerMain                   = "com.google.cloud.functions.invoker.runner.Invoker"
 )
 
+// javaVersionRegexp matches the major version in the output of `java -version`.
+var javaVersionRegexp = regexp.MustCompile(`version "(?:1\.)?(\d+)`)
+
 func main() {
-	gcp.Main(detectFn, buildFn)
+	gcp.Main(detectFn, buildFn, gcp.WithFunctionSourceDir())
 }
 
 func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
 	if _, ok := os.LookupEnv(env.FunctionTarget); ok {
 		return gcp.OptInEnvSet(env.FunctionTarget), nil
 	}
+	if _, ok := os.LookupEnv(env.FunctionTargets); ok {
+		return gcp.OptInEnvSet(env.FunctionTargets), nil
+	}
 	return gcp.OptOutEnvNotSet(env.FunctionTarget), nil
 }
 
@@ -74,18 +80,26 @@ func buildFn(ctx *gcp.Context) error {
 	// Success here doesn't guarantee that the function will execute. It might not implement one of the
 	// required interfaces, for example. But it eliminates the commonest problem of specifying the wrong target.
 	// We use an ExecUser* method so that the time taken by the javap command is counted as user time.
-	target := os.Getenv(env.FunctionTarget)
-	if result, err := ctx.Exec([]string{"javap", "-classpath", classpath, target}, gcp.WithUserAttribution); err != nil {
-		// The javap error output will typically be "Error: class not found: foo.Bar".
-		return gcp.UserErrorf("build succeeded but did not produce the class %q specified as the function target: %s", target, result.Combined)
+	targets, err := gcp.FunctionTargets()
+	if err != nil {
+		return err
+	}
+	for _, target := range targets {
+		if result, err := ctx.Exec([]string{"javap", "-classpath", classpath, target}, gcp.WithUserAttribution); err != nil {
+			// The javap error output will typically be "Error: class not found: foo.Bar".
+			return gcp.UserErrorf("build succeeded but did not produce the class %q specified as the function target: %s", target, result.Combined)
+		}
 	}
 
 	launcherSource := filepath.Join(ctx.BuildpackRoot(), "launch.sh")
 	launcherTarget := filepath.Join(layer.Path, "launch.sh")
 	createLauncher(ctx, launcherSource, launcherTarget)
-	ctx.AddWebProcess([]string{launcherTarget, "java", "-jar", ffPath, "--classpath", classpath})
-
-	return nil
+	args, err := cloudfunctions.JavaFramework.Args()
+	if err != nil {
+		return err
+	}
+	cmd := []string{launcherTarget, "java", "-jar", ffPath, "--classpath", classpath}
+	return ctx.AddFunctionProcesses(append(cmd, args...))
 }
 
 func createLauncher(ctx *gcp.Context, launcherSource, launcherTarget string) error {
@@ -269,6 +283,9 @@ func installFunctionsFramework(ctx *gcp.Context, layer *libcnb.Layer) (string, e
 		}
 	}
 	if len(jars) == 1 && isInvokerJar(ctx, jars[0]) {
+		if os.Getenv(cloudfunctions.JavaFramework.VersionEnv) != "" {
+			ctx.Warnf("Ignoring %s because the function already depends on %s.", cloudfunctions.JavaFramework.VersionEnv, cloudfunctions.JavaFramework.Package)
+		}
 		if err := ctx.ClearLayer(layer); err != nil {
 			return "", fmt.Errorf("clearing layer %q: %w", layer.Name, err)
 		}
@@ -277,11 +294,23 @@ func installFunctionsFramework(ctx *gcp.Context, layer *libcnb.Layer) (string, e
 		return jars[0], nil
 	}
 
-	frameworkVersion := defaultFrameworkVersion
+	frameworkVersion, pinned, err := cloudfunctions.JavaFramework.Version()
+	if err != nil {
+		return "", err
+	}
+	if pinned {
+		ctx.Logf("Using %s %s from %s.", cloudfunctions.JavaFramework.Package, frameworkVersion, cloudfunctions.JavaFramework.VersionEnv)
+	}
+	javaVer, err := javaMajorVersion(ctx)
+	if err != nil {
+		return "", err
+	}
+	if err := cloudfunctions.JavaFramework.CheckRuntime(ctx, frameworkVersion, javaVer); err != nil {
+		return "", err
+	}
 
 	// Install functions-framework.
-	metaVersion := ctx.GetMetadata(layer, versionKey)
-	if frameworkVersion == metaVersion {
+	if frameworkVersion == cloudfunctions.InstalledVersion(ctx, layer) {
 		ctx.CacheHit(layerName)
 	} else {
 		ctx.CacheMiss(layerName)
@@ -291,7 +320,7 @@ func installFunctionsFramework(ctx *gcp.Context, layer *libcnb.Layer) (string, e
 		if err := installFramework(ctx, layer, frameworkVersion); err != nil {
 			return "", err
 		}
-		ctx.SetMetadata(layer, versionKey, frameworkVersion)
+		cloudfunctions.RecordVersion(ctx, layer, frameworkVersion)
 	}
 	return filepath.Join(layer.Path, "functions-framework.jar"), nil
 }
@@ -300,8 +329,26 @@ func installFunctionsFramework(ctx *gcp.Context, layer *libcnb.Layer) (string, e
 // that the manifest's Main-Class matches an expected value.
 func isInvokerJar(ctx *gcp.Context, jar string) bool {
 	main, err := java.MainManifestEntry(jar)
-	ctx.Warnf("Failed to identify functions framework invoker dependency. Installing version %s:\n%v", defaultFrameworkVersion, err)
-	return err == nil && main == invokerMain
+	if err != nil {
+		ctx.Warnf("Failed to identify functions framework invoker dependency, installing %s instead:\n%v", cloudfunctions.JavaFramework.Package, err)
+		return false
+	}
+	return main == invokerMain
+}
+
+// javaMajorVersion returns the major version of the installed JDK, e.g. 17 for 17.0.2 and 8 for
+// 1.8.0_292, or an empty string if it cannot be determined.
+func javaMajorVersion(ctx *gcp.Context) (string, error) {
+	result, err := ctx.Exec([]string{"java", "-version"})
+	if err != nil {
+		return "", err
+	}
+	// `java -version` prints e.g. `openjdk version "17.0.2" 2022-01-18` to stderr.
+	m := javaVersionRegexp.FindStringSubmatch(result.Stderr)
+	if m == nil {
+		return "", nil
+	}
+	return m[1], nil
 }
 
 // installFramework downloads the functions framework invoker jar and saves it in the provided layer.
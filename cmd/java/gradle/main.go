@@ -23,10 +23,12 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/cache"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/devmode"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/java"
+	"github.com/buildpacks/libcnb"
 )
 
 const (
@@ -34,28 +36,40 @@ const (
 	gradleLayer     = "gradle"
 	cacheLayer      = "cache"
 	versionKey      = "version"
+	cacheKeyKey     = "cache_key"
+	defaultTask     = "assemble"
+	installDistTask = "installDist"
+)
+
+var (
+	// cacheKeyFiles are the files that determine the build and dependencies resolved by Gradle,
+	// in addition to the dependency lock files.
+	cacheKeyFiles = []string{
+		"settings.gradle",
+		"settings.gradle.kts",
+		"gradle.properties",
+		"gradle/wrapper/gradle-wrapper.properties",
+		"gradle/libs.versions.toml",
+	}
+	// buildFiles are the files that identify a Gradle build.
+	buildFiles = []string{"build.gradle", "build.gradle.kts", "settings.gradle", "settings.gradle.kts"}
 )
 
 func main() {
-	gcp.Main(detectFn, buildFn)
+	gcp.Main(detectFn, buildFn, gcp.WithFunctionSourceDir())
 }
 
 func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
-	buildGradleExists, err := ctx.FileExists("build.gradle")
-	if err != nil {
-		return nil, err
-	}
-	if buildGradleExists {
-		return gcp.OptInFileFound("build.gradle"), nil
-	}
-	buildGradleKTSExists, err := ctx.FileExists("build.gradle.kts")
-	if err != nil {
-		return nil, err
-	}
-	if buildGradleKTSExists {
-		return gcp.OptInFileFound("build.gradle.kts"), nil
+	for _, f := range buildFiles {
+		exists, err := ctx.FileExists(f)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return gcp.OptInFileFound(f), nil
+		}
 	}
-	return gcp.OptOut("neither build.gradle nor build.gradle.kts found"), nil
+	return gcp.OptOut("none of build.gradle, build.gradle.kts, settings.gradle or settings.gradle.kts found"), nil
 }
 
 func buildFn(ctx *gcp.Context) error {
@@ -67,6 +81,9 @@ func buildFn(ctx *gcp.Context) error {
 	if err := java.CheckCacheExpiration(ctx, gradleCachedRepo); err != nil {
 		return fmt.Errorf("validating the cache: %w", err)
 	}
+	if err := checkCacheKey(ctx, gradleCachedRepo); err != nil {
+		return fmt.Errorf("validating the cache: %w", err)
+	}
 
 	homeGradle := filepath.Join(ctx.HomeDir(), ".gradle")
 	// Symlink the gradle-cache layer into ~/.gradle. If ~/.gradle already exists, delete it first.
@@ -83,7 +100,18 @@ func buildFn(ctx *gcp.Context) error {
 		return err
 	}
 
-	command := []string{gradle, "clean", "assemble", "-x", "test", "--build-cache"}
+	module, err := java.Module()
+	if err != nil {
+		return err
+	}
+	if module != "" {
+		ctx.Logf("Building project %s and the projects it depends on.", java.GradleProjectPath(module))
+	}
+	application, err := java.GradleApplicationPluginApplied(ctx)
+	if err != nil {
+		return err
+	}
+	command := append(append([]string{gradle, "clean"}, gradleTasks(module, application)...), "-x", "test", "--build-cache")
 
 	if buildArgs := os.Getenv(env.BuildArgs); buildArgs != "" {
 		if strings.Contains(buildArgs, "project-cache-dir") {
@@ -108,12 +136,113 @@ func buildFn(ctx *gcp.Context) error {
 	return nil
 }
 
+// gradleTasks returns the tasks that build the application, from GOOGLE_GRADLE_TASKS. By default,
+// the distribution of the application plugin is also installed, with the start script that runs
+// the application. Task names are qualified with the project path of the module, if any, so that
+// only the module and the projects it depends on are built.
+func gradleTasks(module string, application bool) []string {
+	tasks := strings.Fields(os.Getenv(env.GradleTasks))
+	if len(tasks) == 0 {
+		tasks = []string{defaultTask}
+		if application {
+			tasks = append(tasks, installDistTask)
+		}
+	}
+	if module == "" {
+		return tasks
+	}
+	for i, t := range tasks {
+		if !strings.HasPrefix(t, ":") {
+			tasks[i] = java.GradleProjectPath(module) + ":" + t
+		}
+	}
+	return tasks
+}
+
+// checkCacheKey clears the Gradle cache when the settings or the dependency lock files of the
+// build, or the build scripts of the module being built, have changed, so that stale dependencies do not accumulate in the cache.
+func checkCacheKey(ctx *gcp.Context, l *libcnb.Layer) error {
+	files, err := cacheFiles(ctx)
+	if err != nil {
+		return err
+	}
+	key, err := cache.Hash(ctx, cache.WithFiles(files...))
+	if err != nil {
+		return fmt.Errorf("computing cache key: %w", err)
+	}
+	prevKey := ctx.GetMetadata(l, cacheKeyKey)
+	if prevKey == key {
+		ctx.CacheHit(cacheLayer)
+		return nil
+	}
+	ctx.CacheMiss(cacheLayer)
+	if prevKey != "" {
+		ctx.Debugf("Gradle settings or lock files changed, clearing the cache")
+		if err := ctx.ClearLayer(l); err != nil {
+			return fmt.Errorf("clearing layer %q: %w", l.Name, err)
+		}
+		// Start a new expiration period for the cleared cache.
+		if err := java.CheckCacheExpiration(ctx, l); err != nil {
+			return err
+		}
+	}
+	ctx.SetMetadata(l, cacheKeyKey, key)
+	return nil
+}
+
+// cacheFiles returns the existing files that the Gradle cache is keyed on: the settings and the
+// dependency lock files, see https://docs.gradle.org/current/userguide/dependency_locking.html.
+func cacheFiles(ctx *gcp.Context) ([]string, error) {
+	var files []string
+	for _, f := range cacheKeyFiles {
+		path := filepath.Join(ctx.ApplicationRoot(), f)
+		exists, err := ctx.FileExists(path)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			files = append(files, path)
+		}
+	}
+	for _, pattern := range []string{"*.lockfile", "*/*.lockfile", "gradle/dependency-locks/*.lockfile"} {
+		locks, err := ctx.Glob(filepath.Join(ctx.ApplicationRoot(), pattern))
+		if err != nil {
+			return nil, fmt.Errorf("finding lock files: %w", err)
+		}
+		files = append(files, locks...)
+	}
+	module, err := java.Module()
+	if err != nil {
+		return nil, err
+	}
+	if module != "" {
+		// Only the build scripts of the projects the module depends on affect the module build.
+		scripts, err := java.GradleModuleBuildFiles(ctx, ctx.ApplicationRoot(), module)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, scripts...)
+	}
+	return files, nil
+}
+
 func provisionOrDetectGradle(ctx *gcp.Context) (string, error) {
 	gradlewExists, err := ctx.FileExists("gradlew")
 	if err != nil {
 		return "", err
 	}
 	if gradlewExists {
+		// The executable bit of the wrapper is often lost, e.g. when the source is zipped on Windows.
+		info, err := os.Stat(filepath.Join(ctx.ApplicationRoot(), "gradlew"))
+		if err != nil {
+			return "", gcp.InternalErrorf("reading gradlew: %v", err)
+		}
+		if info.Mode()&0111 == 0 {
+			ctx.Logf("Making gradlew executable")
+			if err := os.Chmod(filepath.Join(ctx.ApplicationRoot(), "gradlew"), info.Mode()|0755); err != nil {
+				return "", gcp.UserErrorf("making gradlew executable: %v", err)
+			}
+		}
 		return "./gradlew", nil
 	}
 	installed, err := gradleInstalled(ctx)
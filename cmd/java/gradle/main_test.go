@@ -15,9 +15,13 @@
 package main
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 
 	buildpacktest "github.com/GoogleCloudPlatform/buildpacks/internal/buildpacktest"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
 )
 
 func TestDetect(t *testing.T) {
@@ -40,6 +44,13 @@ func TestDetect(t *testing.T) {
 			},
 			want: 0,
 		},
+		{
+			name: "settings.gradle.kts",
+			files: map[string]string{
+				"settings.gradle.kts": "",
+			},
+			want: 0,
+		},
 		{
 			name:  "no files",
 			files: map[string]string{},
@@ -52,3 +63,86 @@ func TestDetect(t *testing.T) {
 		})
 	}
 }
+
+func TestGradleTasks(t *testing.T) {
+	testCases := []struct {
+		name        string
+		tasks       string
+		module      string
+		application bool
+		want        []string
+	}{
+		{
+			name: "default",
+			want: []string{"assemble"},
+		},
+		{
+			name:        "application plugin",
+			application: true,
+			want:        []string{"assemble", "installDist"},
+		},
+		{
+			name:        "application plugin custom tasks",
+			tasks:       "shadowJar",
+			application: true,
+			want:        []string{"shadowJar"},
+		},
+		{
+			name:  "custom tasks",
+			tasks: " :app:bootJar  :worker:shadowJar ",
+			want:  []string{":app:bootJar", ":worker:shadowJar"},
+		},
+		{
+			name:   "module default",
+			module: "services/api",
+			want:   []string{":services:api:assemble"},
+		},
+		{
+			name:   "module custom tasks",
+			tasks:  "bootJar :worker:shadowJar",
+			module: "services/api",
+			want:   []string{":services:api:bootJar", ":worker:shadowJar"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("GOOGLE_GRADLE_TASKS", tc.tasks)
+			if got := gradleTasks(tc.module, tc.application); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("gradleTasks(%q, %t) = %v, want %v", tc.module, tc.application, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCacheFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{
+		"build.gradle",
+		"settings.gradle",
+		"gradle.lockfile",
+		"app/gradle.lockfile",
+		"gradle/libs.versions.toml",
+		"gradle/wrapper/gradle-wrapper.properties",
+		"src/main/java/App.java",
+	}
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(f)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, f), []byte{}, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := cacheFiles(gcp.NewContext(gcp.WithApplicationRoot(dir)))
+	if err != nil {
+		t.Fatalf("cacheFiles() got error: %v", err)
+	}
+	var want []string
+	for _, f := range []string{"settings.gradle", "gradle/wrapper/gradle-wrapper.properties", "gradle/libs.versions.toml", "gradle.lockfile", "app/gradle.lockfile"} {
+		want = append(want, filepath.Join(dir, f))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("cacheFiles() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Implements java/jlink buildpack.
+// The jlink buildpack assembles a minimal Java runtime from the modules required by the application.
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/java"
+)
+
+const (
+	jreLayer = "jre"
+)
+
+var (
+	// providerModules are included when available in the JDK because they are loaded through
+	// service providers, which jdeps does not report.
+	providerModules = []string{"jdk.crypto.ec", "jdk.crypto.cryptoki"}
+	// versionRe matches the feature version of a JDK tool version, e.g. 17 in 17.0.8 or 1.8.0_382.
+	versionRe = regexp.MustCompile(`^(?:1\.)?(\d+)`)
+)
+
+func main() {
+	gcp.Main(detectFn, buildFn)
+}
+
+func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
+	enabled, err := env.IsPresentAndTrue(env.JavaJlink)
+	if err != nil {
+		return nil, gcp.UserErrorf("failed to parse %s: %v", env.JavaJlink, err)
+	}
+	if !enabled {
+		return gcp.OptOutEnvNotSet(env.JavaJlink), nil
+	}
+	return gcp.OptInEnvSet(env.JavaJlink), nil
+}
+
+func buildFn(ctx *gcp.Context) error {
+	jar, err := java.ExecutableJar(ctx)
+	if err != nil {
+		return fmt.Errorf("finding executable jar: %w", err)
+	}
+	required, err := requiredModules(ctx, jar)
+	if err != nil {
+		return err
+	}
+	available, err := availableModules(ctx)
+	if err != nil {
+		return err
+	}
+	modules := mergeModules(required, providerModules, available, strings.Split(os.Getenv(env.JavaJlinkModules), ","))
+	ctx.Logf("Assembling a Java runtime with modules: %s", strings.Join(modules, ","))
+
+	l, err := ctx.Layer(jreLayer, gcp.LaunchLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", jreLayer, err)
+	}
+	// jlink refuses to write to an existing directory.
+	if err := ctx.RemoveAll(l.Path); err != nil {
+		return err
+	}
+	command := []string{
+		"jlink",
+		"--add-modules", strings.Join(modules, ","),
+		"--strip-debug",
+		"--no-man-pages",
+		"--no-header-files",
+		"--compress=2",
+		"--output", l.Path,
+	}
+	if _, err := ctx.Exec(command, gcp.WithUserAttribution); err != nil {
+		return err
+	}
+	l.LaunchEnvironment.Override("JAVA_HOME", l.Path)
+	return nil
+}
+
+// requiredModules returns the JDK modules used by the jar and its dependencies, as reported by jdeps.
+func requiredModules(ctx *gcp.Context, jar string) ([]string, error) {
+	result, err := ctx.Exec([]string{"jdeps", "--version"})
+	if err != nil {
+		return nil, err
+	}
+	version, err := featureVersion(result.Stdout)
+	if err != nil {
+		return nil, err
+	}
+
+	target, classpath, err := analysisTarget(ctx, jar)
+	if err != nil {
+		return nil, err
+	}
+	command := []string{"jdeps", "--ignore-missing-deps", "--print-module-deps", "--recursive", "--multi-release", version, "-quiet"}
+	if classpath != "" {
+		command = append(command, "--class-path", classpath)
+	}
+	command = append(command, target)
+	result, err = ctx.Exec(command, gcp.WithUserAttribution)
+	if err != nil {
+		return nil, err
+	}
+	return parseModuleDeps(result.Stdout), nil
+}
+
+// analysisTarget returns the classes and class path that jdeps analyzes for the jar. The classes of
+// a Spring Boot fat jar are nested in the jar, which jdeps does not inspect, so the jar is exploded.
+func analysisTarget(ctx *gcp.Context, jar string) (string, string, error) {
+	startClass, err := java.FindManifestValueFromJar(jar, "Start-Class")
+	if err != nil {
+		return "", "", fmt.Errorf("fetching manifest value from JAR %q: %w", jar, err)
+	}
+	if startClass == "" {
+		return jar, "", nil
+	}
+	dir, err := ctx.TempDir("exploded-jar")
+	if err != nil {
+		return "", "", fmt.Errorf("creating temp directory: %w", err)
+	}
+	if _, err := ctx.Exec([]string{"unzip", "-q", jar, "-d", dir}, gcp.WithUserAttribution); err != nil {
+		return "", "", err
+	}
+	libs, err := ctx.Glob(filepath.Join(dir, "BOOT-INF", "lib", "*.jar"))
+	if err != nil {
+		return "", "", fmt.Errorf("finding Spring Boot libraries: %w", err)
+	}
+	return filepath.Join(dir, "BOOT-INF", "classes"), strings.Join(libs, string(filepath.ListSeparator)), nil
+}
+
+// availableModules returns the modules of the installed JDK.
+func availableModules(ctx *gcp.Context) (map[string]bool, error) {
+	result, err := ctx.Exec([]string{"java", "--list-modules"})
+	if err != nil {
+		return nil, err
+	}
+	modules := map[string]bool{}
+	for _, line := range strings.Split(result.Stdout, "\n") {
+		if name := strings.SplitN(strings.TrimSpace(line), "@", 2)[0]; name != "" {
+			modules[name] = true
+		}
+	}
+	return modules, nil
+}
+
+// featureVersion returns the feature version of the output of `jdeps --version`.
+func featureVersion(output string) (string, error) {
+	m := versionRe.FindStringSubmatch(strings.TrimSpace(output))
+	if m == nil {
+		return "", gcp.InternalErrorf("unable to parse jdeps version %q", output)
+	}
+	return m[1], nil
+}
+
+// parseModuleDeps parses the comma-separated modules printed by `jdeps --print-module-deps`.
+func parseModuleDeps(output string) []string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	var modules []string
+	for _, m := range strings.Split(lines[len(lines)-1], ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			modules = append(modules, m)
+		}
+	}
+	return modules
+}
+
+// mergeModules returns the sorted union of the required modules, the optional modules that are
+// available in the JDK and the user modules. java.base is always included.
+func mergeModules(required, optional []string, available map[string]bool, user []string) []string {
+	set := map[string]bool{"java.base": true}
+	for _, m := range required {
+		set[m] = true
+	}
+	for _, m := range optional {
+		if available[m] {
+			set[m] = true
+		}
+	}
+	for _, m := range user {
+		if m = strings.TrimSpace(m); m != "" {
+			set[m] = true
+		}
+	}
+	var modules []string
+	for m := range set {
+		modules = append(modules, m)
+	}
+	sort.Strings(modules)
+	return modules
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	buildpacktest "github.com/GoogleCloudPlatform/buildpacks/internal/buildpacktest"
+)
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		env   []string
+		want  int
+	}{
+		{
+			name: "jlink enabled",
+			env:  []string{"GOOGLE_JAVA_JLINK=true"},
+			want: 0,
+		},
+		{
+			name: "jlink disabled",
+			env:  []string{"GOOGLE_JAVA_JLINK=false"},
+			want: 100,
+		},
+		{
+			name: "env not set",
+			want: 100,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buildpacktest.TestDetect(t, detectFn, tc.name, tc.files, tc.env, tc.want)
+		})
+	}
+}
+
+func TestFeatureVersion(t *testing.T) {
+	testCases := []struct {
+		output  string
+		want    string
+		wantErr bool
+	}{
+		{output: "17.0.8\n", want: "17"},
+		{output: "11.0.20.1", want: "11"},
+		{output: "21", want: "21"},
+		{output: "1.8.0_382", want: "8"},
+		{output: "unknown", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.output, func(t *testing.T) {
+			got, err := featureVersion(tc.output)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("featureVersion(%q) got error: %v, want error: %v", tc.output, err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("featureVersion(%q) = %q, want %q", tc.output, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseModuleDeps(t *testing.T) {
+	testCases := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{
+			name:   "modules",
+			output: "java.base,java.logging,java.sql\n",
+			want:   []string{"java.base", "java.logging", "java.sql"},
+		},
+		{
+			name:   "warnings before modules",
+			output: "Warning: split package: javax.annotation\njava.base,java.naming\n",
+			want:   []string{"java.base", "java.naming"},
+		},
+		{
+			name:   "empty",
+			output: "",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := parseModuleDeps(tc.output); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("parseModuleDeps(%q) = %v, want %v", tc.output, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMergeModules(t *testing.T) {
+	got := mergeModules(
+		[]string{"java.sql", "java.logging"},
+		[]string{"jdk.crypto.ec", "jdk.crypto.cryptoki"},
+		map[string]bool{"java.base": true, "jdk.crypto.ec": true},
+		[]string{" jdk.management", "", "java.sql"},
+	)
+	want := []string{"java.base", "java.logging", "java.sql", "jdk.crypto.ec", "jdk.management"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeModules() = %v, want %v", got, want)
+	}
+}
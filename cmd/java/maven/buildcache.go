@@ -0,0 +1,131 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/fetch"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/java"
+)
+
+const (
+	buildCacheLayer          = "build-cache"
+	buildCacheExtensionLayer = "build-cache-extension"
+	buildCacheExtension      = "maven-build-cache-extension"
+	buildCacheVersion        = "1.1.0"
+	buildCacheURL            = "https://repo.maven.apache.org/maven2/org/apache/maven/extensions/%[1]s/%[2]s/%[1]s-%[2]s.jar"
+	// buildCacheMavenVersion is the Maven version installed when the build cache extension is
+	// enabled, which requires Maven 3.9.0 or later.
+	buildCacheMavenVersion = "3.9.5"
+)
+
+// buildCacheEnabled returns true if the build cache extension is declared by the project or
+// requested with GOOGLE_MAVEN_BUILD_CACHE, and whether it is declared by the project.
+func buildCacheEnabled(ctx *gcp.Context, pomPath string) (enabled, declared bool, err error) {
+	declared, err = buildCacheExtensionDeclared(ctx, pomPath)
+	if err != nil {
+		return false, false, err
+	}
+	if declared {
+		return true, true, nil
+	}
+	enabled, err = env.IsPresentAndTrue(env.MavenBuildCache)
+	if err != nil {
+		return false, false, gcp.UserErrorf("%v", err)
+	}
+	return enabled, false, nil
+}
+
+// buildCacheExtensionDeclared returns true if the .mvn/extensions.xml of the project declares the
+// build cache extension. Maven looks for the .mvn directory from the directory of the pom.xml up to
+// the root of the build.
+func buildCacheExtensionDeclared(ctx *gcp.Context, pomPath string) (bool, error) {
+	dirs := []string{ctx.ApplicationRoot()}
+	if dir := filepath.Dir(pomPath); pomPath != "" && dir != "." {
+		dirs = append([]string{filepath.Join(ctx.ApplicationRoot(), dir)}, dirs...)
+	}
+	for _, dir := range dirs {
+		path := filepath.Join(dir, ".mvn", "extensions.xml")
+		exists, err := ctx.FileExists(path)
+		if err != nil {
+			return false, err
+		}
+		if !exists {
+			continue
+		}
+		content, err := ctx.ReadFile(path)
+		if err != nil {
+			return false, err
+		}
+		return bytes.Contains(content, []byte("<artifactId>"+buildCacheExtension+"</artifactId>")), nil
+	}
+	return false, nil
+}
+
+// buildCacheArgs returns the arguments that store the local build cache in a cache layer, so that
+// modules that have not changed are restored from the cache instead of being built again. The
+// extension is loaded from a layer if the project does not declare it.
+func buildCacheArgs(ctx *gcp.Context, declared bool) ([]string, error) {
+	l, err := ctx.Layer(buildCacheLayer, gcp.CacheLayer)
+	if err != nil {
+		return nil, fmt.Errorf("creating %v layer: %w", buildCacheLayer, err)
+	}
+	if err := java.CheckCacheExpiration(ctx, l); err != nil {
+		return nil, fmt.Errorf("validating the cache: %w", err)
+	}
+	args := []string{"-Dmaven.build.cache.location=" + l.Path}
+	if declared {
+		ctx.Logf("Using the Maven build cache extension declared in .mvn/extensions.xml.")
+		return args, nil
+	}
+	jar, err := installBuildCacheExtension(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("installing the Maven build cache extension: %w", err)
+	}
+	ctx.Logf("Using the Maven build cache extension v%s, it requires Maven 3.9.0 or later.", buildCacheVersion)
+	return append(args, "-Dmaven.ext.class.path="+jar), nil
+}
+
+// installBuildCacheExtension downloads the build cache extension and returns the path of its jar.
+func installBuildCacheExtension(ctx *gcp.Context) (string, error) {
+	l, err := ctx.Layer(buildCacheExtensionLayer, gcp.CacheLayer, gcp.BuildLayer)
+	if err != nil {
+		return "", fmt.Errorf("creating %v layer: %w", buildCacheExtensionLayer, err)
+	}
+	jar := filepath.Join(l.Path, buildCacheExtension+".jar")
+	if ctx.GetMetadata(l, versionKey) == buildCacheVersion {
+		ctx.CacheHit(buildCacheExtensionLayer)
+		return jar, nil
+	}
+	ctx.CacheMiss(buildCacheExtensionLayer)
+	if err := ctx.ClearLayer(l); err != nil {
+		return "", fmt.Errorf("clearing layer %q: %w", l.Name, err)
+	}
+	f, err := ctx.CreateFile(jar)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if err := fetch.GetURL(fmt.Sprintf(buildCacheURL, buildCacheExtension, buildCacheVersion), f); err != nil {
+		return "", err
+	}
+	ctx.SetMetadata(l, versionKey, buildCacheVersion)
+	return jar, nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+func TestBuildCacheEnabled(t *testing.T) {
+	extensions := `<extensions>
+  <extension>
+    <groupId>org.apache.maven.extensions</groupId>
+    <artifactId>maven-build-cache-extension</artifactId>
+    <version>1.1.0</version>
+  </extension>
+</extensions>`
+
+	testCases := []struct {
+		name         string
+		files        map[string]string
+		pomPath      string
+		buildCache   string
+		wantEnabled  bool
+		wantDeclared bool
+		wantErr      bool
+	}{
+		{
+			name:    "not enabled",
+			files:   map[string]string{"pom.xml": ""},
+			pomPath: "pom.xml",
+		},
+		{
+			name:        "enabled with env",
+			files:       map[string]string{"pom.xml": ""},
+			pomPath:     "pom.xml",
+			buildCache:  "true",
+			wantEnabled: true,
+		},
+		{
+			name:       "disabled with env",
+			files:      map[string]string{"pom.xml": ""},
+			pomPath:    "pom.xml",
+			buildCache: "false",
+		},
+		{
+			name:         "declared extension",
+			files:        map[string]string{"pom.xml": "", ".mvn/extensions.xml": extensions},
+			pomPath:      "pom.xml",
+			wantEnabled:  true,
+			wantDeclared: true,
+		},
+		{
+			name:         "declared extension takes precedence",
+			files:        map[string]string{"pom.xml": "", ".mvn/extensions.xml": extensions},
+			pomPath:      "pom.xml",
+			buildCache:   "false",
+			wantEnabled:  true,
+			wantDeclared: true,
+		},
+		{
+			name:         "declared extension in buildable",
+			files:        map[string]string{"app/pom.xml": "", "app/.mvn/extensions.xml": extensions},
+			pomPath:      "app/pom.xml",
+			wantEnabled:  true,
+			wantDeclared: true,
+		},
+		{
+			name:    "other extensions",
+			files:   map[string]string{"pom.xml": "", ".mvn/extensions.xml": "<extensions><extension><artifactId>artifactregistry-maven-wagon</artifactId></extension></extensions>"},
+			pomPath: "pom.xml",
+		},
+		{
+			name:       "invalid env",
+			files:      map[string]string{"pom.xml": ""},
+			pomPath:    "pom.xml",
+			buildCache: "yes please",
+			wantErr:    true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// t.Setenv restores the variable after the test, an empty value must be unset.
+			t.Setenv("GOOGLE_MAVEN_BUILD_CACHE", tc.buildCache)
+			if tc.buildCache == "" {
+				os.Unsetenv("GOOGLE_MAVEN_BUILD_CACHE")
+			}
+			root := t.TempDir()
+			for name, content := range tc.files {
+				path := filepath.Join(root, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("creating directory for %s: %v", name, err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatalf("writing %s: %v", name, err)
+				}
+			}
+
+			enabled, declared, err := buildCacheEnabled(gcp.NewContext(gcp.WithApplicationRoot(root)), tc.pomPath)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("buildCacheEnabled() got error: %v, want error: %t", err, tc.wantErr)
+			}
+			if enabled != tc.wantEnabled || declared != tc.wantDeclared {
+				t.Errorf("buildCacheEnabled() = (%t, %t), want (%t, %t)", enabled, declared, tc.wantEnabled, tc.wantDeclared)
+			}
+		})
+	}
+}
@@ -25,10 +25,12 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/cache"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/devmode"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/java"
+	"github.com/buildpacks/libcnb"
 )
 
 const (
@@ -38,10 +40,11 @@ const (
 	mavenLayer   = "maven"
 	m2Layer      = "m2"
 	versionKey   = "version"
+	moduleKeyKey = "module_key"
 )
 
 func main() {
-	gcp.Main(detectFn, buildFn)
+	gcp.Main(detectFn, buildFn, gcp.WithFunctionSourceDir())
 }
 
 func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
@@ -87,21 +90,45 @@ func buildFn(ctx *gcp.Context) error {
 		return err
 	}
 
-	mvn, err := provisionOrDetectMaven(ctx)
+	pomPath, err := pomFilePath(ctx)
 	if err != nil {
 		return err
 	}
+	buildCache, buildCacheDeclared, err := buildCacheEnabled(ctx, pomPath)
+	if err != nil {
+		return err
+	}
+	version := mavenVersion
+	if buildCache {
+		version = buildCacheMavenVersion
+	}
 
-	command := []string{mvn, "clean", "package", "--batch-mode", "-DskipTests", "-Dhttp.keepAlive=false"}
-
-	pomPath, err := pomFilePath(ctx)
+	mvn, err := provisionOrDetectMaven(ctx, version)
 	if err != nil {
 		return err
 	}
+
+	command := []string{mvn, "clean", "package", "--batch-mode", "-DskipTests", "-Dhttp.keepAlive=false"}
+
 	if pomPath != "" {
 		command = append(command, fmt.Sprintf("-f=%s", pomPath))
 	}
 
+	module, err := java.Module()
+	if err != nil {
+		return err
+	}
+	if module != "" {
+		if pomPath == "" {
+			return gcp.UserErrorf("%s is set but pom.xml was not found", env.JavaModule)
+		}
+		ctx.Logf("Building module %s and the modules it depends on.", module)
+		command = append(command, "--projects", module, "--also-make")
+		if err := checkModuleCacheKey(ctx, m2CachedRepo, filepath.Dir(pomPath), module); err != nil {
+			return fmt.Errorf("validating the cache: %w", err)
+		}
+	}
+
 	if buildArgs := os.Getenv(env.BuildArgs); buildArgs != "" {
 		if strings.Contains(buildArgs, "maven.repo.local") {
 			ctx.Warnf("Detected maven.repo.local property set in GOOGLE_BUILD_ARGS. Maven caching may not work properly.")
@@ -113,7 +140,26 @@ func buildFn(ctx *gcp.Context) error {
 		command = append(command, "--quiet")
 	}
 
-	if _, err := ctx.Exec(command, gcp.WithStdoutTail, gcp.WithUserAttribution); err != nil {
+	settings, cleanup, err := mavenSettings(ctx, pomPath)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+	// The settings and the build cache are transient, so they are not part of the dev mode build
+	// script below.
+	buildCommand := append([]string{}, command...)
+	if settings != "" {
+		buildCommand = append(buildCommand, "--global-settings", settings)
+	}
+	if buildCache {
+		args, err := buildCacheArgs(ctx, buildCacheDeclared)
+		if err != nil {
+			return err
+		}
+		buildCommand = append(buildCommand, args...)
+	}
+
+	if _, err := ctx.Exec(buildCommand, gcp.WithStdoutTail, gcp.WithUserAttribution); err != nil {
 		return err
 	}
 
@@ -125,7 +171,57 @@ func buildFn(ctx *gcp.Context) error {
 	return nil
 }
 
-func provisionOrDetectMaven(ctx *gcp.Context) (string, error) {
+// checkModuleCacheKey clears the Maven repository cache when the poms of the module or of the
+// modules it depends on have changed. Changes to other modules of the build do not affect the cache.
+func checkModuleCacheKey(ctx *gcp.Context, l *libcnb.Layer, root, module string) error {
+	poms, err := java.MavenModulePoms(ctx, filepath.Join(ctx.ApplicationRoot(), root), module)
+	if err != nil {
+		return err
+	}
+	key, err := cache.Hash(ctx, cache.WithStrings(module), cache.WithFiles(poms...))
+	if err != nil {
+		return fmt.Errorf("computing cache key: %w", err)
+	}
+	prevKey := ctx.GetMetadata(l, moduleKeyKey)
+	if prevKey == key {
+		ctx.CacheHit(m2Layer)
+		return nil
+	}
+	ctx.CacheMiss(m2Layer)
+	if prevKey != "" {
+		ctx.Debugf("Poms of module %s changed, clearing the cache", module)
+		if err := ctx.ClearLayer(l); err != nil {
+			return fmt.Errorf("clearing layer %q: %w", l.Name, err)
+		}
+		// Start a new expiration period for the cleared cache.
+		if err := java.CheckCacheExpiration(ctx, l); err != nil {
+			return err
+		}
+	}
+	ctx.SetMetadata(l, moduleKeyKey, key)
+	return nil
+}
+
+// mavenSettings writes the settings.xml with the credentials of the repositories of the project,
+// see java.MavenSettings.
+func mavenSettings(ctx *gcp.Context, pomPath string) (string, func(), error) {
+	var project *java.MavenProject
+	if pomPath != "" {
+		pom, err := ctx.ReadFile(pomPath)
+		if err != nil {
+			return "", func() {}, err
+		}
+		if project, err = java.ParsePomFile(pom); err != nil {
+			ctx.Warnf("Unable to parse %s to find Artifact Registry repositories: %v", pomPath, err)
+			project = nil
+		}
+	}
+	return java.MavenSettings(ctx, project)
+}
+
+// provisionOrDetectMaven returns the Maven wrapper or the installed mvn, or installs the given
+// version of Maven.
+func provisionOrDetectMaven(ctx *gcp.Context, version string) (string, error) {
 	mvnwExists, err := ctx.FileExists("mvnw")
 	if err != nil {
 		return "", err
@@ -144,7 +240,7 @@ func provisionOrDetectMaven(ctx *gcp.Context) (string, error) {
 	if mvnInstalled {
 		return "mvn", nil
 	}
-	mvn, err := installMaven(ctx)
+	mvn, err := installMaven(ctx, version)
 	if err != nil {
 		return "", fmt.Errorf("installing Maven: %w", err)
 	}
@@ -188,8 +284,8 @@ func mvnInstalled(ctx *gcp.Context) (bool, error) {
 	return result.Stdout != "", nil
 }
 
-// installMaven installs Maven and returns the path of the mvn binary
-func installMaven(ctx *gcp.Context) (string, error) {
+// installMaven installs the given version of Maven and returns the path of the mvn binary
+func installMaven(ctx *gcp.Context, version string) (string, error) {
 	mvnl, err := ctx.Layer(mavenLayer, gcp.CacheLayer, gcp.BuildLayer, gcp.LaunchLayerIfDevMode)
 	if err != nil {
 		return "", fmt.Errorf("creating %v layer: %w", mavenLayer, err)
@@ -197,7 +293,7 @@ func installMaven(ctx *gcp.Context) (string, error) {
 
 	// Check the metadata in the cache layer to determine if we need to proceed.
 	metaVersion := ctx.GetMetadata(mvnl, versionKey)
-	if mavenVersion == metaVersion {
+	if version == metaVersion {
 		ctx.CacheHit(mavenLayer)
 		ctx.Logf("Maven cache hit, skipping installation.")
 		return filepath.Join(mvnl.Path, "bin", "mvn"), nil
@@ -208,21 +304,21 @@ func installMaven(ctx *gcp.Context) (string, error) {
 	}
 
 	// Download and install maven in layer.
-	ctx.Logf("Installing Maven v%s", mavenVersion)
-	archiveURL := fmt.Sprintf(mavenURL, mavenVersion)
+	ctx.Logf("Installing Maven v%s", version)
+	archiveURL := fmt.Sprintf(mavenURL, version)
 	code, err := ctx.HTTPStatus(archiveURL)
 	if err != nil {
 		return "", err
 	}
 	if code != http.StatusOK {
-		return "", gcp.UserErrorf("Maven version %s does not exist at %s (status %d).", mavenVersion, archiveURL, code)
+		return "", gcp.UserErrorf("Maven version %s does not exist at %s (status %d).", version, archiveURL, code)
 	}
 	command := fmt.Sprintf("curl --fail --show-error --silent --location --retry 3 %s | tar xz --directory %s --strip-components=1", archiveURL, mvnl.Path)
 	if _, err := ctx.Exec([]string{"bash", "-c", command}, gcp.WithUserAttribution); err != nil {
 		return "", err
 	}
 
-	ctx.SetMetadata(mvnl, versionKey, mavenVersion)
+	ctx.SetMetadata(mvnl, versionKey, version)
 	return filepath.Join(mvnl.Path, "bin", "mvn"), nil
 }
 
@@ -30,6 +30,18 @@ import (
 
 const (
 	invokerMain = "com.google.cloud.functions.invoker.runner.Invoker"
+	// gradleNativePlugin is the ID of the GraalVM Native Build Tools plugin for Gradle.
+	gradleNativePlugin = "org.graalvm.buildtools.native"
+)
+
+var (
+	// nativeMavenPlugins are the Maven plugins that build a native image, identified by group and artifact ID.
+	nativeMavenPlugins = []java.MavenPlugin{
+		{GroupID: "org.graalvm.buildtools", ArtifactID: "native-maven-plugin"},
+		{GroupID: "org.graalvm.nativeimage", ArtifactID: "native-image-maven-plugin"},
+	}
+	// gradleNativeOutput is the directory where the Gradle nativeCompile task writes the executable.
+	gradleNativeOutput = filepath.Join("build", "native", "nativeCompile")
 )
 
 var (
@@ -38,7 +50,7 @@ var (
 )
 
 func main() {
-	gcp.Main(detectFn, buildFn)
+	gcp.Main(detectFn, buildFn, gcp.WithFunctionSourceDir())
 }
 
 func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
@@ -64,6 +76,11 @@ func createImage(ctx *gcp.Context) ([]string, error) {
 		return nil, fmt.Errorf("parsing pom file: %w", err)
 	}
 	if pom == nil {
+		if plugin, err := gradleNativePluginApplied(ctx); err != nil {
+			return nil, err
+		} else if plugin {
+			return buildGradle(ctx)
+		}
 		return buildDefault(ctx)
 	}
 	if functionTarget, ok := os.LookupEnv(env.FunctionTarget); ok {
@@ -149,13 +166,55 @@ func buildMaven(ctx *gcp.Context, buildProfile string) ([]string, error) {
 		return nil, err
 	}
 
-	imagePath, err := findNativeExecutable(ctx)
+	imagePath, err := findNativeExecutable(ctx, "target")
+	if err != nil {
+		return nil, err
+	}
+	return []string{imagePath}, nil
+}
+
+// buildGradle runs the nativeCompile task of the GraalVM Native Build Tools Gradle plugin and
+// returns the image entrypoint.
+func buildGradle(ctx *gcp.Context) ([]string, error) {
+	gradle, err := java.GradleCmd(ctx)
+	if err != nil {
+		return nil, err
+	}
+	command := []string{gradle, "nativeCompile", "-x", "test", "--quiet"}
+	if _, err := ctx.Exec(command, gcp.WithUserAttribution); err != nil {
+		return nil, err
+	}
+
+	imagePath, err := findNativeExecutable(ctx, gradleNativeOutput)
 	if err != nil {
 		return nil, err
 	}
 	return []string{imagePath}, nil
 }
 
+// gradleNativePluginApplied returns true if a Gradle build script references the GraalVM Native
+// Build Tools plugin.
+func gradleNativePluginApplied(ctx *gcp.Context) (bool, error) {
+	for _, name := range []string{"build.gradle", "build.gradle.kts"} {
+		script := filepath.Join(ctx.ApplicationRoot(), name)
+		exists, err := ctx.FileExists(script)
+		if err != nil {
+			return false, err
+		}
+		if !exists {
+			continue
+		}
+		content, err := ctx.ReadFile(script)
+		if err != nil {
+			return false, err
+		}
+		if strings.Contains(string(content), gradleNativePlugin) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // parsePomFile returns a parsed pom.xml if it exists.
 func parsePomFile(ctx *gcp.Context) (*java.MavenProject, error) {
 	pomExists, err := ctx.FileExists("pom.xml")
@@ -199,13 +258,12 @@ func parsePomFile(ctx *gcp.Context) (*java.MavenProject, error) {
 	return project, nil
 }
 
-// findNativeBuildProfile returns the profile in which the native-image-plugin is defined
+// findNativeBuildProfile returns the profile in which a native image plugin is defined
 // and a bool which returns true if the plugin is found, false if not.
 func findNativeBuildProfile(ctx *gcp.Context, project *java.MavenProject) (string, bool) {
 	for _, profile := range project.Profiles {
 		for _, plugin := range profile.Plugins {
-			if plugin.GroupID == "org.graalvm.nativeimage" &&
-				plugin.ArtifactID == "native-image-maven-plugin" {
+			if isNativeMavenPlugin(plugin) {
 				return profile.ID, true
 			}
 		}
@@ -215,6 +273,16 @@ func findNativeBuildProfile(ctx *gcp.Context, project *java.MavenProject) (strin
 	return "", false
 }
 
+// isNativeMavenPlugin returns true if the plugin builds a native image.
+func isNativeMavenPlugin(plugin java.MavenPlugin) bool {
+	for _, p := range nativeMavenPlugins {
+		if plugin.GroupID == p.GroupID && plugin.ArtifactID == p.ArtifactID {
+			return true
+		}
+	}
+	return false
+}
+
 // springBootPluginDefined checks if the spring-boot-maven-plugin is defined.
 func springBootPluginDefined(ctx *gcp.Context, project *java.MavenProject) bool {
 	for _, plugin := range project.Plugins {
@@ -228,25 +296,25 @@ func springBootPluginDefined(ctx *gcp.Context, project *java.MavenProject) bool
 	return false
 }
 
-// findNativeExecutable returns the path to the executable from the target/ folder
+// findNativeExecutable returns the path to the executable from the given output folder
 // and only succeeds if exactly 1 is found; returns error otherwise.
-func findNativeExecutable(ctx *gcp.Context) (string, error) {
+func findNativeExecutable(ctx *gcp.Context, dir string) (string, error) {
 	var allExecutables []string
 
-	targetDir, err := ctx.ReadDir("target")
+	outputDir, err := ctx.ReadDir(dir)
 	if err != nil {
 		return "", err
 	}
 
-	for _, info := range targetDir {
+	for _, info := range outputDir {
 		// If any of the last three bits of the file mode are set, it is executable.
 		if !info.IsDir() && info.Mode()&0111 != 0 {
-			allExecutables = append(allExecutables, filepath.Join("target", info.Name()))
+			allExecutables = append(allExecutables, filepath.Join(dir, info.Name()))
 		}
 	}
 
 	if len(allExecutables) != 1 {
-		return "", gcp.UserErrorf("expected project to produce exactly 1 executable in target/, but found: %v", allExecutables)
+		return "", gcp.UserErrorf("expected project to produce exactly 1 executable in %s/, but found: %v", dir, allExecutables)
 	}
 
 	return allExecutables[0], nil
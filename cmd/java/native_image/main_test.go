@@ -48,6 +48,97 @@ func TestDetect(t *testing.T) {
 	}
 }
 
+func TestFindNativeBuildProfile(t *testing.T) {
+	testCases := []struct {
+		name         string
+		mavenProject *java.MavenProject
+		wantProfile  string
+		wantFound    bool
+	}{
+		{
+			name: "native build tools plugin",
+			mavenProject: &java.MavenProject{
+				Profiles: []java.MavenProfile{
+					{ID: "native", Plugins: []java.MavenPlugin{{GroupID: "org.graalvm.buildtools", ArtifactID: "native-maven-plugin"}}},
+				},
+			},
+			wantProfile: "native",
+			wantFound:   true,
+		},
+		{
+			name: "legacy native image plugin",
+			mavenProject: &java.MavenProject{
+				Profiles: []java.MavenProfile{
+					{ID: "other", Plugins: []java.MavenPlugin{{GroupID: "com.example.foo", ArtifactID: "bar-plugin"}}},
+					{ID: "graal", Plugins: []java.MavenPlugin{{GroupID: "org.graalvm.nativeimage", ArtifactID: "native-image-maven-plugin"}}},
+				},
+			},
+			wantProfile: "graal",
+			wantFound:   true,
+		},
+		{
+			name: "plugin outside of a profile",
+			mavenProject: &java.MavenProject{
+				Plugins: []java.MavenPlugin{{GroupID: "org.graalvm.buildtools", ArtifactID: "native-maven-plugin"}},
+			},
+			wantFound: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			profile, found := findNativeBuildProfile(gcp.NewContext(), tc.mavenProject)
+			if profile != tc.wantProfile || found != tc.wantFound {
+				t.Errorf("findNativeBuildProfile()=(%q, %v) want (%q, %v)", profile, found, tc.wantProfile, tc.wantFound)
+			}
+		})
+	}
+}
+
+func TestGradleNativePluginApplied(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		want  bool
+	}{
+		{
+			name:  "groovy build script",
+			files: map[string]string{"build.gradle": "plugins {\n  id 'org.graalvm.buildtools.native' version '0.9.28'\n}"},
+			want:  true,
+		},
+		{
+			name:  "kotlin build script",
+			files: map[string]string{"build.gradle.kts": "plugins {\n  id(\"org.graalvm.buildtools.native\") version \"0.9.28\"\n}"},
+			want:  true,
+		},
+		{
+			name:  "without plugin",
+			files: map[string]string{"build.gradle": "plugins {\n  id 'java'\n}"},
+			want:  false,
+		},
+		{
+			name: "no build script",
+			want: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tc.files {
+				if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := gradleNativePluginApplied(gcp.NewContext(gcp.WithApplicationRoot(dir)))
+			if err != nil {
+				t.Fatalf("gradleNativePluginApplied() got error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("gradleNativePluginApplied()=%v want %v", got, tc.want)
+			}
+		})
+	}
+}
+
 func TestFindSpringBootPlugin(t *testing.T) {
 	testCases := []struct {
 		name         string
@@ -20,20 +20,28 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/cgroup"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/java"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/runtime"
+	"github.com/buildpacks/libcnb"
 )
 
 const (
 	javaLayer             = "java"
 	defaultFeatureVersion = "11"
+	memoryLayer           = "memory-calculator"
+	// memoryExecD is the name of the exec.d helper that sizes the JVM memory at launch time.
+	memoryExecD = "memory-calculator"
 )
 
 func main() {
-	gcp.Main(detectFn, buildFn)
+	gcp.Main(detectFn, buildFn, gcp.WithExecD(memoryExecD, calculateMemory), gcp.WithFunctionSourceDir())
 }
 
 func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
@@ -76,19 +84,129 @@ func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
 }
 
 func buildFn(ctx *gcp.Context) error {
-	featureVersion := defaultFeatureVersion
-	if v := os.Getenv(env.RuntimeVersion); v != "" {
-		featureVersion = v
-		ctx.Logf("Using requested runtime feature version: %s", featureVersion)
+	featureVersion, source, err := java.RuntimeVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if featureVersion != "" {
+		ctx.Logf("Using Java feature version %s from %s.", featureVersion, source)
 	} else {
+		featureVersion = defaultFeatureVersion
 		ctx.Logf("Using latest Java %s runtime version. You can specify a different version with %s: https://github.com/GoogleCloudPlatform/buildpacks#configuration", defaultFeatureVersion, env.RuntimeVersion)
 	}
-	l, err := ctx.Layer(javaLayer, gcp.BuildLayer, gcp.CacheLayer, gcp.LaunchLayerUnlessSkipRuntimeLaunch)
+	jlink, err := env.IsPresentAndTrue(env.JavaJlink)
+	if err != nil {
+		return gcp.UserErrorf("failed to parse %s: %v", env.JavaJlink, err)
+	}
+	var l *libcnb.Layer
+	if jlink {
+		// The google.java.jlink buildpack provides a minimal runtime to the application image instead.
+		ctx.Logf("%s is set, the JDK will not be included in the application image.", env.JavaJlink)
+		l, err = ctx.Layer(javaLayer, gcp.BuildLayer, gcp.CacheLayer)
+	} else {
+		l, err = ctx.Layer(javaLayer, gcp.BuildLayer, gcp.CacheLayer, gcp.LaunchLayerUnlessSkipRuntimeLaunch)
+	}
 	if err != nil {
 		return fmt.Errorf("creating %v layer: %w", javaLayer, err)
 	}
-	_, err = runtime.InstallTarballIfNotCached(ctx, runtime.OpenJDK, featureVersion, l)
-	return err
+	if _, err := runtime.InstallTarballIfNotCached(ctx, runtime.OpenJDK, featureVersion, l); err != nil {
+		return err
+	}
+
+	ml, err := ctx.Layer(memoryLayer, gcp.LaunchLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", memoryLayer, err)
+	}
+	ctx.Logf("JVM memory will be sized to the container at launch; set %s=false or memory options in %s to override.", env.JavaMemoryCalculator, java.JavaToolOptionsEnv)
+	return ctx.AddExecD(ml, memoryExecD)
+}
+
+// calculateMemory is an exec.d helper that sizes the JVM memory to the container memory limit.
+func calculateMemory() (map[string]string, error) {
+	if v, ok := os.LookupEnv(env.JavaMemoryCalculator); ok {
+		if enabled, err := strconv.ParseBool(v); err != nil {
+			return nil, fmt.Errorf("parsing %s: %v", env.JavaMemoryCalculator, err)
+		} else if !enabled {
+			return nil, nil
+		}
+	}
+	limit, err := cgroup.MemoryLimit()
+	if err != nil {
+		return nil, err
+	}
+	threads, err := envInt(env.JavaThreadCount, java.DefaultThreadCount)
+	if err != nil {
+		return nil, err
+	}
+	classes, err := envInt(env.JavaClassCount, -1)
+	if err != nil {
+		return nil, err
+	}
+	if classes < 0 {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		if classes, err = countClasses(wd); err != nil {
+			return nil, err
+		}
+	}
+	return memoryEnv(limit, classes, threads)
+}
+
+// memoryEnv returns the launch environment with the JVM memory options, leaving user-provided
+// options intact.
+func memoryEnv(limit int64, classes, threads int) (map[string]string, error) {
+	existing := os.Getenv(java.JavaToolOptionsEnv)
+	opts, warning, err := java.MemoryOptions(limit, classes, threads, existing)
+	if err != nil {
+		return nil, fmt.Errorf("calculating JVM memory options: %w", err)
+	}
+	if warning != "" {
+		// The output of exec.d helpers other than the environment is written to the container log.
+		fmt.Fprintf(os.Stderr, "WARNING: %s\n", warning)
+	}
+	if len(opts) == 0 {
+		return nil, nil
+	}
+	return map[string]string{java.JavaToolOptionsEnv: strings.TrimSpace(existing + " " + strings.Join(opts, " "))}, nil
+}
+
+// envInt returns the integer value of the environment variable, or def if it is not set.
+func envInt(name string, def int) (int, error) {
+	v, ok := os.LookupEnv(name)
+	if !ok || v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q, it must be a non-negative integer", name, v)
+	}
+	return n, nil
+}
+
+// countClasses returns the number of classes in the class files and jars under dir. It is used when
+// the class count was not computed at build time.
+func countClasses(dir string) (int, error) {
+	count := 0
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		switch filepath.Ext(path) {
+		case ".class":
+			count++
+		case ".jar":
+			n, err := java.CountClasses(path)
+			if err != nil {
+				// Unreadable jars do not prevent the application from starting.
+				return nil
+			}
+			count += n
+		}
+		return nil
+	})
+	return count, err
 }
 
 type binaryPkg struct {
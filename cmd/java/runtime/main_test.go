@@ -15,6 +15,8 @@
 package main
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -352,3 +354,87 @@ func TestExtractReleaseFail(t *testing.T) {
 		})
 	}
 }
+
+func TestMemoryEnv(t *testing.T) {
+	const mb = 1024 * 1024
+	testCases := []struct {
+		name     string
+		existing string
+		want     map[string]string
+	}{
+		{
+			name: "no options",
+			want: map[string]string{
+				"JAVA_TOOL_OPTIONS": "-Xmx677M -XX:MaxDirectMemorySize=10M -XX:MaxMetaspaceSize=47M -XX:ReservedCodeCacheSize=240M -Xss1M",
+			},
+		},
+		{
+			name:     "user options are kept",
+			existing: "-XX:+UseSerialGC -Xmx512m",
+			want: map[string]string{
+				"JAVA_TOOL_OPTIONS": "-XX:+UseSerialGC -Xmx512m -XX:MaxDirectMemorySize=10M -XX:MaxMetaspaceSize=47M -XX:ReservedCodeCacheSize=240M -Xss1M",
+			},
+		},
+		{
+			name:     "all options set by the user",
+			existing: "-Xmx512m -Xss512k -XX:MaxMetaspaceSize=64m -XX:ReservedCodeCacheSize=64m -XX:MaxDirectMemorySize=10m",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("JAVA_TOOL_OPTIONS", tc.existing)
+			got, err := memoryEnv(1024*mb, 5000, 50)
+			if err != nil {
+				t.Fatalf("memoryEnv() got error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("memoryEnv() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEnvInt(t *testing.T) {
+	testCases := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{value: "", want: 250},
+		{value: "50", want: 50},
+		{value: "-1", wantErr: true},
+		{value: "many", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			t.Setenv("GOOGLE_JAVA_THREAD_COUNT", tc.value)
+			got, err := envInt("GOOGLE_JAVA_THREAD_COUNT", 250)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("envInt() got error: %v, want error: %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("envInt() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCountClasses(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{"com/example/Main.class", "com/example/Util.class", "static/index.html", "lib/broken.jar"} {
+		path := filepath.Join(dir, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := countClasses(dir)
+	if err != nil {
+		t.Fatalf("countClasses() got error: %v", err)
+	}
+	if got != 2 {
+		t.Errorf("countClasses() = %d, want 2", got)
+	}
+}
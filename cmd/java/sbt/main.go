@@ -0,0 +1,248 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Implements java/sbt buildpack.
+// The sbt buildpack builds Scala applications with sbt.
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/fetch"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/java"
+)
+
+const (
+	// sbtVersion is the version of the sbt launcher, which runs the sbt version of the build in
+	// project/build.properties.
+	sbtVersion = "1.9.7"
+	sbtURL     = "https://github.com/sbt/sbt/releases/download/v%[1]s/sbt-%[1]s.tgz"
+	sbtLayer   = "sbt"
+	cacheLayer = "cache"
+	versionKey = "version"
+
+	nativePackagerPlugin = "sbt-native-packager"
+	assemblyPlugin       = "sbt-assembly"
+	stageTask            = "stage"
+	assemblyTask         = "assembly"
+)
+
+var (
+	// cacheDirs are the directories of the sbt, Ivy and Coursier caches relative to the home
+	// directory, and their directories in the cache layer.
+	cacheDirs = map[string]string{
+		".sbt":                              "sbt",
+		".ivy2":                             "ivy2",
+		filepath.Join(".cache", "coursier"): "coursier",
+	}
+	// stageScripts and assemblyJars are the patterns of the start scripts of sbt-native-packager
+	// and the jars of sbt-assembly, in the root project and in subprojects.
+	stageScripts = []string{"target/universal/stage/bin/*", "*/target/universal/stage/bin/*"}
+	assemblyJars = []string{"target/scala-*/*.jar", "*/target/scala-*/*.jar"}
+)
+
+func main() {
+	gcp.Main(detectFn, buildFn)
+}
+
+func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
+	exists, err := ctx.FileExists("build.sbt")
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return gcp.OptInFileFound("build.sbt"), nil
+	}
+	return gcp.OptOutFileNotFound("build.sbt"), nil
+}
+
+func buildFn(ctx *gcp.Context) error {
+	cl, err := ctx.Layer(cacheLayer, gcp.CacheLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", cacheLayer, err)
+	}
+	if err := java.CheckCacheExpiration(ctx, cl); err != nil {
+		return fmt.Errorf("validating the cache: %w", err)
+	}
+	// Symlink the cache directories into the home directory, where sbt, Ivy and Coursier keep
+	// their caches by default.
+	for home, dir := range cacheDirs {
+		target := filepath.Join(cl.Path, dir)
+		if err := ctx.MkdirAll(target, 0755); err != nil {
+			return err
+		}
+		link := filepath.Join(ctx.HomeDir(), home)
+		if err := ctx.RemoveAll(link); err != nil {
+			return err
+		}
+		if err := ctx.MkdirAll(filepath.Dir(link), 0755); err != nil {
+			return err
+		}
+		if err := ctx.Symlink(target, link); err != nil {
+			return err
+		}
+	}
+
+	sbt, err := provisionOrDetectSbt(ctx)
+	if err != nil {
+		return err
+	}
+	tasks, err := sbtTasks(ctx)
+	if err != nil {
+		return err
+	}
+	command := append([]string{sbt, "-batch", "clean"}, tasks...)
+	if buildArgs := os.Getenv(env.BuildArgs); buildArgs != "" {
+		command = append(command, strings.Fields(buildArgs)...)
+	}
+	if _, err := ctx.Exec(command, gcp.WithUserAttribution); err != nil {
+		return err
+	}
+
+	process, err := webProcess(ctx)
+	if err != nil {
+		return err
+	}
+	ctx.AddWebProcess(process)
+	return nil
+}
+
+// sbtTasks returns the tasks that build the application, from GOOGLE_SBT_TASKS or based on the
+// packaging plugin of the build.
+func sbtTasks(ctx *gcp.Context) ([]string, error) {
+	if tasks := strings.Fields(os.Getenv(env.SbtTasks)); len(tasks) > 0 {
+		return tasks, nil
+	}
+	plugins, err := sbtPlugins(ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case strings.Contains(plugins, nativePackagerPlugin):
+		return []string{stageTask}, nil
+	case strings.Contains(plugins, assemblyPlugin):
+		return []string{assemblyTask}, nil
+	}
+	return nil, gcp.UserErrorf("unable to determine how to package the application, add %s or %s to project/plugins.sbt, or set the tasks that build the application with %s", nativePackagerPlugin, assemblyPlugin, env.SbtTasks)
+}
+
+// sbtPlugins returns the contents of the plugin definitions of the build.
+func sbtPlugins(ctx *gcp.Context) (string, error) {
+	files, err := ctx.Glob(filepath.Join(ctx.ApplicationRoot(), "project", "*.sbt"))
+	if err != nil {
+		return "", fmt.Errorf("finding plugin definitions: %w", err)
+	}
+	var sb strings.Builder
+	for _, f := range files {
+		content, err := ctx.ReadFile(f)
+		if err != nil {
+			return "", err
+		}
+		sb.Write(content)
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
+
+// webProcess returns the command that runs the application, the start script of
+// sbt-native-packager or the executable jar of sbt-assembly.
+func webProcess(ctx *gcp.Context) ([]string, error) {
+	var scripts []string
+	for _, pattern := range stageScripts {
+		matches, err := ctx.Glob(filepath.Join(ctx.ApplicationRoot(), pattern))
+		if err != nil {
+			return nil, fmt.Errorf("finding start scripts: %w", err)
+		}
+		for _, m := range matches {
+			// Windows start scripts are staged next to the Unix ones.
+			if !strings.HasSuffix(m, ".bat") {
+				scripts = append(scripts, m)
+			}
+		}
+	}
+	if len(scripts) == 1 {
+		return []string{scripts[0]}, nil
+	} else if len(scripts) > 1 {
+		return nil, gcp.UserErrorf("found more than one start script: %v, please specify an entrypoint", scripts)
+	}
+
+	var jars []string
+	for _, pattern := range assemblyJars {
+		matches, err := ctx.Glob(filepath.Join(ctx.ApplicationRoot(), pattern))
+		if err != nil {
+			return nil, fmt.Errorf("finding jars: %w", err)
+		}
+		for _, m := range matches {
+			main, err := java.MainManifestEntry(m)
+			if err != nil {
+				ctx.Warnf("Failed to inspect %s, skipping: %v.", m, err)
+				continue
+			}
+			if main != "" {
+				jars = append(jars, m)
+			}
+		}
+	}
+	if len(jars) == 1 {
+		return []string{"java", "-jar", jars[0]}, nil
+	} else if len(jars) > 1 {
+		return nil, gcp.UserErrorf("found more than one jar with a Main-Class manifest entry: %v, please specify an entrypoint", jars)
+	}
+	return nil, gcp.UserErrorf("did not find a start script in target/universal/stage/bin or a jar with a Main-Class manifest entry in target/scala-*")
+}
+
+func provisionOrDetectSbt(ctx *gcp.Context) (string, error) {
+	result, err := ctx.Exec([]string{"bash", "-c", "command -v sbt || true"})
+	if err != nil {
+		return "", err
+	}
+	if result.Stdout != "" {
+		return "sbt", nil
+	}
+	sbt, err := installSbt(ctx)
+	if err != nil {
+		return "", fmt.Errorf("installing sbt: %w", err)
+	}
+	return sbt, nil
+}
+
+// installSbt installs the sbt launcher and returns the path of the sbt script.
+func installSbt(ctx *gcp.Context) (string, error) {
+	l, err := ctx.Layer(sbtLayer, gcp.CacheLayer, gcp.BuildLayer)
+	if err != nil {
+		return "", fmt.Errorf("creating %v layer: %w", sbtLayer, err)
+	}
+	sbt := filepath.Join(l.Path, "bin", "sbt")
+	if ctx.GetMetadata(l, versionKey) == sbtVersion {
+		ctx.CacheHit(sbtLayer)
+		ctx.Logf("sbt cache hit, skipping installation.")
+		return sbt, nil
+	}
+	ctx.CacheMiss(sbtLayer)
+	if err := ctx.ClearLayer(l); err != nil {
+		return "", fmt.Errorf("clearing layer %q: %w", l.Name, err)
+	}
+	url := fmt.Sprintf(sbtURL, sbtVersion)
+	ctx.Logf("Installing sbt v%s", sbtVersion)
+	if err := fetch.Tarball(url, l.Path, 1); err != nil {
+		return "", err
+	}
+	ctx.SetMetadata(l, versionKey, sbtVersion)
+	return sbt, nil
+}
@@ -0,0 +1,199 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	buildpacktest "github.com/GoogleCloudPlatform/buildpacks/internal/buildpacktest"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		want  int
+	}{
+		{
+			name:  "build.sbt",
+			files: map[string]string{"build.sbt": ""},
+			want:  0,
+		},
+		{
+			name:  "no build.sbt",
+			files: map[string]string{"pom.xml": ""},
+			want:  100,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buildpacktest.TestDetect(t, detectFn, tc.name, tc.files, []string{}, tc.want)
+		})
+	}
+}
+
+func TestSbtTasks(t *testing.T) {
+	testCases := []struct {
+		name    string
+		files   map[string]string
+		env     string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "native packager",
+			files: map[string]string{"project/plugins.sbt": `addSbtPlugin("com.github.sbt" % "sbt-native-packager" % "1.9.16")`},
+			want:  []string{"stage"},
+		},
+		{
+			name:  "assembly",
+			files: map[string]string{"project/assembly.sbt": `addSbtPlugin("com.eed3si9n" % "sbt-assembly" % "2.1.5")`},
+			want:  []string{"assembly"},
+		},
+		{
+			name:  "env",
+			files: map[string]string{"project/plugins.sbt": `addSbtPlugin("com.github.sbt" % "sbt-native-packager" % "1.9.16")`},
+			env:   "server/stage  worker/stage",
+			want:  []string{"server/stage", "worker/stage"},
+		},
+		{
+			name:    "no packaging plugin",
+			files:   map[string]string{"project/plugins.sbt": `addSbtPlugin("org.scalameta" % "sbt-scalafmt" % "2.5.2")`},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("GOOGLE_SBT_TASKS", tc.env)
+			root := writeFiles(t, tc.files)
+
+			got, err := sbtTasks(gcp.NewContext(gcp.WithApplicationRoot(root)))
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("sbtTasks() got error %v, want error %t", err, tc.wantErr)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("sbtTasks() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWebProcess(t *testing.T) {
+	testCases := []struct {
+		name    string
+		files   map[string]string
+		jars    map[string]string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "stage",
+			files: map[string]string{"target/universal/stage/bin/app": "", "target/universal/stage/bin/app.bat": ""},
+			want:  []string{"ROOT/target/universal/stage/bin/app"},
+		},
+		{
+			name:  "subproject stage",
+			files: map[string]string{"server/target/universal/stage/bin/server": ""},
+			want:  []string{"ROOT/server/target/universal/stage/bin/server"},
+		},
+		{
+			name: "assembly",
+			jars: map[string]string{
+				"target/scala-2.13/app-assembly-0.1.jar": "Main-Class: com.example.Main\n",
+				"target/scala-2.13/app_2.13-0.1.jar":     "Manifest-Version: 1.0\n",
+			},
+			want: []string{"java", "-jar", "ROOT/target/scala-2.13/app-assembly-0.1.jar"},
+		},
+		{
+			name:    "multiple start scripts",
+			files:   map[string]string{"a/target/universal/stage/bin/a": "", "b/target/universal/stage/bin/b": ""},
+			wantErr: true,
+		},
+		{
+			name:    "no output",
+			files:   map[string]string{"target/streams/out": ""},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := writeFiles(t, tc.files)
+			for name, manifest := range tc.jars {
+				writeJar(t, filepath.Join(root, name), manifest)
+			}
+
+			got, err := webProcess(gcp.NewContext(gcp.WithApplicationRoot(root)))
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("webProcess() got error %v, want error %t", err, tc.wantErr)
+			}
+			var want []string
+			for _, arg := range tc.want {
+				if strings.HasPrefix(arg, "ROOT/") {
+					arg = filepath.Join(root, strings.TrimPrefix(arg, "ROOT/"))
+				}
+				want = append(want, arg)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("webProcess() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func writeJar(t *testing.T, path, manifest string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	e, err := w.Create("META-INF/MANIFEST.MF")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Write([]byte(manifest)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
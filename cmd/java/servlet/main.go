@@ -0,0 +1,224 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Implements java/servlet buildpack.
+// The servlet buildpack deploys a WAR file to a servlet container, Tomcat or Jetty.
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/fetch"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/java"
+	"github.com/buildpacks/libcnb"
+)
+
+const (
+	containerLayer = "servlet-container"
+	// baseLayer contains the configuration and the deployed application of the container instance.
+	baseLayer    = "servlet-base"
+	containerKey = "container"
+	versionKey   = "version"
+
+	tomcat = "tomcat"
+	jetty  = "jetty"
+
+	defaultPort = "8080"
+	// portProperty is the system property of the Tomcat HTTP connector port.
+	portProperty = "port.http"
+)
+
+// container is a servlet container distribution.
+type container struct {
+	// version is the default version, for containers that implement the jakarta.servlet API.
+	version string
+	// url returns the URL of the tarball of the given version.
+	url func(version string) string
+}
+
+var containers = map[string]container{
+	tomcat: {
+		version: "10.1.15",
+		url: func(version string) string {
+			major := strings.SplitN(version, ".", 2)[0]
+			return fmt.Sprintf("https://archive.apache.org/dist/tomcat/tomcat-%s/v%s/bin/apache-tomcat-%s.tar.gz", major, version, version)
+		},
+	},
+	jetty: {
+		version: "11.0.17",
+		url: func(version string) string {
+			return fmt.Sprintf("https://repo1.maven.org/maven2/org/eclipse/jetty/jetty-home/%s/jetty-home-%s.tar.gz", version, version)
+		},
+	},
+}
+
+func main() {
+	gcp.Main(detectFn, buildFn)
+}
+
+func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
+	war, err := java.IsWarProject(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !war {
+		return gcp.OptOut("the application does not build a WAR file"), nil
+	}
+	return gcp.OptIn("the application builds a WAR file"), nil
+}
+
+func buildFn(ctx *gcp.Context) error {
+	war, err := java.WarFile(ctx)
+	if err != nil {
+		return fmt.Errorf("finding war file: %w", err)
+	}
+	name, version, err := containerVersion()
+	if err != nil {
+		return err
+	}
+	cl, err := ctx.Layer(containerLayer, gcp.LaunchLayer, gcp.CacheLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", containerLayer, err)
+	}
+	if err := installContainer(ctx, cl, name, version); err != nil {
+		return err
+	}
+	bl, err := ctx.Layer(baseLayer, gcp.LaunchLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", baseLayer, err)
+	}
+	if err := ctx.ClearLayer(bl); err != nil {
+		return fmt.Errorf("clearing layer %q: %w", bl.Name, err)
+	}
+	bl.LaunchEnvironment.Default("PORT", defaultPort)
+
+	ctx.Logf("Deploying %s to %s %s.", war, name, version)
+	var command []string
+	if name == jetty {
+		command, err = deployJetty(ctx, cl.Path, bl.Path, war)
+	} else {
+		command, err = deployTomcat(ctx, cl.Path, bl, war)
+	}
+	if err != nil {
+		return err
+	}
+	ctx.AddWebProcess(command)
+	return nil
+}
+
+// containerVersion returns the servlet container and its version, from
+// GOOGLE_JAVA_SERVLET_CONTAINER and GOOGLE_JAVA_SERVLET_CONTAINER_VERSION.
+func containerVersion() (string, string, error) {
+	name := strings.ToLower(strings.TrimSpace(os.Getenv(env.JavaServletContainer)))
+	if name == "" {
+		name = tomcat
+	}
+	c, ok := containers[name]
+	if !ok {
+		return "", "", gcp.UserErrorf("unsupported servlet container %q in %s, supported containers are: %s, %s", name, env.JavaServletContainer, jetty, tomcat)
+	}
+	version := strings.TrimSpace(os.Getenv(env.JavaServletContainerVersion))
+	if version == "" {
+		version = c.version
+	}
+	return name, version, nil
+}
+
+// installContainer downloads the servlet container into the layer, unless the same version is
+// already cached.
+func installContainer(ctx *gcp.Context, l *libcnb.Layer, name, version string) error {
+	if ctx.GetMetadata(l, containerKey) == name && ctx.GetMetadata(l, versionKey) == version {
+		ctx.CacheHit(containerLayer)
+		ctx.Logf("%s %s cache hit, skipping installation.", name, version)
+		return nil
+	}
+	ctx.CacheMiss(containerLayer)
+	if err := ctx.ClearLayer(l); err != nil {
+		return fmt.Errorf("clearing layer %q: %w", l.Name, err)
+	}
+	url := containers[name].url(version)
+	ctx.Logf("Installing %s %s from %s", name, version, url)
+	if err := fetch.Tarball(url, l.Path, 1); err != nil {
+		return gcp.UserErrorf("installing %s %s, check that the version in %s exists: %v", name, version, env.JavaServletContainerVersion, err)
+	}
+	ctx.SetMetadata(l, containerKey, name)
+	ctx.SetMetadata(l, versionKey, version)
+	return nil
+}
+
+// deployTomcat creates a Tomcat instance in the base layer with the configuration of the Tomcat
+// installation, deploys the WAR as the root application and returns the command that runs Tomcat.
+func deployTomcat(ctx *gcp.Context, home string, l *libcnb.Layer, war string) ([]string, error) {
+	if _, err := ctx.Exec([]string{"cp", "--archive", filepath.Join(home, "conf"), l.Path}); err != nil {
+		return nil, err
+	}
+	serverXML := filepath.Join(l.Path, "conf", "server.xml")
+	content, err := ctx.ReadFile(serverXML)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.WriteFile(serverXML, tomcatServerXML(content), 0644); err != nil {
+		return nil, err
+	}
+	for _, dir := range []string{"bin", "logs", "temp", "work", "webapps"} {
+		if err := ctx.MkdirAll(filepath.Join(l.Path, dir), 0755); err != nil {
+			return nil, err
+		}
+	}
+	// setenv.sh is sourced by catalina.sh and sets the connector port from PORT at launch.
+	setenv := fmt.Sprintf("CATALINA_OPTS=\"$CATALINA_OPTS -D%s=${PORT:-%s}\"\n", portProperty, defaultPort)
+	if err := ctx.WriteFile(filepath.Join(l.Path, "bin", "setenv.sh"), []byte(setenv), 0755); err != nil {
+		return nil, err
+	}
+	// The WAR is extracted at build time, the container filesystem may be read-only at launch.
+	if _, err := ctx.Exec([]string{"unzip", "-q", war, "-d", filepath.Join(l.Path, "webapps", "ROOT")}); err != nil {
+		return nil, err
+	}
+	l.LaunchEnvironment.Override("CATALINA_HOME", home)
+	l.LaunchEnvironment.Override("CATALINA_BASE", l.Path)
+	return []string{filepath.Join(home, "bin", "catalina.sh"), "run"}, nil
+}
+
+// tomcatServerXML returns the server.xml with the HTTP connector port read from a system property
+// and the shutdown port disabled.
+func tomcatServerXML(content []byte) []byte {
+	s := string(content)
+	s = strings.Replace(s, `<Server port="8005"`, `<Server port="-1"`, 1)
+	s = strings.Replace(s, `<Connector port="8080"`, fmt.Sprintf(`<Connector port="${%s}"`, portProperty), 1)
+	return []byte(s)
+}
+
+// deployJetty creates a Jetty base in the layer, deploys the WAR as the root application and
+// returns the command that runs Jetty.
+func deployJetty(ctx *gcp.Context, home, base, war string) ([]string, error) {
+	startJar := filepath.Join(home, "start.jar")
+	if _, err := ctx.Exec([]string{"java", "-jar", startJar, "jetty.home=" + home, "jetty.base=" + base, "--add-modules=server,http,deploy"}, gcp.WithWorkDir(base), gcp.WithUserAttribution); err != nil {
+		return nil, err
+	}
+	webapps := filepath.Join(base, "webapps")
+	if err := ctx.MkdirAll(webapps, 0755); err != nil {
+		return nil, err
+	}
+	if _, err := ctx.Exec([]string{"cp", war, filepath.Join(webapps, "ROOT.war")}); err != nil {
+		return nil, err
+	}
+	// The shell expands PORT at launch.
+	run := fmt.Sprintf("exec java -jar %s jetty.home=%s jetty.base=%s jetty.http.port=${PORT:-%s}", startJar, home, base, defaultPort)
+	return []string{"/bin/bash", "-c", run}, nil
+}
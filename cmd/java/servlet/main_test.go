@@ -0,0 +1,152 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	buildpacktest "github.com/GoogleCloudPlatform/buildpacks/internal/buildpacktest"
+)
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		env   []string
+		want  int
+	}{
+		{
+			name:  "war packaging",
+			files: map[string]string{"pom.xml": "<project><packaging>war</packaging></project>"},
+			want:  0,
+		},
+		{
+			name:  "jar packaging",
+			files: map[string]string{"pom.xml": "<project><packaging>jar</packaging></project>"},
+			want:  100,
+		},
+		{
+			name:  "gradle war plugin",
+			files: map[string]string{"build.gradle": "plugins {\n  id 'war'\n}"},
+			want:  0,
+		},
+		{
+			name:  "prebuilt war",
+			files: map[string]string{"app.war": ""},
+			want:  0,
+		},
+		{
+			name:  "no war",
+			files: map[string]string{"app.jar": ""},
+			want:  100,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buildpacktest.TestDetect(t, detectFn, tc.name, tc.files, tc.env, tc.want)
+		})
+	}
+}
+
+func TestContainerVersion(t *testing.T) {
+	testCases := []struct {
+		name        string
+		container   string
+		version     string
+		wantName    string
+		wantVersion string
+		wantErr     bool
+	}{
+		{
+			name:        "default",
+			wantName:    "tomcat",
+			wantVersion: "10.1.15",
+		},
+		{
+			name:        "jetty",
+			container:   "Jetty",
+			wantName:    "jetty",
+			wantVersion: "11.0.17",
+		},
+		{
+			name:        "tomcat version",
+			container:   "tomcat",
+			version:     "9.0.82",
+			wantName:    "tomcat",
+			wantVersion: "9.0.82",
+		},
+		{
+			name:      "unsupported",
+			container: "wildfly",
+			wantErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("GOOGLE_JAVA_SERVLET_CONTAINER", tc.container)
+			t.Setenv("GOOGLE_JAVA_SERVLET_CONTAINER_VERSION", tc.version)
+
+			name, version, err := containerVersion()
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("containerVersion() got error %v, want error %t", err, tc.wantErr)
+			}
+			if name != tc.wantName || version != tc.wantVersion {
+				t.Errorf("containerVersion() = %q, %q, want %q, %q", name, version, tc.wantName, tc.wantVersion)
+			}
+		})
+	}
+}
+
+func TestContainerURL(t *testing.T) {
+	testCases := []struct {
+		container string
+		version   string
+		want      string
+	}{
+		{
+			container: "tomcat",
+			version:   "9.0.82",
+			want:      "https://archive.apache.org/dist/tomcat/tomcat-9/v9.0.82/bin/apache-tomcat-9.0.82.tar.gz",
+		},
+		{
+			container: "jetty",
+			version:   "11.0.17",
+			want:      "https://repo1.maven.org/maven2/org/eclipse/jetty/jetty-home/11.0.17/jetty-home-11.0.17.tar.gz",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.container, func(t *testing.T) {
+			if got := containers[tc.container].url(tc.version); got != tc.want {
+				t.Errorf("url(%q) = %q, want %q", tc.version, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTomcatServerXML(t *testing.T) {
+	content := `<Server port="8005" shutdown="SHUTDOWN">
+  <Service name="Catalina">
+    <Connector port="8080" protocol="HTTP/1.1" connectionTimeout="20000" redirectPort="8443" />
+  </Service>
+</Server>`
+	want := `<Server port="-1" shutdown="SHUTDOWN">
+  <Service name="Catalina">
+    <Connector port="${port.http}" protocol="HTTP/1.1" connectionTimeout="20000" redirectPort="8443" />
+  </Service>
+</Server>`
+	if got := string(tomcatServerXML([]byte(content))); got != want {
+		t.Errorf("tomcatServerXML() = %q, want %q", got, want)
+	}
+}
@@ -21,9 +21,11 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/ar"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/cache"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/cloudfunctions"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/nodejs"
@@ -39,7 +41,7 @@ const (
 )
 
 func main() {
-	gcp.Main(detectFn, buildFn)
+	gcp.Main(detectFn, buildFn, gcp.WithFunctionSourceDir())
 }
 
 func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
@@ -49,6 +51,9 @@ func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
 	if _, ok := os.LookupEnv(env.FunctionTarget); ok {
 		return gcp.OptInEnvSet(env.FunctionTarget), nil
 	}
+	if _, ok := os.LookupEnv(env.FunctionTargets); ok {
+		return gcp.OptInEnvSet(env.FunctionTargets), nil
+	}
 	return gcp.OptOutEnvNotSet(env.FunctionTarget), nil
 }
 
@@ -59,7 +64,8 @@ func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
 // For a function that does not, also install the framework.
 func buildFn(ctx *gcp.Context) error {
 	if _, ok := os.LookupEnv(env.FunctionSource); ok {
-		return gcp.UserErrorf("%s is not currently supported for Node.js buildpacks", env.FunctionSource)
+		// A directory is the application root, only files are unsupported.
+		return gcp.UserErrorf("%s must be a directory of the application for Node.js buildpacks", env.FunctionSource)
 	}
 
 	indexJSExists, err := ctx.FileExists("index.js")
@@ -93,6 +99,10 @@ func buildFn(ctx *gcp.Context) error {
 	if !fnFileExists {
 		return gcp.UserErrorf("%s does not exist", fnFile)
 	}
+	declared, err := checkFunctionTargets(ctx, fnFile)
+	if err != nil {
+		return err
+	}
 
 	yarnPnP, err := usingYarnModuleResolution(ctx)
 	if err != nil {
@@ -134,6 +144,8 @@ func buildFn(ctx *gcp.Context) error {
 	// npm/yarn buildpack. Otherwise, it will be in the layer's node_modules,
 	// installed below.
 	ff := filepath.Join(".bin", "functions-framework")
+	// smokeTestEnv is the environment of the function at launch that is not set at build time.
+	var smokeTestEnv []string
 
 	if yarnPnP {
 		// In order for node module resolution to work in Yarn Plug'n'Play mode, we must invoke yarn to
@@ -145,6 +157,9 @@ func buildFn(ctx *gcp.Context) error {
 			return fmt.Errorf("clearing layer %q: %w", l.Name, err)
 		}
 		ff = filepath.Join("node_modules", ff)
+		if os.Getenv(cloudfunctions.NodeJSFramework.VersionEnv) != "" {
+			ctx.Warnf("Ignoring %s because the function already depends on %s.", cloudfunctions.NodeJSFramework.VersionEnv, functionsFrameworkPackage)
+		}
 	} else {
 		ctx.Logf("Handling functions without dependency on functions-framework.")
 
@@ -162,6 +177,7 @@ func buildFn(ctx *gcp.Context) error {
 		// Add user's node_modules to NODE_PATH so functions-framework can always find user's packages.
 		if nmExists {
 			l.LaunchEnvironment.Prepend("NODE_PATH", string(os.PathListSeparator), nm)
+			smokeTestEnv = append(smokeTestEnv, "NODE_PATH="+nm)
 		}
 	}
 
@@ -176,30 +192,96 @@ func buildFn(ctx *gcp.Context) error {
 	if err := ctx.SetFunctionsEnvVars(l); err != nil {
 		return err
 	}
-	ctx.AddWebProcess([]string{"/bin/bash", "-c", ff})
-	return nil
+	cmd := []string{"/bin/bash", "-c", ff}
+	smokeTest, err := cloudfunctions.SmokeTestEnabled()
+	if err != nil {
+		return err
+	}
+	if smokeTest {
+		if err := cloudfunctions.SmokeTest(ctx, cmd, smokeTestEnv, declared); err != nil {
+			return err
+		}
+	}
+	args, err := cloudfunctions.NodeJSFramework.Args()
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		// The flag values are restricted to characters that are safe in the command line.
+		cmd = []string{"/bin/bash", "-c", ff + " " + strings.Join(args, " ")}
+	}
+	return ctx.AddFunctionProcesses(cmd)
+}
+
+// checkFunctionTargets fails the build if the function file registers functions with the
+// Functions Framework but not the function targets, and does not export them either, or if the
+// handlers of the targets do not match their signature types. It returns the functions registered
+// in the file.
+func checkFunctionTargets(ctx *gcp.Context, fnFile string) ([]cloudfunctions.DeclaredFunction, error) {
+	content, err := ctx.ReadFile(fnFile)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := gcp.FunctionTargets()
+	if err != nil {
+		return nil, err
+	}
+	src := string(content)
+	declared := cloudfunctions.NodeJSDeclaredFunctions(src)
+	for _, target := range targets {
+		if err := cloudfunctions.CheckFunctionTarget(ctx, target, fnFile, declared, cloudfunctions.NodeJSExportsFunction(src, target)); err != nil {
+			return nil, err
+		}
+		if err := cloudfunctions.CheckNodeJSSignature(target, fnFile, src, declared); err != nil {
+			return nil, err
+		}
+	}
+	return declared, nil
 }
 
-// installFunctionsFramework downloads the functions-framework package to node_modules in the given layer.
+// installFunctionsFramework downloads the functions-framework package to node_modules in the given
+// layer: the version pinned with GOOGLE_NODEJS_FUNCTIONS_FRAMEWORK_VERSION, otherwise the version
+// locked in the converter.
 func installFunctionsFramework(ctx *gcp.Context, l *libcnb.Layer) error {
+	version, pinned, err := cloudfunctions.NodeJSFramework.Version()
+	if err != nil {
+		return err
+	}
+	nodeVer, err := nodejs.NodeVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if err := cloudfunctions.NodeJSFramework.CheckRuntime(ctx, version, nodeVer); err != nil {
+		return err
+	}
+
 	cvt := filepath.Join(ctx.BuildpackRoot(), "converter", "without-framework")
 	pjs := filepath.Join(cvt, "package.json")
 	pljs := filepath.Join(cvt, nodejs.PackageLock)
 
-	cached, err := nodejs.CheckOrClearCache(ctx, l, cache.WithStrings(nodejs.EnvProduction), cache.WithFiles(pjs, pljs))
+	cached, err := nodejs.CheckOrClearCache(ctx, l, cache.WithStrings(nodejs.EnvProduction, version), cache.WithFiles(pjs, pljs))
 	if err != nil {
 		return fmt.Errorf("checking cache: %w", err)
 	}
 	if cached {
 		return nil
 	}
-	installCmd, err := nodejs.NPMInstallCommand(ctx)
-	if err != nil {
-		return err
-	}
-	// NPM expects package.json and the lock file in the prefix directory.
-	if _, err := ctx.Exec([]string{"cp", "-t", l.Path, pjs, pljs}, gcp.WithUserTimingAttribution); err != nil {
-		return err
+	installCmd := "install"
+	if pinned {
+		ctx.Logf("Using %s %s from %s.", functionsFrameworkPackage, version, cloudfunctions.NodeJSFramework.VersionEnv)
+		content := fmt.Sprintf("{\"name\": \"functions-framework\", \"dependencies\": {%q: %q}}\n", functionsFrameworkPackage, version)
+		if err := ctx.WriteFile(filepath.Join(l.Path, "package.json"), []byte(content), 0644); err != nil {
+			return err
+		}
+	} else {
+		installCmd, err = nodejs.NPMInstallCommand(ctx)
+		if err != nil {
+			return err
+		}
+		// NPM expects package.json and the lock file in the prefix directory.
+		if _, err := ctx.Exec([]string{"cp", "-t", l.Path, pjs, pljs}, gcp.WithUserTimingAttribution); err != nil {
+			return err
+		}
 	}
 	if err := ar.GenerateNPMConfig(ctx); err != nil {
 		return fmt.Errorf("generating Artifact Registry credentials: %w", err)
@@ -207,6 +289,7 @@ func installFunctionsFramework(ctx *gcp.Context, l *libcnb.Layer) error {
 	if _, err := ctx.Exec([]string{"npm", installCmd, "--quiet", "--production", "--prefix", l.Path}, gcp.WithUserAttribution); err != nil {
 		return err
 	}
+	cloudfunctions.RecordVersion(ctx, l, version)
 	return nil
 }
 
@@ -36,6 +36,11 @@ func TestDetect(t *testing.T) {
 			env:  []string{"GOOGLE_FUNCTION_TARGET=helloWorld"},
 			want: 0,
 		},
+		{
+			name: "with targets",
+			env:  []string{"GOOGLE_FUNCTION_TARGETS=hello,world"},
+			want: 0,
+		},
 		{
 			name: "with target and GOOGLE_RUNTIME",
 			env:  []string{"GOOGLE_FUNCTION_TARGET=helloWorld", "GOOGLE_RUNTIME=nodejs10"},
@@ -0,0 +1,75 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Exports each background function of the module at FUNCTION_COMPAT_SOURCE as a
+// CloudEvent function, which calls the background function with the data and
+// context of the legacy event.
+'use strict';
+
+const userModule = require(process.env.FUNCTION_COMPAT_SOURCE);
+
+const PUBSUB_MESSAGE_PUBLISHED = 'google.cloud.pubsub.topic.v1.messagePublished';
+
+// The legacy event types of the CloudEvent types.
+const EVENT_TYPES = {
+  [PUBSUB_MESSAGE_PUBLISHED]: 'google.pubsub.topic.publish',
+  'google.cloud.storage.object.v1.finalized': 'google.storage.object.finalize',
+  'google.cloud.storage.object.v1.deleted': 'google.storage.object.delete',
+  'google.cloud.storage.object.v1.archived': 'google.storage.object.archive',
+  'google.cloud.storage.object.v1.metadataUpdated':
+    'google.storage.object.metadataUpdate',
+};
+
+// Returns the data and context of the legacy event of a CloudEvent. The
+// resource is the source without the service, followed by the subject, e.g.
+// projects/_/buckets/my-bucket/objects/my-object.
+function toBackgroundEvent(cloudEvent) {
+  let data = cloudEvent.data;
+  let resource = (cloudEvent.source || '').replace(/^\/\/[^/]+\//, '');
+  if (cloudEvent.subject) {
+    resource += '/' + cloudEvent.subject;
+  }
+  if (cloudEvent.type === PUBSUB_MESSAGE_PUBLISHED && data && data.message) {
+    data = data.message;
+  }
+  return {
+    data,
+    context: {
+      eventId: cloudEvent.id,
+      timestamp: cloudEvent.time,
+      eventType: EVENT_TYPES[cloudEvent.type] || cloudEvent.type,
+      resource,
+    },
+  };
+}
+
+// Returns a CloudEvent function that calls the background function, which
+// either returns a promise or, if it declares a third parameter, calls back.
+function wrap(fn) {
+  return cloudEvent => {
+    const {data, context} = toBackgroundEvent(cloudEvent);
+    if (fn.length >= 3) {
+      return new Promise((resolve, reject) => {
+        fn(data, context, (err, result) => (err ? reject(err) : resolve(result)));
+      });
+    }
+    return fn(data, context);
+  };
+}
+
+for (const [name, fn] of Object.entries(userModule)) {
+  if (typeof fn === 'function') {
+    module.exports[name] = wrap(fn);
+  }
+}
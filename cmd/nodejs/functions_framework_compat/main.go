@@ -0,0 +1,107 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Implements nodejs/functions_framework_compat buildpack.
+// The functions_framework_compat buildpack serves legacy background functions as CloudEvent
+// functions, for platforms that only deliver CloudEvents.
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/nodejs"
+)
+
+const (
+	layerName = "functions-framework-compat"
+	// compatSourceEnv is the launch time env var that tells the converter which module exports the
+	// background functions.
+	compatSourceEnv = "FUNCTION_COMPAT_SOURCE"
+)
+
+func main() {
+	gcp.Main(detectFn, buildFn, gcp.WithFunctionSourceDir())
+}
+
+func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
+	enabled, err := env.IsPresentAndTrue(env.FunctionCloudEventCompat)
+	if err != nil {
+		return nil, gcp.UserErrorf("%v", err)
+	}
+	if !enabled {
+		return gcp.OptOutEnvNotSet(env.FunctionCloudEventCompat), nil
+	}
+	if _, ok := os.LookupEnv(env.FunctionTarget); !ok {
+		if _, ok := os.LookupEnv(env.FunctionTargets); !ok {
+			return gcp.OptOutEnvNotSet(env.FunctionTarget), nil
+		}
+	}
+	if st := os.Getenv(env.FunctionSignatureType); st != "event" {
+		return gcp.OptOut(fmt.Sprintf("env var %s is not set to event", env.FunctionSignatureType)), nil
+	}
+	return gcp.OptInEnvSet(env.FunctionCloudEventCompat), nil
+}
+
+// buildFn adds the converter to a launch layer and points the Functions Framework at it. The
+// converter exports each function of the application module as a CloudEvent function that calls
+// the background function with the data and context of the event.
+func buildFn(ctx *gcp.Context) error {
+	fnFile, err := functionFile(ctx)
+	if err != nil {
+		return err
+	}
+	l, err := ctx.Layer(layerName, gcp.LaunchLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", layerName, err)
+	}
+	content, err := ctx.ReadFile(filepath.Join(ctx.BuildpackRoot(), "converter", "background.js"))
+	if err != nil {
+		return err
+	}
+	converter := filepath.Join(l.Path, "background.js")
+	if err := ctx.WriteFile(converter, content, 0644); err != nil {
+		return err
+	}
+
+	ctx.Logf("Serving the background functions of %s as CloudEvent functions.", fnFile)
+	l.LaunchEnvironment.Override(compatSourceEnv, filepath.Join(ctx.ApplicationRoot(), fnFile))
+	l.LaunchEnvironment.Override(env.FunctionSourceLaunch, converter)
+	l.LaunchEnvironment.Override(env.FunctionSignatureTypeLaunch, "cloudevent")
+	return nil
+}
+
+// functionFile returns the file of the function source code, as the nodejs/functions_framework
+// buildpack resolves it: the "main" field in package.json, index.js or function.js.
+func functionFile(ctx *gcp.Context) (string, error) {
+	fnFile := "function.js"
+	indexJSExists, err := ctx.FileExists("index.js")
+	if err != nil {
+		return "", err
+	}
+	if indexJSExists {
+		fnFile = "index.js"
+	}
+	pjs, err := nodejs.ReadPackageJSONIfExists(ctx.ApplicationRoot())
+	if err != nil {
+		return "", fmt.Errorf("reading package.json: %w", err)
+	}
+	if pjs != nil && pjs.Main != "" {
+		fnFile = pjs.Main
+	}
+	return fnFile, nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	buildpacktest "github.com/GoogleCloudPlatform/buildpacks/internal/buildpacktest"
+)
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		env   []string
+		want  int
+	}{
+		{
+			name: "optIn",
+			env: []string{
+				"GOOGLE_FUNCTION_TARGET=helloWorld",
+				"GOOGLE_FUNCTION_SIGNATURE_TYPE=event",
+				"GOOGLE_FUNCTION_CLOUDEVENT_COMPAT=true",
+			},
+			want: 0,
+		},
+		{
+			name: "optInWithTargets",
+			env: []string{
+				"GOOGLE_FUNCTION_TARGETS=first,second",
+				"GOOGLE_FUNCTION_SIGNATURE_TYPE=event",
+				"GOOGLE_FUNCTION_CLOUDEVENT_COMPAT=true",
+			},
+			want: 0,
+		},
+		{
+			name: "optOutNotEnabled",
+			env: []string{
+				"GOOGLE_FUNCTION_TARGET=helloWorld",
+				"GOOGLE_FUNCTION_SIGNATURE_TYPE=event",
+				"GOOGLE_FUNCTION_CLOUDEVENT_COMPAT=false",
+			},
+			want: 100,
+		},
+		{
+			name: "optOutNoTarget",
+			env: []string{
+				"GOOGLE_FUNCTION_SIGNATURE_TYPE=event",
+				"GOOGLE_FUNCTION_CLOUDEVENT_COMPAT=true",
+			},
+			want: 100,
+		},
+		{
+			name: "optOutCloudEventSignature",
+			env: []string{
+				"GOOGLE_FUNCTION_TARGET=helloWorld",
+				"GOOGLE_FUNCTION_SIGNATURE_TYPE=cloudevent",
+				"GOOGLE_FUNCTION_CLOUDEVENT_COMPAT=true",
+			},
+			want: 100,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buildpacktest.TestDetect(t, detectFn, tc.name, tc.files, tc.env, tc.want)
+		})
+	}
+}
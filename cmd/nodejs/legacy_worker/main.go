@@ -33,7 +33,7 @@ const (
 )
 
 func main() {
-	gcp.Main(detectFn, buildFn)
+	gcp.Main(detectFn, buildFn, gcp.WithFunctionSourceDir())
 }
 
 func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
@@ -54,7 +54,8 @@ func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
 func buildFn(ctx *gcp.Context) error {
 
 	if _, ok := os.LookupEnv(env.FunctionSource); ok {
-		return gcp.UserErrorf("%s is not currently supported for Node.js buildpacks", env.FunctionSource)
+		// A directory is the application root, only files are unsupported.
+		return gcp.UserErrorf("%s must be a directory of the application for Node.js buildpacks", env.FunctionSource)
 	}
 
 	// Function source code should be defined in the "main" field in package.json, index.js or function.js.
@@ -21,6 +21,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/apphosting"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/ar"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/buildermetrics"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/cache"
@@ -34,7 +35,7 @@ const (
 )
 
 func main() {
-	gcp.Main(detectFn, buildFn)
+	gcp.Main(detectFn, buildFn, gcp.WithFunctionSourceDir())
 }
 
 func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
@@ -65,6 +66,22 @@ func buildFn(ctx *gcp.Context) error {
 	if err != nil {
 		return err
 	}
+	ws, err := nodejs.SelectedWorkspace(ctx, pjs)
+	if err != nil {
+		return err
+	}
+	fw, err := apphosting.DetectFramework(ctx, pjs, ws)
+	if err != nil {
+		return err
+	}
+	// Set the build environment of apphosting.yaml before the install, so that install scripts see it.
+	ahc, err := apphosting.ReadConfig(ctx, ws)
+	if err != nil {
+		return err
+	}
+	if err := ahc.SetBuildEnv(ctx); err != nil {
+		return err
+	}
 	if err := upgradeNPM(ctx, pjs); err != nil {
 		return err
 	}
@@ -76,10 +93,18 @@ func buildFn(ctx *gcp.Context) error {
 
 	nodeEnv := nodejs.NodeEnv()
 	gcpBuild := nodejs.HasGCPBuild(pjs)
-	if gcpBuild {
+	// The dependencies of all the workspaces of a monorepo are installed at the application root,
+	// then the selected workspace is built. Frameworks are built with their devDependencies, which
+	// are pruned afterwards.
+	wsBuild := ws.BuildScript()
+	if gcpBuild || wsBuild != "" || fw != nil {
 		nodeEnv = nodejs.EnvDevelopment
 	}
-	cached, err := nodejs.CheckOrClearCache(ctx, ml, cache.WithStrings(nodeEnv), cache.WithFiles("package.json", lockfile))
+	cacheFiles := []string{"package.json", lockfile}
+	if ws != nil {
+		cacheFiles = append(cacheFiles, filepath.Join(ws.Dir, "package.json"))
+	}
+	cached, err := nodejs.CheckOrClearCache(ctx, ml, cache.WithStrings(nodeEnv), cache.WithFiles(cacheFiles...))
 	if err != nil {
 		return fmt.Errorf("checking cache: %w", err)
 	}
@@ -114,13 +139,27 @@ func buildFn(ctx *gcp.Context) error {
 		}
 	}
 
-	if gcpBuild {
+	// The framework build runs the gcp-build script in place of the build script.
+	if gcpBuild && fw == nil {
 		if _, err := ctx.Exec([]string{"npm", "run", "gcp-build"}, gcp.WithUserAttribution); err != nil {
 			return err
 		}
 		buildermetrics.GlobalBuilderMetrics().GetCounter(buildermetrics.NpmGcpBuildUsageCounterID).Increment(1)
-
-		shouldPrune, err := shouldPrune(ctx, pjs)
+	}
+	if wsBuild != "" && fw == nil {
+		ctx.Logf("Building workspace %s.", ws.Dir)
+		if _, err := ctx.Exec(ws.NPMBuildCommand(wsBuild), gcp.WithUserAttribution); err != nil {
+			return err
+		}
+	}
+	var fwCmd []string
+	if fw != nil {
+		if fwCmd, err = fw.Build(ctx, apphosting.NPM); err != nil {
+			return err
+		}
+	}
+	if gcpBuild || wsBuild != "" || fw != nil {
+		shouldPrune, err := shouldPrune(ctx, pjs, ws)
 		if err != nil {
 			return err
 		}
@@ -139,8 +178,42 @@ func buildFn(ctx *gcp.Context) error {
 	el.SharedEnvironment.Prepend("PATH", string(os.PathListSeparator), filepath.Join(ctx.ApplicationRoot(), "node_modules", ".bin"))
 	el.SharedEnvironment.Default("NODE_ENV", nodejs.NodeEnv())
 
+	// Serve the build output of apps that have no server to start, e.g. a static site built with
+	// Vite or a framework, with a file server instead.
+	appDir, appPJS := "", pjs
+	if ws != nil {
+		appDir, appPJS = ws.Dir, ws.PackageJSON
+	}
+	var site string
+	if fw != nil {
+		site, err = fw.StaticSiteDir(ctx)
+	} else {
+		site, err = nodejs.StaticSiteDir(ctx, appDir, appPJS)
+	}
+	if err != nil {
+		return err
+	}
+	if site != "" && !devmode.Enabled(ctx) {
+		sl, err := ctx.Layer("static_server", gcp.LaunchLayer)
+		if err != nil {
+			return fmt.Errorf("creating layer: %w", err)
+		}
+		return nodejs.AddStaticServer(ctx, sl, site)
+	}
+
 	// Configure the entrypoint for production.
 	cmd := []string{"npm", "start"}
+	if ws != nil {
+		cmd = append(cmd, "--workspace="+ws.Dir)
+	}
+	if fwCmd != nil {
+		cmd = fwCmd
+		if ws != nil && !devmode.Enabled(ctx) {
+			// The framework server runs in the directory of the workspace, where it was built.
+			ctx.AddProcess(gcp.WebProcess, cmd, gcp.AsDirectProcess(), gcp.AsDefaultProcess(), gcp.InWorkingDirectory(filepath.Join(ctx.ApplicationRoot(), ws.Dir)))
+			return nil
+		}
+	}
 
 	if !devmode.Enabled(ctx) {
 		ctx.AddWebProcess(cmd)
@@ -159,9 +232,9 @@ func buildFn(ctx *gcp.Context) error {
 	return nil
 }
 
-func shouldPrune(ctx *gcp.Context, pjs *nodejs.PackageJSON) (bool, error) {
+func shouldPrune(ctx *gcp.Context, pjs *nodejs.PackageJSON, ws *nodejs.Workspace) (bool, error) {
 	// if there are no devDependencies, there is no need to prune.
-	if !nodejs.HasDevDependencies(pjs) {
+	if !nodejs.HasDevDependencies(pjs) && (ws == nil || !nodejs.HasDevDependencies(ws.PackageJSON)) {
 		return false, nil
 	}
 	if nodeEnv := nodejs.NodeEnv(); nodeEnv != nodejs.EnvProduction {
@@ -0,0 +1,219 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Implements nodejs/pnpm buildpack.
+// The pnpm buildpack installs dependencies using pnpm.
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/apphosting"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/ar"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/devmode"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/nodejs"
+)
+
+const (
+	pnpmLayer = "pnpm_engine"
+)
+
+func main() {
+	gcp.Main(detectFn, buildFn, gcp.WithFunctionSourceDir())
+}
+
+func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
+	pkgJSONExists, err := ctx.FileExists("package.json")
+	if err != nil {
+		return nil, err
+	}
+	if !pkgJSONExists {
+		return gcp.OptOutFileNotFound("package.json"), nil
+	}
+
+	pnpmLockExists, err := ctx.FileExists(nodejs.PNPMLock)
+	if err != nil {
+		return nil, err
+	}
+	if !pnpmLockExists {
+		return gcp.OptOutFileNotFound(nodejs.PNPMLock), nil
+	}
+
+	return gcp.OptIn("found pnpm-lock.yaml and package.json"), nil
+}
+
+func buildFn(ctx *gcp.Context) error {
+	pjs, err := nodejs.ReadPackageJSONIfExists(ctx.ApplicationRoot())
+	if err != nil {
+		return err
+	}
+	ws, err := nodejs.SelectedWorkspace(ctx, pjs)
+	if err != nil {
+		return err
+	}
+	fw, err := apphosting.DetectFramework(ctx, pjs, ws)
+	if err != nil {
+		return err
+	}
+	// Set the build environment of apphosting.yaml before the install, so that install scripts see it.
+	ahc, err := apphosting.ReadConfig(ctx, ws)
+	if err != nil {
+		return err
+	}
+	if err := ahc.SetBuildEnv(ctx); err != nil {
+		return err
+	}
+	if err := installPNPM(ctx, pjs); err != nil {
+		return fmt.Errorf("installing pnpm: %w", err)
+	}
+	if err := ar.GenerateNPMConfig(ctx); err != nil {
+		return fmt.Errorf("generating Artifact Registry credentials: %w", err)
+	}
+
+	// pnpm links the packages of node_modules from its store, which is cached between builds.
+	sl, err := ctx.Layer("pnpm_store", gcp.BuildLayer, gcp.CacheLayer)
+	if err != nil {
+		return fmt.Errorf("creating layer: %w", err)
+	}
+	storeFlag := fmt.Sprintf("--store-dir=%s", sl.Path)
+
+	nodeEnv := nodejs.NodeEnv()
+	gcpBuild := nodejs.HasGCPBuild(pjs)
+	// The dependencies of all the workspaces of a monorepo are installed at the application root,
+	// then the selected workspace is built. Frameworks are built with their devDependencies, which
+	// are pruned afterwards.
+	wsBuild := ws.BuildScript()
+	if gcpBuild || wsBuild != "" || fw != nil {
+		nodeEnv = nodejs.EnvDevelopment
+	}
+	ctx.Logf("Installing application dependencies.")
+	// pnpm skips the devDependencies if NODE_ENV is production.
+	if _, err := ctx.Exec([]string{"pnpm", "install", "--frozen-lockfile", storeFlag}, gcp.WithEnv("NODE_ENV="+nodeEnv), gcp.WithUserAttribution); err != nil {
+		return err
+	}
+
+	// The framework build runs the gcp-build script in place of the build script.
+	if gcpBuild && fw == nil {
+		if _, err := ctx.Exec([]string{"pnpm", "run", "gcp-build"}, gcp.WithUserAttribution); err != nil {
+			return err
+		}
+	}
+	if wsBuild != "" && fw == nil {
+		ctx.Logf("Building workspace %s.", ws.Dir)
+		if _, err := ctx.Exec(ws.PNPMBuildCommand(wsBuild), gcp.WithUserAttribution); err != nil {
+			return err
+		}
+	}
+	var fwCmd []string
+	if fw != nil {
+		if fwCmd, err = fw.Build(ctx, apphosting.PNPM); err != nil {
+			return err
+		}
+	}
+	if gcpBuild || wsBuild != "" || fw != nil {
+		if err := pruneDevDependencies(ctx, pjs, ws, storeFlag); err != nil {
+			return err
+		}
+	}
+
+	el, err := ctx.Layer("env", gcp.BuildLayer, gcp.LaunchLayer)
+	if err != nil {
+		return fmt.Errorf("creating layer: %w", err)
+	}
+	el.SharedEnvironment.Prepend("PATH", string(os.PathListSeparator), filepath.Join(ctx.ApplicationRoot(), "node_modules", ".bin"))
+	el.SharedEnvironment.Default("NODE_ENV", nodejs.NodeEnv())
+
+	// Serve the build output of apps that have no server to start, e.g. a static site built with
+	// Vite or a framework, with a file server instead.
+	appDir, appPJS := "", pjs
+	if ws != nil {
+		appDir, appPJS = ws.Dir, ws.PackageJSON
+	}
+	var site string
+	if fw != nil {
+		site, err = fw.StaticSiteDir(ctx)
+	} else {
+		site, err = nodejs.StaticSiteDir(ctx, appDir, appPJS)
+	}
+	if err != nil {
+		return err
+	}
+	if site != "" && !devmode.Enabled(ctx) {
+		sl, err := ctx.Layer("static_server", gcp.LaunchLayer)
+		if err != nil {
+			return fmt.Errorf("creating layer: %w", err)
+		}
+		return nodejs.AddStaticServer(ctx, sl, site)
+	}
+
+	// Configure the entrypoint for production.
+	cmd := []string{"pnpm", "start"}
+	if ws != nil {
+		cmd = ws.PNPMRunCommand("start")
+	}
+	if fwCmd != nil {
+		cmd = fwCmd
+		if ws != nil && !devmode.Enabled(ctx) {
+			// The framework server runs in the directory of the workspace, where it was built.
+			ctx.AddProcess(gcp.WebProcess, cmd, gcp.AsDirectProcess(), gcp.AsDefaultProcess(), gcp.InWorkingDirectory(filepath.Join(ctx.ApplicationRoot(), ws.Dir)))
+			return nil
+		}
+	}
+
+	if !devmode.Enabled(ctx) {
+		ctx.AddWebProcess(cmd)
+		return nil
+	}
+
+	// Configure the entrypoint and metadata for dev mode.
+	if err := devmode.AddFileWatcherProcess(ctx, devmode.Config{
+		RunCmd: cmd,
+		Ext:    devmode.NodeWatchedExtensions,
+	}); err != nil {
+		return fmt.Errorf("adding devmode file watcher: %w", err)
+	}
+	devmode.AddSyncMetadata(ctx, devmode.NodeSyncRules)
+
+	return nil
+}
+
+// pruneDevDependencies removes the devDependencies installed for the build by installing the
+// production dependencies again from the store, which also prunes the workspaces of a monorepo.
+func pruneDevDependencies(ctx *gcp.Context, pjs *nodejs.PackageJSON, ws *nodejs.Workspace, storeFlag string) error {
+	// if there are no devDependencies, there is no need to prune.
+	if !nodejs.HasDevDependencies(pjs) && (ws == nil || !nodejs.HasDevDependencies(ws.PackageJSON)) {
+		return nil
+	}
+	if nodeEnv := nodejs.NodeEnv(); nodeEnv != nodejs.EnvProduction {
+		ctx.Logf("Retaining devDependencies because $NODE_ENV=%q.", nodeEnv)
+		return nil
+	}
+	ctx.Logf("Pruning devDependencies")
+	// pnpm asks to confirm that node_modules is recreated without the devDependencies, and fails
+	// without a TTY unless confirmModulesPurge is disabled.
+	cmd := []string{"pnpm", "install", "--prod", "--frozen-lockfile", "--offline", "--ignore-scripts", "--config.confirmModulesPurge=false", storeFlag}
+	_, err := ctx.Exec(cmd, gcp.WithUserAttribution)
+	return err
+}
+
+func installPNPM(ctx *gcp.Context, pjs *nodejs.PackageJSON) error {
+	pl, err := ctx.Layer(pnpmLayer, gcp.BuildLayer, gcp.CacheLayer, gcp.LaunchLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", pnpmLayer, err)
+	}
+	return nodejs.InstallPNPMLayer(ctx, pl, pjs)
+}
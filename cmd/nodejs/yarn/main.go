@@ -21,6 +21,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/apphosting"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/ar"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/cache"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/devmode"
@@ -34,7 +35,7 @@ const (
 )
 
 func main() {
-	gcp.Main(detectFn, buildFn)
+	gcp.Main(detectFn, buildFn, gcp.WithFunctionSourceDir())
 }
 
 func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
@@ -62,18 +63,35 @@ func buildFn(ctx *gcp.Context) error {
 	if err != nil {
 		return err
 	}
+	ws, err := nodejs.SelectedWorkspace(ctx, pjs)
+	if err != nil {
+		return err
+	}
+	fw, err := apphosting.DetectFramework(ctx, pjs, ws)
+	if err != nil {
+		return err
+	}
+	// Set the build environment of apphosting.yaml before the install, so that install scripts see it.
+	ahc, err := apphosting.ReadConfig(ctx, ws)
+	if err != nil {
+		return err
+	}
+	if err := ahc.SetBuildEnv(ctx); err != nil {
+		return err
+	}
 	if err := installYarn(ctx, pjs); err != nil {
 		return fmt.Errorf("installing Yarn: %w", err)
 	}
 
+	var fwCmd []string
 	if yarn2, err := nodejs.IsYarn2(ctx.ApplicationRoot()); err != nil {
 		return err
 	} else if yarn2 {
-		if err := yarn2InstallModules(ctx, pjs); err != nil {
+		if fwCmd, err = yarn2InstallModules(ctx, pjs, ws, fw); err != nil {
 			return err
 		}
 	} else {
-		if err := yarn1InstallModules(ctx, pjs); err != nil {
+		if fwCmd, err = yarn1InstallModules(ctx, pjs, ws, fw); err != nil {
 			return err
 		}
 	}
@@ -85,8 +103,42 @@ func buildFn(ctx *gcp.Context) error {
 	el.SharedEnvironment.Prepend("PATH", string(os.PathListSeparator), filepath.Join(ctx.ApplicationRoot(), "node_modules", ".bin"))
 	el.SharedEnvironment.Default("NODE_ENV", nodejs.NodeEnv())
 
+	// Serve the build output of apps that have no server to start, e.g. a static site built with
+	// Vite or a framework, with a file server instead.
+	appDir, appPJS := "", pjs
+	if ws != nil {
+		appDir, appPJS = ws.Dir, ws.PackageJSON
+	}
+	var site string
+	if fw != nil {
+		site, err = fw.StaticSiteDir(ctx)
+	} else {
+		site, err = nodejs.StaticSiteDir(ctx, appDir, appPJS)
+	}
+	if err != nil {
+		return err
+	}
+	if site != "" && !devmode.Enabled(ctx) {
+		sl, err := ctx.Layer("static_server", gcp.LaunchLayer)
+		if err != nil {
+			return fmt.Errorf("creating layer: %w", err)
+		}
+		return nodejs.AddStaticServer(ctx, sl, site)
+	}
+
 	// Configure the entrypoint for production.
 	cmd := []string{"yarn", "run", "start"}
+	if ws != nil {
+		cmd = ws.YarnRunCommand("start")
+	}
+	if fwCmd != nil {
+		cmd = fwCmd
+		if ws != nil && !devmode.Enabled(ctx) {
+			// The framework server runs in the directory of the workspace, where it was built.
+			ctx.AddProcess(gcp.WebProcess, cmd, gcp.AsDirectProcess(), gcp.AsDefaultProcess(), gcp.InWorkingDirectory(filepath.Join(ctx.ApplicationRoot(), ws.Dir)))
+			return nil
+		}
+	}
 
 	if !devmode.Enabled(ctx) {
 		ctx.AddWebProcess(cmd)
@@ -105,24 +157,28 @@ func buildFn(ctx *gcp.Context) error {
 	return nil
 }
 
-func yarn1InstallModules(ctx *gcp.Context, pjs *nodejs.PackageJSON) error {
+func yarn1InstallModules(ctx *gcp.Context, pjs *nodejs.PackageJSON, ws *nodejs.Workspace, fw *apphosting.Framework) ([]string, error) {
 	freezeLockfile, err := nodejs.UseFrozenLockfile(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	ml, err := ctx.Layer("yarn_modules", gcp.BuildLayer, gcp.CacheLayer, gcp.LaunchLayer)
 	if err != nil {
-		return fmt.Errorf("creating layer: %w", err)
+		return nil, fmt.Errorf("creating layer: %w", err)
 	}
 
 	if err := ar.GenerateNPMConfig(ctx); err != nil {
-		return fmt.Errorf("generating Artifact Registry credentials: %w", err)
+		return nil, fmt.Errorf("generating Artifact Registry credentials: %w", err)
 	}
 
-	_, err = nodejs.CheckOrClearCache(ctx, ml, cache.WithFiles("package.json", nodejs.YarnLock))
+	cacheFiles := []string{"package.json", nodejs.YarnLock}
+	if ws != nil {
+		cacheFiles = append(cacheFiles, filepath.Join(ws.Dir, "package.json"))
+	}
+	_, err = nodejs.CheckOrClearCache(ctx, ml, cache.WithFiles(cacheFiles...))
 	if err != nil {
-		return fmt.Errorf("checking cache: %w", err)
+		return nil, fmt.Errorf("checking cache: %w", err)
 	}
 
 	// Use Yarn's --modules-folder flag to install directly into the layer and then symlink them into
@@ -130,29 +186,29 @@ func yarn1InstallModules(ctx *gcp.Context, pjs *nodejs.PackageJSON) error {
 	layerModules := filepath.Join(ml.Path, "node_modules")
 	appModules := filepath.Join(ctx.ApplicationRoot(), "node_modules")
 	if err := ctx.MkdirAll(layerModules, 0755); err != nil {
-		return err
+		return nil, err
 	}
 	if err := ctx.RemoveAll(appModules); err != nil {
-		return err
+		return nil, err
 	}
 	if err := ctx.Symlink(layerModules, appModules); err != nil {
-		return err
+		return nil, err
 	}
 	locationFlag := fmt.Sprintf("--modules-folder=%s", layerModules)
 
 	runtimeconfigJSONExists, err := ctx.FileExists(".runtimeconfig.json")
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// This is a hack to fix a bug in an old version of Firebase that loaded a config using a path
 	// relative to node_modules: https://github.com/firebase/firebase-functions/issues/630.
 	if runtimeconfigJSONExists {
 		layerConfig := filepath.Join(ml.Path, ".runtimeconfig.json")
 		if err := ctx.RemoveAll(layerConfig); err != nil {
-			return err
+			return nil, err
 		}
 		if err := ctx.Symlink(filepath.Join(ctx.ApplicationRoot(), ".runtimeconfig.json"), layerConfig); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
@@ -164,7 +220,8 @@ func yarn1InstallModules(ctx *gcp.Context, pjs *nodejs.PackageJSON) error {
 		cmd = append(cmd, "--frozen-lockfile")
 	}
 	gcpBuild := nodejs.HasGCPBuild(pjs)
-	if gcpBuild {
+	wsBuild := ws.BuildScript()
+	if gcpBuild || wsBuild != "" || fw != nil {
 		// Setting --production=false causes the devDependencies to be installed regardless of the
 		// NODE_ENV value. The allows the customer's lifecycle hooks to access to them. We purge the
 		// devDependencies from the final app.
@@ -174,14 +231,28 @@ func yarn1InstallModules(ctx *gcp.Context, pjs *nodejs.PackageJSON) error {
 	// Add the layer's node_modules/.bin to the path so it is available in postinstall scripts.
 	nodeBin := filepath.Join(layerModules, ".bin")
 	if _, err := ctx.Exec(cmd, gcp.WithUserAttribution, gcp.WithEnv(fmt.Sprintf("PATH=%s:%s", os.Getenv("PATH"), nodeBin))); err != nil {
-		return err
+		return nil, err
 	}
 
-	if gcpBuild {
+	// The framework build runs the gcp-build script in place of the build script.
+	if gcpBuild && fw == nil {
 		if _, err := ctx.Exec([]string{"yarn", "run", "gcp-build"}, gcp.WithUserAttribution); err != nil {
-			return err
+			return nil, err
 		}
-
+	}
+	if wsBuild != "" && fw == nil {
+		ctx.Logf("Building workspace %s.", ws.Dir)
+		if _, err := ctx.Exec(ws.YarnBuildCommand(wsBuild), gcp.WithUserAttribution); err != nil {
+			return nil, err
+		}
+	}
+	var fwCmd []string
+	if fw != nil {
+		if fwCmd, err = fw.Build(ctx, apphosting.Yarn); err != nil {
+			return nil, err
+		}
+	}
+	if gcpBuild || wsBuild != "" || fw != nil {
 		// If there was a gcp-build script we installed all the devDependencies above. We should try to
 		// prune them from the final app image.
 		nodeEnv := nodejs.NodeEnv()
@@ -195,23 +266,23 @@ func yarn1InstallModules(ctx *gcp.Context, pjs *nodejs.PackageJSON) error {
 				cmd = append(cmd, "--frozen-lockfile")
 			}
 			if _, err := ctx.Exec(cmd, gcp.WithUserAttribution); err != nil {
-				return err
+				return nil, err
 			}
 		}
 	}
 
-	return nil
+	return fwCmd, nil
 }
 
-func yarn2InstallModules(ctx *gcp.Context, pjs *nodejs.PackageJSON) error {
+func yarn2InstallModules(ctx *gcp.Context, pjs *nodejs.PackageJSON, ws *nodejs.Workspace, fw *apphosting.Framework) ([]string, error) {
 	if err := ar.GenerateYarnConfig(ctx); err != nil {
-		return fmt.Errorf("generating Artifact Registry credentials: %w", err)
+		return nil, fmt.Errorf("generating Artifact Registry credentials: %w", err)
 	}
 
 	cmd := []string{"yarn", "install", "--immutable"}
 	yarnCacheExists, err := ctx.FileExists(ctx.ApplicationRoot(), ".yarn", "cache")
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// In Plug'n'Play mode (https://yarnpkg.com/features/pnp) all dependencies must be included in
 	// the Yarn cache. The --immutable-cache option will abort the install with an error if anything
@@ -220,40 +291,54 @@ func yarn2InstallModules(ctx *gcp.Context, pjs *nodejs.PackageJSON) error {
 		cmd = append(cmd, "--immutable-cache")
 	}
 	if _, err := ctx.Exec(cmd, gcp.WithUserAttribution); err != nil {
-		return err
+		return nil, err
 	}
 
-	// Run the gcp-build script if it exists.
-	if nodejs.HasGCPBuild(pjs) {
+	// Run the gcp-build script if it exists, the framework build runs it in place of the build script.
+	if nodejs.HasGCPBuild(pjs) && fw == nil {
 		if _, err := ctx.Exec([]string{"yarn", "run", "gcp-build"}, gcp.WithUserAttribution); err != nil {
-			return err
+			return nil, err
+		}
+	}
+	// The dependencies of all the workspaces of a monorepo are installed at the application root,
+	// then the selected workspace is built.
+	if script := ws.BuildScript(); script != "" && fw == nil {
+		ctx.Logf("Building workspace %s.", ws.Dir)
+		if _, err := ctx.Exec(ws.YarnBuildCommand(script), gcp.WithUserAttribution); err != nil {
+			return nil, err
+		}
+	}
+	var fwCmd []string
+	if fw != nil {
+		if fwCmd, err = fw.Build(ctx, apphosting.Yarn); err != nil {
+			return nil, err
 		}
 	}
 
 	// If there are no devDependencies, there is nothing to prune. We are done.
-	if !nodejs.HasDevDependencies(pjs) {
-		return nil
+	if !nodejs.HasDevDependencies(pjs) && (ws == nil || !nodejs.HasDevDependencies(ws.PackageJSON)) {
+		return fwCmd, nil
 	}
 
 	nodeEnv := nodejs.NodeEnv()
 	if nodeEnv != nodejs.EnvProduction {
 		ctx.Logf("Retaining devDependencies because NODE_ENV=%q", nodeEnv)
-		return nil
+		return fwCmd, nil
 	}
 	hasWorkPlugin, err := nodejs.HasYarnWorkspacePlugin(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if !hasWorkPlugin {
 		ctx.Warnf("Keeping devDependencies because the Yarn workspace-tools plugin is not installed. You can add it to your project by running 'yarn plugin import workspace-tools'")
-		return nil
+		return fwCmd, nil
 	}
 	// For Yarn2, dependency pruning is via the workspaces plugin.
 	ctx.Logf("Pruning devDependencies")
 	if _, err := ctx.Exec([]string{"yarn", "workspaces", "focus", "--all", "--production"}, gcp.WithUserAttribution); err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return fwCmd, nil
 }
 
 func installYarn(ctx *gcp.Context, pjs *nodejs.PackageJSON) error {
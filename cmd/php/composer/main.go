@@ -28,7 +28,7 @@ const (
 )
 
 func main() {
-	gcp.Main(detectFn, buildFn)
+	gcp.Main(detectFn, buildFn, gcp.WithFunctionSourceDir())
 }
 
 func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
@@ -47,6 +47,8 @@ func buildFn(ctx *gcp.Context) error {
 	if err != nil {
 		return fmt.Errorf("composer install: %w", err)
 	}
-
-	return nil
+	if err := optimizeAutoloader(ctx); err != nil {
+		return err
+	}
+	return addOpcachePreload(ctx)
 }
@@ -0,0 +1,134 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/php"
+)
+
+const (
+	preloadLayer  = "opcache-preload"
+	preloadScript = "preload.php"
+	preloadIni    = "preload.ini"
+	// minPreloadVersionID is the PHP_VERSION_ID of PHP 7.4, the first version that supports
+	// preloading.
+	minPreloadVersionID = 70400
+	// preloadScriptContent compiles the files of the classmap of the dependencies into OPcache,
+	// without running them. Classes whose dependencies cannot be linked are skipped by PHP.
+	preloadScriptContent = `<?php
+// Generated by the buildpack, preloads the classes of the dependencies into OPcache.
+$classmap = require %q;
+foreach ($classmap as $file) {
+    if (strpos($file, %q) === 0) {
+        @opcache_compile_file($file);
+    }
+}
+`
+)
+
+// optimizeAutoloader regenerates the autoloader of the dependencies with an authoritative
+// classmap, so that composer does not look up classes on the filesystem at runtime.
+func optimizeAutoloader(ctx *gcp.Context) error {
+	enabled, err := env.IsPresentAndTrue(env.ComposerClassmapAuthoritative)
+	if err != nil {
+		return gcp.UserErrorf("%v", err)
+	}
+	if !enabled {
+		return nil
+	}
+	ctx.Logf("Generating an authoritative classmap autoloader.")
+	_, err = ctx.Exec([]string{"composer", "dump-autoload", "--optimize", "--classmap-authoritative", "--no-dev", "--no-interaction"}, gcp.WithUserAttribution)
+	return err
+}
+
+// addOpcachePreload configures OPcache to preload the classes of the dependencies when PHP starts,
+// with a preload script and an ini file in a launch layer.
+func addOpcachePreload(ctx *gcp.Context) error {
+	enabled, err := env.IsPresentAndTrue(env.PHPOpcachePreload)
+	if err != nil {
+		return gcp.UserErrorf("%v", err)
+	}
+	if !enabled {
+		return nil
+	}
+	result, err := ctx.Exec([]string{"php", "-r", "echo PHP_VERSION_ID;"})
+	if err != nil {
+		return err
+	}
+	if id, err := strconv.Atoi(result.Stdout); err != nil || id < minPreloadVersionID {
+		ctx.Warnf("OPcache preloading requires PHP 7.4 or later, %s is ignored.", env.PHPOpcachePreload)
+		return nil
+	}
+	classmap := filepath.Join(ctx.ApplicationRoot(), php.Vendor, "composer", "autoload_classmap.php")
+	exists, err := ctx.FileExists(classmap)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		ctx.Warnf("The dependencies do not have a classmap, OPcache preloading is disabled.")
+		return nil
+	}
+	result, err = ctx.Exec([]string{"php", "-m"})
+	if err != nil {
+		return err
+	}
+	u, err := user.Current()
+	if err != nil {
+		return fmt.Errorf("getting current user: %w", err)
+	}
+
+	l, err := ctx.Layer(preloadLayer, gcp.LaunchLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", preloadLayer, err)
+	}
+	script := filepath.Join(l.Path, preloadScript)
+	vendorDir := filepath.Join(ctx.ApplicationRoot(), php.Vendor) + string(filepath.Separator)
+	if err := ctx.WriteFile(script, []byte(fmt.Sprintf(preloadScriptContent, classmap, vendorDir)), 0644); err != nil {
+		return err
+	}
+	confDir := filepath.Join(l.Path, "conf.d")
+	if err := ctx.MkdirAll(confDir, 0755); err != nil {
+		return err
+	}
+	ini := preloadIniContent(script, u.Username, strings.Contains(result.Stdout, "Zend OPcache"))
+	if err := ctx.WriteFile(filepath.Join(confDir, preloadIni), []byte(ini), 0644); err != nil {
+		return err
+	}
+	l.LaunchEnvironment.Append(php.IniScanDirEnv, string(os.PathListSeparator), confDir)
+	ctx.Logf("The classes of the dependencies are preloaded into OPcache when PHP starts.")
+	return nil
+}
+
+// preloadIniContent returns the ini directives that enable OPcache and preload the script as the
+// given user. OPcache is loaded unless PHP already loads it.
+func preloadIniContent(script, username string, opcacheLoaded bool) string {
+	var sb strings.Builder
+	if !opcacheLoaded {
+		sb.WriteString("zend_extension=opcache\n")
+	}
+	sb.WriteString("opcache.enable=1\n")
+	fmt.Fprintf(&sb, "opcache.preload=%s\n", script)
+	fmt.Fprintf(&sb, "opcache.preload_user=%s\n", username)
+	return sb.String()
+}
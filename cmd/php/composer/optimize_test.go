@@ -0,0 +1,42 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "testing"
+
+func TestPreloadIniContent(t *testing.T) {
+	testCases := []struct {
+		name          string
+		opcacheLoaded bool
+		want          string
+	}{
+		{
+			name:          "opcache loaded",
+			opcacheLoaded: true,
+			want:          "opcache.enable=1\nopcache.preload=/layers/opcache-preload/preload.php\nopcache.preload_user=www-data\n",
+		},
+		{
+			name: "opcache not loaded",
+			want: "zend_extension=opcache\nopcache.enable=1\nopcache.preload=/layers/opcache-preload/preload.php\nopcache.preload_user=www-data\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := preloadIniContent("/layers/opcache-preload/preload.php", "www-data", tc.opcacheLoaded); got != tc.want {
+				t.Errorf("preloadIniContent() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Implements php/laravel buildpack.
+// The laravel buildpack caches the routes, the views and, on request, the configuration of
+// Laravel applications at build time.
+package main
+
+import (
+	"path/filepath"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/php"
+)
+
+var (
+	// configCache is the configuration cache of Laravel, which contains the values of the
+	// environment it was generated in.
+	configCache = filepath.Join("bootstrap", "cache", "config.php")
+	// storageDirs are the directories Laravel writes compiled views, sessions and cached data to.
+	storageDirs = []string{
+		filepath.Join("storage", "framework", "cache", "data"),
+		filepath.Join("storage", "framework", "sessions"),
+		filepath.Join("storage", "framework", "views"),
+		filepath.Join("storage", "logs"),
+	}
+)
+
+func main() {
+	gcp.Main(detectFn, buildFn)
+}
+
+func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
+	laravel, err := php.IsLaravel(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !laravel {
+		return gcp.OptOut("not a Laravel application"), nil
+	}
+	return gcp.OptIn("found a Laravel application"), nil
+}
+
+func buildFn(ctx *gcp.Context) error {
+	for _, dir := range storageDirs {
+		if err := ctx.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	// A configuration cache generated on a development machine contains its environment, e.g. its
+	// database credentials, and would be used instead of the environment of the application.
+	exists, err := ctx.FileExists(configCache)
+	if err != nil {
+		return err
+	}
+	if exists {
+		ctx.Warnf("Removing %s, the configuration cache generated on a development machine is not used.", configCache)
+		if err := ctx.RemoveAll(configCache); err != nil {
+			return err
+		}
+	}
+
+	cacheConfig, err := env.IsPresentAndTrue(env.LaravelConfigCache)
+	if err != nil {
+		return gcp.UserErrorf("%v", err)
+	}
+	if cacheConfig {
+		// The cached configuration contains the values of the build environment, the values of
+		// the environment of the application are only read by config/*.php without the cache.
+		ctx.Warnf("Caching the configuration, the environment variables of the application at runtime are not read by the configuration.")
+		if _, err := ctx.Exec([]string{"php", php.Artisan, "config:cache"}, gcp.WithUserAttribution); err != nil {
+			return err
+		}
+	} else {
+		ctx.Logf("The configuration is not cached, set %s=true to cache it at build time.", env.LaravelConfigCache)
+	}
+
+	// Routes defined with closures cannot be cached before Laravel 8 and views may depend on
+	// services that are not available at build time, the application works without the caches.
+	if _, err := ctx.Exec([]string{"php", php.Artisan, "route:cache"}, gcp.WithUserAttribution); err != nil {
+		ctx.Warnf("Failed to cache the routes, the routes are loaded at runtime: %v", err)
+		if _, err := ctx.Exec([]string{"php", php.Artisan, "route:clear"}, gcp.WithUserAttribution); err != nil {
+			return err
+		}
+	}
+	if _, err := ctx.Exec([]string{"php", php.Artisan, "view:cache"}, gcp.WithUserAttribution); err != nil {
+		ctx.Warnf("Failed to cache the views, the views are compiled at runtime: %v", err)
+	}
+	return nil
+}
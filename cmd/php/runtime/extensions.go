@@ -0,0 +1,239 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/php"
+	"github.com/buildpacks/libcnb"
+)
+
+const (
+	extensionsLayer = "extensions"
+	extensionsIni   = "extensions.ini"
+	// peclKey is the layer metadata key of the PHP version and the PECL extensions installed in the
+	// layer.
+	peclKey = "pecl_extensions"
+)
+
+var (
+	// peclExtensions are the supported extensions that are not bundled with the PHP runtime, they
+	// are built from PECL when composer.json requires them.
+	peclExtensions = map[string]bool{
+		"apcu":      true,
+		"grpc":      true,
+		"igbinary":  true,
+		"imagick":   true,
+		"memcached": true,
+		"mongodb":   true,
+		"protobuf":  true,
+		"redis":     true,
+	}
+	// zendExtensions are loaded with the zend_extension directive.
+	zendExtensions = map[string]bool{
+		"opcache": true,
+		"xdebug":  true,
+	}
+)
+
+// installExtensions enables the PHP extensions required with ext-* entries in composer.json that
+// are not loaded by default. Extensions bundled with the runtime are enabled, supported PECL
+// extensions are built into a cached layer, and the build fails if any other extension is required.
+func installExtensions(ctx *gcp.Context, phpl *libcnb.Layer) error {
+	exists, err := ctx.FileExists("composer.json")
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+	cjs, err := php.ReadComposerJSON(ctx.ApplicationRoot())
+	if err != nil {
+		return err
+	}
+	required := cjs.RequiredExtensions()
+	if len(required) == 0 {
+		return nil
+	}
+
+	phpEnv := runtimeEnv(phpl)
+	loaded, err := loadedExtensions(ctx, phpEnv)
+	if err != nil {
+		return err
+	}
+	result, err := ctx.Exec([]string{"php", "-r", `echo ini_get("extension_dir");`}, gcp.WithEnv(phpEnv...))
+	if err != nil {
+		return err
+	}
+	extDir := result.Stdout
+	var bundled, pecl, unsupported []string
+	for _, ext := range required {
+		if loaded[ext] {
+			continue
+		}
+		exists, err := ctx.FileExists(extDir, ext+".so")
+		if err != nil {
+			return err
+		}
+		switch {
+		case exists:
+			bundled = append(bundled, ext)
+		case peclExtensions[ext]:
+			pecl = append(pecl, ext)
+		default:
+			unsupported = append(unsupported, ext)
+		}
+	}
+	if len(unsupported) > 0 {
+		return gcp.UserErrorf("composer.json requires PHP extensions that are not supported: %s; the extensions that can be installed in addition to the ones bundled with PHP are: %s", strings.Join(unsupported, ", "), strings.Join(supportedPECLExtensions(), ", "))
+	}
+	if len(bundled) == 0 && len(pecl) == 0 {
+		ctx.Debugf("All the PHP extensions required by composer.json are loaded.")
+		return nil
+	}
+
+	l, err := ctx.Layer(extensionsLayer, gcp.BuildLayer, gcp.CacheLayer, gcp.LaunchLayerUnlessSkipRuntimeLaunch)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", extensionsLayer, err)
+	}
+	paths, err := installPECLExtensions(ctx, l, phpEnv, extDir, pecl)
+	if err != nil {
+		return err
+	}
+	for _, ext := range bundled {
+		paths[ext] = ext
+	}
+	ctx.Logf("Enabling PHP extensions: %s", strings.Join(append(bundled, pecl...), ", "))
+	confDir := filepath.Join(l.Path, "conf.d")
+	if err := ctx.MkdirAll(confDir, 0755); err != nil {
+		return err
+	}
+	if err := ctx.WriteFile(filepath.Join(confDir, extensionsIni), []byte(extensionsIniContent(paths)), 0644); err != nil {
+		return err
+	}
+	l.SharedEnvironment.Append(php.IniScanDirEnv, string(os.PathListSeparator), confDir)
+	return nil
+}
+
+// installPECLExtensions builds the PECL extensions into the layer, unless the layer already
+// contains them for the installed PHP version, and returns the paths of their shared libraries.
+func installPECLExtensions(ctx *gcp.Context, l *libcnb.Layer, phpEnv []string, extDir string, exts []string) (map[string]string, error) {
+	libDir := filepath.Join(l.Path, "lib")
+	paths := map[string]string{}
+	for _, ext := range exts {
+		paths[ext] = filepath.Join(libDir, ext+".so")
+	}
+	result, err := ctx.Exec([]string{"php", "-r", "echo PHP_VERSION;"}, gcp.WithEnv(phpEnv...))
+	if err != nil {
+		return nil, err
+	}
+	key := result.Stdout + " " + strings.Join(exts, ",")
+	if ctx.GetMetadata(l, peclKey) == key {
+		ctx.CacheHit(extensionsLayer)
+		return paths, nil
+	}
+	ctx.CacheMiss(extensionsLayer)
+	if err := ctx.ClearLayer(l); err != nil {
+		return nil, fmt.Errorf("clearing layer %q: %w", l.Name, err)
+	}
+	if len(exts) == 0 {
+		return paths, nil
+	}
+	if err := ctx.MkdirAll(libDir, 0755); err != nil {
+		return nil, err
+	}
+	for _, ext := range exts {
+		ctx.Logf("Installing PHP extension %s from PECL.", ext)
+		// Accept the defaults of the configuration prompts of the extension.
+		if _, err := ctx.Exec([]string{"bash", "-c", fmt.Sprintf("yes '' | pecl install --force %s", ext)}, gcp.WithEnv(phpEnv...), gcp.WithUserAttribution); err != nil {
+			return nil, gcp.UserErrorf("installing PHP extension %s from PECL: %v", ext, err)
+		}
+		// PECL installs into the extension directory of the runtime layer, the library is moved to
+		// this layer so that it is cached with the extensions.
+		if _, err := ctx.Exec([]string{"mv", filepath.Join(extDir, ext+".so"), paths[ext]}, gcp.WithUserTimingAttribution); err != nil {
+			return nil, err
+		}
+	}
+	ctx.SetMetadata(l, peclKey, key)
+	return paths, nil
+}
+
+// runtimeEnv returns the environment that runs the PHP runtime installed in the layer, and the
+// PECL and phpize tools that build extensions against it, during this build.
+func runtimeEnv(phpl *libcnb.Layer) []string {
+	return []string{
+		"PATH=" + filepath.Join(phpl.Path, "bin") + string(os.PathListSeparator) + os.Getenv("PATH"),
+		"PHP_PEAR_PHP_BIN=" + filepath.Join(phpl.Path, "bin", "php"),
+		"PHP_PEAR_INSTALL_DIR=" + filepath.Join(phpl.Path, "lib", "php"),
+	}
+}
+
+// loadedExtensions returns the extensions that PHP loads by default.
+func loadedExtensions(ctx *gcp.Context, phpEnv []string) (map[string]bool, error) {
+	result, err := ctx.Exec([]string{"php", "-m"}, gcp.WithEnv(phpEnv...))
+	if err != nil {
+		return nil, err
+	}
+	return parseModules(result.Stdout), nil
+}
+
+// parseModules returns the extensions in the output of php -m, which lists the PHP and Zend
+// modules under section headers.
+func parseModules(output string) map[string]bool {
+	loaded := map[string]bool{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "[") {
+			continue
+		}
+		loaded[php.NormalizeExtension(line)] = true
+	}
+	return loaded
+}
+
+// extensionsIniContent returns the ini directives that load the extensions, keyed by name, from
+// the given shared library names or paths.
+func extensionsIniContent(paths map[string]string) string {
+	var names []string
+	for ext := range paths {
+		names = append(names, ext)
+	}
+	sort.Strings(names)
+	var sb strings.Builder
+	for _, ext := range names {
+		directive := "extension"
+		if zendExtensions[ext] {
+			directive = "zend_extension"
+		}
+		fmt.Fprintf(&sb, "%s=%s\n", directive, paths[ext])
+	}
+	return sb.String()
+}
+
+// supportedPECLExtensions returns the sorted names of the supported PECL extensions.
+func supportedPECLExtensions() []string {
+	var exts []string
+	for ext := range peclExtensions {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	return exts
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseModules(t *testing.T) {
+	output := `[PHP Modules]
+Core
+ctype
+PDO
+pdo_sqlite
+Zend OPcache
+
+[Zend Modules]
+Zend OPcache
+`
+	want := map[string]bool{
+		"core":       true,
+		"ctype":      true,
+		"pdo":        true,
+		"pdo_sqlite": true,
+		"opcache":    true,
+	}
+	if got := parseModules(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseModules() = %v, want %v", got, want)
+	}
+}
+
+func TestExtensionsIniContent(t *testing.T) {
+	testCases := []struct {
+		name  string
+		paths map[string]string
+		want  string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "bundled and pecl extensions",
+			paths: map[string]string{
+				"redis":   "/layers/extensions/lib/redis.so",
+				"intl":    "intl",
+				"opcache": "opcache",
+			},
+			want: "extension=intl\nzend_extension=opcache\nextension=/layers/extensions/lib/redis.so\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := extensionsIniContent(tc.paths); got != tc.want {
+				t.Errorf("extensionsIniContent(%v) = %q, want %q", tc.paths, got, tc.want)
+			}
+		})
+	}
+}
@@ -33,7 +33,7 @@ const (
 )
 
 func main() {
-	gcp.Main(detectFn, buildFn)
+	gcp.Main(detectFn, buildFn, gcp.WithFunctionSourceDir())
 }
 
 func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
@@ -77,7 +77,10 @@ func buildFn(ctx *gcp.Context) error {
 	setPeclConfig(phpl)
 	setPHPFpmConfig(phpl)
 
-	return addPHPIni(ctx, phpl)
+	if err := addPHPIni(ctx, phpl); err != nil {
+		return err
+	}
+	return installExtensions(ctx, phpl)
 }
 
 func setPeclConfig(phpl *libcnb.Layer) {
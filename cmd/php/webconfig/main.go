@@ -21,11 +21,14 @@ import (
 	"os"
 	"os/user"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/nginx"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/php"
 )
 
 const (
@@ -48,8 +51,16 @@ const (
 	defaultFPMWorkers     = 2
 	phpFpmConf            = "php-fpm.conf"
 	phpFpmPid             = "php-fpm.pid"
+
+	// nginxAppConf and phpFpmAppConf are the config files of the application that are included in
+	// the generated nginx server and php-fpm pool configs.
+	nginxAppConf  = "nginx-app.conf"
+	phpFpmAppConf = "php-fpm.conf"
 )
 
+// nginxSizeRegexp matches an nginx size, e.g. 32m.
+var nginxSizeRegexp = regexp.MustCompile(`^\d+[kKmMgG]?$`)
+
 func main() {
 	gcp.Main(detectFn, buildFn)
 }
@@ -77,13 +88,13 @@ func buildFn(ctx *gcp.Context) error {
 	}
 	defer nginxConfFile.Close()
 
-	procExists, err := ctx.FileExists("Procfile")
+	procs, procfileWeb, err := declaredProcesses(ctx)
 	if err != nil {
 		return err
 	}
 	_, entrypointExists := os.LookupEnv(env.Entrypoint)
 
-	if !procExists && !entrypointExists {
+	if !procfileWeb && !entrypointExists {
 		cmd := []string{
 			"pid1",
 			"--nginxBinaryPath", defaultNginxBinary,
@@ -100,12 +111,16 @@ func buildFn(ctx *gcp.Context) error {
 
 		ctx.AddProcess(gcp.WebProcess, cmd, gcp.AsDefaultProcess())
 	}
+	for _, p := range procs {
+		ctx.Logf("Adding the %s process: %s", p.name, p.command)
+		ctx.AddProcess(p.name, []string{p.command})
+	}
 
 	return nil
 }
 
 func writeFpmConfig(ctx *gcp.Context, path string) (*os.File, error) {
-	conf, err := fpmConfig(path)
+	conf, err := fpmConfig(ctx, path)
 	if err != nil {
 		return nil, err
 	}
@@ -121,43 +136,94 @@ func writeFpmConfig(ctx *gcp.Context, path string) (*os.File, error) {
 	return fpmConfFile, nil
 }
 
-func fpmConfig(l string) (nginx.FPMConfig, error) {
+func fpmConfig(ctx *gcp.Context, l string) (nginx.FPMConfig, error) {
 	user, err := user.Current()
 	if err != nil {
 		return nginx.FPMConfig{}, fmt.Errorf("getting current user: %w", err)
 	}
+	workers := defaultFPMWorkers
+	if v := os.Getenv(env.PHPFPMMaxChildren); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nginx.FPMConfig{}, gcp.UserErrorf("%s must be a positive integer, got %q", env.PHPFPMMaxChildren, v)
+		}
+		workers = n
+	}
+	appConfig, err := appConfigPath(ctx, phpFpmAppConf)
+	if err != nil {
+		return nginx.FPMConfig{}, err
+	}
 
 	fpm := nginx.FPMConfig{
 		PidPath:        filepath.Join(l, phpFpmPid),
-		NumWorkers:     defaultFPMWorkers,
+		NumWorkers:     workers,
 		ListenAddress:  filepath.Join(l, appSocket),
 		DynamicWorkers: defaultDynamicWorkers,
 		Username:       user.Username,
 		Runtime:        strings.ToLower(strings.TrimSpace(os.Getenv(env.Runtime))),
+		AppConfig:      appConfig,
 	}
 
 	return fpm, nil
 }
 
-func nginxConfig(l string) nginx.Config {
+func nginxConfig(ctx *gcp.Context, l string) (nginx.Config, error) {
+	bodySize := os.Getenv(env.NginxClientMaxBodySize)
+	if bodySize != "" && !nginxSizeRegexp.MatchString(bodySize) {
+		return nginx.Config{}, gcp.UserErrorf("%s must be a size such as 32m, got %q", env.NginxClientMaxBodySize, bodySize)
+	}
+	appConfig, err := appConfigPath(ctx, nginxAppConf)
+	if err != nil {
+		return nginx.Config{}, err
+	}
+	root := defaultRoot
+	laravel, err := php.IsLaravel(ctx)
+	if err != nil {
+		return nginx.Config{}, err
+	}
+	if laravel {
+		ctx.Logf("Serving the %s directory of the Laravel application.", php.LaravelPublicDir)
+		root = filepath.Join(defaultRoot, php.LaravelPublicDir)
+	}
 	nginx := nginx.Config{
 		Port:                  defaultNginxPort,
 		FrontControllerScript: defaultFrontController,
-		Root:                  defaultRoot,
+		Root:                  root,
 		AppListenAddress:      filepath.Join(l, appSocket),
+		ClientMaxBodySize:     bodySize,
+		AppConfig:             appConfig,
 	}
 
-	return nginx
+	return nginx, nil
+}
+
+// appConfigPath returns the path of the given config file of the application, or an empty string
+// if the application does not have it.
+func appConfigPath(ctx *gcp.Context, name string) (string, error) {
+	path := filepath.Join(ctx.ApplicationRoot(), name)
+	exists, err := ctx.FileExists(path)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", nil
+	}
+	ctx.Logf("Including %s in the web server configuration.", name)
+	return path, nil
 }
 
 func writeNginxConfig(ctx *gcp.Context, path string) (*os.File, error) {
+	conf, err := nginxConfig(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
 	nginxConfFilePath := filepath.Join(path, nginxServerConf)
 	nginxConfFile, err := os.Create(nginxConfFilePath)
 	if err != nil {
 		return nil, err
 	}
 
-	conf := nginxConfig(path)
 	if err := nginx.NginxTemplate.Execute(nginxConfFile, conf); err != nil {
 		return nil, fmt.Errorf("writing nginx config file: %w", err)
 	}
@@ -17,6 +17,7 @@ package main
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -106,3 +107,150 @@ func TestPhpFpm_DisableDecorateWorkersOutput_ForPhp_Gt_php72(t *testing.T) {
 		})
 	}
 }
+
+func TestFpmConfig(t *testing.T) {
+	testCases := []struct {
+		name          string
+		maxChildren   string
+		appConfig     bool
+		wantWorkers   int
+		wantAppConfig bool
+		wantError     bool
+	}{
+		{
+			name:        "defaults",
+			wantWorkers: defaultFPMWorkers,
+		},
+		{
+			name:        "max children from env",
+			maxChildren: "8",
+			wantWorkers: 8,
+		},
+		{
+			name:        "invalid max children",
+			maxChildren: "many",
+			wantError:   true,
+		},
+		{
+			name:        "zero max children",
+			maxChildren: "0",
+			wantError:   true,
+		},
+		{
+			name:          "app config",
+			appConfig:     true,
+			wantWorkers:   defaultFPMWorkers,
+			wantAppConfig: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(env.PHPFPMMaxChildren, tc.maxChildren)
+			root := t.TempDir()
+			if tc.appConfig {
+				if err := ioutil.WriteFile(filepath.Join(root, phpFpmAppConf), []byte("pm.max_requests = 500\n"), 0644); err != nil {
+					t.Fatalf("writing %s: %v", phpFpmAppConf, err)
+				}
+			}
+			ctx := gcpbuildpack.NewContext(gcpbuildpack.WithApplicationRoot(root))
+
+			got, err := fpmConfig(ctx, t.TempDir())
+			if gotErr := err != nil; gotErr != tc.wantError {
+				t.Fatalf("fpmConfig() got error: %v, want error: %t", err, tc.wantError)
+			}
+			if tc.wantError {
+				return
+			}
+			if got.NumWorkers != tc.wantWorkers {
+				t.Errorf("fpmConfig().NumWorkers = %d, want %d", got.NumWorkers, tc.wantWorkers)
+			}
+			if gotAppConfig := got.AppConfig != ""; gotAppConfig != tc.wantAppConfig {
+				t.Errorf("fpmConfig().AppConfig = %q, want app config: %t", got.AppConfig, tc.wantAppConfig)
+			}
+		})
+	}
+}
+
+func TestWriteNginxConfig(t *testing.T) {
+	testCases := []struct {
+		name        string
+		bodySize    string
+		appConfig   bool
+		laravel     bool
+		wantContain []string
+		wantMissing []string
+		wantError   bool
+	}{
+		{
+			name:        "defaults",
+			wantContain: []string{"root\t/workspace;"},
+			wantMissing: []string{"client_max_body_size", "include"},
+		},
+		{
+			name:        "laravel",
+			laravel:     true,
+			wantContain: []string{"root\t/workspace/public;"},
+		},
+		{
+			name:        "client max body size",
+			bodySize:    "32m",
+			wantContain: []string{"client_max_body_size\t32m;"},
+		},
+		{
+			name:      "invalid client max body size",
+			bodySize:  "32 megabytes",
+			wantError: true,
+		},
+		{
+			name:        "app config",
+			appConfig:   true,
+			wantContain: []string{"include\t", nginxAppConf},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(env.NginxClientMaxBodySize, tc.bodySize)
+			root := t.TempDir()
+			if tc.appConfig {
+				if err := ioutil.WriteFile(filepath.Join(root, nginxAppConf), []byte("add_header X-Frame-Options DENY;\n"), 0644); err != nil {
+					t.Fatalf("writing %s: %v", nginxAppConf, err)
+				}
+			}
+			if tc.laravel {
+				files := map[string]string{
+					"artisan":       "",
+					"composer.json": `{"require": {"laravel/framework": "^10.10"}}`,
+				}
+				for name, content := range files {
+					if err := ioutil.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+						t.Fatalf("writing %s: %v", name, err)
+					}
+				}
+			}
+			ctx := gcpbuildpack.NewContext(gcpbuildpack.WithApplicationRoot(root))
+
+			f, err := writeNginxConfig(ctx, t.TempDir())
+			if gotErr := err != nil; gotErr != tc.wantError {
+				t.Fatalf("writeNginxConfig() got error: %v, want error: %t", err, tc.wantError)
+			}
+			if tc.wantError {
+				return
+			}
+			f.Close()
+			content, err := ioutil.ReadFile(f.Name())
+			if err != nil {
+				t.Fatalf("reading %s: %v", f.Name(), err)
+			}
+			for _, want := range tc.wantContain {
+				if !strings.Contains(string(content), want) {
+					t.Errorf("nginx config does not contain %q:\n%s", want, content)
+				}
+			}
+			for _, missing := range tc.wantMissing {
+				if strings.Contains(string(content), missing) {
+					t.Errorf("nginx config contains %q:\n%s", missing, content)
+				}
+			}
+		})
+	}
+}
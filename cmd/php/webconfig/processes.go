@@ -0,0 +1,107 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+// processRe matches a `name: command` process declaration, as in a Procfile.
+var processRe = regexp.MustCompile(`(?m)^\s*(\w+):\s*(.+)$`)
+
+// process is a process of the application, e.g. `worker: php artisan queue:work`.
+type process struct {
+	name    string
+	command string
+}
+
+// declaredProcesses returns the non-web processes declared in the Procfile and in
+// GOOGLE_PHP_PROCESSES, which run with the same image as the web process, and whether the
+// Procfile declares the web process. Processes of GOOGLE_PHP_PROCESSES replace the Procfile
+// processes of the same name.
+func declaredProcesses(ctx *gcp.Context) ([]process, bool, error) {
+	var procs []process
+	web := false
+	path := filepath.Join(ctx.ApplicationRoot(), "Procfile")
+	exists, err := ctx.FileExists(path)
+	if err != nil {
+		return nil, false, err
+	}
+	if exists {
+		content, err := ctx.ReadFile(path)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, p := range parseProcesses(string(content)) {
+			if p.name == gcp.WebProcess {
+				web = true
+				continue
+			}
+			procs = append(procs, p)
+		}
+	}
+
+	var entries []string
+	for _, e := range strings.Split(os.Getenv(env.PHPProcesses), ";") {
+		if e = strings.TrimSpace(e); e != "" {
+			entries = append(entries, e)
+		}
+	}
+	for _, e := range entries {
+		parsed := parseProcesses(e)
+		if len(parsed) != 1 {
+			return nil, false, gcp.UserErrorf("invalid process %q in %s, want `name: command`", e, env.PHPProcesses)
+		}
+		p := parsed[0]
+		if p.name == gcp.WebProcess {
+			return nil, false, gcp.UserErrorf("%s must not declare the %s process, set %s instead", env.PHPProcesses, gcp.WebProcess, env.Entrypoint)
+		}
+		procs = replaceProcess(procs, p)
+	}
+	return procs, web, nil
+}
+
+// parseProcesses returns the processes declared in the given Procfile contents. Only the first
+// declaration of a process is used.
+func parseProcesses(content string) []process {
+	var procs []process
+	found := map[string]bool{}
+	for _, match := range processRe.FindAllStringSubmatch(content, -1) {
+		name, command := match[1], strings.TrimSpace(match[2])
+		if found[name] {
+			continue
+		}
+		found[name] = true
+		procs = append(procs, process{name: name, command: command})
+	}
+	return procs
+}
+
+// replaceProcess returns the processes with p added, replacing the process of the same name.
+func replaceProcess(procs []process, p process) []process {
+	for i := range procs {
+		if procs[i].name == p.name {
+			procs[i] = p
+			return procs
+		}
+	}
+	return append(procs, p)
+}
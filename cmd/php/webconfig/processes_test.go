@@ -0,0 +1,111 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDeclaredProcesses(t *testing.T) {
+	testCases := []struct {
+		name      string
+		procfile  string
+		processes string
+		want      []process
+		wantWeb   bool
+		wantErr   bool
+	}{
+		{
+			name: "no processes",
+		},
+		{
+			name:     "Procfile with web and worker",
+			procfile: "web: php -S 0.0.0.0:8080\nworker: php artisan queue:work\n",
+			want:     []process{{name: "worker", command: "php artisan queue:work"}},
+			wantWeb:  true,
+		},
+		{
+			name:     "Procfile without web",
+			procfile: "worker: php artisan queue:work --tries=3\nscheduler: php artisan schedule:work\n",
+			want: []process{
+				{name: "worker", command: "php artisan queue:work --tries=3"},
+				{name: "scheduler", command: "php artisan schedule:work"},
+			},
+		},
+		{
+			name:     "duplicate Procfile process",
+			procfile: "worker: php artisan queue:work\nworker: php other.php\n",
+			want:     []process{{name: "worker", command: "php artisan queue:work"}},
+		},
+		{
+			name:      "environment",
+			processes: "worker: php artisan queue:work; scheduler: php artisan schedule:work",
+			want: []process{
+				{name: "worker", command: "php artisan queue:work"},
+				{name: "scheduler", command: "php artisan schedule:work"},
+			},
+		},
+		{
+			name:      "environment replaces Procfile process",
+			procfile:  "worker: php artisan queue:work\nscheduler: php artisan schedule:work\n",
+			processes: "worker: php artisan queue:work --queue=high;",
+			want: []process{
+				{name: "worker", command: "php artisan queue:work --queue=high"},
+				{name: "scheduler", command: "php artisan schedule:work"},
+			},
+		},
+		{
+			name:      "environment without process name",
+			processes: "php artisan queue:work",
+			wantErr:   true,
+		},
+		{
+			name:      "environment with web process",
+			processes: "web: php -S 0.0.0.0:8080",
+			wantErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			if tc.procfile != "" {
+				if err := ioutil.WriteFile(filepath.Join(root, "Procfile"), []byte(tc.procfile), 0644); err != nil {
+					t.Fatalf("writing Procfile: %v", err)
+				}
+			}
+			t.Setenv(env.PHPProcesses, tc.processes)
+
+			got, gotWeb, err := declaredProcesses(gcp.NewContext(gcp.WithApplicationRoot(root)))
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("declaredProcesses() got error: %v, want error: %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(process{})); diff != "" {
+				t.Errorf("declaredProcesses() processes mismatch (-want +got):\n%s", diff)
+			}
+			if gotWeb != tc.wantWeb {
+				t.Errorf("declaredProcesses() web = %v, want %v", gotWeb, tc.wantWeb)
+			}
+		})
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/appstart"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/python"
 	"github.com/Masterminds/semver"
 )
 
@@ -97,12 +98,37 @@ func entrypoint(ctx *gcp.Context) (*appstart.Entrypoint, error) {
 		ctx.Warnf("Installed gunicorn version %q is less than supported version %q.", version, minVersion)
 	}
 
+	asgi, err := python.IsASGIApp(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("detecting ASGI application: %w", err)
+	}
+	if asgi {
+		return asgiEntrypoint(ctx)
+	}
+
 	return &appstart.Entrypoint{
 		Type:    appstart.EntrypointDefault.String(),
 		Command: appengine.DefaultCommand,
 	}, nil
 }
 
+// asgiEntrypoint returns an entrypoint that serves the application with uvicorn workers, because
+// the default WSGI entrypoint fails at run time for ASGI applications.
+func asgiEntrypoint(ctx *gcp.Context) (*appstart.Entrypoint, error) {
+	result, err := ctx.Exec([]string{"python3", "-m", "pip", "show", "uvicorn"}, gcp.WithUserTimingAttribution)
+	if err != nil {
+		if result != nil && result.ExitCode == 1 {
+			return nil, gcp.UserErrorf("ASGI application detected but uvicorn is not installed, add uvicorn to requirements.txt or set %s", env.Entrypoint)
+		}
+		return nil, fmt.Errorf("pip show uvicorn: %v", err)
+	}
+	ctx.Logf("ASGI application detected, using entrypoint: %s", python.ASGIEntrypoint)
+	return &appstart.Entrypoint{
+		Type:    appstart.EntrypointGenerated.String(),
+		Command: python.ASGIEntrypoint,
+	}, nil
+}
+
 func appEngineInDeps(ctx *gcp.Context) (bool, error) {
 	// Check if appengine-python-standard is installed
 	result, err := ctx.Exec([]string{"python3", "-m", "pip", "show", "appengine-python-standard"}, gcp.WithUserTimingAttribution)
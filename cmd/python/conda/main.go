@@ -0,0 +1,210 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Implements python/conda buildpack.
+// The conda buildpack creates a conda environment from environment.yml using micromamba.
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/cache"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/fetch"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/buildpacks/libcnb"
+)
+
+const (
+	micromambaLayer = "micromamba"
+	pkgsLayer       = "conda-pkgs"
+	envLayer        = "conda-env"
+	micromambaVer   = "1.4.1-0"
+	versionKey      = "version"
+	dependencyKey   = "dependency_hash"
+	// micromambaURL is the download URL of the micromamba binary for a version and platform.
+	micromambaURL = "https://github.com/mamba-org/micromamba-releases/releases/download/%s/micromamba-%s"
+)
+
+var (
+	environmentFiles = []string{"environment.yml", "environment.yaml"}
+	// condaPlatforms maps Go architectures to conda platform names.
+	condaPlatforms = map[string]string{
+		"amd64": "linux-64",
+		"arm64": "linux-aarch64",
+	}
+	// micromambaSHA256 pins the SHA256 checksums of the micromamba binaries of micromambaVer by conda
+	// platform, as published in the .sha256 files of the release. Update it with micromambaVer.
+	// Platforms without a pinned checksum fail the build rather than run an unverified binary.
+	micromambaSHA256 = map[string]string{
+		// TODO: pin the checksums of the linux-64 and linux-aarch64 binaries of micromambaVer.
+	}
+)
+
+func main() {
+	gcp.Main(detectFn, buildFn)
+}
+
+func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
+	envFile, err := environmentFile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if envFile == "" {
+		return gcp.OptOutFileNotFound(environmentFiles[0]), nil
+	}
+	return gcp.OptInFileFound(envFile), nil
+}
+
+func buildFn(ctx *gcp.Context) error {
+	envFile, err := environmentFile(ctx)
+	if err != nil {
+		return err
+	}
+	if envFile == "" {
+		return gcp.UserErrorf("%s not found", environmentFiles[0])
+	}
+
+	micromamba, err := installMicromamba(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The package cache lets micromamba skip downloads when the environment needs to be recreated.
+	pl, err := ctx.Layer(pkgsLayer, gcp.BuildLayer, gcp.CacheLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", pkgsLayer, err)
+	}
+
+	el, err := ctx.Layer(envLayer, gcp.BuildLayer, gcp.CacheLayer, gcp.LaunchLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", envLayer, err)
+	}
+	el.SharedEnvironment.Default("CONDA_PREFIX", el.Path)
+
+	hash, err := cache.Hash(ctx, cache.WithFiles(filepath.Join(ctx.ApplicationRoot(), envFile)), cache.WithStrings(micromambaVer, runtime.GOARCH))
+	if err != nil {
+		return fmt.Errorf("computing dependency hash: %w", err)
+	}
+	if hash == ctx.GetMetadata(el, dependencyKey) {
+		ctx.CacheHit(envLayer)
+		ctx.Logf("Conda environment cache hit, skipping environment creation.")
+		return nil
+	}
+	ctx.CacheMiss(envLayer)
+	if err := ctx.ClearLayer(el); err != nil {
+		return fmt.Errorf("clearing layer %q: %w", el.Name, err)
+	}
+
+	ctx.Logf("Creating conda environment from %s.", envFile)
+	if _, err := ctx.Exec([]string{micromamba, "create", "--yes", "--prefix", el.Path, "--file", envFile},
+		gcp.WithEnv("MAMBA_ROOT_PREFIX="+filepath.Join(pl.Path, "root"), "CONDA_PKGS_DIRS="+filepath.Join(pl.Path, "pkgs")),
+		gcp.WithUserAttribution); err != nil {
+		return err
+	}
+	// Remove installation artifacts that are not needed at run time.
+	if err := ctx.RemoveAll(filepath.Join(el.Path, "conda-meta", "history")); err != nil {
+		return err
+	}
+
+	ctx.SetMetadata(el, dependencyKey, hash)
+	return nil
+}
+
+// environmentFile returns the name of the conda environment file in the application root, or an
+// empty string if there is none.
+func environmentFile(ctx *gcp.Context) (string, error) {
+	for _, f := range environmentFiles {
+		exists, err := ctx.FileExists(ctx.ApplicationRoot(), f)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return f, nil
+		}
+	}
+	return "", nil
+}
+
+// installMicromamba downloads the micromamba binary into a cached build layer and returns its path.
+func installMicromamba(ctx *gcp.Context) (string, error) {
+	l, err := ctx.Layer(micromambaLayer, gcp.BuildLayer, gcp.CacheLayer)
+	if err != nil {
+		return "", fmt.Errorf("creating %v layer: %w", micromambaLayer, err)
+	}
+	bin := filepath.Join(l.Path, "bin", "micromamba")
+
+	ctx.AddBOMEntry(libcnb.BOMEntry{
+		Name:     micromambaLayer,
+		Metadata: map[string]interface{}{"version": micromambaVer},
+		Build:    true,
+	})
+
+	if ctx.GetMetadata(l, versionKey) == micromambaVer {
+		ctx.CacheHit(micromambaLayer)
+		return bin, nil
+	}
+	ctx.CacheMiss(micromambaLayer)
+	if err := ctx.ClearLayer(l); err != nil {
+		return "", fmt.Errorf("clearing layer %q: %w", l.Name, err)
+	}
+
+	platform, ok := condaPlatforms[runtime.GOARCH]
+	if !ok {
+		return "", gcp.UserErrorf("conda environments are not supported on %s", runtime.GOARCH)
+	}
+	want, ok := micromambaSHA256[platform]
+	if !ok {
+		return "", gcp.InternalErrorf("micromamba %s has no pinned SHA256 checksum for %s", micromambaVer, platform)
+	}
+	url := fmt.Sprintf(micromambaURL, micromambaVer, platform)
+	ctx.Logf("Installing micromamba v%s.", micromambaVer)
+	if err := ctx.MkdirAll(filepath.Dir(bin), 0755); err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(bin, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return "", gcp.InternalErrorf("creating %s: %v", bin, err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if err := fetch.GetURL(url, io.MultiWriter(f, h)); err != nil {
+		return "", fmt.Errorf("downloading micromamba from %s: %w", url, err)
+	}
+	if err := verifyMicromamba(url, hex.EncodeToString(h.Sum(nil)), want); err != nil {
+		// Do not leave an unverified binary behind in the layer.
+		if rerr := ctx.RemoveAll(bin); rerr != nil {
+			ctx.Warnf("Removing %s: %v", bin, rerr)
+		}
+		return "", err
+	}
+
+	ctx.SetMetadata(l, versionKey, micromambaVer)
+	return bin, nil
+}
+
+// verifyMicromamba compares the SHA256 checksum of the downloaded micromamba binary to the pinned
+// one.
+func verifyMicromamba(binURL, checksum, want string) error {
+	if !strings.EqualFold(checksum, want) {
+		return gcp.InternalErrorf("verifying micromamba binary %s: got SHA256 %s, want %s", binURL, checksum, want)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	buildpacktest "github.com/GoogleCloudPlatform/buildpacks/internal/buildpacktest"
+)
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		want  int
+	}{
+		{
+			name: "environment.yml",
+			files: map[string]string{
+				"main.py":         "",
+				"environment.yml": "dependencies:\n  - numpy",
+			},
+			want: 0,
+		},
+		{
+			name: "environment.yaml",
+			files: map[string]string{
+				"main.py":          "",
+				"environment.yaml": "dependencies:\n  - numpy",
+			},
+			want: 0,
+		},
+		{
+			name: "no environment file",
+			files: map[string]string{
+				"main.py":          "",
+				"requirements.txt": "numpy",
+			},
+			want: 100,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buildpacktest.TestDetect(t, detectFn, tc.name, tc.files, []string{}, tc.want)
+		})
+	}
+}
+
+func TestVerifyMicromamba(t *testing.T) {
+	const checksum = "4c2b9a3bd2d9e4bf7bd5d5e4bbfba4c2e2d0e0e0e5e9e1b1e5f1a6d4c3b2a1f0"
+	testCases := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "match",
+			want: checksum,
+		},
+		{
+			name: "upper case",
+			want: "4C2B9A3BD2D9E4BF7BD5D5E4BBFBA4C2E2D0E0E0E5E9E1B1E5F1A6D4C3B2A1F0",
+		},
+		{
+			name:    "mismatch",
+			want:    "0000000000000000000000000000000000000000000000000000000000000000",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := verifyMicromamba("https://example.com/micromamba-linux-64", checksum, tc.want)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("verifyMicromamba() got error: %v, want error: %t", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Implements python/django buildpack.
+// The django buildpack runs Django management commands at build time so that they do not need to
+// run when the container starts.
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/python"
+)
+
+const (
+	// collectStaticEnv enables running `manage.py collectstatic` at build time.
+	collectStaticEnv = "GOOGLE_PYTHON_DJANGO_COLLECTSTATIC"
+	// checkDeployEnv enables running `manage.py check --deploy` at build time.
+	checkDeployEnv = "GOOGLE_PYTHON_DJANGO_CHECK_DEPLOY"
+	// checkFailLevelEnv sets the message level that fails `manage.py check --deploy`.
+	checkFailLevelEnv = "GOOGLE_PYTHON_DJANGO_CHECK_FAIL_LEVEL"
+	// defaultCheckFailLevel matches the default of `manage.py check`, so that deployment warnings,
+	// which many production settings intentionally accept, do not fail the build.
+	defaultCheckFailLevel = "ERROR"
+	// staticRootEnv is set to the static layer directory so that settings.py can use it as
+	// STATIC_ROOT, e.g. `STATIC_ROOT = os.environ.get("DJANGO_STATIC_ROOT")`.
+	staticRootEnv = "DJANGO_STATIC_ROOT"
+	staticLayer   = "static"
+	manageFile    = "manage.py"
+)
+
+// checkFailLevels are the message levels accepted by `manage.py check --fail-level`.
+var checkFailLevels = []string{"CRITICAL", "ERROR", "WARNING", "INFO", "DEBUG"}
+
+func main() {
+	gcp.Main(detectFn, buildFn)
+}
+
+func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
+	manageExists, err := ctx.FileExists(manageFile)
+	if err != nil {
+		return nil, err
+	}
+	if !manageExists {
+		return gcp.OptOutFileNotFound(manageFile), nil
+	}
+	hasDjango, err := python.RequirementsContain(ctx, "django")
+	if err != nil {
+		return nil, err
+	}
+	if !hasDjango {
+		return gcp.OptOut("django not found in requirements"), nil
+	}
+	collect, check, err := enabledSteps()
+	if err != nil {
+		return nil, err
+	}
+	if !collect && !check {
+		return gcp.OptOut(fmt.Sprintf("neither %s nor %s is enabled", collectStaticEnv, checkDeployEnv)), nil
+	}
+	return gcp.OptIn("found Django project with build steps enabled"), nil
+}
+
+func buildFn(ctx *gcp.Context) error {
+	collect, check, err := enabledSteps()
+	if err != nil {
+		return err
+	}
+	if collect {
+		if err := collectStatic(ctx); err != nil {
+			return err
+		}
+	}
+	if check {
+		level, err := checkFailLevel()
+		if err != nil {
+			return err
+		}
+		if err := manage(ctx, []string{"check", "--deploy", "--fail-level", level}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enabledSteps reports which of the Django build steps are enabled through the environment.
+func enabledSteps() (collect, check bool, err error) {
+	collect, err = env.IsPresentAndTrue(collectStaticEnv)
+	if err != nil {
+		return false, false, err
+	}
+	check, err = env.IsPresentAndTrue(checkDeployEnv)
+	if err != nil {
+		return false, false, err
+	}
+	return collect, check, nil
+}
+
+// checkFailLevel returns the message level that fails `manage.py check --deploy`.
+func checkFailLevel() (string, error) {
+	level := strings.ToUpper(strings.TrimSpace(os.Getenv(checkFailLevelEnv)))
+	if level == "" {
+		return defaultCheckFailLevel, nil
+	}
+	for _, l := range checkFailLevels {
+		if level == l {
+			return level, nil
+		}
+	}
+	return "", gcp.UserErrorf("invalid %s %q, must be one of %s", checkFailLevelEnv, os.Getenv(checkFailLevelEnv), strings.Join(checkFailLevels, ", "))
+}
+
+// collectStatic collects the static files of the application into a launch layer. The layer path
+// is exposed to settings.py through DJANGO_STATIC_ROOT during the build and at launch.
+func collectStatic(ctx *gcp.Context) error {
+	l, err := ctx.Layer(staticLayer, gcp.LaunchLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", staticLayer, err)
+	}
+	l.LaunchEnvironment.Default(staticRootEnv, l.Path)
+	ctx.Logf("Collecting Django static files into %s", l.Path)
+	return manage(ctx, []string{"collectstatic", "--noinput"}, gcp.WithEnv(staticRootEnv+"="+l.Path))
+}
+
+func manage(ctx *gcp.Context, args []string, opts ...gcp.ExecOption) error {
+	cmd := append([]string{"python3", manageFile}, args...)
+	opts = append(opts, gcp.WithUserAttribution)
+	if _, err := ctx.Exec(cmd, opts...); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	buildpacktest "github.com/GoogleCloudPlatform/buildpacks/internal/buildpacktest"
+)
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		env   []string
+		want  int
+	}{
+		{
+			name: "collectstatic enabled",
+			files: map[string]string{
+				"manage.py":        "",
+				"requirements.txt": "Django==4.1.7\ngunicorn",
+			},
+			env:  []string{"GOOGLE_PYTHON_DJANGO_COLLECTSTATIC=true"},
+			want: 0,
+		},
+		{
+			name: "check deploy enabled",
+			files: map[string]string{
+				"manage.py":        "",
+				"requirements.txt": "django",
+			},
+			env:  []string{"GOOGLE_PYTHON_DJANGO_CHECK_DEPLOY=true"},
+			want: 0,
+		},
+		{
+			name: "no steps enabled",
+			files: map[string]string{
+				"manage.py":        "",
+				"requirements.txt": "django",
+			},
+			want: 100,
+		},
+		{
+			name: "no manage.py",
+			files: map[string]string{
+				"requirements.txt": "django",
+			},
+			env:  []string{"GOOGLE_PYTHON_DJANGO_COLLECTSTATIC=true"},
+			want: 100,
+		},
+		{
+			name: "no django requirement",
+			files: map[string]string{
+				"manage.py":        "",
+				"requirements.txt": "django-environ\nflask",
+			},
+			env:  []string{"GOOGLE_PYTHON_DJANGO_COLLECTSTATIC=true"},
+			want: 100,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buildpacktest.TestDetect(t, detectFn, tc.name, tc.files, tc.env, tc.want)
+		})
+	}
+}
+
+func TestCheckFailLevel(t *testing.T) {
+	testCases := []struct {
+		name    string
+		level   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "default",
+			want: "ERROR",
+		},
+		{
+			name:  "warning",
+			level: "WARNING",
+			want:  "WARNING",
+		},
+		{
+			name:  "lower case",
+			level: " critical ",
+			want:  "CRITICAL",
+		},
+		{
+			name:    "invalid",
+			level:   "FATAL",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(checkFailLevelEnv, tc.level)
+
+			got, err := checkFailLevel()
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("checkFailLevel() got error: %v, want error: %t", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("checkFailLevel() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -22,33 +22,51 @@ import (
 	"path/filepath"
 	"regexp"
 
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/cloudfunctions"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/fetch"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/python"
 )
 
 const (
 	layerName = "functions-framework"
+	// pypiReleaseURL is the PyPI JSON API URL of a functions-framework release.
+	pypiReleaseURL = "https://pypi.org/pypi/functions-framework/%s/json"
 )
 
+// pypiRelease represents the fields of the PyPI JSON API response used by the buildpack.
+type pypiRelease struct {
+	Info struct {
+		RequiresPython string `json:"requires_python"`
+	} `json:"info"`
+}
+
 var (
 	ffRegexp  = regexp.MustCompile(`(?m)^functions-framework\b([^-]|$)`)
 	eggRegexp = regexp.MustCompile(`(?m)#egg=functions-framework$`)
 )
 
 func main() {
-	gcp.Main(detectFn, buildFn)
+	gcp.Main(detectFn, buildFn, gcp.WithFunctionSourceDir())
 }
 
 func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
 	if _, ok := os.LookupEnv(env.FunctionTarget); ok {
 		return gcp.OptInEnvSet(env.FunctionTarget, gcp.WithBuildPlans(python.RequirementsProvidesPlan)), nil
 	}
+	if _, ok := os.LookupEnv(env.FunctionTargets); ok {
+		return gcp.OptInEnvSet(env.FunctionTargets, gcp.WithBuildPlans(python.RequirementsProvidesPlan)), nil
+	}
 	return gcp.OptOutEnvNotSet(env.FunctionTarget), nil
 }
 
 func buildFn(ctx *gcp.Context) error {
-	if err := validateSource(ctx); err != nil {
+	fnSource, err := validateSource(ctx)
+	if err != nil {
+		return err
+	}
+	if err := checkFunctionTargets(ctx, fnSource); err != nil {
 		return err
 	}
 
@@ -59,16 +77,24 @@ func buildFn(ctx *gcp.Context) error {
 
 	// Determine if the function has dependency on functions-framework.
 	hasFrameworkDependency := false
-	requirementsExists, err := ctx.FileExists("requirements.txt")
+	reqs, err := python.UserRequirementsFiles(ctx)
 	if err != nil {
 		return err
 	}
-	if requirementsExists {
-		content, err := ctx.ReadFile("requirements.txt")
+	for _, req := range reqs {
+		content, err := ctx.ReadFile(req)
 		if err != nil {
 			return err
 		}
-		hasFrameworkDependency = containsFF(string(content))
+		if containsFF(string(content)) {
+			hasFrameworkDependency = true
+			break
+		}
+	}
+
+	ffVersion, pinned, err := cloudfunctions.PythonFramework.Version()
+	if err != nil {
+		return err
 	}
 
 	// Install functions-framework if necessary.
@@ -81,45 +107,116 @@ func buildFn(ctx *gcp.Context) error {
 		if err := ctx.ClearLayer(l); err != nil {
 			return fmt.Errorf("clearing layer %q: %w", l.Name, err)
 		}
+		if pinned {
+			ctx.Warnf("Ignoring %s because the function already depends on functions-framework.", python.FunctionsFrameworkVersionEnv)
+		}
 	} else {
 		ctx.Logf("Handling functions without dependency on functions-framework.")
 
 		// The pip install is performed by the pip buildpack; see python.InstallRequirements.
 		ctx.Debugf("Adding functions-framework requirements.txt to the list of requirements files to install.")
 		r := filepath.Join(ctx.BuildpackRoot(), "converter", "requirements.txt")
+		if pinned {
+			if err := checkFrameworkCompatibility(ctx, ffVersion); err != nil {
+				return err
+			}
+			ctx.Logf("Using functions-framework %s from %s.", ffVersion, python.FunctionsFrameworkVersionEnv)
+			r = filepath.Join(l.Path, "requirements.txt")
+			if err := ctx.WriteFile(r, []byte(python.FunctionsFrameworkRequirement(ffVersion)), 0644); err != nil {
+				return err
+			}
+		}
 		l.BuildEnvironment.Append(python.RequirementsFilesEnv, string(os.PathListSeparator), r)
+		cloudfunctions.RecordVersion(ctx, l, ffVersion)
 	}
 
 	if err := ctx.SetFunctionsEnvVars(l); err != nil {
 		return err
 	}
-	ctx.AddWebProcess([]string{"functions-framework"})
-	return nil
+	args, err := cloudfunctions.PythonFramework.Args()
+	if err != nil {
+		return err
+	}
+	return ctx.AddFunctionProcesses(append([]string{"functions-framework"}, args...))
 }
 
-func validateSource(ctx *gcp.Context) error {
+// validateSource returns the file that contains the function.
+func validateSource(ctx *gcp.Context) (string, error) {
 	// Fail if the default|custom source file doesn't exist, otherwise the app will fail at runtime but still build here.
 	fnSource, ok := os.LookupEnv(env.FunctionSource)
 	if !ok {
 		mainPYExists, err := ctx.FileExists("main.py")
 		if err != nil {
-			return err
+			return "", err
 		}
 		if !mainPYExists {
-			return gcp.UserErrorf("missing main.py and %s not specified. Either create the function in main.py or specify %s to point to the file that contains the function", env.FunctionSource, env.FunctionSource)
+			return "", gcp.UserErrorf("missing main.py and %s not specified. Either create the function in main.py or specify %s to point to the file that contains the function", env.FunctionSource, env.FunctionSource)
 		}
-	} else {
-		fnSourceExists, err := ctx.FileExists(fnSource)
-		if err != nil {
+		return "main.py", nil
+	}
+	fnSourceExists, err := ctx.FileExists(fnSource)
+	if err != nil {
+		return "", err
+	}
+	if !fnSourceExists {
+		return "", gcp.UserErrorf("%s specified file %q but it does not exist", env.FunctionSource, fnSource)
+	}
+	return fnSource, nil
+}
+
+// checkFunctionTargets fails the build if the function source registers functions with the
+// functions_framework decorators but not the function targets, or if the handlers of the targets
+// do not match their signature types.
+func checkFunctionTargets(ctx *gcp.Context, fnSource string) error {
+	content, err := ctx.ReadFile(fnSource)
+	if err != nil {
+		return err
+	}
+	targets, err := gcp.FunctionTargets()
+	if err != nil {
+		return err
+	}
+	src := string(content)
+	declared := cloudfunctions.PythonDeclaredFunctions(src)
+	for _, target := range targets {
+		if err := cloudfunctions.CheckFunctionTarget(ctx, target, fnSource, declared, cloudfunctions.PythonDefinesFunction(src, target)); err != nil {
 			return err
 		}
-		if !fnSourceExists {
-			return gcp.UserErrorf("%s specified file %q but it does not exist", env.FunctionSource, fnSource)
+		if err := cloudfunctions.CheckPythonSignature(target, fnSource, src, declared); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
+// checkFrameworkCompatibility fails the build if the pinned functions-framework version does not
+// support the Python version used by the build. The check is skipped if the supported Python
+// versions cannot be retrieved from PyPI.
+func checkFrameworkCompatibility(ctx *gcp.Context, version string) error {
+	var release pypiRelease
+	url := fmt.Sprintf(pypiReleaseURL, version)
+	if err := fetch.JSON(url, &release); err != nil {
+		ctx.Warnf("Unable to verify that functions-framework %s supports the Python version: %v", version, err)
+		return nil
+	}
+	if release.Info.RequiresPython == "" {
+		return nil
+	}
+	pyVer, err := python.Version(ctx)
+	if err != nil {
+		return err
+	}
+	ok, err := python.SatisfiesRequiresPython(pyVer, release.Info.RequiresPython)
+	if err != nil {
+		ctx.Warnf("Unable to verify that functions-framework %s supports %s: %v", version, pyVer, err)
+		return nil
+	}
+	if !ok {
+		return gcp.UserErrorf("functions-framework %s requires Python %s but the function uses %s, set %s to a version that supports %s", version, release.Info.RequiresPython, pyVer, python.FunctionsFrameworkVersionEnv, pyVer)
+	}
+	return nil
+}
+
 func containsFF(s string) bool {
 	return ffRegexp.MatchString(s) || eggRegexp.MatchString(s)
 }
@@ -81,6 +81,11 @@ func TestDetect(t *testing.T) {
 			env:  []string{"GOOGLE_FUNCTION_TARGET=helloWorld"},
 			want: 0,
 		},
+		{
+			name: "with targets",
+			env:  []string{"GOOGLE_FUNCTION_TARGETS=hello,world"},
+			want: 0,
+		},
 		{
 			name: "without target",
 			want: 100,
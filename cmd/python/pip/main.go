@@ -22,13 +22,17 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/cloudfunctions"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/python"
 	"github.com/buildpacks/libcnb"
 )
 
 const (
-	layerName = "pip"
+	layerName         = "pip"
+	bytecodeLayerName = "bytecode"
+	projectLayerName  = "project"
 )
 
 // metadata represents metadata stored for a dependencies layer.
@@ -39,19 +43,23 @@ type metadata struct {
 }
 
 func main() {
-	gcp.Main(detectFn, buildFn)
+	gcp.Main(detectFn, buildFn, gcp.WithFunctionSourceDir())
 }
 
 func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
 	plan := libcnb.BuildPlan{Requires: python.RequirementsRequires}
-	// If a requirement.txt file exists, the buildpack needs to provide the Requirements dependency.
+	// If a requirements file exists, the buildpack needs to provide the Requirements dependency.
 	// If the dependency is not provided by any buildpacks, lifecycle will exclude the pip
 	// buildpack from the build.
-	requirementsExists, err := ctx.FileExists("requirements.txt")
+	userReqs, err := python.UserRequirementsFiles(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if requirementsExists {
+	project, err := python.IsInstallableProject(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(userReqs) > 0 || project {
 		plan.Provides = python.RequirementsProvides
 	}
 	return gcp.OptInAlways(gcp.WithBuildPlans(plan)), nil
@@ -62,14 +70,12 @@ func buildFn(ctx *gcp.Context) error {
 	reqs := filepath.SplitList(strings.Trim(os.Getenv(python.RequirementsFilesEnv), string(os.PathListSeparator)))
 	ctx.Debugf("Found requirements.txt files provided by other buildpacks: %s", reqs)
 
-	// The workspace requirements.txt file should be installed last.
-	requirementsExists, err := ctx.FileExists("requirements.txt")
+	// The workspace requirements files should be installed last.
+	userReqs, err := python.UserRequirementsFiles(ctx)
 	if err != nil {
 		return err
 	}
-	if requirementsExists {
-		reqs = append(reqs, "requirements.txt")
-	}
+	reqs = append(reqs, userReqs...)
 
 	l, err := ctx.Layer(layerName, gcp.BuildLayer, gcp.CacheLayer, gcp.LaunchLayer)
 	if err != nil {
@@ -80,6 +86,56 @@ func buildFn(ctx *gcp.Context) error {
 		return fmt.Errorf("installing dependencies: %w", err)
 	}
 
+	// Directories holding Python code that is loaded at launch.
+	codeDirs := []string{l.Path, ctx.ApplicationRoot()}
+	project, err := python.IsInstallableProject(ctx)
+	if err != nil {
+		return err
+	}
+	if project {
+		// The project is kept out of the cached dependencies layer so that the dependencies layer can be
+		// reused as is when only the application code changes.
+		pl, err := ctx.Layer(projectLayerName, gcp.BuildLayer, gcp.LaunchLayer)
+		if err != nil {
+			return fmt.Errorf("creating %v layer: %w", projectLayerName, err)
+		}
+		if err := python.InstallProject(ctx, pl); err != nil {
+			return fmt.Errorf("installing project: %w", err)
+		}
+		codeDirs = append(codeDirs, pl.Path)
+	}
+
+	if err := python.WriteSBOM(ctx, l); err != nil {
+		return fmt.Errorf("writing SBOM: %w", err)
+	}
+
+	precompile, err := env.IsPresentAndTrue(python.PrecompileEnv)
+	if err != nil {
+		return err
+	}
+	if precompile {
+		bl, err := ctx.Layer(bytecodeLayerName, gcp.LaunchLayer)
+		if err != nil {
+			return fmt.Errorf("creating %v layer: %w", bytecodeLayerName, err)
+		}
+		if err := python.PrecompileBytecode(ctx, bl, codeDirs...); err != nil {
+			return fmt.Errorf("precompiling bytecode: %w", err)
+		}
+	}
+
+	verify, err := env.IsPresentAndTrue(python.VerifyImportEnv)
+	if err != nil {
+		return err
+	}
+	if verify {
+		if err := python.VerifyImport(ctx); err != nil {
+			return err
+		}
+	}
+	if err := smokeTestFunction(ctx); err != nil {
+		return err
+	}
+
 	ctx.Logf("Checking for incompatible dependencies.")
 	result, err := ctx.Exec([]string{"python3", "-m", "pip", "check"}, gcp.WithUserAttribution)
 	if result == nil {
@@ -97,6 +153,33 @@ func buildFn(ctx *gcp.Context) error {
 		ctx.Warnf("Found incompatible dependencies: %q", result.Stdout)
 		return nil
 	}
+	if conflicts := python.FunctionsFrameworkConflicts(result.Stdout); len(conflicts) > 0 {
+		return python.FunctionsFrameworkConflictError(conflicts)
+	}
 	return gcp.UserErrorf("found incompatible dependencies: %q", result.Stdout)
 
 }
+
+// smokeTestFunction starts the Functions Framework and sends a test request to the function if
+// GOOGLE_FUNCTION_SMOKE_TEST is enabled for a function, now that the framework and the
+// dependencies of the function are installed.
+func smokeTestFunction(ctx *gcp.Context) error {
+	_, hasTarget := os.LookupEnv(env.FunctionTarget)
+	_, hasTargets := os.LookupEnv(env.FunctionTargets)
+	if !hasTarget && !hasTargets {
+		return nil
+	}
+	enabled, err := cloudfunctions.SmokeTestEnabled()
+	if err != nil || !enabled {
+		return err
+	}
+	source := os.Getenv(env.FunctionSource)
+	if source == "" {
+		source = "main.py"
+	}
+	content, err := ctx.ReadFile(filepath.Join(ctx.ApplicationRoot(), source))
+	if err != nil {
+		return err
+	}
+	return cloudfunctions.SmokeTest(ctx, []string{"python3", "-m", "functions_framework"}, nil, cloudfunctions.PythonDeclaredFunctions(string(content)))
+}
@@ -15,15 +15,24 @@
 package main
 
 import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	buildpacktest "github.com/GoogleCloudPlatform/buildpacks/internal/buildpacktest"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/python"
+	"github.com/buildpacks/libcnb"
 )
 
 func TestDetect(t *testing.T) {
 	testCases := []struct {
 		name  string
 		files map[string]string
+		env   []string
 		want  int
 	}{
 		{
@@ -42,10 +51,123 @@ func TestDetect(t *testing.T) {
 			},
 			want: 0,
 		},
+		{
+			name: "installable pyproject",
+			files: map[string]string{
+				"pyproject.toml":   "[build-system]\nrequires = [\"setuptools\"]\n\n[project]\nname = \"myapp\"\n",
+				"src/myapp/app.py": "",
+			},
+			want: 0,
+		},
+		{
+			name: "requirements files from env",
+			files: map[string]string{
+				"main.py":               "",
+				"requirements-prod.txt": "",
+			},
+			env:  []string{"GOOGLE_PYTHON_REQUIREMENTS=requirements-prod.txt"},
+			want: 0,
+		},
+		{
+			name: "missing requirements file from env",
+			files: map[string]string{
+				"main.py": "",
+			},
+			env:  []string{"GOOGLE_PYTHON_REQUIREMENTS=requirements-prod.txt"},
+			want: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buildpacktest.TestDetect(t, detectFn, tc.name, tc.files, tc.env, tc.want)
+		})
+	}
+}
+
+// fakeFunctionsFramework is a Functions Framework that serves every request on $PORT.
+const fakeFunctionsFramework = `import http.server, os
+
+class Handler(http.server.BaseHTTPRequestHandler):
+    def do_GET(self):
+        self.send_response(200)
+        self.end_headers()
+    do_POST = do_GET
+
+http.server.HTTPServer(("127.0.0.1", int(os.environ["PORT"])), Handler).serve_forever()
+`
+
+func TestSmokeTestFunction(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 is not installed")
+	}
+	testCases := []struct {
+		name   string
+		cached bool
+	}{
+		{
+			name: "installed dependencies",
+		},
+		{
+			name:   "cached dependencies",
+			cached: true,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			buildpacktest.TestDetect(t, detectFn, tc.name, tc.files, []string{}, tc.want)
+			for _, e := range []string{"PYTHONUSERBASE", "VIRTUAL_ENV", env.Runtime, env.FunctionTargets, env.FunctionSource} {
+				t.Setenv(e, "")
+				os.Unsetenv(e)
+			}
+			t.Setenv(env.FunctionTarget, "hello")
+			t.Setenv(env.FunctionSmokeTest, "true")
+			root := t.TempDir()
+			for f, content := range map[string]string{"main.py": "def hello(request):\n    return 'OK'\n", "requirements.txt": ""} {
+				if err := os.WriteFile(filepath.Join(root, f), []byte(content), 0644); err != nil {
+					t.Fatalf("writing %s: %v", f, err)
+				}
+			}
+			ctx := gcp.NewContext(gcp.WithApplicationRoot(root))
+			req := filepath.Join(root, "requirements.txt")
+			l := newLayer(filepath.Join(t.TempDir(), layerName), map[string]interface{}{})
+			if err := python.InstallRequirements(ctx, l, req); err != nil {
+				t.Fatalf("InstallRequirements() got error: %v", err)
+			}
+			// The Functions Framework is installed with the dependencies of the function.
+			result, err := ctx.Exec([]string{"python3", "-c", "import site; print(site.getusersitepackages())"})
+			if err != nil {
+				t.Fatalf("finding site-packages: %v", err)
+			}
+			path := filepath.Join(strings.TrimSpace(result.Stdout), "functions_framework", "__main__.py")
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatalf("creating directory for %q: %v", path, err)
+			}
+			if err := os.WriteFile(path, []byte(fakeFunctionsFramework), 0644); err != nil {
+				t.Fatalf("writing %q: %v", path, err)
+			}
+			if tc.cached {
+				// The next build restores the layer and its metadata, but not the environment of the first
+				// build.
+				os.Unsetenv("PYTHONUSERBASE")
+				l = newLayer(l.Path, l.Metadata)
+				if err := python.InstallRequirements(ctx, l, req); err != nil {
+					t.Fatalf("InstallRequirements() with a cached layer got error: %v", err)
+				}
+			}
+
+			if err := smokeTestFunction(ctx); err != nil {
+				t.Errorf("smokeTestFunction() got error: %v", err)
+			}
 		})
 	}
 }
+
+func newLayer(path string, metadata map[string]interface{}) *libcnb.Layer {
+	return &libcnb.Layer{
+		Name:              layerName,
+		Path:              path,
+		Metadata:          metadata,
+		SharedEnvironment: libcnb.Environment{},
+		BuildEnvironment:  libcnb.Environment{},
+		LaunchEnvironment: libcnb.Environment{},
+	}
+}
@@ -35,7 +35,7 @@ const (
 var execPrefixRegex = regexp.MustCompile(`exec_prefix\s*=\s*"([^"]+)`)
 
 func main() {
-	gcp.Main(detectFn, buildFn)
+	gcp.Main(detectFn, buildFn, gcp.WithFunctionSourceDir())
 }
 
 func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
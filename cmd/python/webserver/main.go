@@ -13,7 +13,9 @@
 // limitations under the License.
 
 // Implements python/webserver buildpack.
-// The webserver buildpack installs gunicorn if a custom entrypoint is not specified.
+// The webserver buildpack installs gunicorn if a custom entrypoint is not specified, and serves
+// ASGI applications with uvicorn workers.
+// At launch, it sizes the gunicorn workers and threads to the container's CPU and memory.
 package main
 
 import (
@@ -21,14 +23,25 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
+	"strings"
 
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/cgroup"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/python"
 )
 
 const (
-	layerName = "gunicorn"
+	layerName       = "gunicorn"
+	tuningLayerName = "gunicorn-tuning"
+	// tuningExecD is the name of the exec.d helper that tunes gunicorn at launch time.
+	tuningExecD = "gunicorn-tuning"
+
+	// webConcurrencyEnv is read by gunicorn as the default number of workers.
+	webConcurrencyEnv = "WEB_CONCURRENCY"
+	// gunicornCmdArgsEnv is read by gunicorn as additional command line arguments.
+	gunicornCmdArgsEnv = "GUNICORN_CMD_ARGS"
 )
 
 var (
@@ -37,25 +50,27 @@ var (
 )
 
 func main() {
-	gcp.Main(detectFn, buildFn)
+	gcp.Main(detectFn, buildFn, gcp.WithExecD(tuningExecD, tuneGunicorn))
 }
 
 func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
 	if os.Getenv(env.Entrypoint) != "" {
 		return gcp.OptOut("custom entrypoint present"), nil
 	}
-	requirementsExists, err := ctx.FileExists("requirements.txt")
+	reqs, err := python.UserRequirementsFiles(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if requirementsExists {
-		present, err := gunicornPresentInRequirements(ctx, "requirements.txt")
+	for _, req := range reqs {
+		present, err := gunicornPresentInRequirements(ctx, req)
 		if err != nil {
 			return nil, fmt.Errorf("error detecting gunicorn: %w", err)
 		}
 		if present {
-			return gcp.OptOut("gunicorn present in requirements.txt"), nil
+			return gcp.OptOut(fmt.Sprintf("gunicorn present in %s", req)), nil
 		}
+	}
+	if len(reqs) > 0 {
 		return gcp.OptIn("gunicorn missing from requirements.txt", gcp.WithBuildPlans(python.RequirementsProvidesPlan)), nil
 	}
 	return gcp.OptIn("requirements.txt with gunicorn not found", gcp.WithBuildPlans(python.RequirementsProvidesPlan)), nil
@@ -71,9 +86,75 @@ func buildFn(ctx *gcp.Context) error {
 	ctx.Debugf("Adding webserver requirements.txt to the list of requirements files to install.")
 	r := filepath.Join(ctx.BuildpackRoot(), "requirements.txt")
 	l.BuildEnvironment.Append(python.RequirementsFilesEnv, string(os.PathListSeparator), r)
+
+	asgi, err := python.IsASGIApp(ctx)
+	if err != nil {
+		return fmt.Errorf("detecting ASGI application: %w", err)
+	}
+	if asgi {
+		uvicorn, err := python.RequirementsContain(ctx, "uvicorn")
+		if err != nil {
+			return err
+		}
+		if !uvicorn {
+			ctx.Logf("ASGI application detected, adding uvicorn to the list of requirements files to install.")
+			r := filepath.Join(ctx.BuildpackRoot(), "asgi", "requirements.txt")
+			l.BuildEnvironment.Append(python.RequirementsFilesEnv, string(os.PathListSeparator), r)
+		}
+		if err := addASGIProcess(ctx); err != nil {
+			return err
+		}
+	}
+
+	tl, err := ctx.Layer(tuningLayerName, gcp.LaunchLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", tuningLayerName, err)
+	}
+	ctx.Logf("gunicorn workers and threads will be sized to the container at launch; set %s or --threads in %s to override.", webConcurrencyEnv, gunicornCmdArgsEnv)
+	return ctx.AddExecD(tl, tuningExecD)
+}
+
+// addASGIProcess serves an ASGI application with uvicorn workers, because the default WSGI
+// entrypoint fails at run time for ASGI applications. A Procfile takes precedence, and App Engine
+// apps get their entrypoint from the appengine buildpack.
+func addASGIProcess(ctx *gcp.Context) error {
+	if env.IsGAE() {
+		return nil
+	}
+	procExists, err := ctx.FileExists(ctx.ApplicationRoot(), "Procfile")
+	if err != nil {
+		return err
+	}
+	if procExists {
+		return nil
+	}
+	ctx.Logf("ASGI application detected, using entrypoint: %s", python.ASGIEntrypoint)
+	ctx.AddProcess(gcp.WebProcess, []string{python.ASGIEntrypoint}, gcp.AsDefaultProcess())
 	return nil
 }
 
+// tuneGunicorn is an exec.d helper that sizes gunicorn to the container's CPU and memory.
+func tuneGunicorn() (map[string]string, error) {
+	memory, err := cgroup.MemoryLimit()
+	if err != nil {
+		return nil, err
+	}
+	return gunicornEnv(cgroup.CPUs(), memory), nil
+}
+
+// gunicornEnv returns the launch environment for gunicorn, leaving user-provided settings intact.
+func gunicornEnv(cpus int, memory int64) map[string]string {
+	workers, threads := python.GunicornSettings(cpus, memory)
+	e := map[string]string{}
+	if os.Getenv(webConcurrencyEnv) == "" {
+		e[webConcurrencyEnv] = strconv.Itoa(workers)
+	}
+	if args := os.Getenv(gunicornCmdArgsEnv); !strings.Contains(args, "--threads") {
+		e[gunicornCmdArgsEnv] = strings.TrimSpace(fmt.Sprintf("%s --threads=%d", args, threads))
+	}
+	return e
+}
+
 func gunicornPresentInRequirements(ctx *gcp.Context, path string) (bool, error) {
 	content, err := ctx.ReadFile(path)
 	if err != nil {
@@ -15,8 +15,16 @@
 package main
 
 import (
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/python"
+	"github.com/buildpacks/libcnb"
+	"github.com/google/go-cmp/cmp"
+
 	buildpacktest "github.com/GoogleCloudPlatform/buildpacks/internal/buildpacktest"
 )
 
@@ -56,6 +64,15 @@ func TestDetect(t *testing.T) {
 				"requirements.txt": "gunicorn==19.3.0"},
 			want: 100,
 		},
+		{
+			name: "has gunicorn in second requirements file",
+			files: map[string]string{
+				"main.py":               "",
+				"requirements.txt":      "flask",
+				"requirements-prod.txt": "gunicorn==19.3.0"},
+			env:  []string{"GOOGLE_PYTHON_REQUIREMENTS=requirements.txt,requirements-prod.txt"},
+			want: 100,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -111,3 +128,98 @@ func TestContainsGunicorn(t *testing.T) {
 		})
 	}
 }
+
+func TestAddASGIProcess(t *testing.T) {
+	testCases := []struct {
+		name     string
+		platform string
+		procfile bool
+		want     []libcnb.Process
+	}{
+		{
+			name: "no Procfile",
+			want: []libcnb.Process{{Type: gcp.WebProcess, Command: python.ASGIEntrypoint, Default: true}},
+		},
+		{
+			name:     "Procfile",
+			procfile: true,
+		},
+		{
+			name:     "App Engine",
+			platform: env.TargetPlatformAppEngine,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(env.XGoogleTargetPlatform, tc.platform)
+			dir := t.TempDir()
+			if tc.procfile {
+				if err := ioutil.WriteFile(filepath.Join(dir, "Procfile"), []byte("web: uvicorn main:app"), 0644); err != nil {
+					t.Fatalf("writing Procfile: %v", err)
+				}
+			}
+			ctx := gcp.NewContext(gcp.WithApplicationRoot(dir))
+
+			if err := addASGIProcess(ctx); err != nil {
+				t.Fatalf("addASGIProcess() got error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, ctx.Processes()); diff != "" {
+				t.Errorf("addASGIProcess() processes mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGunicornEnv(t *testing.T) {
+	const mb = 1024 * 1024
+	testCases := []struct {
+		name string
+		env  map[string]string
+		want map[string]string
+	}{
+		{
+			name: "defaults",
+			want: map[string]string{
+				"WEB_CONCURRENCY":   "2",
+				"GUNICORN_CMD_ARGS": "--threads=4",
+			},
+		},
+		{
+			name: "user workers",
+			env:  map[string]string{"WEB_CONCURRENCY": "5"},
+			want: map[string]string{
+				"GUNICORN_CMD_ARGS": "--threads=4",
+			},
+		},
+		{
+			name: "user args without threads",
+			env:  map[string]string{"GUNICORN_CMD_ARGS": "--timeout=0"},
+			want: map[string]string{
+				"WEB_CONCURRENCY":   "2",
+				"GUNICORN_CMD_ARGS": "--timeout=0 --threads=4",
+			},
+		},
+		{
+			name: "user threads",
+			env: map[string]string{
+				"WEB_CONCURRENCY":   "1",
+				"GUNICORN_CMD_ARGS": "--threads=16",
+			},
+			want: map[string]string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("WEB_CONCURRENCY", "")
+			t.Setenv("GUNICORN_CMD_ARGS", "")
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			got := gunicornEnv(1, 512*mb)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("gunicornEnv() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
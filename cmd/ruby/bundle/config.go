@@ -0,0 +1,99 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+const (
+	// The Bundler settings that are read from the environment. Bundler gives the local config of the
+	// application precedence over the environment, so they are applied to the local config and to
+	// the bundle install command instead.
+	bundleWithoutEnv = "BUNDLE_WITHOUT"
+	bundleFrozenEnv  = "BUNDLE_FROZEN"
+	bundleJobsEnv    = "BUNDLE_JOBS"
+	bundleRetryEnv   = "BUNDLE_RETRY"
+
+	defaultWithout = "development test"
+)
+
+// bundleConfig is the configuration of the bundle install command.
+type bundleConfig struct {
+	// without is the space-separated list of groups that are not installed.
+	without string
+	// frozen is true if the Gemfile.lock must not be changed by bundle install.
+	frozen bool
+	// jobs is the number of gems that are installed in parallel, 0 for the Bundler default.
+	jobs int
+	// retry is the number of times that failed network requests are retried, 0 for the Bundler
+	// default.
+	retry int
+}
+
+// bundleConfigFromEnv returns the bundle install configuration from the Bundler environment
+// variables. Groups may be separated with colons or spaces, as in the Bundler settings.
+func bundleConfigFromEnv() (bundleConfig, error) {
+	cfg := bundleConfig{without: defaultWithout, frozen: true}
+	if v, ok := os.LookupEnv(bundleWithoutEnv); ok {
+		// An empty value installs all groups.
+		cfg.without = strings.Join(strings.FieldsFunc(v, func(r rune) bool { return r == ':' || r == ' ' || r == ',' }), " ")
+	}
+	if v := os.Getenv(bundleFrozenEnv); v != "" {
+		frozen, err := strconv.ParseBool(v)
+		if err != nil {
+			return bundleConfig{}, gcp.UserErrorf("invalid %s %q, it must be true or false", bundleFrozenEnv, v)
+		}
+		cfg.frozen = frozen
+	}
+	var err error
+	if cfg.jobs, err = positiveInt(bundleJobsEnv); err != nil {
+		return bundleConfig{}, err
+	}
+	if cfg.retry, err = positiveInt(bundleRetryEnv); err != nil {
+		return bundleConfig{}, err
+	}
+	return cfg, nil
+}
+
+// installArgs returns the arguments of the bundle install command.
+func (c bundleConfig) installArgs() []string {
+	args := []string{"bundle", "install"}
+	if c.jobs > 0 {
+		args = append(args, "--jobs", strconv.Itoa(c.jobs))
+	}
+	if c.retry > 0 {
+		args = append(args, "--retry", strconv.Itoa(c.retry))
+	}
+	return args
+}
+
+// positiveInt returns the value of the environment variable, which must be a positive integer, or 0
+// if it is not set.
+func positiveInt(name string) (int, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return 0, gcp.UserErrorf("invalid %s %q, it must be a positive integer", name, v)
+	}
+	return n, nil
+}
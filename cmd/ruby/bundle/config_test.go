@@ -0,0 +1,102 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestBundleConfigFromEnv(t *testing.T) {
+	testCases := []struct {
+		name     string
+		env      map[string]string
+		want     bundleConfig
+		wantArgs []string
+		wantErr  bool
+	}{
+		{
+			name:     "defaults",
+			want:     bundleConfig{without: "development test", frozen: true},
+			wantArgs: []string{"bundle", "install"},
+		},
+		{
+			name:     "without groups separated by colons",
+			env:      map[string]string{"BUNDLE_WITHOUT": "development:test:assets"},
+			want:     bundleConfig{without: "development test assets", frozen: true},
+			wantArgs: []string{"bundle", "install"},
+		},
+		{
+			name:     "install all groups",
+			env:      map[string]string{"BUNDLE_WITHOUT": ""},
+			want:     bundleConfig{frozen: true},
+			wantArgs: []string{"bundle", "install"},
+		},
+		{
+			name:     "not frozen",
+			env:      map[string]string{"BUNDLE_FROZEN": "false"},
+			want:     bundleConfig{without: "development test"},
+			wantArgs: []string{"bundle", "install"},
+		},
+		{
+			name:     "jobs and retry",
+			env:      map[string]string{"BUNDLE_JOBS": "4", "BUNDLE_RETRY": "3"},
+			want:     bundleConfig{without: "development test", frozen: true, jobs: 4, retry: 3},
+			wantArgs: []string{"bundle", "install", "--jobs", "4", "--retry", "3"},
+		},
+		{
+			name:    "invalid frozen",
+			env:     map[string]string{"BUNDLE_FROZEN": "sometimes"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid jobs",
+			env:     map[string]string{"BUNDLE_JOBS": "0"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid retry",
+			env:     map[string]string{"BUNDLE_RETRY": "many"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, name := range []string{bundleWithoutEnv, bundleFrozenEnv, bundleJobsEnv, bundleRetryEnv} {
+				// t.Setenv restores the variable after the test, unset variables must not be present.
+				t.Setenv(name, "")
+				os.Unsetenv(name)
+			}
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			got, err := bundleConfigFromEnv()
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("bundleConfigFromEnv() got error: %v, want error: %t", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if got != tc.want {
+				t.Errorf("bundleConfigFromEnv() = %+v, want %+v", got, tc.want)
+			}
+			if args := got.installArgs(); !reflect.DeepEqual(args, tc.wantArgs) {
+				t.Errorf("installArgs() = %v, want %v", args, tc.wantArgs)
+			}
+		})
+	}
+}
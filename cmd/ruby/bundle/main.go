@@ -18,11 +18,13 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/buildererror"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/cache"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/ruby"
 	"github.com/buildpacks/libcnb"
 )
 
@@ -30,10 +32,16 @@ const (
 	layerName         = "gems"
 	dependencyHashKey = "dependency_hash"
 	rubyVersionKey    = "ruby_version"
+	rubyABIKey        = "ruby_abi"
+	// bundleDirName is the directory of the gems layer that the application .bundle directory links to.
+	bundleDirName = ".bundle"
+
+	// rubyABIScript prints the ABI version, the engine and the platform of the Ruby runtime.
+	rubyABIScript = `print RbConfig::CONFIG["ruby_version"], " ", RUBY_ENGINE, " ", RUBY_PLATFORM`
 )
 
 func main() {
-	gcp.Main(detectFn, buildFn)
+	gcp.Main(detectFn, buildFn, gcp.WithFunctionSourceDir())
 }
 
 func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
@@ -92,27 +100,50 @@ func buildFn(ctx *gcp.Context) error {
 		return err
 	}
 
+	// The gems are installed directly into their own layer, which is reused as long as the lockfile
+	// and the Ruby version do not change, whatever the changes to the application.
 	deps, err := ctx.Layer(layerName, gcp.BuildLayer, gcp.CacheLayer, gcp.LaunchLayer)
 	if err != nil {
 		return fmt.Errorf("creating %v layer: %w", layerName, err)
 	}
 
-	// This layer directory contains the files installed by bundler into the application .bundle directory
-	bundleOutput := filepath.Join(deps.Path, ".bundle")
+	// This layer directory contains the bundler config, the installed gems and the gem binaries,
+	// laid out as the application .bundle directory.
+	bundleDir := filepath.Join(deps.Path, bundleDirName)
+	gemsDir := filepath.Join(bundleDir, "gems")
+	binDir := filepath.Join(bundleDir, "bin")
+
+	cfg, err := bundleConfigFromEnv()
+	if err != nil {
+		return err
+	}
 
-	cached, err := checkCache(ctx, deps, cache.WithFiles(lockFile))
+	// The installed groups are part of the cache key, the frozen setting and the parallelism do not
+	// change the installed gems.
+	key, err := currentCacheKey(ctx, cache.WithFiles(lockFile), cache.WithStrings(cfg.without))
 	if err != nil {
 		return fmt.Errorf("checking cache: %w", err)
 	}
 
-	localGemsDir := filepath.Join(".bundle", "gems")
-	localBinDir := filepath.Join(".bundle", "bin")
+	credsEnv, cleanup, err := credentialsEnv(ctx)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
 
-	// Ensure the GCP runtime platform is present in the lockfile. This is needed for Bundler >= 2.2, in case the user's lockfile is specific to a different platform.
-	if _, err := ctx.Exec([]string{"bundle", "config", "--local", "without", "development test"}, gcp.WithUserAttribution); err != nil {
+	// Ensure the GCP runtime platform is present in the lockfile. This is needed for Bundler >= 2.2,
+	// in case the user's lockfile is specific to a different platform. The lockfile is updated with a
+	// throwaway config, the frozen setting of the installation would prevent the update.
+	lockConfig, err := os.MkdirTemp("", "bundle-lock")
+	if err != nil {
+		return gcp.InternalErrorf("creating temporary bundle config directory: %v", err)
+	}
+	defer os.RemoveAll(lockConfig)
+	lockEnv := append([]string{"BUNDLE_APP_CONFIG=" + lockConfig}, credsEnv...)
+	if err := configureWithout(ctx, cfg, lockEnv...); err != nil {
 		return err
 	}
-	if _, err := ctx.Exec([]string{"bundle", "config", "--local", "path", localGemsDir}, gcp.WithUserAttribution); err != nil {
+	if _, err := ctx.Exec([]string{"bundle", "config", "--local", "path", gemsDir}, gcp.WithEnv(lockEnv...), gcp.WithUserAttribution); err != nil {
 		return err
 	}
 
@@ -120,99 +151,163 @@ func buildFn(ctx *gcp.Context) error {
 	// It'll use the currently activated bundler version instead
 	// This was a change in bundler 2.1+
 	// https://github.com/rubygems/rubygems/issues/5683
-	if _, err := ctx.Exec([]string{"bundle", "lock", "--add-platform", "x86_64-linux"}, gcp.WithUserAttribution); err != nil {
-		return err
-	}
-	if _, err := ctx.Exec([]string{"bundle", "lock", "--add-platform", "ruby"}, gcp.WithUserAttribution); err != nil {
+	if _, err := ctx.Exec([]string{"bundle", "lock", "--add-platform", platform()}, gcp.WithEnv(lockEnv...), gcp.WithUserAttribution); err != nil {
 		return err
 	}
-	if err := ctx.RemoveAll(".bundle"); err != nil {
+	if _, err := ctx.Exec([]string{"bundle", "lock", "--add-platform", "ruby"}, gcp.WithEnv(lockEnv...), gcp.WithUserAttribution); err != nil {
 		return err
 	}
 
-	if cached {
+	if reason := key.missReason(cachedKey(ctx, deps)); reason == "" {
 		ctx.CacheHit(layerName)
+		ctx.Logf("Dependencies cache hit, skipping installation.")
 	} else {
 		ctx.CacheMiss(layerName)
-
-		// Install the bundle locally into .bundle/gems
-		if _, err := ctx.Exec([]string{"bundle", "config", "--local", "deployment", "true"}, gcp.WithUserAttribution); err != nil {
+		ctx.Logf("Installing application dependencies, %s.", reason)
+		if err := ctx.ClearLayer(deps); err != nil {
+			return fmt.Errorf("clearing layer %q: %w", deps.Name, err)
+		}
+		if err := ctx.MkdirAll(bundleDir, 0755); err != nil {
 			return err
 		}
-		if _, err := ctx.Exec([]string{"bundle", "config", "--local", "frozen", "true"}, gcp.WithUserAttribution); err != nil {
+
+		installEnv := []string{"BUNDLE_APP_CONFIG=" + bundleDir}
+		// Install the bundle into the layer.
+		if cfg.frozen {
+			if _, err := ctx.Exec([]string{"bundle", "config", "--local", "deployment", "true"}, gcp.WithEnv(installEnv...), gcp.WithUserAttribution); err != nil {
+				return err
+			}
+			if _, err := ctx.Exec([]string{"bundle", "config", "--local", "frozen", "true"}, gcp.WithEnv(installEnv...), gcp.WithUserAttribution); err != nil {
+				return err
+			}
+		} else {
+			ctx.Warnf("%s is false, bundle install may update %s.", bundleFrozenEnv, lockFile)
+		}
+		if err := configureWithout(ctx, cfg, installEnv...); err != nil {
 			return err
 		}
-		if _, err := ctx.Exec([]string{"bundle", "config", "--local", "without", "development test"}, gcp.WithUserAttribution); err != nil {
+		if _, err := ctx.Exec([]string{"bundle", "config", "--local", "path", gemsDir}, gcp.WithEnv(installEnv...), gcp.WithUserAttribution); err != nil {
 			return err
 		}
-		if _, err := ctx.Exec([]string{"bundle", "config", "--local", "path", localGemsDir}, gcp.WithUserAttribution); err != nil {
+		if !ruby.IsJRuby() {
+			if err := checkNativeDependencies(ctx, lockFile); err != nil {
+				return err
+			}
+		}
+		installEnv = append(installEnv, "NOKOGIRI_USE_SYSTEM_LIBRARIES=1", "MALLOC_ARENA_MAX=2", "LANG=C.utf8")
+		if result, err := ctx.Exec(cfg.installArgs(), gcp.WithEnv(append(installEnv, credsEnv...)...), gcp.WithUserAttribution); err != nil {
+			if result != nil {
+				if hint := installFailureHint(result.Combined); hint != "" {
+					ctx.Warnf("%s", hint)
+				}
+			}
 			return err
 		}
-		if _, err := ctx.Exec([]string{"bundle", "install"},
-			gcp.WithEnv("NOKOGIRI_USE_SYSTEM_LIBRARIES=1", "MALLOC_ARENA_MAX=2", "LANG=C.utf8"), gcp.WithUserAttribution); err != nil {
+		if err := removeGitCredentials(ctx, gemsDir); err != nil {
 			return err
 		}
 
 		// Find any gem-installed binary directory and symlink as a static path
-		foundBinDirs, err := ctx.Glob(".bundle/gems/ruby/*/bin")
+		foundBinDirs, err := ctx.Glob(filepath.Join(gemsDir, "ruby", "*", "bin"))
 		if err != nil {
 			return fmt.Errorf("finding bin dirs: %w", err)
 		}
 		if len(foundBinDirs) > 1 {
 			return fmt.Errorf("unexpected multiple gem bin dirs: %v", foundBinDirs)
 		} else if len(foundBinDirs) == 1 {
-			if err := ctx.Symlink(filepath.Join(ctx.ApplicationRoot(), foundBinDirs[0]), localBinDir); err != nil {
+			if err := ctx.Symlink(foundBinDirs[0], binDir); err != nil {
 				return err
 			}
 		}
-
-		// Move the built .bundle directory into the layer
-		if err := ctx.RemoveAll(bundleOutput); err != nil {
-			return err
-		}
-		if _, err := ctx.Exec([]string{"mv", ".bundle", bundleOutput}, gcp.WithUserTimingAttribution); err != nil {
-			return err
-		}
+		key.setMetadata(ctx, deps)
 	}
 
+	// Bundler reads its config from the layer, both in later buildpacks and at launch.
+	deps.SharedEnvironment.Override("BUNDLE_APP_CONFIG", bundleDir)
+
 	// Always link local .bundle directory to the actual installation stored in the layer.
-	if err := ctx.Symlink(bundleOutput, ".bundle"); err != nil {
+	if err := ctx.Symlink(bundleDir, ".bundle"); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-// checkCache checks whether cached dependencies exist and match.
-func checkCache(ctx *gcp.Context, l *libcnb.Layer, opts ...cache.Option) (bool, error) {
+// platform returns the platform of the gems installed for the Ruby engine, JRuby installs the
+// java variants of platform-specific gems.
+func platform() string {
+	if ruby.IsJRuby() {
+		return "java"
+	}
+	return "x86_64-linux"
+}
+
+// configureWithout sets the groups that are not installed in the local config. All groups are
+// installed if the list is empty. The env selects the config, e.g. with BUNDLE_APP_CONFIG.
+func configureWithout(ctx *gcp.Context, cfg bundleConfig, env ...string) error {
+	if cfg.without == "" {
+		return nil
+	}
+	_, err := ctx.Exec([]string{"bundle", "config", "--local", "without", cfg.without}, gcp.WithEnv(env...), gcp.WithUserAttribution)
+	return err
+}
+
+// cacheKey identifies the gems installed in the layer.
+type cacheKey struct {
+	// dependencyHash is the digest of the lockfile and of the installed groups.
+	dependencyHash string
+	// rubyABI is the ABI version, the engine and the platform of the Ruby runtime. Compiled native
+	// extensions are compatible with the Ruby versions of the same ABI, e.g. 3.2.0 for all 3.2.x
+	// versions, on the same engine and platform.
+	rubyABI string
+	// rubyVersion is the full version of the Ruby runtime, for reference only.
+	rubyVersion string
+}
+
+// currentCacheKey returns the cache key of the gems of the current build.
+func currentCacheKey(ctx *gcp.Context, opts ...cache.Option) (cacheKey, error) {
 	result, err := ctx.Exec([]string{"ruby", "-v"})
 	if err != nil {
-		return false, err
+		return cacheKey{}, err
 	}
-	currentRubyVersion := result.Stdout
-	opts = append(opts, cache.WithStrings(currentRubyVersion))
-	currentDependencyHash, err := cache.Hash(ctx, opts...)
+	rubyVersion := result.Stdout
+	result, err = ctx.Exec([]string{"ruby", "-e", rubyABIScript})
 	if err != nil {
-		return false, fmt.Errorf("computing dependency hash: %v", err)
+		return cacheKey{}, err
 	}
-
-	// Perform install, skipping if the dependency hash matches existing metadata.
-	metaDependencyHash := ctx.GetMetadata(l, dependencyHashKey)
-	ctx.Debugf("Current dependency hash: %q", currentDependencyHash)
-	ctx.Debugf("  Cache dependency hash: %q", metaDependencyHash)
-	if currentDependencyHash == metaDependencyHash {
-		ctx.Logf("Dependencies cache hit, skipping installation.")
-		return true, nil
+	hash, err := cache.Hash(ctx, opts...)
+	if err != nil {
+		return cacheKey{}, fmt.Errorf("computing dependency hash: %v", err)
 	}
+	return cacheKey{dependencyHash: hash, rubyABI: result.Stdout, rubyVersion: rubyVersion}, nil
+}
 
-	if metaDependencyHash == "" {
-		ctx.Debugf("No metadata found from a previous build, skipping cache.")
+// cachedKey returns the cache key of the gems in the layer from a previous build.
+func cachedKey(ctx *gcp.Context, l *libcnb.Layer) cacheKey {
+	return cacheKey{
+		dependencyHash: ctx.GetMetadata(l, dependencyHashKey),
+		rubyABI:        ctx.GetMetadata(l, rubyABIKey),
+		rubyVersion:    ctx.GetMetadata(l, rubyVersionKey),
 	}
-	ctx.Logf("Installing application dependencies.")
+}
 
-	// Update the layer metadata.
-	ctx.SetMetadata(l, dependencyHashKey, currentDependencyHash)
-	ctx.SetMetadata(l, rubyVersionKey, currentRubyVersion)
+// setMetadata records the cache key in the layer metadata.
+func (k cacheKey) setMetadata(ctx *gcp.Context, l *libcnb.Layer) {
+	ctx.SetMetadata(l, dependencyHashKey, k.dependencyHash)
+	ctx.SetMetadata(l, rubyABIKey, k.rubyABI)
+	ctx.SetMetadata(l, rubyVersionKey, k.rubyVersion)
+}
 
-	return false, nil
+// missReason returns why the gems cached with the given key cannot be reused, or an empty string
+// if they can.
+func (k cacheKey) missReason(cached cacheKey) string {
+	switch {
+	case cached.dependencyHash == "":
+		return "no gems from a previous build"
+	case k.dependencyHash != cached.dependencyHash:
+		return "the lockfile or the installed groups changed"
+	case k.rubyABI != cached.rubyABI:
+		return "the Ruby version changed"
+	}
+	return ""
 }
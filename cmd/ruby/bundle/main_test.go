@@ -60,3 +60,42 @@ func TestDetect(t *testing.T) {
 		})
 	}
 }
+
+func TestCacheKeyMissReason(t *testing.T) {
+	current := cacheKey{dependencyHash: "abc", rubyABI: "3.2.0 ruby x86_64-linux", rubyVersion: "ruby 3.2.2"}
+	testCases := []struct {
+		name   string
+		cached cacheKey
+		want   string
+	}{
+		{
+			name:   "same key",
+			cached: current,
+		},
+		{
+			name:   "different patch version of the same ABI",
+			cached: cacheKey{dependencyHash: "abc", rubyABI: "3.2.0 ruby x86_64-linux", rubyVersion: "ruby 3.2.1"},
+		},
+		{
+			name: "no previous build",
+			want: "no gems from a previous build",
+		},
+		{
+			name:   "lockfile changed",
+			cached: cacheKey{dependencyHash: "def", rubyABI: "3.2.0 ruby x86_64-linux"},
+			want:   "the lockfile or the installed groups changed",
+		},
+		{
+			name:   "ruby version changed",
+			cached: cacheKey{dependencyHash: "abc", rubyABI: "3.1.0 ruby x86_64-linux"},
+			want:   "the Ruby version changed",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := current.missReason(tc.cached); got != tc.want {
+				t.Errorf("missReason(%+v) = %q, want %q", tc.cached, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/ruby"
+)
+
+// nativeDependency is a system library or tool that a gem with a native extension is compiled
+// with.
+type nativeDependency struct {
+	// headers are the header files of the library, relative to an include directory. The
+	// library is present if any of them is found.
+	headers []string
+	// commands are the tools that compile the extension, which must all be on the PATH.
+	commands []string
+	// pkg is the Ubuntu package that provides the headers or the commands.
+	pkg string
+}
+
+var (
+	// nativeGems are the system libraries required to compile popular gems with native extensions.
+	// Gems with precompiled variants for the platform, e.g. nokogiri and grpc, only compile their
+	// extension when the precompiled variant is not locked.
+	nativeGems = map[string]nativeDependency{
+		"pg":       {headers: []string{"libpq-fe.h", "postgresql/libpq-fe.h"}, pkg: "libpq-dev"},
+		"mysql2":   {headers: []string{"mysql/mysql.h", "mariadb/mysql.h"}, pkg: "default-libmysqlclient-dev"},
+		"sqlite3":  {headers: []string{"sqlite3.h"}, pkg: "libsqlite3-dev"},
+		"nokogiri": {headers: []string{"libxml2/libxml/parser.h"}, pkg: "libxml2-dev"},
+		"rmagick":  {headers: []string{"ImageMagick-6/MagickCore/MagickCore.h", "ImageMagick-7/MagickCore/MagickCore.h"}, pkg: "libmagickwand-dev"},
+		"grpc":     {commands: []string{"g++"}, pkg: "g++"},
+	}
+	// includeDirs are the directories searched for the headers.
+	includeDirs = []string{"/usr/include", "/usr/local/include", "/usr/include/x86_64-linux-gnu"}
+	// installErrorRegexp matches the gem that Bundler failed to install, e.g.
+	// "An error occurred while installing pg (1.5.4), and Bundler cannot continue."
+	installErrorRegexp = regexp.MustCompile(`An error occurred while installing ([\w.-]+) \(`)
+)
+
+// missingNativeDependencies returns the gems in the lock file whose native extensions cannot be
+// compiled because the headers of a system library are not installed, with the packages that
+// provide them, sorted by gem name.
+func missingNativeDependencies(ctx *gcp.Context, lockFile string) ([]string, error) {
+	gems, err := ruby.ParseLockedGems(lockFile)
+	if err != nil {
+		return nil, gcp.UserErrorf("parsing %s: %v", lockFile, err)
+	}
+	content, err := ctx.ReadFile(lockFile)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for name := range gems {
+		dep, ok := nativeGems[name]
+		if !ok || precompiled(string(content), name) {
+			continue
+		}
+		found, err := dependencyExists(ctx, dep)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			missing = append(missing, fmt.Sprintf("%s (requires %s)", name, dep.pkg))
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+// precompiled returns true if the lock file contains a variant of the gem precompiled for Linux,
+// e.g. nokogiri (1.15.4-x86_64-linux), which Bundler installs instead of compiling the extension.
+func precompiled(lockFile, gem string) bool {
+	return regexp.MustCompile(`(?m)^    ` + regexp.QuoteMeta(gem) + ` \([^)]+-x86_64-linux[^)]*\)$`).MatchString(lockFile)
+}
+
+// dependencyExists returns true if any of the headers is in an include directory and all of the
+// commands are on the PATH.
+func dependencyExists(ctx *gcp.Context, dep nativeDependency) (bool, error) {
+	for _, c := range dep.commands {
+		if _, err := exec.LookPath(c); err != nil {
+			return false, nil
+		}
+	}
+	if len(dep.headers) == 0 {
+		return true, nil
+	}
+	for _, dir := range includeDirs {
+		for _, h := range dep.headers {
+			exists, err := ctx.FileExists(filepath.Join(dir, h))
+			if err != nil {
+				return false, err
+			}
+			if exists {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// checkNativeDependencies returns an error naming the missing system packages if the native
+// extensions of the locked gems cannot be compiled in the build image.
+func checkNativeDependencies(ctx *gcp.Context, lockFile string) error {
+	missing, err := missingNativeDependencies(ctx, lockFile)
+	if err != nil {
+		return err
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return gcp.UserErrorf("the native extensions of the following gems cannot be compiled because system packages are missing from the build image: %s. Use gem versions with precompiled binaries for x86_64-linux, or a builder with the packages installed", strings.Join(missing, ", "))
+}
+
+// installFailureHint returns a hint about the system package required by the gem that failed to
+// install, according to the output of bundle install, or an empty string if there is none.
+func installFailureHint(output string) string {
+	m := installErrorRegexp.FindStringSubmatch(output)
+	if m == nil || !strings.Contains(output, "Failed to build gem native extension") {
+		return ""
+	}
+	dep, ok := nativeGems[m[1]]
+	if !ok {
+		return fmt.Sprintf("The native extension of gem %s may require a system package that is missing from the build image.", m[1])
+	}
+	return fmt.Sprintf("Gem %s requires the system package %s to compile its native extension.", m[1], dep.pkg)
+}
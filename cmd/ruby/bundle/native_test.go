@@ -0,0 +1,115 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+func TestMissingNativeDependencies(t *testing.T) {
+	testCases := []struct {
+		name     string
+		lockFile string
+		headers  []string
+		want     []string
+	}{
+		{
+			name:     "no native gems",
+			lockFile: "GEM\n  specs:\n    rack (3.0.8)\n",
+		},
+		{
+			name:     "missing headers",
+			lockFile: "GEM\n  specs:\n    mysql2 (0.5.5)\n    pg (1.5.4)\n    rack (3.0.8)\n",
+			want:     []string{"mysql2 (requires default-libmysqlclient-dev)", "pg (requires libpq-dev)"},
+		},
+		{
+			name:     "headers present",
+			lockFile: "GEM\n  specs:\n    pg (1.5.4)\n",
+			headers:  []string{"postgresql/libpq-fe.h"},
+		},
+		{
+			name:     "precompiled variant",
+			lockFile: "GEM\n  specs:\n    nokogiri (1.15.4)\n      racc (~> 1.4)\n    nokogiri (1.15.4-x86_64-linux)\n      racc (~> 1.4)\n",
+		},
+		{
+			name:     "only other platforms precompiled",
+			lockFile: "GEM\n  specs:\n    nokogiri (1.15.4)\n    nokogiri (1.15.4-arm64-darwin)\n",
+			want:     []string{"nokogiri (requires libxml2-dev)"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			include := t.TempDir()
+			defer func(dirs []string) { includeDirs = dirs }(includeDirs)
+			includeDirs = []string{include}
+			for _, h := range tc.headers {
+				path := filepath.Join(include, h)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("creating directory for %s: %v", h, err)
+				}
+				if err := os.WriteFile(path, nil, 0644); err != nil {
+					t.Fatalf("writing %s: %v", h, err)
+				}
+			}
+			lockFile := filepath.Join(t.TempDir(), "Gemfile.lock")
+			if err := os.WriteFile(lockFile, []byte(tc.lockFile), 0644); err != nil {
+				t.Fatalf("writing %s: %v", lockFile, err)
+			}
+
+			got, err := missingNativeDependencies(gcp.NewContext(), lockFile)
+			if err != nil {
+				t.Fatalf("missingNativeDependencies() got error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("missingNativeDependencies() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestInstallFailureHint(t *testing.T) {
+	testCases := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{
+			name:   "known gem",
+			output: "Gem::Ext::BuildError: ERROR: Failed to build gem native extension.\nAn error occurred while installing pg (1.5.4), and Bundler cannot continue.",
+			want:   "Gem pg requires the system package libpq-dev to compile its native extension.",
+		},
+		{
+			name:   "unknown gem",
+			output: "ERROR: Failed to build gem native extension.\nAn error occurred while installing charlock_holmes (0.7.7), and Bundler cannot continue.",
+			want:   "The native extension of gem charlock_holmes may require a system package that is missing from the build image.",
+		},
+		{
+			name:   "other failure",
+			output: "Could not reach host index.rubygems.org.\nAn error occurred while installing rack (3.0.8), and Bundler cannot continue.",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := installFailureHint(tc.output); got != tc.want {
+				t.Errorf("installFailureHint() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
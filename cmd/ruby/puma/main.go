@@ -0,0 +1,158 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Implements ruby/puma buildpack.
+// The puma buildpack sets the entrypoint of applications served by puma if a custom entrypoint is
+// not specified. At launch, it sizes the puma workers and threads to the container's CPU and memory.
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/cgroup"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/ruby"
+)
+
+const (
+	pumaGem         = "puma"
+	tuningLayerName = "puma-tuning"
+	// tuningExecD is the name of the exec.d helper that tunes puma at launch time.
+	tuningExecD = "puma-tuning"
+	// pumaConfig is the config file that puma loads by default, generated by Rails.
+	pumaConfig = "config/puma.rb"
+
+	// webConcurrencyEnv is read by puma and the Rails puma config as the number of workers.
+	webConcurrencyEnv = "WEB_CONCURRENCY"
+	// maxThreadsEnv is read by the Rails puma config as the number of threads per worker.
+	maxThreadsEnv = "RAILS_MAX_THREADS"
+
+	// workerMemory is the memory budgeted for each puma worker process.
+	workerMemory = 512 * 1024 * 1024
+	// workerThreads is the number of threads per puma worker, the Rails default.
+	workerThreads = 5
+)
+
+// lockFiles are the lock files of the bundle, in order of precedence.
+var lockFiles = []string{"Gemfile.lock", "gems.locked"}
+
+func main() {
+	gcp.Main(detectFn, buildFn, gcp.WithExecD(tuningExecD, tunePuma))
+}
+
+func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
+	for _, name := range lockFiles {
+		exists, err := ctx.FileExists(name)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+		gems, err := ruby.ParseLockedGems(filepath.Join(ctx.ApplicationRoot(), name))
+		if err != nil {
+			return nil, gcp.UserErrorf("parsing %s: %v", name, err)
+		}
+		if _, ok := gems[pumaGem]; ok {
+			return gcp.OptIn(fmt.Sprintf("found %s in %s", pumaGem, name)), nil
+		}
+		return gcp.OptOut(fmt.Sprintf("%s not found in %s", pumaGem, name)), nil
+	}
+	return gcp.OptOut("no Gemfile.lock or gems.locked found"), nil
+}
+
+func buildFn(ctx *gcp.Context) error {
+	l, err := ctx.Layer(tuningLayerName, gcp.LaunchLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", tuningLayerName, err)
+	}
+	ctx.Logf("puma workers and threads will be sized to the container at launch; set %s or %s to override.", webConcurrencyEnv, maxThreadsEnv)
+	if err := ctx.AddExecD(l, tuningExecD); err != nil {
+		return err
+	}
+
+	custom, err := hasCustomEntrypoint(ctx)
+	if err != nil {
+		return err
+	}
+	if custom {
+		return nil
+	}
+	hasConfig, err := ctx.FileExists(pumaConfig)
+	if err != nil {
+		return err
+	}
+	entrypoint := pumaEntrypoint(hasConfig)
+	ctx.Logf("No entrypoint specified, using %q.", entrypoint)
+	ctx.AddProcess(gcp.WebProcess, []string{entrypoint}, gcp.AsDefaultProcess())
+	return nil
+}
+
+// hasCustomEntrypoint returns true if the entrypoint is set with GOOGLE_ENTRYPOINT or a Procfile.
+func hasCustomEntrypoint(ctx *gcp.Context) (bool, error) {
+	if os.Getenv(env.Entrypoint) != "" {
+		return true, nil
+	}
+	return ctx.FileExists("Procfile")
+}
+
+// pumaEntrypoint returns the shell command that serves the application with puma. The puma config
+// of the application sets the port, workers and threads, otherwise they are set from the launch
+// environment.
+func pumaEntrypoint(hasConfig bool) string {
+	if hasConfig {
+		return "bundle exec puma --config " + pumaConfig
+	}
+	return fmt.Sprintf("bundle exec puma --bind tcp://0.0.0.0:${PORT:-8080} --workers ${%[1]s:-1} --threads ${%[2]s:-%[3]d}:${%[2]s:-%[3]d}", webConcurrencyEnv, maxThreadsEnv, workerThreads)
+}
+
+// tunePuma is an exec.d helper that sizes puma to the container's CPU and memory.
+func tunePuma() (map[string]string, error) {
+	memory, err := cgroup.MemoryLimit()
+	if err != nil {
+		return nil, err
+	}
+	return pumaEnv(cgroup.CPUs(), memory), nil
+}
+
+// pumaEnv returns the launch environment for puma, leaving user-provided settings intact.
+func pumaEnv(cpus int, memory int64) map[string]string {
+	workers, threads := pumaSettings(cpus, memory)
+	e := map[string]string{}
+	if os.Getenv(webConcurrencyEnv) == "" {
+		e[webConcurrencyEnv] = strconv.Itoa(workers)
+	}
+	if os.Getenv(maxThreadsEnv) == "" {
+		e[maxThreadsEnv] = strconv.Itoa(threads)
+	}
+	return e
+}
+
+// pumaSettings returns the number of puma workers and threads per worker for a container with the
+// given number of CPUs and memory in bytes. Ruby threads do not run Ruby code in parallel, so
+// there is one worker per CPU, limited by the memory budgeted for each worker.
+func pumaSettings(cpus int, memory int64) (workers, threads int) {
+	workers = cpus
+	if byMemory := int(memory / workerMemory); byMemory < workers {
+		workers = byMemory
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	return workers, workerThreads
+}
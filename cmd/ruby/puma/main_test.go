@@ -0,0 +1,192 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"testing"
+
+	buildpacktest "github.com/GoogleCloudPlatform/buildpacks/internal/buildpacktest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		want  int
+	}{
+		{
+			name: "puma in Gemfile.lock",
+			files: map[string]string{
+				"Gemfile":      "",
+				"Gemfile.lock": "GEM\n  remote: https://rubygems.org/\n  specs:\n    nio4r (2.5.9)\n    puma (6.4.0)\n      nio4r (~> 2.0)\n",
+			},
+			want: 0,
+		},
+		{
+			name: "puma in gems.locked",
+			files: map[string]string{
+				"gems.rb":     "",
+				"gems.locked": "GEM\n  remote: https://rubygems.org/\n  specs:\n    puma (6.4.0)\n",
+			},
+			want: 0,
+		},
+		{
+			name: "puma as a dependency only",
+			files: map[string]string{
+				"Gemfile":      "",
+				"Gemfile.lock": "GEM\n  remote: https://rubygems.org/\n  specs:\n    rack (3.0.8)\n    webrick (1.8.1)\n      puma\n",
+			},
+			want: 100,
+		},
+		{
+			name: "no lock file",
+			files: map[string]string{
+				"Gemfile": "gem 'puma'",
+			},
+			want: 100,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buildpacktest.TestDetect(t, detectFn, tc.name, tc.files, []string{}, tc.want)
+		})
+	}
+}
+
+func TestPumaEntrypoint(t *testing.T) {
+	testCases := []struct {
+		name      string
+		hasConfig bool
+		want      string
+	}{
+		{
+			name:      "with config",
+			hasConfig: true,
+			want:      "bundle exec puma --config config/puma.rb",
+		},
+		{
+			name: "without config",
+			want: "bundle exec puma --bind tcp://0.0.0.0:${PORT:-8080} --workers ${WEB_CONCURRENCY:-1} --threads ${RAILS_MAX_THREADS:-5}:${RAILS_MAX_THREADS:-5}",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := pumaEntrypoint(tc.hasConfig); got != tc.want {
+				t.Errorf("pumaEntrypoint(%t) = %q, want %q", tc.hasConfig, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPumaSettings(t *testing.T) {
+	const mb = 1024 * 1024
+	testCases := []struct {
+		name        string
+		cpus        int
+		memory      int64
+		wantWorkers int
+		wantThreads int
+	}{
+		{
+			name:        "one cpu",
+			cpus:        1,
+			memory:      2048 * mb,
+			wantWorkers: 1,
+			wantThreads: 5,
+		},
+		{
+			name:        "four cpus",
+			cpus:        4,
+			memory:      4096 * mb,
+			wantWorkers: 4,
+			wantThreads: 5,
+		},
+		{
+			name:        "limited by memory",
+			cpus:        4,
+			memory:      1024 * mb,
+			wantWorkers: 2,
+			wantThreads: 5,
+		},
+		{
+			name:        "less memory than a worker",
+			cpus:        2,
+			memory:      256 * mb,
+			wantWorkers: 1,
+			wantThreads: 5,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			workers, threads := pumaSettings(tc.cpus, tc.memory)
+			if workers != tc.wantWorkers || threads != tc.wantThreads {
+				t.Errorf("pumaSettings(%d, %d) = %d, %d, want %d, %d", tc.cpus, tc.memory, workers, threads, tc.wantWorkers, tc.wantThreads)
+			}
+		})
+	}
+}
+
+func TestPumaEnv(t *testing.T) {
+	const mb = 1024 * 1024
+	testCases := []struct {
+		name string
+		env  map[string]string
+		want map[string]string
+	}{
+		{
+			name: "defaults",
+			want: map[string]string{
+				"WEB_CONCURRENCY":   "2",
+				"RAILS_MAX_THREADS": "5",
+			},
+		},
+		{
+			name: "user workers",
+			env:  map[string]string{"WEB_CONCURRENCY": "3"},
+			want: map[string]string{
+				"RAILS_MAX_THREADS": "5",
+			},
+		},
+		{
+			name: "user threads",
+			env:  map[string]string{"RAILS_MAX_THREADS": "10"},
+			want: map[string]string{
+				"WEB_CONCURRENCY": "2",
+			},
+		},
+		{
+			name: "user workers and threads",
+			env:  map[string]string{"WEB_CONCURRENCY": "3", "RAILS_MAX_THREADS": "10"},
+			want: map[string]string{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, k := range []string{"WEB_CONCURRENCY", "RAILS_MAX_THREADS"} {
+				t.Setenv(k, "")
+				os.Unsetenv(k)
+			}
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			got := pumaEnv(2, 2048*mb)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("pumaEnv() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
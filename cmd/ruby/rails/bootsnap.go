@@ -0,0 +1,82 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/ruby"
+)
+
+const (
+	bootsnapLayer = "bootsnap"
+	bootsnapGem   = "bootsnap"
+	// bootsnapCacheDirEnv is the env var that sets the cache directory of bootsnap/setup, which
+	// defaults to tmp/cache in the application.
+	bootsnapCacheDirEnv = "BOOTSNAP_CACHE_DIR"
+)
+
+// lockFiles are the lock files of the bundle, in order of precedence.
+var lockFiles = []string{"Gemfile.lock", "gems.locked"}
+
+// usesBootsnap returns true if bootsnap is part of the bundle of the application.
+func usesBootsnap(ctx *gcp.Context) (bool, error) {
+	for _, name := range lockFiles {
+		exists, err := ctx.FileExists(name)
+		if err != nil {
+			return false, err
+		}
+		if !exists {
+			continue
+		}
+		gems, err := ruby.ParseLockedGems(name)
+		if err != nil {
+			return false, gcp.UserErrorf("parsing %s: %v", name, err)
+		}
+		_, ok := gems[bootsnapGem]
+		return ok, nil
+	}
+	return false, nil
+}
+
+// precompileBootsnap compiles the application code and the gems into the bootsnap cache at build
+// time, so that the application does not compile them when it boots. The cache is stored in a
+// launch layer, which bootsnap/setup uses through BOOTSNAP_CACHE_DIR. Bootsnap only compiles the
+// files that changed since the previous build.
+func precompileBootsnap(ctx *gcp.Context) error {
+	l, err := ctx.Layer(bootsnapLayer, gcp.CacheLayer, gcp.LaunchLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", bootsnapLayer, err)
+	}
+	command := []string{"bundle", "exec", "bootsnap", "precompile", "--cache-dir", l.Path, "--gemfile"}
+	for _, dir := range []string{"app", "lib"} {
+		exists, err := ctx.FileExists(dir)
+		if err != nil {
+			return err
+		}
+		if exists {
+			command = append(command, dir+"/")
+		}
+	}
+	ctx.Logf("Precompiling the application code and gems with bootsnap.")
+	if _, err := ctx.Exec(command, gcp.WithEnv("RAILS_ENV=production", "LANG=C.utf8"), gcp.WithUserAttribution); err != nil {
+		// The cache only reduces the boot time, the application boots without it.
+		ctx.Warnf("Bootsnap precompilation failed, the application is compiled when it boots: %v", err)
+		return nil
+	}
+	l.LaunchEnvironment.Default(bootsnapCacheDirEnv, l.Path)
+	return nil
+}
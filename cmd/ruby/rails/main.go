@@ -13,19 +13,53 @@
 // limitations under the License.
 
 // Implements ruby/rails buildpack.
-// The rails buildpack precompiles assets using Rails.
+// The rails buildpack precompiles assets using Rails, and the application code and gems using
+// bootsnap.
 package main
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/cache"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/nodejs"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/ruby"
 )
 
 const (
-	yarnLayer = "yarn"
+	yarnLayer   = "yarn"
+	assetsLayer = "assets"
+	assetsKey   = "assets_hash"
+	// placeholderSecretKeyBase is used as the secret key base during asset precompilation when
+	// neither the secret key base nor the master key is available at build time, since Rails
+	// requires one to boot in production. Assets do not depend on its value.
+	placeholderSecretKeyBase = "placeholder-secret-key-base-for-asset-precompilation"
+)
+
+var (
+	// assetSources are the files and directories that the precompiled assets depend on.
+	assetSources = []string{
+		filepath.Join("app", "assets"),
+		filepath.Join("app", "javascript"),
+		filepath.Join("lib", "assets"),
+		filepath.Join("vendor", "assets"),
+		"config",
+		"Gemfile.lock",
+		"gems.locked",
+		"package.json",
+		"yarn.lock",
+		"package-lock.json",
+		"postcss.config.js",
+		"tailwind.config.js",
+	}
+	// assetOutputs are the directories that asset precompilation writes to, for Sprockets and
+	// Webpacker.
+	assetOutputs = []string{
+		filepath.Join("public", "assets"),
+		filepath.Join("public", "packs"),
+	}
 )
 
 func main() {
@@ -44,13 +78,60 @@ func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
 	if err != nil {
 		return nil, err
 	}
-	if !needsPrecompile {
-		return gcp.OptOut("Rails assets do not need precompilation"), nil
+	if needsPrecompile {
+		return gcp.OptIn("found Rails assets to precompile"), nil
+	}
+	bootsnap, err := usesBootsnap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if bootsnap {
+		return gcp.OptIn("found bootsnap in the bundle"), nil
 	}
-	return gcp.OptIn("found Rails assets to precompile"), nil
+	return gcp.OptOut("Rails assets do not need precompilation and bootsnap is not used"), nil
 }
 
 func buildFn(ctx *gcp.Context) error {
+	needsPrecompile, err := ruby.NeedsRailsAssetPrecompile(ctx)
+	if err != nil {
+		return err
+	}
+	if needsPrecompile {
+		if err := precompileAssets(ctx); err != nil {
+			return err
+		}
+	}
+	bootsnap, err := usesBootsnap(ctx)
+	if err != nil {
+		return err
+	}
+	if bootsnap {
+		return precompileBootsnap(ctx)
+	}
+	return nil
+}
+
+// precompileAssets precompiles the assets, or restores them from the assets layer if their sources
+// have not changed since the previous build.
+func precompileAssets(ctx *gcp.Context) error {
+	l, err := ctx.Layer(assetsLayer, gcp.CacheLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", assetsLayer, err)
+	}
+	key, err := assetsHash(ctx)
+	if err != nil {
+		return fmt.Errorf("computing assets hash: %w", err)
+	}
+	if ctx.GetMetadata(l, assetsKey) == key {
+		ctx.CacheHit(assetsLayer)
+		ctx.Logf("Rails assets have not changed, restoring the precompiled assets.")
+		return copyOutputs(ctx, l.Path, ctx.ApplicationRoot())
+	}
+	ctx.CacheMiss(assetsLayer)
+	if err := ctx.ClearLayer(l); err != nil {
+		return fmt.Errorf("clearing layer %q: %w", l.Name, err)
+	}
+
 	ctx.Logf("Running Rails asset precompilation")
 
 	// Install Yarn as it is needed for asset precompilation.
@@ -60,7 +141,7 @@ func buildFn(ctx *gcp.Context) error {
 
 	// It is common practise in Ruby asset precompilation to ignore non-zero exit codes.
 	result, err := ctx.Exec([]string{"bundle", "exec", "ruby", "bin/rails", "assets:precompile"},
-		gcp.WithEnv("RAILS_ENV=production", "MALLOC_ARENA_MAX=2", "RAILS_LOG_TO_STDOUT=true", "LANG=C.utf8"), gcp.WithUserAttribution)
+		gcp.WithEnv(precompileEnv()...), gcp.WithUserAttribution)
 	if err != nil && result != nil && result.ExitCode != 0 {
 		ctx.Logf("WARNING: Asset precompilation returned non-zero exit code %d. Ignoring.", result.ExitCode)
 		return nil
@@ -72,6 +153,72 @@ func buildFn(ctx *gcp.Context) error {
 		return gcp.InternalErrorf("asset precompilation failed: %v", err)
 	}
 
+	if err := copyOutputs(ctx, ctx.ApplicationRoot(), l.Path); err != nil {
+		return err
+	}
+	ctx.SetMetadata(l, assetsKey, key)
+	return nil
+}
+
+// precompileEnv returns the environment of the asset precompilation. Rails requires a secret key
+// base to boot in production, a placeholder is used when none is available at build time.
+func precompileEnv() []string {
+	env := []string{"RAILS_ENV=production", "MALLOC_ARENA_MAX=2", "RAILS_LOG_TO_STDOUT=true", "LANG=C.utf8"}
+	if os.Getenv("SECRET_KEY_BASE") == "" && os.Getenv("RAILS_MASTER_KEY") == "" {
+		// SECRET_KEY_BASE_DUMMY is supported since Rails 7.1, earlier versions read SECRET_KEY_BASE.
+		env = append(env, "SECRET_KEY_BASE_DUMMY=1", "SECRET_KEY_BASE="+placeholderSecretKeyBase)
+	}
+	return env
+}
+
+// assetsHash returns the hash of the sources of the assets.
+func assetsHash(ctx *gcp.Context) (string, error) {
+	var files []string
+	for _, source := range assetSources {
+		path := filepath.Join(ctx.ApplicationRoot(), source)
+		exists, err := ctx.FileExists(path)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			continue
+		}
+		err = filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			files = append(files, p)
+			return nil
+		})
+		if err != nil {
+			return "", gcp.InternalErrorf("walking %s: %v", path, err)
+		}
+	}
+	// The paths are part of the hash, so that renamed files change the hash.
+	return cache.Hash(ctx, cache.WithStrings(files...), cache.WithFiles(files...))
+}
+
+// copyOutputs copies the directories of the precompiled assets from one root directory to another.
+func copyOutputs(ctx *gcp.Context, from, to string) error {
+	for _, dir := range assetOutputs {
+		exists, err := ctx.FileExists(from, dir)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+		dest := filepath.Join(to, dir)
+		if err := ctx.RemoveAll(dest); err != nil {
+			return err
+		}
+		if err := ctx.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return err
+		}
+		if _, err := ctx.Exec([]string{"cp", "--archive", filepath.Join(from, dir), dest}, gcp.WithUserTimingAttribution); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
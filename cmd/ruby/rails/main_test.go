@@ -15,9 +15,12 @@
 package main
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	buildpacktest "github.com/GoogleCloudPlatform/buildpacks/internal/buildpacktest"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
 )
 
 func TestDetect(t *testing.T) {
@@ -68,6 +71,22 @@ func TestDetect(t *testing.T) {
 			},
 			want: 100,
 		},
+		{
+			name: "bootsnap without assets",
+			files: map[string]string{
+				"bin/rails":    "",
+				"Gemfile.lock": "GEM\n  specs:\n    bootsnap (1.17.0)\n      msgpack (~> 1.2)\n",
+			},
+			want: 0,
+		},
+		{
+			name: "bootsnap dependency only",
+			files: map[string]string{
+				"bin/rails":    "",
+				"Gemfile.lock": "GEM\n  specs:\n    rails-bootsnap-helper (1.0.0)\n      bootsnap (~> 1.4)\n",
+			},
+			want: 100,
+		},
 		{
 			name:  "no bin/rails",
 			files: map[string]string{},
@@ -80,3 +99,84 @@ func TestDetect(t *testing.T) {
 		})
 	}
 }
+
+func TestAssetsHash(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("creating directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+	}
+	hash := func() string {
+		t.Helper()
+		h, err := assetsHash(gcp.NewContext(gcp.WithApplicationRoot(root)))
+		if err != nil {
+			t.Fatalf("assetsHash() got error: %v", err)
+		}
+		return h
+	}
+
+	write("app/assets/stylesheets/application.css", "body {}")
+	write("app/models/user.rb", "class User; end")
+	initial := hash()
+
+	write("app/models/user.rb", "class User < ApplicationRecord; end")
+	if got := hash(); got != initial {
+		t.Errorf("assetsHash() changed after a change to application code")
+	}
+
+	write("app/assets/stylesheets/application.css", "body { margin: 0; }")
+	changed := hash()
+	if changed == initial {
+		t.Errorf("assetsHash() did not change after a change to an asset")
+	}
+
+	if err := os.Rename(filepath.Join(root, "app/assets/stylesheets/application.css"), filepath.Join(root, "app/assets/stylesheets/main.css")); err != nil {
+		t.Fatalf("renaming asset: %v", err)
+	}
+	if got := hash(); got == changed {
+		t.Errorf("assetsHash() did not change after an asset was renamed")
+	}
+}
+
+func TestPrecompileEnv(t *testing.T) {
+	testCases := []struct {
+		name            string
+		env             map[string]string
+		wantPlaceholder bool
+	}{
+		{
+			name:            "no secrets",
+			wantPlaceholder: true,
+		},
+		{
+			name: "secret key base",
+			env:  map[string]string{"SECRET_KEY_BASE": "abc"},
+		},
+		{
+			name: "master key",
+			env:  map[string]string{"RAILS_MASTER_KEY": "abc"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("SECRET_KEY_BASE", tc.env["SECRET_KEY_BASE"])
+			t.Setenv("RAILS_MASTER_KEY", tc.env["RAILS_MASTER_KEY"])
+
+			got := false
+			for _, e := range precompileEnv() {
+				if e == "SECRET_KEY_BASE_DUMMY=1" {
+					got = true
+				}
+			}
+			if got != tc.wantPlaceholder {
+				t.Errorf("precompileEnv() sets SECRET_KEY_BASE_DUMMY = %t, want %t", got, tc.wantPlaceholder)
+			}
+		})
+	}
+}
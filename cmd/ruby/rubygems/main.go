@@ -66,6 +66,10 @@ func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
 }
 
 func buildFn(ctx *gcp.Context) error {
+	if ruby.IsJRuby() {
+		ctx.Logf("JRuby includes RubyGems and Bundler, skipping installation.")
+		return nil
+	}
 	layer, err := ctx.Layer(layerName, gcp.BuildLayer, gcp.CacheLayer, gcp.LaunchLayerUnlessSkipRuntimeLaunch)
 	if err != nil {
 		return fmt.Errorf("creating layer: %w", err)
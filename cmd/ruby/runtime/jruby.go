@@ -0,0 +1,58 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/runtime"
+	"github.com/buildpacks/libcnb"
+)
+
+const (
+	jdkLayer = "jdk"
+	// jrubyJDKVersion is the feature version of the JDK that runs JRuby, the latest LTS version
+	// supported by JRuby 9.4.
+	jrubyJDKVersion = "17"
+)
+
+// installJRuby installs the JDK into its own layer and the given version of JRuby into the ruby
+// layer. JAVA_HOME is set at build and launch time, and JRuby is also run by the ruby command, so
+// that Bundler and the entrypoint of the application work unchanged.
+func installJRuby(ctx *gcp.Context, rl *libcnb.Layer, version string) error {
+	jl, err := ctx.Layer(jdkLayer, gcp.BuildLayer, gcp.CacheLayer, gcp.LaunchLayerUnlessSkipRuntimeLaunch)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", jdkLayer, err)
+	}
+	if _, err := runtime.InstallTarballIfNotCached(ctx, runtime.OpenJDK, jrubyJDKVersion, jl); err != nil {
+		return err
+	}
+	jl.SharedEnvironment.Override("JAVA_HOME", jl.Path)
+
+	if _, err := runtime.InstallJRubyIfNotCached(ctx, rl, version); err != nil {
+		return err
+	}
+	ruby := filepath.Join(rl.Path, "bin", "ruby")
+	exists, err := ctx.FileExists(ruby)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ctx.Symlink("jruby", ruby)
+	}
+	return nil
+}
@@ -39,7 +39,7 @@ func getRailsNodeVersion(ctx *gcp.Context) string {
 }
 
 func main() {
-	gcp.Main(detectFn, buildFn)
+	gcp.Main(detectFn, buildFn, gcp.WithFunctionSourceDir())
 }
 
 func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
@@ -89,20 +89,33 @@ func buildFn(ctx *gcp.Context) error {
 		rl.BuildEnvironment.Override(nodejs.EnvNodeVersion, railsNodeVersion)
 	}
 
-	_, err = runtime.InstallTarballIfNotCached(ctx, runtime.Ruby, version, rl)
+	jrubyVersion, err := ruby.DetectJRubyVersion(ctx)
 	if err != nil {
-		return err
+		return fmt.Errorf("determining JRuby version: %w", err)
 	}
+	if jrubyVersion != "" {
+		if err := installJRuby(ctx, rl, jrubyVersion); err != nil {
+			return err
+		}
+		// Store the Ruby language version of JRuby for subsequent buildpacks that depend on it.
+		rl.BuildEnvironment.Override(ruby.RubyVersionKey, version)
+		rl.BuildEnvironment.Override(ruby.RubyEngineKey, ruby.JRuby)
+	} else {
+		_, err = runtime.InstallTarballIfNotCached(ctx, runtime.Ruby, version, rl)
+		if err != nil {
+			return err
+		}
 
-	versionInstalled, _ := runtime.ResolveVersion(runtime.Ruby, version, runtime.OSForStack(ctx.StackID()))
-	// Store the installed Ruby version for subsequent buildpacks (like RubyGems) that depend on it.
-	rl.BuildEnvironment.Override(ruby.RubyVersionKey, versionInstalled)
+		versionInstalled, _ := runtime.ResolveVersion(runtime.Ruby, version, runtime.OSForStack(ctx.StackID()))
+		// Store the installed Ruby version for subsequent buildpacks (like RubyGems) that depend on it.
+		rl.BuildEnvironment.Override(ruby.RubyVersionKey, versionInstalled)
 
-	ctx.Exec([]string{"ldd", filepath.Join(rl.Path, "lib/ruby/3.1.0/x86_64-linux/psych.so")})
+		ctx.Exec([]string{"ldd", filepath.Join(rl.Path, "lib/ruby/3.1.0/x86_64-linux/psych.so")})
+	}
 
 	// For GAE and GCF, install RubyGems and Bundler in the same layer to maintain compatibility
-	// with existing builder images.
-	if env.IsGAE() || env.IsGCF() {
+	// with existing builder images. JRuby includes its own RubyGems and Bundler.
+	if (env.IsGAE() || env.IsGCF()) && jrubyVersion == "" {
 		err = runtime.PinGemAndBundlerVersion(ctx, version, rl)
 		if err != nil {
 			return fmt.Errorf("updating rubygems and bundler: %w", err)
@@ -0,0 +1,335 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// The ecosystems of the collected packages, which are the top-level directories of the layer.
+const (
+	ecosystemNPM   = "npm"
+	ecosystemPyPI  = "pypi"
+	ecosystemGo    = "go"
+	ecosystemMaven = "maven"
+)
+
+// licenseFileRegexp matches the names of license and notice files, e.g. LICENSE, LICENSE.md,
+// LICENCE-MIT, COPYING or NOTICE.txt.
+var licenseFileRegexp = regexp.MustCompile(`(?i)^(licen[cs]e|copying|notice)([.-].*)?$`)
+
+// pkgLicense is the license information of an installed dependency.
+type pkgLicense struct {
+	Ecosystem string   `json:"ecosystem"`
+	Name      string   `json:"name"`
+	Version   string   `json:"version"`
+	Licenses  []string `json:"licenses,omitempty"`
+	// Files are the paths of the copied license files, relative to the layer.
+	Files []string `json:"files,omitempty"`
+	// sources are the absolute paths of the license files of the installed package.
+	sources []string
+	// dir is the directory that sources are relative to.
+	dir string
+}
+
+// licenseFiles returns the license files at the top level of dir.
+func licenseFiles(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, e := range entries {
+		if e.Type().IsRegular() && licenseFileRegexp.MatchString(e.Name()) {
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	return files
+}
+
+// nodeModules returns the packages installed in a node_modules directory, including scoped and
+// nested packages. Symlinked packages, e.g. of workspaces, are part of the application and skipped.
+func nodeModules(dir string) ([]pkgLicense, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var pkgs []pkgLicense
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		if strings.HasPrefix(e.Name(), "@") {
+			scoped, err := nodeModules(path)
+			if err != nil {
+				return nil, err
+			}
+			pkgs = append(pkgs, scoped...)
+			continue
+		}
+		if p, ok := nodePackage(path); ok {
+			pkgs = append(pkgs, p)
+		}
+		nested, err := nodeModules(filepath.Join(path, "node_modules"))
+		if err != nil {
+			return nil, err
+		}
+		pkgs = append(pkgs, nested...)
+	}
+	return pkgs, nil
+}
+
+// nodePackage returns the license information of the package in dir, from its package.json.
+func nodePackage(dir string) (pkgLicense, bool) {
+	content, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return pkgLicense{}, false
+	}
+	var pj struct {
+		Name     string          `json:"name"`
+		Version  string          `json:"version"`
+		License  json.RawMessage `json:"license"`
+		Licenses []struct {
+			Type string `json:"type"`
+		} `json:"licenses"`
+	}
+	if err := json.Unmarshal(content, &pj); err != nil || pj.Name == "" {
+		return pkgLicense{}, false
+	}
+	p := pkgLicense{Ecosystem: ecosystemNPM, Name: pj.Name, Version: pj.Version, dir: dir, sources: licenseFiles(dir)}
+	// The license is an SPDX expression, or a {"type": ...} object in old packages.
+	var license string
+	var legacy struct {
+		Type string `json:"type"`
+	}
+	if json.Unmarshal(pj.License, &license) == nil && license != "" {
+		p.Licenses = append(p.Licenses, license)
+	} else if json.Unmarshal(pj.License, &legacy) == nil && legacy.Type != "" {
+		p.Licenses = append(p.Licenses, legacy.Type)
+	}
+	for _, l := range pj.Licenses {
+		if l.Type != "" {
+			p.Licenses = append(p.Licenses, l.Type)
+		}
+	}
+	return p, true
+}
+
+// pythonPackages returns the packages installed in a site-packages directory, from their
+// .dist-info metadata.
+func pythonPackages(dir string) ([]pkgLicense, error) {
+	infos, err := filepath.Glob(filepath.Join(dir, "*.dist-info"))
+	if err != nil {
+		return nil, err
+	}
+	var pkgs []pkgLicense
+	for _, info := range infos {
+		content, err := os.ReadFile(filepath.Join(info, "METADATA"))
+		if err != nil {
+			continue
+		}
+		p := pkgLicense{Ecosystem: ecosystemPyPI, dir: info, sources: licenseFiles(info)}
+		var licenseFileNames []string
+		s := bufio.NewScanner(bytes.NewReader(content))
+		// The headers end at the first empty line, before the description.
+		for s.Scan() && s.Text() != "" {
+			parts := strings.SplitN(s.Text(), ":", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			key, value := parts[0], strings.TrimSpace(parts[1])
+			switch key {
+			case "Name":
+				p.Name = value
+			case "Version":
+				p.Version = value
+			case "License", "License-Expression":
+				// Some packages put the full license text in License, keep its first line only.
+				if value != "" && value != "UNKNOWN" {
+					p.Licenses = append(p.Licenses, value)
+				}
+			case "Classifier":
+				const prefix = "License :: "
+				if strings.HasPrefix(value, prefix) {
+					segments := strings.Split(value, " :: ")
+					p.Licenses = append(p.Licenses, segments[len(segments)-1])
+				}
+			case "License-File":
+				licenseFileNames = append(licenseFileNames, value)
+			}
+		}
+		if p.Name == "" {
+			continue
+		}
+		// License files are in the licenses directory since PEP 639, and at the top level before.
+		for _, name := range licenseFileNames {
+			for _, path := range []string{filepath.Join(info, "licenses", name), filepath.Join(info, name)} {
+				if fi, err := os.Stat(path); err == nil && fi.Mode().IsRegular() {
+					p.sources = appendUnique(p.sources, path)
+					break
+				}
+			}
+		}
+		pkgs = append(pkgs, p)
+	}
+	return pkgs, nil
+}
+
+// goModules returns the modules in a module cache, i.e. GOMODCACHE.
+func goModules(dir string) ([]pkgLicense, error) {
+	var pkgs []pkgLicense
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "cache" {
+			return filepath.SkipDir
+		}
+		// Modules are extracted to <escaped module path>@<version>.
+		i := strings.LastIndex(rel, "@")
+		if i < 0 {
+			return nil
+		}
+		pkgs = append(pkgs, pkgLicense{
+			Ecosystem: ecosystemGo,
+			Name:      unescapeModulePath(filepath.ToSlash(rel[:i])),
+			Version:   rel[i+1:],
+			dir:       path,
+			sources:   licenseFiles(path),
+		})
+		return filepath.SkipDir
+	})
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return pkgs, err
+}
+
+// unescapeModulePath reverses the case-encoding of the module cache, in which upper-case letters
+// are replaced by an exclamation mark followed by the lower-case letter.
+func unescapeModulePath(path string) string {
+	var b strings.Builder
+	bang := false
+	for _, r := range path {
+		switch {
+		case r == '!':
+			bang = true
+			continue
+		case bang:
+			b.WriteString(strings.ToUpper(string(r)))
+		default:
+			b.WriteRune(r)
+		}
+		bang = false
+	}
+	return b.String()
+}
+
+// mavenArtifacts returns the artifacts with a jar in a local Maven repository, with the licenses
+// declared in their poms. Maven artifacts do not have license files outside of their jars.
+func mavenArtifacts(dir string) ([]pkgLicense, error) {
+	var pkgs []pkgLicense
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".pom") {
+			return err
+		}
+		if _, err := os.Stat(strings.TrimSuffix(path, ".pom") + ".jar"); err != nil {
+			// Parent and BOM poms are not part of the application.
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		var pom struct {
+			GroupID    string `xml:"groupId"`
+			ArtifactID string `xml:"artifactId"`
+			Version    string `xml:"version"`
+			Parent     struct {
+				GroupID string `xml:"groupId"`
+				Version string `xml:"version"`
+			} `xml:"parent"`
+			Licenses []struct {
+				Name string `xml:"name"`
+				URL  string `xml:"url"`
+			} `xml:"licenses>license"`
+		}
+		if err := xml.Unmarshal(content, &pom); err != nil || pom.ArtifactID == "" {
+			return nil
+		}
+		p := pkgLicense{Ecosystem: ecosystemMaven, Version: pom.Version, dir: filepath.Dir(path)}
+		group := pom.GroupID
+		if group == "" {
+			group = pom.Parent.GroupID
+		}
+		if p.Version == "" {
+			p.Version = pom.Parent.Version
+		}
+		p.Name = group + ":" + pom.ArtifactID
+		for _, l := range pom.Licenses {
+			if name := strings.TrimSpace(l.Name); name != "" {
+				p.Licenses = append(p.Licenses, name)
+			} else if url := strings.TrimSpace(l.URL); url != "" {
+				p.Licenses = append(p.Licenses, url)
+			}
+		}
+		pkgs = append(pkgs, p)
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return pkgs, err
+}
+
+// safePath returns a relative path for a package name and version that stays within its parent,
+// e.g. @types/node@18.0.0 or github.com/google/uuid@v1.3.0.
+func safePath(name, version string) string {
+	segments := strings.Split(filepath.ToSlash(name+"@"+version), "/")
+	for i, s := range segments {
+		if s == "" || s == "." || s == ".." {
+			segments[i] = "_"
+		}
+	}
+	return filepath.Join(segments...)
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("creating directory of %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+	}
+}
+
+func TestCollect(t *testing.T) {
+	testCases := []struct {
+		name   string
+		app    map[string]string
+		layers map[string]string
+		want   []pkgLicense
+	}{
+		{
+			name: "no dependencies",
+			app:  map[string]string{"index.js": ""},
+		},
+		{
+			name: "node_modules",
+			app: map[string]string{
+				"node_modules/express/package.json":                    `{"name": "express", "version": "4.18.2", "license": "MIT"}`,
+				"node_modules/express/LICENSE":                         "MIT License",
+				"node_modules/express/node_modules/debug/package.json": `{"name": "debug", "version": "2.6.9", "license": {"type": "MIT"}}`,
+				"node_modules/express/node_modules/debug/LICENSE.md":   "MIT License",
+				"node_modules/@types/node/package.json":                `{"name": "@types/node", "version": "18.0.0", "licenses": [{"type": "MIT"}]}`,
+				"node_modules/@types/node/README.md":                   "",
+				"node_modules/.bin/express":                            "",
+				"node_modules/.package-lock.json":                      "{}",
+				"node_modules/invalid/package.json":                    "{",
+			},
+			want: []pkgLicense{
+				{Ecosystem: "npm", Name: "@types/node", Version: "18.0.0", Licenses: []string{"MIT"}},
+				{Ecosystem: "npm", Name: "debug", Version: "2.6.9", Licenses: []string{"MIT"}, Files: []string{"npm/debug@2.6.9/LICENSE.md"}},
+				{Ecosystem: "npm", Name: "express", Version: "4.18.2", Licenses: []string{"MIT"}, Files: []string{"npm/express@4.18.2/LICENSE"}},
+			},
+		},
+		{
+			name: "site-packages",
+			layers: map[string]string{
+				"google.python.pip/pip/lib/python3.11/site-packages/flask-2.3.2.dist-info/METADATA":          "Metadata-Version: 2.1\nName: Flask\nVersion: 2.3.2\nClassifier: License :: OSI Approved :: BSD License\nLicense-File: LICENSE.rst\n\nLicense: not a header\n",
+				"google.python.pip/pip/lib/python3.11/site-packages/flask-2.3.2.dist-info/LICENSE.rst":       "BSD",
+				"google.python.pip/pip/lib/python3.11/site-packages/attrs-23.1.0.dist-info/METADATA":         "Name: attrs\nVersion: 23.1.0\nLicense-Expression: MIT\nLicense-File: LICENSE\n",
+				"google.python.pip/pip/lib/python3.11/site-packages/attrs-23.1.0.dist-info/licenses/LICENSE": "MIT",
+				"google.python.pip/pip/lib/python3.11/site-packages/unknown-1.0.dist-info/METADATA":          "Name: unknown\nVersion: 1.0\nLicense: UNKNOWN\n",
+				"google.python.runtime/python/lib/python3.11/site-packages/flask-2.3.2.dist-info/METADATA":   "Name: Flask\nVersion: 2.3.2\n",
+				"google.python.pip/pip/lib/python3.11/site-packages/flask/__init__.py":                       "",
+			},
+			want: []pkgLicense{
+				{Ecosystem: "pypi", Name: "Flask", Version: "2.3.2", Licenses: []string{"BSD License"}, Files: []string{"pypi/Flask@2.3.2/LICENSE.rst"}},
+				{Ecosystem: "pypi", Name: "attrs", Version: "23.1.0", Licenses: []string{"MIT"}, Files: []string{"pypi/attrs@23.1.0/licenses/LICENSE"}},
+				{Ecosystem: "pypi", Name: "unknown", Version: "1.0"},
+			},
+		},
+		{
+			name: "go modules",
+			layers: map[string]string{
+				"google.go.gomod/gopath/pkg/mod/github.com/!burnt!sushi/toml@v1.2.1/COPYING":   "MIT",
+				"google.go.gomod/gopath/pkg/mod/github.com/!burnt!sushi/toml@v1.2.1/decode.go": "",
+				"google.go.gomod/gopath/pkg/mod/github.com/google/uuid@v1.3.0/LICENSE":         "BSD",
+				"google.go.gomod/gopath/pkg/mod/github.com/google/uuid@v1.3.0/nested/LICENSE":  "BSD",
+				"google.go.gomod/gopath/pkg/mod/cache/download/github.com/google/uuid/@v/list": "v1.3.0",
+			},
+			want: []pkgLicense{
+				{Ecosystem: "go", Name: "github.com/BurntSushi/toml", Version: "v1.2.1", Files: []string{"go/github.com/BurntSushi/toml@v1.2.1/COPYING"}},
+				{Ecosystem: "go", Name: "github.com/google/uuid", Version: "v1.3.0", Files: []string{"go/github.com/google/uuid@v1.3.0/LICENSE"}},
+			},
+		},
+		{
+			name: "maven repository",
+			layers: map[string]string{
+				"google.java.maven/m2/repository/com/google/guava/guava/32.0.0/guava-32.0.0.pom": `<project><parent><groupId>com.google.guava</groupId><version>32.0.0</version></parent><artifactId>guava</artifactId>
+					<licenses><license><name>Apache License, Version 2.0</name></license></licenses></project>`,
+				"google.java.maven/m2/repository/com/google/guava/guava/32.0.0/guava-32.0.0.jar":               "",
+				"google.java.maven/m2/repository/org/example/lib/1.0/lib-1.0.pom":                              `<project><groupId>org.example</groupId><artifactId>lib</artifactId><version>1.0</version><licenses><license><url>https://example.com/license</url></license></licenses></project>`,
+				"google.java.maven/m2/repository/org/example/lib/1.0/lib-1.0.jar":                              "",
+				"google.java.maven/m2/repository/com/google/guava/guava-parent/32.0.0/guava-parent-32.0.0.pom": `<project><groupId>com.google.guava</groupId><artifactId>guava-parent</artifactId><version>32.0.0</version></project>`,
+			},
+			want: []pkgLicense{
+				{Ecosystem: "maven", Name: "com.google.guava:guava", Version: "32.0.0", Licenses: []string{"Apache License, Version 2.0"}},
+				{Ecosystem: "maven", Name: "org.example:lib", Version: "1.0", Licenses: []string{"https://example.com/license"}},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			app, layers := t.TempDir(), t.TempDir()
+			writeFiles(t, app, tc.app)
+			writeFiles(t, layers, tc.layers)
+			layer := filepath.Join(layers, "google.utils.licenses", licensesLayer)
+			if err := os.MkdirAll(layer, 0755); err != nil {
+				t.Fatalf("creating layer: %v", err)
+			}
+
+			pkgs, err := collect(app, layers)
+			if err != nil {
+				t.Fatalf("collect() got error: %v", err)
+			}
+			got, err := copyLicenses(gcp.NewContext(), pkgs, layer)
+			if err != nil {
+				t.Fatalf("copyLicenses() got error: %v", err)
+			}
+
+			want := &manifest{Packages: tc.want}
+			if want.Packages == nil {
+				want.Packages = []pkgLicense{}
+			}
+			if diff := cmp.Diff(want, got, cmpopts.IgnoreUnexported(pkgLicense{})); diff != "" {
+				t.Errorf("collected licenses mismatch (-want +got):\n%s", diff)
+			}
+			for _, p := range got.Packages {
+				for _, f := range p.Files {
+					if _, err := os.Stat(filepath.Join(layer, f)); err != nil {
+						t.Errorf("license file %s was not copied: %v", f, err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSafePath(t *testing.T) {
+	testCases := []struct {
+		name    string
+		version string
+		want    string
+	}{
+		{name: "express", version: "4.18.2", want: "express@4.18.2"},
+		{name: "@types/node", version: "18.0.0", want: "@types/node@18.0.0"},
+		{name: "../../etc", version: "1.0", want: "_/_/etc@1.0"},
+		{name: "/abs", version: "../x", want: "_/abs@../x"},
+	}
+	for _, tc := range testCases {
+		if got := safePath(tc.name, tc.version); got != tc.want {
+			t.Errorf("safePath(%q, %q) = %q, want %q", tc.name, tc.version, got, tc.want)
+		}
+	}
+}
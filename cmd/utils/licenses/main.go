@@ -0,0 +1,170 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Implements utils/licenses buildpack.
+// The licenses buildpack collects the license files of the dependencies installed by the previous
+// buildpacks into the licenses layer of the application image, with a licenses.json manifest.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+const (
+	licensesLayer = "licenses"
+	manifestFile  = "licenses.json"
+	// maxLicenseBytes is the size above which files are not copied, as they are unlikely to be
+	// license files.
+	maxLicenseBytes = 1 << 20
+)
+
+// manifest is the content of licenses.json.
+type manifest struct {
+	Packages []pkgLicense `json:"packages"`
+}
+
+func main() {
+	gcp.Main(detectFn, buildFn)
+}
+
+func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
+	return gcp.OptInAlways(), nil
+}
+
+func buildFn(ctx *gcp.Context) error {
+	l, err := ctx.Layer(licensesLayer, gcp.LaunchLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", licensesLayer, err)
+	}
+	// The layers of the previous buildpacks are in /layers/<buildpack ID>/<layer name>.
+	layersDir := filepath.Dir(filepath.Dir(l.Path))
+	pkgs, err := collect(ctx.ApplicationRoot(), layersDir)
+	if err != nil {
+		return err
+	}
+	m, err := copyLicenses(ctx, pkgs, l.Path)
+	if err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return gcp.InternalErrorf("marshalling %s: %v", manifestFile, err)
+	}
+	if err := ctx.WriteFile(filepath.Join(l.Path, manifestFile), content, 0644); err != nil {
+		return err
+	}
+	counts := map[string]int{}
+	for _, p := range m.Packages {
+		counts[p.Ecosystem]++
+	}
+	var summary []string
+	for _, e := range []string{ecosystemNPM, ecosystemPyPI, ecosystemGo, ecosystemMaven} {
+		if counts[e] > 0 {
+			summary = append(summary, fmt.Sprintf("%d %s", counts[e], e))
+		}
+	}
+	if len(summary) == 0 {
+		ctx.Logf("No dependencies with licenses found")
+		return nil
+	}
+	ctx.Logf("Collected the licenses of %d dependencies (%s) in %s", len(m.Packages), strings.Join(summary, ", "), filepath.Join(l.Path, manifestFile))
+	return nil
+}
+
+// collect returns the dependencies installed in the application and the layers, sorted by
+// ecosystem, name and version, with duplicates removed.
+func collect(appRoot, layersDir string) ([]pkgLicense, error) {
+	sources := []struct {
+		pattern string
+		find    func(string) ([]pkgLicense, error)
+	}{
+		{filepath.Join(appRoot, "node_modules"), nodeModules},
+		{filepath.Join(layersDir, "*", "*", "lib", "python*", "site-packages"), pythonPackages},
+		{filepath.Join(layersDir, "*", "*", "pkg", "mod"), goModules},
+		{filepath.Join(layersDir, "*", "*", "repository"), mavenArtifacts},
+	}
+	var pkgs []pkgLicense
+	for _, s := range sources {
+		dirs, err := filepath.Glob(s.pattern)
+		if err != nil {
+			return nil, gcp.InternalErrorf("finding %s: %v", s.pattern, err)
+		}
+		for _, dir := range dirs {
+			found, err := s.find(dir)
+			if err != nil {
+				return nil, gcp.InternalErrorf("collecting licenses in %s: %v", dir, err)
+			}
+			pkgs = append(pkgs, found...)
+		}
+	}
+	sort.SliceStable(pkgs, func(i, j int) bool {
+		a, b := pkgs[i], pkgs[j]
+		if a.Ecosystem != b.Ecosystem {
+			return a.Ecosystem < b.Ecosystem
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Version < b.Version
+	})
+	var unique []pkgLicense
+	for i, p := range pkgs {
+		if i > 0 && p.Ecosystem == pkgs[i-1].Ecosystem && p.Name == pkgs[i-1].Name && p.Version == pkgs[i-1].Version {
+			continue
+		}
+		unique = append(unique, p)
+	}
+	return unique, nil
+}
+
+// copyLicenses copies the license files of the dependencies to <layer>/<ecosystem>/<name>@<version>
+// and returns the manifest of the copies.
+func copyLicenses(ctx *gcp.Context, pkgs []pkgLicense, layer string) (*manifest, error) {
+	m := &manifest{Packages: []pkgLicense{}}
+	for _, p := range pkgs {
+		dest := filepath.Join(p.Ecosystem, safePath(p.Name, p.Version))
+		for _, src := range p.sources {
+			fi, err := os.Stat(src)
+			if err != nil || fi.Size() > maxLicenseBytes {
+				continue
+			}
+			rel, err := filepath.Rel(p.dir, src)
+			if err != nil || strings.HasPrefix(rel, "..") {
+				rel = filepath.Base(src)
+			}
+			content, err := os.ReadFile(src)
+			if err != nil {
+				ctx.Warnf("Skipping license file %s: %v", src, err)
+				continue
+			}
+			target := filepath.Join(dest, rel)
+			if err := ctx.MkdirAll(filepath.Join(layer, filepath.Dir(target)), 0755); err != nil {
+				return nil, err
+			}
+			if err := ctx.WriteFile(filepath.Join(layer, target), content, 0644); err != nil {
+				return nil, err
+			}
+			p.Files = append(p.Files, filepath.ToSlash(target))
+		}
+		m.Packages = append(m.Packages, p)
+	}
+	return m, nil
+}
@@ -0,0 +1,207 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Implements utils/provenance buildpack.
+// The provenance buildpack generates the SLSA provenance of the image: the builder, the source and
+// its digest, and the buildpacks of the build. It runs first so that it hashes the source before
+// other buildpacks modify it.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+const (
+	provenanceLayer = "provenance"
+	provenanceFile  = "provenance.json"
+	// provenanceLabel is the image label with the provenance, google.slsa-provenance.
+	provenanceLabel  = "SLSA_PROVENANCE"
+	defaultBuilderID = "https://github.com/GoogleCloudPlatform/buildpacks"
+	buildType        = "https://github.com/GoogleCloudPlatform/buildpacks/provenance/v1"
+)
+
+// provenance is a SLSA provenance v0.2 predicate, https://slsa.dev/provenance/v0.2. The subject
+// of the in-toto statement, the image digest, is only known once the image is exported, so it is
+// added when the provenance is attested.
+type provenance struct {
+	Builder struct {
+		ID string `json:"id"`
+	} `json:"builder"`
+	BuildType   string      `json:"buildType"`
+	Invocation  invocation  `json:"invocation"`
+	BuildConfig buildConfig `json:"buildConfig"`
+	Metadata    metadata    `json:"metadata"`
+	Materials   []material  `json:"materials"`
+}
+
+type invocation struct {
+	ConfigSource material `json:"configSource"`
+	// Environment lists the names of the GOOGLE_ variables of the build, but not their values,
+	// which may be secrets.
+	Environment struct {
+		Stack     string   `json:"stack,omitempty"`
+		Variables []string `json:"variables,omitempty"`
+	} `json:"environment"`
+}
+
+type buildConfig struct {
+	Buildpacks []buildpackRef `json:"buildpacks"`
+}
+
+type buildpackRef struct {
+	ID      string `json:"id" toml:"id"`
+	Version string `json:"version" toml:"version"`
+}
+
+type metadata struct {
+	BuildStartedOn string `json:"buildStartedOn"`
+	Completeness   struct {
+		Parameters  bool `json:"parameters"`
+		Environment bool `json:"environment"`
+		Materials   bool `json:"materials"`
+	} `json:"completeness"`
+	Reproducible bool `json:"reproducible"`
+}
+
+type material struct {
+	URI    string            `json:"uri,omitempty"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+func main() {
+	gcp.Main(detectFn, buildFn)
+}
+
+func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
+	return gcp.OptInAlways(), nil
+}
+
+func buildFn(ctx *gcp.Context) error {
+	l, err := ctx.Layer(provenanceLayer, gcp.LaunchLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", provenanceLayer, err)
+	}
+	// The lifecycle writes the buildpacks of the build to /layers/group.toml.
+	groupFile := filepath.Join(filepath.Dir(filepath.Dir(l.Path)), "group.toml")
+	p, err := newProvenance(ctx, groupFile, time.Now())
+	if err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return gcp.InternalErrorf("marshalling provenance: %v", err)
+	}
+	if err := ctx.WriteFile(filepath.Join(l.Path, provenanceFile), content, 0644); err != nil {
+		return err
+	}
+	compact, err := json.Marshal(p)
+	if err != nil {
+		return gcp.InternalErrorf("marshalling provenance: %v", err)
+	}
+	ctx.AddLabel(provenanceLabel, string(compact))
+	return nil
+}
+
+// newProvenance returns the provenance of the build of the application.
+func newProvenance(ctx *gcp.Context, groupFile string, started time.Time) (*provenance, error) {
+	p := &provenance{BuildType: buildType}
+	p.Builder.ID = os.Getenv(env.ProvenanceBuilderID)
+	if p.Builder.ID == "" {
+		p.Builder.ID = defaultBuilderID
+	}
+	p.Metadata.BuildStartedOn = started.UTC().Format(time.RFC3339)
+
+	source, err := sourceMaterial(ctx.ApplicationRoot())
+	if err != nil {
+		return nil, err
+	}
+	p.Invocation.ConfigSource = source
+	p.Materials = []material{source}
+	p.Invocation.Environment.Stack = ctx.StackID()
+	for _, e := range os.Environ() {
+		name := strings.SplitN(e, "=", 2)[0]
+		if strings.HasPrefix(name, "GOOGLE_") {
+			p.Invocation.Environment.Variables = append(p.Invocation.Environment.Variables, name)
+		}
+	}
+	sort.Strings(p.Invocation.Environment.Variables)
+
+	p.BuildConfig.Buildpacks, err = buildpacks(ctx, groupFile)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// sourceMaterial returns the source of the application, with the digest of its files and the
+// commit of its git repository, or the source of GOOGLE_PROVENANCE_SOURCE_URI and
+// GOOGLE_PROVENANCE_SOURCE_DIGEST.
+func sourceMaterial(appRoot string) (material, error) {
+	hash, err := dirHash(appRoot)
+	if err != nil {
+		return material{}, gcp.InternalErrorf("hashing the source of the application: %v", err)
+	}
+	m := material{Digest: map[string]string{"dirHash": hash}}
+	uri, commit := gitSource(appRoot)
+	if commit != "" {
+		m.Digest["sha1"] = commit
+	}
+	m.URI = uri
+	if v := os.Getenv(env.ProvenanceSourceURI); v != "" {
+		if m.URI = scrubURL(strings.TrimPrefix(v, "git+")); m.URI == "" {
+			return material{}, gcp.UserErrorf("invalid %s, must be a URL", env.ProvenanceSourceURI)
+		}
+		if strings.HasPrefix(v, "git+") {
+			m.URI = "git+" + m.URI
+		}
+	}
+	if v := os.Getenv(env.ProvenanceSourceDigest); v != "" {
+		parts := strings.SplitN(v, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return material{}, gcp.UserErrorf("invalid %s %q, must be <algorithm>:<value>", env.ProvenanceSourceDigest, v)
+		}
+		m.Digest[parts[0]] = parts[1]
+	}
+	return m, nil
+}
+
+// buildpacks returns the buildpacks of the group of the build, or this buildpack only if the
+// lifecycle did not write the group file.
+func buildpacks(ctx *gcp.Context, groupFile string) ([]buildpackRef, error) {
+	exists, err := ctx.FileExists(groupFile)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		ctx.Warnf("%s not found, the provenance only lists the %s buildpack", groupFile, ctx.BuildpackID())
+		return []buildpackRef{{ID: ctx.BuildpackID(), Version: ctx.BuildpackVersion()}}, nil
+	}
+	var group struct {
+		Group []buildpackRef `toml:"group"`
+	}
+	if _, err := toml.DecodeFile(groupFile, &group); err != nil {
+		return nil, gcp.InternalErrorf("decoding %s: %v", groupFile, err)
+	}
+	return group.Group, nil
+}
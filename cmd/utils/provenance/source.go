@@ -0,0 +1,178 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// commitRegexp matches git commit IDs.
+	commitRegexp = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	// scpURLRegexp matches scp-like git URLs with a user, e.g. git@github.com:org/repo.git.
+	scpURLRegexp = regexp.MustCompile(`^[^@/:]+@([^/:]+:.*)$`)
+)
+
+// dirHash returns the hash of the files of dir, except the .git directory, in the h1 format of
+// Go modules: the base64 SHA-256 of the sorted lines `<hex SHA-256 of the file>  <path>`, so that
+// it can be verified with golang.org/x/mod/sumdb/dirhash.
+func dirHash(dir string) (string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(files)
+	summary := sha256.New()
+	for _, f := range files {
+		if strings.Contains(f, "\n") {
+			return "", fmt.Errorf("file name %q contains a newline", f)
+		}
+		sum, err := fileSHA256(filepath.Join(dir, filepath.FromSlash(f)))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(summary, "%x  %s\n", sum, f)
+	}
+	return "h1:" + base64.StdEncoding.EncodeToString(summary.Sum(nil)), nil
+}
+
+func fileSHA256(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// gitSource returns the URI of the HEAD of the git repository in dir, in the SPDX download
+// location format of SLSA, e.g. git+https://github.com/org/repo@refs/heads/main, and its commit.
+// It returns empty strings if dir is not a git repository or HEAD has no commit.
+func gitSource(dir string) (string, string) {
+	gitDir := filepath.Join(dir, ".git")
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return "", ""
+	}
+	ref := ""
+	commit := strings.TrimSpace(string(head))
+	if strings.HasPrefix(commit, "ref: ") {
+		ref = strings.TrimPrefix(commit, "ref: ")
+		commit = resolveRef(gitDir, ref)
+	}
+	if !commitRegexp.MatchString(commit) {
+		return "", ""
+	}
+	remote := originURL(gitDir)
+	if remote == "" {
+		return "", commit
+	}
+	uri := "git+" + remote
+	if ref != "" {
+		uri += "@" + ref
+	}
+	return uri, commit
+}
+
+// resolveRef returns the commit of a ref, from its file or from packed-refs.
+func resolveRef(gitDir, ref string) string {
+	if content, err := os.ReadFile(filepath.Join(gitDir, filepath.FromSlash(ref))); err == nil {
+		return strings.TrimSpace(string(content))
+	}
+	content, err := os.ReadFile(filepath.Join(gitDir, "packed-refs"))
+	if err != nil {
+		return ""
+	}
+	s := bufio.NewScanner(bytes.NewReader(content))
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 2 && fields[1] == ref {
+			return fields[0]
+		}
+	}
+	return ""
+}
+
+// originURL returns the URL of the origin remote of the git config, without credentials.
+func originURL(gitDir string) string {
+	content, err := os.ReadFile(filepath.Join(gitDir, "config"))
+	if err != nil {
+		return ""
+	}
+	inOrigin := false
+	s := bufio.NewScanner(bytes.NewReader(content))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if strings.HasPrefix(line, "[") {
+			inOrigin = line == `[remote "origin"]`
+			continue
+		}
+		if !inOrigin {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) == 2 && strings.TrimSpace(parts[0]) == "url" {
+			return scrubURL(strings.TrimSpace(parts[1]))
+		}
+	}
+	return ""
+}
+
+// scrubURL returns the URL without its user information, which may contain credentials. URLs
+// that cannot be parsed are dropped rather than risk recording a secret.
+func scrubURL(raw string) string {
+	if m := scpURLRegexp.FindStringSubmatch(raw); m != nil {
+		return "ssh://" + strings.Replace(m[1], ":", "/", 1)
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" {
+		return ""
+	}
+	u.User = nil
+	return u.String()
+}
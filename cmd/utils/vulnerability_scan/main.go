@@ -0,0 +1,207 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Implements utils/vulnerability-scan buildpack.
+// The vulnerability-scan buildpack looks up the dependencies listed in the SBOMs of the previous
+// buildpacks in the OSV database of known vulnerabilities, and fails the build if any reaches the
+// severity threshold of GOOGLE_VULNERABILITY_SEVERITY_THRESHOLD.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/sbom"
+)
+
+const (
+	reportLayer      = "vulnerabilities"
+	reportFile       = "findings.json"
+	defaultThreshold = severityHigh
+)
+
+// finding is a known vulnerability of a dependency.
+type finding struct {
+	ID       string   `json:"id"`
+	Aliases  []string `json:"aliases,omitempty"`
+	Summary  string   `json:"summary,omitempty"`
+	Severity severity `json:"severity"`
+	Score    float64  `json:"score,omitempty"`
+	Package  string   `json:"package"`
+	Version  string   `json:"version,omitempty"`
+	PURL     string   `json:"purl"`
+}
+
+// report is the JSON report of the scan.
+type report struct {
+	Threshold severity  `json:"threshold"`
+	Scanned   int       `json:"scanned"`
+	Findings  []finding `json:"findings"`
+}
+
+func main() {
+	gcp.Main(detectFn, buildFn)
+}
+
+func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
+	scan, err := env.IsPresentAndTrue(env.VulnerabilityScan)
+	if err != nil {
+		return nil, gcp.UserErrorf("%v", err)
+	}
+	if !scan {
+		return gcp.OptOutEnvNotSet(env.VulnerabilityScan), nil
+	}
+	return gcp.OptInEnvSet(env.VulnerabilityScan), nil
+}
+
+func buildFn(ctx *gcp.Context) error {
+	threshold, err := severityThreshold()
+	if err != nil {
+		return err
+	}
+	l, err := ctx.Layer(reportLayer, gcp.LaunchLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", reportLayer, err)
+	}
+	// The SBOMs of the layers of the previous buildpacks are in /layers/<buildpack ID>.
+	components, err := sbomComponents(ctx, filepath.Dir(filepath.Dir(l.Path)))
+	if err != nil {
+		return err
+	}
+	if len(components) == 0 {
+		ctx.Logf("No dependencies with package URLs in the SBOMs of the build, skipping the vulnerability scan")
+		return nil
+	}
+	r, err := scan(components, threshold)
+	if err != nil {
+		return gcp.InternalErrorf("scanning dependencies for known vulnerabilities: %v", err)
+	}
+	content, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return gcp.InternalErrorf("marshalling vulnerability report: %v", err)
+	}
+	ctx.Logf("Vulnerability report:\n%s", content)
+	if err := ctx.WriteFile(filepath.Join(l.Path, reportFile), content, 0644); err != nil {
+		return err
+	}
+
+	var failing []string
+	for _, f := range r.Findings {
+		if f.Severity >= threshold {
+			failing = append(failing, fmt.Sprintf("%s (%s in %s %s)", f.ID, f.Severity, f.Package, f.Version))
+		}
+	}
+	if len(failing) > 0 {
+		return gcp.UserErrorf("found %d known vulnerabilities with severity %s or higher: %s; update the dependencies, or set %s to a higher severity", len(failing), threshold, strings.Join(failing, ", "), env.VulnerabilitySeverityThreshold)
+	}
+	ctx.Logf("Found %d known vulnerabilities in %d dependencies, none with severity %s or higher", len(r.Findings), r.Scanned, threshold)
+	return nil
+}
+
+// severityThreshold returns the severity of GOOGLE_VULNERABILITY_SEVERITY_THRESHOLD, HIGH if it is
+// not set.
+func severityThreshold() (severity, error) {
+	val := os.Getenv(env.VulnerabilitySeverityThreshold)
+	if val == "" {
+		return defaultThreshold, nil
+	}
+	s, ok := parseSeverity(val)
+	if !ok {
+		return severityUnknown, gcp.UserErrorf("invalid %s %q, must be one of LOW, MEDIUM, HIGH or CRITICAL", env.VulnerabilitySeverityThreshold, val)
+	}
+	return s, nil
+}
+
+// sbomComponents returns the components with a package URL of the CycloneDX SBOMs of the layers,
+// without duplicates.
+func sbomComponents(ctx *gcp.Context, layersDir string) ([]sbom.Component, error) {
+	paths, err := ctx.Glob(filepath.Join(layersDir, "*", "*.sbom.cdx.json"))
+	if err != nil {
+		return nil, err
+	}
+	var components []sbom.Component
+	seen := map[string]bool{}
+	for _, path := range paths {
+		content, err := ctx.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var bom sbom.BOM
+		if err := json.Unmarshal(content, &bom); err != nil {
+			return nil, gcp.InternalErrorf("unmarshalling SBOM %s: %v", path, err)
+		}
+		for _, c := range bom.Components {
+			if c.PURL == "" || seen[c.PURL] {
+				continue
+			}
+			seen[c.PURL] = true
+			components = append(components, c)
+		}
+	}
+	return components, nil
+}
+
+// scan returns the report of the known vulnerabilities of the components, sorted from the most
+// severe.
+func scan(components []sbom.Component, threshold severity) (*report, error) {
+	purls := make([]string, len(components))
+	for i, c := range components {
+		purls[i] = c.PURL
+	}
+	ids, err := queryVulnIDs(purls)
+	if err != nil {
+		return nil, err
+	}
+	r := &report{Threshold: threshold, Scanned: len(components), Findings: []finding{}}
+	vulns := map[string]*osvVuln{}
+	for i, c := range components {
+		for _, id := range ids[i] {
+			v, ok := vulns[id]
+			if !ok {
+				if v, err = getVuln(id); err != nil {
+					return nil, err
+				}
+				vulns[id] = v
+			}
+			s, score := v.rate()
+			r.Findings = append(r.Findings, finding{
+				ID:       id,
+				Aliases:  v.Aliases,
+				Summary:  v.Summary,
+				Severity: s,
+				Score:    score,
+				Package:  c.Name,
+				Version:  c.Version,
+				PURL:     c.PURL,
+			})
+		}
+	}
+	sort.SliceStable(r.Findings, func(i, j int) bool {
+		a, b := r.Findings[i], r.Findings[j]
+		if a.Severity != b.Severity {
+			return a.Severity > b.Severity
+		}
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		return a.PURL < b.PURL
+	})
+	return r, nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/buildpacks/internal/buildpacktest"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/sbom"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name string
+		env  []string
+		want int
+	}{
+		{
+			name: "scan enabled",
+			env:  []string{"GOOGLE_VULNERABILITY_SCAN=true"},
+			want: 0,
+		},
+		{
+			name: "scan disabled",
+			env:  []string{"GOOGLE_VULNERABILITY_SCAN=false"},
+			want: 100,
+		},
+		{
+			name: "scan not set",
+			want: 100,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buildpacktest.TestDetect(t, detectFn, tc.name, map[string]string{}, tc.env, tc.want)
+		})
+	}
+}
+
+func TestSeverityThreshold(t *testing.T) {
+	testCases := []struct {
+		value   string
+		want    severity
+		wantErr bool
+	}{
+		{value: "", want: severityHigh},
+		{value: "critical", want: severityCritical},
+		{value: "MEDIUM", want: severityMedium},
+		{value: "UNKNOWN", wantErr: true},
+		{value: "severe", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			t.Setenv(env.VulnerabilitySeverityThreshold, tc.value)
+			got, err := severityThreshold()
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("severityThreshold() got error: %v, want error: %v", err, tc.wantErr)
+			}
+			if !tc.wantErr && got != tc.want {
+				t.Errorf("severityThreshold() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// stubOSV serves the vulnerabilities of package URLs, and their details.
+func stubOSV(t *testing.T, affected map[string][]string, vulns map[string]string) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/querybatch" {
+			var req struct {
+				Queries []osvQuery `json:"queries"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("decoding query: %v", err)
+			}
+			var results []string
+			for _, q := range req.Queries {
+				var ids []string
+				for _, id := range affected[q.Package.PURL] {
+					ids = append(ids, `{"id": "`+id+`"}`)
+				}
+				results = append(results, `{"vulns": [`+strings.Join(ids, ",")+`]}`)
+			}
+			w.Write([]byte(`{"results": [` + strings.Join(results, ",") + `]}`))
+			return
+		}
+		v, ok := vulns[strings.TrimPrefix(r.URL.Path, "/vulns/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(v))
+	}))
+	t.Cleanup(server.Close)
+	orig := osvURL
+	osvURL = server.URL
+	t.Cleanup(func() { osvURL = orig })
+}
+
+func TestScan(t *testing.T) {
+	layers := t.TempDir()
+	boms := map[string][]sbom.Component{
+		"google.go.build/bin.sbom.cdx.json": {
+			{Type: "library", Name: "golang.org/x/net", Version: "v0.7.0", PURL: "pkg:golang/golang.org/x/net@v0.7.0"},
+			{Type: "library", Name: "github.com/google/uuid", Version: "v1.3.0", PURL: "pkg:golang/github.com/google/uuid@v1.3.0"},
+			{Type: "library", Name: "local", Version: "(devel)"},
+		},
+		"google.python.pip/pip.sbom.cdx.json": {
+			{Type: "library", Name: "flask", Version: "0.12", PURL: "pkg:pypi/flask@0.12"},
+			{Type: "library", Name: "golang.org/x/net", Version: "v0.7.0", PURL: "pkg:golang/golang.org/x/net@v0.7.0"},
+		},
+	}
+	for name, components := range boms {
+		content, err := json.Marshal(sbom.NewBOM(components))
+		if err != nil {
+			t.Fatalf("marshalling SBOM: %v", err)
+		}
+		path := filepath.Join(layers, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("creating %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatalf("writing %s: %v", path, err)
+		}
+	}
+	stubOSV(t, map[string][]string{
+		"pkg:golang/golang.org/x/net@v0.7.0": {"GO-2023-1571"},
+		"pkg:pypi/flask@0.12":                {"PYSEC-2018-66", "GHSA-562c-5r94-xh97"},
+	}, map[string]string{
+		"GO-2023-1571":        `{"id": "GO-2023-1571", "summary": "Denial of service in net/http", "aliases": ["CVE-2022-41723"], "severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H"}]}`,
+		"PYSEC-2018-66":       `{"id": "PYSEC-2018-66", "affected": [{"database_specific": {"severity": "LOW"}}]}`,
+		"GHSA-562c-5r94-xh97": `{"id": "GHSA-562c-5r94-xh97", "database_specific": {"severity": "MODERATE"}}`,
+	})
+
+	components, err := sbomComponents(gcp.NewContext(), layers)
+	if err != nil {
+		t.Fatalf("sbomComponents() got error: %v", err)
+	}
+	if len(components) != 3 {
+		t.Errorf("sbomComponents() returned %d components, want 3: %v", len(components), components)
+	}
+	got, err := scan(components, severityHigh)
+	if err != nil {
+		t.Fatalf("scan() got error: %v", err)
+	}
+
+	want := &report{
+		Threshold: severityHigh,
+		Scanned:   3,
+		Findings: []finding{
+			{ID: "GO-2023-1571", Aliases: []string{"CVE-2022-41723"}, Summary: "Denial of service in net/http", Severity: severityHigh, Score: 7.5, Package: "golang.org/x/net", Version: "v0.7.0", PURL: "pkg:golang/golang.org/x/net@v0.7.0"},
+			{ID: "GHSA-562c-5r94-xh97", Severity: severityMedium, Package: "flask", Version: "0.12", PURL: "pkg:pypi/flask@0.12"},
+			{ID: "PYSEC-2018-66", Severity: severityLow, Package: "flask", Version: "0.12", PURL: "pkg:pypi/flask@0.12"},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("scan() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net/url"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/fetch"
+)
+
+// osvBatchSize is the maximum number of queries of a batch query of the OSV API.
+const osvBatchSize = 1000
+
+// osvURL is the base URL of the OSV API, https://google.github.io/osv.dev/api/.
+var osvURL = "https://api.osv.dev/v1"
+
+type osvQuery struct {
+	Package struct {
+		PURL string `json:"purl"`
+	} `json:"package"`
+}
+
+type osvBatchResponse struct {
+	Results []struct {
+		Vulns []struct {
+			ID string `json:"id"`
+		} `json:"vulns"`
+	} `json:"results"`
+}
+
+type osvSeverity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+type osvDatabaseSpecific struct {
+	Severity string `json:"severity"`
+}
+
+// osvVuln is the part of an OSV vulnerability used to rate its severity.
+type osvVuln struct {
+	ID               string              `json:"id"`
+	Summary          string              `json:"summary"`
+	Aliases          []string            `json:"aliases"`
+	Severity         []osvSeverity       `json:"severity"`
+	DatabaseSpecific osvDatabaseSpecific `json:"database_specific"`
+	Affected         []struct {
+		Severity         []osvSeverity       `json:"severity"`
+		DatabaseSpecific osvDatabaseSpecific `json:"database_specific"`
+	} `json:"affected"`
+}
+
+// queryVulnIDs returns the IDs of the known vulnerabilities of each package URL.
+func queryVulnIDs(purls []string) ([][]string, error) {
+	var ids [][]string
+	for start := 0; start < len(purls); start += osvBatchSize {
+		end := start + osvBatchSize
+		if end > len(purls) {
+			end = len(purls)
+		}
+		var req struct {
+			Queries []osvQuery `json:"queries"`
+		}
+		for _, p := range purls[start:end] {
+			var q osvQuery
+			q.Package.PURL = p
+			req.Queries = append(req.Queries, q)
+		}
+		var resp osvBatchResponse
+		if err := fetch.PostJSON(osvURL+"/querybatch", req, &resp); err != nil {
+			return nil, err
+		}
+		for i := range purls[start:end] {
+			var vulnIDs []string
+			if i < len(resp.Results) {
+				for _, v := range resp.Results[i].Vulns {
+					vulnIDs = append(vulnIDs, v.ID)
+				}
+			}
+			ids = append(ids, vulnIDs)
+		}
+	}
+	return ids, nil
+}
+
+// getVuln returns the OSV vulnerability with the ID.
+func getVuln(id string) (*osvVuln, error) {
+	var v osvVuln
+	if err := fetch.JSON(osvURL+"/vulns/"+url.PathEscape(id), &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// rate returns the highest severity of the vulnerability, and its CVSS v3 base score if it has
+// one. The severity is rated from the CVSS v3 vectors of the vulnerability and of the affected
+// packages, or from the severity of the database, e.g. of GitHub advisories, without vector.
+func (v *osvVuln) rate() (severity, float64) {
+	severities := v.Severity
+	databaseSeverities := []string{v.DatabaseSpecific.Severity}
+	for _, a := range v.Affected {
+		severities = append(severities, a.Severity...)
+		databaseSeverities = append(databaseSeverities, a.DatabaseSpecific.Severity)
+	}
+	score := 0.0
+	for _, s := range severities {
+		if s.Type != "CVSS_V3" {
+			continue
+		}
+		if sc, err := cvss3BaseScore(s.Score); err == nil && sc > score {
+			score = sc
+		}
+	}
+	if score > 0 {
+		return scoreSeverity(score), score
+	}
+	rated := severityUnknown
+	for _, name := range databaseSeverities {
+		if s, ok := parseSeverity(name); ok && s > rated {
+			rated = s
+		}
+	}
+	return rated, 0
+}
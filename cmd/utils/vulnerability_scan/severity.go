@@ -0,0 +1,151 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// severity is the qualitative severity of a vulnerability, ordered from least to most severe.
+type severity int
+
+const (
+	severityUnknown severity = iota
+	severityLow
+	severityMedium
+	severityHigh
+	severityCritical
+)
+
+var severityNames = map[severity]string{
+	severityUnknown:  "UNKNOWN",
+	severityLow:      "LOW",
+	severityMedium:   "MEDIUM",
+	severityHigh:     "HIGH",
+	severityCritical: "CRITICAL",
+}
+
+func (s severity) String() string {
+	return severityNames[s]
+}
+
+// MarshalText marshals the severity as its name in the JSON report.
+func (s severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// parseSeverity parses a severity name, case-insensitively. MODERATE is the name of MEDIUM in
+// GitHub advisories.
+func parseSeverity(name string) (severity, bool) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if name == "MODERATE" {
+		return severityMedium, true
+	}
+	for s, n := range severityNames {
+		if n == name && s != severityUnknown {
+			return s, true
+		}
+	}
+	return severityUnknown, false
+}
+
+// scoreSeverity returns the CVSS qualitative severity rating of a score.
+func scoreSeverity(score float64) severity {
+	switch {
+	case score >= 9:
+		return severityCritical
+	case score >= 7:
+		return severityHigh
+	case score >= 4:
+		return severityMedium
+	case score > 0:
+		return severityLow
+	default:
+		return severityUnknown
+	}
+}
+
+// cvss3Weights are the weights of the values of the CVSS v3 base metrics. The weights of the
+// privileges required with a changed scope are in cvss3ChangedPR.
+var cvss3Weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"PR": {"N": 0.85, "L": 0.62, "H": 0.27},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+var cvss3ChangedPR = map[string]float64{"N": 0.85, "L": 0.68, "H": 0.5}
+
+// cvss3BaseScore returns the base score of a CVSS v3.0 or v3.1 vector, e.g.
+// CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H, as specified in
+// https://www.first.org/cvss/v3.1/specification-document#7-1-Base-Metrics-Equations.
+func cvss3BaseScore(vector string) (float64, error) {
+	parts := strings.Split(vector, "/")
+	if len(parts) == 0 || !strings.HasPrefix(parts[0], "CVSS:3.") {
+		return 0, fmt.Errorf("invalid CVSS v3 vector %q", vector)
+	}
+	metrics := map[string]string{}
+	for _, p := range parts[1:] {
+		kv := strings.SplitN(p, ":", 2)
+		if len(kv) != 2 {
+			return 0, fmt.Errorf("invalid metric %q in CVSS v3 vector %q", p, vector)
+		}
+		metrics[kv[0]] = kv[1]
+	}
+	changed := metrics["S"] == "C"
+	if !changed && metrics["S"] != "U" {
+		return 0, fmt.Errorf("invalid scope in CVSS v3 vector %q", vector)
+	}
+	w := map[string]float64{}
+	for m, values := range cvss3Weights {
+		v, ok := values[metrics[m]]
+		if !ok {
+			return 0, fmt.Errorf("invalid or missing %s in CVSS v3 vector %q", m, vector)
+		}
+		w[m] = v
+	}
+	if changed {
+		w["PR"] = cvss3ChangedPR[metrics["PR"]]
+	}
+
+	iss := 1 - (1-w["C"])*(1-w["I"])*(1-w["A"])
+	impact := 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, nil
+	}
+	exploitability := 8.22 * w["AV"] * w["AC"] * w["PR"] * w["UI"]
+	if changed {
+		return roundUp(math.Min(1.08*(impact+exploitability), 10)), nil
+	}
+	return roundUp(math.Min(impact+exploitability, 10)), nil
+}
+
+// roundUp returns the smallest number with one decimal that is equal to or higher than x, as
+// defined in appendix A of the CVSS v3.1 specification to avoid floating point errors.
+func roundUp(x float64) float64 {
+	i := int64(math.Round(x * 100000))
+	if i%10000 == 0 {
+		return float64(i) / 100000
+	}
+	return float64(i/10000+1) / 10
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestCVSS3BaseScore(t *testing.T) {
+	testCases := []struct {
+		vector  string
+		want    float64
+		wantErr bool
+	}{
+		{vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", want: 9.8},
+		{vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H", want: 10},
+		{vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H", want: 7.5},
+		{vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N", want: 6.1},
+		{vector: "CVSS:3.0/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N", want: 5.5},
+		{vector: "CVSS:3.1/AV:P/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N", want: 1.6},
+		{vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N", want: 0},
+		{vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/E:P", want: 9.8},
+		{vector: "CVSS:2.0/AV:N/AC:L/Au:N/C:P/I:P/A:P", wantErr: true},
+		{vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H", wantErr: true},
+		{vector: "CVSS:3.1/AV:X/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.vector, func(t *testing.T) {
+			got, err := cvss3BaseScore(tc.vector)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("cvss3BaseScore(%q) got error: %v, want error: %v", tc.vector, err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("cvss3BaseScore(%q) = %v, want %v", tc.vector, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		vuln      osvVuln
+		want      severity
+		wantScore float64
+	}{
+		{
+			name: "no severity",
+			want: severityUnknown,
+		},
+		{
+			name: "CVSS v3",
+			vuln: osvVuln{Severity: []osvSeverity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H"}}},
+			want: severityHigh, wantScore: 7.5,
+		},
+		{
+			name: "highest CVSS v3 of affected packages",
+			vuln: osvVuln{
+				Severity: []osvSeverity{{Type: "CVSS_V3", Score: "CVSS:3.0/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N"}},
+				Affected: []struct {
+					Severity         []osvSeverity       `json:"severity"`
+					DatabaseSpecific osvDatabaseSpecific `json:"database_specific"`
+				}{{Severity: []osvSeverity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}}}},
+			},
+			want: severityCritical, wantScore: 9.8,
+		},
+		{
+			name: "CVSS v3 over database severity",
+			vuln: osvVuln{
+				Severity:         []osvSeverity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N"}},
+				DatabaseSpecific: osvDatabaseSpecific{Severity: "HIGH"},
+			},
+			want: severityMedium, wantScore: 6.1,
+		},
+		{
+			name: "database severity",
+			vuln: osvVuln{DatabaseSpecific: osvDatabaseSpecific{Severity: "MODERATE"}},
+			want: severityMedium,
+		},
+		{
+			name: "unsupported CVSS version",
+			vuln: osvVuln{Severity: []osvSeverity{{Type: "CVSS_V4", Score: "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N"}}},
+			want: severityUnknown,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, gotScore := tc.vuln.rate()
+			if got != tc.want || gotScore != tc.wantScore {
+				t.Errorf("rate() = %v, %v, want %v, %v", got, gotScore, tc.want, tc.wantScore)
+			}
+		})
+	}
+}
@@ -36,6 +36,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	goruntime "runtime"
 	"strconv"
 	"strings"
 	"testing"
@@ -70,6 +71,9 @@ var (
 	cloudbuild          bool   // Use cloudbuild network; required for Cloud Build.
 	runtimeVersion      string // A runtime version which will be applied to tests that do not explicilty set a version.
 	runtimeName         string // The name of the runtime (aka the language name such as 'go' or 'dotnet'). Used to properly set GOOGLE_RUNTIME.
+	shard               string // The shard of the tests to run, N/M with N from 1 to M.
+	arch                string // The architecture of the stack images, e.g. amd64 or arm64.
+	resultsFile         string // Path of the file that the results of the tests are appended to.
 	specialChars        = regexp.MustCompile("[^a-zA-Z0-9]+")
 )
 
@@ -107,6 +111,9 @@ func DefineFlags() {
 	flag.BoolVar(&cloudbuild, "cloudbuild", false, "Use cloudbuild network; required for Cloud Build.")
 	flag.StringVar(&runtimeVersion, "runtime-version", "", "A default runtime version which will be applied to the tests that do not explicitly set a version.")
 	flag.StringVar(&runtimeName, "runtime-name", "", "The name of the runtime (aka the language name such as 'go' or 'dotnet'). Used to properly set GOOGLE_RUNTIME.")
+	flag.StringVar(&arch, "arch", goruntime.GOARCH, "The architecture of the builder and run images; the stack image tags of builder.toml are suffixed with it for architectures other than amd64.")
+	flag.StringVar(&resultsFile, "results-file", "", "Path of a file that the results of the application tests, such as build durations, cache hits and image sizes, are appended to as lines of JSON.")
+	flag.StringVar(&shard, "shard", "", "Run only the tests of shard N of M, given as N/M with N from 1 to M, to split the tests across workers.")
 
 }
 
@@ -280,7 +287,11 @@ func testAppWithCache(t *testing.T, src, image, builderName, runName string, env
 }
 
 func testApp(t *testing.T, src, image, builderName, runName string, env map[string]string, cacheEnabled bool, checks *StructureTest, cfg Test) {
-	buildApp(t, src, image, builderName, runName, env, cacheEnabled, cfg)
+	result := newTestResult(cfg, builderName, cacheEnabled)
+	defer result.record(t)
+	duration, logs := buildApp(t, src, image, builderName, runName, env, cacheEnabled, cfg)
+	result.setBuild(duration, logs)
+	result.setImageSize(t, image)
 	verifyBuildMetadata(t, image, cfg.MustUse, cfg.MustNotUse, cfg.BOM)
 	verifyStructure(t, image, builderName, cacheEnabled, checks)
 	invokeApp(t, cfg, image, cacheEnabled)
@@ -391,7 +402,7 @@ func invokeApp(t *testing.T, cfg Test, image string, cache bool) {
 	if cfg.MustRebuildOnChange != "" {
 		start = time.Now()
 		// Modify a source file in the running container.
-		if _, err := runOutput("docker", "exec", containerID, "sed", "-i", "s/PASS/UPDATED/", cfg.MustRebuildOnChange); err != nil {
+		if _, err := runContainerOutput("exec", containerID, "sed", "-i", "s/PASS/UPDATED/", cfg.MustRebuildOnChange); err != nil {
 			t.Fatalf("Unable to modify a source file in the running container %q: %v", containerID, err)
 		}
 
@@ -569,7 +580,7 @@ func runCombinedOutput(args ...string) (string, error) {
 // runDockerLogs returns the logs for a container, the lineLimit parameter
 // controls the maximum number of lines read from the log
 func runDockerLogs(containerID string, lineLimit int) (string, error) {
-	return runCombinedOutput("docker", "logs", "--tail", string(lineLimit), containerID)
+	return runCombinedOutput(containerCmd("logs", "--tail", strconv.Itoa(lineLimit), containerID)...)
 }
 
 // cleanUpImage attempts to delete an image from the Docker daemon.
@@ -579,7 +590,7 @@ func cleanUpImage(t *testing.T, name string) {
 	if keepArtifacts {
 		return
 	}
-	if _, err := runOutput("docker", "rmi", "-f", name); err != nil {
+	if _, err := runContainerOutput("rmi", "-f", name); err != nil {
 		t.Logf("Failed to clean up image: %v", err)
 	}
 }
@@ -610,12 +621,12 @@ func ProvisionImages(t *testing.T) (ImageContext, func()) {
 	if builderImage != "" {
 		t.Logf("Testing existing builder image: %s", builderImage)
 		if pullImages {
-			if _, err := runOutput("docker", "pull", builderImage); err != nil {
+			if _, err := runContainerOutput("pull", builderImage); err != nil {
 				t.Fatalf("Error pulling %s: %v", builderImage, err)
 			}
 		}
 		// Pack cache is based on builder name; retag with a unique name.
-		if _, err := runOutput("docker", "tag", builderImage, builderName); err != nil {
+		if _, err := runContainerOutput("tag", builderImage, builderName); err != nil {
 			t.Fatalf("Error tagging %s as %s: %v", builderImage, builderName, err)
 		}
 		runName, cleanUpRun, err := provisionRunImageFromBuilder(builderName)
@@ -649,6 +660,15 @@ func ProvisionImages(t *testing.T) (ImageContext, func()) {
 		}
 	}
 
+	if arch != "amd64" {
+		t.Logf("Using %s stack images", arch)
+		if c, err := updateStackImages(config, arch); err != nil {
+			t.Fatalf("Error updating stack images: %v", err)
+		} else {
+			config = c
+		}
+	}
+
 	builderConfig, err := readBuilderTOML(config)
 	if err != nil {
 		t.Fatalf("Error reading builder.toml: %v", err)
@@ -657,7 +677,7 @@ func ProvisionImages(t *testing.T) (ImageContext, func()) {
 	// The images are intentionally not cleaned up to prevent conflicts across different test targets.
 	if pullImages {
 		buildName := builderConfig.Stack.BuildImage
-		if _, err := runOutput("docker", "pull", buildName); err != nil {
+		if _, err := runContainerOutput("pull", buildName); err != nil {
 			t.Fatalf("Error pulling %s: %v", buildName, err)
 		}
 	}
@@ -669,6 +689,7 @@ func ProvisionImages(t *testing.T) (ImageContext, func()) {
 	// Pack command to create the builder.
 	args := strings.Fields(fmt.Sprintf("builder create %s --config %s --pull-policy never --verbose --no-color", builderName, config))
 	cmd := exec.Command(packBin, args...)
+	cmd.Env = containerEnv()
 
 	outFile, errFile, cleanup := outFiles(t, builderName, "pack", "create-builder")
 	defer cleanup()
@@ -702,7 +723,7 @@ func provisionRunImageFromTOML(builderConfig *builderTOML) (string, func(t *test
 		runName = runImageOverride
 	}
 	if pullImages {
-		if _, err := runOutput("docker", "pull", runName); err != nil {
+		if _, err := runContainerOutput("pull", runName); err != nil {
 			return "", nil, fmt.Errorf("pulling %q: %w", runName, err)
 		}
 	}
@@ -724,7 +745,7 @@ func provisionRunImageFromBuilder(builderName string) (string, func(t *testing.T
 		runName = runImageOverride
 	}
 	if pullImages {
-		if _, err := runOutput("docker", "pull", runName); err != nil {
+		if _, err := runContainerOutput("pull", runName); err != nil {
 			return "", nil, fmt.Errorf("pulling %q: %w", runName, err)
 		}
 	}
@@ -766,7 +787,7 @@ func provisionImageWithMatchingStackID(fromImage, stackID string) (string, func(
 }
 
 func getImageStackID(image string) (string, error) {
-	out, err := runOutput("docker", "inspect", `--format={{index .Config.Labels "io.buildpacks.stack.id"}}`, image)
+	out, err := runContainerOutput("inspect", `--format={{index .Config.Labels "io.buildpacks.stack.id"}}`, image)
 	if err != nil {
 		return "", fmt.Errorf("getting stack id from docker inspect: %w", err)
 	}
@@ -782,8 +803,10 @@ func newImageWithStackID(fromImage, stackID string) (string, error) {
 	return newImage, nil
 }
 
+// generateRandomImageName returns a unique image name, which does not conflict with the images of
+// other test runs, such as the other shards of the tests, that share the Docker daemon.
 func generateRandomImageName(baseName string) string {
-	rand := randString(10)
+	rand := xid.New().String()
 	if strings.Contains(baseName, ":") {
 		return fmt.Sprintf("%v_%v", baseName, rand)
 	}
@@ -817,6 +840,16 @@ func extractBuilder(t *testing.T, builderSource string) (string, func()) {
 
 // updateLifecycle rewrites the lifecycle field of the config to the given uri.
 func updateLifecycle(config, uri string) (string, error) {
+	return rewriteBuilderTOML(config, func(data map[string]interface{}) {
+		data["lifecycle"] = map[string]string{
+			"uri": uri,
+		}
+	})
+}
+
+// rewriteBuilderTOML writes a copy of the builder.toml with the given update applied next to it,
+// and returns the path of the copy.
+func rewriteBuilderTOML(config string, update func(data map[string]interface{})) (string, error) {
 	p, err := ioutil.ReadFile(config)
 	if err != nil {
 		return "", fmt.Errorf("reading %s: %v", config, err)
@@ -826,9 +859,7 @@ func updateLifecycle(config, uri string) (string, error) {
 		return "", fmt.Errorf("unmarshaling %s: %v", config, err)
 	}
 
-	data["lifecycle"] = map[string]string{
-		"uri": uri,
-	}
+	update(data)
 
 	f, err := ioutil.TempFile("", "builder-*.toml")
 	if err != nil {
@@ -850,7 +881,7 @@ func updateLifecycle(config, uri string) (string, error) {
 // runImageFromMetadata returns the run image name from the metadata of the given image.
 func runImageFromMetadata(image string) (string, error) {
 	format := "--format={{(index (index .Config.Labels) \"io.buildpacks.builder.metadata\")}}"
-	out, err := runOutput("docker", "inspect", image, format)
+	out, err := runContainerOutput("inspect", image, format)
 	if err != nil {
 		return "", fmt.Errorf("reading builder metadata: %v", err)
 	}
@@ -916,6 +947,7 @@ func buildCommand(srcDir, image, builderName, runName string, env map[string]str
 	if runName != "" {
 		args = append(args, "--run-image", runName)
 	}
+	args = append(args, containerRuntime().PackArgs()...)
 	if !cache {
 		args = append(args, "--clear-cache")
 	}
@@ -931,8 +963,9 @@ func buildCommand(srcDir, image, builderName, runName string, env map[string]str
 	return args
 }
 
-// buildApp builds an application image from source.
-func buildApp(t *testing.T, srcDir, image, builderName, runName string, env map[string]string, cache bool, cfg Test) {
+// buildApp builds an application image from source, and returns the duration and the logs of the
+// build.
+func buildApp(t *testing.T, srcDir, image, builderName, runName string, env map[string]string, cache bool, cfg Test) (time.Duration, string) {
 	t.Helper()
 
 	attempts := cfg.FlakyBuildAttempts
@@ -954,6 +987,7 @@ func buildApp(t *testing.T, srcDir, image, builderName, runName string, env map[
 
 		bcmd := buildCommand(srcDir, image, builderName, runName, env, cache)
 		cmd := exec.Command(bcmd[0], bcmd[1:]...)
+		cmd.Env = containerEnv()
 		cmd.Stdout = io.MultiWriter(outFile, &outb) // pack emits detect output to stdout.
 		cmd.Stderr = io.MultiWriter(errFile, &errb) // pack emits build output to stderr.
 
@@ -1002,7 +1036,9 @@ func buildApp(t *testing.T, srcDir, image, builderName, runName string, env map[
 		}
 	}
 
-	t.Logf("Successfully built application: %s (in %s)", image, time.Since(start))
+	duration := time.Since(start)
+	t.Logf("Successfully built application: %s (in %s)", image, duration)
+	return duration, errb.String()
 }
 
 // buildFailingApp attempts to build an app and ensures that it failues (non-zero exit code).
@@ -1081,6 +1117,7 @@ func verifyStructure(t *testing.T, image, builder string, cache bool, checks *St
 		args = append(args, "--config", configuration)
 	}
 	cmd := exec.Command(structureBin, args...)
+	cmd.Env = containerEnv()
 
 	outFile, errFile, cleanup := outFiles(t, builder, "container-structure-test", fmt.Sprintf("%s-cache-%t", image, cache))
 	defer cleanup()
@@ -1100,7 +1137,7 @@ func verifyBuildMetadata(t *testing.T, image string, mustUse, mustNotUse []strin
 	t.Helper()
 
 	start := time.Now()
-	out, err := runOutput("docker", "inspect", "--format={{index .Config.Labels \"io.buildpacks.build.metadata\"}}", image)
+	out, err := runContainerOutput("inspect", "--format={{index .Config.Labels \"io.buildpacks.build.metadata\"}}", image)
 	if err != nil {
 		t.Fatalf("Error reading build metadata: %v", err)
 	}
@@ -1148,7 +1185,7 @@ func startContainer(t *testing.T, image, entrypoint string, env []string, cache
 	t.Helper()
 
 	containerName := xid.New().String()
-	command := []string{"docker", "run", "--detach", fmt.Sprintf("--name=%s", containerName)}
+	command := containerCmd("run", "--detach", fmt.Sprintf("--name=%s", containerName))
 	for _, e := range env {
 		command = append(command, "--env", e)
 	}
@@ -1170,7 +1207,7 @@ func startContainer(t *testing.T, image, entrypoint string, env []string, cache
 
 	host, port := getHostAndPortForApp(t, id, containerName)
 	return id, host, port, func() {
-		if _, err := runOutput("docker", "stop", id); err != nil {
+		if _, err := runContainerOutput("stop", id); err != nil {
 			t.Logf("Failed to stop container: %v", err)
 		}
 		if t.Failed() {
@@ -1180,7 +1217,7 @@ func startContainer(t *testing.T, image, entrypoint string, env []string, cache
 		if keepArtifacts {
 			return
 		}
-		if _, err := runOutput("docker", "rm", "-f", id); err != nil {
+		if _, err := runContainerOutput("rm", "-f", id); err != nil {
 			t.Logf("Failed to clean up container: %v", err)
 		}
 	}
@@ -1221,7 +1258,7 @@ func getHostAndPortForApp(t *testing.T, containerID, containerName string) (stri
 	// our own port picker logic which would bring its own set of issues when run on
 	// glinux machines which have various services running on ports. Since we have not
 	// observed issues with the docker port picker, we are continuing to use it.
-	return "localhost", hostPort(t, containerID)
+	return containerRuntime().AppHost(), hostPort(t, containerID)
 }
 
 // hostPort returns the host port assigned to the exposed container port.
@@ -1229,7 +1266,7 @@ func hostPort(t *testing.T, id string) int {
 	t.Helper()
 
 	format := "--format={{(index (index .NetworkSettings.Ports \"8080/tcp\") 0).HostPort}}"
-	portstr, err := runOutput("docker", "inspect", id, format)
+	portstr, err := runContainerOutput("inspect", id, format)
 	if err != nil {
 		t.Fatalf("Error getting port: %v", err)
 	}
@@ -1303,7 +1340,7 @@ func cleanUpVolumes(t *testing.T, image string) {
 	}
 	prefix = "pack-cache-" + prefix
 
-	if _, err := runOutput("docker", "volume", "rm", "-f", prefix+".launch", prefix+".build"); err != nil {
+	if _, err := runContainerOutput("volume", "rm", "-f", prefix+".launch", prefix+".build"); err != nil {
 		t.Logf("Failed to clean up cache volumes: %v", err)
 	}
 }
@@ -1314,11 +1351,16 @@ func PullImages() bool {
 }
 
 // FilterTests returns a new slice with only tests that should be run. Tests are filtered out if
-// their VersionInclusionConstraint does not match the `-runtime-version` flag.
+// their VersionInclusionConstraint does not match the `-runtime-version` flag, or if they do not
+// belong to the shard of the `-shard` flag. No tests are run if the runtime does not support the
+// architecture of the `-arch` flag.
 func FilterTests(t *testing.T, imageCtx ImageContext, testCases []Test) []Test {
 	results := make([]Test, 0)
+	if !ShouldTestArch(t) {
+		return results
+	}
 	for _, tc := range testCases {
-		if ShouldTestVersion(t, tc.VersionInclusionConstraint) && ShouldTestStack(t, imageCtx.StackID, tc.SkipStacks) {
+		if ShouldTestVersion(t, tc.VersionInclusionConstraint) && ShouldTestStack(t, imageCtx.StackID, tc.SkipStacks) && ShouldTestShard(t, testName(tc.Name, tc.App)) {
 			results = append(results, tc)
 		}
 	}
@@ -1326,11 +1368,16 @@ func FilterTests(t *testing.T, imageCtx ImageContext, testCases []Test) []Test {
 }
 
 // FilterFailureTests returns a new slice with only tests that should be run. Tests are filtered out
-// if their VersionInclusionConstraint does not match the `-runtime-version` flag.
+// if their VersionInclusionConstraint does not match the `-runtime-version` flag, or if they do not
+// belong to the shard of the `-shard` flag. No tests are run if the runtime does not support the
+// architecture of the `-arch` flag.
 func FilterFailureTests(t *testing.T, testCases []FailureTest) []FailureTest {
 	results := make([]FailureTest, 0)
+	if !ShouldTestArch(t) {
+		return results
+	}
 	for _, tc := range testCases {
-		if ShouldTestVersion(t, tc.VersionInclusionConstraint) {
+		if ShouldTestVersion(t, tc.VersionInclusionConstraint) && ShouldTestShard(t, testName(tc.Name, tc.App)) {
 			results = append(results, tc)
 		}
 	}
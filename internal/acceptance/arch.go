@@ -0,0 +1,73 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acceptance
+
+import (
+	"strings"
+	"testing"
+)
+
+// multiArchRuntimes are the runtimes that install architecture-specific binaries on architectures
+// other than amd64. The other runtimes install amd64 binaries, so their tests only run on amd64.
+var multiArchRuntimes = map[string]bool{
+	"go": true,
+}
+
+// ShouldTestArch returns true if the runtime of the `-runtime-name` flag supports the architecture
+// of the `-arch` flag.
+func ShouldTestArch(t *testing.T) bool {
+	t.Helper()
+	if arch == "amd64" || multiArchRuntimes[runtimeName] {
+		return true
+	}
+	t.Logf("Skipping tests: runtime %q does not support %s", runtimeName, arch)
+	return false
+}
+
+// archImage returns the name of the stack image for the architecture. Stack images of
+// architectures other than amd64 are tagged with the architecture as a suffix, e.g.
+// gcr.io/buildpacks/gcp/run:v1-arm64. Images referenced by digest are already specific.
+func archImage(image, arch string) string {
+	if arch == "amd64" || image == "" || strings.Contains(image, "@") {
+		return image
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image + "-" + arch
+	}
+	return image + ":latest-" + arch
+}
+
+// updateStackImages writes a copy of the builder.toml with the stack images of the architecture,
+// and returns the path of the copy.
+func updateStackImages(config, arch string) (string, error) {
+	return rewriteBuilderTOML(config, func(data map[string]interface{}) {
+		stack, ok := data["stack"].(map[string]interface{})
+		if !ok {
+			return
+		}
+		for _, key := range []string{"build-image", "run-image"} {
+			if image, ok := stack[key].(string); ok {
+				stack[key] = archImage(image, arch)
+			}
+		}
+		if mirrors, ok := stack["run-image-mirrors"].([]interface{}); ok {
+			for i, m := range mirrors {
+				if image, ok := m.(string); ok {
+					mirrors[i] = archImage(image, arch)
+				}
+			}
+		}
+	})
+}
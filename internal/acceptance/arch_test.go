@@ -0,0 +1,70 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acceptance
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestArchImage(t *testing.T) {
+	testCases := []struct {
+		image string
+		arch  string
+		want  string
+	}{
+		{image: "gcr.io/buildpacks/gcp/run:v1", arch: "amd64", want: "gcr.io/buildpacks/gcp/run:v1"},
+		{image: "gcr.io/buildpacks/gcp/run:v1", arch: "arm64", want: "gcr.io/buildpacks/gcp/run:v1-arm64"},
+		{image: "gcr.io/buildpacks/gcp/run", arch: "arm64", want: "gcr.io/buildpacks/gcp/run:latest-arm64"},
+		{image: "localhost:5000/run", arch: "arm64", want: "localhost:5000/run:latest-arm64"},
+		{image: "gcr.io/buildpacks/gcp/run@sha256:abc", arch: "arm64", want: "gcr.io/buildpacks/gcp/run@sha256:abc"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.image+" "+tc.arch, func(t *testing.T) {
+			if got := archImage(tc.image, tc.arch); got != tc.want {
+				t.Errorf("archImage(%q, %q) = %q, want %q", tc.image, tc.arch, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUpdateStackImages(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "builder.toml")
+	content := `[stack]
+  id = "google"
+  build-image = "gcr.io/buildpacks/gcp/build:v1"
+  run-image = "gcr.io/buildpacks/gcp/run:v1"
+`
+	if err := os.WriteFile(config, []byte(content), 0644); err != nil {
+		t.Fatalf("writing builder.toml: %v", err)
+	}
+
+	updated, err := updateStackImages(config, "arm64")
+	if err != nil {
+		t.Fatalf("updateStackImages() got error: %v", err)
+	}
+	if filepath.Dir(updated) != dir {
+		t.Errorf("updateStackImages() = %q, want a file in %q", updated, dir)
+	}
+	got, err := readBuilderTOML(updated)
+	if err != nil {
+		t.Fatalf("reading %s: %v", updated, err)
+	}
+	if got.Stack.BuildImage != "gcr.io/buildpacks/gcp/build:v1-arm64" || got.Stack.RunImage != "gcr.io/buildpacks/gcp/run:v1-arm64" {
+		t.Errorf("updateStackImages() stack = %+v, want arm64 images", got.Stack)
+	}
+}
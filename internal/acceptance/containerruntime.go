@@ -0,0 +1,156 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acceptance
+
+import (
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/GoogleCloudPlatform/buildpacks/internal/checktools"
+)
+
+// ContainerRuntime is the container engine that pack builds the test images with and that runs
+// the test applications, selected with the ACCEPTANCE_CONTAINER_RUNTIME env var. Both local and
+// remote daemons are supported, as configured with the env vars of the runtime CLI.
+type ContainerRuntime interface {
+	// Name returns the name of the runtime CLI.
+	Name() string
+	// Command returns the command line of the runtime CLI with the given arguments.
+	Command(args ...string) []string
+	// Env returns the env vars that pack and container-structure-test need to use the runtime.
+	Env() []string
+	// PackArgs returns the arguments that `pack build` needs to use the runtime.
+	PackArgs() []string
+	// AppHost returns the host at which the published ports of the containers are reachable.
+	AppHost() string
+}
+
+var (
+	containersOnce sync.Once
+	containers     ContainerRuntime
+)
+
+// containerRuntime returns the container runtime of the tests.
+func containerRuntime() ContainerRuntime {
+	containersOnce.Do(func() {
+		name, err := checktools.ContainerRuntime()
+		if err != nil {
+			log.Fatalf("Selecting container runtime: %v", err)
+		}
+		switch name {
+		case "podman":
+			containers = newPodmanRuntime()
+		default:
+			containers = dockerRuntime{}
+		}
+		log.Printf("Using container runtime %s", containers.Name())
+	})
+	return containers
+}
+
+// containerCmd returns the command line of the container runtime CLI with the given arguments.
+func containerCmd(args ...string) []string {
+	return containerRuntime().Command(args...)
+}
+
+// runContainerOutput runs the container runtime CLI with the given arguments and returns its
+// stdout or an error.
+func runContainerOutput(args ...string) (string, error) {
+	return runOutput(containerCmd(args...)...)
+}
+
+// containerEnv returns the environment of the pack and container-structure-test commands.
+func containerEnv() []string {
+	return append(os.Environ(), containerRuntime().Env()...)
+}
+
+// dockerRuntime is the Docker daemon, local or remote with DOCKER_HOST.
+type dockerRuntime struct{}
+
+func (dockerRuntime) Name() string {
+	return "docker"
+}
+
+func (dockerRuntime) Command(args ...string) []string {
+	return append([]string{"docker"}, args...)
+}
+
+// Env returns nothing, pack and container-structure-test use DOCKER_HOST like the Docker CLI.
+func (dockerRuntime) Env() []string {
+	return nil
+}
+
+func (dockerRuntime) PackArgs() []string {
+	return nil
+}
+
+func (dockerRuntime) AppHost() string {
+	return remoteHost(os.Getenv("DOCKER_HOST"))
+}
+
+// podmanRuntime is Podman, local or remote with CONTAINER_HOST. Pack and
+// container-structure-test use its Docker-compatible API socket.
+type podmanRuntime struct {
+	socket string
+}
+
+func newPodmanRuntime() podmanRuntime {
+	if host := os.Getenv("DOCKER_HOST"); host != "" {
+		return podmanRuntime{socket: host}
+	}
+	path, err := runOutput("podman", "info", "--format", "{{.Host.RemoteSocket.Path}}")
+	if err != nil {
+		log.Fatalf("Finding the Podman API socket: %v", err)
+	}
+	if strings.HasPrefix(path, "/") {
+		path = "unix://" + path
+	}
+	return podmanRuntime{socket: path}
+}
+
+func (podmanRuntime) Name() string {
+	return "podman"
+}
+
+func (podmanRuntime) Command(args ...string) []string {
+	return append([]string{"podman"}, args...)
+}
+
+func (p podmanRuntime) Env() []string {
+	return []string{"DOCKER_HOST=" + p.socket}
+}
+
+// PackArgs makes the lifecycle containers use the Podman socket rather than the Docker socket,
+// which pack mounts by default.
+func (podmanRuntime) PackArgs() []string {
+	return []string{"--docker-host=inherit"}
+}
+
+func (podmanRuntime) AppHost() string {
+	return remoteHost(os.Getenv("CONTAINER_HOST"))
+}
+
+// remoteHost returns the host of a remote daemon address, such as tcp://10.0.0.2:2376 or
+// ssh://user@builder/run/podman/podman.sock, or localhost for a local daemon.
+func remoteHost(addr string) string {
+	u, err := url.Parse(addr)
+	if err != nil || u.Scheme == "unix" || u.Scheme == "npipe" || u.Hostname() == "" {
+		return "localhost"
+	}
+	return u.Hostname()
+}
@@ -0,0 +1,50 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acceptance
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRemoteHost(t *testing.T) {
+	testCases := []struct {
+		addr string
+		want string
+	}{
+		{addr: "", want: "localhost"},
+		{addr: "unix:///var/run/docker.sock", want: "localhost"},
+		{addr: "npipe:////./pipe/docker_engine", want: "localhost"},
+		{addr: "tcp://10.0.0.2:2376", want: "10.0.0.2"},
+		{addr: "ssh://user@builder/run/podman/podman.sock", want: "builder"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.addr, func(t *testing.T) {
+			if got := remoteHost(tc.addr); got != tc.want {
+				t.Errorf("remoteHost(%q) = %q, want %q", tc.addr, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPodmanRuntime(t *testing.T) {
+	p := podmanRuntime{socket: "unix:///run/user/1000/podman/podman.sock"}
+	if got, want := p.Command("inspect", "image"), []string{"podman", "inspect", "image"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Command() = %v, want %v", got, want)
+	}
+	if got, want := p.Env(), []string{"DOCKER_HOST=unix:///run/user/1000/podman/podman.sock"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Env() = %v, want %v", got, want)
+	}
+}
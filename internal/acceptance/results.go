@@ -0,0 +1,125 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acceptance
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	// resultsMu serializes the writes to the results file of the tests of a process.
+	resultsMu sync.Mutex
+	// cacheHitRegexp and cacheMissRegexp match the cache hits and misses logged by the buildpacks,
+	// e.g. `***** CACHE HIT: "go"`.
+	cacheHitRegexp  = regexp.MustCompile(regexp.QuoteMeta(cacheHitMessage) + ` "([^"]*)"`)
+	cacheMissRegexp = regexp.MustCompile(regexp.QuoteMeta(cacheMissMessage) + ` "([^"]*)"`)
+)
+
+// testResult is the result of building and running an application, written as a line of JSON to
+// the file of the `-results-file` flag to track performance across runs.
+type testResult struct {
+	Name           string    `json:"name"`
+	App            string    `json:"app"`
+	Builder        string    `json:"builder"`
+	RuntimeName    string    `json:"runtimeName,omitempty"`
+	RuntimeVersion string    `json:"runtimeVersion,omitempty"`
+	Arch           string    `json:"arch"`
+	Cache          bool      `json:"cache"`
+	Passed         bool      `json:"passed"`
+	Start          time.Time `json:"start"`
+	BuildSeconds   float64   `json:"buildSeconds"`
+	ImageBytes     int64     `json:"imageBytes,omitempty"`
+	CacheHits      []string  `json:"cacheHits,omitempty"`
+	CacheMisses    []string  `json:"cacheMisses,omitempty"`
+}
+
+// newTestResult returns the result of a test that starts now.
+func newTestResult(cfg Test, builderName string, cache bool) *testResult {
+	return &testResult{
+		Name:           testName(cfg.Name, cfg.App),
+		App:            cfg.App,
+		Builder:        builderName,
+		RuntimeName:    runtimeName,
+		RuntimeVersion: runtimeVersion,
+		Arch:           arch,
+		Cache:          cache,
+		Start:          time.Now(),
+	}
+}
+
+// setBuild records the duration and the cache hits and misses of the build of the application.
+func (r *testResult) setBuild(duration time.Duration, logs string) {
+	r.BuildSeconds = duration.Seconds()
+	r.CacheHits = submatches(cacheHitRegexp, logs)
+	r.CacheMisses = submatches(cacheMissRegexp, logs)
+}
+
+// setImageSize records the size of the application image.
+func (r *testResult) setImageSize(t *testing.T, image string) {
+	t.Helper()
+	out, err := runContainerOutput("image", "inspect", "--format={{.Size}}", image)
+	if err != nil {
+		t.Logf("Failed to get the size of image %s: %v", image, err)
+		return
+	}
+	size, err := strconv.ParseInt(out, 10, 64)
+	if err != nil {
+		t.Logf("Failed to parse the size %q of image %s: %v", out, image, err)
+		return
+	}
+	r.ImageBytes = size
+}
+
+// record appends the result, passed if the test did not fail, to the file of the `-results-file`
+// flag. It is deferred so that failed tests are recorded as well.
+func (r *testResult) record(t *testing.T) {
+	t.Helper()
+	if resultsFile == "" {
+		return
+	}
+	r.Passed = !t.Failed()
+	line, err := json.Marshal(r)
+	if err != nil {
+		t.Errorf("Marshalling test result: %v", err)
+		return
+	}
+	resultsMu.Lock()
+	defer resultsMu.Unlock()
+	f, err := os.OpenFile(resultsFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Errorf("Opening results file: %v", err)
+		return
+	}
+	defer f.Close()
+	// A single write keeps the lines of concurrent test processes, such as shards, intact.
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		t.Errorf("Writing results file: %v", err)
+	}
+}
+
+// submatches returns the first submatch of each match of the regexp in the text.
+func submatches(re *regexp.Regexp, text string) []string {
+	var result []string
+	for _, m := range re.FindAllStringSubmatch(text, -1) {
+		result = append(result, m[1])
+	}
+	return result
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acceptance
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResultSetBuild(t *testing.T) {
+	logs := `===> BUILD
+[builder] DEBUG: ***** CACHE HIT: "go"
+[builder] DEBUG: ***** CACHE MISS: "gopath"
+[builder] DEBUG: ***** CACHE HIT: "deps"
+`
+	r := &testResult{}
+	r.setBuild(1500*time.Millisecond, logs)
+
+	if r.BuildSeconds != 1.5 {
+		t.Errorf("BuildSeconds = %v, want 1.5", r.BuildSeconds)
+	}
+	if want := []string{"go", "deps"}; !reflect.DeepEqual(r.CacheHits, want) {
+		t.Errorf("CacheHits = %v, want %v", r.CacheHits, want)
+	}
+	if want := []string{"gopath"}; !reflect.DeepEqual(r.CacheMisses, want) {
+		t.Errorf("CacheMisses = %v, want %v", r.CacheMisses, want)
+	}
+}
+
+func TestResultRecord(t *testing.T) {
+	orig := resultsFile
+	t.Cleanup(func() { resultsFile = orig })
+	resultsFile = filepath.Join(t.TempDir(), "results.json")
+
+	for _, name := range []string{"first", "second"} {
+		r := &testResult{Name: name, App: "app", Builder: "builder", Arch: "amd64", BuildSeconds: 2}
+		r.record(t)
+	}
+
+	content, err := os.ReadFile(resultsFile)
+	if err != nil {
+		t.Fatalf("reading results file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("results file has %d lines, want 2:\n%s", len(lines), content)
+	}
+	var got testResult
+	if err := json.Unmarshal([]byte(lines[1]), &got); err != nil {
+		t.Fatalf("unmarshalling %q: %v", lines[1], err)
+	}
+	if got.Name != "second" || !got.Passed || got.BuildSeconds != 2 {
+		t.Errorf("recorded result = %+v, want passed result of second", got)
+	}
+}
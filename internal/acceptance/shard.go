@@ -0,0 +1,84 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acceptance
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// testShard is a shard of the tests, e.g. 2/4 is the second of four shards.
+type testShard struct {
+	index int // 1-based index of the shard.
+	total int
+}
+
+// parseShard parses the value of the -shard flag, N/M with N from 1 to M. An empty value is the
+// single shard of all tests.
+func parseShard(val string) (testShard, error) {
+	if val == "" {
+		return testShard{index: 1, total: 1}, nil
+	}
+	parts := strings.SplitN(val, "/", 2)
+	if len(parts) != 2 {
+		return testShard{}, fmt.Errorf("invalid shard %q, want N/M", val)
+	}
+	index, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return testShard{}, fmt.Errorf("invalid shard index in %q: %v", val, err)
+	}
+	total, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return testShard{}, fmt.Errorf("invalid shard count in %q: %v", val, err)
+	}
+	if total < 1 || index < 1 || index > total {
+		return testShard{}, fmt.Errorf("invalid shard %q, want N/M with N from 1 to M", val)
+	}
+	return testShard{index: index, total: total}, nil
+}
+
+// includes returns true if the test of the given name belongs to the shard. Tests are assigned by
+// the hash of their name, so that every worker assigns them the same way, regardless of the
+// tests that are filtered out.
+func (s testShard) includes(name string) bool {
+	if s.total == 1 {
+		return true
+	}
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return int(h.Sum32()%uint32(s.total)) == s.index-1
+}
+
+// ShouldTestShard returns true if the test of the given name belongs to the shard of the test run
+// selected with the `-shard` flag.
+func ShouldTestShard(t *testing.T, name string) bool {
+	t.Helper()
+	s, err := parseShard(shard)
+	if err != nil {
+		t.Fatalf("Parsing -shard flag: %v", err)
+	}
+	return s.includes(name)
+}
+
+// testName returns the name of a test, which defaults to its application.
+func testName(name, app string) string {
+	if name == "" {
+		return app
+	}
+	return name
+}
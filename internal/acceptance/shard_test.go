@@ -0,0 +1,72 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acceptance
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseShard(t *testing.T) {
+	testCases := []struct {
+		val     string
+		want    testShard
+		wantErr bool
+	}{
+		{val: "", want: testShard{index: 1, total: 1}},
+		{val: "1/1", want: testShard{index: 1, total: 1}},
+		{val: "2/4", want: testShard{index: 2, total: 4}},
+		{val: "4/4", want: testShard{index: 4, total: 4}},
+		{val: "0/4", wantErr: true},
+		{val: "5/4", wantErr: true},
+		{val: "1/0", wantErr: true},
+		{val: "2", wantErr: true},
+		{val: "a/b", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.val, func(t *testing.T) {
+			got, err := parseShard(tc.val)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("parseShard(%q) got error: %v, want error: %t", tc.val, err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("parseShard(%q) = %+v, want %+v", tc.val, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestShardIncludesEachTestOnce(t *testing.T) {
+	const total = 3
+	counts := map[int]int{}
+	for i := 0; i < 100; i++ {
+		name := fmt.Sprintf("test-%d", i)
+		found := 0
+		for index := 1; index <= total; index++ {
+			if (testShard{index: index, total: total}).includes(name) {
+				found++
+				counts[index]++
+			}
+		}
+		if found != 1 {
+			t.Errorf("test %q is included in %d shards, want 1", name, found)
+		}
+	}
+	for index := 1; index <= total; index++ {
+		if counts[index] == 0 {
+			t.Errorf("shard %d/%d includes no tests", index, total)
+		}
+	}
+}
@@ -33,14 +33,41 @@ var (
 	// LINT.ThenChange(//depot/google3/apphosting/g3doc/runtimes/tutorials/buildpack-tests-debug.md)
 )
 
+// ContainerRuntimeEnv is the env var that selects the container runtime of the tests, docker or
+// podman. Docker is used if it is not set.
+const ContainerRuntimeEnv = "ACCEPTANCE_CONTAINER_RUNTIME"
+
+// containerRuntimeURLs are the installation instructions of the supported container runtimes.
+var containerRuntimeURLs = map[string]string{
+	"docker": "https://docs.docker.com/install/",
+	"podman": "https://podman.io/docs/installation",
+}
+
+// ContainerRuntime returns the name of the CLI of the container runtime selected with
+// ACCEPTANCE_CONTAINER_RUNTIME.
+func ContainerRuntime() (string, error) {
+	name := os.Getenv(ContainerRuntimeEnv)
+	if name == "" {
+		return "docker", nil
+	}
+	if _, ok := containerRuntimeURLs[name]; !ok {
+		return "", fmt.Errorf("unsupported container runtime %q in %s, must be docker or podman", name, ContainerRuntimeEnv)
+	}
+	return name, nil
+}
+
 // Installed checks that all required tools are on PATH.
 func Installed() error {
+	containerRuntime, err := ContainerRuntime()
+	if err != nil {
+		return err
+	}
 	tools := []struct {
 		name string
 		url  string
 	}{
 		{"pack", "https://buildpacks.io/docs/install-pack/"},
-		{"docker", "https://docs.docker.com/install/"},
+		{containerRuntime, containerRuntimeURLs[containerRuntime]},
 		{"container-structure-test", "https://github.com/GoogleContainerTools/container-structure-test#installation"},
 	}
 
@@ -0,0 +1,264 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apphosting
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/nodejs"
+)
+
+var angularAdapter = &adapter{
+	framework:   "angular",
+	displayName: "Angular",
+	packageName: "@angular/core",
+	minVersion:  "16.0",
+	buildCmd:    []string{"ng", "build"},
+	detect: func(ctx *gcp.Context, root string, pjs *nodejs.PackageJSON) (bool, error) {
+		if !hasDependency(pjs, "@angular/core") {
+			return false, nil
+		}
+		return ctx.FileExists(root, "angular.json")
+	},
+	postBuild: angularPostBuild,
+	bundle:    angularBundle,
+}
+
+// angularWorkspace is the subset of angular.json used by the adapter.
+type angularWorkspace struct {
+	Projects map[string]struct {
+		ProjectType string                   `json:"projectType"`
+		Architect   map[string]angularTarget `json:"architect"`
+		Targets     map[string]angularTarget `json:"targets"`
+	} `json:"projects"`
+}
+
+type angularTarget struct {
+	Builder string `json:"builder"`
+	Options struct {
+		OutputPath json.RawMessage `json:"outputPath"`
+		SSR        json.RawMessage `json:"ssr"`
+	} `json:"options"`
+}
+
+// angularApp is the application project of an Angular workspace and the output of its build.
+type angularApp struct {
+	name string
+	// universal is true for the server target of Angular Universal, which the application builder
+	// replaces since Angular 17.
+	universal bool
+	// prerender is true if the Angular Universal server is built by a prerender target.
+	prerender bool
+	// browserDir contains the files served to the browser.
+	browserDir string
+	// serverEntry is the script that starts the server, empty if the app is not server-side rendered.
+	serverEntry string
+	// serverDirs contain the files the server needs at runtime.
+	serverDirs []string
+}
+
+// readAngularApp returns the application project of angular.json. If the workspace has several
+// application projects, exactly one of them must be server-side rendered.
+func readAngularApp(root string) (*angularApp, error) {
+	raw, err := ioutil.ReadFile(filepath.Join(root, "angular.json"))
+	if err != nil {
+		return nil, gcp.InternalErrorf("reading angular.json: %v", err)
+	}
+	var ws angularWorkspace
+	if err := json.Unmarshal(raw, &ws); err != nil {
+		return nil, gcp.UserErrorf("parsing angular.json: %v", err)
+	}
+
+	var apps, ssrApps []*angularApp
+	var names []string
+	for name := range ws.Projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := ws.Projects[name]
+		if p.ProjectType != "application" {
+			continue
+		}
+		targets := p.Architect
+		if targets == nil {
+			targets = p.Targets
+		}
+		app, err := newAngularApp(name, targets)
+		if err != nil {
+			return nil, err
+		}
+		if app == nil {
+			continue
+		}
+		apps = append(apps, app)
+		if app.serverEntry != "" {
+			ssrApps = append(ssrApps, app)
+		}
+	}
+	switch {
+	case len(apps) == 1:
+		return apps[0], nil
+	case len(ssrApps) == 1:
+		return ssrApps[0], nil
+	case len(apps) == 0:
+		return nil, gcp.UserErrorf("angular.json does not contain an application project with a build target")
+	}
+	var ssrNames []string
+	for _, app := range ssrApps {
+		ssrNames = append(ssrNames, app.name)
+	}
+	return nil, gcp.UserErrorf("angular.json contains %d application projects and %d of them are server-side rendered (%s), App Hosting builds a single application", len(apps), len(ssrApps), strings.Join(ssrNames, ", "))
+}
+
+// newAngularApp resolves the build output of a project. It returns nil if the project cannot be
+// built.
+func newAngularApp(name string, targets map[string]angularTarget) (*angularApp, error) {
+	build, ok := targets["build"]
+	if !ok {
+		return nil, nil
+	}
+	app := &angularApp{name: name}
+	switch {
+	case strings.HasSuffix(build.Builder, ":application"):
+		base, browser, server, err := angularOutputPath(build.Options.OutputPath, name)
+		if err != nil {
+			return nil, err
+		}
+		app.browserDir = filepath.Join(base, browser)
+		if isTruthy(build.Options.SSR) {
+			app.serverEntry = filepath.Join(base, server, "server.mjs")
+			app.serverDirs = []string{base}
+		}
+	case strings.HasSuffix(build.Builder, ":browser"), strings.HasSuffix(build.Builder, ":browser-esbuild"):
+		out, err := angularLegacyOutputPath(build.Options.OutputPath, name)
+		if err != nil {
+			return nil, err
+		}
+		app.browserDir = out
+		server, ok := targets["server"]
+		if !ok {
+			break
+		}
+		serverOut, err := angularLegacyOutputPath(server.Options.OutputPath, name)
+		if err != nil {
+			return nil, err
+		}
+		app.universal = true
+		_, app.prerender = targets["prerender"]
+		app.serverEntry = filepath.Join(serverOut, "main.js")
+		app.serverDirs = []string{out, serverOut}
+	default:
+		return nil, gcp.UserErrorf("the build target of Angular project %q uses the unsupported builder %q", name, build.Builder)
+	}
+	return app, nil
+}
+
+// angularOutputPath returns the output directories of the application builder. outputPath is
+// either the base directory or an object with base, browser and server directories.
+func angularOutputPath(raw json.RawMessage, project string) (string, string, string, error) {
+	out := struct {
+		Base    string  `json:"base"`
+		Browser *string `json:"browser"`
+		Server  *string `json:"server"`
+	}{}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &out.Base); err != nil {
+			if err := json.Unmarshal(raw, &out); err != nil {
+				return "", "", "", gcp.UserErrorf("parsing the outputPath of Angular project %q: %v", project, err)
+			}
+		}
+	}
+	if out.Base == "" {
+		out.Base = filepath.Join("dist", project)
+	}
+	browser, server := "browser", "server"
+	if out.Browser != nil {
+		browser = *out.Browser
+	}
+	if out.Server != nil {
+		server = *out.Server
+	}
+	return out.Base, browser, server, nil
+}
+
+// angularLegacyOutputPath returns the output directory of the browser and server builders.
+func angularLegacyOutputPath(raw json.RawMessage, project string) (string, error) {
+	var out string
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &out); err != nil {
+			return "", gcp.UserErrorf("parsing the outputPath of Angular project %q: %v", project, err)
+		}
+	}
+	if out == "" {
+		return "", gcp.UserErrorf("Angular project %q does not set the outputPath of its build targets", project)
+	}
+	return out, nil
+}
+
+// isTruthy returns true if a JSON value is set and neither false nor null.
+func isTruthy(raw json.RawMessage) bool {
+	v := strings.TrimSpace(string(raw))
+	return v != "" && v != "false" && v != "null"
+}
+
+// angularPostBuild builds the server of an Angular Universal app, which `ng build` does not.
+func angularPostBuild(ctx *gcp.Context, root string, pm PackageManager) error {
+	app, err := readAngularApp(root)
+	if err != nil {
+		return err
+	}
+	if !app.universal {
+		return nil
+	}
+	target := "server"
+	if app.prerender {
+		target = "prerender"
+	}
+	cmd := append(append([]string{}, pm.Exec...), "ng", "run", app.name+":"+target)
+	_, err = ctx.Exec(cmd, gcp.WithWorkDir(root), gcp.WithUserAttribution)
+	return err
+}
+
+// angularBundle describes the server and the browser files of an Angular app.
+func angularBundle(ctx *gcp.Context, root string) (*Bundle, error) {
+	app, err := readAngularApp(root)
+	if err != nil {
+		return nil, err
+	}
+	b := &Bundle{
+		OutputFiles: OutputFiles{
+			StaticAssets: []StaticAsset{{URLPath: "/", Dir: app.browserDir}},
+		},
+	}
+	if app.serverEntry == "" {
+		return b, nil
+	}
+	exists, err := ctx.FileExists(root, app.serverEntry)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, gcp.UserErrorf("the server of Angular project %q was not built, %s not found", app.name, app.serverEntry)
+	}
+	b.RunConfig.RunCommand = "node " + app.serverEntry
+	b.OutputFiles.ServerApp.Include = app.serverDirs
+	return b, nil
+}
@@ -0,0 +1,200 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apphosting
+
+import (
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAngularBundle(t *testing.T) {
+	testCases := []struct {
+		name    string
+		files   map[string]string
+		want    *Bundle
+		wantErr bool
+	}{
+		{
+			name: "application builder with ssr",
+			files: map[string]string{
+				"angular.json": `{"projects": {"shop": {"projectType": "application", "architect": {"build": {
+					"builder": "@angular-devkit/build-angular:application",
+					"options": {"outputPath": "dist/shop", "server": "src/main.server.ts", "ssr": {"entry": "server.ts"}}}}}}}`,
+				"dist/shop/server/server.mjs": "",
+			},
+			want: &Bundle{
+				RunConfig: RunConfig{RunCommand: "node dist/shop/server/server.mjs"},
+				OutputFiles: OutputFiles{
+					ServerApp:    ServerApp{Include: []string{"dist/shop"}},
+					StaticAssets: []StaticAsset{{URLPath: "/", Dir: "dist/shop/browser"}},
+				},
+			},
+		},
+		{
+			name: "application builder with output path object",
+			files: map[string]string{
+				"angular.json": `{"projects": {"shop": {"projectType": "application", "targets": {"build": {
+					"builder": "@angular/build:application",
+					"options": {"outputPath": {"base": "out", "browser": "", "server": "node"}, "ssr": true}}}}}}`,
+				"out/node/server.mjs": "",
+			},
+			want: &Bundle{
+				RunConfig: RunConfig{RunCommand: "node out/node/server.mjs"},
+				OutputFiles: OutputFiles{
+					ServerApp:    ServerApp{Include: []string{"out"}},
+					StaticAssets: []StaticAsset{{URLPath: "/", Dir: "out"}},
+				},
+			},
+		},
+		{
+			name: "application builder without ssr",
+			files: map[string]string{
+				"angular.json": `{"projects": {"shop": {"projectType": "application", "architect": {"build": {
+					"builder": "@angular-devkit/build-angular:application", "options": {"prerender": true}}}}}}`,
+			},
+			want: &Bundle{
+				OutputFiles: OutputFiles{
+					StaticAssets: []StaticAsset{{URLPath: "/", Dir: "dist/shop/browser"}},
+				},
+			},
+		},
+		{
+			name: "universal",
+			files: map[string]string{
+				"angular.json": `{"projects": {"shop": {"projectType": "application", "architect": {
+					"build": {"builder": "@angular-devkit/build-angular:browser", "options": {"outputPath": "dist/shop/browser"}},
+					"server": {"builder": "@angular-devkit/build-angular:server", "options": {"outputPath": "dist/shop/server"}}}}}}`,
+				"dist/shop/server/main.js": "",
+			},
+			want: &Bundle{
+				RunConfig: RunConfig{RunCommand: "node dist/shop/server/main.js"},
+				OutputFiles: OutputFiles{
+					ServerApp:    ServerApp{Include: []string{"dist/shop/browser", "dist/shop/server"}},
+					StaticAssets: []StaticAsset{{URLPath: "/", Dir: "dist/shop/browser"}},
+				},
+			},
+		},
+		{
+			name: "library and ssr application",
+			files: map[string]string{
+				"angular.json": `{"projects": {
+					"ui": {"projectType": "library", "architect": {"build": {"builder": "@angular-devkit/build-angular:ng-packagr"}}},
+					"admin": {"projectType": "application", "architect": {"build": {"builder": "@angular-devkit/build-angular:application"}}},
+					"shop": {"projectType": "application", "architect": {"build": {"builder": "@angular-devkit/build-angular:application", "options": {"ssr": true}}}}}}`,
+				"dist/shop/server/server.mjs": "",
+			},
+			want: &Bundle{
+				RunConfig: RunConfig{RunCommand: "node dist/shop/server/server.mjs"},
+				OutputFiles: OutputFiles{
+					ServerApp:    ServerApp{Include: []string{"dist/shop"}},
+					StaticAssets: []StaticAsset{{URLPath: "/", Dir: "dist/shop/browser"}},
+				},
+			},
+		},
+		{
+			name: "several ssr applications",
+			files: map[string]string{
+				"angular.json": `{"projects": {
+					"admin": {"projectType": "application", "architect": {"build": {"builder": "@angular-devkit/build-angular:application", "options": {"ssr": true}}}},
+					"shop": {"projectType": "application", "architect": {"build": {"builder": "@angular-devkit/build-angular:application", "options": {"ssr": true}}}}}}`,
+			},
+			wantErr: true,
+		},
+		{
+			name: "server not built",
+			files: map[string]string{
+				"angular.json": `{"projects": {"shop": {"projectType": "application", "architect": {"build": {
+					"builder": "@angular-devkit/build-angular:application", "options": {"ssr": true}}}}}}`,
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported builder",
+			files: map[string]string{
+				"angular.json": `{"projects": {"shop": {"projectType": "application", "architect": {"build": {"builder": "@angular-builders/custom-webpack:browser-v2"}}}}}`,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tc.files)
+			ctx := gcp.NewContext(gcp.WithApplicationRoot(dir))
+
+			got, err := angularBundle(ctx, dir)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("angularBundle() got no error, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("angularBundle() got error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("angularBundle() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAngularPostBuild(t *testing.T) {
+	testCases := []struct {
+		name        string
+		angularJSON string
+		want        string
+	}{
+		{
+			name: "application builder",
+			angularJSON: `{"projects": {"shop": {"projectType": "application", "architect": {
+				"build": {"builder": "@angular-devkit/build-angular:application", "options": {"ssr": true}}}}}}`,
+		},
+		{
+			name: "universal server",
+			angularJSON: `{"projects": {"shop": {"projectType": "application", "architect": {
+				"build": {"builder": "@angular-devkit/build-angular:browser", "options": {"outputPath": "dist/shop/browser"}},
+				"server": {"builder": "@angular-devkit/build-angular:server", "options": {"outputPath": "dist/shop/server"}}}}}}`,
+			want: "ng run shop:server",
+		},
+		{
+			name: "universal prerender",
+			angularJSON: `{"projects": {"shop": {"projectType": "application", "architect": {
+				"build": {"builder": "@angular-devkit/build-angular:browser", "options": {"outputPath": "dist/shop/browser"}},
+				"server": {"builder": "@angular-devkit/build-angular:server", "options": {"outputPath": "dist/shop/server"}},
+				"prerender": {"builder": "@nguniversal/builders:prerender"}}}}}`,
+			want: "ng run shop:prerender",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{"angular.json": tc.angularJSON})
+			ctx := gcp.NewContext(gcp.WithApplicationRoot(dir))
+			// The fake package manager records the command it runs.
+			pm := PackageManager{Exec: []string{"sh", "-c", `echo "$@" > ran`, "--"}}
+
+			if err := angularPostBuild(ctx, dir, pm); err != nil {
+				t.Fatalf("angularPostBuild() got error: %v", err)
+			}
+			got := readFileIfExists(t, dir, "ran")
+			if got != tc.want {
+				t.Errorf("angularPostBuild() ran %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,327 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package apphosting contains the framework adapters that build Node.js web apps for Firebase App
+// Hosting and describe their output in an App Hosting bundle.
+package apphosting
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/nodejs"
+	"github.com/Masterminds/semver"
+)
+
+// unsupportedVersionReason is the reason in the error details of a build that failed because the
+// framework version is not supported.
+const unsupportedVersionReason = "UNSUPPORTED_FRAMEWORK_VERSION"
+
+// PackageManager describes how to run the package.json scripts and the binaries of the installed
+// packages of an app.
+type PackageManager struct {
+	// Run is the command prefix that runs a package.json script.
+	Run []string
+	// Exec is the command prefix that runs a binary of an installed package.
+	Exec []string
+}
+
+var (
+	// NPM runs scripts and binaries with npm.
+	NPM = PackageManager{Run: []string{"npm", "run"}, Exec: []string{"npx", "--no-install"}}
+	// Yarn runs scripts and binaries with Yarn.
+	Yarn = PackageManager{Run: []string{"yarn", "run"}, Exec: []string{"yarn", "run"}}
+	// PNPM runs scripts and binaries with pnpm.
+	PNPM = PackageManager{Run: []string{"pnpm", "run"}, Exec: []string{"pnpm", "exec"}}
+)
+
+// adapter builds the apps of a framework and describes their output.
+type adapter struct {
+	// framework is the framework identifier written to the bundle metadata.
+	framework string
+	// displayName is the framework name used in logs and errors.
+	displayName string
+	// packageName is the npm package that provides the framework and its version.
+	packageName string
+	// minVersion is the oldest version of the framework package that the adapter supports.
+	minVersion string
+	// buildCmd is the framework CLI command used when package.json has no build script.
+	buildCmd []string
+	// buildEnv holds environment variables (of the form "KEY=value") set for the build.
+	buildEnv []string
+	// launchEnv holds environment variables set for the built server, overriding the environment.
+	launchEnv map[string]string
+	// launchEnvDefaults holds environment variables set for the built server unless they are set.
+	launchEnvDefaults map[string]string
+	// postBuild, if set, runs after the build, e.g. to build a server that the build script does not.
+	postBuild func(ctx *gcp.Context, root string, pm PackageManager) error
+	// detect returns true if the app in the root directory uses the framework.
+	detect func(ctx *gcp.Context, root string, pjs *nodejs.PackageJSON) (bool, error)
+	// bundle describes the output of the build, with paths relative to the root directory of the app.
+	bundle func(ctx *gcp.Context, root string) (*Bundle, error)
+}
+
+// adapters lists the supported frameworks in detection order.
+var adapters = []*adapter{
+	nextjsAdapter,
+	angularAdapter,
+	nuxtAdapter,
+	svelteKitAdapter,
+	astroAdapter,
+	reactRouterAdapter,
+	remixAdapter,
+}
+
+// Framework is a framework detected in an app.
+type Framework struct {
+	adapter *adapter
+	pjs     *nodejs.PackageJSON
+	// root is the directory of the app: the application root, or the selected workspace of a
+	// monorepo.
+	root string
+	// ws is the selected workspace of a monorepo, nil if the app is the application root.
+	ws *nodejs.Workspace
+	// bundle is the App Hosting bundle written by Build.
+	bundle *Bundle
+}
+
+// Name returns the display name of the framework.
+func (f *Framework) Name() string {
+	return f.adapter.displayName
+}
+
+// DetectFramework returns the framework of an app that is built for Firebase App Hosting. It
+// returns nil if the target platform is not App Hosting or if the app does not use a supported
+// framework. In a monorepo the app is the selected workspace ws, otherwise ws is nil and the app is
+// the application root with the package.json pjs.
+func DetectFramework(ctx *gcp.Context, pjs *nodejs.PackageJSON, ws *nodejs.Workspace) (*Framework, error) {
+	if !env.IsFAH() {
+		return nil, nil
+	}
+	root := appRoot(ctx, ws)
+	if ws != nil {
+		pjs = ws.PackageJSON
+	}
+	if pjs == nil {
+		return nil, nil
+	}
+	for _, a := range adapters {
+		found, err := a.detect(ctx, root, pjs)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			ctx.Logf("Detected %s, building the app for Firebase App Hosting.", a.displayName)
+			return &Framework{adapter: a, pjs: pjs, root: root, ws: ws}, nil
+		}
+	}
+	return nil, nil
+}
+
+// appRoot returns the directory of the app: the selected workspace of a monorepo, or the
+// application root if ws is nil.
+func appRoot(ctx *gcp.Context, ws *nodejs.Workspace) string {
+	if ws == nil {
+		return ctx.ApplicationRoot()
+	}
+	return filepath.Join(ctx.ApplicationRoot(), ws.Dir)
+}
+
+// Build builds the app with the framework, writes the App Hosting bundle to the directory of the
+// app and returns the command that serves the app, which runs in that directory. The command is
+// nil if the output is a fully static site. Build must run after the devDependencies of the app
+// are installed. The runtime environment variables and the service config of apphosting.yaml are
+// written to the bundle.
+func (f *Framework) Build(ctx *gcp.Context, pm PackageManager) ([]string, error) {
+	a := f.adapter
+	version := installedVersion(ctx, f.root, f.pjs, a.packageName)
+	if err := a.checkVersion(ctx, version); err != nil {
+		return nil, err
+	}
+	ctx.Logf("Building the %s %s app.", a.displayName, version)
+	cmd, dir := buildCommand(f.pjs, pm, a.buildCmd), f.root
+	if f.ws != nil && f.ws.Turbo {
+		if script := f.ws.BuildScript(); script != "" {
+			// Turborepo builds the workspaces that the app depends on first, from the application root.
+			cmd, dir = f.ws.TurboBuildCommand(pm.Exec, script), ctx.ApplicationRoot()
+		}
+	}
+	opts := []gcp.ExecOption{gcp.WithWorkDir(dir), gcp.WithUserAttribution}
+	if len(a.buildEnv) > 0 {
+		opts = append(opts, gcp.WithEnv(a.buildEnv...))
+	}
+	if _, err := ctx.Exec(cmd, opts...); err != nil {
+		return nil, err
+	}
+	if a.postBuild != nil {
+		if err := a.postBuild(ctx, f.root, pm); err != nil {
+			return nil, err
+		}
+	}
+
+	b, err := a.bundle(ctx, f.root)
+	if err != nil {
+		return nil, err
+	}
+	b.Version = bundleVersion
+	cfg, err := readConfig(ctx, f.root)
+	if err != nil {
+		return nil, err
+	}
+	if cfg != nil {
+		b.RunConfig.ServiceConfig = cfg.RunConfig
+		b.RunConfig.EnvironmentVariables = cfg.runtimeEnv()
+	}
+	b.Metadata = Metadata{
+		AdapterPackageName: ctx.BuildpackID(),
+		AdapterVersion:     ctx.BuildpackVersion(),
+		Framework:          a.framework,
+		FrameworkVersion:   version,
+	}
+	if err := writeBundle(ctx, f.root, b); err != nil {
+		return nil, err
+	}
+	f.bundle = b
+
+	if b.RunConfig.RunCommand == "" {
+		return nil, nil
+	}
+	if len(a.launchEnv) > 0 || len(a.launchEnvDefaults) > 0 {
+		l, err := ctx.Layer("apphosting", gcp.LaunchLayer)
+		if err != nil {
+			return nil, fmt.Errorf("creating layer: %w", err)
+		}
+		for k, v := range a.launchEnv {
+			l.LaunchEnvironment.Override(k, v)
+		}
+		for k, v := range a.launchEnvDefaults {
+			l.LaunchEnvironment.Default(k, v)
+		}
+	}
+	return strings.Fields(b.RunConfig.RunCommand), nil
+}
+
+// StaticSiteDir returns the directory of the static site that Build output for an app without a
+// server, relative to the application root: the first static assets that are not immutable, which
+// are the pages of the site. It returns "" if the app has a server.
+func (f *Framework) StaticSiteDir(ctx *gcp.Context) (string, error) {
+	if f.bundle == nil || f.bundle.RunConfig.RunCommand != "" {
+		return "", nil
+	}
+	for _, a := range f.bundle.OutputFiles.StaticAssets {
+		if a.Immutable {
+			continue
+		}
+		dir, err := filepath.Rel(ctx.ApplicationRoot(), filepath.Join(f.root, a.Dir))
+		if err != nil {
+			return "", gcp.InternalErrorf("finding the static site of the %s app: %v", f.Name(), err)
+		}
+		return dir, nil
+	}
+	return "", nil
+}
+
+// checkVersion returns an error if the installed version of the framework is older than the
+// adapter supports. The error details tell App Hosting why the build failed. Versions that are not
+// semantic versions, e.g. the "latest" tag declared in package.json, are assumed to be supported.
+func (a *adapter) checkVersion(ctx *gcp.Context, version string) error {
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		ctx.Debugf("Skipping the %s version check of %q: %v", a.displayName, version, err)
+		return nil
+	}
+	if !v.LessThan(semver.MustParse(a.minVersion)) {
+		return nil
+	}
+	be := gcp.UserErrorf("%s %s is not supported; minimum is %s. Upgrade the %s package to version %s or later.", a.displayName, version, a.minVersion, a.packageName, a.minVersion)
+	be.Details = map[string]string{
+		"reason":           unsupportedVersionReason,
+		"framework":        a.framework,
+		"frameworkVersion": version,
+		"minimumVersion":   a.minVersion,
+		"packageName":      a.packageName,
+	}
+	return be
+}
+
+// buildCommand returns the command that builds the app: the gcp-build or build script of
+// package.json if there is one, the framework CLI otherwise.
+func buildCommand(pjs *nodejs.PackageJSON, pm PackageManager, cli []string) []string {
+	var cmd []string
+	switch {
+	case nodejs.HasGCPBuild(pjs):
+		cmd = append(append(cmd, pm.Run...), "gcp-build")
+	case pjs.Scripts.Build != "":
+		cmd = append(append(cmd, pm.Run...), "build")
+	default:
+		cmd = append(append(cmd, pm.Exec...), cli...)
+	}
+	return cmd
+}
+
+// hasDependency returns true if package.json lists the package as a dependency or devDependency.
+func hasDependency(pjs *nodejs.PackageJSON, pkg string) bool {
+	if _, ok := pjs.Dependencies[pkg]; ok {
+		return true
+	}
+	_, ok := pjs.DevDependencies[pkg]
+	return ok
+}
+
+// installedVersion returns the installed version of a package, falling back to the version
+// declared in package.json if node_modules does not contain it. The package is looked up in the
+// node_modules of the app, then in the node_modules of the application root, where the package
+// managers of monorepos hoist it.
+func installedVersion(ctx *gcp.Context, root string, pjs *nodejs.PackageJSON, pkg string) string {
+	for _, dir := range []string{root, ctx.ApplicationRoot()} {
+		raw, err := ioutil.ReadFile(filepath.Join(dir, "node_modules", pkg, "package.json"))
+		if err != nil {
+			continue
+		}
+		var p struct {
+			Version string `json:"version"`
+		}
+		if err := json.Unmarshal(raw, &p); err == nil && p.Version != "" {
+			return p.Version
+		}
+	}
+	v := pjs.Dependencies[pkg]
+	if v == "" {
+		v = pjs.DevDependencies[pkg]
+	}
+	return strings.TrimLeft(v, "^~>=v ")
+}
+
+// urlPath returns the absolute URL path of p under the base path of the app.
+func urlPath(basePath, p string) string {
+	return path.Join("/", basePath, p)
+}
+
+// copyDir replaces dst with a copy of src.
+func copyDir(ctx *gcp.Context, src, dst string) error {
+	if err := ctx.RemoveAll(dst); err != nil {
+		return err
+	}
+	if err := ctx.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	_, err := ctx.Exec([]string{"cp", "--archive", src, dst}, gcp.WithUserTimingAttribution)
+	return err
+}
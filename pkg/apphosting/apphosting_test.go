@@ -0,0 +1,481 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apphosting
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/buildererror"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/nodejs"
+	"github.com/buildpacks/libcnb"
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v2"
+)
+
+func TestDetectFramework(t *testing.T) {
+	testCases := []struct {
+		name        string
+		platform    string
+		packageJSON string
+		// workspace is the directory of the selected workspace of a monorepo, whose package.json is
+		// packageJSON.
+		workspace string
+		files     map[string]string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:        "Next.js",
+			platform:    env.TargetPlatformAppHosting,
+			packageJSON: `{"dependencies": {"next": "^14.1.0", "react": "^18.2.0"}}`,
+			want:        "Next.js",
+		},
+		{
+			name:        "Next.js devDependency",
+			platform:    env.TargetPlatformAppHosting,
+			packageJSON: `{"devDependencies": {"next": "^14.1.0"}}`,
+			want:        "Next.js",
+		},
+		{
+			name:        "Angular",
+			platform:    env.TargetPlatformAppHosting,
+			packageJSON: `{"dependencies": {"@angular/core": "^17.3.0"}}`,
+			files:       map[string]string{"angular.json": `{}`},
+			want:        "Angular",
+		},
+		{
+			name:        "Angular without angular.json",
+			platform:    env.TargetPlatformAppHosting,
+			packageJSON: `{"dependencies": {"@angular/core": "^17.3.0"}}`,
+		},
+		{
+			name:        "Nuxt",
+			platform:    env.TargetPlatformAppHosting,
+			packageJSON: `{"devDependencies": {"nuxt": "^3.10.0"}}`,
+			want:        "Nuxt",
+		},
+		{
+			name:        "SvelteKit with adapter-node",
+			platform:    env.TargetPlatformAppHosting,
+			packageJSON: `{"devDependencies": {"@sveltejs/kit": "^2.5.0", "@sveltejs/adapter-node": "^5.0.0"}}`,
+			want:        "SvelteKit",
+		},
+		{
+			name:        "SvelteKit with adapter-auto",
+			platform:    env.TargetPlatformAppHosting,
+			packageJSON: `{"devDependencies": {"@sveltejs/kit": "^2.5.0", "@sveltejs/adapter-auto": "^3.0.0"}}`,
+			wantErr:     true,
+		},
+		{
+			name:        "Astro",
+			platform:    env.TargetPlatformAppHosting,
+			packageJSON: `{"dependencies": {"astro": "^4.5.0"}}`,
+			want:        "Astro",
+		},
+		{
+			name:        "React Router",
+			platform:    env.TargetPlatformAppHosting,
+			packageJSON: `{"dependencies": {"react-router": "^7.0.0"}, "devDependencies": {"@react-router/dev": "^7.0.0"}}`,
+			want:        "React Router",
+		},
+		{
+			name:        "Remix",
+			platform:    env.TargetPlatformAppHosting,
+			packageJSON: `{"dependencies": {"@remix-run/node": "^2.8.0"}, "devDependencies": {"@remix-run/dev": "^2.8.0"}}`,
+			want:        "Remix",
+		},
+		{
+			name:        "Angular workspace",
+			platform:    env.TargetPlatformAppHosting,
+			packageJSON: `{"name": "web", "dependencies": {"@angular/core": "^17.3.0"}}`,
+			workspace:   "apps/web",
+			files:       map[string]string{"apps/web/angular.json": `{}`},
+			want:        "Angular",
+		},
+		{
+			name:        "Angular workspace without angular.json",
+			platform:    env.TargetPlatformAppHosting,
+			packageJSON: `{"name": "web", "dependencies": {"@angular/core": "^17.3.0"}}`,
+			workspace:   "apps/web",
+			files:       map[string]string{"angular.json": `{}`},
+		},
+		{
+			name:        "not App Hosting",
+			platform:    env.TargetPlatformAppEngine,
+			packageJSON: `{"dependencies": {"next": "^14.1.0"}}`,
+		},
+		{
+			name:        "no framework",
+			platform:    env.TargetPlatformAppHosting,
+			packageJSON: `{"dependencies": {"express": "^4.18.2"}}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(env.XGoogleTargetPlatform, tc.platform)
+			dir := t.TempDir()
+			writeFiles(t, dir, tc.files)
+			ctx := gcp.NewContext(gcp.WithApplicationRoot(dir))
+
+			pjs := readPackageJSON(t, tc.packageJSON)
+			var ws *nodejs.Workspace
+			if tc.workspace != "" {
+				ws = &nodejs.Workspace{Dir: tc.workspace, PackageJSON: pjs}
+				pjs = readPackageJSON(t, `{"workspaces": ["apps/*"]}`)
+			}
+
+			f, err := DetectFramework(ctx, pjs, ws)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("DetectFramework() got no error, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DetectFramework() got error: %v", err)
+			}
+			got := ""
+			if f != nil {
+				got = f.Name()
+			}
+			if got != tc.want {
+				t.Errorf("DetectFramework() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuildCommand(t *testing.T) {
+	testCases := []struct {
+		name        string
+		packageJSON string
+		pm          PackageManager
+		want        []string
+	}{
+		{
+			name:        "gcp-build script",
+			packageJSON: `{"scripts": {"gcp-build": "next build --debug", "build": "next build"}}`,
+			pm:          NPM,
+			want:        []string{"npm", "run", "gcp-build"},
+		},
+		{
+			name:        "build script",
+			packageJSON: `{"scripts": {"build": "next build"}}`,
+			pm:          Yarn,
+			want:        []string{"yarn", "run", "build"},
+		},
+		{
+			name:        "framework cli",
+			packageJSON: `{}`,
+			pm:          NPM,
+			want:        []string{"npx", "--no-install", "next", "build"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := buildCommand(readPackageJSON(t, tc.packageJSON), tc.pm, []string{"next", "build"})
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("buildCommand() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	t.Setenv(env.XGoogleTargetPlatform, env.TargetPlatformAppHosting)
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"node_modules/next/package.json": `{"name": "next", "version": "14.1.4"}`,
+	})
+	ctx := gcp.NewContext(
+		gcp.WithApplicationRoot(dir),
+		gcp.WithBuildpackInfo(libcnb.BuildpackInfo{ID: "google.nodejs.npm", Version: "1.0.0"}),
+		gcp.WithBuildContext(libcnb.BuildContext{Layers: libcnb.Layers{Path: t.TempDir()}}),
+	)
+	f, err := DetectFramework(ctx, readPackageJSON(t, `{"dependencies": {"next": "^14.1.0"}, "scripts": {"build": "next build"}}`), nil)
+	if err != nil || f == nil {
+		t.Fatalf("DetectFramework() = %v, %v, want Next.js", f, err)
+	}
+	// The fake build script emits a standalone server when NEXT_PRIVATE_STANDALONE is set.
+	fakeBuild := `test "$NEXT_PRIVATE_STANDALONE" = true && mkdir -p .next/static .next/standalone && touch .next/standalone/server.js`
+	pm := PackageManager{Run: []string{"sh", "-c", fakeBuild}}
+
+	cmd, err := f.Build(ctx, pm)
+	if err != nil {
+		t.Fatalf("Build() got error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"node", ".next/standalone/server.js"}, cmd); diff != "" {
+		t.Errorf("Build() mismatch (-want +got):\n%s", diff)
+	}
+	got := readBundle(t, dir)
+	wantMetadata := Metadata{
+		AdapterPackageName: "google.nodejs.npm",
+		AdapterVersion:     "1.0.0",
+		Framework:          "nextjs",
+		FrameworkVersion:   "14.1.4",
+	}
+	if got.Version != "v1" {
+		t.Errorf("bundle version = %q, want v1", got.Version)
+	}
+	if diff := cmp.Diff(wantMetadata, got.Metadata); diff != "" {
+		t.Errorf("bundle metadata mismatch (-want +got):\n%s", diff)
+	}
+	if site, err := f.StaticSiteDir(ctx); err != nil || site != "" {
+		t.Errorf("StaticSiteDir() = %q, %v, want no static site for a server", site, err)
+	}
+}
+
+func TestBuildWorkspace(t *testing.T) {
+	testCases := []struct {
+		name  string
+		turbo bool
+		// wantLog is the first line written by the fake build, the working directory of the build
+		// and its arguments.
+		wantLog string
+	}{
+		{
+			name:    "workspace",
+			wantLog: "apps/web build",
+		},
+		{
+			name:    "turborepo",
+			turbo:   true,
+			wantLog: ". turbo run build --filter=web",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(env.XGoogleTargetPlatform, env.TargetPlatformAppHosting)
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{"apps/web/package.json": `{"name": "web"}`})
+			ctx := gcp.NewContext(gcp.WithApplicationRoot(dir))
+			ws := &nodejs.Workspace{
+				Dir:         "apps/web",
+				PackageJSON: readPackageJSON(t, `{"name": "web", "dependencies": {"astro": "^4.5.0"}, "scripts": {"build": "astro build"}}`),
+				Turbo:       tc.turbo,
+			}
+			f, err := DetectFramework(ctx, readPackageJSON(t, `{"workspaces": ["apps/*"]}`), ws)
+			if err != nil || f == nil {
+				t.Fatalf("DetectFramework() = %v, %v, want Astro", f, err)
+			}
+			// The fake build logs where it runs and its arguments, then emits a static site in the
+			// workspace.
+			fakeBuild := []string{"sh", "-c", `realpath --relative-to="$ROOT" . > "$ROOT/build.log" && echo "$@" >> "$ROOT/build.log" && mkdir -p "$ROOT/apps/web/dist" && touch "$ROOT/apps/web/dist/index.html"`, "sh"}
+			t.Setenv("ROOT", dir)
+			pm := PackageManager{Run: fakeBuild, Exec: fakeBuild}
+
+			cmd, err := f.Build(ctx, pm)
+			if err != nil {
+				t.Fatalf("Build() got error: %v", err)
+			}
+			if cmd != nil {
+				t.Errorf("Build() = %v, want nil for a static site", cmd)
+			}
+			if got := strings.Join(strings.Split(readFileIfExists(t, dir, "build.log"), "\n"), " "); got != tc.wantLog {
+				t.Errorf("build ran as %q, want %q", got, tc.wantLog)
+			}
+			got := readBundle(t, filepath.Join(dir, "apps", "web"))
+			want := []StaticAsset{
+				{URLPath: "/_astro", Dir: "dist/_astro", Immutable: true},
+				{URLPath: "/", Dir: "dist"},
+			}
+			if diff := cmp.Diff(want, got.OutputFiles.StaticAssets); diff != "" {
+				t.Errorf("bundle static assets mismatch (-want +got):\n%s", diff)
+			}
+			site, err := f.StaticSiteDir(ctx)
+			if err != nil {
+				t.Fatalf("StaticSiteDir() got error: %v", err)
+			}
+			if site != "apps/web/dist" {
+				t.Errorf("StaticSiteDir() = %q, want %q", site, "apps/web/dist")
+			}
+		})
+	}
+}
+
+func TestInstalledVersion(t *testing.T) {
+	testCases := []struct {
+		name        string
+		packageJSON string
+		// root is the directory of the app relative to the application root.
+		root  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name:        "installed",
+			packageJSON: `{"dependencies": {"next": "^14.0.0"}}`,
+			files:       map[string]string{"node_modules/next/package.json": `{"version": "14.2.3"}`},
+			want:        "14.2.3",
+		},
+		{
+			name:        "installed in workspace",
+			packageJSON: `{"dependencies": {"next": "^14.0.0"}}`,
+			root:        "apps/web",
+			files: map[string]string{
+				"apps/web/node_modules/next/package.json": `{"version": "14.2.3"}`,
+				"node_modules/next/package.json":          `{"version": "13.5.6"}`,
+			},
+			want: "14.2.3",
+		},
+		{
+			name:        "hoisted from workspace",
+			packageJSON: `{"dependencies": {"next": "^14.0.0"}}`,
+			root:        "apps/web",
+			files:       map[string]string{"node_modules/next/package.json": `{"version": "14.2.3"}`},
+			want:        "14.2.3",
+		},
+		{
+			name:        "declared",
+			packageJSON: `{"devDependencies": {"next": "~13.5.1"}}`,
+			want:        "13.5.1",
+		},
+		{
+			name:        "missing",
+			packageJSON: `{}`,
+			want:        "",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tc.files)
+			ctx := gcp.NewContext(gcp.WithApplicationRoot(dir))
+
+			if got := installedVersion(ctx, filepath.Join(dir, tc.root), readPackageJSON(t, tc.packageJSON), "next"); got != tc.want {
+				t.Errorf("installedVersion() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	testCases := []struct {
+		name        string
+		version     string
+		wantErr     string
+		wantDetails map[string]string
+	}{
+		{
+			name:    "supported",
+			version: "14.1.4",
+		},
+		{
+			name:    "minimum",
+			version: "13.4.0",
+		},
+		{
+			name:    "unsupported",
+			version: "12.3.4",
+			wantErr: "Next.js 12.3.4 is not supported; minimum is 13.4. Upgrade the next package to version 13.4 or later.",
+			wantDetails: map[string]string{
+				"reason":           "UNSUPPORTED_FRAMEWORK_VERSION",
+				"framework":        "nextjs",
+				"frameworkVersion": "12.3.4",
+				"minimumVersion":   "13.4",
+				"packageName":      "next",
+			},
+		},
+		{
+			name:    "prerelease of minimum",
+			version: "13.4.0-canary.1",
+			wantErr: "Next.js 13.4.0-canary.1 is not supported",
+		},
+		{
+			name:    "not a version",
+			version: "latest",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := gcp.NewContext()
+
+			err := nextjsAdapter.checkVersion(ctx, tc.version)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("checkVersion(%q) got error: %v", tc.version, err)
+				}
+				return
+			}
+			var be *buildererror.Error
+			if !errors.As(err, &be) || !strings.Contains(be.Message, tc.wantErr) {
+				t.Fatalf("checkVersion(%q) got error %v, want error containing %q", tc.version, err, tc.wantErr)
+			}
+			if be.Status != buildererror.StatusUnknown {
+				t.Errorf("checkVersion(%q) error status = %v, want a user error", tc.version, be.Status)
+			}
+			if tc.wantDetails != nil {
+				if diff := cmp.Diff(tc.wantDetails, be.Details); diff != "" {
+					t.Errorf("checkVersion(%q) error details mismatch (-want +got):\n%s", tc.version, diff)
+				}
+			}
+		})
+	}
+}
+
+func readPackageJSON(t *testing.T, raw string) *nodejs.PackageJSON {
+	t.Helper()
+	var pjs nodejs.PackageJSON
+	if err := json.Unmarshal([]byte(raw), &pjs); err != nil {
+		t.Fatalf("unmarshalling package.json %q: %v", raw, err)
+	}
+	return &pjs
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("creating dir for %s: %v", name, err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+	}
+}
+
+func readFileIfExists(t *testing.T, dir, name string) string {
+	t.Helper()
+	raw, err := ioutil.ReadFile(filepath.Join(dir, name))
+	if os.IsNotExist(err) {
+		return ""
+	}
+	if err != nil {
+		t.Fatalf("reading %s: %v", name, err)
+	}
+	return strings.TrimSpace(string(raw))
+}
+
+func readBundle(t *testing.T, dir string) *Bundle {
+	t.Helper()
+	raw, err := ioutil.ReadFile(filepath.Join(dir, BundleDir, BundleFile))
+	if err != nil {
+		t.Fatalf("reading bundle: %v", err)
+	}
+	var b Bundle
+	if err := yaml.Unmarshal(raw, &b); err != nil {
+		t.Fatalf("unmarshalling bundle: %v", err)
+	}
+	return &b
+}
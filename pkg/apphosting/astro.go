@@ -0,0 +1,93 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apphosting
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/nodejs"
+)
+
+const (
+	// astroOutputDir is the default outDir of astro.config.mjs.
+	astroOutputDir = "dist"
+	// astroAssetsDir is the default build.assets of astro.config.mjs.
+	astroAssetsDir = "_astro"
+)
+
+var astroAdapter = &adapter{
+	framework:   "astro",
+	displayName: "Astro",
+	packageName: "astro",
+	minVersion:  "3.0",
+	buildCmd:    []string{"astro", "build"},
+	// The standalone server of @astrojs/node listens on localhost unless $HOST is set.
+	launchEnvDefaults: map[string]string{"HOST": "0.0.0.0"},
+	detect: func(ctx *gcp.Context, root string, pjs *nodejs.PackageJSON) (bool, error) {
+		return hasDependency(pjs, "astro"), nil
+	},
+	bundle: astroBundle,
+}
+
+// astroBundle describes the standalone server of an Astro app built with @astrojs/node, or the
+// pages of a static Astro site.
+func astroBundle(ctx *gcp.Context, root string) (*Bundle, error) {
+	exists, err := ctx.FileExists(root, astroOutputDir)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, gcp.UserErrorf("the Astro build did not produce %s, App Hosting supports the default outDir of astro.config.mjs", astroOutputDir)
+	}
+	server, err := ctx.FileExists(root, astroOutputDir, "server")
+	if err != nil {
+		return nil, err
+	}
+	if !server {
+		return &Bundle{
+			OutputFiles: OutputFiles{
+				StaticAssets: astroAssets(astroOutputDir),
+			},
+		}, nil
+	}
+
+	serverEntry := filepath.Join(astroOutputDir, "server", "entry.mjs")
+	src, err := ioutil.ReadFile(filepath.Join(root, serverEntry))
+	if err != nil {
+		return nil, gcp.UserErrorf("the Astro server entry %s was not found, App Hosting runs server-rendered Astro apps with the @astrojs/node adapter", serverEntry)
+	}
+	// In middleware mode the entry exports a request handler and does not start a server.
+	if bytes.Contains(src, []byte(`"mode":"middleware"`)) {
+		return nil, gcp.UserErrorf("the @astrojs/node adapter is in middleware mode, set its mode to 'standalone' in astro.config.mjs to serve the app on App Hosting")
+	}
+	return &Bundle{
+		RunConfig: RunConfig{RunCommand: "node " + serverEntry},
+		OutputFiles: OutputFiles{
+			ServerApp:    ServerApp{Include: []string{astroOutputDir, "node_modules", "package.json"}},
+			StaticAssets: astroAssets(filepath.Join(astroOutputDir, "client")),
+		},
+	}, nil
+}
+
+// astroAssets returns the static assets of a directory served at the root of the site.
+func astroAssets(dir string) []StaticAsset {
+	return []StaticAsset{
+		{URLPath: urlPath("/", astroAssetsDir), Dir: filepath.Join(dir, astroAssetsDir), Immutable: true},
+		{URLPath: "/", Dir: dir},
+	}
+}
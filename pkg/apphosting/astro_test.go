@@ -0,0 +1,104 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apphosting
+
+import (
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAstroBundle(t *testing.T) {
+	testCases := []struct {
+		name    string
+		files   map[string]string
+		want    *Bundle
+		wantErr bool
+	}{
+		{
+			name: "standalone server",
+			files: map[string]string{
+				"dist/server/entry.mjs":      `const _args = {"mode":"standalone","host":false,"port":4321};`,
+				"dist/client/_astro/app.css": "",
+			},
+			want: &Bundle{
+				RunConfig: RunConfig{RunCommand: "node dist/server/entry.mjs"},
+				OutputFiles: OutputFiles{
+					ServerApp: ServerApp{Include: []string{"dist", "node_modules", "package.json"}},
+					StaticAssets: []StaticAsset{
+						{URLPath: "/_astro", Dir: "dist/client/_astro", Immutable: true},
+						{URLPath: "/", Dir: "dist/client"},
+					},
+				},
+			},
+		},
+		{
+			name: "static site",
+			files: map[string]string{
+				"dist/index.html":     "",
+				"dist/_astro/app.css": "",
+			},
+			want: &Bundle{
+				OutputFiles: OutputFiles{
+					StaticAssets: []StaticAsset{
+						{URLPath: "/_astro", Dir: "dist/_astro", Immutable: true},
+						{URLPath: "/", Dir: "dist"},
+					},
+				},
+			},
+		},
+		{
+			name: "middleware mode",
+			files: map[string]string{
+				"dist/server/entry.mjs": `const _args = {"mode":"middleware"};`,
+			},
+			wantErr: true,
+		},
+		{
+			name: "other adapter",
+			files: map[string]string{
+				"dist/server/index.mjs": "",
+			},
+			wantErr: true,
+		},
+		{
+			name:    "no output",
+			files:   map[string]string{"src/pages/index.astro": ""},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tc.files)
+			ctx := gcp.NewContext(gcp.WithApplicationRoot(dir))
+
+			got, err := astroBundle(ctx, dir)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("astroBundle() got no error, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("astroBundle() got error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("astroBundle() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
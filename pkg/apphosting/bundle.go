@@ -0,0 +1,116 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apphosting
+
+import (
+	"path/filepath"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// BundleDir is the directory of the application that contains the App Hosting bundle.
+	BundleDir = ".apphosting"
+	// BundleFile is the name of the App Hosting bundle file.
+	BundleFile = "bundle.yaml"
+
+	bundleVersion = "v1"
+)
+
+// Bundle describes the output of a framework build to Firebase App Hosting.
+type Bundle struct {
+	Version     string       `yaml:"version"`
+	RunConfig   RunConfig    `yaml:"runConfig"`
+	Metadata    Metadata     `yaml:"metadata"`
+	OutputFiles OutputFiles  `yaml:"outputFiles"`
+	Images      *ImageConfig `yaml:"images,omitempty"`
+	RouteRules  []RouteRule  `yaml:"routeRules,omitempty"`
+}
+
+// RunConfig describes how to run the server of the app. RunCommand is empty for a static site.
+type RunConfig struct {
+	RunCommand           string   `yaml:"runCommand,omitempty"`
+	EnvironmentVariables []EnvVar `yaml:"environmentVariables,omitempty"`
+	ServiceConfig        `yaml:",inline"`
+}
+
+// Metadata identifies the adapter and the framework that produced the bundle.
+type Metadata struct {
+	AdapterPackageName string `yaml:"adapterPackageName"`
+	AdapterVersion     string `yaml:"adapterVersion"`
+	Framework          string `yaml:"framework"`
+	FrameworkVersion   string `yaml:"frameworkVersion,omitempty"`
+}
+
+// OutputFiles lists the files of the build output.
+type OutputFiles struct {
+	ServerApp    ServerApp     `yaml:"serverApp,omitempty"`
+	StaticAssets []StaticAsset `yaml:"staticAssets,omitempty"`
+}
+
+// ServerApp lists the paths, relative to the application root, that the server needs at runtime.
+type ServerApp struct {
+	Include []string `yaml:"include,omitempty"`
+}
+
+// StaticAsset maps a directory of static files, relative to the application root, to the URL path
+// it is served from. Immutable assets have content hashed names and can be cached forever.
+type StaticAsset struct {
+	URLPath   string `yaml:"urlPath"`
+	Dir       string `yaml:"dir"`
+	Immutable bool   `yaml:"immutable,omitempty"`
+}
+
+// ImageConfig is the image optimization configuration of the app.
+type ImageConfig struct {
+	Path            string   `yaml:"path"`
+	Loader          string   `yaml:"loader"`
+	Unoptimized     bool     `yaml:"unoptimized"`
+	Formats         []string `yaml:"formats,omitempty"`
+	MinimumCacheTTL int      `yaml:"minimumCacheTTL,omitempty"`
+}
+
+// RouteRule configures the responses to the requests whose path matches a pattern.
+type RouteRule struct {
+	Path      string            `yaml:"path"`
+	Headers   map[string]string `yaml:"headers,omitempty"`
+	Redirect  *Redirect         `yaml:"redirect,omitempty"`
+	Prerender bool              `yaml:"prerender,omitempty"`
+}
+
+// Redirect redirects the requests that match a route rule.
+type Redirect struct {
+	To         string `yaml:"to"`
+	StatusCode int    `yaml:"statusCode,omitempty"`
+}
+
+// writeBundle writes the bundle to BundleDir/BundleFile in the directory of the app.
+func writeBundle(ctx *gcp.Context, root string, b *Bundle) error {
+	data, err := yaml.Marshal(b)
+	if err != nil {
+		return gcp.InternalErrorf("marshalling %s: %v", BundleFile, err)
+	}
+	dir := filepath.Join(root, BundleDir)
+	if err := ctx.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, BundleFile)
+	if err := ctx.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	ctx.Logf("Wrote the App Hosting bundle to %s.", path)
+	return nil
+}
@@ -0,0 +1,224 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apphosting
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/nodejs"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// ConfigFile is the App Hosting configuration file of an app.
+	ConfigFile = "apphosting.yaml"
+
+	// AvailabilityBuild makes an environment variable available to the build.
+	AvailabilityBuild = "BUILD"
+	// AvailabilityRuntime makes an environment variable available to the server.
+	AvailabilityRuntime = "RUNTIME"
+)
+
+var (
+	envVarNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	// reservedEnvVars are set by the platform and cannot be declared in apphosting.yaml.
+	reservedEnvVars = map[string]bool{
+		"PORT":            true,
+		"K_SERVICE":       true,
+		"K_REVISION":      true,
+		"K_CONFIGURATION": true,
+	}
+	reservedEnvVarPrefixes = []string{"X_GOOGLE_", "X_FIREBASE_"}
+)
+
+// Config is the content of apphosting.yaml.
+type Config struct {
+	RunConfig ServiceConfig `yaml:"runConfig"`
+	Env       []EnvVar      `yaml:"env"`
+}
+
+// ServiceConfig configures the service that runs the server of the app.
+type ServiceConfig struct {
+	CPU          *float64 `yaml:"cpu,omitempty"`
+	MemoryMiB    *int     `yaml:"memoryMiB,omitempty"`
+	Concurrency  *int     `yaml:"concurrency,omitempty"`
+	MinInstances *int     `yaml:"minInstances,omitempty"`
+	MaxInstances *int     `yaml:"maxInstances,omitempty"`
+}
+
+// EnvVar declares an environment variable with either a plain value or a reference to a Secret
+// Manager secret. It is available to both the build and the server unless Availability says
+// otherwise.
+type EnvVar struct {
+	Variable     string   `yaml:"variable"`
+	Value        string   `yaml:"value,omitempty"`
+	Secret       string   `yaml:"secret,omitempty"`
+	Availability []string `yaml:"availability,omitempty"`
+}
+
+// availableTo returns true if the variable is available at the given stage.
+func (e EnvVar) availableTo(stage string) bool {
+	if len(e.Availability) == 0 {
+		return true
+	}
+	for _, a := range e.Availability {
+		if a == stage {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadConfig reads and validates the apphosting.yaml of an app that is built for Firebase App
+// Hosting. It returns nil if the target platform is not App Hosting or if the file does not exist.
+// In a monorepo the file is read from the selected workspace ws, or else from the application root.
+func ReadConfig(ctx *gcp.Context, ws *nodejs.Workspace) (*Config, error) {
+	return readConfig(ctx, appRoot(ctx, ws))
+}
+
+// readConfig reads and validates the apphosting.yaml in root, or in the application root if root
+// does not contain one.
+func readConfig(ctx *gcp.Context, root string) (*Config, error) {
+	if !env.IsFAH() {
+		return nil, nil
+	}
+	raw, err := ioutil.ReadFile(filepath.Join(root, ConfigFile))
+	if os.IsNotExist(err) && root != ctx.ApplicationRoot() {
+		raw, err = ioutil.ReadFile(filepath.Join(ctx.ApplicationRoot(), ConfigFile))
+	}
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, gcp.InternalErrorf("reading %s: %v", ConfigFile, err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(raw, &cfg); err != nil {
+		return nil, gcp.UserErrorf("parsing %s: %v", ConfigFile, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, gcp.UserErrorf("invalid %s: %v", ConfigFile, err)
+	}
+	return &cfg, nil
+}
+
+// validate returns an error describing the first invalid declaration of the config.
+func (c *Config) validate() error {
+	seen := map[string]bool{}
+	for i, e := range c.Env {
+		if e.Variable == "" {
+			return fmt.Errorf("env[%d] has no variable name", i)
+		}
+		if !envVarNameRegexp.MatchString(e.Variable) {
+			return fmt.Errorf("env[%d]: %q is not a valid environment variable name", i, e.Variable)
+		}
+		if reservedEnvVars[e.Variable] {
+			return fmt.Errorf("env[%d]: %s is reserved and set by App Hosting", i, e.Variable)
+		}
+		for _, p := range reservedEnvVarPrefixes {
+			if strings.HasPrefix(e.Variable, p) {
+				return fmt.Errorf("env[%d]: %s uses the reserved prefix %s", i, e.Variable, p)
+			}
+		}
+		if seen[e.Variable] {
+			return fmt.Errorf("env[%d]: %s is declared more than once", i, e.Variable)
+		}
+		seen[e.Variable] = true
+		if (e.Value == "") == (e.Secret == "") {
+			return fmt.Errorf("env[%d]: %s must set exactly one of value and secret", i, e.Variable)
+		}
+		for _, a := range e.Availability {
+			if a != AvailabilityBuild && a != AvailabilityRuntime {
+				return fmt.Errorf("env[%d]: %s has availability %q, want %s or %s", i, e.Variable, a, AvailabilityBuild, AvailabilityRuntime)
+			}
+		}
+	}
+
+	rc := c.RunConfig
+	counts := []struct {
+		name  string
+		value *int
+	}{
+		{"memoryMiB", rc.MemoryMiB},
+		{"concurrency", rc.Concurrency},
+		{"minInstances", rc.MinInstances},
+		{"maxInstances", rc.MaxInstances},
+	}
+	for _, n := range counts {
+		if n.value != nil && *n.value < 0 {
+			return fmt.Errorf("runConfig.%s is %d, want a non-negative number", n.name, *n.value)
+		}
+	}
+	if rc.CPU != nil && *rc.CPU <= 0 {
+		return fmt.Errorf("runConfig.cpu is %v, want a positive number", *rc.CPU)
+	}
+	if rc.MinInstances != nil && rc.MaxInstances != nil && *rc.MinInstances > *rc.MaxInstances {
+		return fmt.Errorf("runConfig.minInstances (%d) is greater than runConfig.maxInstances (%d)", *rc.MinInstances, *rc.MaxInstances)
+	}
+	return nil
+}
+
+// SetBuildEnv sets the plain values that are available to the build in the environment of the
+// buildpack, unless the environment already sets them. Secrets are resolved by App Hosting, which
+// sets them in the build environment; SetBuildEnv warns about the missing ones. Values are never
+// logged.
+func (c *Config) SetBuildEnv(ctx *gcp.Context) error {
+	if c == nil {
+		return nil
+	}
+	var set []string
+	for _, e := range c.Env {
+		if !e.availableTo(AvailabilityBuild) {
+			continue
+		}
+		if _, ok := os.LookupEnv(e.Variable); ok {
+			continue
+		}
+		if e.Secret != "" {
+			ctx.Warnf("The secret %s of %s is available to the build but is not set in the build environment.", e.Secret, e.Variable)
+			continue
+		}
+		// Use os.Setenv rather than ctx.Setenv, which logs the value.
+		if err := os.Setenv(e.Variable, e.Value); err != nil {
+			return gcp.InternalErrorf("setting %s: %v", e.Variable, err)
+		}
+		set = append(set, e.Variable)
+	}
+	if len(set) > 0 {
+		ctx.Logf("Set build environment variables from %s: %s", ConfigFile, strings.Join(set, ", "))
+	}
+	return nil
+}
+
+// runtimeEnv returns the variables that are available to the server.
+func (c *Config) runtimeEnv() []EnvVar {
+	if c == nil {
+		return nil
+	}
+	var vars []EnvVar
+	for _, e := range c.Env {
+		if e.availableTo(AvailabilityRuntime) {
+			vars = append(vars, e)
+		}
+	}
+	return vars
+}
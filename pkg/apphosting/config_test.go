@@ -0,0 +1,279 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apphosting
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/nodejs"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadConfig(t *testing.T) {
+	testCases := []struct {
+		name     string
+		platform string
+		config   string
+		// configDir is the directory of apphosting.yaml relative to the application root.
+		configDir string
+		// workspace is the directory of the selected workspace of a monorepo.
+		workspace string
+		want      *Config
+		wantErr   string
+	}{
+		{
+			name:     "valid",
+			platform: env.TargetPlatformAppHosting,
+			config: `
+runConfig:
+  cpu: 2
+  memoryMiB: 1024
+  minInstances: 1
+  maxInstances: 4
+env:
+  - variable: API_URL
+    value: https://api.example.com
+  - variable: API_KEY
+    secret: apiKey
+    availability:
+      - RUNTIME
+  - variable: NEXT_PUBLIC_MAPS_KEY
+    secret: mapsKey
+    availability: [BUILD, RUNTIME]
+`,
+			want: &Config{
+				RunConfig: ServiceConfig{CPU: floatPtr(2), MemoryMiB: intPtr(1024), MinInstances: intPtr(1), MaxInstances: intPtr(4)},
+				Env: []EnvVar{
+					{Variable: "API_URL", Value: "https://api.example.com"},
+					{Variable: "API_KEY", Secret: "apiKey", Availability: []string{"RUNTIME"}},
+					{Variable: "NEXT_PUBLIC_MAPS_KEY", Secret: "mapsKey", Availability: []string{"BUILD", "RUNTIME"}},
+				},
+			},
+		},
+		{
+			name:      "workspace",
+			platform:  env.TargetPlatformAppHosting,
+			config:    `env: [{variable: API_URL, value: https://api.example.com}]`,
+			configDir: "apps/web",
+			workspace: "apps/web",
+			want:      &Config{Env: []EnvVar{{Variable: "API_URL", Value: "https://api.example.com"}}},
+		},
+		{
+			name:      "application root of workspace",
+			platform:  env.TargetPlatformAppHosting,
+			config:    `env: [{variable: API_URL, value: https://api.example.com}]`,
+			workspace: "apps/web",
+			want:      &Config{Env: []EnvVar{{Variable: "API_URL", Value: "https://api.example.com"}}},
+		},
+		{
+			name:      "other workspace",
+			platform:  env.TargetPlatformAppHosting,
+			config:    `env: [{variable: API_URL, value: https://api.example.com}]`,
+			configDir: "apps/admin",
+			workspace: "apps/web",
+		},
+		{
+			name:     "not App Hosting",
+			platform: env.TargetPlatformAppEngine,
+			config:   `env: [{variable: PORT, value: "8080"}]`,
+		},
+		{
+			name:     "no config",
+			platform: env.TargetPlatformAppHosting,
+		},
+		{
+			name:     "malformed yaml",
+			platform: env.TargetPlatformAppHosting,
+			config:   "env:\n  variable: A\n",
+			wantErr:  "parsing apphosting.yaml",
+		},
+		{
+			name:     "invalid name",
+			platform: env.TargetPlatformAppHosting,
+			config:   `env: [{variable: 1ST, value: a}]`,
+			wantErr:  `"1ST" is not a valid environment variable name`,
+		},
+		{
+			name:     "reserved name",
+			platform: env.TargetPlatformAppHosting,
+			config:   `env: [{variable: PORT, value: "8080"}]`,
+			wantErr:  "PORT is reserved",
+		},
+		{
+			name:     "reserved prefix",
+			platform: env.TargetPlatformAppHosting,
+			config:   `env: [{variable: X_GOOGLE_TARGET_PLATFORM, value: gcp}]`,
+			wantErr:  "reserved prefix X_GOOGLE_",
+		},
+		{
+			name:     "duplicate",
+			platform: env.TargetPlatformAppHosting,
+			config:   `env: [{variable: A, value: a}, {variable: A, secret: b}]`,
+			wantErr:  "A is declared more than once",
+		},
+		{
+			name:     "value and secret",
+			platform: env.TargetPlatformAppHosting,
+			config:   `env: [{variable: A, value: a, secret: b}]`,
+			wantErr:  "exactly one of value and secret",
+		},
+		{
+			name:     "neither value nor secret",
+			platform: env.TargetPlatformAppHosting,
+			config:   `env: [{variable: A}]`,
+			wantErr:  "exactly one of value and secret",
+		},
+		{
+			name:     "invalid availability",
+			platform: env.TargetPlatformAppHosting,
+			config:   `env: [{variable: A, value: a, availability: [DEPLOY]}]`,
+			wantErr:  `availability "DEPLOY"`,
+		},
+		{
+			name:     "min instances greater than max",
+			platform: env.TargetPlatformAppHosting,
+			config:   "runConfig: {minInstances: 4, maxInstances: 2}",
+			wantErr:  "greater than runConfig.maxInstances",
+		},
+		{
+			name:     "negative concurrency",
+			platform: env.TargetPlatformAppHosting,
+			config:   "runConfig: {concurrency: -1}",
+			wantErr:  "runConfig.concurrency is -1",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(env.XGoogleTargetPlatform, tc.platform)
+			dir := t.TempDir()
+			if tc.config != "" {
+				writeFiles(t, dir, map[string]string{filepath.Join(tc.configDir, ConfigFile): tc.config})
+			}
+			ctx := gcp.NewContext(gcp.WithApplicationRoot(dir))
+			var ws *nodejs.Workspace
+			if tc.workspace != "" {
+				ws = &nodejs.Workspace{Dir: tc.workspace}
+			}
+
+			got, err := ReadConfig(ctx, ws)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("ReadConfig() got error %v, want error containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadConfig() got error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ReadConfig() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSetBuildEnv(t *testing.T) {
+	t.Setenv("PRESET", "from-platform")
+	t.Setenv("RESOLVED_SECRET", "s3cr3t-resolved")
+	for _, v := range []string{"PLAIN", "RUNTIME_ONLY", "MISSING_SECRET"} {
+		v := v
+		os.Unsetenv(v)
+		t.Cleanup(func() { os.Unsetenv(v) })
+	}
+	cfg := &Config{
+		Env: []EnvVar{
+			{Variable: "PLAIN", Value: "plain-value"},
+			{Variable: "PRESET", Value: "from-config"},
+			{Variable: "RUNTIME_ONLY", Value: "runtime-value", Availability: []string{AvailabilityRuntime}},
+			{Variable: "RESOLVED_SECRET", Secret: "resolved"},
+			{Variable: "MISSING_SECRET", Secret: "missing", Availability: []string{AvailabilityBuild}},
+		},
+	}
+	var logs bytes.Buffer
+	ctx := gcp.NewContext(gcp.WithLogger(log.New(&logs, "", 0)))
+
+	if err := cfg.SetBuildEnv(ctx); err != nil {
+		t.Fatalf("SetBuildEnv() got error: %v", err)
+	}
+	want := map[string]string{
+		"PLAIN":           "plain-value",
+		"PRESET":          "from-platform",
+		"RUNTIME_ONLY":    "",
+		"RESOLVED_SECRET": "s3cr3t-resolved",
+		"MISSING_SECRET":  "",
+	}
+	for k, v := range want {
+		if got := os.Getenv(k); got != v {
+			t.Errorf("os.Getenv(%q) = %q, want %q", k, got, v)
+		}
+	}
+	if !strings.Contains(logs.String(), "MISSING_SECRET") {
+		t.Errorf("SetBuildEnv() logs do not warn about MISSING_SECRET:\n%s", logs.String())
+	}
+	for _, v := range []string{"plain-value", "s3cr3t-resolved", "from-config"} {
+		if strings.Contains(logs.String(), v) {
+			t.Errorf("SetBuildEnv() logged the value %q:\n%s", v, logs.String())
+		}
+	}
+}
+
+func TestBuildWritesConfig(t *testing.T) {
+	t.Setenv(env.XGoogleTargetPlatform, env.TargetPlatformAppHosting)
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		ConfigFile: `
+runConfig:
+  concurrency: 80
+env:
+  - variable: BUILD_ONLY
+    value: b
+    availability: [BUILD]
+  - variable: API_KEY
+    secret: apiKey
+`,
+	})
+	ctx := gcp.NewContext(gcp.WithApplicationRoot(dir))
+	f, err := DetectFramework(ctx, readPackageJSON(t, `{"dependencies": {"astro": "^4.5.0"}, "scripts": {"build": "astro build"}}`), nil)
+	if err != nil || f == nil {
+		t.Fatalf("DetectFramework() = %v, %v, want Astro", f, err)
+	}
+	pm := PackageManager{Run: []string{"sh", "-c", "mkdir -p dist && touch dist/index.html"}}
+
+	if _, err := f.Build(ctx, pm); err != nil {
+		t.Fatalf("Build() got error: %v", err)
+	}
+	want := RunConfig{
+		EnvironmentVariables: []EnvVar{{Variable: "API_KEY", Secret: "apiKey"}},
+		ServiceConfig:        ServiceConfig{Concurrency: intPtr(80)},
+	}
+	if diff := cmp.Diff(want, readBundle(t, dir).RunConfig); diff != "" {
+		t.Errorf("bundle runConfig mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
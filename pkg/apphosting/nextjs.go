@@ -0,0 +1,184 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apphosting
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/nodejs"
+)
+
+var nextjsAdapter = &adapter{
+	framework:   "nextjs",
+	displayName: "Next.js",
+	packageName: "next",
+	minVersion:  "13.4",
+	buildCmd:    []string{"next", "build"},
+	// Makes `next build` emit a standalone server with only the files it needs, as if
+	// `output: 'standalone'` was set in next.config.js.
+	buildEnv: []string{"NEXT_PRIVATE_STANDALONE=true"},
+	// The standalone server listens on $HOSTNAME, which is the container name by default.
+	launchEnv: map[string]string{"HOSTNAME": "0.0.0.0"},
+	detect: func(ctx *gcp.Context, root string, pjs *nodejs.PackageJSON) (bool, error) {
+		return hasDependency(pjs, "next"), nil
+	},
+	bundle: nextjsBundle,
+}
+
+// nextRequiredServerFiles is the subset of .next/required-server-files.json used by the adapter.
+type nextRequiredServerFiles struct {
+	Config struct {
+		BasePath string `json:"basePath"`
+		Images   struct {
+			Path            string   `json:"path"`
+			Loader          string   `json:"loader"`
+			Unoptimized     bool     `json:"unoptimized"`
+			Formats         []string `json:"formats"`
+			MinimumCacheTTL int      `json:"minimumCacheTTL"`
+		} `json:"images"`
+	} `json:"config"`
+	// RelativeAppDir is the directory of the app relative to the root of a monorepo.
+	RelativeAppDir string `json:"relativeAppDir"`
+}
+
+// readNextRequiredServerFiles reads .next/required-server-files.json. It returns an empty config if
+// the file does not exist.
+func readNextRequiredServerFiles(root string) (*nextRequiredServerFiles, error) {
+	var rsf nextRequiredServerFiles
+	raw, err := ioutil.ReadFile(filepath.Join(root, ".next", "required-server-files.json"))
+	if os.IsNotExist(err) {
+		return &rsf, nil
+	}
+	if err != nil {
+		return nil, gcp.InternalErrorf("reading required-server-files.json: %v", err)
+	}
+	if err := json.Unmarshal(raw, &rsf); err != nil {
+		return nil, gcp.InternalErrorf("parsing required-server-files.json: %v", err)
+	}
+	return &rsf, nil
+}
+
+// nextjsBundle describes the standalone server of a Next.js app, or its static export if the app
+// sets `output: 'export'`.
+func nextjsBundle(ctx *gcp.Context, root string) (*Bundle, error) {
+	rsf, err := readNextRequiredServerFiles(root)
+	if err != nil {
+		return nil, err
+	}
+	basePath := rsf.Config.BasePath
+
+	standalone, err := ctx.FileExists(root, ".next", "standalone")
+	if err != nil {
+		return nil, err
+	}
+	if !standalone {
+		return nextjsExportBundle(ctx, root, basePath)
+	}
+
+	// In a monorepo the server is nested under the path of the app relative to the monorepo root.
+	appDir := filepath.Join(".next", "standalone", rsf.RelativeAppDir)
+	serverJS := filepath.Join(appDir, "server.js")
+	exists, err := ctx.FileExists(root, serverJS)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, gcp.InternalErrorf("Next.js standalone server %s not found", serverJS)
+	}
+
+	b := &Bundle{
+		RunConfig: RunConfig{RunCommand: "node " + serverJS},
+		OutputFiles: OutputFiles{
+			ServerApp: ServerApp{Include: []string{filepath.Join(".next", "standalone")}},
+			StaticAssets: []StaticAsset{
+				{URLPath: urlPath(basePath, "_next/static"), Dir: filepath.Join(".next", "static"), Immutable: true},
+			},
+		},
+	}
+	// The standalone output does not contain the static assets, copy them next to the server so it
+	// can serve them too.
+	if err := copyDir(ctx, filepath.Join(root, ".next", "static"), filepath.Join(root, appDir, ".next", "static")); err != nil {
+		return nil, err
+	}
+	public, err := ctx.FileExists(root, "public")
+	if err != nil {
+		return nil, err
+	}
+	if public {
+		if err := copyDir(ctx, filepath.Join(root, "public"), filepath.Join(root, appDir, "public")); err != nil {
+			return nil, err
+		}
+		b.OutputFiles.StaticAssets = append(b.OutputFiles.StaticAssets, StaticAsset{URLPath: urlPath(basePath, ""), Dir: "public"})
+	}
+
+	images, err := nextjsImages(ctx, root, rsf, appDir)
+	if err != nil {
+		return nil, err
+	}
+	b.Images = images
+	return b, nil
+}
+
+// nextjsExportBundle describes the static export of a Next.js app in the out directory.
+func nextjsExportBundle(ctx *gcp.Context, root, basePath string) (*Bundle, error) {
+	exists, err := ctx.FileExists(root, "out")
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, gcp.UserErrorf("the Next.js build produced neither a standalone server in .next/standalone nor a static export in out, check the output setting of next.config.js")
+	}
+	return &Bundle{
+		OutputFiles: OutputFiles{
+			StaticAssets: []StaticAsset{
+				{URLPath: urlPath(basePath, "_next/static"), Dir: filepath.Join("out", "_next", "static"), Immutable: true},
+				{URLPath: urlPath(basePath, ""), Dir: "out"},
+			},
+		},
+	}, nil
+}
+
+// nextjsImages returns the image optimization config of the app. The default loader optimizes
+// images in the server, which needs the sharp package in standalone mode.
+func nextjsImages(ctx *gcp.Context, root string, rsf *nextRequiredServerFiles, appDir string) (*ImageConfig, error) {
+	img := rsf.Config.Images
+	if img.Path == "" && img.Loader == "" {
+		return nil, nil
+	}
+	if img.Loader == "default" && !img.Unoptimized {
+		sharp := false
+		for _, dir := range []string{appDir, filepath.Join(".next", "standalone"), "."} {
+			exists, err := ctx.FileExists(root, dir, "node_modules", "sharp")
+			if err != nil {
+				return nil, err
+			}
+			sharp = sharp || exists
+		}
+		if !sharp {
+			ctx.Warnf("Next.js image optimization uses the sharp package in production, add it to the dependencies of the app or set images.unoptimized in next.config.js.")
+		}
+	}
+	return &ImageConfig{
+		Path:            img.Path,
+		Loader:          img.Loader,
+		Unoptimized:     img.Unoptimized,
+		Formats:         img.Formats,
+		MinimumCacheTTL: img.MinimumCacheTTL,
+	}, nil
+}
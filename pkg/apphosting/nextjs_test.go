@@ -0,0 +1,131 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apphosting
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNextjsBundle(t *testing.T) {
+	testCases := []struct {
+		name      string
+		files     map[string]string
+		want      *Bundle
+		wantFiles []string
+		wantErr   bool
+	}{
+		{
+			name: "standalone",
+			files: map[string]string{
+				".next/standalone/server.js":         "",
+				".next/static/chunks/main-abc.js":    "",
+				"public/favicon.ico":                 "",
+				".next/required-server-files.json":   `{"config": {"basePath": "", "images": {"path": "/_next/image", "loader": "default", "formats": ["image/webp"], "minimumCacheTTL": 60}}}`,
+				"node_modules/sharp/package.json":    "{}",
+				".next/standalone/node_modules/x.js": "",
+			},
+			want: &Bundle{
+				RunConfig: RunConfig{RunCommand: "node .next/standalone/server.js"},
+				OutputFiles: OutputFiles{
+					ServerApp: ServerApp{Include: []string{".next/standalone"}},
+					StaticAssets: []StaticAsset{
+						{URLPath: "/_next/static", Dir: ".next/static", Immutable: true},
+						{URLPath: "/", Dir: "public"},
+					},
+				},
+				Images: &ImageConfig{Path: "/_next/image", Loader: "default", Formats: []string{"image/webp"}, MinimumCacheTTL: 60},
+			},
+			wantFiles: []string{
+				".next/standalone/.next/static/chunks/main-abc.js",
+				".next/standalone/public/favicon.ico",
+			},
+		},
+		{
+			name: "standalone in monorepo with base path",
+			files: map[string]string{
+				".next/standalone/apps/web/server.js": "",
+				".next/static/css/app.css":            "",
+				".next/required-server-files.json":    `{"relativeAppDir": "apps/web", "config": {"basePath": "/docs", "images": {"path": "/docs/_next/image", "loader": "custom", "unoptimized": true}}}`,
+			},
+			want: &Bundle{
+				RunConfig: RunConfig{RunCommand: "node .next/standalone/apps/web/server.js"},
+				OutputFiles: OutputFiles{
+					ServerApp: ServerApp{Include: []string{".next/standalone"}},
+					StaticAssets: []StaticAsset{
+						{URLPath: "/docs/_next/static", Dir: ".next/static", Immutable: true},
+					},
+				},
+				Images: &ImageConfig{Path: "/docs/_next/image", Loader: "custom", Unoptimized: true},
+			},
+			wantFiles: []string{".next/standalone/apps/web/.next/static/css/app.css"},
+		},
+		{
+			name: "static export",
+			files: map[string]string{
+				"out/index.html":           "",
+				"out/_next/static/main.js": "",
+			},
+			want: &Bundle{
+				OutputFiles: OutputFiles{
+					StaticAssets: []StaticAsset{
+						{URLPath: "/_next/static", Dir: "out/_next/static", Immutable: true},
+						{URLPath: "/", Dir: "out"},
+					},
+				},
+			},
+		},
+		{
+			name:    "no output",
+			files:   map[string]string{".next/BUILD_ID": "abc"},
+			wantErr: true,
+		},
+		{
+			name:    "standalone without server",
+			files:   map[string]string{".next/standalone/package.json": "{}"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tc.files)
+			ctx := gcp.NewContext(gcp.WithApplicationRoot(dir))
+
+			got, err := nextjsBundle(ctx, dir)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("nextjsBundle() got no error, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("nextjsBundle() got error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("nextjsBundle() mismatch (-want +got):\n%s", diff)
+			}
+			for _, f := range tc.wantFiles {
+				if _, err := os.Stat(filepath.Join(dir, f)); err != nil {
+					t.Errorf("%s was not copied: %v", f, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apphosting
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/nodejs"
+)
+
+// nuxtOutputDir is the output directory of Nitro, the server engine of Nuxt.
+const nuxtOutputDir = ".output"
+
+var nuxtAdapter = &adapter{
+	framework:   "nuxt",
+	displayName: "Nuxt",
+	packageName: "nuxt",
+	minVersion:  "3.0",
+	buildCmd:    []string{"nuxt", "build"},
+	buildEnv:    []string{"NITRO_PRESET=node-server"},
+	detect: func(ctx *gcp.Context, root string, pjs *nodejs.PackageJSON) (bool, error) {
+		return hasDependency(pjs, "nuxt"), nil
+	},
+	bundle: nuxtBundle,
+}
+
+// nitroRuntimeConfig is the subset of the runtime config inlined in the Nitro server used by the
+// adapter.
+type nitroRuntimeConfig struct {
+	App struct {
+		BaseURL        string `json:"baseURL"`
+		BuildAssetsDir string `json:"buildAssetsDir"`
+	} `json:"app"`
+	Nitro struct {
+		RouteRules map[string]struct {
+			Headers  map[string]string `json:"headers"`
+			Redirect *struct {
+				To         string `json:"to"`
+				StatusCode int    `json:"statusCode"`
+			} `json:"redirect"`
+			Prerender bool `json:"prerender"`
+		} `json:"routeRules"`
+	} `json:"nitro"`
+}
+
+// nuxtBundle describes the Nitro server and the public files of a Nuxt app, or only its public files
+// if the app was generated as a static site.
+func nuxtBundle(ctx *gcp.Context, root string) (*Bundle, error) {
+	public := filepath.Join(nuxtOutputDir, "public")
+	exists, err := ctx.FileExists(root, public)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, gcp.UserErrorf("the Nuxt build did not produce %s, check that the build script runs nuxt build or nuxt generate", public)
+	}
+
+	serverEntry := filepath.Join(nuxtOutputDir, "server", "index.mjs")
+	server, err := ctx.FileExists(root, serverEntry)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &nitroRuntimeConfig{}
+	if server {
+		if cfg, err = readNitroRuntimeConfig(filepath.Join(root, nuxtOutputDir, "server")); err != nil {
+			return nil, err
+		}
+	}
+	baseURL, assetsDir := cfg.App.BaseURL, cfg.App.BuildAssetsDir
+	if assetsDir == "" {
+		assetsDir = "/_nuxt/"
+	}
+
+	b := &Bundle{
+		OutputFiles: OutputFiles{
+			StaticAssets: []StaticAsset{
+				{URLPath: urlPath(baseURL, assetsDir), Dir: filepath.Join(public, assetsDir), Immutable: true},
+				{URLPath: urlPath(baseURL, ""), Dir: public},
+			},
+		},
+	}
+	if !server {
+		return b, nil
+	}
+	b.RunConfig.RunCommand = "node " + serverEntry
+	b.OutputFiles.ServerApp.Include = []string{nuxtOutputDir}
+	for p, r := range cfg.Nitro.RouteRules {
+		rule := RouteRule{Path: p, Headers: r.Headers, Prerender: r.Prerender}
+		if r.Redirect != nil {
+			rule.Redirect = &Redirect{To: r.Redirect.To, StatusCode: r.Redirect.StatusCode}
+		}
+		b.RouteRules = append(b.RouteRules, rule)
+	}
+	sort.Slice(b.RouteRules, func(i, j int) bool { return b.RouteRules[i].Path < b.RouteRules[j].Path })
+	return b, nil
+}
+
+// readNitroRuntimeConfig reads the runtime config that Nitro inlines as JSON in one of the chunks of
+// the server. It returns an empty config if no chunk contains it.
+func readNitroRuntimeConfig(serverDir string) (*nitroRuntimeConfig, error) {
+	const marker = "_inlineRuntimeConfig = "
+	var cfg nitroRuntimeConfig
+	found := false
+	err := filepath.Walk(serverDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || found {
+			return err
+		}
+		if info.IsDir() {
+			if path != serverDir && info.Name() == "node_modules" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".mjs") {
+			return nil
+		}
+		src, err := ioutil.ReadFile(path)
+		if err != nil {
+			return gcp.InternalErrorf("reading %s: %v", path, err)
+		}
+		i := bytes.Index(src, []byte(marker))
+		if i < 0 {
+			return nil
+		}
+		if err := json.NewDecoder(bytes.NewReader(src[i+len(marker):])).Decode(&cfg); err != nil {
+			return gcp.InternalErrorf("parsing the Nitro runtime config in %s: %v", path, err)
+		}
+		found = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apphosting
+
+import (
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNuxtBundle(t *testing.T) {
+	testCases := []struct {
+		name    string
+		files   map[string]string
+		want    *Bundle
+		wantErr bool
+	}{
+		{
+			name: "server with route rules",
+			files: map[string]string{
+				".output/server/index.mjs": `import './chunks/runtime.mjs';`,
+				".output/server/chunks/runtime.mjs": `const _inlineRuntimeConfig = {"app":{"baseURL":"/shop/","buildAssetsDir":"/_assets/","cdnURL":""},` +
+					`"nitro":{"routeRules":{"/old":{"redirect":{"to":"/new","statusCode":301}},"/api/**":{"headers":{"cache-control":"no-store"}},"/about":{"prerender":true}}},` +
+					`"public":{},"apiSecret":"s3cr3t"};
+const config = useRuntimeConfig();`,
+				".output/public/favicon.ico": "",
+			},
+			want: &Bundle{
+				RunConfig: RunConfig{RunCommand: "node .output/server/index.mjs"},
+				OutputFiles: OutputFiles{
+					ServerApp: ServerApp{Include: []string{".output"}},
+					StaticAssets: []StaticAsset{
+						{URLPath: "/shop/_assets", Dir: ".output/public/_assets", Immutable: true},
+						{URLPath: "/shop", Dir: ".output/public"},
+					},
+				},
+				RouteRules: []RouteRule{
+					{Path: "/about", Prerender: true},
+					{Path: "/api/**", Headers: map[string]string{"cache-control": "no-store"}},
+					{Path: "/old", Redirect: &Redirect{To: "/new", StatusCode: 301}},
+				},
+			},
+		},
+		{
+			name: "server without inlined config",
+			files: map[string]string{
+				".output/server/index.mjs":   "",
+				".output/public/favicon.ico": "",
+			},
+			want: &Bundle{
+				RunConfig: RunConfig{RunCommand: "node .output/server/index.mjs"},
+				OutputFiles: OutputFiles{
+					ServerApp: ServerApp{Include: []string{".output"}},
+					StaticAssets: []StaticAsset{
+						{URLPath: "/_nuxt", Dir: ".output/public/_nuxt", Immutable: true},
+						{URLPath: "/", Dir: ".output/public"},
+					},
+				},
+			},
+		},
+		{
+			name: "generated static site",
+			files: map[string]string{
+				".output/public/index.html": "",
+			},
+			want: &Bundle{
+				OutputFiles: OutputFiles{
+					StaticAssets: []StaticAsset{
+						{URLPath: "/_nuxt", Dir: ".output/public/_nuxt", Immutable: true},
+						{URLPath: "/", Dir: ".output/public"},
+					},
+				},
+			},
+		},
+		{
+			name: "malformed runtime config",
+			files: map[string]string{
+				".output/server/index.mjs":   `const _inlineRuntimeConfig = {"app": {`,
+				".output/public/favicon.ico": "",
+			},
+			wantErr: true,
+		},
+		{
+			name:    "no output",
+			files:   map[string]string{".nuxt/nuxt.d.ts": ""},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tc.files)
+			ctx := gcp.NewContext(gcp.WithApplicationRoot(dir))
+
+			got, err := nuxtBundle(ctx, dir)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("nuxtBundle() got no error, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("nuxtBundle() got error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("nuxtBundle() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apphosting
+
+import (
+	"path/filepath"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/nodejs"
+)
+
+var (
+	reactRouterAdapter = &adapter{
+		framework:   "react-router",
+		displayName: "React Router",
+		packageName: "@react-router/dev",
+		minVersion:  "7.0",
+		buildCmd:    []string{"react-router", "build"},
+		detect: func(ctx *gcp.Context, root string, pjs *nodejs.PackageJSON) (bool, error) {
+			return hasDependency(pjs, "@react-router/dev"), nil
+		},
+		bundle: func(ctx *gcp.Context, root string) (*Bundle, error) {
+			return remixBundle(ctx, root, "React Router", "react-router-serve")
+		},
+	}
+
+	remixAdapter = &adapter{
+		framework:   "remix",
+		displayName: "Remix",
+		packageName: "@remix-run/dev",
+		minVersion:  "2.0",
+		buildCmd:    []string{"remix", "vite:build"},
+		detect: func(ctx *gcp.Context, root string, pjs *nodejs.PackageJSON) (bool, error) {
+			return hasDependency(pjs, "@remix-run/dev"), nil
+		},
+		bundle: func(ctx *gcp.Context, root string) (*Bundle, error) {
+			return remixBundle(ctx, root, "Remix", "remix-serve")
+		},
+	}
+)
+
+// remixBundle describes the server build and the client assets of a Remix or React Router app. The
+// server build runs with the serve CLI of the framework, or with the start script of package.json
+// if the app has its own server. Apps built with the Vite plugin output the server build to
+// build/server and the client to build/client, apps built with the classic Remix compiler output
+// the server build to build and the client to public/build.
+func remixBundle(ctx *gcp.Context, root, name, serveCLI string) (*Bundle, error) {
+	b := &Bundle{}
+	serverBuild := filepath.Join("build", "server", "index.js")
+	vite, err := ctx.FileExists(root, serverBuild)
+	if err != nil {
+		return nil, err
+	}
+	if vite {
+		client := filepath.Join("build", "client")
+		b.OutputFiles.StaticAssets = []StaticAsset{
+			{URLPath: "/assets", Dir: filepath.Join(client, "assets"), Immutable: true},
+			{URLPath: "/", Dir: client},
+		}
+	} else {
+		serverBuild = filepath.Join("build", "index.js")
+		classic, err := ctx.FileExists(root, serverBuild)
+		if err != nil {
+			return nil, err
+		}
+		if !classic {
+			return nil, gcp.UserErrorf("the %s build did not produce a server build in build/server/index.js or build/index.js, App Hosting supports the default build directory", name)
+		}
+		b.OutputFiles.StaticAssets = []StaticAsset{
+			{URLPath: "/build", Dir: filepath.Join("public", "build"), Immutable: true},
+			{URLPath: "/", Dir: "public"},
+		}
+	}
+	b.OutputFiles.ServerApp.Include = []string{"build", "node_modules", "package.json"}
+
+	cli := filepath.Join("node_modules", ".bin", serveCLI)
+	serve, err := ctx.FileExists(root, cli)
+	if err != nil {
+		return nil, err
+	}
+	if serve {
+		b.RunConfig.RunCommand = cli + " " + serverBuild
+		return b, nil
+	}
+	pjs, err := nodejs.ReadPackageJSONIfExists(root)
+	if err != nil {
+		return nil, err
+	}
+	if pjs == nil || pjs.Scripts.Start == "" {
+		return nil, gcp.UserErrorf("the %s app has neither the %s CLI nor a start script, add the serve package of %s to the dependencies or a start script that runs the server", name, serveCLI, name)
+	}
+	b.RunConfig.RunCommand = "npm run start"
+	return b, nil
+}
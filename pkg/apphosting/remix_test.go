@@ -0,0 +1,126 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apphosting
+
+import (
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRemixBundle(t *testing.T) {
+	testCases := []struct {
+		name     string
+		serveCLI string
+		files    map[string]string
+		want     *Bundle
+		wantErr  bool
+	}{
+		{
+			name:     "react router",
+			serveCLI: "react-router-serve",
+			files: map[string]string{
+				"build/server/index.js":                "",
+				"build/client/assets/root-abc.js":      "",
+				"node_modules/.bin/react-router-serve": "",
+			},
+			want: &Bundle{
+				RunConfig: RunConfig{RunCommand: "node_modules/.bin/react-router-serve build/server/index.js"},
+				OutputFiles: OutputFiles{
+					ServerApp: ServerApp{Include: []string{"build", "node_modules", "package.json"}},
+					StaticAssets: []StaticAsset{
+						{URLPath: "/assets", Dir: "build/client/assets", Immutable: true},
+						{URLPath: "/", Dir: "build/client"},
+					},
+				},
+			},
+		},
+		{
+			name:     "remix classic compiler",
+			serveCLI: "remix-serve",
+			files: map[string]string{
+				"build/index.js":                  "",
+				"public/build/root-abc.js":        "",
+				"node_modules/.bin/remix-serve":   "",
+				"node_modules/@remix-run/serve/x": "",
+			},
+			want: &Bundle{
+				RunConfig: RunConfig{RunCommand: "node_modules/.bin/remix-serve build/index.js"},
+				OutputFiles: OutputFiles{
+					ServerApp: ServerApp{Include: []string{"build", "node_modules", "package.json"}},
+					StaticAssets: []StaticAsset{
+						{URLPath: "/build", Dir: "public/build", Immutable: true},
+						{URLPath: "/", Dir: "public"},
+					},
+				},
+			},
+		},
+		{
+			name:     "custom server",
+			serveCLI: "remix-serve",
+			files: map[string]string{
+				"build/server/index.js": "",
+				"package.json":          `{"scripts": {"start": "node ./server.js"}}`,
+			},
+			want: &Bundle{
+				RunConfig: RunConfig{RunCommand: "npm run start"},
+				OutputFiles: OutputFiles{
+					ServerApp: ServerApp{Include: []string{"build", "node_modules", "package.json"}},
+					StaticAssets: []StaticAsset{
+						{URLPath: "/assets", Dir: "build/client/assets", Immutable: true},
+						{URLPath: "/", Dir: "build/client"},
+					},
+				},
+			},
+		},
+		{
+			name:     "no server",
+			serveCLI: "remix-serve",
+			files: map[string]string{
+				"build/server/index.js": "",
+				"package.json":          `{}`,
+			},
+			wantErr: true,
+		},
+		{
+			name:     "no output",
+			serveCLI: "remix-serve",
+			files:    map[string]string{"app/root.tsx": ""},
+			wantErr:  true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tc.files)
+			ctx := gcp.NewContext(gcp.WithApplicationRoot(dir))
+
+			got, err := remixBundle(ctx, dir, "Remix", tc.serveCLI)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("remixBundle() got no error, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("remixBundle() got error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("remixBundle() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
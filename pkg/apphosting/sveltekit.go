@@ -0,0 +1,128 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apphosting
+
+import (
+	"path/filepath"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/nodejs"
+)
+
+const (
+	svelteKitNodeAdapter   = "@sveltejs/adapter-node"
+	svelteKitStaticAdapter = "@sveltejs/adapter-static"
+	// svelteKitOutputDir is the default output directory of both adapters.
+	svelteKitOutputDir = "build"
+)
+
+var svelteKitAdapter = &adapter{
+	framework:   "sveltekit",
+	displayName: "SvelteKit",
+	packageName: "@sveltejs/kit",
+	minVersion:  "1.0",
+	buildCmd:    []string{"vite", "build"},
+	// The adapter-node server listens on $HOST and $PORT, and builds the URL of the requests from
+	// the headers set by the App Hosting load balancer unless $ORIGIN is set.
+	launchEnvDefaults: map[string]string{
+		"HOST":            "0.0.0.0",
+		"PROTOCOL_HEADER": "x-forwarded-proto",
+		"HOST_HEADER":     "x-forwarded-host",
+	},
+	detect: func(ctx *gcp.Context, root string, pjs *nodejs.PackageJSON) (bool, error) {
+		if !hasDependency(pjs, "@sveltejs/kit") {
+			return false, nil
+		}
+		if !hasDependency(pjs, svelteKitNodeAdapter) && !hasDependency(pjs, svelteKitStaticAdapter) {
+			return false, gcp.UserErrorf("SvelteKit apps are built for App Hosting with %s, add it to the devDependencies and set it as the adapter in svelte.config.js", svelteKitNodeAdapter)
+		}
+		return true, nil
+	},
+	bundle: svelteKitBundle,
+}
+
+// svelteKitBundle describes the adapter-node server and the client files of a SvelteKit app, or
+// the pages of an app built with adapter-static.
+func svelteKitBundle(ctx *gcp.Context, root string) (*Bundle, error) {
+	serverEntry := filepath.Join(svelteKitOutputDir, "index.js")
+	server, err := ctx.FileExists(root, serverEntry)
+	if err != nil {
+		return nil, err
+	}
+	if !server {
+		return svelteKitStaticBundle(ctx, root)
+	}
+
+	client := filepath.Join(svelteKitOutputDir, "client")
+	b := &Bundle{
+		RunConfig: RunConfig{RunCommand: "node " + serverEntry},
+		OutputFiles: OutputFiles{
+			ServerApp: ServerApp{Include: []string{svelteKitOutputDir, "node_modules", "package.json"}},
+		},
+	}
+	immutable, err := svelteKitImmutableAssets(ctx, root, client)
+	if err != nil {
+		return nil, err
+	}
+	b.OutputFiles.StaticAssets = append(immutable, StaticAsset{URLPath: "/", Dir: client})
+	prerendered := filepath.Join(svelteKitOutputDir, "prerendered")
+	exists, err := ctx.FileExists(root, prerendered)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		b.OutputFiles.StaticAssets = append(b.OutputFiles.StaticAssets, StaticAsset{URLPath: "/", Dir: prerendered})
+	}
+	return b, nil
+}
+
+// svelteKitStaticBundle describes the pages of an app built with adapter-static.
+func svelteKitStaticBundle(ctx *gcp.Context, root string) (*Bundle, error) {
+	exists, err := ctx.FileExists(root, svelteKitOutputDir)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, gcp.UserErrorf("the SvelteKit build did not produce %s, App Hosting supports the default output directory of %s and %s", svelteKitOutputDir, svelteKitNodeAdapter, svelteKitStaticAdapter)
+	}
+	immutable, err := svelteKitImmutableAssets(ctx, root, svelteKitOutputDir)
+	if err != nil {
+		return nil, err
+	}
+	return &Bundle{
+		OutputFiles: OutputFiles{
+			StaticAssets: append(immutable, StaticAsset{URLPath: "/", Dir: svelteKitOutputDir}),
+		},
+	}, nil
+}
+
+// svelteKitImmutableAssets returns the immutable directory of the client files, whose parent is
+// the appDir of svelte.config.js (_app by default).
+func svelteKitImmutableAssets(ctx *gcp.Context, root, dir string) ([]StaticAsset, error) {
+	matches, err := filepath.Glob(filepath.Join(root, dir, "*", "immutable"))
+	if err != nil {
+		return nil, gcp.InternalErrorf("finding the immutable SvelteKit assets: %v", err)
+	}
+	var assets []StaticAsset
+	for _, m := range matches {
+		appDir := filepath.Base(filepath.Dir(m))
+		assets = append(assets, StaticAsset{
+			URLPath:   urlPath("/", appDir+"/immutable"),
+			Dir:       filepath.Join(dir, appDir, "immutable"),
+			Immutable: true,
+		})
+	}
+	return assets, nil
+}
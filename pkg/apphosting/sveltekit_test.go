@@ -0,0 +1,114 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apphosting
+
+import (
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSvelteKitBundle(t *testing.T) {
+	testCases := []struct {
+		name    string
+		files   map[string]string
+		want    *Bundle
+		wantErr bool
+	}{
+		{
+			name: "adapter-node",
+			files: map[string]string{
+				"build/index.js":                            "",
+				"build/handler.js":                          "",
+				"build/client/_app/immutable/entry/app.js":  "",
+				"build/client/favicon.png":                  "",
+				"build/prerendered/about.html":              "",
+				"build/server/manifest.js":                  "",
+				"node_modules/@sveltejs/kit/package.json":   "{}",
+				"node_modules/@sveltejs/adapter-node/index": "",
+			},
+			want: &Bundle{
+				RunConfig: RunConfig{RunCommand: "node build/index.js"},
+				OutputFiles: OutputFiles{
+					ServerApp: ServerApp{Include: []string{"build", "node_modules", "package.json"}},
+					StaticAssets: []StaticAsset{
+						{URLPath: "/_app/immutable", Dir: "build/client/_app/immutable", Immutable: true},
+						{URLPath: "/", Dir: "build/client"},
+						{URLPath: "/", Dir: "build/prerendered"},
+					},
+				},
+			},
+		},
+		{
+			name: "adapter-node with custom app dir",
+			files: map[string]string{
+				"build/index.js": "",
+				"build/client/assets/immutable/chunks/index.js": "",
+			},
+			want: &Bundle{
+				RunConfig: RunConfig{RunCommand: "node build/index.js"},
+				OutputFiles: OutputFiles{
+					ServerApp: ServerApp{Include: []string{"build", "node_modules", "package.json"}},
+					StaticAssets: []StaticAsset{
+						{URLPath: "/assets/immutable", Dir: "build/client/assets/immutable", Immutable: true},
+						{URLPath: "/", Dir: "build/client"},
+					},
+				},
+			},
+		},
+		{
+			name: "adapter-static",
+			files: map[string]string{
+				"build/index.html":                  "",
+				"build/_app/immutable/entry/app.js": "",
+			},
+			want: &Bundle{
+				OutputFiles: OutputFiles{
+					StaticAssets: []StaticAsset{
+						{URLPath: "/_app/immutable", Dir: "build/_app/immutable", Immutable: true},
+						{URLPath: "/", Dir: "build"},
+					},
+				},
+			},
+		},
+		{
+			name:    "no output",
+			files:   map[string]string{".svelte-kit/output/client/index.js": ""},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tc.files)
+			ctx := gcp.NewContext(gcp.WithApplicationRoot(dir))
+
+			got, err := svelteKitBundle(ctx, dir)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("svelteKitBundle() got no error, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("svelteKitBundle() got error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("svelteKitBundle() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -38,8 +38,10 @@ const (
 )
 
 var (
-	npmRegistryURLRegexp = `https:(//[a-zA-Z0-9-]+[-]npm[.]pkg[.]dev/.*/)`
-	npmRegistryRegexp    = regexp.MustCompile(`(@[a-zA-Z0-9-]+:)?registry=` + npmRegistryURLRegexp)
+	npmRegistryURLRegexp  = `https:(//[a-zA-Z0-9-]+[-]npm[.]pkg[.]dev/.*/)`
+	npmRegistryRegexp     = regexp.MustCompile(`(@[a-zA-Z0-9-]+:)?registry=` + npmRegistryURLRegexp)
+	mavenRepositoryRegexp = regexp.MustCompile(`^https://[a-zA-Z0-9-]+[-]maven[.]pkg[.]dev/`)
+	nugetRepositoryRegexp = regexp.MustCompile(`^https://[a-zA-Z0-9-]+[-]nuget[.]pkg[.]dev/`)
 )
 
 // locations is a list of AR regional endpoints.
@@ -266,6 +268,42 @@ func writeNpmConfig(wr io.Writer, repos []string, tok string) error {
 	return nil
 }
 
+// IsMavenRepository returns true if the URL is the HTTPS URL of an Artifact Registry Maven
+// repository. Repositories that use the artifactregistry:// scheme are authenticated by the
+// Artifact Registry Maven wagon instead.
+func IsMavenRepository(url string) bool {
+	return mavenRepositoryRegexp.MatchString(url)
+}
+
+// MavenCredentials returns the username and password for Maven to make authenticated requests to
+// Artifact Registry with Application Default Credentials (see
+// https://cloud.google.com/artifact-registry/docs/java/authentication#token).
+func MavenCredentials() (string, string, error) {
+	tok, err := findDefaultCredentials()
+	if err != nil {
+		return "", "", err
+	}
+	buildermetrics.GlobalBuilderMetrics().GetCounter(buildermetrics.ArMavenCredsGenCounterID).Increment(1)
+	return "oauth2accesstoken", tok, nil
+}
+
+// IsNuGetRepository returns true if the URL is the HTTPS URL of an Artifact Registry NuGet
+// repository.
+func IsNuGetRepository(url string) bool {
+	return nugetRepositoryRegexp.MatchString(url)
+}
+
+// NuGetCredentials returns the username and password for NuGet to make authenticated requests to
+// Artifact Registry with Application Default Credentials.
+func NuGetCredentials() (string, string, error) {
+	tok, err := findDefaultCredentials()
+	if err != nil {
+		return "", "", err
+	}
+	buildermetrics.GlobalBuilderMetrics().GetCounter(buildermetrics.ArNuGetCredsGenCounterID).Increment(1)
+	return "oauth2accesstoken", tok, nil
+}
+
 // findDefaultCredentials searches for "Application Default Credentials" using the google/oauth
 // package (see https://cloud.google.com/docs/authentication/production#automatically).
 var findDefaultCredentials = func() (string, error) {
@@ -520,3 +520,42 @@ func TestGenerateYarnConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestIsMavenRepository(t *testing.T) {
+	testCases := []struct {
+		url  string
+		want bool
+	}{
+		{url: "https://us-central1-maven.pkg.dev/my-project/my-repo", want: true},
+		{url: "https://europe-maven.pkg.dev/my-project/my-repo/", want: true},
+		{url: "artifactregistry://us-central1-maven.pkg.dev/my-project/my-repo", want: false},
+		{url: "https://us-central1-npm.pkg.dev/my-project/my-repo", want: false},
+		{url: "https://repo.maven.apache.org/maven2", want: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.url, func(t *testing.T) {
+			if got := IsMavenRepository(tc.url); got != tc.want {
+				t.Errorf("IsMavenRepository(%q) = %v, want %v", tc.url, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestIsNuGetRepository(t *testing.T) {
+	testCases := []struct {
+		url  string
+		want bool
+	}{
+		{url: "https://us-central1-nuget.pkg.dev/my-project/my-repo/v3/index.json", want: true},
+		{url: "https://europe-nuget.pkg.dev/my-project/my-repo/", want: true},
+		{url: "https://us-central1-maven.pkg.dev/my-project/my-repo", want: false},
+		{url: "https://api.nuget.org/v3/index.json", want: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.url, func(t *testing.T) {
+			if got := IsNuGetRepository(tc.url); got != tc.want {
+				t.Errorf("IsNuGetRepository(%q) = %v, want %v", tc.url, got, tc.want)
+			}
+		})
+	}
+}
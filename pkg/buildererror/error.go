@@ -37,6 +37,9 @@ type Error struct {
 	Status           Status `json:"canonicalCode"`
 	ID               ID     `json:"errorId"`
 	Message          string `json:"errorMessage"`
+	// Details holds machine-readable facts about the error for the platform that runs the build,
+	// e.g. the reason of the failure and the values that caused it.
+	Details map[string]string `json:"errorDetails,omitempty"`
 }
 
 func (e *Error) Error() string {
@@ -35,6 +35,8 @@ type CounterID string
 const (
 	ArNpmCredsGenCounterID    CounterID = "1"
 	NpmGcpBuildUsageCounterID CounterID = "2"
+	ArMavenCredsGenCounterID  CounterID = "3"
+	ArNuGetCredsGenCounterID  CounterID = "4"
 )
 
 var (
@@ -47,6 +49,14 @@ var (
 			"npm_gcp_build_script_uses",
 			"The number of times the gcp-build script is used by npm developers",
 		},
+		ArMavenCredsGenCounterID: Descriptor{
+			"maven_artifact_registry_creds_generated",
+			"The number of artifact registry credentials generated for Maven",
+		},
+		ArNuGetCredsGenCounterID: Descriptor{
+			"nuget_artifact_registry_creds_generated",
+			"The number of artifact registry credentials generated for NuGet",
+		},
 	}
 )
 
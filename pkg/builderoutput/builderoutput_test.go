@@ -110,7 +110,7 @@ func TestJSON(t *testing.T) {
 	b := BuilderOutput{
 		InstalledRuntimeVersions: []string{"6.0.6"},
 		Metrics:                  bm,
-		Error:                    buildererror.Error{Status: buildererror.StatusInternal},
+		Error:                    buildererror.Error{Status: buildererror.StatusInternal, Details: map[string]string{"reason": "SOME_REASON"}},
 	}
 
 	s, err := b.JSON()
@@ -127,6 +127,9 @@ func TestJSON(t *testing.T) {
 	if want := `{"c":{"1":3}}`; !strings.Contains(string(s), want) {
 		t.Errorf(`Expected string %q not found in %s`, want, s)
 	}
+	if want := `"errorDetails":{"reason":"SOME_REASON"}`; !strings.Contains(string(s), want) {
+		t.Errorf("Expected string %q not found in %s", want, s)
+	}
 }
 
 func TestIsSystemError(t *testing.T) {
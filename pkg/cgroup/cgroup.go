@@ -0,0 +1,142 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cgroup reads the CPU and memory limits of the running container. It is intended for
+// exec.d helpers that tune application settings at launch time.
+package cgroup
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+var (
+	// root is the cgroup filesystem mount point, overridden in tests.
+	root = "/sys/fs/cgroup"
+	// meminfo is the kernel memory information file, overridden in tests.
+	meminfo = "/proc/meminfo"
+	// numCPU returns the number of CPUs visible to the process, overridden in tests.
+	numCPU = runtime.NumCPU
+)
+
+// CPUs returns the number of CPUs available to the container. A fractional CPU quota is rounded
+// up, and the number of visible CPUs is returned if the container does not have a quota.
+func CPUs() int {
+	cpus := numCPU()
+	quota, ok := cpuQuota()
+	if !ok {
+		return cpus
+	}
+	if n := int(math.Ceil(quota)); n < cpus {
+		return n
+	}
+	return cpus
+}
+
+// MemoryLimit returns the amount of memory available to the container in bytes. The total system
+// memory is returned if the container does not have a memory limit.
+func MemoryLimit() (int64, error) {
+	total, err := totalMemory()
+	if err != nil {
+		return 0, err
+	}
+	if limit, ok := memoryLimit(); ok && limit < total {
+		return limit, nil
+	}
+	return total, nil
+}
+
+// cpuQuota returns the CPU quota in number of CPUs, supporting both cgroup v2 and v1.
+func cpuQuota() (float64, bool) {
+	// cgroup v2: "<quota> <period>" or "max <period>".
+	if b, err := os.ReadFile(filepath.Join(root, "cpu.max")); err == nil {
+		fields := strings.Fields(string(b))
+		if len(fields) != 2 || fields[0] == "max" {
+			return 0, false
+		}
+		return ratio(fields[0], fields[1])
+	}
+	// cgroup v1: a quota of -1 means unlimited.
+	q, err := os.ReadFile(filepath.Join(root, "cpu", "cpu.cfs_quota_us"))
+	if err != nil {
+		return 0, false
+	}
+	p, err := os.ReadFile(filepath.Join(root, "cpu", "cpu.cfs_period_us"))
+	if err != nil {
+		return 0, false
+	}
+	return ratio(strings.TrimSpace(string(q)), strings.TrimSpace(string(p)))
+}
+
+func ratio(quota, period string) (float64, bool) {
+	q, err := strconv.ParseFloat(quota, 64)
+	if err != nil || q <= 0 {
+		return 0, false
+	}
+	p, err := strconv.ParseFloat(period, 64)
+	if err != nil || p <= 0 {
+		return 0, false
+	}
+	return q / p, true
+}
+
+// memoryLimit returns the container memory limit in bytes, supporting both cgroup v2 and v1.
+func memoryLimit() (int64, bool) {
+	for _, f := range []string{filepath.Join(root, "memory.max"), filepath.Join(root, "memory", "memory.limit_in_bytes")} {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		// cgroup v1 reports a very large number instead of "max"; callers compare the result with
+		// the total system memory.
+		limit, err := strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+		if err != nil || limit <= 0 {
+			return 0, false
+		}
+		return limit, true
+	}
+	return 0, false
+}
+
+// totalMemory returns the total system memory in bytes.
+func totalMemory() (int64, error) {
+	f, err := os.Open(meminfo)
+	if err != nil {
+		return 0, fmt.Errorf("opening %s: %w", meminfo, err)
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 2 || fields[0] != "MemTotal:" {
+			continue
+		}
+		kb, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("parsing MemTotal %q: %w", fields[1], err)
+		}
+		return kb * 1024, nil
+	}
+	if err := s.Err(); err != nil {
+		return 0, fmt.Errorf("reading %s: %w", meminfo, err)
+	}
+	return 0, fmt.Errorf("MemTotal not found in %s", meminfo)
+}
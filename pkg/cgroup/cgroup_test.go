@@ -0,0 +1,173 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cgroup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func setup(t *testing.T, files map[string]string, cpus int) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("creating directory for %q: %v", path, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("writing %q: %v", path, err)
+		}
+	}
+	origRoot, origMeminfo, origNumCPU := root, meminfo, numCPU
+	t.Cleanup(func() {
+		root, meminfo, numCPU = origRoot, origMeminfo, origNumCPU
+	})
+	root = filepath.Join(dir, "cgroup")
+	meminfo = filepath.Join(dir, "meminfo")
+	numCPU = func() int { return cpus }
+}
+
+func TestCPUs(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		cpus  int
+		want  int
+	}{
+		{
+			name: "no cgroup",
+			cpus: 8,
+			want: 8,
+		},
+		{
+			name:  "cgroup v2 unlimited",
+			files: map[string]string{"cgroup/cpu.max": "max 100000\n"},
+			cpus:  8,
+			want:  8,
+		},
+		{
+			name:  "cgroup v2 quota",
+			files: map[string]string{"cgroup/cpu.max": "200000 100000\n"},
+			cpus:  8,
+			want:  2,
+		},
+		{
+			name:  "cgroup v2 fractional quota rounds up",
+			files: map[string]string{"cgroup/cpu.max": "50000 100000\n"},
+			cpus:  8,
+			want:  1,
+		},
+		{
+			name:  "quota larger than visible CPUs",
+			files: map[string]string{"cgroup/cpu.max": "1600000 100000\n"},
+			cpus:  4,
+			want:  4,
+		},
+		{
+			name: "cgroup v1 quota",
+			files: map[string]string{
+				"cgroup/cpu/cpu.cfs_quota_us":  "400000\n",
+				"cgroup/cpu/cpu.cfs_period_us": "100000\n",
+			},
+			cpus: 8,
+			want: 4,
+		},
+		{
+			name: "cgroup v1 unlimited",
+			files: map[string]string{
+				"cgroup/cpu/cpu.cfs_quota_us":  "-1\n",
+				"cgroup/cpu/cpu.cfs_period_us": "100000\n",
+			},
+			cpus: 8,
+			want: 8,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			setup(t, tc.files, tc.cpus)
+			if got := CPUs(); got != tc.want {
+				t.Errorf("CPUs() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMemoryLimit(t *testing.T) {
+	const meminfoContent = "MemTotal:        4096000 kB\nMemFree:         1024000 kB\n"
+	testCases := []struct {
+		name    string
+		files   map[string]string
+		want    int64
+		wantErr bool
+	}{
+		{
+			name:  "no cgroup",
+			files: map[string]string{"meminfo": meminfoContent},
+			want:  4096000 * 1024,
+		},
+		{
+			name: "cgroup v2 limit",
+			files: map[string]string{
+				"meminfo":           meminfoContent,
+				"cgroup/memory.max": "536870912\n",
+			},
+			want: 536870912,
+		},
+		{
+			name: "cgroup v2 unlimited",
+			files: map[string]string{
+				"meminfo":           meminfoContent,
+				"cgroup/memory.max": "max\n",
+			},
+			want: 4096000 * 1024,
+		},
+		{
+			name: "cgroup v1 unlimited",
+			files: map[string]string{
+				"meminfo":                             meminfoContent,
+				"cgroup/memory/memory.limit_in_bytes": "9223372036854771712\n",
+			},
+			want: 4096000 * 1024,
+		},
+		{
+			name: "cgroup v1 limit",
+			files: map[string]string{
+				"meminfo":                             meminfoContent,
+				"cgroup/memory/memory.limit_in_bytes": "268435456\n",
+			},
+			want: 268435456,
+		},
+		{
+			name:    "missing meminfo",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			setup(t, tc.files, 1)
+			got, err := MemoryLimit()
+			if tc.wantErr == (err == nil) {
+				t.Fatalf("MemoryLimit() got error: %v, want err? %t", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("MemoryLimit() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
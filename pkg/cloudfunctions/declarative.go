@@ -0,0 +1,107 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudfunctions
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+var (
+	// pythonDeclarativeRegexp matches the functions registered with the decorators of the Python
+	// Functions Framework, e.g. `@functions_framework.http` followed by `def hello(request):`.
+	pythonDeclarativeRegexp = regexp.MustCompile(`(?m)^@functions_framework\.(http|cloud_event)\s*\n(?:@.*\n)*(?:async\s+)?def\s+(\w+)\s*\(`)
+	// nodeJSDeclarativeRegexp matches the functions registered with the Node.js Functions Framework,
+	// e.g. `functions.http('hello', (req, res) => {...})`.
+	nodeJSDeclarativeRegexp = regexp.MustCompile("\\bfunctions\\.(http|cloudEvent)\\(\\s*['\"`]([^'\"`]+)['\"`]")
+)
+
+// DeclaredFunction is a function registered with the declarative API of a Functions Framework.
+type DeclaredFunction struct {
+	// Name is the name the function is registered with, which is the function target.
+	Name string
+	// SignatureType is the signature the function is registered with, http or cloudevent.
+	SignatureType string
+}
+
+// PythonDeclaredFunctions returns the functions registered with the decorators of the Python
+// Functions Framework in the given source.
+func PythonDeclaredFunctions(source string) []DeclaredFunction {
+	var fns []DeclaredFunction
+	for _, m := range pythonDeclarativeRegexp.FindAllStringSubmatch(source, -1) {
+		fns = append(fns, DeclaredFunction{Name: m[2], SignatureType: strings.ReplaceAll(m[1], "_", "")})
+	}
+	return fns
+}
+
+// PythonDefinesFunction returns true if the given source defines a top-level function or callable
+// of the given name, which the Python Functions Framework can serve without a registration.
+func PythonDefinesFunction(source, name string) bool {
+	re := regexp.MustCompile(`(?m)^(?:(?:async\s+)?def\s+` + regexp.QuoteMeta(name) + `\s*\(|` + regexp.QuoteMeta(name) + `\s*=)`)
+	return re.MatchString(source)
+}
+
+// NodeJSDeclaredFunctions returns the functions registered with the Node.js Functions Framework
+// in the given source.
+func NodeJSDeclaredFunctions(source string) []DeclaredFunction {
+	var fns []DeclaredFunction
+	for _, m := range nodeJSDeclarativeRegexp.FindAllStringSubmatch(source, -1) {
+		fns = append(fns, DeclaredFunction{Name: m[2], SignatureType: strings.ToLower(m[1])})
+	}
+	return fns
+}
+
+// NodeJSExportsFunction returns true if the given source exports a function of the given name,
+// which the Node.js Functions Framework can serve without a registration.
+func NodeJSExportsFunction(source, name string) bool {
+	q := regexp.QuoteMeta(name)
+	re := regexp.MustCompile(`\bexports\.` + q + `\b|\bexports\[\s*['"]` + q + `['"]\s*\]|\bmodule\.exports\s*=\s*\{[^}]*\b` + q + `\b|\bexport\s+(?:async\s+)?(?:function|const|let|var)\s+` + q + `\b`)
+	return re.MatchString(source)
+}
+
+// CheckFunctionTarget fails the build if the source file registers functions with the declarative
+// API of the Functions Framework but not the function target, and does not otherwise define it.
+// Source files that do not use the declarative API are not checked.
+func CheckFunctionTarget(ctx *gcp.Context, target, file string, declared []DeclaredFunction, defined bool) error {
+	if len(declared) == 0 {
+		return nil
+	}
+	var names []string
+	for _, fn := range declared {
+		if fn.Name == target {
+			ctx.Logf("Found function %q registered with signature type %s in %s.", target, fn.SignatureType, file)
+			return nil
+		}
+		names = append(names, fn.Name)
+	}
+	if defined {
+		return nil
+	}
+	sort.Strings(names)
+	return gcp.UserErrorf("%s=%q is not a function registered in %s, the registered functions are: %s", env.FunctionTarget, target, file, formatNames(names))
+}
+
+func formatNames(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = fmt.Sprintf("%q", n)
+	}
+	return strings.Join(quoted, ", ")
+}
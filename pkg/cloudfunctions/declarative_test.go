@@ -0,0 +1,124 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudfunctions
+
+import (
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPythonDeclaredFunctions(t *testing.T) {
+	testCases := []struct {
+		name   string
+		source string
+		want   []DeclaredFunction
+	}{
+		{
+			name:   "no registrations",
+			source: "def hello(request):\n    return 'hello'\n",
+		},
+		{
+			name: "http and cloud event",
+			source: `import functions_framework
+
+@functions_framework.http
+def hello(request):
+    return 'hello'
+
+@functions_framework.cloud_event
+@other.decorator
+async def handle(cloud_event):
+    pass
+`,
+			want: []DeclaredFunction{
+				{Name: "hello", SignatureType: "http"},
+				{Name: "handle", SignatureType: "cloudevent"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, PythonDeclaredFunctions(tc.source)); diff != "" {
+				t.Errorf("PythonDeclaredFunctions() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNodeJSDeclaredFunctions(t *testing.T) {
+	source := `const functions = require('@google-cloud/functions-framework');
+
+functions.http('hello', (req, res) => res.send('hello'));
+functions.cloudEvent("handle", (cloudEvent) => {});
+`
+	want := []DeclaredFunction{
+		{Name: "hello", SignatureType: "http"},
+		{Name: "handle", SignatureType: "cloudevent"},
+	}
+	if diff := cmp.Diff(want, NodeJSDeclaredFunctions(source)); diff != "" {
+		t.Errorf("NodeJSDeclaredFunctions() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDefinesFunction(t *testing.T) {
+	testCases := []struct {
+		name    string
+		defines func(string, string) bool
+		source  string
+		want    bool
+	}{
+		{name: "python def", defines: PythonDefinesFunction, source: "def hello(request):\n", want: true},
+		{name: "python assignment", defines: PythonDefinesFunction, source: "hello = make_function()\n", want: true},
+		{name: "python nested def", defines: PythonDefinesFunction, source: "class A:\n    def hello(self):\n"},
+		{name: "python other function", defines: PythonDefinesFunction, source: "def hello_world(request):\n"},
+		{name: "node exports", defines: NodeJSExportsFunction, source: "exports.hello = (req, res) => {};", want: true},
+		{name: "node module.exports", defines: NodeJSExportsFunction, source: "module.exports = { hello, other };", want: true},
+		{name: "node ES module", defines: NodeJSExportsFunction, source: "export async function hello(req, res) {}", want: true},
+		{name: "node not exported", defines: NodeJSExportsFunction, source: "function hello(req, res) {}"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.defines(tc.source, "hello"); got != tc.want {
+				t.Errorf("defines(%q, hello) = %v, want %v", tc.source, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCheckFunctionTarget(t *testing.T) {
+	declared := []DeclaredFunction{{Name: "world", SignatureType: "http"}, {Name: "hello", SignatureType: "cloudevent"}}
+	testCases := []struct {
+		name     string
+		target   string
+		declared []DeclaredFunction
+		defined  bool
+		wantErr  bool
+	}{
+		{name: "no registrations", target: "hello"},
+		{name: "registered", target: "hello", declared: declared},
+		{name: "defined without registration", target: "other", declared: declared, defined: true},
+		{name: "not registered", target: "other", declared: declared, wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckFunctionTarget(gcp.NewContext(), tc.target, "main.py", tc.declared, tc.defined)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("CheckFunctionTarget(%q) got error: %v, want error: %v", tc.target, err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudfunctions
+
+import (
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/Masterminds/semver"
+	"github.com/buildpacks/libcnb"
+)
+
+// frameworkVersionKey is the layer metadata key of the installed Functions Framework version.
+const frameworkVersionKey = "functions_framework_version"
+
+var (
+	// frameworkVersionRegexp matches the versions that can be pinned, e.g. 3 or 3.3 or 3.3.0.
+	frameworkVersionRegexp = regexp.MustCompile(`^\d+(\.\d+){0,2}$`)
+	// exactFrameworkVersionRegexp matches the versions that can be pinned if a range is not supported.
+	exactFrameworkVersionRegexp = regexp.MustCompile(`^\d+\.\d+\.\d+$`)
+	// frameworkArgValueRegexp matches the flag values that can be passed to the framework, which
+	// are safe to use in a shell command line.
+	frameworkArgValueRegexp = regexp.MustCompile(`^[\w.:/-]+$`)
+	// signatureTypes are the values of the signature type flag.
+	signatureTypes = map[string]bool{"http": true, "event": true, "cloudevent": true}
+)
+
+// Framework is the Functions Framework of a language, which is installed for functions that do
+// not depend on it explicitly.
+type Framework struct {
+	// Package is the name of the framework package.
+	Package string
+	// Runtime is the name of the runtime in messages.
+	Runtime string
+	// VersionEnv is the env var that pins the version of the framework.
+	VersionEnv string
+	// DefaultVersion is the version installed if the version is not pinned.
+	DefaultVersion string
+	// ExactVersion is true if only exact versions can be pinned.
+	ExactVersion bool
+	// MinRuntimeVersions maps the major versions of the framework to the minimum runtime versions
+	// they support.
+	MinRuntimeVersions map[uint64]string
+	// Flags maps the flags of the framework that GOOGLE_FUNCTION_FRAMEWORK_ARGS can pass, without
+	// the leading dashes, to whether they take a value. The target and source are not flags, they
+	// are set with their own env vars.
+	Flags map[string]bool
+}
+
+var (
+	// PythonFramework is the Python Functions Framework. The compatibility of pinned versions with
+	// the Python version is checked against the requirements published on PyPI.
+	PythonFramework = Framework{
+		Package:        "functions-framework",
+		Runtime:        "Python",
+		VersionEnv:     env.PythonFunctionsFrameworkVersion,
+		DefaultVersion: "3.0.0",
+		Flags:          map[string]bool{"signature-type": true, "host": true, "port": true, "debug": false},
+	}
+	// NodeJSFramework is the Node.js Functions Framework.
+	NodeJSFramework = Framework{
+		Package:            "@google-cloud/functions-framework",
+		Runtime:            "Node.js",
+		VersionEnv:         env.NodeJSFunctionsFrameworkVersion,
+		DefaultVersion:     "3.0.0",
+		MinRuntimeVersions: map[uint64]string{3: "10.0.0"},
+		Flags:              map[string]bool{"signature-type": true, "port": true},
+	}
+	// JavaFramework is the Java Functions Framework invoker.
+	JavaFramework = Framework{
+		Package:            "java-function-invoker",
+		Runtime:            "Java",
+		VersionEnv:         env.JavaFunctionsFrameworkVersion,
+		DefaultVersion:     "1.1.0",
+		ExactVersion:       true,
+		MinRuntimeVersions: map[uint64]string{1: "11"},
+		Flags:              map[string]bool{"port": true},
+	}
+)
+
+// Version returns the version of the framework to install, pinned with the env var of the
+// framework or the default version, and whether it is pinned.
+func (f Framework) Version() (string, bool, error) {
+	version := strings.TrimSpace(os.Getenv(f.VersionEnv))
+	if version == "" {
+		return f.DefaultVersion, false, nil
+	}
+	if f.ExactVersion && !exactFrameworkVersionRegexp.MatchString(version) {
+		return "", false, gcp.UserErrorf("invalid %s %q, must be an exact release version such as %s", f.VersionEnv, version, f.DefaultVersion)
+	}
+	if !frameworkVersionRegexp.MatchString(version) {
+		return "", false, gcp.UserErrorf("invalid %s %q, must be a release version such as %s", f.VersionEnv, version, f.DefaultVersion)
+	}
+	return version, true, nil
+}
+
+// CheckRuntime fails the build if the framework version does not support the runtime version.
+// Runtime versions that cannot be parsed are not checked.
+func (f Framework) CheckRuntime(ctx *gcp.Context, version, runtimeVersion string) error {
+	major, err := strconv.ParseUint(strings.SplitN(version, ".", 2)[0], 10, 64)
+	if err != nil {
+		return gcp.InternalErrorf("parsing %s version %q: %v", f.Package, version, err)
+	}
+	minimum, ok := f.MinRuntimeVersions[major]
+	if !ok {
+		return nil
+	}
+	rv, err := semver.NewVersion(strings.TrimSpace(runtimeVersion))
+	if err != nil {
+		ctx.Warnf("Unable to verify that %s %s supports %s %s: %v", f.Package, version, f.Runtime, runtimeVersion, err)
+		return nil
+	}
+	mv, err := semver.NewVersion(minimum)
+	if err != nil {
+		return gcp.InternalErrorf("parsing minimum %s version %q: %v", f.Runtime, minimum, err)
+	}
+	if rv.LessThan(mv) {
+		return gcp.UserErrorf("%s %s requires %s %s or later but the function uses %s, set %s to a version that supports %s %s", f.Package, version, f.Runtime, minimum, rv.Original(), f.VersionEnv, f.Runtime, rv.Original())
+	}
+	return nil
+}
+
+// Args returns the flags of GOOGLE_FUNCTION_FRAMEWORK_ARGS to append to the command that starts
+// the framework. Flags are given as `--name=value` or `--name value`, and are returned as the
+// latter. Only the flags of the framework are accepted, and their values are restricted to
+// characters that are safe in a shell command line.
+func (f Framework) Args() ([]string, error) {
+	fields := strings.Fields(os.Getenv(env.FunctionFrameworkArgs))
+	var args []string
+	for i := 0; i < len(fields); i++ {
+		if !strings.HasPrefix(fields[i], "--") {
+			return nil, gcp.UserErrorf("invalid argument %q in %s, want flags of the form --name=value", fields[i], env.FunctionFrameworkArgs)
+		}
+		parts := strings.SplitN(strings.TrimPrefix(fields[i], "--"), "=", 2)
+		name := parts[0]
+		takesValue, ok := f.Flags[name]
+		if !ok {
+			return nil, gcp.UserErrorf("unsupported flag --%s in %s, the flags supported by %s are %s", name, env.FunctionFrameworkArgs, f.Package, f.flagNames())
+		}
+		if !takesValue {
+			if len(parts) == 2 {
+				return nil, gcp.UserErrorf("flag --%s in %s does not take a value", name, env.FunctionFrameworkArgs)
+			}
+			args = append(args, "--"+name)
+			continue
+		}
+		var value string
+		if len(parts) == 2 {
+			value = parts[1]
+		} else if i+1 < len(fields) && !strings.HasPrefix(fields[i+1], "--") {
+			i++
+			value = fields[i]
+		}
+		if err := checkFlagValue(name, value); err != nil {
+			return nil, err
+		}
+		args = append(args, "--"+name, value)
+	}
+	return args, nil
+}
+
+// flagNames returns the sorted flags of the framework, for messages.
+func (f Framework) flagNames() string {
+	var names []string
+	for name := range f.Flags {
+		names = append(names, "--"+name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// checkFlagValue fails the build if the value of a framework flag is invalid, or if the
+// signature type conflicts with GOOGLE_FUNCTION_SIGNATURE_TYPE, which the build checks rely on.
+func checkFlagValue(name, value string) error {
+	if value == "" {
+		return gcp.UserErrorf("flag --%s in %s requires a value", name, env.FunctionFrameworkArgs)
+	}
+	if !frameworkArgValueRegexp.MatchString(value) {
+		return gcp.UserErrorf("invalid value %q of flag --%s in %s", value, name, env.FunctionFrameworkArgs)
+	}
+	switch name {
+	case "port":
+		if port, err := strconv.Atoi(value); err != nil || port < 1 || port > 65535 {
+			return gcp.UserErrorf("invalid value %q of flag --port in %s, must be a port number", value, env.FunctionFrameworkArgs)
+		}
+	case "signature-type":
+		if !signatureTypes[value] {
+			return gcp.UserErrorf("invalid value %q of flag --signature-type in %s, must be one of http, event, cloudevent", value, env.FunctionFrameworkArgs)
+		}
+		if st, ok := os.LookupEnv(env.FunctionSignatureType); ok && st != value {
+			return gcp.UserErrorf("flag --signature-type=%s in %s conflicts with %s=%s", value, env.FunctionFrameworkArgs, env.FunctionSignatureType, st)
+		}
+	}
+	return nil
+}
+
+// InstalledVersion returns the framework version recorded in the layer, or an empty string.
+func InstalledVersion(ctx *gcp.Context, l *libcnb.Layer) string {
+	return ctx.GetMetadata(l, frameworkVersionKey)
+}
+
+// RecordVersion records the framework version installed in the layer, so that the layer is
+// reinstalled if a different version is selected.
+func RecordVersion(ctx *gcp.Context, l *libcnb.Layer, version string) {
+	ctx.SetMetadata(l, frameworkVersionKey, version)
+}
@@ -0,0 +1,238 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudfunctions
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFrameworkVersion(t *testing.T) {
+	testCases := []struct {
+		name       string
+		framework  Framework
+		env        string
+		want       string
+		wantPinned bool
+		wantErr    bool
+	}{
+		{
+			name:      "not set",
+			framework: PythonFramework,
+			want:      "3.0.0",
+		},
+		{
+			name:       "full version",
+			framework:  PythonFramework,
+			env:        "3.3.0",
+			want:       "3.3.0",
+			wantPinned: true,
+		},
+		{
+			name:       "major minor version",
+			framework:  NodeJSFramework,
+			env:        " 3.3 ",
+			want:       "3.3",
+			wantPinned: true,
+		},
+		{
+			name:      "specifier",
+			framework: PythonFramework,
+			env:       ">=3.0",
+			wantErr:   true,
+		},
+		{
+			name:       "exact version",
+			framework:  JavaFramework,
+			env:        "1.3.0",
+			want:       "1.3.0",
+			wantPinned: true,
+		},
+		{
+			name:      "inexact version",
+			framework: JavaFramework,
+			env:       "1.3",
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(tc.framework.VersionEnv, tc.env)
+
+			got, pinned, err := tc.framework.Version()
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("Version() got error: %v, want error: %t", err, tc.wantErr)
+			}
+			if got != tc.want || pinned != tc.wantPinned {
+				t.Errorf("Version() = (%q, %t), want (%q, %t)", got, pinned, tc.want, tc.wantPinned)
+			}
+		})
+	}
+}
+
+func TestCheckRuntime(t *testing.T) {
+	testCases := []struct {
+		name           string
+		framework      Framework
+		version        string
+		runtimeVersion string
+		wantErr        bool
+	}{
+		{
+			name:           "supported node version",
+			framework:      NodeJSFramework,
+			version:        "3.3.0",
+			runtimeVersion: "v18.12.1\n",
+		},
+		{
+			name:           "unsupported node version",
+			framework:      NodeJSFramework,
+			version:        "3",
+			runtimeVersion: "v8.17.0",
+			wantErr:        true,
+		},
+		{
+			name:           "unsupported java version",
+			framework:      JavaFramework,
+			version:        "1.1.0",
+			runtimeVersion: "8",
+			wantErr:        true,
+		},
+		{
+			name:           "supported java version",
+			framework:      JavaFramework,
+			version:        "1.1.0",
+			runtimeVersion: "17",
+		},
+		{
+			name:           "unknown framework major version",
+			framework:      NodeJSFramework,
+			version:        "1.0.0",
+			runtimeVersion: "v8.17.0",
+		},
+		{
+			name:           "unknown runtime version",
+			framework:      JavaFramework,
+			version:        "1.1.0",
+			runtimeVersion: "",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.framework.CheckRuntime(gcp.NewContext(), tc.version, tc.runtimeVersion)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("CheckRuntime(%q, %q) got error: %v, want error: %t", tc.version, tc.runtimeVersion, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestFrameworkArgs(t *testing.T) {
+	testCases := []struct {
+		name          string
+		framework     Framework
+		args          string
+		signatureType string
+		want          []string
+		wantErr       bool
+	}{
+		{
+			name:      "not set",
+			framework: PythonFramework,
+		},
+		{
+			name:      "flags with values",
+			framework: PythonFramework,
+			args:      "--signature-type=cloudevent --port 8081 --debug",
+			want:      []string{"--signature-type", "cloudevent", "--port", "8081", "--debug"},
+		},
+		{
+			name:          "signature type matches env",
+			framework:     NodeJSFramework,
+			args:          "--signature-type=event",
+			signatureType: "event",
+			want:          []string{"--signature-type", "event"},
+		},
+		{
+			name:          "signature type conflicts with env",
+			framework:     NodeJSFramework,
+			args:          "--signature-type=event",
+			signatureType: "http",
+			wantErr:       true,
+		},
+		{
+			name:      "invalid signature type",
+			framework: PythonFramework,
+			args:      "--signature-type=background",
+			wantErr:   true,
+		},
+		{
+			name:      "unsupported flag",
+			framework: JavaFramework,
+			args:      "--target=com.example.Function",
+			wantErr:   true,
+		},
+		{
+			name:      "invalid port",
+			framework: JavaFramework,
+			args:      "--port=http",
+			wantErr:   true,
+		},
+		{
+			name:      "missing value",
+			framework: NodeJSFramework,
+			args:      "--port",
+			wantErr:   true,
+		},
+		{
+			name:      "value for boolean flag",
+			framework: PythonFramework,
+			args:      "--debug=true",
+			wantErr:   true,
+		},
+		{
+			name:      "not a flag",
+			framework: PythonFramework,
+			args:      "main.py",
+			wantErr:   true,
+		},
+		{
+			name:      "unsafe value",
+			framework: PythonFramework,
+			args:      "--host=$(hostname)",
+			wantErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(env.FunctionFrameworkArgs, tc.args)
+			if tc.signatureType != "" {
+				t.Setenv(env.FunctionSignatureType, tc.signatureType)
+			}
+
+			got, err := tc.framework.Args()
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("Args() got error: %v, want error: %t", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Args() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
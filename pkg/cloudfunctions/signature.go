@@ -0,0 +1,199 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudfunctions
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+// handlerSignature is the signature of the handlers of a signature type in a language.
+type handlerSignature struct {
+	// params are the numbers of arguments the handlers are called with.
+	params []int
+	// example is an example handler declaration, formatted with the function name.
+	example string
+}
+
+var (
+	pythonSignatures = map[string]handlerSignature{
+		"http":       {params: []int{1}, example: "def %s(request):"},
+		"cloudevent": {params: []int{1}, example: "def %s(cloud_event):"},
+		"event":      {params: []int{2}, example: "def %s(data, context):"},
+	}
+	nodeJSSignatures = map[string]handlerSignature{
+		"http":       {params: []int{2, 3}, example: "functions.http('%s', (req, res) => {...})"},
+		"cloudevent": {params: []int{1, 2}, example: "functions.cloudEvent('%s', (cloudEvent) => {...})"},
+		"event":      {params: []int{2, 3}, example: "exports.%s = (data, context) => {...}"},
+	}
+
+	// nodeJSHandlerParams matches the parameter list of a function expression, either in parentheses
+	// or the single parameter of an arrow function.
+	nodeJSHandlerParams = `\s*(?:async\s+)?(?:function\b\s*\w*\s*\(([^)]*)\)|\(([^)]*)\)\s*=>|(\w+)\s*=>)`
+)
+
+// Handler is the parameter list of a function handler.
+type Handler struct {
+	// Params are the names of the positional parameters.
+	Params []string
+	// Required is the number of positional parameters without a default value.
+	Required int
+	// Variadic is true if the handler takes any number of additional arguments.
+	Variadic bool
+}
+
+// accepts returns true if the handler can be called with n arguments.
+func (h Handler) accepts(n int) bool {
+	return n >= h.Required && (h.Variadic || n <= len(h.Params))
+}
+
+// PythonHandler returns the handler of the top-level Python function of the given name, and
+// false if the source does not define it.
+func PythonHandler(source, name string) (Handler, bool) {
+	re := regexp.MustCompile(`(?m)^(?:async\s+)?def\s+` + regexp.QuoteMeta(name) + `\s*\(([^)]*)\)`)
+	m := re.FindStringSubmatch(source)
+	if m == nil {
+		return Handler{}, false
+	}
+	var h Handler
+	for _, p := range splitParams(m[1]) {
+		if strings.HasPrefix(p, "**") {
+			break
+		}
+		if strings.HasPrefix(p, "*") {
+			// Parameters after *args or * are keyword-only.
+			h.Variadic = p != "*"
+			break
+		}
+		if p == "/" {
+			continue
+		}
+		name := p
+		if i := strings.IndexAny(name, ":="); i >= 0 {
+			name = strings.TrimSpace(name[:i])
+		}
+		if !strings.Contains(p, "=") {
+			h.Required++
+		}
+		h.Params = append(h.Params, name)
+	}
+	return h, true
+}
+
+// NodeJSHandler returns the handler of the Node.js function of the given name, either registered
+// with the Functions Framework or exported, and false if it is not found. All parameters are
+// considered required, as a handler that declares fewer parameters than its signature type cannot
+// use all of them, e.g. respond to an HTTP request.
+func NodeJSHandler(source, name string) (Handler, bool) {
+	q := regexp.QuoteMeta(name)
+	for _, re := range []*regexp.Regexp{
+		regexp.MustCompile(`\bfunctions\.(?:http|cloudEvent)\(\s*['"` + "`" + `]` + q + `['"` + "`" + `]\s*,` + nodeJSHandlerParams),
+		regexp.MustCompile(`\bexports\.` + q + `\s*=` + nodeJSHandlerParams),
+		regexp.MustCompile(`\b(?:const|let|var)\s+` + q + `\s*=` + nodeJSHandlerParams),
+		regexp.MustCompile(`(?:^|[^.\w])(?:async\s+)?function\s+` + q + `\s*\(([^)]*)\)`),
+	} {
+		m := re.FindStringSubmatch(source)
+		if m == nil {
+			continue
+		}
+		var h Handler
+		for _, params := range m[1:] {
+			if params == "" {
+				continue
+			}
+			for _, p := range splitParams(params) {
+				if strings.HasPrefix(p, "...") {
+					h.Variadic = true
+					break
+				}
+				// Remove default values.
+				if i := strings.Index(p, "="); i >= 0 {
+					p = strings.TrimSpace(p[:i])
+				}
+				h.Params = append(h.Params, p)
+			}
+			break
+		}
+		h.Required = len(h.Params)
+		return h, true
+	}
+	return Handler{}, false
+}
+
+// splitParams splits a parameter list on commas.
+func splitParams(list string) []string {
+	var params []string
+	for _, p := range strings.Split(list, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// CheckPythonSignature fails the build if the handler of the target does not take the parameters
+// of the signature type the target is registered with or configured with in
+// GOOGLE_FUNCTION_SIGNATURE_TYPE.
+func CheckPythonSignature(target, file, source string, declared []DeclaredFunction) error {
+	h, ok := PythonHandler(source, target)
+	return checkSignature(target, file, h, ok, declared, pythonSignatures, "Python")
+}
+
+// CheckNodeJSSignature fails the build if the handler of the target does not take the parameters
+// of the signature type the target is registered with or configured with in
+// GOOGLE_FUNCTION_SIGNATURE_TYPE.
+func CheckNodeJSSignature(target, file, source string, declared []DeclaredFunction) error {
+	h, ok := NodeJSHandler(source, target)
+	return checkSignature(target, file, h, ok, declared, nodeJSSignatures, "Node.js")
+}
+
+// checkSignature checks the parameters of the handler against the signature type of the target.
+// Handlers that are not found, and targets of unknown signature type, are not checked.
+func checkSignature(target, file string, h Handler, found bool, declared []DeclaredFunction, signatures map[string]handlerSignature, language string) error {
+	configured := os.Getenv(env.FunctionSignatureType)
+	signature := configured
+	for _, fn := range declared {
+		if fn.Name != target {
+			continue
+		}
+		if configured != "" && configured != fn.SignatureType {
+			return gcp.UserErrorf("function %q is registered in %s as a %s function but %s is %q, remove %s or register the function as a %s function", target, file, fn.SignatureType, env.FunctionSignatureType, configured, env.FunctionSignatureType, configured)
+		}
+		signature = fn.SignatureType
+	}
+	if !found || signature == "" {
+		return nil
+	}
+	sig, ok := signatures[signature]
+	if !ok {
+		return nil
+	}
+	for _, n := range sig.params {
+		if h.accepts(n) {
+			return nil
+		}
+	}
+	var counts []string
+	for _, n := range sig.params {
+		counts = append(counts, strconv.Itoa(n))
+	}
+	return gcp.UserErrorf("%s function %q in %s takes %d parameter(s) (%s) but %s functions take %s parameter(s): %s", language, target, file, len(h.Params), strings.Join(h.Params, ", "), signature, strings.Join(counts, " or "), fmt.Sprintf(sig.example, target))
+}
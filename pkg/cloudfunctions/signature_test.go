@@ -0,0 +1,225 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudfunctions
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPythonHandler(t *testing.T) {
+	testCases := []struct {
+		name      string
+		source    string
+		want      Handler
+		wantFound bool
+	}{
+		{
+			name:      "http",
+			source:    "def hello(request):\n",
+			want:      Handler{Params: []string{"request"}, Required: 1},
+			wantFound: true,
+		},
+		{
+			name:      "annotations and defaults",
+			source:    "async def hello(\n    data: dict,\n    context=None,\n) -> None:\n",
+			want:      Handler{Params: []string{"data", "context"}, Required: 1},
+			wantFound: true,
+		},
+		{
+			name:      "variadic",
+			source:    "def hello(data, *args, **kwargs):\n",
+			want:      Handler{Params: []string{"data"}, Required: 1, Variadic: true},
+			wantFound: true,
+		},
+		{
+			name:      "keyword-only",
+			source:    "def hello(request, *, debug=False):\n",
+			want:      Handler{Params: []string{"request"}, Required: 1},
+			wantFound: true,
+		},
+		{
+			name:   "method",
+			source: "class A:\n    def hello(self, request):\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, found := PythonHandler(tc.source, "hello")
+			if found != tc.wantFound {
+				t.Fatalf("PythonHandler() found = %v, want %v", found, tc.wantFound)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("PythonHandler() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNodeJSHandler(t *testing.T) {
+	testCases := []struct {
+		name      string
+		source    string
+		want      Handler
+		wantFound bool
+	}{
+		{
+			name:      "registered arrow function",
+			source:    "functions.http('hello', async (req, res) => {});",
+			want:      Handler{Params: []string{"req", "res"}, Required: 2},
+			wantFound: true,
+		},
+		{
+			name:      "registered single parameter arrow function",
+			source:    "functions.cloudEvent(\"hello\", cloudEvent => {});",
+			want:      Handler{Params: []string{"cloudEvent"}, Required: 1},
+			wantFound: true,
+		},
+		{
+			name:      "exported function expression",
+			source:    "exports.hello = function (data, context, callback) {};",
+			want:      Handler{Params: []string{"data", "context", "callback"}, Required: 3},
+			wantFound: true,
+		},
+		{
+			name:      "function declaration",
+			source:    "async function hello(req, res = null) {}\nmodule.exports = { hello };",
+			want:      Handler{Params: []string{"req", "res"}, Required: 2},
+			wantFound: true,
+		},
+		{
+			name:      "rest parameter",
+			source:    "const hello = (...args) => {};",
+			want:      Handler{Variadic: true},
+			wantFound: true,
+		},
+		{
+			name:   "not found",
+			source: "exports.world = (req, res) => {};",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, found := NodeJSHandler(tc.source, "hello")
+			if found != tc.wantFound {
+				t.Fatalf("NodeJSHandler() found = %v, want %v", found, tc.wantFound)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("NodeJSHandler() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCheckSignature(t *testing.T) {
+	testCases := []struct {
+		name      string
+		check     func(target, file, source string, declared []DeclaredFunction) error
+		source    string
+		declared  []DeclaredFunction
+		signature string
+		wantErr   bool
+	}{
+		{
+			name:   "python without signature type",
+			check:  CheckPythonSignature,
+			source: "def hello(data, context):\n",
+		},
+		{
+			name:      "python background function",
+			check:     CheckPythonSignature,
+			source:    "def hello(data, context):\n",
+			signature: "event",
+		},
+		{
+			name:      "python background function as cloudevent",
+			check:     CheckPythonSignature,
+			source:    "def hello(data, context):\n",
+			signature: "cloudevent",
+			wantErr:   true,
+		},
+		{
+			name:      "python http function as background function",
+			check:     CheckPythonSignature,
+			source:    "def hello(request):\n",
+			signature: "event",
+			wantErr:   true,
+		},
+		{
+			name:      "python optional context",
+			check:     CheckPythonSignature,
+			source:    "def hello(data, context=None):\n",
+			signature: "cloudevent",
+		},
+		{
+			name:     "python registered cloudevent function",
+			check:    CheckPythonSignature,
+			source:   "@functions_framework.cloud_event\ndef hello(request, response):\n",
+			declared: []DeclaredFunction{{Name: "hello", SignatureType: "cloudevent"}},
+			wantErr:  true,
+		},
+		{
+			name:      "registration conflicts with signature type",
+			check:     CheckPythonSignature,
+			source:    "@functions_framework.http\ndef hello(request):\n",
+			declared:  []DeclaredFunction{{Name: "hello", SignatureType: "http"}},
+			signature: "cloudevent",
+			wantErr:   true,
+		},
+		{
+			name:      "python unknown handler",
+			check:     CheckPythonSignature,
+			source:    "hello = make_handler()\n",
+			signature: "event",
+		},
+		{
+			name:      "node http function",
+			check:     CheckNodeJSSignature,
+			source:    "exports.hello = (req, res) => {};",
+			signature: "http",
+		},
+		{
+			name:     "node cloudevent function with http handler",
+			check:    CheckNodeJSSignature,
+			source:   "functions.cloudEvent('hello', (req, res, next) => {});",
+			declared: []DeclaredFunction{{Name: "hello", SignatureType: "cloudevent"}},
+			wantErr:  true,
+		},
+		{
+			name:      "node http function with one parameter",
+			check:     CheckNodeJSSignature,
+			source:    "exports.hello = (cloudEvent) => {};",
+			signature: "http",
+			wantErr:   true,
+		},
+		{
+			name:      "node background function with callback",
+			check:     CheckNodeJSSignature,
+			source:    "exports.hello = (data, context, callback) => {};",
+			signature: "event",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(env.FunctionSignatureType, tc.signature)
+			err := tc.check("hello", "source", tc.source, tc.declared)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("check() got error: %v, want error: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudfunctions
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+const (
+	// smokeTestOutputLines is the number of lines of the framework output included in errors.
+	smokeTestOutputLines = 30
+	// smokeTestEventType is the type of the test events sent to event-driven functions.
+	smokeTestEventType = "com.google.cloud.buildpacks.smoketest"
+)
+
+var (
+	// smokeTestStartupTimeout is how long the framework has to start accepting requests.
+	smokeTestStartupTimeout = 30 * time.Second
+	// smokeTestPollInterval is the interval between connection attempts while the framework starts.
+	smokeTestPollInterval = 200 * time.Millisecond
+)
+
+// SmokeTestEnabled returns true if GOOGLE_FUNCTION_SMOKE_TEST enables the build-time smoke test.
+func SmokeTestEnabled() (bool, error) {
+	enabled, err := env.IsPresentAndTrue(env.FunctionSmokeTest)
+	if err != nil {
+		return false, gcp.UserErrorf("%v", err)
+	}
+	return enabled, nil
+}
+
+// SmokeTest starts the Functions Framework with the given command once for each function target,
+// in the application root and with the given environment in addition to the environment of the
+// build, and sends a test request of the signature type of the target. It returns a user error
+// with the output of the framework if the framework exits or does not accept connections, which
+// is how import and registration errors manifest. Error responses to the test request only
+// produce a warning, as functions might legitimately reject the synthetic request.
+func SmokeTest(ctx *gcp.Context, cmd []string, extraEnv []string, declared []DeclaredFunction) error {
+	targets, err := gcp.FunctionTargets()
+	if err != nil {
+		return err
+	}
+	for _, target := range targets {
+		if err := smokeTestTarget(ctx, cmd, extraEnv, target, signatureType(target, declared)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// signatureType returns the signature type of the target: the type it is registered with, the
+// type of GOOGLE_FUNCTION_SIGNATURE_TYPE, or http.
+func signatureType(target string, declared []DeclaredFunction) string {
+	for _, fn := range declared {
+		if fn.Name == target {
+			return fn.SignatureType
+		}
+	}
+	if sig := os.Getenv(env.FunctionSignatureType); sig != "" {
+		return sig
+	}
+	return "http"
+}
+
+func smokeTestTarget(ctx *gcp.Context, cmd []string, extraEnv []string, target, signature string) error {
+	port, err := freePort()
+	if err != nil {
+		return gcp.InternalErrorf("finding a port for the smoke test: %v", err)
+	}
+	ctx.Logf("Smoke testing function %q with signature type %s.", target, signature)
+
+	c := exec.Command(cmd[0], cmd[1:]...)
+	c.Dir = ctx.ApplicationRoot()
+	c.Env = append(os.Environ(),
+		"PORT="+strconv.Itoa(port),
+		env.FunctionTargetLaunch+"="+target,
+		env.FunctionSignatureTypeLaunch+"="+signature,
+	)
+	if source, ok := os.LookupEnv(env.FunctionSource); ok {
+		c.Env = append(c.Env, env.FunctionSourceLaunch+"="+source)
+	}
+	c.Env = append(c.Env, extraEnv...)
+	var output bytes.Buffer
+	c.Stdout = &output
+	c.Stderr = &output
+	// Run the framework in its own process group so that the processes it starts are stopped too.
+	c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := c.Start(); err != nil {
+		return gcp.InternalErrorf("starting %q: %v", strings.Join(cmd, " "), err)
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- c.Wait() }()
+	stop := func() {
+		syscall.Kill(-c.Process.Pid, syscall.SIGKILL)
+		<-exited
+	}
+
+	req, err := smokeTestRequest(fmt.Sprintf("http://127.0.0.1:%d/", port), signature)
+	if err != nil {
+		stop()
+		return err
+	}
+	client := &http.Client{Timeout: smokeTestStartupTimeout}
+	deadline := time.Now().Add(smokeTestStartupTimeout)
+	for {
+		select {
+		case err := <-exited:
+			return gcp.UserErrorf("the Functions Framework exited before serving function %q (%v), output:\n%s", target, err, tail(output.String(), smokeTestOutputLines))
+		default:
+		}
+		r, err := req.httpRequest()
+		if err != nil {
+			stop()
+			return gcp.InternalErrorf("creating the smoke test request: %v", err)
+		}
+		resp, err := client.Do(r)
+		if err == nil {
+			resp.Body.Close()
+			stop()
+			if resp.StatusCode >= http.StatusInternalServerError {
+				ctx.Warnf("Function %q responded to the smoke test request with %s, output:\n%s", target, resp.Status, tail(output.String(), smokeTestOutputLines))
+			} else {
+				ctx.Logf("Function %q responded to the smoke test request with %s.", target, resp.Status)
+			}
+			return nil
+		}
+		if time.Now().After(deadline) {
+			stop()
+			return gcp.UserErrorf("the Functions Framework did not serve function %q within %v, output:\n%s", target, smokeTestStartupTimeout, tail(output.String(), smokeTestOutputLines))
+		}
+		time.Sleep(smokeTestPollInterval)
+	}
+}
+
+// testRequest is a request that can be sent several times.
+type testRequest struct {
+	url     string
+	method  string
+	headers map[string]string
+	body    string
+}
+
+func (r testRequest) httpRequest() (*http.Request, error) {
+	req, err := http.NewRequest(r.method, r.url, strings.NewReader(r.body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range r.headers {
+		req.Header.Set(k, v)
+	}
+	return req, nil
+}
+
+// smokeTestRequest returns the test request for the signature type: a GET request for HTTP
+// functions, a binary mode CloudEvent for CloudEvent functions and a legacy event for background
+// functions.
+func smokeTestRequest(url, signature string) (testRequest, error) {
+	switch signature {
+	case "http":
+		return testRequest{url: url, method: http.MethodGet}, nil
+	case "cloudevent":
+		return testRequest{
+			url:    url,
+			method: http.MethodPost,
+			headers: map[string]string{
+				"Content-Type":   "application/json",
+				"ce-id":          "smoke-test",
+				"ce-source":      "//buildpacks.cloud.google.com/smoke-test",
+				"ce-specversion": "1.0",
+				"ce-type":        smokeTestEventType,
+			},
+			body: "{}",
+		}, nil
+	case "event":
+		return testRequest{
+			url:     url,
+			method:  http.MethodPost,
+			headers: map[string]string{"Content-Type": "application/json"},
+			body:    fmt.Sprintf(`{"context": {"eventId": "smoke-test", "timestamp": %q, "eventType": %q, "resource": "smoke-test"}, "data": {}}`, time.Now().UTC().Format(time.RFC3339), smokeTestEventType),
+		}, nil
+	}
+	return testRequest{}, gcp.UserErrorf("unsupported function signature type %q, must be one of http, event, cloudevent", signature)
+}
+
+// freePort returns a port that is free on the loopback interface.
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// tail returns the last n lines of the output.
+func tail(output string, n int) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,136 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudfunctions
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+const helperModeEnv = "SMOKE_TEST_HELPER_MODE"
+
+// TestSmokeTestHelper is not a test, it is the fake Functions Framework started by TestSmokeTest.
+func TestSmokeTestHelper(t *testing.T) {
+	mode := os.Getenv(helperModeEnv)
+	if mode == "" {
+		return
+	}
+	if mode == "crash" {
+		fmt.Fprintln(os.Stderr, "ModuleNotFoundError: No module named 'missing'")
+		os.Exit(1)
+	}
+	if mode == "hang" {
+		time.Sleep(time.Minute)
+		os.Exit(0)
+	}
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if os.Getenv(env.FunctionTargetLaunch) != "hello" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if os.Getenv(env.FunctionSignatureTypeLaunch) == "cloudevent" && r.Header.Get("ce-type") != smokeTestEventType {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if mode == "error" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+	// Delay startup so that the smoke test waits for the server.
+	time.Sleep(300 * time.Millisecond)
+	http.ListenAndServe("127.0.0.1:"+os.Getenv("PORT"), nil)
+	os.Exit(0)
+}
+
+func TestSmokeTest(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mode     string
+		declared []DeclaredFunction
+		wantErr  string
+	}{
+		{
+			name: "http function",
+			mode: "serve",
+		},
+		{
+			name:     "cloudevent function",
+			mode:     "serve",
+			declared: []DeclaredFunction{{Name: "hello", SignatureType: "cloudevent"}},
+		},
+		{
+			name: "error response",
+			mode: "error",
+		},
+		{
+			name:    "startup error",
+			mode:    "crash",
+			wantErr: "No module named 'missing'",
+		},
+		{
+			name:    "startup timeout",
+			mode:    "hang",
+			wantErr: "did not serve function",
+		},
+	}
+	origTimeout := smokeTestStartupTimeout
+	t.Cleanup(func() { smokeTestStartupTimeout = origTimeout })
+	smokeTestStartupTimeout = 2 * time.Second
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(env.FunctionTarget, "hello")
+			cmd := []string{os.Args[0], "-test.run=TestSmokeTestHelper"}
+			err := SmokeTest(gcp.NewContext(), cmd, []string{helperModeEnv + "=" + tc.mode}, tc.declared)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("SmokeTest() got error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("SmokeTest() got error: %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestSignatureType(t *testing.T) {
+	declared := []DeclaredFunction{{Name: "hello", SignatureType: "cloudevent"}}
+	testCases := []struct {
+		name      string
+		target    string
+		signature string
+		want      string
+	}{
+		{name: "registered", target: "hello", signature: "http", want: "cloudevent"},
+		{name: "from env", target: "other", signature: "event", want: "event"},
+		{name: "default", target: "other", want: "http"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(env.FunctionSignatureType, tc.signature)
+			if got := signatureType(tc.target, declared); got != tc.want {
+				t.Errorf("signatureType(%q) = %q, want %q", tc.target, got, tc.want)
+			}
+		})
+	}
+}
@@ -22,6 +22,8 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/buildererror"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
@@ -30,7 +32,15 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
-var versionURL = "https://storage.googleapis.com/dart-archive/channels/stable/release/latest/VERSION"
+var (
+	versionURL = "https://storage.googleapis.com/dart-archive/channels/stable/release/latest/VERSION"
+
+	// partRegexp matches the part directives of Dart libraries, e.g. `part 'user.g.dart';`, which
+	// include the code generated by build_runner.
+	partRegexp = regexp.MustCompile(`(?m)^\s*part\s+['"]([^'"]+)['"]\s*;`)
+	// sourceDirs are the directories of the Dart sources that are compiled into executables.
+	sourceDirs = []string{"bin", "lib"}
+)
 
 // releaseInfo contains information about a Dart SDK release.
 type releaseInfo struct {
@@ -39,10 +49,14 @@ type releaseInfo struct {
 	Revision string `json:"revision"`
 }
 
-// pubspec represents the contents of a pubspec.yaml.
+// pubspec represents the contents of a pubspec.yaml. The dependencies are either version
+// constraints or maps that describe the source, e.g. `sdk: flutter`.
 type pubspec struct {
-	Dependencies    map[string]string `yaml:"dependencies"`
-	DevDependencies map[string]string `yaml:"dev_dependencies"`
+	Dependencies    map[string]interface{} `yaml:"dependencies"`
+	DevDependencies map[string]interface{} `yaml:"dev_dependencies"`
+	// Executables maps the names of the executables of the package to their script in bin/, without
+	// the .dart extension. An empty script is the same as the name.
+	Executables map[string]string `yaml:"executables"`
 }
 
 // DetectSDKVersion detects which SDK version should be installed from the environment or fetches
@@ -82,26 +96,112 @@ func fetchLatestSdkVersion() (string, error) {
 // HasBuildRunner returns true if the given Dart project contains a pubspec.yaml that declares a
 // dependency on build_runner.
 func HasBuildRunner(dir string) (bool, error) {
+	ps, err := readPubspec(dir)
+	if err != nil || ps == nil {
+		return false, err
+	}
+	if _, exists := ps.Dependencies["build_runner"]; exists {
+		return true, nil
+	}
+	if _, exists := ps.DevDependencies["build_runner"]; exists {
+		return true, nil
+	}
+	return false, nil
+}
+
+// MissingParts returns the files included by the part directives of the Dart sources in the bin
+// and lib directories of the given Dart project that do not exist, relative to the project. These
+// are usually generated by build_runner.
+func MissingParts(dir string) ([]string, error) {
+	var missing []string
+	for _, sd := range sourceDirs {
+		err := filepath.Walk(filepath.Join(dir, sd), func(path string, info os.FileInfo, err error) error {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || filepath.Ext(path) != ".dart" {
+				return nil
+			}
+			content, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			for _, m := range partRegexp.FindAllStringSubmatch(string(content), -1) {
+				part := filepath.Join(filepath.Dir(path), filepath.FromSlash(m[1]))
+				if _, err := os.Stat(part); os.IsNotExist(err) {
+					rel, err := filepath.Rel(dir, part)
+					if err != nil {
+						return err
+					}
+					missing = append(missing, rel)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, gcp.InternalErrorf("finding the parts of the Dart sources in %s: %v", sd, err)
+		}
+	}
+	return missing, nil
+}
+
+// IsFlutter returns true if the given Dart project contains a pubspec.yaml that depends on the
+// Flutter SDK, i.e. declares the dependency `flutter: {sdk: flutter}`.
+func IsFlutter(dir string) (bool, error) {
+	ps, err := readPubspec(dir)
+	if err != nil || ps == nil {
+		return false, err
+	}
+	dep, ok := ps.Dependencies["flutter"].(map[interface{}]interface{})
+	if !ok {
+		return false, nil
+	}
+	return dep["sdk"] == "flutter", nil
+}
+
+// Executable is an executable declared in the executables section of pubspec.yaml.
+type Executable struct {
+	// Name is the name of the executable.
+	Name string
+	// Script is the path of the Dart script of the executable, relative to the project.
+	Script string
+}
+
+// Executables returns the executables declared in the pubspec.yaml of the given Dart project,
+// sorted by name.
+func Executables(dir string) ([]Executable, error) {
+	ps, err := readPubspec(dir)
+	if err != nil || ps == nil {
+		return nil, err
+	}
+	var exes []Executable
+	for name, script := range ps.Executables {
+		if script == "" {
+			script = name
+		}
+		exes = append(exes, Executable{Name: name, Script: filepath.Join("bin", script+".dart")})
+	}
+	sort.Slice(exes, func(i, j int) bool { return exes[i].Name < exes[j].Name })
+	return exes, nil
+}
+
+// readPubspec returns the pubspec.yaml of the given Dart project, or nil if there is none.
+func readPubspec(dir string) (*pubspec, error) {
 	f := filepath.Join(dir, "pubspec.yaml")
 	rawpjs, err := ioutil.ReadFile(f)
 	if os.IsNotExist(err) {
-		// If there is no pubspec.yaml, there is no build_runner dependency.
-		return false, nil
+		return nil, nil
 	}
 	if err != nil {
-		return false, gcp.InternalErrorf("reading pubspec.yaml: %v", err)
+		return nil, gcp.InternalErrorf("reading pubspec.yaml: %v", err)
 	}
 
 	var ps pubspec
 	if err := yaml.Unmarshal(rawpjs, &ps); err != nil {
-		return false, gcp.UserErrorf("unmarshalling pubspec.yaml: %v", err)
+		return nil, gcp.UserErrorf("unmarshalling pubspec.yaml: %v", err)
 	}
-
-	if _, exists := ps.Dependencies["build_runner"]; exists {
-		return true, nil
-	}
-	if _, exists := ps.DevDependencies["build_runner"]; exists {
-		return true, nil
-	}
-	return false, nil
+	return &ps, nil
 }
@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/GoogleCloudPlatform/buildpacks/internal/testserver"
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestResolvePackageVersion(t *testing.T) {
@@ -127,6 +128,20 @@ dependencies:
 
 dev_dependencies:
   functions_framework: ^0.4.0
+`,
+			want: true,
+		},
+		{
+			name: "with sdk dependency",
+			pubspec: `
+name: example_flutter
+
+dependencies:
+  flutter:
+    sdk: flutter
+
+dev_dependencies:
+  build_runner: ^2.0.0
 `,
 			want: true,
 		},
@@ -156,3 +171,190 @@ dev_dependencies:
 		})
 	}
 }
+
+func TestIsFlutter(t *testing.T) {
+	testCases := []struct {
+		name    string
+		pubspec string
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "no pubspec.yaml",
+		},
+		{
+			name: "dart server",
+			pubspec: `
+name: example_server
+
+dependencies:
+  shelf: ^1.4.0
+`,
+		},
+		{
+			name: "flutter sdk dependency",
+			pubspec: `
+name: example_flutter
+
+dependencies:
+  flutter:
+    sdk: flutter
+  cupertino_icons: ^1.0.2
+`,
+			want: true,
+		},
+		{
+			name: "flutter test sdk dependency only",
+			pubspec: `
+name: example_package
+
+dev_dependencies:
+  flutter_test:
+    sdk: flutter
+`,
+		},
+		{
+			name:    "invalid yaml",
+			pubspec: "\t",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tc.pubspec != "" {
+				path := filepath.Join(dir, "pubspec.yaml")
+				if err := os.WriteFile(path, []byte(tc.pubspec), 0744); err != nil {
+					t.Fatalf("writing %s: %v", path, err)
+				}
+			}
+			got, err := IsFlutter(dir)
+			if tc.wantErr == (err == nil) {
+				t.Errorf("IsFlutter(%q) got error: %v, want err? %t", dir, err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("IsFlutter(%q) = %t, want %t", dir, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExecutables(t *testing.T) {
+	testCases := []struct {
+		name    string
+		pubspec string
+		want    []Executable
+		wantErr bool
+	}{
+		{
+			name: "no pubspec.yaml",
+		},
+		{
+			name:    "no executables",
+			pubspec: `name: test`,
+		},
+		{
+			name: "executables",
+			pubspec: `
+name: example_server
+
+executables:
+  server:
+  worker: queue_worker
+  migrate:
+`,
+			want: []Executable{
+				{Name: "migrate", Script: "bin/migrate.dart"},
+				{Name: "server", Script: "bin/server.dart"},
+				{Name: "worker", Script: "bin/queue_worker.dart"},
+			},
+		},
+		{
+			name:    "invalid yaml",
+			pubspec: "\t",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tc.pubspec != "" {
+				path := filepath.Join(dir, "pubspec.yaml")
+				if err := os.WriteFile(path, []byte(tc.pubspec), 0744); err != nil {
+					t.Fatalf("writing %s: %v", path, err)
+				}
+			}
+			got, err := Executables(dir)
+			if tc.wantErr == (err == nil) {
+				t.Errorf("Executables(%q) got error: %v, want err? %t", dir, err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Executables(%q) mismatch (-want +got):\n%s", dir, diff)
+			}
+		})
+	}
+}
+
+func TestMissingParts(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{
+			name: "no sources",
+		},
+		{
+			name: "no parts",
+			files: map[string]string{
+				"bin/server.dart": "import 'package:shelf/shelf.dart';",
+			},
+		},
+		{
+			name: "generated parts present",
+			files: map[string]string{
+				"lib/src/user.dart":   "import 'package:json_annotation/json_annotation.dart';\n\npart 'user.g.dart';\n",
+				"lib/src/user.g.dart": "part of 'user.dart';\n",
+			},
+		},
+		{
+			name: "generated parts missing",
+			files: map[string]string{
+				"bin/server.dart":       "part \"server.g.dart\";\n",
+				"lib/src/user.dart":     "part 'user.g.dart';\npart 'user.freezed.dart';\n",
+				"lib/src/user.g.dart":   "part of 'user.dart';\n",
+				"test/user_test.dart":   "part 'user_test.mocks.dart';\n",
+				"lib/src/model.dart":    "// part 'model.g.dart';\n",
+				"lib/src/readme.txt":    "part 'readme.g.dart';\n",
+				"tool/generate.dart":    "part 'generate.g.dart';\n",
+				"lib/src/account.dart":  "part of 'user.dart';\n",
+				"lib/src/settings.dart": "  part 'settings.g.dart';\n",
+			},
+			want: []string{"bin/server.g.dart", "lib/src/settings.g.dart", "lib/src/user.freezed.dart"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tc.files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("creating directory of %s: %v", path, err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatalf("writing %s: %v", path, err)
+				}
+			}
+			got, err := MissingParts(dir)
+			if err != nil {
+				t.Fatalf("MissingParts(%q) got error: %v", dir, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("MissingParts(%q) mismatch (-want +got):\n%s", dir, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dotnet
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/ar"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+const nugetOrgURL = "https://api.nuget.org/v3/index.json"
+
+var (
+	// nugetConfigNames are the file names of nuget.config files that NuGet reads on Linux.
+	nugetConfigNames = []string{"nuget.config", "NuGet.config", "NuGet.Config"}
+	// credentialElementRegexp matches the package source names that can be used as the element names
+	// of packageSourceCredentials, once spaces are encoded.
+	credentialElementRegexp = regexp.MustCompile(`^[A-Za-z_][\w.-]*$`)
+)
+
+// nugetCredential is the login for a NuGet package source, identified by its name.
+type nugetCredential struct {
+	Source   string
+	Username string
+	Password string
+}
+
+// nugetConfig is the package sources section of a nuget.config file.
+type nugetConfig struct {
+	PackageSources []struct {
+		Key   string `xml:"key,attr"`
+		Value string `xml:"value,attr"`
+	} `xml:"packageSources>add"`
+}
+
+// NuGetCredentialsEnv returns the environment for `dotnet restore` to authenticate to private
+// NuGet feeds, from GOOGLE_NUGET_CREDENTIALS and Application Default Credentials for the Artifact
+// Registry feeds of the nuget.config of the application or the project. The credentials are
+// written to the user-level NuGet config of a temporary home directory, outside of the layers and
+// the application, which NuGet merges with the nuget.config of the application. The environment
+// only references the directory, because commands and their environment are logged. The returned
+// cleanup function removes the directory and must be called once the restore is complete.
+func NuGetCredentialsEnv(ctx *gcp.Context, proj string) ([]string, func(), error) {
+	cleanup := func() {}
+	creds, err := parseNuGetCredentials(os.Getenv(env.NuGetCredentials))
+	if err != nil {
+		return nil, cleanup, err
+	}
+	arCreds, err := artifactRegistryCredentials(ctx, proj, creds)
+	if err != nil {
+		return nil, cleanup, err
+	}
+	creds = append(creds, arCreds...)
+	if len(creds) == 0 {
+		return nil, cleanup, nil
+	}
+
+	content, err := userConfigContent(creds)
+	if err != nil {
+		return nil, cleanup, err
+	}
+	home, err := os.MkdirTemp("", "nuget-home-")
+	if err != nil {
+		return nil, cleanup, gcp.InternalErrorf("creating NuGet home directory: %v", err)
+	}
+	cleanup = func() {
+		if err := os.RemoveAll(home); err != nil {
+			ctx.Warnf("Failed to remove NuGet home directory %s: %v", home, err)
+		}
+	}
+	dir := filepath.Join(home, ".nuget", "NuGet")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		cleanup()
+		return nil, func() {}, gcp.InternalErrorf("creating NuGet config directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "NuGet.Config"), content, 0600); err != nil {
+		cleanup()
+		return nil, func() {}, gcp.InternalErrorf("writing NuGet config: %v", err)
+	}
+	sources := make([]string, len(creds))
+	for i, c := range creds {
+		sources[i] = c.Source
+	}
+	ctx.Logf("Configured credentials for NuGet package sources: %s", strings.Join(sources, ", "))
+	return []string{"HOME=" + home}, cleanup, nil
+}
+
+// parseNuGetCredentials parses the comma-separated list of credentials in
+// GOOGLE_NUGET_CREDENTIALS.
+func parseNuGetCredentials(val string) ([]nugetCredential, error) {
+	var creds []nugetCredential
+	for _, entry := range strings.Split(val, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		// Do not include the value in the errors, it contains a secret.
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, gcp.UserErrorf("invalid entry in %s, each entry must be of the form <source-name>=<username>:<password>", env.NuGetCredentials)
+		}
+		if !credentialElementRegexp.MatchString(credentialElement(parts[0])) {
+			return nil, gcp.UserErrorf("invalid package source name %q in %s", parts[0], env.NuGetCredentials)
+		}
+		login := strings.SplitN(parts[1], ":", 2)
+		if len(login) != 2 || login[0] == "" || login[1] == "" {
+			return nil, gcp.UserErrorf("invalid entry for package source %q in %s, the credentials must be of the form <username>:<password>", parts[0], env.NuGetCredentials)
+		}
+		creds = append(creds, nugetCredential{Source: parts[0], Username: login[0], Password: login[1]})
+	}
+	return creds, nil
+}
+
+// artifactRegistryCredentials returns the credentials of the Artifact Registry package sources of
+// the nuget.config files in the application root and the project directory that are not
+// configured explicitly.
+func artifactRegistryCredentials(ctx *gcp.Context, proj string, configured []nugetCredential) ([]nugetCredential, error) {
+	seen := map[string]bool{}
+	for _, c := range configured {
+		seen[c.Source] = true
+	}
+	dirs := []string{ctx.ApplicationRoot()}
+	projDir := filepath.Dir(proj)
+	if !filepath.IsAbs(projDir) {
+		projDir = filepath.Join(ctx.ApplicationRoot(), projDir)
+	}
+	if filepath.Clean(projDir) != filepath.Clean(ctx.ApplicationRoot()) {
+		dirs = append(dirs, projDir)
+	}
+	var sources []string
+	for _, dir := range dirs {
+		for _, name := range nugetConfigNames {
+			path := filepath.Join(dir, name)
+			exists, err := ctx.FileExists(path)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				continue
+			}
+			content, err := ctx.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			var cfg nugetConfig
+			if err := xml.Unmarshal(content, &cfg); err != nil {
+				return nil, gcp.UserErrorf("unmarshalling %s: %v", path, err)
+			}
+			for _, s := range cfg.PackageSources {
+				if s.Key != "" && !seen[s.Key] && ar.IsNuGetRepository(s.Value) && credentialElementRegexp.MatchString(credentialElement(s.Key)) {
+					seen[s.Key] = true
+					sources = append(sources, s.Key)
+				}
+			}
+		}
+	}
+	if len(sources) == 0 {
+		return nil, nil
+	}
+	username, password, err := ar.NuGetCredentials()
+	if err != nil {
+		// Application Default Credentials are missing when running the buildpacks locally outside of
+		// GCB. Credentials might not be required for the build to succeed so we should not fail here.
+		ctx.Warnf("Skipping Artifact Registry credentials for %s. Unable to find Application Default Credentials: %v", strings.Join(sources, ", "), err)
+		return nil, nil
+	}
+	sort.Strings(sources)
+	var creds []nugetCredential
+	for _, s := range sources {
+		creds = append(creds, nugetCredential{Source: s, Username: username, Password: password})
+	}
+	return creds, nil
+}
+
+// credentialElement returns the element name of a package source in packageSourceCredentials, in
+// which NuGet encodes spaces.
+func credentialElement(source string) string {
+	return strings.ReplaceAll(source, " ", "_x0020_")
+}
+
+// userConfigContent returns a user-level NuGet config with the credentials. Like the config that
+// NuGet creates by default, it declares the nuget.org package source, which the nuget.config of
+// the application can clear.
+func userConfigContent(creds []nugetCredential) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString("<configuration>\n")
+	b.WriteString("  <packageSources>\n")
+	b.WriteString(`    <add key="nuget.org" value="` + nugetOrgURL + `" protocolVersion="3" />` + "\n")
+	b.WriteString("  </packageSources>\n")
+	b.WriteString("  <packageSourceCredentials>\n")
+	for _, c := range creds {
+		element := credentialElement(c.Source)
+		b.WriteString("    <" + element + ">\n")
+		for _, kv := range [][2]string{{"Username", c.Username}, {"ClearTextPassword", c.Password}} {
+			b.WriteString(`      <add key="` + kv[0] + `" value="`)
+			if err := xml.EscapeText(&b, []byte(kv[1])); err != nil {
+				return nil, gcp.InternalErrorf("escaping NuGet credentials: %v", err)
+			}
+			b.WriteString("\" />\n")
+		}
+		b.WriteString("    </" + element + ">\n")
+	}
+	b.WriteString("  </packageSourceCredentials>\n")
+	b.WriteString("</configuration>\n")
+	return b.Bytes(), nil
+}
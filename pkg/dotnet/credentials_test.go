@@ -0,0 +1,144 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dotnet
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseNuGetCredentials(t *testing.T) {
+	testCases := []struct {
+		name    string
+		val     string
+		want    []nugetCredential
+		wantErr bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "multiple sources",
+			val:  "my-feed=user:s3cr3t, github=my-user:ghp_token:with:colons",
+			want: []nugetCredential{
+				{Source: "my-feed", Username: "user", Password: "s3cr3t"},
+				{Source: "github", Username: "my-user", Password: "ghp_token:with:colons"},
+			},
+		},
+		{
+			name: "source with spaces",
+			val:  "My Feed=user:s3cr3t",
+			want: []nugetCredential{{Source: "My Feed", Username: "user", Password: "s3cr3t"}},
+		},
+		{
+			name:    "missing source",
+			val:     "user:s3cr3t",
+			wantErr: true,
+		},
+		{
+			name:    "missing password",
+			val:     "my-feed=user",
+			wantErr: true,
+		},
+		{
+			name:    "invalid source name",
+			val:     "my<feed>=user:s3cr3t",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseNuGetCredentials(tc.val)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("parseNuGetCredentials() got error: %v, want error: %v", err, tc.wantErr)
+			}
+			if err != nil && strings.Contains(err.Error(), "s3cr3t") {
+				t.Errorf("parseNuGetCredentials() error %q contains the password", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("parseNuGetCredentials() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNuGetCredentialsEnv(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv(env.NuGetCredentials, `My Feed=user:p&ss"word`)
+
+	ctx := gcp.NewContext(gcp.WithApplicationRoot(root))
+	got, cleanup, err := NuGetCredentialsEnv(ctx, filepath.Join("src", "Web", "Web.csproj"))
+	if err != nil {
+		t.Fatalf("NuGetCredentialsEnv() got error: %v", err)
+	}
+	if len(got) != 1 || !strings.HasPrefix(got[0], "HOME=") {
+		t.Fatalf("NuGetCredentialsEnv() = %v, want a HOME entry", got)
+	}
+	home := strings.TrimPrefix(got[0], "HOME=")
+	if strings.HasPrefix(home, root) {
+		t.Errorf("NuGetCredentialsEnv() home %s is inside the application %s", home, root)
+	}
+	config := filepath.Join(home, ".nuget", "NuGet", "NuGet.Config")
+	content, err := os.ReadFile(config)
+	if err != nil {
+		t.Fatalf("reading %s: %v", config, err)
+	}
+	for _, want := range []string{
+		`<add key="nuget.org" value="https://api.nuget.org/v3/index.json" protocolVersion="3" />`,
+		"<My_x0020_Feed>",
+		`<add key="Username" value="user" />`,
+		`<add key="ClearTextPassword" value="p&amp;ss&#34;word" />`,
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("NuGet config does not contain %q:\n%s", want, content)
+		}
+	}
+	fi, err := os.Stat(config)
+	if err != nil {
+		t.Fatalf("stat %s: %v", config, err)
+	}
+	if perm := fi.Mode().Perm(); perm != 0600 {
+		t.Errorf("NuGet config permissions = %v, want 0600", perm)
+	}
+
+	cleanup()
+	if _, err := os.Stat(home); !os.IsNotExist(err) {
+		t.Errorf("NuGet home %s exists after cleanup, got error: %v", home, err)
+	}
+}
+
+func TestNuGetCredentialsEnvWithoutCredentials(t *testing.T) {
+	root := t.TempDir()
+	nugetConfig := `<configuration><packageSources><add key="nuget.org" value="https://api.nuget.org/v3/index.json" /></packageSources></configuration>`
+	if err := os.WriteFile(filepath.Join(root, "nuget.config"), []byte(nugetConfig), 0644); err != nil {
+		t.Fatalf("writing nuget.config: %v", err)
+	}
+	t.Setenv(env.NuGetCredentials, "")
+
+	got, cleanup, err := NuGetCredentialsEnv(gcp.NewContext(gcp.WithApplicationRoot(root)), "app.csproj")
+	defer cleanup()
+	if err != nil {
+		t.Fatalf("NuGetCredentialsEnv() got error: %v", err)
+	}
+	if got != nil {
+		t.Errorf("NuGetCredentialsEnv() = %v, want nil", got)
+	}
+}
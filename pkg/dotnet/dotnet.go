@@ -65,6 +65,7 @@ type PropertyGroup struct {
 	AssemblyName     string `xml:"AssemblyName"`
 	TargetFramework  string `xml:"TargetFramework"`
 	TargetFrameworks string `xml:"TargetFrameworks"`
+	PublishAot       string `xml:"PublishAot"`
 }
 
 // ItemGroup contains information about a project item group.
@@ -157,7 +158,9 @@ func ReadRuntimeConfigJSON(path string) (*RuntimeConfigJSON, error) {
 // globalJSON represents the contents of a global.json file.
 type globalJSON struct {
 	Sdk struct {
-		Version string `json:"version"`
+		Version         string `json:"version"`
+		RollForward     string `json:"rollForward"`
+		AllowPrerelease *bool  `json:"allowPrerelease"`
 	} `json:"sdk"`
 }
 
@@ -220,6 +223,9 @@ func FindProjectFile(ctx *gcp.Context) (string, error) {
 		if err != nil {
 			return "", err
 		}
+		if len(projFiles) > 1 {
+			return "", gcp.UserErrorf("expected to find exactly one project file in directory %s, found %v, set %s to the project to publish, e.g. %s", proj, projFiles, env.Buildable, filepath.Join("src", "Web", "Web.csproj"))
+		}
 		if len(projFiles) != 1 {
 			return "", gcp.UserErrorf("expected to find exactly one project file in directory %s, found %v", proj, projFiles)
 		}
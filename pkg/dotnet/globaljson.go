@@ -0,0 +1,184 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dotnet
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/Masterminds/semver"
+)
+
+// The rollForward policies of global.json, see
+// https://learn.microsoft.com/en-us/dotnet/core/tools/global-json#rollforward.
+const (
+	rollForwardPatch         = "patch"
+	rollForwardFeature       = "feature"
+	rollForwardMinor         = "minor"
+	rollForwardMajor         = "major"
+	rollForwardLatestPatch   = "latestPatch"
+	rollForwardLatestFeature = "latestFeature"
+	rollForwardLatestMinor   = "latestMinor"
+	rollForwardLatestMajor   = "latestMajor"
+	rollForwardDisable       = "disable"
+)
+
+// sdkVersion is a .NET SDK version, e.g. 8.0.203, which is patch 3 of feature band 8.0.200.
+type sdkVersion struct {
+	*semver.Version
+}
+
+// featureBand returns the feature band of the version, e.g. 2 for 8.0.203.
+func (v sdkVersion) featureBand() int64 {
+	return v.Patch() / 100
+}
+
+// ResolveSDKVersion returns the .NET SDK version to install. If the version is pinned by
+// global.json, and not overridden by GOOGLE_DOTNET_SDK_VERSION or GOOGLE_RUNTIME_VERSION, it is
+// resolved from the available versions according to the rollForward policy of global.json, which
+// defaults to patch. Otherwise it returns the version of GetSDKVersion.
+func ResolveSDKVersion(ctx *gcp.Context, available func() ([]string, error)) (string, error) {
+	if os.Getenv(envSdkVersion) != "" || os.Getenv(env.RuntimeVersion) != "" {
+		return GetSDKVersion(ctx)
+	}
+	gjs, err := getGlobalJSONOrNil(ctx.ApplicationRoot())
+	if err != nil {
+		return "", err
+	}
+	if gjs == nil || gjs.Sdk.Version == "" {
+		return GetSDKVersion(ctx)
+	}
+	versions, err := available()
+	if err != nil {
+		return "", err
+	}
+	v, err := resolveRollForward(gjs.Sdk.Version, gjs.Sdk.RollForward, gjs.Sdk.AllowPrerelease, versions)
+	if err != nil {
+		return "", err
+	}
+	ctx.Logf("Using .NET Core SDK version %s for version %s and rollForward %s in global.json", v, gjs.Sdk.Version, rollForwardPolicy(gjs.Sdk.RollForward))
+	return v, nil
+}
+
+// rollForwardPolicy returns the rollForward policy, patch if it is not set.
+func rollForwardPolicy(policy string) string {
+	if policy == "" {
+		return rollForwardPatch
+	}
+	return policy
+}
+
+// resolveRollForward returns the version that the rollForward policy selects for the requested
+// version among the available versions. As the SDK is installed rather than selected from the
+// installed SDKs, the policies that prefer the requested version if it is installed select it if
+// it is available. Prerelease versions are only selected if allowed, or if the requested version
+// is one.
+func resolveRollForward(requested, policy string, allowPrerelease *bool, available []string) (string, error) {
+	policy = rollForwardPolicy(policy)
+	rv, err := semver.NewVersion(requested)
+	if err != nil {
+		return "", gcp.UserErrorf("invalid .NET SDK version %q in global.json: %v", requested, err)
+	}
+	req := sdkVersion{rv}
+	prerelease := req.Prerelease() != "" || (allowPrerelease != nil && *allowPrerelease)
+
+	var candidates []sdkVersion
+	for _, a := range available {
+		v, err := semver.NewVersion(a)
+		if err != nil || v.LessThan(req.Version) || (v.Prerelease() != "" && !prerelease) {
+			continue
+		}
+		candidates = append(candidates, sdkVersion{v})
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].LessThan(candidates[j].Version) })
+
+	sameBand := func(v sdkVersion) bool {
+		return v.Major() == req.Major() && v.Minor() == req.Minor() && v.featureBand() == req.featureBand()
+	}
+	sameMinor := func(v sdkVersion) bool { return v.Major() == req.Major() && v.Minor() == req.Minor() }
+	sameMajor := func(v sdkVersion) bool { return v.Major() == req.Major() }
+	anyVersion := func(v sdkVersion) bool { return true }
+
+	var v *sdkVersion
+	switch policy {
+	case rollForwardDisable:
+		v = exact(candidates, req)
+	case rollForwardPatch:
+		if v = exact(candidates, req); v == nil {
+			v = latest(candidates, sameBand)
+		}
+	case rollForwardFeature:
+		v = nearestBand(candidates, sameMinor)
+	case rollForwardMinor:
+		v = nearestBand(candidates, sameMajor)
+	case rollForwardMajor:
+		v = nearestBand(candidates, anyVersion)
+	case rollForwardLatestPatch:
+		v = latest(candidates, sameBand)
+	case rollForwardLatestFeature:
+		v = latest(candidates, sameMinor)
+	case rollForwardLatestMinor:
+		v = latest(candidates, sameMajor)
+	case rollForwardLatestMajor:
+		v = latest(candidates, anyVersion)
+	default:
+		return "", gcp.UserErrorf("invalid rollForward %q in global.json, must be one of %s", policy, strings.Join([]string{
+			rollForwardPatch, rollForwardFeature, rollForwardMinor, rollForwardMajor, rollForwardLatestPatch,
+			rollForwardLatestFeature, rollForwardLatestMinor, rollForwardLatestMajor, rollForwardDisable,
+		}, ", "))
+	}
+	if v == nil {
+		return "", gcp.UserErrorf("no available .NET SDK version satisfies version %s with rollForward %s in global.json, the %d.%d.%dxx feature band is not available, update global.json or use a less restrictive rollForward policy", requested, policy, req.Major(), req.Minor(), req.featureBand())
+	}
+	return v.Original(), nil
+}
+
+// exact returns the requested version if it is available.
+func exact(candidates []sdkVersion, req sdkVersion) *sdkVersion {
+	for i := range candidates {
+		if candidates[i].Equal(req.Version) {
+			return &candidates[i]
+		}
+	}
+	return nil
+}
+
+// latest returns the latest available version that matches.
+func latest(candidates []sdkVersion, match func(sdkVersion) bool) *sdkVersion {
+	for i := len(candidates) - 1; i >= 0; i-- {
+		if match(candidates[i]) {
+			return &candidates[i]
+		}
+	}
+	return nil
+}
+
+// nearestBand returns the latest patch level of the lowest available feature band that matches,
+// i.e. the requested feature band if it is available.
+func nearestBand(candidates []sdkVersion, match func(sdkVersion) bool) *sdkVersion {
+	for i := range candidates {
+		if !match(candidates[i]) {
+			continue
+		}
+		first := candidates[i]
+		return latest(candidates, func(v sdkVersion) bool {
+			return v.Major() == first.Major() && v.Minor() == first.Minor() && v.featureBand() == first.featureBand()
+		})
+	}
+	return nil
+}
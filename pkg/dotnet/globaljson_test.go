@@ -0,0 +1,120 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dotnet
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+var testSDKVersions = []string{
+	"6.0.100", "6.0.101", "6.0.300", "6.0.301",
+	"7.0.100", "7.0.102", "7.0.200", "7.0.203", "7.0.400",
+	"8.0.100-rc.2.23502.2", "8.0.100", "8.0.101", "8.0.200",
+	"9.0.100-preview.1.24101.2",
+}
+
+func TestResolveRollForward(t *testing.T) {
+	allow := true
+	testCases := []struct {
+		name            string
+		version         string
+		policy          string
+		allowPrerelease *bool
+		want            string
+		wantErr         bool
+	}{
+		{name: "patch default uses available version", version: "7.0.100", want: "7.0.100"},
+		{name: "patch rolls to latest patch", version: "7.0.101", want: "7.0.102"},
+		{name: "patch does not change feature band", version: "6.0.102", wantErr: true},
+		{name: "feature uses latest patch of band", version: "7.0.100", policy: "feature", want: "7.0.102"},
+		{name: "feature rolls to next band", version: "7.0.104", policy: "feature", want: "7.0.203"},
+		{name: "feature does not change minor", version: "6.0.302", policy: "feature", wantErr: true},
+		{name: "minor rolls to next minor", version: "6.0.302", policy: "minor", wantErr: true},
+		{name: "major rolls to next major", version: "6.0.302", policy: "major", want: "7.0.102"},
+		{name: "latestPatch", version: "7.0.200", policy: "latestPatch", want: "7.0.203"},
+		{name: "latestFeature", version: "7.0.100", policy: "latestFeature", want: "7.0.400"},
+		{name: "latestMinor", version: "6.0.100", policy: "latestMinor", want: "6.0.301"},
+		{name: "latestMajor", version: "6.0.100", policy: "latestMajor", want: "8.0.200"},
+		{name: "disable", version: "7.0.200", policy: "disable", want: "7.0.200"},
+		{name: "disable unavailable", version: "7.0.201", policy: "disable", wantErr: true},
+		{name: "prerelease not allowed by default", version: "8.0.100-preview.1", policy: "latestPatch", want: "8.0.101"},
+		{name: "prerelease allowed", version: "8.0.100", policy: "latestMajor", allowPrerelease: &allow, want: "9.0.100-preview.1.24101.2"},
+		{name: "requested prerelease allows prerelease", version: "8.0.100-preview.1", policy: "latestMajor", want: "9.0.100-preview.1.24101.2"},
+		{name: "invalid policy", version: "7.0.100", policy: "nearest", wantErr: true},
+		{name: "invalid version", version: "seven", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := resolveRollForward(tc.version, tc.policy, tc.allowPrerelease, testSDKVersions)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("resolveRollForward(%q, %q) got error: %v, want error: %v", tc.version, tc.policy, err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("resolveRollForward(%q, %q) = %q, want %q", tc.version, tc.policy, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestResolveSDKVersion(t *testing.T) {
+	testCases := []struct {
+		name       string
+		globalJSON string
+		env        string
+		want       string
+	}{
+		{
+			name:       "global.json with rollForward",
+			globalJSON: `{"sdk": {"version": "7.0.100", "rollForward": "latestFeature"}}`,
+			want:       "7.0.400",
+		},
+		{
+			name:       "environment overrides global.json",
+			globalJSON: `{"sdk": {"version": "7.0.100", "rollForward": "latestFeature"}}`,
+			env:        "6.0.100",
+			want:       "6.0.100",
+		},
+		{
+			name: "no global.json",
+			want: "",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			if tc.globalJSON != "" {
+				if err := ioutil.WriteFile(filepath.Join(root, "global.json"), []byte(tc.globalJSON), 0644); err != nil {
+					t.Fatalf("writing global.json: %v", err)
+				}
+			}
+			t.Setenv(envSdkVersion, tc.env)
+			t.Setenv(env.RuntimeVersion, "")
+
+			ctx := gcp.NewContext(gcp.WithApplicationRoot(root))
+			got, err := ResolveSDKVersion(ctx, func() ([]string, error) { return testSDKVersions, nil })
+			if err != nil {
+				t.Fatalf("ResolveSDKVersion() got error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("ResolveSDKVersion() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
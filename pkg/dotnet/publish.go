@@ -0,0 +1,225 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dotnet
+
+import (
+	"os"
+	goruntime "runtime"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+const (
+	// EnvPublishAOT is the environment variable used to publish the application as a Native AOT
+	// binary, which does not require the .NET runtime.
+	EnvPublishAOT = "GOOGLE_DOTNET_PUBLISH_AOT"
+	// EnvPublishMode is the environment variable used to choose between framework-dependent,
+	// self-contained and Native AOT deployments. The publish buildpack sets it to the mode of the
+	// published application for the subsequent buildpacks.
+	EnvPublishMode = "GOOGLE_DOTNET_PUBLISH_MODE"
+	// EnvPublishReadyToRun is the environment variable used to compile the assemblies of the
+	// application to ReadyToRun code, which reduces the startup time and increases the size.
+	EnvPublishReadyToRun = "GOOGLE_DOTNET_PUBLISH_READY_TO_RUN"
+	// EnvPublishTrimmed is the environment variable used to trim the unused parts of the .NET
+	// runtime from self-contained deployments, which are trimmed by default.
+	EnvPublishTrimmed = "GOOGLE_DOTNET_PUBLISH_TRIMMED"
+)
+
+// PublishMode is the deployment mode of a published application.
+type PublishMode string
+
+const (
+	// FrameworkDependent applications run on the .NET runtime installed in a separate layer.
+	FrameworkDependent PublishMode = "framework-dependent"
+	// SelfContained applications include the .NET runtime, trimmed to the parts that they use.
+	SelfContained PublishMode = "self-contained"
+	// NativeAOT applications are compiled ahead of time to a native binary.
+	NativeAOT PublishMode = "native-aot"
+)
+
+// RequiresRuntime returns true if applications published with the mode require the .NET runtime.
+func (m PublishMode) RequiresRuntime() bool {
+	return m == FrameworkDependent
+}
+
+// PublishOptions are the options of `dotnet publish`.
+type PublishOptions struct {
+	Mode       PublishMode
+	ReadyToRun bool
+	Trimmed    bool
+}
+
+// GetPublishOptions returns the deployment mode of the application and the ReadyToRun and
+// trimming options of GOOGLE_DOTNET_PUBLISH_READY_TO_RUN and GOOGLE_DOTNET_PUBLISH_TRIMMED.
+func GetPublishOptions(ctx *gcp.Context, proj string) (PublishOptions, error) {
+	mode, err := GetPublishMode(ctx, proj)
+	if err != nil {
+		return PublishOptions{}, err
+	}
+	opts := PublishOptions{Mode: mode}
+	if opts.ReadyToRun, _, err = envBool(EnvPublishReadyToRun); err != nil {
+		return PublishOptions{}, err
+	}
+	trimmed, set, err := envBool(EnvPublishTrimmed)
+	if err != nil {
+		return PublishOptions{}, err
+	}
+	switch mode {
+	case FrameworkDependent:
+		if trimmed {
+			return PublishOptions{}, gcp.UserErrorf("%s requires a %s deployment, set %s=%s", EnvPublishTrimmed, SelfContained, EnvPublishMode, SelfContained)
+		}
+	case SelfContained:
+		opts.Trimmed = trimmed || !set
+	case NativeAOT:
+		// Native AOT binaries are always trimmed and do not contain ReadyToRun code.
+		if opts.ReadyToRun {
+			ctx.Warnf("Ignoring %s, Native AOT applications are compiled to native code.", EnvPublishReadyToRun)
+			opts.ReadyToRun = false
+		}
+	}
+	return opts, nil
+}
+
+// Args returns the arguments of `dotnet restore` and `dotnet publish` for the options. Restore
+// requires the same runtime identifier and properties as publish to restore the runtime packs and
+// compilers that publish uses.
+func (o PublishOptions) Args() []string {
+	var args []string
+	switch o.Mode {
+	case FrameworkDependent:
+		// ReadyToRun code is specific to the target platform.
+		if o.ReadyToRun {
+			args = append(args, "--runtime", RuntimeIdentifier(), "--self-contained", "false")
+		}
+	case SelfContained:
+		args = append(args, "--runtime", RuntimeIdentifier(), "--self-contained", "true")
+	case NativeAOT:
+		args = append(args, "--runtime", RuntimeIdentifier(), "-p:PublishAot=true")
+	}
+	if o.ReadyToRun {
+		args = append(args, "-p:PublishReadyToRun=true")
+	}
+	if o.Trimmed {
+		args = append(args, "-p:PublishTrimmed=true")
+	}
+	return args
+}
+
+// envBool returns the boolean value of the environment variable and whether it is set.
+func envBool(name string) (bool, bool, error) {
+	if _, ok := os.LookupEnv(name); !ok {
+		return false, false, nil
+	}
+	v, err := env.IsPresentAndTrue(name)
+	if err != nil {
+		return false, true, gcp.UserErrorf("%v", err)
+	}
+	return v, true, nil
+}
+
+// GetPublishMode returns the deployment mode of the application: Native AOT if requested with
+// GOOGLE_DOTNET_PUBLISH_AOT or the PublishAot property of the project file, otherwise the mode of
+// GOOGLE_DOTNET_PUBLISH_MODE, framework-dependent by default.
+func GetPublishMode(ctx *gcp.Context, proj string) (PublishMode, error) {
+	aot, err := PublishAOT(ctx, proj)
+	if err != nil {
+		return "", err
+	}
+	mode, err := PublishModeFromEnv()
+	if err != nil {
+		return "", err
+	}
+	if aot {
+		if mode == FrameworkDependent && os.Getenv(EnvPublishMode) != "" {
+			return "", gcp.UserErrorf("Native AOT applications cannot be published as %s, unset %s or disable Native AOT", FrameworkDependent, EnvPublishMode)
+		}
+		return NativeAOT, nil
+	}
+	return mode, nil
+}
+
+// PublishModeFromEnv returns the deployment mode of GOOGLE_DOTNET_PUBLISH_MODE, framework-dependent
+// if it is not set. After the publish buildpack, it is the mode of the published application.
+func PublishModeFromEnv() (PublishMode, error) {
+	v := strings.ToLower(strings.TrimSpace(os.Getenv(EnvPublishMode)))
+	switch PublishMode(v) {
+	case "", FrameworkDependent:
+		return FrameworkDependent, nil
+	case SelfContained, NativeAOT:
+		return PublishMode(v), nil
+	}
+	return "", gcp.UserErrorf("invalid %s %q, must be one of %s, %s or %s", EnvPublishMode, v, FrameworkDependent, SelfContained, NativeAOT)
+}
+
+// aotToolchain are the commands the .NET SDK requires to link Native AOT binaries on Linux.
+var aotToolchain = []string{"clang", "objcopy"}
+
+// PublishAOT returns true if the application is published as a Native AOT binary, as requested
+// with GOOGLE_DOTNET_PUBLISH_AOT or the PublishAot property of the project file.
+func PublishAOT(ctx *gcp.Context, proj string) (bool, error) {
+	aot, set, err := envBool(EnvPublishAOT)
+	if err != nil {
+		return false, err
+	}
+	if set {
+		return aot, nil
+	}
+	p, err := ReadProjectFile(ctx, proj)
+	if err != nil {
+		return false, err
+	}
+	return p.PublishAOT(), nil
+}
+
+// PublishAOT returns true if the project enables Native AOT publishing with the PublishAot
+// property.
+func (p Project) PublishAOT() bool {
+	for _, pg := range p.PropertyGroups {
+		if strings.EqualFold(strings.TrimSpace(pg.PublishAot), "true") {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckAOTToolchain returns a user error if the build image lacks the native toolchain that Native
+// AOT publishing requires.
+func CheckAOTToolchain(ctx *gcp.Context) error {
+	var missing []string
+	for _, cmd := range aotToolchain {
+		result, err := ctx.Exec([]string{"bash", "-c", "command -v " + cmd + " || true"})
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(result.Stdout) == "" {
+			missing = append(missing, cmd)
+		}
+	}
+	if len(missing) > 0 {
+		return gcp.UserErrorf("Native AOT publishing requires %s in the build image, install them or unset %s and PublishAot", strings.Join(missing, ", "), EnvPublishAOT)
+	}
+	return nil
+}
+
+// RuntimeIdentifier returns the .NET runtime identifier of the build platform, e.g. linux-x64.
+func RuntimeIdentifier() string {
+	if goruntime.GOARCH == "arm64" {
+		return "linux-arm64"
+	}
+	return "linux-x64"
+}
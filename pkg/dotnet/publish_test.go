@@ -0,0 +1,244 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dotnet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+func TestPublishAOT(t *testing.T) {
+	testCases := []struct {
+		name    string
+		env     string
+		project string
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:    "not enabled",
+			project: `<Project Sdk="Microsoft.NET.Sdk.Web"><PropertyGroup><TargetFramework>net8.0</TargetFramework></PropertyGroup></Project>`,
+		},
+		{
+			name:    "PublishAot in project file",
+			project: `<Project Sdk="Microsoft.NET.Sdk.Web"><PropertyGroup><TargetFramework>net8.0</TargetFramework></PropertyGroup><PropertyGroup><PublishAot>True</PublishAot></PropertyGroup></Project>`,
+			want:    true,
+		},
+		{
+			name:    "PublishAot false in project file",
+			project: `<Project Sdk="Microsoft.NET.Sdk.Web"><PropertyGroup><PublishAot>false</PublishAot></PropertyGroup></Project>`,
+		},
+		{
+			name:    "environment enables",
+			env:     "true",
+			project: `<Project Sdk="Microsoft.NET.Sdk.Web"></Project>`,
+			want:    true,
+		},
+		{
+			name:    "environment disables",
+			env:     "false",
+			project: `<Project Sdk="Microsoft.NET.Sdk.Web"><PropertyGroup><PublishAot>true</PublishAot></PropertyGroup></Project>`,
+		},
+		{
+			name:    "invalid environment",
+			env:     "sometimes",
+			project: `<Project Sdk="Microsoft.NET.Sdk.Web"></Project>`,
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			proj := filepath.Join(t.TempDir(), "app.csproj")
+			if err := ioutil.WriteFile(proj, []byte(tc.project), 0644); err != nil {
+				t.Fatalf("writing project file: %v", err)
+			}
+			if tc.env != "" {
+				t.Setenv(EnvPublishAOT, tc.env)
+			} else {
+				os.Unsetenv(EnvPublishAOT)
+			}
+
+			got, err := PublishAOT(gcp.NewContext(), proj)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("PublishAOT() got error: %v, want error: %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("PublishAOT() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGetPublishMode(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mode    string
+		aot     string
+		project string
+		want    PublishMode
+		wantErr bool
+	}{
+		{
+			name: "default",
+			want: FrameworkDependent,
+		},
+		{
+			name: "framework-dependent",
+			mode: "framework-dependent",
+			want: FrameworkDependent,
+		},
+		{
+			name: "self-contained",
+			mode: "Self-Contained",
+			want: SelfContained,
+		},
+		{
+			name:    "PublishAot in project file",
+			project: `<PropertyGroup><PublishAot>true</PublishAot></PropertyGroup>`,
+			want:    NativeAOT,
+		},
+		{
+			name: "Native AOT from environment with self-contained",
+			mode: "self-contained",
+			aot:  "true",
+			want: NativeAOT,
+		},
+		{
+			name:    "Native AOT with framework-dependent",
+			mode:    "framework-dependent",
+			project: `<PropertyGroup><PublishAot>true</PublishAot></PropertyGroup>`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid mode",
+			mode:    "portable",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			proj := filepath.Join(t.TempDir(), "app.csproj")
+			content := `<Project Sdk="Microsoft.NET.Sdk.Web">` + tc.project + `</Project>`
+			if err := ioutil.WriteFile(proj, []byte(content), 0644); err != nil {
+				t.Fatalf("writing project file: %v", err)
+			}
+			t.Setenv(EnvPublishMode, tc.mode)
+			if tc.aot != "" {
+				t.Setenv(EnvPublishAOT, tc.aot)
+			} else {
+				os.Unsetenv(EnvPublishAOT)
+			}
+
+			got, err := GetPublishMode(gcp.NewContext(), proj)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("GetPublishMode() got error: %v, want error: %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("GetPublishMode() = %q, want %q", got, tc.want)
+			}
+			if !tc.wantErr && got.RequiresRuntime() != (got == FrameworkDependent) {
+				t.Errorf("%q.RequiresRuntime() = %v, want %v", got, got.RequiresRuntime(), got == FrameworkDependent)
+			}
+		})
+	}
+}
+
+func TestGetPublishOptions(t *testing.T) {
+	rid := RuntimeIdentifier()
+	testCases := []struct {
+		name     string
+		env      map[string]string
+		project  string
+		want     PublishOptions
+		wantArgs []string
+		wantErr  bool
+	}{
+		{
+			name: "framework-dependent",
+			want: PublishOptions{Mode: FrameworkDependent},
+		},
+		{
+			name:     "framework-dependent with ReadyToRun",
+			env:      map[string]string{EnvPublishReadyToRun: "true"},
+			want:     PublishOptions{Mode: FrameworkDependent, ReadyToRun: true},
+			wantArgs: []string{"--runtime", rid, "--self-contained", "false", "-p:PublishReadyToRun=true"},
+		},
+		{
+			name:    "framework-dependent trimmed",
+			env:     map[string]string{EnvPublishTrimmed: "true"},
+			wantErr: true,
+		},
+		{
+			name:     "self-contained is trimmed by default",
+			env:      map[string]string{EnvPublishMode: "self-contained"},
+			want:     PublishOptions{Mode: SelfContained, Trimmed: true},
+			wantArgs: []string{"--runtime", rid, "--self-contained", "true", "-p:PublishTrimmed=true"},
+		},
+		{
+			name:     "self-contained untrimmed with ReadyToRun",
+			env:      map[string]string{EnvPublishMode: "self-contained", EnvPublishTrimmed: "false", EnvPublishReadyToRun: "true"},
+			want:     PublishOptions{Mode: SelfContained, ReadyToRun: true},
+			wantArgs: []string{"--runtime", rid, "--self-contained", "true", "-p:PublishReadyToRun=true"},
+		},
+		{
+			name:     "Native AOT ignores ReadyToRun",
+			env:      map[string]string{EnvPublishReadyToRun: "true"},
+			project:  `<PropertyGroup><PublishAot>true</PublishAot></PropertyGroup>`,
+			want:     PublishOptions{Mode: NativeAOT},
+			wantArgs: []string{"--runtime", rid, "-p:PublishAot=true"},
+		},
+		{
+			name:    "invalid ReadyToRun",
+			env:     map[string]string{EnvPublishReadyToRun: "fast"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			proj := filepath.Join(t.TempDir(), "app.csproj")
+			content := `<Project Sdk="Microsoft.NET.Sdk.Web">` + tc.project + `</Project>`
+			if err := ioutil.WriteFile(proj, []byte(content), 0644); err != nil {
+				t.Fatalf("writing project file: %v", err)
+			}
+			for _, name := range []string{EnvPublishAOT, EnvPublishMode, EnvPublishReadyToRun, EnvPublishTrimmed} {
+				if v, ok := tc.env[name]; ok {
+					t.Setenv(name, v)
+				} else {
+					t.Setenv(name, "")
+					os.Unsetenv(name)
+				}
+			}
+
+			got, err := GetPublishOptions(gcp.NewContext(), proj)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("GetPublishOptions() got error: %v, want error: %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if got != tc.want {
+				t.Errorf("GetPublishOptions() = %+v, want %+v", got, tc.want)
+			}
+			if !reflect.DeepEqual(got.Args(), tc.wantArgs) {
+				t.Errorf("%+v.Args() = %v, want %v", got, got.Args(), tc.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dotnet
+
+import (
+	"sort"
+	"strings"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+// EnvNuGetPackages is the environment variable that sets the NuGet global packages folder.
+const EnvNuGetPackages = "NUGET_PACKAGES"
+
+// RestoreInputFiles returns the files under dir that determine the packages restored by
+// `dotnet restore`, sorted by path: the project files, the packages.lock.json lock files, the
+// Directory.Build.* and Directory.Packages.props files shared by projects, the nuget.config files
+// and global.json.
+func RestoreInputFiles(ctx *gcp.Context, dir string) ([]string, error) {
+	result, err := ctx.Exec([]string{"find", dir, "-type", "f", "(",
+		"-regex", `.*\.\(cs\|fs\|vb\)proj`,
+		"-o", "-name", "packages.lock.json",
+		"-o", "-name", "Directory.Build.props",
+		"-o", "-name", "Directory.Build.targets",
+		"-o", "-name", "Directory.Packages.props",
+		"-o", "-iname", "nuget.config",
+		"-o", "-name", "global.json",
+		")"}, gcp.WithUserTimingAttribution)
+	if err != nil {
+		return nil, err
+	}
+	stdout := strings.TrimSpace(result.Stdout)
+	if stdout == "" {
+		return nil, nil
+	}
+	files := strings.Split(stdout, "\n")
+	// The order of find is not stable across builds, which would invalidate hashes of the files.
+	sort.Strings(files)
+	return files, nil
+}
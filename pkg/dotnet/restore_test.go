@@ -0,0 +1,95 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dotnet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRestoreInputFiles(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files []string
+		want  []string
+	}{
+		{
+			name: "no files",
+		},
+		{
+			name:  "single project",
+			files: []string{"app.csproj", "Program.cs"},
+			want:  []string{"app.csproj"},
+		},
+		{
+			name: "solution with lock files and shared props",
+			files: []string{
+				"app.sln",
+				"global.json",
+				"NuGet.Config",
+				"Directory.Build.props",
+				"Directory.Packages.props",
+				"src/web/web.csproj",
+				"src/web/packages.lock.json",
+				"src/web/Program.cs",
+				"src/lib/lib.fsproj",
+				"src/lib/packages.lock.json",
+				"src/lib/Directory.Build.targets",
+			},
+			want: []string{
+				"Directory.Build.props",
+				"Directory.Packages.props",
+				"NuGet.Config",
+				"global.json",
+				"src/lib/Directory.Build.targets",
+				"src/lib/lib.fsproj",
+				"src/lib/packages.lock.json",
+				"src/web/packages.lock.json",
+				"src/web/web.csproj",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			for _, f := range tc.files {
+				path := filepath.Join(root, f)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("creating directory for %s: %v", f, err)
+				}
+				if err := ioutil.WriteFile(path, []byte(f), 0644); err != nil {
+					t.Fatalf("writing %s: %v", f, err)
+				}
+			}
+			var want []string
+			for _, f := range tc.want {
+				want = append(want, filepath.Join(root, f))
+			}
+
+			got, err := RestoreInputFiles(gcp.NewContext(gcp.WithApplicationRoot(root)), root)
+			if err != nil {
+				t.Fatalf("RestoreInputFiles() got error: %v", err)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("RestoreInputFiles() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dotnet
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+// solutionProjectRegexp matches the project entries of a solution file, e.g.
+// `Project("{FAE04EC0-301F-11D3-BF4B-00C04F79EFBC}") = "Web", "src\Web\Web.csproj", "{...}"`.
+var solutionProjectRegexp = regexp.MustCompile(`(?m)^Project\("\{[^}]*\}"\)\s*=\s*"[^"]*",\s*"([^"]+\.(?:cs|fs|vb)proj)"`)
+
+// SolutionFile returns the solution file in the application root that lists the project, which
+// is restored instead of the project so that the restore is shared by all projects of the
+// solution. It returns an empty string if there is no such solution file.
+func SolutionFile(ctx *gcp.Context, proj string) (string, error) {
+	slns, err := ctx.Glob(filepath.Join(ctx.ApplicationRoot(), "*.sln"))
+	if err != nil {
+		return "", fmt.Errorf("finding solution files: %w", err)
+	}
+	// Project paths such as GOOGLE_BUILDABLE are relative to the application root.
+	projPath := filepath.Clean(proj)
+	if !filepath.IsAbs(projPath) {
+		projPath = filepath.Join(ctx.ApplicationRoot(), projPath)
+	}
+	for _, sln := range slns {
+		projects, err := solutionProjects(ctx, sln)
+		if err != nil {
+			return "", err
+		}
+		for _, p := range projects {
+			if p == projPath {
+				return sln, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// solutionProjects returns the paths of the projects listed in the solution file.
+func solutionProjects(ctx *gcp.Context, sln string) ([]string, error) {
+	content, err := ctx.ReadFile(sln)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(sln)
+	var projects []string
+	for _, m := range solutionProjectRegexp.FindAllStringSubmatch(string(content), -1) {
+		// Solution files are usually created on Windows and use backslashes.
+		rel := filepath.FromSlash(strings.ReplaceAll(m[1], `\`, "/"))
+		projects = append(projects, filepath.Join(dir, rel))
+	}
+	return projects, nil
+}
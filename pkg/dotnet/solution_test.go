@@ -0,0 +1,88 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dotnet
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+const testSolution = `
+Microsoft Visual Studio Solution File, Format Version 12.00
+# Visual Studio Version 17
+Project("{2150E333-8FDC-42A3-9474-1A3956D46DE8}") = "src", "src", "{6D7E1F0B-1C2D-4E5F-8A9B-0C1D2E3F4A5B}"
+EndProject
+Project("{FAE04EC0-301F-11D3-BF4B-00C04F79EFBC}") = "Web", "src\Web\Web.csproj", "{3C2E6E1A-8F1B-4B6A-9C1D-2E3F4A5B6C7D}"
+EndProject
+Project("{9A19103F-16F7-4668-BE54-9A1E7A4F7556}") = "Core", "src\Core\Core.fsproj", "{4D3F7F2B-9A2C-4C7B-AD2E-3F4A5B6C7D8E}"
+EndProject
+Global
+EndGlobal
+`
+
+func TestSolutionFile(t *testing.T) {
+	testCases := []struct {
+		name     string
+		solution string
+		proj     string
+		want     string
+	}{
+		{
+			name:     "project in solution",
+			solution: testSolution,
+			proj:     filepath.Join("src", "Web", "Web.csproj"),
+			want:     "app.sln",
+		},
+		{
+			name:     "project with dot prefix",
+			solution: testSolution,
+			proj:     "./src/Core/Core.fsproj",
+			want:     "app.sln",
+		},
+		{
+			name:     "project not in solution",
+			solution: testSolution,
+			proj:     filepath.Join("tools", "Tool", "Tool.csproj"),
+		},
+		{
+			name: "no solution",
+			proj: filepath.Join("src", "Web", "Web.csproj"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			if tc.solution != "" {
+				if err := ioutil.WriteFile(filepath.Join(root, "app.sln"), []byte(tc.solution), 0644); err != nil {
+					t.Fatalf("writing solution file: %v", err)
+				}
+			}
+			got, err := SolutionFile(gcp.NewContext(gcp.WithApplicationRoot(root)), tc.proj)
+			if err != nil {
+				t.Fatalf("SolutionFile() got error: %v", err)
+			}
+			want := ""
+			if tc.want != "" {
+				want = filepath.Join(root, tc.want)
+			}
+			if got != want {
+				t.Errorf("SolutionFile() = %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ package fetch
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
 	"encoding/json"
 	"io"
@@ -60,6 +61,28 @@ func JSON(url string, v interface{}) error {
 	return nil
 }
 
+// PostJSON posts a JSON payload to a URL and unmarshalls the JSON response into the value pointed
+// to by v.
+func PostJSON(url string, payload, v interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return gcp.InternalErrorf("encoding request to %q: %v", url, err)
+	}
+	response, err := do("POST", url, body)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	respBody, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return gcp.InternalErrorf("reading response body from %q: %v", url, err)
+	}
+	if err := json.Unmarshal(respBody, v); err != nil {
+		return gcp.InternalErrorf("decoding response from %q: %v", url, err)
+	}
+	return nil
+}
+
 // GetURL makes an HTTP GET request to given URL and writes the body to the provided writer.
 func GetURL(url string, f io.Writer) error {
 	response, err := doGet(url)
@@ -181,14 +204,26 @@ func isValidTarDestination(dest, rootDir string, tarType byte) bool {
 
 // doGet performs an HTTP GET request for a URL.
 func doGet(url string) (*http.Response, error) {
+	return do("GET", url, nil)
+}
+
+// do performs an HTTP request for a URL, with a JSON body if body is not nil.
+func do(method, url string, body []byte) (*http.Response, error) {
 	retryClient := retryablehttp.NewClient()
 	retryClient.RetryMax = 3
-	req, err := http.NewRequest("GET", url, nil)
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, url, r)
 	if err != nil {
 		return nil, gcp.UserErrorf("fetching %s: %v", url, err)
 	}
 
 	req.Header.Set("User-Agent", gcpUserAgent)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
 	response, err := retryClient.StandardClient().Do(req)
 	if err != nil {
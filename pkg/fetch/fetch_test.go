@@ -16,8 +16,10 @@ package fetch
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
@@ -134,6 +136,64 @@ func TestJSON(t *testing.T) {
 	}
 }
 
+func TestPostJSON(t *testing.T) {
+	testCases := []struct {
+		name       string
+		httpStatus int
+		response   string
+		wantError  bool
+		want       map[string]string
+	}{
+		{
+			name:     "success",
+			response: `{"foo": "bar"}`,
+			want:     map[string]string{"foo": "bar"},
+		},
+		{
+			name:       "bad request",
+			httpStatus: http.StatusBadRequest,
+			wantError:  true,
+		},
+		{
+			name:       "invalid json",
+			response:   "foo bar",
+			httpStatus: http.StatusOK,
+			wantError:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotRequest map[string]string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "POST" || r.Header.Get("Content-Type") != "application/json" {
+					t.Errorf("got %s request with content type %q, want POST with application/json", r.Method, r.Header.Get("Content-Type"))
+				}
+				if err := json.NewDecoder(r.Body).Decode(&gotRequest); err != nil {
+					t.Errorf("decoding request: %v", err)
+				}
+				if tc.httpStatus != 0 {
+					w.WriteHeader(tc.httpStatus)
+				}
+				w.Write([]byte(tc.response))
+			}))
+			t.Cleanup(server.Close)
+
+			var got map[string]string
+			err := PostJSON(server.URL, map[string]string{"query": "foo"}, &got)
+			if tc.wantError == (err == nil) {
+				t.Fatalf("PostJSON(%q, payload, &got) got error: %v, want error? %v", server.URL, err, tc.wantError)
+			}
+			if !cmp.Equal(got, tc.want) {
+				t.Errorf("PostJSON(%q, payload, &got) = %v, want %v", server.URL, got, tc.want)
+			}
+			if want := map[string]string{"query": "foo"}; !cmp.Equal(gotRequest, want) {
+				t.Errorf("PostJSON(%q, payload, &got) sent %v, want %v", server.URL, gotRequest, want)
+			}
+		})
+	}
+}
+
 func TestGetURL(t *testing.T) {
 	testCases := []struct {
 		name       string
@@ -16,21 +16,75 @@ package gcpbuildpack
 
 import (
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
 	"github.com/buildpacks/libcnb"
 )
 
+// functionProcessRegexp matches the function targets that can be used as process names.
+var functionProcessRegexp = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// FunctionTargets returns the function targets of the build. The first target is the one served
+// by the web process: GOOGLE_FUNCTION_TARGET if it is set, otherwise the first target of
+// GOOGLE_FUNCTION_TARGETS.
+func FunctionTargets() ([]string, error) {
+	target, hasTarget := os.LookupEnv(env.FunctionTarget)
+	var targets []string
+	seen := map[string]bool{}
+	for _, t := range strings.Split(os.Getenv(env.FunctionTargets), ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		if !functionProcessRegexp.MatchString(t) || t == WebProcess {
+			return nil, UserErrorf("invalid function target %q in %s, targets must only contain letters, digits, '_', '.' and '-' and must not be %q", t, env.FunctionTargets, WebProcess)
+		}
+		if seen[t] {
+			return nil, UserErrorf("duplicate function target %q in %s", t, env.FunctionTargets)
+		}
+		seen[t] = true
+		targets = append(targets, t)
+	}
+	if len(targets) == 0 {
+		if !hasTarget {
+			return nil, UserErrorf("required env var %s not found", env.FunctionTarget)
+		}
+		if target == "" {
+			return nil, UserErrorf("required env var %s has an empty value", env.FunctionTarget)
+		}
+		return []string{target}, nil
+	}
+	if !hasTarget {
+		return targets, nil
+	}
+	if !seen[target] {
+		return nil, UserErrorf("%s=%q must be one of the targets of %s", env.FunctionTarget, target, env.FunctionTargets)
+	}
+	result := []string{target}
+	for _, t := range targets {
+		if t != target {
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
+
 // SetFunctionsEnvVars sets launch-time functions environment variables.
 func (ctx *Context) SetFunctionsEnvVars(l *libcnb.Layer) error {
-	target, ok := os.LookupEnv(env.FunctionTarget)
-	if !ok {
-		return UserErrorf("required env var %s not found", env.FunctionTarget)
+	targets, err := FunctionTargets()
+	if err != nil {
+		return err
 	}
-	if target == "" {
-		return UserErrorf("required env var %s has an empty value", env.FunctionTarget)
+	l.LaunchEnvironment.Default(env.FunctionTargetLaunch, targets[0])
+	if len(targets) > 1 {
+		// Each target is served by the process of the same name.
+		for _, t := range targets {
+			l.LaunchEnvironment.ProcessOverride(t, env.FunctionTargetLaunch, t)
+		}
 	}
-	l.LaunchEnvironment.Default(env.FunctionTargetLaunch, target)
 	if signature, ok := os.LookupEnv(env.FunctionSignatureType); ok {
 		l.LaunchEnvironment.Default(env.FunctionSignatureTypeLaunch, signature)
 	}
@@ -39,3 +93,110 @@ func (ctx *Context) SetFunctionsEnvVars(l *libcnb.Layer) error {
 	}
 	return nil
 }
+
+// AddFunctionProcesses adds the web process that serves the function with the given command, and
+// if GOOGLE_FUNCTION_TARGETS lists several targets, a process of the same command for each target,
+// which SetFunctionsEnvVars configures to serve it.
+func (ctx *Context) AddFunctionProcesses(cmd []string) error {
+	targets, err := FunctionTargets()
+	if err != nil {
+		return err
+	}
+	ctx.AddWebProcess(cmd)
+	if len(targets) > 1 {
+		for _, t := range targets {
+			ctx.AddProcess(t, cmd, AsDirectProcess())
+		}
+		ctx.Logf("Added processes for function targets: %s", strings.Join(targets, ", "))
+	}
+	return nil
+}
+
+// functionSourceDir returns the directory of the application that GOOGLE_FUNCTION_SOURCE names
+// for a function, e.g. in a repository of several functions, or an empty string if it is not set,
+// does not name a directory, or the build is not for a function.
+func functionSourceDir(appRoot string) (string, error) {
+	source := os.Getenv(env.FunctionSource)
+	if source == "" {
+		return "", nil
+	}
+	_, hasTarget := os.LookupEnv(env.FunctionTarget)
+	_, hasTargets := os.LookupEnv(env.FunctionTargets)
+	if !hasTarget && !hasTargets {
+		return "", nil
+	}
+	dir := source
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(appRoot, dir)
+	}
+	fi, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", InternalErrorf("stating %s: %v", dir, err)
+	}
+	if !fi.IsDir() {
+		return "", nil
+	}
+	rel, err := filepath.Rel(appRoot, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", UserErrorf("%s=%q must be a directory of the application", env.FunctionSource, source)
+	}
+	if rel == "." {
+		return "", nil
+	}
+	return dir, nil
+}
+
+// WithFunctionSourceDir runs the buildpack in the directory of GOOGLE_FUNCTION_SOURCE if it names a
+// directory of the application, see useFunctionSourceDir. It is used by the language buildpacks
+// that detect, install the dependencies of, build and serve a function. The other buildpacks, e.g.
+// the ones that archive, scan or describe the source, keep the application root.
+func WithFunctionSourceDir() MainOption {
+	return func(c *mainConfig) {
+		c.functionSourceDir = true
+	}
+}
+
+// detectInFunctionSourceDir returns a DetectFn that runs d in the directory of
+// GOOGLE_FUNCTION_SOURCE.
+func detectInFunctionSourceDir(d DetectFn) DetectFn {
+	return func(ctx *Context) (DetectResult, error) {
+		if err := ctx.useFunctionSourceDir(); err != nil {
+			return nil, err
+		}
+		return d(ctx)
+	}
+}
+
+// buildInFunctionSourceDir returns a BuildFn that runs b in the directory of GOOGLE_FUNCTION_SOURCE.
+func buildInFunctionSourceDir(b BuildFn) BuildFn {
+	return func(ctx *Context) error {
+		if err := ctx.useFunctionSourceDir(); err != nil {
+			return err
+		}
+		return b(ctx)
+	}
+}
+
+// useFunctionSourceDir makes the directory of GOOGLE_FUNCTION_SOURCE the application root and the
+// working directory of the buildpack, so that the function is detected, its dependencies installed
+// and it is built as if the directory were the root of the application. GOOGLE_FUNCTION_SOURCE is
+// then unset for the buildpack, as it names the application root rather than a file in it, and
+// AddProcess runs the processes in the directory.
+func (ctx *Context) useFunctionSourceDir() error {
+	dir, err := functionSourceDir(ctx.applicationRoot)
+	if err != nil || dir == "" {
+		return err
+	}
+	if err := os.Chdir(dir); err != nil {
+		return InternalErrorf("changing to function source directory %s: %v", dir, err)
+	}
+	if err := os.Unsetenv(env.FunctionSource); err != nil {
+		return InternalErrorf("unsetting %s: %v", env.FunctionSource, err)
+	}
+	ctx.Debugf("Using function source directory %s as the application root.", dir)
+	ctx.applicationRoot = dir
+	return nil
+}
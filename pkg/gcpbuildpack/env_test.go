@@ -0,0 +1,286 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcpbuildpack
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	"github.com/buildpacks/libcnb"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFunctionTargets(t *testing.T) {
+	testCases := []struct {
+		name    string
+		env     map[string]string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "target",
+			env:  map[string]string{env.FunctionTarget: "hello"},
+			want: []string{"hello"},
+		},
+		{
+			name: "targets",
+			env:  map[string]string{env.FunctionTargets: "hello, world ,events"},
+			want: []string{"hello", "world", "events"},
+		},
+		{
+			name: "target served by the web process",
+			env:  map[string]string{env.FunctionTarget: "world", env.FunctionTargets: "hello,world,events"},
+			want: []string{"world", "hello", "events"},
+		},
+		{
+			name: "java class targets",
+			env:  map[string]string{env.FunctionTargets: "com.example.Hello,com.example.World"},
+			want: []string{"com.example.Hello", "com.example.World"},
+		},
+		{
+			name:    "no target",
+			wantErr: true,
+		},
+		{
+			name:    "empty target",
+			env:     map[string]string{env.FunctionTarget: ""},
+			wantErr: true,
+		},
+		{
+			name:    "target not in targets",
+			env:     map[string]string{env.FunctionTarget: "other", env.FunctionTargets: "hello,world"},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate target",
+			env:     map[string]string{env.FunctionTargets: "hello,world,hello"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid process name",
+			env:     map[string]string{env.FunctionTargets: "hello,hello world"},
+			wantErr: true,
+		},
+		{
+			name:    "web target",
+			env:     map[string]string{env.FunctionTargets: "hello,web"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			unsetFunctionEnv(t)
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			got, err := FunctionTargets()
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("FunctionTargets() got error: %v, want error: %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("FunctionTargets() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAddFunctionProcesses(t *testing.T) {
+	unsetFunctionEnv(t)
+	t.Setenv(env.FunctionTargets, "hello,events")
+	ctx := NewContext()
+	l := &libcnb.Layer{LaunchEnvironment: libcnb.Environment{}}
+
+	if err := ctx.SetFunctionsEnvVars(l); err != nil {
+		t.Fatalf("SetFunctionsEnvVars() got error: %v", err)
+	}
+	if err := ctx.AddFunctionProcesses([]string{"functions-framework"}); err != nil {
+		t.Fatalf("AddFunctionProcesses() got error: %v", err)
+	}
+
+	wantEnv := libcnb.Environment{
+		"FUNCTION_TARGET.default":         "hello",
+		"hello/FUNCTION_TARGET.override":  "hello",
+		"events/FUNCTION_TARGET.override": "events",
+	}
+	if diff := cmp.Diff(wantEnv, l.LaunchEnvironment); diff != "" {
+		t.Errorf("launch environment mismatch (-want +got):\n%s", diff)
+	}
+	wantProcesses := []libcnb.Process{
+		{Type: "web", Command: "functions-framework", Direct: true, Default: true},
+		{Type: "hello", Command: "functions-framework", Direct: true},
+		{Type: "events", Command: "functions-framework", Direct: true},
+	}
+	if diff := cmp.Diff(wantProcesses, ctx.buildResult.Processes); diff != "" {
+		t.Errorf("processes mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFunctionSourceDir(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{filepath.Join(root, "functions", "hello"), filepath.Join(filepath.Dir(root), "outside")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("creating %s: %v", dir, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "main.py"), nil, 0644); err != nil {
+		t.Fatalf("writing main.py: %v", err)
+	}
+
+	testCases := []struct {
+		name    string
+		source  string
+		target  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "not set",
+			target: "hello",
+		},
+		{
+			name:   "directory",
+			source: "functions/hello",
+			target: "hello",
+			want:   filepath.Join(root, "functions", "hello"),
+		},
+		{
+			name:   "absolute directory",
+			source: filepath.Join(root, "functions", "hello"),
+			target: "hello",
+			want:   filepath.Join(root, "functions", "hello"),
+		},
+		{
+			name:   "file",
+			source: "main.py",
+			target: "hello",
+		},
+		{
+			name:   "missing",
+			source: "functions/missing",
+			target: "hello",
+		},
+		{
+			name:   "application root",
+			source: "./",
+			target: "hello",
+		},
+		{
+			name:   "not a function",
+			source: "functions/hello",
+		},
+		{
+			name:    "outside of the application",
+			source:  "../outside",
+			target:  "hello",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			unsetFunctionEnv(t)
+			t.Setenv(env.FunctionSource, tc.source)
+			if tc.target != "" {
+				t.Setenv(env.FunctionTarget, tc.target)
+			}
+
+			got, err := functionSourceDir(root)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("functionSourceDir() got error: %v, want error: %t", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("functionSourceDir() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuildInFunctionSourceDir(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "functions", "hello")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("creating %s: %v", dir, err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getting working directory: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	testCases := []struct {
+		name     string
+		build    func(BuildFn) BuildFn
+		wantRoot string
+	}{
+		{
+			name:     "opted in",
+			build:    buildInFunctionSourceDir,
+			wantRoot: dir,
+		},
+		{
+			name:     "not opted in",
+			build:    func(b BuildFn) BuildFn { return b },
+			wantRoot: root,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			unsetFunctionEnv(t)
+			t.Setenv(env.FunctionTarget, "hello")
+			t.Setenv(env.FunctionSource, "functions/hello")
+			ctx := NewContext(WithApplicationRoot(root))
+
+			var gotRoot string
+			b := tc.build(func(ctx *Context) error {
+				gotRoot = ctx.ApplicationRoot()
+				return nil
+			})
+			if err := b(ctx); err != nil {
+				t.Fatalf("build got error: %v", err)
+			}
+			if gotRoot != tc.wantRoot {
+				t.Errorf("ApplicationRoot() = %q, want %q", gotRoot, tc.wantRoot)
+			}
+		})
+	}
+}
+
+func TestAddProcessInFunctionSourceDir(t *testing.T) {
+	root := t.TempDir()
+	ctx := NewContext(WithApplicationRoot(filepath.Join(root, "functions", "hello")))
+	ctx.buildContext.Application.Path = root
+
+	ctx.AddWebProcess([]string{"functions-framework"})
+
+	want := []libcnb.Process{
+		{Type: "web", Command: "functions-framework", Direct: true, Default: true, WorkingDirectory: filepath.Join(root, "functions", "hello")},
+	}
+	if diff := cmp.Diff(want, ctx.buildResult.Processes); diff != "" {
+		t.Errorf("processes mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// unsetFunctionEnv unsets the function target env vars for the duration of the test.
+func unsetFunctionEnv(t *testing.T) {
+	t.Helper()
+	for _, k := range []string{env.FunctionTarget, env.FunctionTargets} {
+		k := k
+		if v, ok := os.LookupEnv(k); ok {
+			t.Cleanup(func() { os.Setenv(k, v) })
+			os.Unsetenv(k)
+		}
+	}
+}
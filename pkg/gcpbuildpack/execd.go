@@ -0,0 +1,71 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcpbuildpack
+
+import (
+	"path/filepath"
+
+	"github.com/buildpacks/libcnb"
+)
+
+const (
+	// buildpackExecutable is the path of the buildpack binary relative to the buildpack root.
+	buildpackExecutable = "bin/main"
+	execDDir            = "exec.d"
+)
+
+// ExecDFn is the callback signature for an exec.d helper. It returns environment variables to add
+// to the launch environment, see https://github.com/buildpacks/spec/blob/main/buildpack.md#execd.
+type ExecDFn func() (map[string]string, error)
+
+// Execute implements libcnb.ExecD.
+func (fn ExecDFn) Execute() (map[string]string, error) {
+	return fn()
+}
+
+type mainConfig struct {
+	execDs map[string]libcnb.ExecD
+	// functionSourceDir is true if the buildpack runs in the directory of GOOGLE_FUNCTION_SOURCE.
+	functionSourceDir bool
+}
+
+// MainOption configures Main.
+type MainOption func(c *mainConfig)
+
+// WithExecD registers an exec.d helper. When the buildpack binary is invoked under the given name it
+// runs fn instead of detect or build. Use ctx.AddExecD to install the helper into a launch layer.
+func WithExecD(name string, fn ExecDFn) MainOption {
+	return func(c *mainConfig) {
+		c.execDs[name] = fn
+	}
+}
+
+// AddExecD installs the exec.d helper registered with WithExecD under name into layer l. The helper
+// is a copy of the buildpack binary, so it remains available in the run image.
+func (ctx *Context) AddExecD(l *libcnb.Layer, name string) error {
+	if !l.Launch {
+		return InternalErrorf("exec.d helper %q requires %q to be a launch layer", name, l.Name)
+	}
+	bin, err := ctx.ReadFile(filepath.Join(ctx.BuildpackRoot(), buildpackExecutable))
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(l.Path, execDDir)
+	if err := ctx.MkdirAll(dir, layerMode); err != nil {
+		return err
+	}
+	ctx.Debugf("Adding exec.d helper %s to layer %s", name, l.Name)
+	return ctx.WriteFile(filepath.Join(dir, name), bin, 0755)
+}
@@ -0,0 +1,76 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcpbuildpack
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+)
+
+func TestAddExecD(t *testing.T) {
+	bpRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(bpRoot, "bin"), 0755); err != nil {
+		t.Fatalf("creating bin directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(bpRoot, buildpackExecutable), []byte("binary"), 0755); err != nil {
+		t.Fatalf("writing buildpack executable: %v", err)
+	}
+	ctx := NewContext(WithBuildpackRoot(bpRoot))
+	l := &libcnb.Layer{Name: "some-layer", Path: t.TempDir(), LayerTypes: libcnb.LayerTypes{Launch: true}}
+
+	if err := ctx.AddExecD(l, "helper"); err != nil {
+		t.Fatalf("AddExecD() got error: %v", err)
+	}
+
+	helper := filepath.Join(l.Path, "exec.d", "helper")
+	info, err := os.Stat(helper)
+	if err != nil {
+		t.Fatalf("stat %q: %v", helper, err)
+	}
+	if info.Mode().Perm()&0111 == 0 {
+		t.Errorf("exec.d helper %q is not executable, mode: %v", helper, info.Mode())
+	}
+}
+
+func TestAddExecDRequiresLaunchLayer(t *testing.T) {
+	ctx := NewContext(WithBuildpackRoot(t.TempDir()))
+	l := &libcnb.Layer{Name: "some-layer", Path: t.TempDir()}
+
+	if err := ctx.AddExecD(l, "helper"); err == nil {
+		t.Error("AddExecD() got nil error, want error for non-launch layer")
+	}
+}
+
+func TestWithExecD(t *testing.T) {
+	c := mainConfig{execDs: map[string]libcnb.ExecD{}}
+	WithExecD("helper", func() (map[string]string, error) {
+		return map[string]string{"FOO": "bar"}, nil
+	})(&c)
+
+	e, ok := c.execDs["helper"]
+	if !ok {
+		t.Fatalf("WithExecD() did not register helper")
+	}
+	got, err := e.Execute()
+	if err != nil {
+		t.Fatalf("Execute() got error: %v", err)
+	}
+	if got["FOO"] != "bar" {
+		t.Errorf("Execute() = %v, want FOO=bar", got)
+	}
+}
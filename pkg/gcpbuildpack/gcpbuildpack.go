@@ -224,14 +224,26 @@ func (ctx *Context) Processes() []libcnb.Process {
 	return ctx.buildResult.Processes
 }
 
-// Main is the main entrypoint to a buildpack's detect and build functions.
-func Main(d DetectFn, b BuildFn) {
-	switch filepath.Base(os.Args[0]) {
+// Main is the main entrypoint to a buildpack's detect and build functions, and to any exec.d
+// helpers registered with WithExecD.
+func Main(d DetectFn, b BuildFn, opts ...MainOption) {
+	c := mainConfig{execDs: map[string]libcnb.ExecD{}}
+	for _, o := range opts {
+		o(&c)
+	}
+	if c.functionSourceDir {
+		d, b = detectInFunctionSourceDir(d), buildInFunctionSourceDir(b)
+	}
+	switch cmd := filepath.Base(os.Args[0]); cmd {
 	case "detect":
 		detect(d)
 	case "build":
 		build(b)
 	default:
+		if _, ok := c.execDs[cmd]; ok {
+			libcnb.RunExecD(c.execDs)
+			return
+		}
 		defaultLogger.Print("Unknown command, expected 'detect' or 'build'.")
 		os.Exit(1)
 	}
@@ -407,6 +419,11 @@ func AsDefaultProcess() processOption {
 	return func(o *libcnb.Process) { o.Default = true }
 }
 
+// InWorkingDirectory runs the process in dir rather than in the application root.
+func InWorkingDirectory(dir string) processOption {
+	return func(o *libcnb.Process) { o.WorkingDirectory = dir }
+}
+
 // AddProcess adds the given command as named process, overwriting any previous process with the same name.
 func (ctx *Context) AddProcess(name string, cmd []string, opts ...processOption) {
 	current := ctx.buildResult.Processes
@@ -425,6 +442,10 @@ func (ctx *Context) AddProcess(name string, cmd []string, opts ...processOption)
 	if len(cmd) > 1 {
 		p.Arguments = cmd[1:]
 	}
+	if root := ctx.buildContext.Application.Path; root != "" && ctx.applicationRoot != root {
+		// The application root is the function source directory, see useFunctionSourceDir.
+		p.WorkingDirectory = ctx.applicationRoot
+	}
 	for _, opt := range opts {
 		opt(&p)
 	}
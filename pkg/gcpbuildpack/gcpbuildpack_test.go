@@ -356,6 +356,15 @@ func TestAddProcess(t *testing.T) {
 				libcnb.Process{Command: "/start", Arguments: []string{"arg1", "arg2"}, Type: "foo", Direct: true, Default: true},
 			},
 		},
+		{
+			desc: "with opts, working directory",
+			name: "foo",
+			cmd:  []string{"/start"},
+			opts: []processOption{InWorkingDirectory("/workspace/apps/web")},
+			want: []libcnb.Process{
+				libcnb.Process{Command: "/start", Type: "foo", WorkingDirectory: "/workspace/apps/web"},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
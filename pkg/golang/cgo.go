@@ -0,0 +1,110 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+// lookPath finds an executable in PATH. It can be overridden for testing.
+var lookPath = exec.LookPath
+
+// CgoConfig is the cgo and linking configuration of a build, from GOOGLE_GO_CGO_ENABLED and
+// GOOGLE_GO_STATIC.
+type CgoConfig struct {
+	// Enabled is the value of CGO_ENABLED, or an empty string to use the default of the go command,
+	// which enables cgo when a C compiler is available.
+	Enabled string
+	// Static is true if a statically linked binary is requested.
+	Static bool
+}
+
+// ReadCgoConfig returns the cgo configuration of the build. When cgo is explicitly enabled, it
+// checks that the C compiler is available, so that the build does not fail with an obscure error.
+func ReadCgoConfig(ctx *gcp.Context) (CgoConfig, error) {
+	var cfg CgoConfig
+	static, err := env.IsPresentAndTrue(env.GoStatic)
+	if err != nil {
+		return cfg, gcp.UserErrorf("%v", err)
+	}
+	cfg.Static = static
+
+	if v, ok := os.LookupEnv(env.GoCgoEnabled); ok {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return cfg, gcp.UserErrorf("parsing %s: %v", env.GoCgoEnabled, err)
+		}
+		cfg.Enabled = "0"
+		if enabled {
+			cfg.Enabled = "1"
+		}
+	} else if cfg.Static {
+		// Without cgo, the go command produces static binaries by itself.
+		cfg.Enabled = "0"
+	}
+
+	if cfg.Enabled == "1" {
+		cc := cCompiler()
+		if _, err := lookPath(cc); err != nil {
+			return cfg, gcp.UserErrorf("%s is true but the C compiler %q was not found, use a builder image with a C toolchain or set CC", env.GoCgoEnabled, cc)
+		}
+	}
+	if cfg.Enabled != "" {
+		ctx.Logf("Building with CGO_ENABLED=%s", cfg.Enabled)
+	}
+	if cfg.Static {
+		ctx.Logf("Building a statically linked binary")
+	}
+	return cfg, nil
+}
+
+// cCompiler returns the C compiler used by cgo.
+func cCompiler() string {
+	if cc := strings.Fields(os.Getenv("CC")); len(cc) > 0 {
+		return cc[0]
+	}
+	return "gcc"
+}
+
+// Env returns the environment variables for the go command.
+func (c CgoConfig) Env() []string {
+	if c.Enabled == "" {
+		return nil
+	}
+	return []string{"CGO_ENABLED=" + c.Enabled}
+}
+
+// Tags returns the build tags required for static linking: the pure Go implementations of the
+// net and os/user packages do not depend on the dynamically loaded libc resolvers.
+func (c CgoConfig) Tags() []string {
+	if !c.Static {
+		return nil
+	}
+	return []string{"netgo", "osusergo"}
+}
+
+// LDFlags returns the linker flags required for static linking with cgo.
+func (c CgoConfig) LDFlags() []string {
+	if !c.Static || c.Enabled != "1" {
+		return nil
+	}
+	return []string{"-linkmode=external", "-extldflags=-static"}
+}
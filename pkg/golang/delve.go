@@ -0,0 +1,111 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/buildpacks/libcnb"
+)
+
+const (
+	// DebugProcess is the name of the process that runs the application under Delve.
+	DebugProcess = "debug"
+	// DebugGCFlags disables optimizations and inlining, so that Delve can inspect all variables.
+	DebugGCFlags = "all=-N -l"
+
+	delveLayer       = "delve"
+	delveModule      = "github.com/go-delve/delve/cmd/dlv"
+	delveVersion     = "1.21.0"
+	delveVersionKey  = "version"
+	defaultDebugPort = 40000
+)
+
+// DebugEnabled returns true if GOOGLE_GO_DEBUG requests a debug build.
+func DebugEnabled() (bool, error) {
+	enabled, err := env.IsPresentAndTrue(env.GoDebug)
+	if err != nil {
+		return false, gcp.UserErrorf("%v", err)
+	}
+	return enabled, nil
+}
+
+// DebugPort returns the port Delve listens on, from GOOGLE_GO_DEBUG_PORT.
+func DebugPort() (int, error) {
+	v := os.Getenv(env.GoDebugPort)
+	if v == "" {
+		return defaultDebugPort, nil
+	}
+	port, err := strconv.Atoi(v)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, gcp.UserErrorf("invalid %s %q, it must be a port number", env.GoDebugPort, v)
+	}
+	return port, nil
+}
+
+// InstallDelve installs the Delve debugger in a launch layer and returns the path of dlv.
+func InstallDelve(ctx *gcp.Context) (string, error) {
+	l, err := ctx.Layer(delveLayer, gcp.LaunchLayer, gcp.CacheLayer)
+	if err != nil {
+		return "", fmt.Errorf("creating %v layer: %w", delveLayer, err)
+	}
+	dlv := filepath.Join(l.Path, "bin", "dlv")
+	if ctx.GetMetadata(l, delveVersionKey) == delveVersion {
+		ctx.CacheHit(delveLayer)
+		return dlv, nil
+	}
+	ctx.CacheMiss(delveLayer)
+	if err := ctx.ClearLayer(l); err != nil {
+		return "", fmt.Errorf("clearing layer %q: %w", l.Name, err)
+	}
+
+	gopath, err := ctx.TempDir("delve-gopath")
+	if err != nil {
+		return "", err
+	}
+	// Delve is not a dependency of the application: download it from the module proxy, outside of
+	// the application module, workspace and GOPATH layer.
+	installEnv := []string{
+		"GOBIN=" + filepath.Dir(dlv),
+		"GOPATH=" + gopath,
+		"GOMODCACHE=" + filepath.Join(gopath, "pkg", "mod"),
+		"GOPROXY=https://proxy.golang.org,direct",
+		"GOFLAGS=",
+		"GOWORK=off",
+		"CGO_ENABLED=0",
+	}
+	ctx.Logf("Installing Delve v%s", delveVersion)
+	if _, err := ctx.Exec([]string{"go", "install", fmt.Sprintf("%s@v%s", delveModule, delveVersion)}, gcp.WithEnv(installEnv...), gcp.WithWorkDir(gopath), gcp.WithUserAttribution); err != nil {
+		return "", fmt.Errorf("installing Delve: %w", err)
+	}
+	ctx.SetMetadata(l, delveVersionKey, delveVersion)
+	ctx.AddBOMEntry(libcnb.BOMEntry{
+		Name:     delveLayer,
+		Metadata: map[string]interface{}{"version": delveVersion},
+		Launch:   true,
+	})
+	return dlv, nil
+}
+
+// DebugCommand returns the command that runs the binary under a headless Delve server on the
+// given port, which debuggers such as VS Code or GoLand connect to.
+func DebugCommand(dlv, bin string, port int) []string {
+	return []string{dlv, "exec", bin, "--headless", fmt.Sprintf("--listen=:%d", port), "--api-version=2", "--accept-multiclient", "--continue"}
+}
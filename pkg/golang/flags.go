@@ -0,0 +1,100 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+// buildTagRegexp matches a valid build tag, see https://pkg.go.dev/cmd/go#hdr-Build_constraints.
+var buildTagRegexp = regexp.MustCompile(`^[A-Za-z0-9_.]+$`)
+
+// BuildTagFlags returns the `-tags` flag for the build tags specified with GOOGLE_GO_BUILD_TAGS
+// and the extra tags required by the buildpack, or nil if there are none. The same flags must be
+// used by all go commands that compile the application, e.g. go build, go vet and go test.
+func BuildTagFlags(ctx *gcp.Context, extra ...string) ([]string, error) {
+	tags, err := parseBuildTags(os.Getenv(env.GoBuildTags))
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) > 0 {
+		ctx.Logf("Using build tags %s from %s", strings.Join(tags, ","), env.GoBuildTags)
+	}
+	for _, e := range extra {
+		if !contains(tags, e) {
+			tags = append(tags, e)
+		}
+	}
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	return []string{"-tags", strings.Join(tags, ",")}, nil
+}
+
+func contains(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// parseBuildTags parses a comma or space separated list of build tags.
+func parseBuildTags(val string) ([]string, error) {
+	fields := strings.FieldsFunc(val, func(r rune) bool { return r == ',' || r == ' ' })
+	var tags []string
+	for _, f := range fields {
+		if !buildTagRegexp.MatchString(f) {
+			return nil, gcp.UserErrorf("invalid build tag %q in %s: build tags may only contain letters, digits, underscores and dots", f, env.GoBuildTags)
+		}
+		tags = append(tags, f)
+	}
+	return tags, nil
+}
+
+// GoFlags returns the value of GOFLAGS specified with GOOGLE_GOFLAGS, or an empty string if there
+// is none. As with the go command, the flags are space separated and each one must start with a
+// dash.
+func GoFlags(ctx *gcp.Context) (string, error) {
+	flags, err := parseGoFlags(os.Getenv(env.GoFlags))
+	if err != nil {
+		return "", err
+	}
+	if flags != "" {
+		ctx.Logf("Using GOFLAGS %q from %s", flags, env.GoFlags)
+	}
+	return flags, nil
+}
+
+// parseGoFlags validates and normalizes the space separated flags of GOOGLE_GOFLAGS.
+func parseGoFlags(val string) (string, error) {
+	fields := strings.Fields(val)
+	for _, f := range fields {
+		if !strings.HasPrefix(f, "-") {
+			return "", gcp.UserErrorf("invalid flag %q in %s: each flag must start with a dash, values with spaces are not supported", f, env.GoFlags)
+		}
+		if name := strings.TrimLeft(strings.SplitN(f, "=", 2)[0], "-"); name == "tags" {
+			// The -tags flag of the go command line would silently take precedence.
+			return "", gcp.UserErrorf("flag %q is not supported in %s, use %s instead", f, env.GoFlags, env.GoBuildTags)
+		}
+	}
+	return strings.Join(fields, " "), nil
+}
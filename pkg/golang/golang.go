@@ -39,6 +39,12 @@ const (
 	goPathLayerName = "gopath"
 	// The key used when a layers' cache is keyed off of the go mod
 	goModCacheKey = "go-mod-sha"
+	// The name of the layer where the build cache, GOCACHE, is stored
+	goBuildCacheLayerName = "gocache"
+	// The key used when the build cache is keyed off of the dependencies and Go version
+	goBuildCacheKey = "go-build-cache-sha"
+	// goWorkFile is the name of the file that defines a Go workspace.
+	goWorkFile = "go.work"
 )
 
 var (
@@ -47,6 +53,12 @@ var (
 
 	// goModVersionRegexp is used to get correct declaration of Go version from go.mod file.
 	goModVersionRegexp = regexp.MustCompile(`(?m)^\s*go\s+(\d+(\.\d+){1,2})\s*$`)
+
+	// goModToolchainRegexp is used to get the toolchain directive from a go.mod file, e.g. `toolchain go1.21.3`.
+	goModToolchainRegexp = regexp.MustCompile(`(?m)^\s*toolchain\s+go(\d+\.\d+(\.\d+)?((rc|beta)\d+)?)\s*$`)
+
+	// goWorkUseRegexp matches a `use` directive, or the start of a `use` block, in go.work.
+	goWorkUseRegexp = regexp.MustCompile(`^use\s*(\(|\S+)`)
 )
 
 // SupportsAppEngineApis is a Go buildpack specific function that returns true if App Engine API access is enabled
@@ -150,6 +162,21 @@ func GoModVersion(ctx *gcp.Context) (string, error) {
 	return match[1], nil
 }
 
+// GoModToolchain reads the Go toolchain version required by the toolchain directive of a go.mod
+// file, e.g. `1.21.3` for `toolchain go1.21.3`. If there is no go.mod or toolchain directive, it
+// returns an empty string.
+func GoModToolchain(ctx *gcp.Context) (string, error) {
+	v, err := readGoMod(ctx)
+	if err != nil {
+		return "", fmt.Errorf("reading go.mod: %w", err)
+	}
+	match := goModToolchainRegexp.FindStringSubmatch(v)
+	if len(match) < 2 {
+		return "", nil
+	}
+	return match[1], nil
+}
+
 // readGoVersion returns the output of `go version`.
 // It can be overridden for testing.
 var readGoVersion = func(ctx *gcp.Context) (string, error) {
@@ -169,7 +196,9 @@ var cleanModCache = func(ctx *gcp.Context) error {
 	return err
 }
 
-// readGoMod reads the go.mod file if present. If not present, returns an empty string.
+// readGoMod reads the go.mod file if present. In a Go workspace without a go.mod file in the
+// application root, go.work is read instead as it declares the Go version in the same way. If
+// neither is present, returns an empty string.
 // It can be overridden for testing.
 var readGoMod = func(ctx *gcp.Context) (string, error) {
 	goModPath := goModPath(ctx)
@@ -177,6 +206,13 @@ var readGoMod = func(ctx *gcp.Context) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if !goModExists {
+		goModPath = goWorkPath(ctx)
+		goModExists, err = ctx.FileExists(goModPath)
+		if err != nil {
+			return "", err
+		}
+	}
 	if !goModExists {
 		return "", nil
 	}
@@ -187,20 +223,30 @@ var readGoMod = func(ctx *gcp.Context) (string, error) {
 	return string(bytes), nil
 }
 
-// NewGoWorkspaceLayer returns a new layer for `go env GOPATH` or the go workspace. The
-// layer is configured for caching if possible. It only supports caching for "go mod"
-// based builds.
+// NewGoWorkspaceLayer returns a new layer for `go env GOPATH` or the go workspace, which also
+// holds the module cache. The layer is configured for caching if possible, keyed off of the
+// module files and the Go version. It only supports caching for "go mod" based builds.
 func NewGoWorkspaceLayer(ctx *gcp.Context) (*libcnb.Layer, error) {
 	l, err := ctx.Layer(goPathLayerName, gcp.BuildLayer, gcp.CacheLayer, gcp.LaunchLayerIfDevMode)
 	if err != nil {
 		return nil, fmt.Errorf("creating %v layer: %w", goPathLayerName, err)
 	}
 	l.BuildEnvironment.Override("GOPATH", l.Path)
+	l.BuildEnvironment.Override("GOMODCACHE", filepath.Join(l.Path, "pkg", "mod"))
 	l.BuildEnvironment.Override("GO111MODULE", "on")
 	// Set GOPROXY to ensure no additional dependency is downloaded at built time.
 	// All of them are downloaded here.
 	l.BuildEnvironment.Override("GOPROXY", "off")
 
+	workspace, err := WorkspaceExists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if workspace {
+		// Build all workspace modules together, even when building from a subdirectory.
+		l.BuildEnvironment.Override("GOWORK", goWorkPath(ctx))
+	}
+
 	shouldEnablePkgCache, err := SupportsGoCleanModCache(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("checking for go pkg cache support: %w", err)
@@ -210,7 +256,11 @@ func NewGoWorkspaceLayer(ctx *gcp.Context) (*libcnb.Layer, error) {
 		return l, nil
 	}
 
-	sha, err := cache.Hash(ctx, cache.WithFiles(goModPath(ctx)))
+	files, err := moduleFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sha, err := dependencyHash(ctx, files)
 	if err != nil {
 		if os.IsNotExist(err) {
 			// when go.mod doesn't exist, clear any previously cached bits and return an empty layer
@@ -220,22 +270,173 @@ func NewGoWorkspaceLayer(ctx *gcp.Context) (*libcnb.Layer, error) {
 		}
 		return nil, err
 	}
-	shaStr := fmt.Sprintf("%x", sha)
-	if shaStr == ctx.GetMetadata(l, goModCacheKey) {
+	if sha == ctx.GetMetadata(l, goModCacheKey) {
 		ctx.Logf("GOPATH layer cache hit")
 		ctx.CacheHit(goPathLayerName)
 		return l, nil
 	}
 	ctx.Debugf("go.mod SHA has changed: clearing GOPATH layer's cache")
+	ctx.CacheMiss(goPathLayerName)
 	cleanModCache(ctx)
-	ctx.SetMetadata(l, goModCacheKey, shaStr)
+	ctx.SetMetadata(l, goModCacheKey, sha)
+	return l, nil
+}
+
+// NewGoBuildCacheLayer returns a new layer for GOCACHE. The build cache is kept between builds
+// with the same module files, extra files, e.g. profile-guided optimization profiles, and Go
+// version, so that unchanged packages, including all of the dependencies, are not compiled again.
+func NewGoBuildCacheLayer(ctx *gcp.Context, extraFiles ...string) (*libcnb.Layer, error) {
+	l, err := ctx.Layer(goBuildCacheLayerName, gcp.BuildLayer, gcp.CacheLayer, gcp.LaunchLayerIfDevMode)
+	if err != nil {
+		return nil, fmt.Errorf("creating %v layer: %w", goBuildCacheLayerName, err)
+	}
+	l.BuildEnvironment.Override("GOCACHE", l.Path)
+
+	candidates, err := moduleFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// GOPATH based builds have no module files, the cache is then only keyed off of the Go version.
+	var files []string
+	for _, f := range candidates {
+		exists, err := ctx.FileExists(f)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			files = append(files, f)
+		}
+	}
+	sha, err := dependencyHash(ctx, append(files, extraFiles...))
+	if err != nil {
+		return nil, err
+	}
+	if sha == ctx.GetMetadata(l, goBuildCacheKey) {
+		ctx.Logf("Go build cache hit")
+		ctx.CacheHit(goBuildCacheLayerName)
+		return l, nil
+	}
+	ctx.Debugf("Module files or Go version have changed: clearing Go build cache")
+	ctx.CacheMiss(goBuildCacheLayerName)
+	if err := ctx.ClearLayer(l); err != nil {
+		return nil, fmt.Errorf("clearing layer %q: %w", l.Name, err)
+	}
+	ctx.SetMetadata(l, goBuildCacheKey, sha)
 	return l, nil
 }
 
+// dependencyHash returns a hash of the given module files and of the installed Go version, as
+// cached modules and compiled packages are only valid for the Go version that produced them.
+func dependencyHash(ctx *gcp.Context, files []string) (string, error) {
+	goVersion, err := GoVersion(ctx)
+	if err != nil {
+		return "", err
+	}
+	return cache.Hash(ctx, cache.WithFiles(files...), cache.WithStrings(goVersion))
+}
+
 func goModPath(ctx *gcp.Context) string {
 	return filepath.Join(ctx.ApplicationRoot(), "go.mod")
 }
 
+func goWorkPath(ctx *gcp.Context) string {
+	return filepath.Join(ctx.ApplicationRoot(), goWorkFile)
+}
+
+// moduleFiles returns the files that determine the dependencies of the application: go.mod and
+// go.sum, or in a Go workspace go.work, go.work.sum and the go.mod and go.sum files of all
+// workspace modules. go.mod is always returned outside of a workspace, even if it doesn't exist.
+func moduleFiles(ctx *gcp.Context) ([]string, error) {
+	workspace, err := WorkspaceExists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !workspace {
+		files := []string{goModPath(ctx)}
+		goSum := filepath.Join(ctx.ApplicationRoot(), "go.sum")
+		goSumExists, err := ctx.FileExists(goSum)
+		if err != nil {
+			return nil, err
+		}
+		if goSumExists {
+			files = append(files, goSum)
+		}
+		return files, nil
+	}
+	modules, err := WorkspaceModules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	candidates := []string{goWorkPath(ctx), goWorkPath(ctx) + ".sum"}
+	for _, m := range modules {
+		dir := filepath.Join(ctx.ApplicationRoot(), m)
+		candidates = append(candidates, filepath.Join(dir, "go.mod"), filepath.Join(dir, "go.sum"))
+	}
+	var files []string
+	for _, f := range candidates {
+		exists, err := ctx.FileExists(f)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// WorkspaceExists returns true if the application is a Go workspace, i.e. it has a go.work file.
+func WorkspaceExists(ctx *gcp.Context) (bool, error) {
+	return ctx.FileExists(goWorkPath(ctx))
+}
+
+// WorkspaceModules returns the directories of the modules used by the Go workspace, relative to
+// the application root.
+func WorkspaceModules(ctx *gcp.Context) ([]string, error) {
+	content, err := ctx.ReadFile(goWorkPath(ctx))
+	if err != nil {
+		return nil, err
+	}
+	modules := parseGoWorkUses(string(content))
+	if len(modules) == 0 {
+		return nil, gcp.UserErrorf("%s does not use any modules", goWorkFile)
+	}
+	return modules, nil
+}
+
+// parseGoWorkUses returns the module directories of the `use` directives in a go.work file.
+func parseGoWorkUses(content string) []string {
+	var modules []string
+	inBlock := false
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if inBlock {
+			if line == ")" {
+				inBlock = false
+				continue
+			}
+			modules = append(modules, filepath.Clean(strings.Trim(line, "\"`")))
+			continue
+		}
+		m := goWorkUseRegexp.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if m[1] == "(" {
+			inBlock = true
+			continue
+		}
+		modules = append(modules, filepath.Clean(strings.Trim(m[1], "\"`")))
+	}
+	return modules
+}
+
 // ExecWithGoproxyFallback runs the given command with a GOPROXY fallback.
 // Before Go 1.14, Go would fall back to direct only if a 404 or 410 error ocurred, for those
 // versions, we explictly disable GOPROXY and try again on any error.
@@ -0,0 +1,105 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"fmt"
+	"strings"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/sbom"
+	"github.com/buildpacks/libcnb"
+)
+
+// moduleSumProperty is the name of the SBOM property holding the go.sum checksum of a module.
+const moduleSumProperty = "golang:sum"
+
+// WriteSBOM attaches a CycloneDX SBOM listing the Go modules and standard library compiled into
+// the binaries to layer l. The modules are read from the build information embedded in each
+// binary with `go version -m`, so the SBOM lists exactly the code that runs, without source access.
+func WriteSBOM(ctx *gcp.Context, l *libcnb.Layer, bins []string) error {
+	seen := map[string]bool{}
+	var components []sbom.Component
+	for _, bin := range bins {
+		result, err := ctx.Exec([]string{"go", "version", "-m", bin})
+		if err != nil {
+			return fmt.Errorf("reading build information of %s: %w", bin, err)
+		}
+		for _, c := range binaryComponents(result.Stdout) {
+			if !seen[c.PURL] {
+				seen[c.PURL] = true
+				components = append(components, c)
+			}
+		}
+	}
+	return sbom.WriteLayerSBOM(ctx, l, components)
+}
+
+// binaryComponents parses the output of `go version -m` into SBOM components: the Go standard
+// library, the main module and the dependencies, after replacements.
+func binaryComponents(output string) []sbom.Component {
+	var components []sbom.Component
+	lines := strings.Split(output, "\n")
+	if len(lines) > 0 {
+		// The first line is `<path>: <go version>`.
+		if i := strings.LastIndex(lines[0], ": go"); i >= 0 {
+			components = append(components, goComponent("stdlib", "v"+strings.TrimSpace(lines[0][i+len(": go"):]), ""))
+		}
+	}
+	for _, line := range lines[1:] {
+		fields := strings.Split(strings.TrimPrefix(line, "\t"), "\t")
+		switch fields[0] {
+		case "mod", "dep":
+			if len(fields) < 3 {
+				continue
+			}
+			sum := ""
+			if len(fields) > 3 {
+				sum = fields[3]
+			}
+			components = append(components, goComponent(fields[1], fields[2], sum))
+		case "=>":
+			// The replacement of the previous module is the code that is compiled in.
+			if len(fields) < 3 || len(components) == 0 {
+				continue
+			}
+			sum := ""
+			if len(fields) > 3 {
+				sum = fields[3]
+			}
+			components[len(components)-1] = goComponent(fields[1], fields[2], sum)
+		}
+	}
+	return components
+}
+
+// goComponent returns an SBOM component with a package URL for a Go module.
+func goComponent(path, version, sum string) sbom.Component {
+	c := sbom.Component{
+		Type:    "library",
+		Name:    path,
+		Version: version,
+		PURL:    fmt.Sprintf("pkg:golang/%s@%s", path, version),
+	}
+	if version == "" || version == "(devel)" {
+		// The main module, and local replacements, have no version.
+		c.Version = ""
+		c.PURL = "pkg:golang/" + path
+	}
+	if sum != "" {
+		c.Properties = []sbom.Property{{Name: moduleSumProperty, Value: sum}}
+	}
+	return c
+}
@@ -0,0 +1,110 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"path/filepath"
+	"strings"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/Masterminds/semver"
+)
+
+// maxReportedPackages is the maximum number of missing vendored packages listed in errors.
+const maxReportedPackages = 5
+
+// VerifyVendor checks that the vendor directory in dir is consistent with go.mod and contains all
+// of the packages listed in vendor/modules.txt. Otherwise, the build would fail later on with
+// errors about missing packages that do not point to the vendor directory.
+func VerifyVendor(ctx *gcp.Context, dir string, env []string) error {
+	goModVersion, err := GoModVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if !usesVendorByDefault(goModVersion) {
+		return nil
+	}
+
+	// The go command checks the consistency of vendor/modules.txt with go.mod when loading the main
+	// module in vendor mode, without loading any package.
+	result, err := ctx.Exec([]string{"go", "list", "-mod=vendor", "-m"}, gcp.WithEnv(env...), gcp.WithWorkDir(dir), gcp.WithUserAttribution)
+	if err != nil {
+		stderr := ""
+		if result != nil {
+			stderr = strings.TrimSpace(result.Stderr)
+		}
+		return gcp.UserErrorf("the vendor directory is not consistent with go.mod, run `go mod vendor` and include the updated vendor directory, or delete it:\n%s", stderr)
+	}
+
+	modulesTxt := filepath.Join(dir, "vendor", "modules.txt")
+	exists, err := ctx.FileExists(modulesTxt)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		// The go command only accepts a vendor directory without modules.txt if there are no
+		// dependencies, which leaves nothing to verify.
+		return nil
+	}
+	content, err := ctx.ReadFile(modulesTxt)
+	if err != nil {
+		return err
+	}
+	var missing []string
+	for _, pkg := range vendoredPackages(string(content)) {
+		exists, err := ctx.FileExists(dir, "vendor", filepath.FromSlash(pkg))
+		if err != nil {
+			return err
+		}
+		if !exists {
+			missing = append(missing, pkg)
+		}
+	}
+	if len(missing) > 0 {
+		reported := missing
+		if len(reported) > maxReportedPackages {
+			reported = append(reported[:maxReportedPackages:maxReportedPackages], "...")
+		}
+		return gcp.UserErrorf("%d packages listed in vendor/modules.txt are missing from the vendor directory, check that it is not excluded from the upload (e.g. by .gcloudignore) or run `go mod vendor`: %s", len(missing), strings.Join(reported, ", "))
+	}
+	return nil
+}
+
+// usesVendorByDefault returns true if the go command uses the vendor directory without -mod=vendor
+// for a go.mod file with the given go version, i.e. for go 1.14 and later.
+func usesVendorByDefault(goModVersion string) bool {
+	if goModVersion == "" {
+		return false
+	}
+	v, err := semver.NewVersion(goModVersion)
+	if err != nil {
+		return false
+	}
+	return !v.LessThan(semver.MustParse("1.14.0"))
+}
+
+// vendoredPackages returns the packages listed in a vendor/modules.txt file. Lines starting with
+// `#` describe modules and are skipped.
+func vendoredPackages(modulesTxt string) []string {
+	var pkgs []string
+	for _, line := range strings.Split(modulesTxt, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pkgs = append(pkgs, line)
+	}
+	return pkgs
+}
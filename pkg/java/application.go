@@ -0,0 +1,206 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"archive/zip"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+// kotlinFileClassSuffix is the suffix of the class that the Kotlin compiler generates for the
+// top-level functions of a file, e.g. AppKt for the main function in App.kt.
+const kotlinFileClassSuffix = "Kt"
+
+var (
+	// mainClassProperties are the pom.xml properties that conventionally declare the main class,
+	// e.g. main.class in projects generated by the Kotlin Maven archetype.
+	mainClassProperties = []string{"main.class", "mainClass", "exec.mainClass", "start-class"}
+	// gradleMainClassRegexps match the main class of the Gradle application plugin in Groovy and
+	// Kotlin build scripts, e.g. `mainClass = 'com.example.App'`,
+	// `mainClass.set("com.example.AppKt")` or `mainClassName = "com.example.App"`.
+	gradleMainClassRegexps = []*regexp.Regexp{
+		regexp.MustCompile(`\bmainClass\s*(?:\.set\s*\(|=)\s*["']([\w.$]+)["']`),
+		regexp.MustCompile(`\bmainClassName\s*=\s*["']([\w.$]+)["']`),
+	}
+	// gradleApplicationPluginRegexp matches the application of the Gradle application plugin, e.g.
+	// `id 'application'`, `apply plugin: 'application'` or `application` in a plugins block.
+	gradleApplicationPluginRegexp = regexp.MustCompile(`(?m)(\bid\s*\(?\s*["']application["']|\bapply\s*\(?\s*plugin\s*[:=]\s*["']application["']|^\s*application\s*$)`)
+)
+
+// GradleApplicationPluginApplied returns true if the build script of the Gradle project, or of
+// the selected module, applies the application plugin.
+func GradleApplicationPluginApplied(ctx *gcp.Context) (bool, error) {
+	content, err := gradleBuildScript(ctx)
+	if err != nil {
+		return false, err
+	}
+	return gradleApplicationPluginRegexp.Match(content), nil
+}
+
+// DeclaredMainClass returns the main class declared in the build configuration of the project,
+// or of the selected module: the main class properties and plugin configuration of the pom.xml,
+// or the main class of the Gradle application plugin. It returns an empty string if there is none.
+func DeclaredMainClass(ctx *gcp.Context) (string, error) {
+	dirs, err := projectDirs(ctx)
+	if err != nil {
+		return "", err
+	}
+	pomPath := filepath.Join(dirs[0], "pom.xml")
+	exists, err := ctx.FileExists(pomPath)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		content, err := ctx.ReadFile(pomPath)
+		if err != nil {
+			return "", err
+		}
+		project, err := ParsePomFile(content)
+		if err != nil {
+			return "", err
+		}
+		return pomMainClass(project), nil
+	}
+
+	content, err := gradleBuildScript(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, re := range gradleMainClassRegexps {
+		if m := re.FindSubmatch(content); m != nil {
+			return string(m[1]), nil
+		}
+	}
+	return "", nil
+}
+
+// pomMainClass returns the main class declared in the properties or the plugin configuration of
+// the project.
+func pomMainClass(project *MavenProject) string {
+	for _, name := range mainClassProperties {
+		for _, p := range project.Properties.Entries {
+			if p.XMLName.Local == name && strings.TrimSpace(p.Value) != "" {
+				return strings.TrimSpace(p.Value)
+			}
+		}
+	}
+	for _, p := range project.Plugins {
+		// Values such as ${main.class} reference undefined properties.
+		if main := strings.TrimSpace(p.Configuration.MainClass); main != "" && !strings.Contains(main, "${") {
+			return main
+		}
+	}
+	return ""
+}
+
+// gradleBuildScript returns the contents of the build script of the Gradle project, or of the
+// selected module, or nil if there is none.
+func gradleBuildScript(ctx *gcp.Context) ([]byte, error) {
+	dirs, err := projectDirs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range gradleBuildScripts {
+		path := filepath.Join(dirs[0], name)
+		exists, err := ctx.FileExists(path)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return ctx.ReadFile(path)
+		}
+	}
+	return nil, nil
+}
+
+// ResolveMainClass returns the main class in the jar for the declared main class. Following the
+// Kotlin convention, the main function in App.kt may be declared as com.example.App while it is
+// compiled to com.example.AppKt. It returns an empty string if the jar does not contain the class.
+func ResolveMainClass(jar, declared string) (string, error) {
+	r, err := zip.OpenReader(jar)
+	if err != nil {
+		return "", gcp.UserErrorf("unzipping jar %s: %v", jar, err)
+	}
+	defer r.Close()
+	entries := map[string]bool{}
+	for _, f := range r.File {
+		entries[f.Name] = true
+	}
+	for _, candidate := range []string{declared, declared + kotlinFileClassSuffix} {
+		if entries[strings.ReplaceAll(candidate, ".", "/")+".class"] {
+			return candidate, nil
+		}
+	}
+	return "", nil
+}
+
+// ApplicationStartScript returns the start script of the distribution installed by the installDist
+// task of the Gradle application plugin, or an empty string if there is none.
+func ApplicationStartScript(ctx *gcp.Context) (string, error) {
+	dirs, err := projectDirs(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, dir := range dirs {
+		matches, err := ctx.Glob(filepath.Join(dir, "build", "install", "*", "bin", "*"))
+		if err != nil {
+			return "", fmt.Errorf("finding start scripts: %w", err)
+		}
+		var scripts []string
+		for _, m := range matches {
+			// Windows start scripts are installed next to the Unix ones.
+			if !strings.HasSuffix(m, ".bat") {
+				scripts = append(scripts, m)
+			}
+		}
+		if len(scripts) == 1 {
+			return scripts[0], nil
+		} else if len(scripts) > 1 {
+			return "", gcp.UserErrorf("found more than one start script in %s: %v, please specify an entrypoint", dir, scripts)
+		}
+	}
+	return "", nil
+}
+
+// ApplicationJar returns the jar built by the application when it does not have a Main-Class
+// manifest entry, i.e. the only primary jar in the first path that contains jars, or an empty
+// string if there is none.
+func ApplicationJar(ctx *gcp.Context) (string, error) {
+	paths, err := artifactPaths()
+	if err != nil {
+		return "", err
+	}
+	for _, path := range paths {
+		path = append([]string{ctx.ApplicationRoot()}, path...)
+		path = append(path, "*.jar")
+		jars, err := ctx.Glob(filepath.Join(path...))
+		if err != nil {
+			return "", fmt.Errorf("finding jars: %w", err)
+		}
+		if len(jars) == 0 {
+			continue
+		}
+		if jars = primaryJars(jars); len(jars) == 1 {
+			return jars[0], nil
+		}
+		return "", nil
+	}
+	return "", nil
+}
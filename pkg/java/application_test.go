@@ -0,0 +1,201 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+func TestGradleApplicationPluginApplied(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		want  bool
+	}{
+		{
+			name:  "groovy plugins block",
+			files: map[string]string{"build.gradle": "plugins {\n  id 'java'\n  id 'application'\n}"},
+			want:  true,
+		},
+		{
+			name:  "kotlin plugins block",
+			files: map[string]string{"build.gradle.kts": "plugins {\n    kotlin(\"jvm\") version \"1.9.20\"\n    application\n}\n\napplication {\n    mainClass.set(\"com.example.AppKt\")\n}"},
+			want:  true,
+		},
+		{
+			name:  "apply plugin",
+			files: map[string]string{"build.gradle": "apply plugin: 'application'"},
+			want:  true,
+		},
+		{
+			name:  "no application plugin",
+			files: map[string]string{"build.gradle.kts": "plugins {\n    kotlin(\"jvm\") version \"1.9.20\"\n}"},
+		},
+		{
+			name:  "maven",
+			files: map[string]string{"pom.xml": "<project></project>"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("GOOGLE_JAVA_MODULE", "")
+			root := t.TempDir()
+			writeTestFiles(t, root, tc.files)
+
+			got, err := GradleApplicationPluginApplied(gcp.NewContext(gcp.WithApplicationRoot(root)))
+			if err != nil {
+				t.Fatalf("GradleApplicationPluginApplied() got error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("GradleApplicationPluginApplied() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDeclaredMainClass(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name:  "kotlin archetype property",
+			files: map[string]string{"pom.xml": "<project><properties><kotlin.version>1.9.20</kotlin.version><main.class>com.example.HelloKt</main.class></properties></project>"},
+			want:  "com.example.HelloKt",
+		},
+		{
+			name: "plugin configuration",
+			files: map[string]string{"pom.xml": `<project><build><plugins><plugin>
+<groupId>org.codehaus.mojo</groupId><artifactId>exec-maven-plugin</artifactId>
+<configuration><mainClass>com.example.App</mainClass></configuration>
+</plugin></plugins></build></project>`},
+			want: "com.example.App",
+		},
+		{
+			name: "plugin configuration with undefined property",
+			files: map[string]string{"pom.xml": `<project><build><plugins><plugin>
+<configuration><mainClass>${app.main}</mainClass></configuration>
+</plugin></plugins></build></project>`},
+		},
+		{
+			name:  "kotlin dsl set",
+			files: map[string]string{"build.gradle.kts": "application {\n    mainClass.set(\"com.example.AppKt\")\n}"},
+			want:  "com.example.AppKt",
+		},
+		{
+			name:  "kotlin dsl assignment",
+			files: map[string]string{"build.gradle.kts": "application {\n    mainClass = \"com.example.App\"\n}"},
+			want:  "com.example.App",
+		},
+		{
+			name:  "groovy main class name",
+			files: map[string]string{"build.gradle": "mainClassName = 'com.example.Main'"},
+			want:  "com.example.Main",
+		},
+		{
+			name:  "none",
+			files: map[string]string{"build.gradle": "plugins { id 'java' }"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("GOOGLE_JAVA_MODULE", "")
+			root := t.TempDir()
+			writeTestFiles(t, root, tc.files)
+
+			got, err := DeclaredMainClass(gcp.NewContext(gcp.WithApplicationRoot(root)))
+			if err != nil {
+				t.Fatalf("DeclaredMainClass() got error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("DeclaredMainClass() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestResolveMainClass(t *testing.T) {
+	jar := filepath.Join(t.TempDir(), "app.jar")
+	content := zipContent(t, map[string][]byte{
+		"com/example/AppKt.class": nil,
+		"com/example/Main.class":  nil,
+	})
+	if err := os.WriteFile(jar, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		declared string
+		want     string
+	}{
+		{declared: "com.example.AppKt", want: "com.example.AppKt"},
+		{declared: "com.example.App", want: "com.example.AppKt"},
+		{declared: "com.example.Main", want: "com.example.Main"},
+		{declared: "com.example.Other"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.declared, func(t *testing.T) {
+			got, err := ResolveMainClass(jar, tc.declared)
+			if err != nil {
+				t.Fatalf("ResolveMainClass() got error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("ResolveMainClass(%q) = %q, want %q", tc.declared, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestApplicationStartScript(t *testing.T) {
+	testCases := []struct {
+		name    string
+		files   []string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "install dist",
+			files: []string{"build/install/app/bin/app", "build/install/app/bin/app.bat", "build/install/app/lib/app.jar"},
+			want:  "build/install/app/bin/app",
+		},
+		{
+			name:  "no distribution",
+			files: []string{"build/libs/app.jar"},
+		},
+		{
+			name:    "multiple distributions",
+			files:   []string{"build/install/a/bin/a", "build/install/b/bin/b"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("GOOGLE_JAVA_MODULE", "")
+			root := setupFiles(t, tc.files)
+
+			got, err := ApplicationStartScript(gcp.NewContext(gcp.WithApplicationRoot(root)))
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("ApplicationStartScript() got error %v, want error %t", err, tc.wantErr)
+			}
+			if want := joinRoot(root, tc.want); got != want {
+				t.Errorf("ApplicationStartScript() = %q, want %q", got, want)
+			}
+		})
+	}
+}
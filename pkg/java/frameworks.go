@@ -0,0 +1,102 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"path/filepath"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+const (
+	// QuarkusRunJar is the jar that runs a Quarkus fast-jar application.
+	QuarkusRunJar = "quarkus-run.jar"
+	// quarkusAppDir is the directory of a Quarkus fast-jar application in the build output.
+	quarkusAppDir = "quarkus-app"
+	// micronautApplicationJar is the application jar of the Micronaut layers layout.
+	micronautApplicationJar = "application.jar"
+)
+
+var (
+	// QuarkusLibDirs are the directories of the dependencies of a Quarkus fast-jar application.
+	QuarkusLibDirs = []string{filepath.Join("lib", "boot"), filepath.Join("lib", "main")}
+	// MicronautLibDirs are the directories of the dependencies in the Micronaut layers layout, from
+	// the least to the most frequently changing.
+	MicronautLibDirs = []string{"libs", "snapshot_libs", "project_libs"}
+	// micronautLayersPaths are the paths of the layers layout created by the Micronaut Gradle
+	// plugin, relative to the project directory.
+	micronautLayersPaths = [][]string{
+		[]string{"build", "docker", "main", "layers"},
+		[]string{"build", "layers"},
+	}
+)
+
+// QuarkusApp returns the directory of the Quarkus fast-jar application, e.g. target/quarkus-app,
+// or an empty string if the application is not a Quarkus fast-jar application.
+func QuarkusApp(ctx *gcp.Context) (string, error) {
+	paths, err := artifactPaths()
+	if err != nil {
+		return "", err
+	}
+	for _, path := range paths {
+		dir := filepath.Join(append(append([]string{ctx.ApplicationRoot()}, path...), quarkusAppDir)...)
+		exists, err := ctx.FileExists(dir, QuarkusRunJar)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return dir, nil
+		}
+	}
+	return "", nil
+}
+
+// MicronautLayers returns the directory of the layers layout of a Micronaut application built by
+// the buildLayers Gradle task, or an empty string if there is no such directory.
+func MicronautLayers(ctx *gcp.Context) (string, error) {
+	dirs, err := projectDirs(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, project := range dirs {
+		for _, lp := range micronautLayersPaths {
+			dir := filepath.Join(append([]string{project}, lp...)...)
+			jar, err := MicronautApplicationJar(ctx, dir)
+			if err != nil {
+				return "", err
+			}
+			if jar != "" {
+				return dir, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// MicronautApplicationJar returns the application jar in the Micronaut layers directory, which is
+// in the app directory for Micronaut 4 and at the root of the layers for earlier versions, or an
+// empty string if there is none.
+func MicronautApplicationJar(ctx *gcp.Context, layers string) (string, error) {
+	for _, jar := range []string{filepath.Join(layers, "app", micronautApplicationJar), filepath.Join(layers, micronautApplicationJar)} {
+		exists, err := ctx.FileExists(jar)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return jar, nil
+		}
+	}
+	return "", nil
+}
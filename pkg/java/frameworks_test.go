@@ -0,0 +1,136 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"path/filepath"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+func TestQuarkusApp(t *testing.T) {
+	testCases := []struct {
+		name   string
+		files  []string
+		module string
+		want   string
+	}{
+		{
+			name:  "maven",
+			files: []string{"target/quarkus-app/quarkus-run.jar", "target/quarkus-app/lib/main/a.jar", "target/app-1.0.jar"},
+			want:  "target/quarkus-app",
+		},
+		{
+			name:  "gradle",
+			files: []string{"build/quarkus-app/quarkus-run.jar"},
+			want:  "build/quarkus-app",
+		},
+		{
+			name:   "module",
+			files:  []string{"api/target/quarkus-app/quarkus-run.jar"},
+			module: "api",
+			want:   "api/target/quarkus-app",
+		},
+		{
+			name:  "uber jar",
+			files: []string{"target/app-1.0-runner.jar"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("GOOGLE_JAVA_MODULE", tc.module)
+			root := setupFiles(t, tc.files)
+
+			got, err := QuarkusApp(gcp.NewContext(gcp.WithApplicationRoot(root)))
+			if err != nil {
+				t.Fatalf("QuarkusApp() got error: %v", err)
+			}
+			if want := joinRoot(root, tc.want); got != want {
+				t.Errorf("QuarkusApp() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestMicronautLayers(t *testing.T) {
+	testCases := []struct {
+		name    string
+		files   []string
+		want    string
+		wantJar string
+	}{
+		{
+			name:    "micronaut 4",
+			files:   []string{"build/docker/main/layers/app/application.jar", "build/docker/main/layers/libs/a.jar"},
+			want:    "build/docker/main/layers",
+			wantJar: "build/docker/main/layers/app/application.jar",
+		},
+		{
+			name:    "micronaut 3",
+			files:   []string{"build/layers/application.jar", "build/layers/libs/a.jar"},
+			want:    "build/layers",
+			wantJar: "build/layers/application.jar",
+		},
+		{
+			name:  "shaded jar",
+			files: []string{"build/libs/app-0.1-all.jar"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("GOOGLE_JAVA_MODULE", "")
+			root := setupFiles(t, tc.files)
+			ctx := gcp.NewContext(gcp.WithApplicationRoot(root))
+
+			got, err := MicronautLayers(ctx)
+			if err != nil {
+				t.Fatalf("MicronautLayers() got error: %v", err)
+			}
+			if want := joinRoot(root, tc.want); got != want {
+				t.Errorf("MicronautLayers() = %q, want %q", got, want)
+			}
+			if got == "" {
+				return
+			}
+			jar, err := MicronautApplicationJar(ctx, got)
+			if err != nil {
+				t.Fatalf("MicronautApplicationJar() got error: %v", err)
+			}
+			if want := joinRoot(root, tc.wantJar); jar != want {
+				t.Errorf("MicronautApplicationJar() = %q, want %q", jar, want)
+			}
+		})
+	}
+}
+
+func setupFiles(t *testing.T, files []string) string {
+	t.Helper()
+	root := t.TempDir()
+	contents := map[string]string{}
+	for _, f := range files {
+		contents[f] = ""
+	}
+	writeTestFiles(t, root, contents)
+	return root
+}
+
+// joinRoot returns the path relative to root, or an empty string for an empty path.
+func joinRoot(root, path string) string {
+	if path == "" {
+		return ""
+	}
+	return filepath.Join(root, path)
+}
@@ -44,9 +44,16 @@ const (
 
 	// FFJarPathEnv is an environment variable which is used to store the path to the functions framework invoker jar.
 	FFJarPathEnv = "GOOGLE_INTERNAL_FUNCTIONS_FRAMEWORK_JAR"
+
+	// shadedJarSuffix is the suffix of the jar with all dependencies built by the Gradle shadow
+	// plugin, e.g. for Micronaut applications.
+	shadedJarSuffix = "-all.jar"
 )
 
 var (
+	// secondaryJarSuffixes are the suffixes of jars built next to the application jar.
+	secondaryJarSuffixes = []string{"-plain.jar", "-sources.jar", "-javadoc.jar", "-tests.jar", "-plain.war"}
+
 	// jarPaths contains the paths that we search for executable jar files. Order of paths decides precedence.
 	jarPaths = [][]string{
 		[]string{"target"},
@@ -59,11 +66,11 @@ var (
 
 // ExecutableJar looks for the jar with a Main-Class manifest. If there is not exactly 1 of these jars, throw an error.
 func ExecutableJar(ctx *gcp.Context) (string, error) {
-	var buildable = os.Getenv(env.Buildable)
-	if buildable != "" {
-		jarPaths = append([][]string{[]string{buildable, "target"}}, jarPaths...)
+	paths, err := artifactPaths()
+	if err != nil {
+		return "", err
 	}
-	for i, path := range jarPaths {
+	for i, path := range paths {
 		path = append([]string{ctx.ApplicationRoot()}, path...)
 		path = append(path, "*.jar")
 		jars, err := ctx.Glob(filepath.Join(path...))
@@ -72,17 +79,83 @@ func ExecutableJar(ctx *gcp.Context) (string, error) {
 		}
 		// There may be multiple jars due to some frameworks like Quarkus creating multiple jars,
 		// so we look for the jar that contains a Main-Class entry in its manifest.
-		executables := filterExecutables(ctx, jars)
+		executables := primaryJars(filterExecutables(ctx, jars))
 		// We've found a path with exactly 1 jar, so return that jar.
 		if len(executables) == 1 {
 			return executables[0], nil
 		} else if len(executables) > 1 {
-			return "", gcp.UserErrorf("found more than one jar with a Main-Class manifest entry in %s: %v, please specify an entrypoint", jarPaths[i], executables)
+			return "", gcp.UserErrorf("found more than one jar with a Main-Class manifest entry in %s: %v, please specify an entrypoint", paths[i], executables)
 		}
 	}
 	return "", gcp.UserErrorf("did not find any jar files with a Main-Class manifest entry")
 }
 
+// artifactPaths returns the paths that are searched for the built application, in order of
+// precedence, including the paths of the buildable directory and the selected module.
+func artifactPaths() ([][]string, error) {
+	var buildable = os.Getenv(env.Buildable)
+	module, err := Module()
+	if err != nil {
+		return nil, err
+	}
+	paths := jarPaths
+	if buildable != "" {
+		paths = append([][]string{[]string{buildable, "target"}, []string{buildable, "build", "libs"}}, paths...)
+	}
+	if module != "" {
+		paths = append([][]string{[]string{buildable, module, "target"}, []string{buildable, module, "build", "libs"}}, paths...)
+	}
+	return paths, nil
+}
+
+// projectDirs returns the directories of the projects that are searched for build outputs, in
+// order of precedence: the selected module, the buildable directory and the application root.
+func projectDirs(ctx *gcp.Context) ([]string, error) {
+	buildable := os.Getenv(env.Buildable)
+	module, err := Module()
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	seen := map[string]bool{}
+	for _, dir := range []string{filepath.Join(ctx.ApplicationRoot(), buildable, module), filepath.Join(ctx.ApplicationRoot(), buildable), ctx.ApplicationRoot()} {
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs, nil
+}
+
+// primaryJars drops secondary jars, e.g. the plain jar produced next to a Spring Boot jar by
+// Gradle or the thin jar produced next to the shaded -all jar of a Micronaut application, when
+// there are other jars.
+func primaryJars(jars []string) []string {
+	shaded := map[string]bool{}
+	for _, jar := range jars {
+		if strings.HasSuffix(jar, shadedJarSuffix) {
+			shaded[strings.TrimSuffix(jar, shadedJarSuffix)+".jar"] = true
+		}
+	}
+	var primary []string
+	for _, jar := range jars {
+		secondary := shaded[jar]
+		for _, suffix := range secondaryJarSuffixes {
+			if strings.HasSuffix(jar, suffix) {
+				secondary = true
+				break
+			}
+		}
+		if !secondary {
+			primary = append(primary, jar)
+		}
+	}
+	if len(primary) == 0 {
+		return jars
+	}
+	return primary
+}
+
 func filterExecutables(ctx *gcp.Context, jars []string) []string {
 	var executables []string
 	for _, jar := range jars {
@@ -20,6 +20,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
@@ -305,3 +306,39 @@ func setupTestJar(t *testing.T, mfContent []byte) string {
 	}
 	return jarPath
 }
+
+func TestPrimaryJars(t *testing.T) {
+	testCases := []struct {
+		name string
+		jars []string
+		want []string
+	}{
+		{
+			name: "single jar",
+			jars: []string{"build/libs/app.jar"},
+			want: []string{"build/libs/app.jar"},
+		},
+		{
+			name: "boot and plain jars",
+			jars: []string{"build/libs/app-0.0.1.jar", "build/libs/app-0.0.1-plain.jar", "build/libs/app-0.0.1-sources.jar"},
+			want: []string{"build/libs/app-0.0.1.jar"},
+		},
+		{
+			name: "shaded and thin jars",
+			jars: []string{"build/libs/app-0.1-all.jar", "build/libs/app-0.1.jar"},
+			want: []string{"build/libs/app-0.1-all.jar"},
+		},
+		{
+			name: "only secondary jars",
+			jars: []string{"build/libs/app-plain.jar"},
+			want: []string{"build/libs/app-plain.jar"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := primaryJars(tc.jars); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("primaryJars(%v) = %v, want %v", tc.jars, got, tc.want)
+			}
+		})
+	}
+}
@@ -22,10 +22,39 @@ import (
 
 // MavenProject is the root struct that contains the unmarshalled pom.xml.
 type MavenProject struct {
-	Plugins    []MavenPlugin  `xml:"build>plugins>plugin"`
-	Profiles   []MavenProfile `xml:"profiles>profile"`
-	ArtifactID string         `xml:"artifactId"`
-	Version    string         `xml:"version"`
+	Plugins            []MavenPlugin     `xml:"build>plugins>plugin"`
+	Profiles           []MavenProfile    `xml:"profiles>profile"`
+	Repositories       []MavenRepository `xml:"repositories>repository"`
+	PluginRepositories []MavenRepository `xml:"pluginRepositories>pluginRepository"`
+	Modules            []string          `xml:"modules>module"`
+	Dependencies       []MavenDependency `xml:"dependencies>dependency"`
+	ArtifactID         string            `xml:"artifactId"`
+	Version            string            `xml:"version"`
+	Packaging          string            `xml:"packaging"`
+	Properties         MavenProperties   `xml:"properties"`
+}
+
+// MavenProperties contains the properties defined in the pom.xml.
+type MavenProperties struct {
+	Entries []MavenProperty `xml:",any"`
+}
+
+// MavenProperty is a property defined in the pom.xml.
+type MavenProperty struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+// MavenDependency describes a dependency defined in the pom.xml.
+type MavenDependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+}
+
+// MavenRepository describes a remote repository defined in the pom.xml.
+type MavenRepository struct {
+	ID  string `xml:"id"`
+	URL string `xml:"url"`
 }
 
 // MavenProfile describes a profile defined in the pom.xml.
@@ -39,12 +68,23 @@ type MavenPlugin struct {
 	GroupID       string                   `xml:"groupId"`
 	ArtifactID    string                   `xml:"artifactId"`
 	Configuration MavenPluginConfiguration `xml:"configuration"`
+	Executions    []MavenPluginExecution   `xml:"executions>execution"`
+}
+
+// MavenPluginExecution describes an execution of a plugin defined in the pom.xml.
+type MavenPluginExecution struct {
+	ID            string                   `xml:"id"`
+	Configuration MavenPluginConfiguration `xml:"configuration"`
 }
 
 // MavenPluginConfiguration describes plugin settings that are parsed from the pom.xml.
 type MavenPluginConfiguration struct {
 	MainClass string `xml:"mainClass"`
 	BuildArgs string `xml:"buildArgs"`
+	// ToolchainJDKVersion is the JDK version required by the maven-toolchains-plugin.
+	ToolchainJDKVersion string `xml:"toolchains>jdk>version"`
+	// RequireJavaVersion is the Java version range required by the maven-enforcer-plugin.
+	RequireJavaVersion string `xml:"rules>requireJavaVersion>version"`
 }
 
 // ParsePomFile unmarshals the provided pom.xml into a MavenProject.
@@ -48,10 +48,16 @@ func TestParseValidPom(t *testing.T) {
 									MainClass: "com.example.Driver",
 									BuildArgs: "--no-server",
 								},
+								Executions: []MavenPluginExecution{{}},
 							},
 						},
 					},
 				},
+				Dependencies: []MavenDependency{
+					{GroupID: "com.google.cloud", ArtifactID: "google-cloud-graalvm-support"},
+					{GroupID: "com.google.cloud", ArtifactID: "google-cloud-core"},
+					{GroupID: "com.google.cloud", ArtifactID: "google-cloud-firestore"},
+				},
 				ArtifactID: "firestore-sample",
 				Version:    "",
 			},
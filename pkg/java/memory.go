@@ -0,0 +1,205 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+)
+
+const (
+	// JavaToolOptionsEnv is read by the JVM as additional options.
+	JavaToolOptionsEnv = "JAVA_TOOL_OPTIONS"
+	// DefaultThreadCount is the default number of threads that stack memory is reserved for.
+	DefaultThreadCount = 250
+
+	mib = 1024 * 1024
+	// jvmClassCount approximates the number of JDK classes loaded by a typical application.
+	jvmClassCount = 12000
+	// classLoadFactor is the share of the application classes that are loaded at runtime.
+	classLoadFactor = 0.35
+	// classMetaspace is the metaspace used by a loaded class, in bytes.
+	classMetaspace = 5800
+	// metaspaceOverhead is the metaspace used independently of the classes, in bytes.
+	metaspaceOverhead = 14 * mib
+	// defaultDirectMemory, defaultCodeCache and defaultStack are the JVM defaults.
+	defaultDirectMemory = 10 * mib
+	defaultCodeCache    = 240 * mib
+	defaultStack        = 1 * mib
+	// minHeapRatio is the smallest share of the memory limit assigned to the heap, which is also the
+	// JVM default. The non-heap reservations are upper bounds that are rarely reached together.
+	minHeapRatio = 4
+)
+
+var (
+	// memoryFlags matches the JVM options that the memory calculator sets, with their size.
+	memoryFlags = map[string]*regexp.Regexp{
+		"heap":      regexp.MustCompile(`(?:^|\s)-Xmx(\d+[kKmMgGtT]?)(?:\s|$)`),
+		"stack":     regexp.MustCompile(`(?:^|\s)-Xss(\d+[kKmMgGtT]?)(?:\s|$)`),
+		"metaspace": regexp.MustCompile(`(?:^|\s)-XX:MaxMetaspaceSize=(\d+[kKmMgGtT]?)(?:\s|$)`),
+		"codeCache": regexp.MustCompile(`(?:^|\s)-XX:ReservedCodeCacheSize=(\d+[kKmMgGtT]?)(?:\s|$)`),
+		"direct":    regexp.MustCompile(`(?:^|\s)-XX:MaxDirectMemorySize=(\d+[kKmMgGtT]?)(?:\s|$)`),
+	}
+)
+
+// MemoryOptions returns the JVM options that size the heap, metaspace, code cache, direct memory
+// and thread stacks so that they fit in the memory limit in bytes, for an application with the
+// given number of classes and threads. Sizes that are set in the existing JAVA_TOOL_OPTIONS are
+// kept and accounted for. If the sizes add up to more than the limit, e.g. because the code cache
+// and the thread stacks alone do not leave room for the minimum heap, a warning describing the
+// memory budget is returned along with the options.
+func MemoryOptions(limit int64, classCount, threadCount int, existing string) ([]string, string, error) {
+	size := func(name string, def int64) (int64, bool, error) {
+		m := memoryFlags[name].FindStringSubmatch(existing)
+		if m == nil {
+			return def, false, nil
+		}
+		v, err := parseMemorySize(m[1])
+		return v, true, err
+	}
+	metaspaceDefault := int64(float64(jvmClassCount+classCount)*classLoadFactor*classMetaspace) + metaspaceOverhead
+
+	var opts, budget []string
+	var nonHeap int64
+	for _, f := range []struct {
+		name   string
+		desc   string
+		def    int64
+		format string
+		count  int64
+	}{
+		{name: "direct", desc: "direct memory", def: defaultDirectMemory, format: "-XX:MaxDirectMemorySize=%dM", count: 1},
+		{name: "metaspace", desc: "metaspace", def: metaspaceDefault, format: "-XX:MaxMetaspaceSize=%dM", count: 1},
+		{name: "codeCache", desc: "code cache", def: defaultCodeCache, format: "-XX:ReservedCodeCacheSize=%dM", count: 1},
+		{name: "stack", desc: "thread stacks", def: defaultStack, format: "-Xss%dM", count: int64(threadCount)},
+	} {
+		v, set, err := size(f.name, f.def)
+		if err != nil {
+			return nil, "", err
+		}
+		if !set {
+			v = roundUpMiB(v)
+			opts = append(opts, fmt.Sprintf(f.format, v/mib))
+		}
+		nonHeap += v * f.count
+		if f.count == 1 {
+			budget = append(budget, fmt.Sprintf("%s %s", formatMiB(v), f.desc))
+		} else {
+			budget = append(budget, fmt.Sprintf("%d %s of %s", f.count, f.desc, formatMiB(v)))
+		}
+	}
+
+	heap, set, err := size("heap", 0)
+	if err != nil {
+		return nil, "", err
+	}
+	if !set {
+		heap = limit - nonHeap
+		if min := limit / minHeapRatio; heap < min {
+			heap = min
+		}
+		opts = append([]string{fmt.Sprintf("-Xmx%dM", heap/mib)}, opts...)
+	}
+
+	var warning string
+	if total := heap + nonHeap; total > limit {
+		warning = fmt.Sprintf("The JVM memory of %s exceeds the container memory limit of %s: %s heap, %s. "+
+			"The container may be stopped when the JVM uses its memory. Increase the memory limit, reduce the number of threads "+
+			"with %s, or set smaller sizes, e.g. -XX:ReservedCodeCacheSize or -Xss, in %s.",
+			formatMiB(total), formatMiB(limit), formatMiB(heap), strings.Join(budget, ", "), env.JavaThreadCount, JavaToolOptionsEnv)
+	}
+	return opts, warning, nil
+}
+
+// formatMiB formats a size in bytes in MiB, e.g. 240M, as in JVM options.
+func formatMiB(v int64) string {
+	return fmt.Sprintf("%dM", v/mib)
+}
+
+// parseMemorySize parses a JVM memory size such as 512m or 1G in bytes.
+func parseMemorySize(s string) (int64, error) {
+	multiplier := int64(1)
+	switch strings.ToLower(s[len(s)-1:]) {
+	case "k":
+		multiplier = 1024
+	case "m":
+		multiplier = mib
+	case "g":
+		multiplier = 1024 * mib
+	case "t":
+		multiplier = 1024 * 1024 * mib
+	}
+	if multiplier != 1 {
+		s = s[:len(s)-1]
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing memory size %q: %w", s, err)
+	}
+	return v * multiplier, nil
+}
+
+func roundUpMiB(v int64) int64 {
+	return (v + mib - 1) / mib * mib
+}
+
+// CountClasses returns the number of classes in the jar, including the classes of the jars nested
+// in it, e.g. the dependencies of a Spring Boot fat jar.
+func CountClasses(jar string) (int, error) {
+	r, err := zip.OpenReader(jar)
+	if err != nil {
+		return 0, fmt.Errorf("opening jar %s: %w", jar, err)
+	}
+	defer r.Close()
+	return countClasses(r.File)
+}
+
+func countClasses(files []*zip.File) (int, error) {
+	count := 0
+	for _, f := range files {
+		switch {
+		case strings.HasSuffix(f.Name, ".class"):
+			count++
+		case strings.HasSuffix(f.Name, ".jar"):
+			rc, err := f.Open()
+			if err != nil {
+				return 0, fmt.Errorf("opening nested jar %s: %w", f.Name, err)
+			}
+			content, err := ioutil.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				return 0, fmt.Errorf("reading nested jar %s: %w", f.Name, err)
+			}
+			nested, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+			if err != nil {
+				// Files named .jar that are not archives do not contain classes.
+				continue
+			}
+			n, err := countClasses(nested.File)
+			if err != nil {
+				return 0, err
+			}
+			count += n
+		}
+	}
+	return count, nil
+}
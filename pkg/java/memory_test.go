@@ -0,0 +1,171 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMemoryOptions(t *testing.T) {
+	testCases := []struct {
+		name        string
+		limit       int64
+		classCount  int
+		threadCount int
+		existing    string
+		want        []string
+		wantWarning string
+		wantErr     bool
+	}{
+		{
+			name:        "1G container",
+			limit:       1024 * mib,
+			classCount:  5000,
+			threadCount: 250,
+			want:        []string{"-Xmx477M", "-XX:MaxDirectMemorySize=10M", "-XX:MaxMetaspaceSize=47M", "-XX:ReservedCodeCacheSize=240M", "-Xss1M"},
+		},
+		{
+			name:        "small container keeps the default heap ratio",
+			limit:       512 * mib,
+			classCount:  5000,
+			threadCount: 250,
+			want:        []string{"-Xmx128M", "-XX:MaxDirectMemorySize=10M", "-XX:MaxMetaspaceSize=47M", "-XX:ReservedCodeCacheSize=240M", "-Xss1M"},
+			wantWarning: "The JVM memory of 675M exceeds the container memory limit of 512M: 128M heap, 10M direct memory, 47M metaspace, 240M code cache, 250 thread stacks of 1M.",
+		},
+		{
+			name:        "small container with fewer threads",
+			limit:       512 * mib,
+			classCount:  5000,
+			threadCount: 50,
+			want:        []string{"-Xmx165M", "-XX:MaxDirectMemorySize=10M", "-XX:MaxMetaspaceSize=47M", "-XX:ReservedCodeCacheSize=240M", "-Xss1M"},
+		},
+		{
+			name:        "user heap exceeds the limit",
+			limit:       1024 * mib,
+			classCount:  5000,
+			threadCount: 250,
+			existing:    "-Xmx900m",
+			want:        []string{"-XX:MaxDirectMemorySize=10M", "-XX:MaxMetaspaceSize=47M", "-XX:ReservedCodeCacheSize=240M", "-Xss1M"},
+			wantWarning: "The JVM memory of 1447M exceeds the container memory limit of 1024M: 900M heap,",
+		},
+		{
+			name:        "fewer threads",
+			limit:       1024 * mib,
+			classCount:  5000,
+			threadCount: 50,
+			want:        []string{"-Xmx677M", "-XX:MaxDirectMemorySize=10M", "-XX:MaxMetaspaceSize=47M", "-XX:ReservedCodeCacheSize=240M", "-Xss1M"},
+		},
+		{
+			name:        "user settings are kept and accounted for",
+			limit:       1024 * mib,
+			classCount:  5000,
+			threadCount: 250,
+			existing:    "-XX:+UseSerialGC -Xss512k -XX:ReservedCodeCacheSize=64m",
+			want:        []string{"-Xmx778M", "-XX:MaxDirectMemorySize=10M", "-XX:MaxMetaspaceSize=47M"},
+		},
+		{
+			name:        "user heap",
+			limit:       1024 * mib,
+			classCount:  5000,
+			threadCount: 250,
+			existing:    "-Xmx300m",
+			want:        []string{"-XX:MaxDirectMemorySize=10M", "-XX:MaxMetaspaceSize=47M", "-XX:ReservedCodeCacheSize=240M", "-Xss1M"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, warning, err := MemoryOptions(tc.limit, tc.classCount, tc.threadCount, tc.existing)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("MemoryOptions() got error: %v, want error: %v", err, tc.wantErr)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("MemoryOptions() = %v, want %v", got, tc.want)
+			}
+			if !strings.HasPrefix(warning, tc.wantWarning) || (tc.wantWarning == "") != (warning == "") {
+				t.Errorf("MemoryOptions() warning = %q, want prefix %q", warning, tc.wantWarning)
+			}
+		})
+	}
+}
+
+func TestParseMemorySize(t *testing.T) {
+	testCases := map[string]int64{
+		"1024": 1024,
+		"512k": 512 * 1024,
+		"64M":  64 * mib,
+		"2g":   2048 * mib,
+	}
+	for s, want := range testCases {
+		t.Run(s, func(t *testing.T) {
+			got, err := parseMemorySize(s)
+			if err != nil {
+				t.Fatalf("parseMemorySize(%q) got error: %v", s, err)
+			}
+			if got != want {
+				t.Errorf("parseMemorySize(%q) = %d, want %d", s, got, want)
+			}
+		})
+	}
+}
+
+func TestCountClasses(t *testing.T) {
+	nested := zipContent(t, map[string][]byte{
+		"com/example/lib/A.class": nil,
+		"com/example/lib/B.class": nil,
+		"META-INF/MANIFEST.MF":    nil,
+	})
+	jar := filepath.Join(t.TempDir(), "app.jar")
+	if err := os.WriteFile(jar, zipContent(t, map[string][]byte{
+		"BOOT-INF/classes/com/example/Main.class": nil,
+		"BOOT-INF/lib/lib.jar":                    nested,
+		"BOOT-INF/lib/not-a.jar":                  []byte("text"),
+		"org/springframework/boot/loader/L.class": nil,
+	}), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := CountClasses(jar)
+	if err != nil {
+		t.Fatalf("CountClasses() got error: %v", err)
+	}
+	if got != 4 {
+		t.Errorf("CountClasses() = %d, want 4", got)
+	}
+}
+
+func zipContent(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,185 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+var (
+	// gradleProjectDependencyRe matches project dependencies in Groovy and Kotlin build scripts,
+	// e.g. project(':libs:common') or project(path: ":libs:common").
+	gradleProjectDependencyRe = regexp.MustCompile(`project\(\s*(?:path\s*[:=]\s*)?["'](:[^"']+)["']`)
+	// gradleBuildScripts are the names of the build script of a Gradle project.
+	gradleBuildScripts = []string{"build.gradle", "build.gradle.kts"}
+)
+
+// Module returns the module to build in a multi-module build from GOOGLE_JAVA_MODULE, or an
+// empty string if the whole build is built.
+func Module() (string, error) {
+	module := strings.TrimSpace(os.Getenv(env.JavaModule))
+	if module == "" {
+		return "", nil
+	}
+	module = filepath.Clean(module)
+	if filepath.IsAbs(module) || module == "." || module == ".." || strings.HasPrefix(module, "../") {
+		return "", gcp.UserErrorf("invalid %s %q, it must be a path relative to the root of the build", env.JavaModule, module)
+	}
+	return module, nil
+}
+
+// GradleProjectPath returns the Gradle project path of the module directory, e.g. :services:api
+// for services/api.
+func GradleProjectPath(module string) string {
+	return ":" + strings.ReplaceAll(filepath.ToSlash(module), "/", ":")
+}
+
+// MavenModulePoms returns the pom.xml files of the module in the reactor at root, of the reactor
+// modules it depends on transitively and of their parent directories. These are the files that
+// determine the build of `mvn -pl <module> -am`.
+func MavenModulePoms(ctx *gcp.Context, root, module string) ([]string, error) {
+	projects := map[string]*MavenProject{}
+	if err := readReactor(ctx, root, ".", projects); err != nil {
+		return nil, err
+	}
+	if _, ok := projects[module]; !ok {
+		return nil, gcp.UserErrorf("module %q is not a module of the Maven build in %s", module, root)
+	}
+	modules := map[string]string{}
+	for dir, p := range projects {
+		modules[p.ArtifactID] = dir
+	}
+
+	dirs := map[string]bool{}
+	queue := []string{module}
+	for len(queue) > 0 {
+		dir := queue[0]
+		queue = queue[1:]
+		if dirs[dir] {
+			continue
+		}
+		dirs[dir] = true
+		for _, d := range projects[dir].Dependencies {
+			if dep, ok := modules[d.ArtifactID]; ok {
+				queue = append(queue, dep)
+			}
+		}
+	}
+	// Parent directories usually contain the parent pom, which configures the module.
+	for dir := range dirs {
+		for parent := filepath.Dir(dir); ; parent = filepath.Dir(parent) {
+			if _, ok := projects[parent]; ok {
+				dirs[parent] = true
+			}
+			if parent == "." {
+				break
+			}
+		}
+	}
+	return sortedPaths(root, dirs, func(dir string) []string { return []string{filepath.Join(dir, "pom.xml")} }), nil
+}
+
+// readReactor parses the pom.xml in dir and the poms of its modules recursively, keyed by the
+// module directory relative to root.
+func readReactor(ctx *gcp.Context, root, dir string, projects map[string]*MavenProject) error {
+	if _, ok := projects[dir]; ok {
+		return nil
+	}
+	content, err := ctx.ReadFile(filepath.Join(root, dir, "pom.xml"))
+	if err != nil {
+		return err
+	}
+	project, err := ParsePomFile(content)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", filepath.Join(dir, "pom.xml"), err)
+	}
+	projects[dir] = project
+	for _, m := range project.Modules {
+		// Modules may reference a pom file instead of a directory.
+		m = strings.TrimSuffix(filepath.Clean(m), string(filepath.Separator)+"pom.xml")
+		if err := readReactor(ctx, root, filepath.Join(dir, m), projects); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GradleModuleBuildFiles returns the build scripts of the project at the module directory, of the
+// projects it depends on transitively and of the root project. Projects are expected in the
+// directory that matches their project path, which is the Gradle default.
+func GradleModuleBuildFiles(ctx *gcp.Context, root, module string) ([]string, error) {
+	dirs := map[string]bool{}
+	queue := []string{module}
+	for len(queue) > 0 {
+		dir := queue[0]
+		queue = queue[1:]
+		if dirs[dir] {
+			continue
+		}
+		dirs[dir] = true
+		for _, script := range gradleBuildScripts {
+			path := filepath.Join(root, dir, script)
+			exists, err := ctx.FileExists(path)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				continue
+			}
+			content, err := ctx.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			for _, m := range gradleProjectDependencyRe.FindAllStringSubmatch(string(content), -1) {
+				queue = append(queue, filepath.FromSlash(strings.ReplaceAll(strings.TrimPrefix(m[1], ":"), ":", "/")))
+			}
+		}
+	}
+	// The root build script configures all projects.
+	dirs["."] = true
+	var files []string
+	for _, path := range sortedPaths(root, dirs, func(dir string) []string {
+		return []string{filepath.Join(dir, gradleBuildScripts[0]), filepath.Join(dir, gradleBuildScripts[1])}
+	}) {
+		exists, err := ctx.FileExists(path)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
+
+// sortedPaths returns the sorted absolute paths of the files of each directory.
+func sortedPaths(root string, dirs map[string]bool, files func(dir string) []string) []string {
+	var paths []string
+	for dir := range dirs {
+		for _, f := range files(dir) {
+			paths = append(paths, filepath.Join(root, f))
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,136 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+func TestModule(t *testing.T) {
+	testCases := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "", want: ""},
+		{value: "services/api/", want: "services/api"},
+		{value: " api ", want: "api"},
+		{value: "/services/api", wantErr: true},
+		{value: "../api", wantErr: true},
+		{value: ".", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			t.Setenv("GOOGLE_JAVA_MODULE", tc.value)
+			got, err := Module()
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("Module() got error: %v, want error: %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("Module() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGradleProjectPath(t *testing.T) {
+	if got, want := GradleProjectPath("services/api"), ":services:api"; got != want {
+		t.Errorf("GradleProjectPath() = %q, want %q", got, want)
+	}
+}
+
+func TestMavenModulePoms(t *testing.T) {
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{
+		"pom.xml":                  pom("parent", []string{"libs", "services/api", "services/worker"}, nil),
+		"libs/pom.xml":             pom("libs", []string{"common", "unused/pom.xml"}, nil),
+		"libs/common/pom.xml":      pom("common", nil, nil),
+		"libs/unused/pom.xml":      pom("unused", nil, nil),
+		"services/api/pom.xml":     pom("api", nil, []string{"common", "guava"}),
+		"services/worker/pom.xml":  pom("worker", nil, []string{"api"}),
+		"services/other/pom.xml":   pom("other", nil, nil),
+		"services/api/src/App.txt": "",
+	})
+
+	got, err := MavenModulePoms(gcp.NewContext(), root, "services/api")
+	if err != nil {
+		t.Fatalf("MavenModulePoms() got error: %v", err)
+	}
+	var want []string
+	for _, f := range []string{"libs/common/pom.xml", "libs/pom.xml", "pom.xml", "services/api/pom.xml"} {
+		want = append(want, filepath.Join(root, f))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MavenModulePoms() = %v, want %v", got, want)
+	}
+
+	if _, err := MavenModulePoms(gcp.NewContext(), root, "services/other"); err == nil {
+		t.Errorf("MavenModulePoms() for a module outside the reactor got no error, want error")
+	}
+}
+
+func TestGradleModuleBuildFiles(t *testing.T) {
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{
+		"settings.gradle":               "include 'services:api', 'libs:common', 'libs:json', 'services:worker'",
+		"build.gradle":                  "",
+		"services/api/build.gradle.kts": `dependencies { implementation(project(":libs:common")) }`,
+		"libs/common/build.gradle":      `dependencies { api project(path: ':libs:json') }`,
+		"libs/json/build.gradle":        "",
+		"services/worker/build.gradle":  `dependencies { implementation project(':services:api') }`,
+	})
+
+	got, err := GradleModuleBuildFiles(gcp.NewContext(), root, "services/api")
+	if err != nil {
+		t.Fatalf("GradleModuleBuildFiles() got error: %v", err)
+	}
+	var want []string
+	for _, f := range []string{"build.gradle", "libs/common/build.gradle", "libs/json/build.gradle", "services/api/build.gradle.kts"} {
+		want = append(want, filepath.Join(root, f))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GradleModuleBuildFiles() = %v, want %v", got, want)
+	}
+}
+
+func pom(artifactID string, modules, dependencies []string) string {
+	content := "<project><artifactId>" + artifactID + "</artifactId><modules>"
+	for _, m := range modules {
+		content += "<module>" + m + "</module>"
+	}
+	content += "</modules><dependencies>"
+	for _, d := range dependencies {
+		content += "<dependency><groupId>com.example</groupId><artifactId>" + d + "</artifactId></dependency>"
+	}
+	return content + "</dependencies></project>"
+}
+
+func writeTestFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
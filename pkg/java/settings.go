@@ -0,0 +1,147 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/ar"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+// MavenServer is the login for a Maven repository, identified by the ID of the repository.
+type MavenServer struct {
+	ID       string `xml:"id"`
+	Username string `xml:"username"`
+	Password string `xml:"password"`
+}
+
+// mavenSettings is the root of a Maven settings.xml file.
+type mavenSettings struct {
+	XMLName xml.Name      `xml:"settings"`
+	Servers []MavenServer `xml:"servers>server"`
+}
+
+// MavenSettings writes a settings.xml file with the credentials of the repositories used by the
+// project, from GOOGLE_MAVEN_SERVER_CREDENTIALS and Application Default Credentials for Artifact
+// Registry repositories. The file is written outside of the layers and the application, because
+// ~/.m2 is a cache layer, and is meant to be passed to Maven with --global-settings so that the
+// settings of the project still apply. It returns an empty path if there are no credentials. The
+// returned cleanup function removes the file and must be called once the build is complete.
+func MavenSettings(ctx *gcp.Context, project *MavenProject) (string, func(), error) {
+	cleanup := func() {}
+	servers, err := parseServerCredentials(os.Getenv(env.MavenServerCredentials))
+	if err != nil {
+		return "", cleanup, err
+	}
+	if project != nil {
+		servers = append(servers, artifactRegistryServers(ctx, project, servers)...)
+	}
+	if len(servers) == 0 {
+		return "", cleanup, nil
+	}
+
+	content, err := settingsContent(servers)
+	if err != nil {
+		return "", cleanup, err
+	}
+	dir, err := os.MkdirTemp("", "maven-settings-")
+	if err != nil {
+		return "", cleanup, gcp.InternalErrorf("creating settings directory: %v", err)
+	}
+	cleanup = func() {
+		if err := os.RemoveAll(dir); err != nil {
+			ctx.Warnf("Failed to remove settings directory %s: %v", dir, err)
+		}
+	}
+	path := filepath.Join(dir, "settings.xml")
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		cleanup()
+		return "", func() {}, gcp.InternalErrorf("writing settings file: %v", err)
+	}
+	ids := make([]string, len(servers))
+	for i, s := range servers {
+		ids[i] = s.ID
+	}
+	ctx.Logf("Configured credentials for Maven repositories: %s", strings.Join(ids, ", "))
+	return path, cleanup, nil
+}
+
+// parseServerCredentials parses the comma-separated list of credentials in
+// GOOGLE_MAVEN_SERVER_CREDENTIALS.
+func parseServerCredentials(val string) ([]MavenServer, error) {
+	var servers []MavenServer
+	for _, entry := range strings.Split(val, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		// Do not include the value in the errors, it contains a secret.
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, gcp.UserErrorf("invalid entry in %s, each entry must be of the form <server-id>=<username>:<password>", env.MavenServerCredentials)
+		}
+		login := strings.SplitN(parts[1], ":", 2)
+		if len(login) != 2 || login[0] == "" || login[1] == "" {
+			return nil, gcp.UserErrorf("invalid entry for server %q in %s, the credentials must be of the form <username>:<password>", parts[0], env.MavenServerCredentials)
+		}
+		servers = append(servers, MavenServer{ID: parts[0], Username: login[0], Password: login[1]})
+	}
+	return servers, nil
+}
+
+// artifactRegistryServers returns the credentials of the Artifact Registry repositories of the
+// project that are not configured explicitly.
+func artifactRegistryServers(ctx *gcp.Context, project *MavenProject, configured []MavenServer) []MavenServer {
+	seen := map[string]bool{}
+	for _, s := range configured {
+		seen[s.ID] = true
+	}
+	var ids []string
+	for _, r := range append(project.Repositories, project.PluginRepositories...) {
+		if r.ID != "" && !seen[r.ID] && ar.IsMavenRepository(r.URL) {
+			seen[r.ID] = true
+			ids = append(ids, r.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	username, password, err := ar.MavenCredentials()
+	if err != nil {
+		// Application Default Credentials are missing when running the buildpacks locally outside of
+		// GCB. Credentials might not be required for the build to succeed so we should not fail here.
+		ctx.Warnf("Skipping Artifact Registry credentials for %s. Unable to find Application Default Credentials: %v", strings.Join(ids, ", "), err)
+		return nil
+	}
+	var servers []MavenServer
+	for _, id := range ids {
+		servers = append(servers, MavenServer{ID: id, Username: username, Password: password})
+	}
+	return servers
+}
+
+// settingsContent returns a settings.xml file with the servers.
+func settingsContent(servers []MavenServer) ([]byte, error) {
+	content, err := xml.MarshalIndent(mavenSettings{Servers: servers}, "", "  ")
+	if err != nil {
+		return nil, gcp.InternalErrorf("marshalling settings.xml: %v", err)
+	}
+	return append([]byte(xml.Header), content...), nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+func TestParseServerCredentials(t *testing.T) {
+	testCases := []struct {
+		name    string
+		val     string
+		want    []MavenServer
+		wantErr bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "multiple servers",
+			val:  "my-repo=deployer:s3cr3t, other-repo=reader:pa:ss=word",
+			want: []MavenServer{
+				{ID: "my-repo", Username: "deployer", Password: "s3cr3t"},
+				{ID: "other-repo", Username: "reader", Password: "pa:ss=word"},
+			},
+		},
+		{
+			name:    "missing server id",
+			val:     "deployer:s3cr3t",
+			wantErr: true,
+		},
+		{
+			name:    "missing password",
+			val:     "my-repo=deployer",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseServerCredentials(tc.val)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("parseServerCredentials() got error: %v, want error: %v", err, tc.wantErr)
+			}
+			if err != nil && strings.Contains(err.Error(), "s3cr3t") {
+				t.Errorf("parseServerCredentials() error %q contains the password", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("parseServerCredentials() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSettingsContent(t *testing.T) {
+	got, err := settingsContent([]MavenServer{{ID: "my-repo", Username: "deployer", Password: "a<b&c"}})
+	if err != nil {
+		t.Fatalf("settingsContent() got error: %v", err)
+	}
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<settings>
+  <servers>
+    <server>
+      <id>my-repo</id>
+      <username>deployer</username>
+      <password>a&lt;b&amp;c</password>
+    </server>
+  </servers>
+</settings>`
+	if string(got) != want {
+		t.Errorf("settingsContent() = %s, want %s", got, want)
+	}
+}
+
+func TestMavenSettings(t *testing.T) {
+	t.Setenv("GOOGLE_MAVEN_SERVER_CREDENTIALS", "my-repo=deployer:s3cr3t")
+
+	path, cleanup, err := MavenSettings(gcp.NewContext(), &MavenProject{})
+	if err != nil {
+		t.Fatalf("MavenSettings() got error: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat settings: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("settings mode = %v, want 0600", info.Mode().Perm())
+	}
+	cleanup()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("settings %s exists after cleanup, got error: %v", path, err)
+	}
+}
+
+func TestMavenSettingsNoCredentials(t *testing.T) {
+	project := &MavenProject{Repositories: []MavenRepository{{ID: "central", URL: "https://repo.maven.apache.org/maven2"}}}
+	path, cleanup, err := MavenSettings(gcp.NewContext(), project)
+	defer cleanup()
+	if err != nil {
+		t.Fatalf("MavenSettings() got error: %v", err)
+	}
+	if path != "" {
+		t.Errorf("MavenSettings() = %q, want empty path", path)
+	}
+}
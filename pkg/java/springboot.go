@@ -0,0 +1,66 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"archive/zip"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+const (
+	// SpringBootLayersIndex is the path of the layers index in a layered Spring Boot fat jar.
+	SpringBootLayersIndex = "BOOT-INF/layers.idx"
+	// SpringBootStartClassKey is the manifest entry of a Spring Boot fat jar with the application main class.
+	SpringBootStartClassKey = "Start-Class"
+)
+
+// JarEntry returns the content of a file in a jar, or nil if the file does not exist.
+func JarEntry(jarPath, name string) ([]byte, error) {
+	r, err := zip.OpenReader(jarPath)
+	if err != nil {
+		return nil, gcp.UserErrorf("unzipping jar %s: %v", jarPath, err)
+	}
+	defer r.Close()
+	for _, f := range r.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("opening file %s in jar %s: %v", name, jarPath, err)
+		}
+		defer rc.Close()
+		return ioutil.ReadAll(rc)
+	}
+	return nil, nil
+}
+
+// ParseLayersIndex returns the layer names of a Spring Boot layers index, in the order in which
+// they are declared. Layers are ordered from the least to the most frequently changed.
+func ParseLayersIndex(content []byte) []string {
+	var layers []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimRight(line, "\r")
+		// Layers are top-level entries of the form `- "name":`, their contents are indented.
+		if strings.HasPrefix(line, `- "`) && strings.HasSuffix(line, `":`) {
+			layers = append(layers, strings.TrimSuffix(strings.TrimPrefix(line, `- "`), `":`))
+		}
+	}
+	return layers
+}
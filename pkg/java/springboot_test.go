@@ -0,0 +1,99 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseLayersIndex(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name: "default layers",
+			content: `- "dependencies":
+  - "BOOT-INF/lib/"
+- "spring-boot-loader":
+  - "org/"
+- "snapshot-dependencies":
+- "application":
+  - "BOOT-INF/classes/"
+  - "BOOT-INF/classpath.idx"
+  - "BOOT-INF/layers.idx"
+  - "META-INF/"
+`,
+			want: []string{"dependencies", "spring-boot-loader", "snapshot-dependencies", "application"},
+		},
+		{
+			name:    "custom layers with CRLF",
+			content: "- \"company-dependencies\":\r\n  - \"BOOT-INF/lib/company-lib.jar\"\r\n- \"application\":\r\n",
+			want:    []string{"company-dependencies", "application"},
+		},
+		{
+			name: "empty",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ParseLayersIndex([]byte(tc.content)); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("ParseLayersIndex() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestJarEntry(t *testing.T) {
+	jar := filepath.Join(t.TempDir(), "app.jar")
+	f, err := os.Create(jar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	e, err := w.Create(SpringBootLayersIndex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Write([]byte("- \"application\":\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := JarEntry(jar, SpringBootLayersIndex)
+	if err != nil {
+		t.Fatalf("JarEntry(%q) got error: %v", SpringBootLayersIndex, err)
+	}
+	if string(got) != "- \"application\":\n" {
+		t.Errorf("JarEntry(%q) = %q, want %q", SpringBootLayersIndex, got, "- \"application\":\n")
+	}
+	got, err = JarEntry(jar, "BOOT-INF/classpath.idx")
+	if err != nil {
+		t.Fatalf("JarEntry(classpath.idx) got error: %v", err)
+	}
+	if got != nil {
+		t.Errorf("JarEntry(classpath.idx) = %q, want nil", got)
+	}
+}
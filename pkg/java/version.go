@@ -0,0 +1,206 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+const (
+	javaVersionFile = ".java-version"
+	sdkmanrcFile    = ".sdkmanrc"
+)
+
+var (
+	// featureVersionRegexp matches the feature version of a Java version, e.g. 17 in 17.0.8-tem,
+	// temurin-17.0.8 or 17, and 8 in 1.8.0_382.
+	featureVersionRegexp = regexp.MustCompile(`^(?:[A-Za-z][\w.]*-)?(?:1\.)?([1-9]\d*)(?:[._+-].*)?$`)
+	// gradleToolchainRegexp matches the language version of a Gradle Java toolchain, e.g.
+	// `languageVersion = JavaLanguageVersion.of(17)` or `languageVersion.set(JavaLanguageVersion.of("17"))`.
+	gradleToolchainRegexp = regexp.MustCompile(`languageVersion\s*(?:\.set\s*\(|=)\s*JavaLanguageVersion\.of\(\s*["']?(\d+)["']?\s*\)`)
+)
+
+// versionSource is a file that may specify the Java version, and the function that parses it.
+type versionSource struct {
+	path  string
+	parse func([]byte) (string, error)
+}
+
+// RuntimeVersion returns the requested Java feature version and a description of where it was
+// requested. The version is taken from the first of the following sources that specifies one:
+//  1. The GOOGLE_RUNTIME_VERSION environment variable.
+//  2. The .java-version file.
+//  3. The java entry of the .sdkmanrc file.
+//  4. The JDK version of the maven-toolchains-plugin in pom.xml.
+//  5. The lower bound of the Java version required by the maven-enforcer-plugin in pom.xml.
+//  6. The language version of the Java toolchain in build.gradle or build.gradle.kts.
+//
+// Versions from files are reduced to their feature version, e.g. 17 for 17.0.8-tem. It returns an
+// empty version if none of the sources specify a version.
+func RuntimeVersion(ctx *gcp.Context) (string, string, error) {
+	if v := os.Getenv(env.RuntimeVersion); v != "" {
+		return v, env.RuntimeVersion, nil
+	}
+	dirs, err := projectDirs(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	root := ctx.ApplicationRoot()
+
+	sources := []versionSource{
+		{path: filepath.Join(root, javaVersionFile), parse: parseJavaVersionFile},
+		{path: filepath.Join(root, sdkmanrcFile), parse: parseSdkmanrc},
+		{path: filepath.Join(dirs[0], "pom.xml"), parse: parsePomJavaVersion},
+	}
+	for _, name := range gradleBuildScripts {
+		sources = append(sources, versionSource{path: filepath.Join(dirs[0], name), parse: parseGradleToolchain})
+	}
+	for _, s := range sources {
+		exists, err := ctx.FileExists(s.path)
+		if err != nil {
+			return "", "", err
+		}
+		if !exists {
+			continue
+		}
+		content, err := ctx.ReadFile(s.path)
+		if err != nil {
+			return "", "", err
+		}
+		v, err := s.parse(content)
+		if err != nil {
+			return "", "", err
+		}
+		if v != "" {
+			rel, err := filepath.Rel(root, s.path)
+			if err != nil {
+				rel = s.path
+			}
+			return v, rel, nil
+		}
+	}
+	return "", "", nil
+}
+
+// parseJavaVersionFile returns the feature version in a .java-version file, as used by jenv.
+func parseJavaVersionFile(content []byte) (string, error) {
+	v := strings.TrimSpace(string(content))
+	if v == "" {
+		return "", gcp.UserErrorf("%s exists but does not specify a version", javaVersionFile)
+	}
+	return featureVersion(javaVersionFile, v)
+}
+
+// parseSdkmanrc returns the feature version of the java entry of a .sdkmanrc file, e.g.
+// `java=17.0.8-tem`.
+func parseSdkmanrc(content []byte) (string, error) {
+	s := bufio.NewScanner(bytes.NewReader(content))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) != "java" {
+			continue
+		}
+		return featureVersion(sdkmanrcFile, strings.TrimSpace(parts[1]))
+	}
+	return "", nil
+}
+
+// parsePomJavaVersion returns the feature version required by the maven-toolchains-plugin or,
+// failing that, the lower bound of the version required by the maven-enforcer-plugin.
+func parsePomJavaVersion(content []byte) (string, error) {
+	project, err := ParsePomFile(content)
+	if err != nil {
+		return "", err
+	}
+	var toolchain, enforcer string
+	for _, p := range project.Plugins {
+		configs := []MavenPluginConfiguration{p.Configuration}
+		for _, e := range p.Executions {
+			configs = append(configs, e.Configuration)
+		}
+		for _, c := range configs {
+			if v := strings.TrimSpace(c.ToolchainJDKVersion); v != "" && toolchain == "" {
+				toolchain = v
+			}
+			if v := strings.TrimSpace(c.RequireJavaVersion); v != "" && enforcer == "" {
+				enforcer = v
+			}
+		}
+	}
+	if v := mavenVersionLowerBound(resolveProperty(project, toolchain)); v != "" {
+		return featureVersion("the maven-toolchains-plugin JDK version", v)
+	}
+	if v := mavenVersionLowerBound(resolveProperty(project, enforcer)); v != "" {
+		return featureVersion("the maven-enforcer-plugin requireJavaVersion rule", v)
+	}
+	return "", nil
+}
+
+// resolveProperty returns the value of the project property referenced by v, e.g. ${java.version},
+// or v itself if it does not reference a property. It returns an empty string if the property is
+// not defined in the project.
+func resolveProperty(project *MavenProject, v string) string {
+	if !strings.HasPrefix(v, "${") || !strings.HasSuffix(v, "}") {
+		return v
+	}
+	name := strings.TrimSuffix(strings.TrimPrefix(v, "${"), "}")
+	for _, p := range project.Properties.Entries {
+		if p.XMLName.Local == name {
+			return strings.TrimSpace(p.Value)
+		}
+	}
+	return ""
+}
+
+// parseGradleToolchain returns the language version of the Java toolchain of a Gradle build.
+func parseGradleToolchain(content []byte) (string, error) {
+	if m := gradleToolchainRegexp.FindSubmatch(content); m != nil {
+		return string(m[1]), nil
+	}
+	return "", nil
+}
+
+// mavenVersionLowerBound returns the lower bound of a Maven version range, e.g. 17 for [17,) or
+// [17,21), or the version itself if it is not a range. It returns an empty string for ranges
+// without a lower bound.
+func mavenVersionLowerBound(v string) string {
+	if !strings.HasPrefix(v, "[") && !strings.HasPrefix(v, "(") {
+		return v
+	}
+	// Multiple ranges are separated by commas, e.g. [1.8,9),[11,), the first range is the lowest.
+	lower := strings.SplitN(strings.TrimLeft(v, "[("), ",", 2)[0]
+	return strings.TrimSpace(strings.TrimRight(lower, "])"))
+}
+
+// featureVersion returns the feature version of a Java version from the given source.
+func featureVersion(source, v string) (string, error) {
+	m := featureVersionRegexp.FindStringSubmatch(v)
+	if m == nil {
+		return "", gcp.UserErrorf("invalid Java version %q in %s", v, source)
+	}
+	return m[1], nil
+}
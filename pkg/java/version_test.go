@@ -0,0 +1,199 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+func TestRuntimeVersion(t *testing.T) {
+	toolchainsPom := `<project><build><plugins><plugin>
+  <artifactId>maven-toolchains-plugin</artifactId>
+  <executions><execution><goals><goal>toolchain</goal></goals>
+    <configuration><toolchains><jdk><version>[17,)</version></jdk></toolchains></configuration>
+  </execution></executions>
+</plugin></plugins></build></project>`
+	enforcerPom := `<project><properties><java.version>1.8</java.version></properties><build><plugins><plugin>
+  <artifactId>maven-enforcer-plugin</artifactId>
+  <configuration><rules><requireJavaVersion><version>${java.version}</version></requireJavaVersion></rules></configuration>
+</plugin></plugins></build></project>`
+
+	testCases := []struct {
+		name           string
+		files          map[string]string
+		runtimeVersion string
+		want           string
+		wantSource     string
+		wantErr        bool
+	}{
+		{
+			name: "no version",
+			files: map[string]string{
+				"pom.xml": "<project></project>",
+			},
+		},
+		{
+			name: "environment variable takes precedence",
+			files: map[string]string{
+				".java-version": "17",
+			},
+			runtimeVersion: "21",
+			want:           "21",
+			wantSource:     "GOOGLE_RUNTIME_VERSION",
+		},
+		{
+			name: "java-version",
+			files: map[string]string{
+				".java-version": "temurin-17.0.8\n",
+				".sdkmanrc":     "java=21.0.1-tem",
+			},
+			want:       "17",
+			wantSource: ".java-version",
+		},
+		{
+			name: "sdkmanrc",
+			files: map[string]string{
+				".sdkmanrc": "# Enable auto-env through the sdkman_auto_env config\nmaven=3.9.5\njava=21.0.1-tem\n",
+				"pom.xml":   toolchainsPom,
+			},
+			want:       "21",
+			wantSource: ".sdkmanrc",
+		},
+		{
+			name: "sdkmanrc without java",
+			files: map[string]string{
+				".sdkmanrc": "maven=3.9.5",
+				"pom.xml":   toolchainsPom,
+			},
+			want:       "17",
+			wantSource: "pom.xml",
+		},
+		{
+			name: "maven toolchains",
+			files: map[string]string{
+				"pom.xml": toolchainsPom,
+			},
+			want:       "17",
+			wantSource: "pom.xml",
+		},
+		{
+			name: "maven enforcer with property",
+			files: map[string]string{
+				"pom.xml": enforcerPom,
+			},
+			want:       "8",
+			wantSource: "pom.xml",
+		},
+		{
+			name: "gradle toolchain",
+			files: map[string]string{
+				"build.gradle": "java {\n  toolchain {\n    languageVersion = JavaLanguageVersion.of(17)\n  }\n}",
+			},
+			want:       "17",
+			wantSource: "build.gradle",
+		},
+		{
+			name: "gradle kotlin toolchain",
+			files: map[string]string{
+				"build.gradle.kts": "java {\n    toolchain {\n        languageVersion.set(JavaLanguageVersion.of(21))\n    }\n}",
+			},
+			want:       "21",
+			wantSource: "build.gradle.kts",
+		},
+		{
+			name: "empty java-version",
+			files: map[string]string{
+				".java-version": "\n",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid sdkmanrc version",
+			files: map[string]string{
+				".sdkmanrc": "java=latest",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("GOOGLE_JAVA_MODULE", "")
+			t.Setenv("GOOGLE_RUNTIME_VERSION", tc.runtimeVersion)
+			root := t.TempDir()
+			writeTestFiles(t, root, tc.files)
+
+			got, source, err := RuntimeVersion(gcp.NewContext(gcp.WithApplicationRoot(root)))
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("RuntimeVersion() got error: %v, want error: %t", err, tc.wantErr)
+			}
+			if got != tc.want || source != tc.wantSource {
+				t.Errorf("RuntimeVersion() = (%q, %q), want (%q, %q)", got, source, tc.want, tc.wantSource)
+			}
+		})
+	}
+}
+
+func TestFeatureVersion(t *testing.T) {
+	testCases := []struct {
+		version string
+		want    string
+		wantErr bool
+	}{
+		{version: "17", want: "17"},
+		{version: "17.0.8-tem", want: "17"},
+		{version: "21.0.1+12", want: "21"},
+		{version: "temurin-17.0.8", want: "17"},
+		{version: "1.8", want: "8"},
+		{version: "1.8.0_382", want: "8"},
+		{version: "11.0.21-zulu", want: "11"},
+		{version: "latest", wantErr: true},
+		{version: "", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.version, func(t *testing.T) {
+			got, err := featureVersion("test", tc.version)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("featureVersion(%q) got error: %v, want error: %t", tc.version, err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("featureVersion(%q) = %q, want %q", tc.version, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMavenVersionLowerBound(t *testing.T) {
+	testCases := []struct {
+		version string
+		want    string
+	}{
+		{version: "17", want: "17"},
+		{version: "[17,)", want: "17"},
+		{version: "[17,21)", want: "17"},
+		{version: "[1.8,9),[11,)", want: "1.8"},
+		{version: "[17]", want: "17"},
+		{version: "(,11]", want: ""},
+		{version: "", want: ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.version, func(t *testing.T) {
+			if got := mavenVersionLowerBound(tc.version); got != tc.want {
+				t.Errorf("mavenVersionLowerBound(%q) = %q, want %q", tc.version, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+// warPackaging is the packaging of Maven projects that build a web application archive.
+const warPackaging = "war"
+
+// gradleWarPluginRegexp matches the application of the Gradle war plugin in a Groovy or Kotlin
+// build script, e.g. `id 'war'`, `id("war")`, `apply plugin: 'war'` or `war` in a plugins block.
+var gradleWarPluginRegexp = regexp.MustCompile(`(?m)(\bid\s*\(?\s*["']war["']|\bapply\s*\(?\s*plugin\s*[:=]\s*["']war["']|^\s*war\s*$)`)
+
+// IsWarProject returns true if the application, or the selected module of a multi-module build,
+// builds a WAR file, or if the application is a prebuilt WAR file.
+func IsWarProject(ctx *gcp.Context) (bool, error) {
+	module, err := Module()
+	if err != nil {
+		return false, err
+	}
+	dir := filepath.Join(ctx.ApplicationRoot(), os.Getenv(env.Buildable), module)
+
+	pomPath := filepath.Join(dir, "pom.xml")
+	if exists, err := ctx.FileExists(pomPath); err != nil {
+		return false, err
+	} else if exists {
+		content, err := ctx.ReadFile(pomPath)
+		if err != nil {
+			return false, err
+		}
+		project, err := ParsePomFile(content)
+		if err != nil {
+			return false, err
+		}
+		return strings.TrimSpace(project.Packaging) == warPackaging, nil
+	}
+
+	for _, name := range []string{"build.gradle", "build.gradle.kts"} {
+		path := filepath.Join(dir, name)
+		exists, err := ctx.FileExists(path)
+		if err != nil {
+			return false, err
+		}
+		if !exists {
+			continue
+		}
+		content, err := ctx.ReadFile(path)
+		if err != nil {
+			return false, err
+		}
+		return gradleWarPluginRegexp.Match(content), nil
+	}
+
+	wars, err := ctx.Glob(filepath.Join(ctx.ApplicationRoot(), "*.war"))
+	if err != nil {
+		return false, fmt.Errorf("finding wars: %w", err)
+	}
+	return len(wars) > 0, nil
+}
+
+// WarFile returns the WAR file built by the application. If there is not exactly 1 WAR file in
+// the first path that contains WAR files, it returns an error.
+func WarFile(ctx *gcp.Context) (string, error) {
+	paths, err := artifactPaths()
+	if err != nil {
+		return "", err
+	}
+	for i, path := range paths {
+		path = append([]string{ctx.ApplicationRoot()}, path...)
+		path = append(path, "*.war")
+		wars, err := ctx.Glob(filepath.Join(path...))
+		if err != nil {
+			return "", fmt.Errorf("finding wars: %w", err)
+		}
+		wars = primaryJars(wars)
+		if len(wars) == 1 {
+			return wars[0], nil
+		} else if len(wars) > 1 {
+			return "", gcp.UserErrorf("found more than one war file in %s: %v", paths[i], wars)
+		}
+	}
+	return "", gcp.UserErrorf("did not find any war files")
+}
@@ -0,0 +1,151 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"path/filepath"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+func TestIsWarProject(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		env   map[string]string
+		want  bool
+	}{
+		{
+			name:  "maven war packaging",
+			files: map[string]string{"pom.xml": "<project><packaging>war</packaging></project>"},
+			want:  true,
+		},
+		{
+			name:  "maven jar packaging",
+			files: map[string]string{"pom.xml": "<project><packaging>jar</packaging></project>"},
+		},
+		{
+			name:  "maven default packaging",
+			files: map[string]string{"pom.xml": "<project></project>"},
+		},
+		{
+			name:  "gradle war plugin",
+			files: map[string]string{"build.gradle": "plugins {\n  id 'war'\n}"},
+			want:  true,
+		},
+		{
+			name:  "gradle kotlin war plugin",
+			files: map[string]string{"build.gradle.kts": "plugins {\n    war\n}"},
+			want:  true,
+		},
+		{
+			name:  "gradle apply war plugin",
+			files: map[string]string{"build.gradle": "apply plugin: 'war'"},
+			want:  true,
+		},
+		{
+			name:  "gradle without war plugin",
+			files: map[string]string{"build.gradle": "plugins {\n  id 'java'\n}\nwarName = 'app'"},
+		},
+		{
+			name:  "prebuilt war",
+			files: map[string]string{"app.war": ""},
+			want:  true,
+		},
+		{
+			name:  "module war packaging",
+			files: map[string]string{"pom.xml": "<project><packaging>pom</packaging></project>", "web/pom.xml": "<project><packaging>war</packaging></project>"},
+			env:   map[string]string{"GOOGLE_JAVA_MODULE": "web"},
+			want:  true,
+		},
+		{
+			name:  "no build files",
+			files: map[string]string{"Main.java": ""},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("GOOGLE_JAVA_MODULE", tc.env["GOOGLE_JAVA_MODULE"])
+			root := t.TempDir()
+			writeTestFiles(t, root, tc.files)
+
+			got, err := IsWarProject(gcp.NewContext(gcp.WithApplicationRoot(root)))
+			if err != nil {
+				t.Fatalf("IsWarProject() got error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("IsWarProject() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWarFile(t *testing.T) {
+	testCases := []struct {
+		name    string
+		files   []string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "maven",
+			files: []string{"target/app-1.0.war", "target/classes/App.class"},
+			want:  "target/app-1.0.war",
+		},
+		{
+			name:  "gradle with plain war",
+			files: []string{"build/libs/app.war", "build/libs/app-plain.war"},
+			want:  "build/libs/app.war",
+		},
+		{
+			name:  "prebuilt",
+			files: []string{"app.war"},
+			want:  "app.war",
+		},
+		{
+			name:    "multiple wars",
+			files:   []string{"target/a.war", "target/b.war"},
+			wantErr: true,
+		},
+		{
+			name:    "no war",
+			files:   []string{"target/app.jar"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("GOOGLE_JAVA_MODULE", "")
+			root := t.TempDir()
+			files := map[string]string{}
+			for _, f := range tc.files {
+				files[f] = ""
+			}
+			writeTestFiles(t, root, files)
+
+			got, err := WarFile(gcp.NewContext(gcp.WithApplicationRoot(root)))
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("WarFile() got error %v, want error %t", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if want := filepath.Join(root, tc.want); got != want {
+				t.Errorf("WarFile() = %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -64,6 +64,10 @@ catch_workers_output = yes
 {{else}}
 decorate_workers_output = no
 {{end}}
+{{if .AppConfig}}
+; Settings of the application, which override the ones above.
+include = {{.AppConfig}}
+{{end}}
 `))
 
 // NginxTemplate is a template that produces a snippet of nginx config that sets up the
@@ -84,6 +88,13 @@ server {
 	server_name	"";
 	root	{{.Root}};
 
+{{if .ClientMaxBodySize}}
+	client_max_body_size	{{.ClientMaxBodySize}};
+{{end}}
+{{if .AppConfig}}
+	# Settings of the application, e.g. custom headers.
+	include	{{.AppConfig}};
+{{end}}
 	rewrite	^/(.*)$	/{{.FrontControllerScript}}$uri;
 
 	location	~	^/{{.FrontControllerScript}}	{
@@ -133,6 +144,47 @@ server {
 }
 `))
 
+// StaticTemplate is a template that produces a complete nginx config that serves the static files
+// of a single-page application, such as a Flutter web application. Paths that do not match a file
+// are served the index, so that the application handles its own routes.
+var StaticTemplate = template.Must(template.New("static").Parse(`
+daemon off;
+pid {{.TempDir}}/nginx.pid;
+error_log stderr;
+
+events {}
+
+http {
+	include	{{.MimeTypesPath}};
+	default_type	application/octet-stream;
+	access_log	off;
+	sendfile	on;
+	gzip	on;
+	gzip_types	text/css application/javascript application/json application/wasm image/svg+xml;
+
+	client_body_temp_path	{{.TempDir}}/client_body;
+	proxy_temp_path	{{.TempDir}}/proxy;
+	fastcgi_temp_path	{{.TempDir}}/fastcgi;
+	uwsgi_temp_path	{{.TempDir}}/uwsgi;
+	scgi_temp_path	{{.TempDir}}/scgi;
+
+	server {
+		listen	{{.Port}} default_server;
+		listen	[::]:{{.Port}} default_server;
+		server_name	"";
+		root	{{.Root}};
+		index	index.html;
+{{if .AppConfig}}
+		# Settings of the application, e.g. custom headers.
+		include	{{.AppConfig}};
+{{end}}
+		location	/	{
+			try_files	$uri $uri/ /index.html;
+		}
+	}
+}
+`))
+
 // FPMConfig represents the content values of a php-fpm config file.
 type FPMConfig struct {
 	PidPath        string
@@ -141,6 +193,8 @@ type FPMConfig struct {
 	NumWorkers     int
 	Username       string
 	Runtime        string
+	// AppConfig is the path of the php-fpm pool config of the application, if any.
+	AppConfig string
 }
 
 // Config represents the content values of a nginx config file.
@@ -149,4 +203,23 @@ type Config struct {
 	Root                  string
 	AppListenAddress      string
 	FrontControllerScript string
+	// ClientMaxBodySize is the maximum size of request bodies, e.g. 32m, or empty for the nginx
+	// default.
+	ClientMaxBodySize string
+	// AppConfig is the path of the nginx server config of the application, if any.
+	AppConfig string
+}
+
+// StaticConfig represents the content values of a nginx config file for static files.
+type StaticConfig struct {
+	// Port is the port to listen on, which can be a placeholder that is replaced at launch time.
+	Port string
+	// Root is the directory of the static files.
+	Root string
+	// MimeTypesPath is the path of the mime.types file of nginx.
+	MimeTypesPath string
+	// TempDir is the directory of the pid file and temporary files, which must be writable at launch.
+	TempDir string
+	// AppConfig is the path of the nginx server config of the application, if any.
+	AppConfig string
 }
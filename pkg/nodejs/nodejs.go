@@ -26,8 +26,8 @@ import (
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/cache"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
-	"github.com/buildpacks/libcnb"
 	"github.com/Masterminds/semver"
+	"github.com/buildpacks/libcnb"
 )
 
 const (
@@ -53,15 +53,18 @@ type packageEnginesJSON struct {
 	Node string `json:"node"`
 	NPM  string `json:"npm"`
 	Yarn string `json:"yarn"`
+	PNPM string `json:"pnpm"`
 }
 
 type packageScriptsJSON struct {
 	Start    string `json:"start"`
+	Build    string `json:"build"`
 	GCPBuild string `json:"gcp-build"`
 }
 
 // PackageJSON represents the contents of a package.json file.
 type PackageJSON struct {
+	Name            string             `json:"name"`
 	Main            string             `json:"main"`
 	Type            string             `json:"type"`
 	Version         string             `json:"version"`
@@ -69,6 +72,10 @@ type PackageJSON struct {
 	Scripts         packageScriptsJSON `json:"scripts"`
 	Dependencies    map[string]string  `json:"dependencies"`
 	DevDependencies map[string]string  `json:"devDependencies"`
+	// PackageManager is the package manager of the project and its version, e.g. "pnpm@8.15.4".
+	PackageManager string `json:"packageManager"`
+	// Workspaces is either a list of workspace directories or a Yarn workspaces object.
+	Workspaces json.RawMessage `json:"workspaces"`
 }
 
 // ReadPackageJSONIfExists returns deserialized package.json from the given dir. If the provided dir
@@ -131,6 +138,11 @@ var nodeVersion = func(ctx *gcp.Context) (string, error) {
 	return result.Stdout, nil
 }
 
+// NodeVersion returns the installed version of Node.js, e.g. v18.12.1.
+func NodeVersion(ctx *gcp.Context) (string, error) {
+	return nodeVersion(ctx)
+}
+
 // isPreNode11 returns true if the installed version of Node.js is
 // v10.x.x or older.
 func isPreNode11(ctx *gcp.Context) (bool, error) {
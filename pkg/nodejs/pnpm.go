@@ -0,0 +1,99 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodejs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/buildpacks/libcnb"
+)
+
+const (
+	// PNPMLock is the name of the pnpm lock file.
+	PNPMLock = "pnpm-lock.yaml"
+	// PNPMWorkspace is the file that declares the workspaces of a pnpm monorepo.
+	PNPMWorkspace = "pnpm-workspace.yaml"
+)
+
+// detectPNPMVersion determines the version of pnpm that should be installed in a Node.js project
+// from the "engines.pnpm" constraint of package.json, or else from its "packageManager" field, e.g.
+// "pnpm@8.15.4". It returns the latest version available in the NPM registry if package.json
+// requests neither.
+func detectPNPMVersion(pjs *PackageJSON) (string, error) {
+	if pjs != nil && pjs.Engines.PNPM != "" {
+		requested := pjs.Engines.PNPM
+		version, err := resolvePackageVersion("pnpm", requested)
+		if err != nil {
+			return "", gcp.UserErrorf("finding pnpm version that matched %q: %w", requested, err)
+		}
+		return version, nil
+	}
+	if pjs != nil && strings.HasPrefix(pjs.PackageManager, "pnpm@") {
+		// The version may be followed by the hash of the package, e.g. pnpm@8.15.4+sha256.abc.
+		version := strings.TrimPrefix(pjs.PackageManager, "pnpm@")
+		if i := strings.Index(version, "+"); i >= 0 {
+			version = version[:i]
+		}
+		return version, nil
+	}
+	version, err := latestPackageVersion("pnpm")
+	if err != nil {
+		return "", gcp.InternalErrorf("fetching available pnpm versions: %w", err)
+	}
+	return version, nil
+}
+
+// InstallPNPMLayer installs pnpm in the given layer if it is not already cached.
+func InstallPNPMLayer(ctx *gcp.Context, pnpmLayer *libcnb.Layer, pjs *PackageJSON) error {
+	layerName := pnpmLayer.Name
+	version, err := detectPNPMVersion(pjs)
+	if err != nil {
+		return err
+	}
+
+	metaVersion := ctx.GetMetadata(pnpmLayer, versionKey)
+	if version == metaVersion {
+		ctx.CacheHit(layerName)
+		ctx.Logf("pnpm cache hit: %q, %q, skipping installation.", version, metaVersion)
+	} else {
+		ctx.CacheMiss(layerName)
+		if err := ctx.ClearLayer(pnpmLayer); err != nil {
+			return fmt.Errorf("clearing layer %q: %w", layerName, err)
+		}
+		ctx.Logf("Installing pnpm v%s", version)
+		prefix := fmt.Sprintf("--prefix=%s", pnpmLayer.Path)
+		if _, err := ctx.Exec([]string{"npm", "install", "-g", prefix, "pnpm@" + version}, gcp.WithUserAttribution); err != nil {
+			return err
+		}
+	}
+
+	ctx.SetMetadata(pnpmLayer, versionKey, version)
+	// Update the path so that the version we just installed takes precedence over anything
+	// pre-installed in the base image.
+	if err := ctx.Setenv("PATH", filepath.Join(pnpmLayer.Path, "bin")+":"+os.Getenv("PATH")); err != nil {
+		return err
+	}
+	ctx.AddBOMEntry(libcnb.BOMEntry{
+		Name:     layerName,
+		Metadata: map[string]interface{}{"version": version},
+		Launch:   pnpmLayer.Launch,
+		Build:    pnpmLayer.Build,
+	})
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodejs
+
+import (
+	"testing"
+)
+
+func TestDetectPNPMVersion(t *testing.T) {
+	registry := `{
+		"name": "pnpm",
+		"dist-tags": {
+			"latest": "9.1.0"
+		},
+		"versions": {
+			"8.15.4": {"name": "pnpm", "version": "8.15.4"},
+			"9.1.0": {"name": "pnpm", "version": "9.1.0"}
+		}
+	}`
+	testCases := []struct {
+		name string
+		pjs  *PackageJSON
+		want string
+	}{
+		{
+			name: "no package.json",
+			want: "9.1.0",
+		},
+		{
+			name: "engines",
+			pjs:  &PackageJSON{Engines: packageEnginesJSON{PNPM: "8.x"}},
+			want: "8.15.4",
+		},
+		{
+			name: "packageManager",
+			pjs:  &PackageJSON{PackageManager: "pnpm@8.15.4"},
+			want: "8.15.4",
+		},
+		{
+			name: "packageManager with hash",
+			pjs:  &PackageJSON{PackageManager: "pnpm@8.15.4+sha256.abc123"},
+			want: "8.15.4",
+		},
+		{
+			name: "engines over packageManager",
+			pjs:  &PackageJSON{Engines: packageEnginesJSON{PNPM: ">=9"}, PackageManager: "pnpm@8.15.4"},
+			want: "9.1.0",
+		},
+		{
+			name: "other packageManager",
+			pjs:  &PackageJSON{PackageManager: "yarn@4.1.0"},
+			want: "9.1.0",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stubNPMRegistry(t, registry, 0)
+
+			got, err := detectPNPMVersion(tc.pjs)
+			if err != nil {
+				t.Fatalf("detectPNPMVersion() got error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("detectPNPMVersion() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
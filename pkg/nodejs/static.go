@@ -0,0 +1,69 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodejs
+
+import (
+	_ "embed"
+	"path/filepath"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/buildpacks/libcnb"
+)
+
+var (
+	// staticOutputDirs are the directories that frameworks write static sites to, e.g. `out` for a
+	// Next.js static export, `dist` for Vite and Astro, and `build` for Create React App.
+	staticOutputDirs = []string{"out", "dist", "build"}
+
+	// staticServer is a file server for static sites with the Cache-Control header of each asset
+	// type, which has no dependencies besides Node.js.
+	//go:embed static/server.js
+	staticServer []byte
+)
+
+// StaticSiteDir returns the directory of the static site built in dir, relative to the application
+// root, if the app has no server to start: no start script, no main and no server.js. It returns ""
+// if the app has a server or no static site with an index.html was built.
+func StaticSiteDir(ctx *gcp.Context, dir string, pjs *PackageJSON) (string, error) {
+	if pjs != nil && (pjs.Scripts.Start != "" || pjs.Main != "") {
+		return "", nil
+	}
+	server, err := ctx.FileExists(ctx.ApplicationRoot(), dir, "server.js")
+	if err != nil || server {
+		return "", err
+	}
+	for _, out := range staticOutputDirs {
+		index, err := ctx.FileExists(ctx.ApplicationRoot(), dir, out, "index.html")
+		if err != nil {
+			return "", err
+		}
+		if index {
+			return filepath.Join(dir, out), nil
+		}
+	}
+	return "", nil
+}
+
+// AddStaticServer writes the static file server to the layer and sets the web process to serve
+// the static site in siteDir, relative to the application root.
+func AddStaticServer(ctx *gcp.Context, l *libcnb.Layer, siteDir string) error {
+	server := filepath.Join(l.Path, "server.js")
+	if err := ctx.WriteFile(server, staticServer, 0644); err != nil {
+		return err
+	}
+	ctx.Logf("Serving the static site in %s, the application has no server to start.", siteDir)
+	ctx.AddWebProcess([]string{"node", server, filepath.Join(ctx.ApplicationRoot(), siteDir)})
+	return nil
+}
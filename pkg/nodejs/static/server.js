@@ -0,0 +1,130 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Serves the static site in the directory given as argument on $PORT.
+// Usage: node server.js <site directory>
+
+'use strict';
+
+const fs = require('fs');
+const http = require('http');
+const path = require('path');
+
+const root = path.resolve(process.argv[2] || '.');
+const port = parseInt(process.env.PORT || '8080', 10);
+
+const contentTypes = {
+  '.html': 'text/html; charset=utf-8',
+  '.css': 'text/css; charset=utf-8',
+  '.js': 'text/javascript; charset=utf-8',
+  '.mjs': 'text/javascript; charset=utf-8',
+  '.json': 'application/json; charset=utf-8',
+  '.map': 'application/json; charset=utf-8',
+  '.txt': 'text/plain; charset=utf-8',
+  '.xml': 'application/xml; charset=utf-8',
+  '.webmanifest': 'application/manifest+json',
+  '.svg': 'image/svg+xml',
+  '.png': 'image/png',
+  '.jpg': 'image/jpeg',
+  '.jpeg': 'image/jpeg',
+  '.gif': 'image/gif',
+  '.webp': 'image/webp',
+  '.avif': 'image/avif',
+  '.ico': 'image/x-icon',
+  '.woff': 'font/woff',
+  '.woff2': 'font/woff2',
+  '.ttf': 'font/ttf',
+  '.otf': 'font/otf',
+  '.wasm': 'application/wasm',
+};
+
+// Fingerprinted assets change name when their content changes, so they can be cached forever:
+// the build output of Next.js, Vite, Astro and Create React App, and files with a content hash,
+// e.g. main.3f9a1c2b.js.
+const fingerprinted = /^\/(_next\/static|assets|_astro|static\/(js|css|media))\/|\.[0-9a-f]{8,}\.[a-z0-9]+$/;
+
+// cacheControl returns the Cache-Control header of the file at the URL path: HTML is revalidated
+// on each request, fingerprinted assets are immutable and other assets are cached for an hour.
+function cacheControl(urlPath) {
+  if (path.extname(urlPath) === '.html') {
+    return 'no-cache';
+  }
+  if (fingerprinted.test(urlPath)) {
+    return 'public, max-age=31536000, immutable';
+  }
+  return 'public, max-age=3600';
+}
+
+// resolve returns the file that serves the URL path, trying the index.html of directories and
+// the .html file of extensionless paths, or null if there is none.
+function resolve(urlPath) {
+  const file = path.join(root, urlPath);
+  if (file !== root && !file.startsWith(root + path.sep)) {
+    return null;
+  }
+  for (const candidate of [file, path.join(file, 'index.html'), file + '.html']) {
+    try {
+      if (fs.statSync(candidate).isFile()) {
+        return candidate;
+      }
+    } catch (e) {
+      // Try the next candidate.
+    }
+  }
+  return null;
+}
+
+function send(req, res, status, file) {
+  const rel = '/' + path.relative(root, file).split(path.sep).join('/');
+  res.writeHead(status, {
+    'Content-Type': contentTypes[path.extname(file)] || 'application/octet-stream',
+    'Cache-Control': status === 200 ? cacheControl(rel) : 'no-cache',
+    'Content-Length': fs.statSync(file).size,
+  });
+  if (req.method === 'HEAD') {
+    res.end();
+    return;
+  }
+  fs.createReadStream(file).pipe(res);
+}
+
+http.createServer((req, res) => {
+  if (req.method !== 'GET' && req.method !== 'HEAD') {
+    res.writeHead(405, {'Allow': 'GET, HEAD'});
+    res.end();
+    return;
+  }
+  let urlPath;
+  try {
+    urlPath = decodeURIComponent(new URL(req.url, 'http://localhost').pathname);
+  } catch (e) {
+    res.writeHead(400);
+    res.end();
+    return;
+  }
+  const file = resolve(urlPath);
+  if (file) {
+    send(req, res, 200, file);
+    return;
+  }
+  const notFound = resolve('/404.html');
+  if (notFound) {
+    send(req, res, 404, notFound);
+    return;
+  }
+  res.writeHead(404, {'Content-Type': 'text/plain; charset=utf-8'});
+  res.end('Not Found');
+}).listen(port, () => {
+  console.log(`Serving ${root} on port ${port}`);
+});
@@ -0,0 +1,218 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodejs
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/buildpacks/libcnb"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStaticSiteDir(t *testing.T) {
+	testCases := []struct {
+		name  string
+		dir   string
+		pjs   *PackageJSON
+		files []string
+		want  string
+	}{
+		{
+			name:  "vite",
+			files: []string{"dist/index.html"},
+			want:  "dist",
+		},
+		{
+			name:  "next.js static export",
+			pjs:   &PackageJSON{Scripts: packageScriptsJSON{Build: "next build"}},
+			files: []string{"out/index.html", "build/index.html"},
+			want:  "out",
+		},
+		{
+			name:  "workspace",
+			dir:   "apps/web",
+			files: []string{"apps/web/build/index.html", "dist/index.html"},
+			want:  "apps/web/build",
+		},
+		{
+			name:  "start script",
+			pjs:   &PackageJSON{Scripts: packageScriptsJSON{Start: "node index.js"}},
+			files: []string{"dist/index.html"},
+		},
+		{
+			name:  "main",
+			pjs:   &PackageJSON{Main: "index.js"},
+			files: []string{"dist/index.html"},
+		},
+		{
+			name:  "server.js",
+			files: []string{"server.js", "dist/index.html"},
+		},
+		{
+			name:  "no index.html",
+			files: []string{"dist/main.js"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, tc.files)
+			ctx := gcp.NewContext(gcp.WithApplicationRoot(root))
+
+			got, err := StaticSiteDir(ctx, tc.dir, tc.pjs)
+			if err != nil {
+				t.Fatalf("StaticSiteDir(%q) got error: %v", tc.dir, err)
+			}
+			if got != tc.want {
+				t.Errorf("StaticSiteDir(%q) = %q, want %q", tc.dir, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAddStaticServer(t *testing.T) {
+	root := t.TempDir()
+	l := &libcnb.Layer{Name: "static_server", Path: t.TempDir()}
+	ctx := gcp.NewContext(gcp.WithApplicationRoot(root))
+
+	if err := AddStaticServer(ctx, l, "dist"); err != nil {
+		t.Fatalf("AddStaticServer() got error: %v", err)
+	}
+
+	server := filepath.Join(l.Path, "server.js")
+	if _, err := os.Stat(server); err != nil {
+		t.Errorf("AddStaticServer() did not write the server: %v", err)
+	}
+	want := []libcnb.Process{{
+		Type:    gcp.WebProcess,
+		Command: "node",
+		Arguments: []string{
+			server,
+			filepath.Join(root, "dist"),
+		},
+		Direct:  true,
+		Default: true,
+	}}
+	if diff := cmp.Diff(want, ctx.Processes()); diff != "" {
+		t.Errorf("AddStaticServer() processes mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestStaticServer(t *testing.T) {
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Skip("node is not installed")
+	}
+	site := t.TempDir()
+	writeFiles(t, site, []string{
+		"index.html",
+		"about.html",
+		"blog/index.html",
+		"favicon.ico",
+		"hero-background.png",
+		"main.3f9a1c2b.js",
+		"assets/index-BxK3v9aQ.css",
+		"_next/static/chunks/app.js",
+	})
+	server := filepath.Join(t.TempDir(), "server.js")
+	if err := os.WriteFile(server, staticServer, 0644); err != nil {
+		t.Fatalf("writing the server: %v", err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("finding a free port: %v", err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+	cmd := exec.Command("node", server, site)
+	cmd.Env = append(os.Environ(), fmt.Sprintf("PORT=%d", port))
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("starting the server: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	url := fmt.Sprintf("http://127.0.0.1:%d", port)
+	for deadline := time.Now().Add(10 * time.Second); ; time.Sleep(50 * time.Millisecond) {
+		resp, err := http.Get(url)
+		if err == nil {
+			resp.Body.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("the server did not start: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		path             string
+		wantStatus       int
+		wantType         string
+		wantCacheControl string
+	}{
+		{path: "/", wantStatus: 200, wantType: "text/html; charset=utf-8", wantCacheControl: "no-cache"},
+		{path: "/about", wantStatus: 200, wantType: "text/html; charset=utf-8", wantCacheControl: "no-cache"},
+		{path: "/blog/", wantStatus: 200, wantType: "text/html; charset=utf-8", wantCacheControl: "no-cache"},
+		{path: "/favicon.ico", wantStatus: 200, wantType: "image/x-icon", wantCacheControl: "public, max-age=3600"},
+		{path: "/hero-background.png", wantStatus: 200, wantType: "image/png", wantCacheControl: "public, max-age=3600"},
+		{path: "/main.3f9a1c2b.js", wantStatus: 200, wantType: "text/javascript; charset=utf-8", wantCacheControl: "public, max-age=31536000, immutable"},
+		{path: "/assets/index-BxK3v9aQ.css", wantStatus: 200, wantType: "text/css; charset=utf-8", wantCacheControl: "public, max-age=31536000, immutable"},
+		{path: "/_next/static/chunks/app.js", wantStatus: 200, wantType: "text/javascript; charset=utf-8", wantCacheControl: "public, max-age=31536000, immutable"},
+		{path: "/missing.js", wantStatus: 404},
+		{path: "/%2e%2e/server.js", wantStatus: 404},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			resp, err := http.Get(url + tc.path)
+			if err != nil {
+				t.Fatalf("GET %s got error: %v", tc.path, err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tc.wantStatus {
+				t.Errorf("GET %s got status %d, want %d", tc.path, resp.StatusCode, tc.wantStatus)
+			}
+			if tc.wantStatus != 200 {
+				return
+			}
+			if got := resp.Header.Get("Content-Type"); got != tc.wantType {
+				t.Errorf("GET %s got Content-Type %q, want %q", tc.path, got, tc.wantType)
+			}
+			if got := resp.Header.Get("Cache-Control"); got != tc.wantCacheControl {
+				t.Errorf("GET %s got Cache-Control %q, want %q", tc.path, got, tc.wantCacheControl)
+			}
+		})
+	}
+}
+
+func writeFiles(t *testing.T, dir string, files []string) {
+	t.Helper()
+	for _, f := range files {
+		path := filepath.Join(dir, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("creating directory for %q: %v", f, err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("writing %q: %v", f, err)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodejs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+// TurboJSON is the configuration file of a Turborepo monorepo.
+const TurboJSON = "turbo.json"
+
+// Workspace is an app of a monorepo, e.g. apps/web. The dependencies of all the workspaces are
+// installed at the application root, then the selected workspace is built and started.
+type Workspace struct {
+	// Dir is the directory of the workspace, relative to the application root.
+	Dir string
+	// PackageJSON is the package.json of the workspace.
+	PackageJSON *PackageJSON
+	// Turbo is true if the monorepo is built with Turborepo, which builds the workspaces that the
+	// workspace depends on before it.
+	Turbo bool
+}
+
+// SelectedWorkspace returns the workspace selected with GOOGLE_NODEJS_WORKSPACE, or nil if no
+// workspace is selected. The monorepo must declare its workspaces in rootPJS, the package.json at
+// the application root, or in a pnpm-workspace.yaml.
+func SelectedWorkspace(ctx *gcp.Context, rootPJS *PackageJSON) (*Workspace, error) {
+	dir := os.Getenv(env.NodeJSWorkspace)
+	if dir == "" {
+		return nil, nil
+	}
+	dir = filepath.Clean(dir)
+	if filepath.IsAbs(dir) || dir == "." || dir == ".." || strings.HasPrefix(dir, ".."+string(filepath.Separator)) {
+		return nil, gcp.UserErrorf("%s must be a subdirectory of the application, got %q", env.NodeJSWorkspace, dir)
+	}
+	pnpmWorkspace, err := ctx.FileExists(ctx.ApplicationRoot(), PNPMWorkspace)
+	if err != nil {
+		return nil, err
+	}
+	if !hasWorkspaces(rootPJS) && !pnpmWorkspace {
+		return nil, gcp.UserErrorf("%s is set to %q, but neither the package.json at the root of the application nor a %s declares workspaces", env.NodeJSWorkspace, dir, PNPMWorkspace)
+	}
+	pjs, err := ReadPackageJSONIfExists(filepath.Join(ctx.ApplicationRoot(), dir))
+	if err != nil {
+		return nil, err
+	}
+	if pjs == nil {
+		return nil, gcp.UserErrorf("workspace %q selected with %s does not contain a package.json", dir, env.NodeJSWorkspace)
+	}
+	if pjs.Name == "" {
+		return nil, gcp.UserErrorf("the package.json of workspace %q must have a name", dir)
+	}
+	turbo, err := usesTurbo(ctx, rootPJS)
+	if err != nil {
+		return nil, err
+	}
+	return &Workspace{Dir: dir, PackageJSON: pjs, Turbo: turbo}, nil
+}
+
+// usesTurbo returns true if the monorepo has a turbo.json and depends on turbo at its root.
+func usesTurbo(ctx *gcp.Context, rootPJS *PackageJSON) (bool, error) {
+	turboJSON, err := ctx.FileExists(ctx.ApplicationRoot(), TurboJSON)
+	if err != nil || !turboJSON {
+		return false, err
+	}
+	if rootPJS != nil {
+		if _, ok := rootPJS.DevDependencies["turbo"]; ok {
+			return true, nil
+		}
+		if _, ok := rootPJS.Dependencies["turbo"]; ok {
+			return true, nil
+		}
+	}
+	ctx.Warnf("Building the workspace without Turborepo because the package.json at the root of the application does not depend on turbo.")
+	return false, nil
+}
+
+// hasWorkspaces returns true if the package.json declares workspaces, either as a list of
+// directories or as the packages of a Yarn workspaces object.
+func hasWorkspaces(p *PackageJSON) bool {
+	return p != nil && len(p.Workspaces) > 0 && string(p.Workspaces) != "null"
+}
+
+// BuildScript returns the script that builds the workspace, "gcp-build" or else "build", or "" if
+// the workspace is not built. It is safe to call on a nil workspace.
+func (w *Workspace) BuildScript() string {
+	if w == nil {
+		return ""
+	}
+	if HasGCPBuild(w.PackageJSON) {
+		return "gcp-build"
+	}
+	if w.PackageJSON.Scripts.Build != "" {
+		return "build"
+	}
+	return ""
+}
+
+// NPMRunCommand returns the npm command that runs the script in the workspace.
+func (w *Workspace) NPMRunCommand(script string) []string {
+	return []string{"npm", "run", script, "--workspace=" + w.Dir}
+}
+
+// YarnRunCommand returns the yarn command that runs the script in the workspace.
+func (w *Workspace) YarnRunCommand(script string) []string {
+	return []string{"yarn", "workspace", w.PackageJSON.Name, "run", script}
+}
+
+// PNPMRunCommand returns the pnpm command that runs the script in the workspace.
+func (w *Workspace) PNPMRunCommand(script string) []string {
+	return []string{"pnpm", "--filter", w.PackageJSON.Name, "run", script}
+}
+
+// NPMBuildCommand returns the npm command that builds the workspace with the script.
+func (w *Workspace) NPMBuildCommand(script string) []string {
+	if w.Turbo {
+		return w.TurboBuildCommand([]string{"npx", "--no-install"}, script)
+	}
+	return w.NPMRunCommand(script)
+}
+
+// YarnBuildCommand returns the yarn command that builds the workspace with the script.
+func (w *Workspace) YarnBuildCommand(script string) []string {
+	if w.Turbo {
+		return w.TurboBuildCommand([]string{"yarn", "run"}, script)
+	}
+	return w.YarnRunCommand(script)
+}
+
+// PNPMBuildCommand returns the pnpm command that builds the workspace with the script.
+func (w *Workspace) PNPMBuildCommand(script string) []string {
+	if w.Turbo {
+		return w.TurboBuildCommand([]string{"pnpm", "exec"}, script)
+	}
+	return w.PNPMRunCommand(script)
+}
+
+// TurboBuildCommand returns the command that runs the script with Turborepo in the workspace and,
+// as configured in turbo.json, in the workspaces it depends on. exec is the command prefix that
+// runs the turbo binary, e.g. "npx --no-install". The command runs at the application root.
+func (w *Workspace) TurboBuildCommand(exec []string, script string) []string {
+	return append(append([]string{}, exec...), "turbo", "run", script, "--filter="+w.PackageJSON.Name)
+}
@@ -0,0 +1,251 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodejs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSelectedWorkspace(t *testing.T) {
+	testCases := []struct {
+		name      string
+		workspace string
+		files     map[string]string
+		wantDir   string
+		wantName  string
+		wantTurbo bool
+		wantErr   bool
+	}{
+		{
+			name: "not set",
+			files: map[string]string{
+				"package.json": `{"workspaces": ["apps/*"]}`,
+			},
+		},
+		{
+			name:      "npm workspaces",
+			workspace: "apps/web/",
+			files: map[string]string{
+				"package.json":          `{"workspaces": ["apps/*"]}`,
+				"apps/web/package.json": `{"name": "web"}`,
+			},
+			wantDir:  "apps/web",
+			wantName: "web",
+		},
+		{
+			name:      "yarn workspaces object",
+			workspace: "apps/web",
+			files: map[string]string{
+				"package.json":          `{"workspaces": {"packages": ["apps/*"]}}`,
+				"apps/web/package.json": `{"name": "@acme/web"}`,
+			},
+			wantDir:  "apps/web",
+			wantName: "@acme/web",
+		},
+		{
+			name:      "pnpm workspaces",
+			workspace: "apps/web",
+			files: map[string]string{
+				"package.json":          `{}`,
+				"pnpm-workspace.yaml":   "packages:\n  - 'apps/*'\n",
+				"apps/web/package.json": `{"name": "web"}`,
+			},
+			wantDir:  "apps/web",
+			wantName: "web",
+		},
+		{
+			name:      "turborepo",
+			workspace: "apps/web",
+			files: map[string]string{
+				"package.json":          `{"workspaces": ["apps/*", "packages/*"], "devDependencies": {"turbo": "^2.0.0"}}`,
+				"turbo.json":            `{"tasks": {"build": {"dependsOn": ["^build"]}}}`,
+				"apps/web/package.json": `{"name": "web"}`,
+			},
+			wantDir:   "apps/web",
+			wantName:  "web",
+			wantTurbo: true,
+		},
+		{
+			name:      "turbo.json without turbo",
+			workspace: "apps/web",
+			files: map[string]string{
+				"package.json":          `{"workspaces": ["apps/*"]}`,
+				"turbo.json":            `{}`,
+				"apps/web/package.json": `{"name": "web"}`,
+			},
+			wantDir:  "apps/web",
+			wantName: "web",
+		},
+		{
+			name:      "root without workspaces",
+			workspace: "apps/web",
+			files: map[string]string{
+				"package.json":          `{}`,
+				"apps/web/package.json": `{"name": "web"}`,
+			},
+			wantErr: true,
+		},
+		{
+			name:      "missing package.json",
+			workspace: "apps/api",
+			files: map[string]string{
+				"package.json": `{"workspaces": ["apps/*"]}`,
+			},
+			wantErr: true,
+		},
+		{
+			name:      "workspace without name",
+			workspace: "apps/web",
+			files: map[string]string{
+				"package.json":          `{"workspaces": ["apps/*"]}`,
+				"apps/web/package.json": `{}`,
+			},
+			wantErr: true,
+		},
+		{
+			name:      "outside of the application",
+			workspace: "apps/../../web",
+			files: map[string]string{
+				"package.json": `{"workspaces": ["apps/*"]}`,
+			},
+			wantErr: true,
+		},
+		{
+			name:      "application root",
+			workspace: "./",
+			files: map[string]string{
+				"package.json": `{"workspaces": ["apps/*"]}`,
+			},
+			wantErr: true,
+		},
+		{
+			name:      "absolute",
+			workspace: "/apps/web",
+			files: map[string]string{
+				"package.json": `{"workspaces": ["apps/*"]}`,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(env.NodeJSWorkspace, tc.workspace)
+			root := t.TempDir()
+			for f, content := range tc.files {
+				path := filepath.Join(root, f)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("creating directory for %q: %v", f, err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatalf("writing %q: %v", f, err)
+				}
+			}
+			ctx := gcp.NewContext(gcp.WithApplicationRoot(root))
+			pjs, err := ReadPackageJSONIfExists(root)
+			if err != nil {
+				t.Fatalf("ReadPackageJSONIfExists() got error: %v", err)
+			}
+
+			got, err := SelectedWorkspace(ctx, pjs)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("SelectedWorkspace() got error %v, want error %t", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if tc.wantDir == "" {
+				if got != nil {
+					t.Errorf("SelectedWorkspace() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("SelectedWorkspace() = nil, want workspace %q", tc.wantDir)
+			}
+			if got.Dir != tc.wantDir || got.PackageJSON.Name != tc.wantName || got.Turbo != tc.wantTurbo {
+				t.Errorf("SelectedWorkspace() = {Dir: %q, Name: %q, Turbo: %t}, want {Dir: %q, Name: %q, Turbo: %t}", got.Dir, got.PackageJSON.Name, got.Turbo, tc.wantDir, tc.wantName, tc.wantTurbo)
+			}
+		})
+	}
+}
+
+func TestWorkspaceCommands(t *testing.T) {
+	testCases := []struct {
+		name      string
+		workspace *Workspace
+		wantBuild string
+		wantNPM   []string
+		wantYarn  []string
+		wantPNPM  []string
+	}{
+		{
+			name: "no workspace",
+		},
+		{
+			name:      "gcp-build",
+			workspace: &Workspace{Dir: "apps/web", PackageJSON: &PackageJSON{Name: "web", Scripts: packageScriptsJSON{Build: "next build", GCPBuild: "next build --profile"}}},
+			wantBuild: "gcp-build",
+			wantNPM:   []string{"npm", "run", "gcp-build", "--workspace=apps/web"},
+			wantYarn:  []string{"yarn", "workspace", "web", "run", "gcp-build"},
+			wantPNPM:  []string{"pnpm", "--filter", "web", "run", "gcp-build"},
+		},
+		{
+			name:      "build",
+			workspace: &Workspace{Dir: "apps/web", PackageJSON: &PackageJSON{Name: "@acme/web", Scripts: packageScriptsJSON{Build: "vite build"}}},
+			wantBuild: "build",
+			wantNPM:   []string{"npm", "run", "build", "--workspace=apps/web"},
+			wantYarn:  []string{"yarn", "workspace", "@acme/web", "run", "build"},
+			wantPNPM:  []string{"pnpm", "--filter", "@acme/web", "run", "build"},
+		},
+		{
+			name:      "turborepo",
+			workspace: &Workspace{Dir: "apps/web", PackageJSON: &PackageJSON{Name: "web", Scripts: packageScriptsJSON{Build: "next build"}}, Turbo: true},
+			wantBuild: "build",
+			wantNPM:   []string{"npx", "--no-install", "turbo", "run", "build", "--filter=web"},
+			wantYarn:  []string{"yarn", "run", "turbo", "run", "build", "--filter=web"},
+			wantPNPM:  []string{"pnpm", "exec", "turbo", "run", "build", "--filter=web"},
+		},
+		{
+			name:      "not built",
+			workspace: &Workspace{Dir: "apps/api", PackageJSON: &PackageJSON{Name: "api"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			script := tc.workspace.BuildScript()
+			if script != tc.wantBuild {
+				t.Errorf("BuildScript() = %q, want %q", script, tc.wantBuild)
+			}
+			if script == "" {
+				return
+			}
+			if diff := cmp.Diff(tc.wantNPM, tc.workspace.NPMBuildCommand(script)); diff != "" {
+				t.Errorf("NPMBuildCommand(%q) mismatch (-want +got):\n%s", script, diff)
+			}
+			if diff := cmp.Diff(tc.wantYarn, tc.workspace.YarnBuildCommand(script)); diff != "" {
+				t.Errorf("YarnBuildCommand(%q) mismatch (-want +got):\n%s", script, diff)
+			}
+			if diff := cmp.Diff(tc.wantPNPM, tc.workspace.PNPMBuildCommand(script)); diff != "" {
+				t.Errorf("PNPMBuildCommand(%q) mismatch (-want +got):\n%s", script, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package php
+
+import (
+	"sort"
+	"strings"
+)
+
+// extensionPrefix is the prefix of the platform packages of composer.json that require a PHP
+// extension, e.g. ext-intl.
+const extensionPrefix = "ext-"
+
+// extensionAliases maps the names composer and php -m use for some extensions to the name of
+// their shared library.
+var extensionAliases = map[string]string{
+	"zend-opcache": "opcache",
+	"zend_opcache": "opcache",
+	"zend opcache": "opcache",
+}
+
+// RequiredExtensions returns the sorted names of the PHP extensions required with ext-* entries in
+// the require section of composer.json, e.g. intl for ext-intl.
+func (c *ComposerJSON) RequiredExtensions() []string {
+	var exts []string
+	for name := range c.Require {
+		if n := strings.ToLower(name); strings.HasPrefix(n, extensionPrefix) {
+			exts = append(exts, NormalizeExtension(strings.TrimPrefix(n, extensionPrefix)))
+		}
+	}
+	sort.Strings(exts)
+	return exts
+}
+
+// NormalizeExtension returns the name of the shared library of a PHP extension from its name in
+// composer.json or in the output of php -m, e.g. opcache for Zend OPcache.
+func NormalizeExtension(name string) string {
+	n := strings.ToLower(strings.TrimSpace(name))
+	if alias, ok := extensionAliases[n]; ok {
+		return alias
+	}
+	return n
+}
@@ -0,0 +1,75 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package php
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRequiredExtensions(t *testing.T) {
+	testCases := []struct {
+		name    string
+		require map[string]string
+		want    []string
+	}{
+		{
+			name:    "no extensions",
+			require: map[string]string{"php": "^8.1", "laravel/framework": "^10.0"},
+		},
+		{
+			name: "extensions",
+			require: map[string]string{
+				"php":           "^8.1",
+				"ext-redis":     "*",
+				"ext-pdo_pgsql": "*",
+				"ext-GD":        "*",
+			},
+			want: []string{"gd", "pdo_pgsql", "redis"},
+		},
+		{
+			name:    "opcache",
+			require: map[string]string{"ext-zend-opcache": "*"},
+			want:    []string{"opcache"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &ComposerJSON{Require: tc.require}
+			if got := c.RequiredExtensions(); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("RequiredExtensions() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeExtension(t *testing.T) {
+	testCases := []struct {
+		name string
+		want string
+	}{
+		{name: "intl", want: "intl"},
+		{name: "PDO", want: "pdo"},
+		{name: "Zend OPcache", want: "opcache"},
+		{name: "zend-opcache", want: "opcache"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := NormalizeExtension(tc.name); got != tc.want {
+				t.Errorf("NormalizeExtension(%q) = %q, want %q", tc.name, got, tc.want)
+			}
+		})
+	}
+}
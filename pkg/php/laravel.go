@@ -0,0 +1,50 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package php
+
+import (
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+const (
+	// laravelFramework is the composer package of the Laravel framework.
+	laravelFramework = "laravel/framework"
+	// Artisan is the command line interface of Laravel applications.
+	Artisan = "artisan"
+	// LaravelPublicDir is the document root of Laravel applications.
+	LaravelPublicDir = "public"
+)
+
+// IsLaravel returns true if the application is a Laravel application, i.e. it has the artisan
+// script and requires the Laravel framework in composer.json.
+func IsLaravel(ctx *gcp.Context) (bool, error) {
+	artisanExists, err := ctx.FileExists(ctx.ApplicationRoot(), Artisan)
+	if err != nil {
+		return false, err
+	}
+	composerJSONExists, err := ctx.FileExists(ctx.ApplicationRoot(), composerJSON)
+	if err != nil {
+		return false, err
+	}
+	if !artisanExists || !composerJSONExists {
+		return false, nil
+	}
+	cjs, err := ReadComposerJSON(ctx.ApplicationRoot())
+	if err != nil {
+		return false, err
+	}
+	_, ok := cjs.Require[laravelFramework]
+	return ok, nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package php
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+func TestIsLaravel(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		want  bool
+	}{
+		{
+			name: "laravel",
+			files: map[string]string{
+				"artisan":       "#!/usr/bin/env php",
+				"composer.json": `{"require": {"php": "^8.1", "laravel/framework": "^10.10"}}`,
+			},
+			want: true,
+		},
+		{
+			name: "no artisan",
+			files: map[string]string{
+				"composer.json": `{"require": {"laravel/framework": "^10.10"}}`,
+			},
+		},
+		{
+			name: "artisan without the framework",
+			files: map[string]string{
+				"artisan":       "",
+				"composer.json": `{"require": {"symfony/console": "^6.0"}}`,
+			},
+		},
+		{
+			name: "no composer.json",
+			files: map[string]string{
+				"artisan": "",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			for name, content := range tc.files {
+				if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+					t.Fatalf("writing %s: %v", name, err)
+				}
+			}
+			got, err := IsLaravel(gcp.NewContext(gcp.WithApplicationRoot(root)))
+			if err != nil {
+				t.Fatalf("IsLaravel() got error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("IsLaravel() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
@@ -35,6 +35,8 @@ const (
 	composerJSON = "composer.json"
 	// composerLock is the name of the Composer lock file.
 	composerLock = "composer.lock"
+	// phpVersionFile is the name of the file that specifies the PHP version, as used by phpenv.
+	phpVersionFile = ".php-version"
 	// Vendor is the name of the Composer vendor directory.
 	Vendor = "vendor"
 
@@ -43,6 +45,9 @@ const (
 
 	composerVersionKey = "php"
 
+	// IniScanDirEnv is read by PHP as the list of directories of additional ini files.
+	IniScanDirEnv = "PHP_INI_SCAN_DIR"
+
 	// PHPIni is the content of the php.ini config file
 	PHPIni = `
 ; Copyright 2022 Google Inc.
@@ -142,15 +147,13 @@ func composerDependencyHash(ctx *gcp.Context) (string, error) {
 // composerInstall runs `composer install` with the given flags.
 func composerInstall(ctx *gcp.Context, flags []string) error {
 	cmd := append([]string{"composer", "install"}, flags...)
-	if _, err := ctx.Exec(cmd, gcp.WithUserAttribution); err != nil {
-		return err
-	}
-	return nil
+	return execComposer(ctx, cmd)
 }
 
 // ComposerInstall runs `composer install`, using the cache iff a lock file is present.
 // It creates a layer, so it returns the layer so that the caller may further modify it
-// if they desire.
+// if they desire. The scripts listed in GOOGLE_COMPOSER_SCRIPTS run after every install, including
+// the ones restored from the cache.
 func ComposerInstall(ctx *gcp.Context, cacheTag string) (*libcnb.Layer, error) {
 	var flags []string
 	if composerArgs := os.Getenv(env.ComposerArgsEnv); composerArgs != "" {
@@ -163,6 +166,10 @@ func ComposerInstall(ctx *gcp.Context, cacheTag string) (*libcnb.Layer, error) {
 		//   https://github.com/GoogleCloudPlatform/runtimes-common/commit/6c4970f609d80f9436ac58ae272cfcc6bcd57143
 		flags = []string{"--no-dev", "--no-progress", "--no-interaction", "--optimize-autoloader"}
 	}
+	scripts := composerScripts()
+	if len(scripts) > 0 {
+		flags = withNoScripts(flags)
+	}
 
 	if err := ctx.RemoveAll(Vendor); err != nil {
 		return nil, err
@@ -185,6 +192,11 @@ func ComposerInstall(ctx *gcp.Context, cacheTag string) (*libcnb.Layer, error) {
 		if err := composerInstall(ctx, flags); err != nil {
 			return nil, err
 		}
+		if len(scripts) > 0 {
+			if err := runComposerScripts(ctx, scripts); err != nil {
+				return nil, err
+			}
+		}
 		return l, nil
 	}
 
@@ -232,6 +244,13 @@ func ComposerInstall(ctx *gcp.Context, cacheTag string) (*libcnb.Layer, error) {
 		}
 	}
 
+	// The scripts run after the cache is updated, the cached vendor directory only contains the
+	// installed dependencies.
+	if len(scripts) > 0 {
+		if err := runComposerScripts(ctx, scripts); err != nil {
+			return nil, err
+		}
+	}
 	return l, nil
 }
 
@@ -240,13 +259,19 @@ func ComposerInstall(ctx *gcp.Context, cacheTag string) (*libcnb.Layer, error) {
 // "myorg/mypackage:^0.7". It does no caching.
 func ComposerRequire(ctx *gcp.Context, packages []string) error {
 	cmd := append([]string{"composer", "require", "--no-progress", "--no-interaction"}, packages...)
-	if _, err := ctx.Exec(cmd, gcp.WithUserAttribution); err != nil {
-		return err
-	}
-	return nil
+	return execComposer(ctx, cmd)
 }
 
-// ExtractVersion extracts the php version from the environment, composer.json.
+// ExtractVersion extracts the php version from the first of the following sources that specifies
+// one:
+//  1. The GOOGLE_RUNTIME_VERSION environment variable.
+//  2. The .php-version file.
+//  3. The platform php version of composer.lock, from the config.platform.php setting of
+//     composer.json, which is the version the dependencies were resolved for.
+//  4. The php requirement of composer.json.
+//  5. The php requirement of composer.lock, for applications without composer.json.
+//
+// It returns an empty version if none of the sources specify one.
 func ExtractVersion(ctx *gcp.Context) (string, error) {
 	// get the runtime version from env.RuntimeVersion
 	if v := os.Getenv(env.RuntimeVersion); v != "" {
@@ -254,6 +279,25 @@ func ExtractVersion(ctx *gcp.Context) (string, error) {
 		return v, nil
 	}
 
+	// get the runtime version from the .php-version file
+	v, err := phpVersionFileVersion(ctx)
+	if err != nil {
+		return "", err
+	}
+	if v != "" {
+		ctx.Logf("Using php version from %s: %s", phpVersionFile, v)
+		return v, nil
+	}
+
+	lock, err := readComposerLock(ctx)
+	if err != nil {
+		return "", err
+	}
+	if v := lock.PlatformOverrides[composerVersionKey]; v != "" {
+		ctx.Logf("Using php version from %s platform-overrides: %s", composerLock, v)
+		return v, nil
+	}
+
 	// get the runtime version from the composer.json file
 	composerFilePath := filepath.Join(ctx.ApplicationRoot(), composerJSON)
 	composerFileExists, err := ctx.FileExists(composerFilePath)
@@ -271,9 +315,81 @@ func ExtractVersion(ctx *gcp.Context) (string, error) {
 		}
 	}
 
+	if v := lock.Platform[composerVersionKey]; v != "" {
+		ctx.Logf("Using php version from %s platform: %s", composerLock, v)
+		return v, nil
+	}
 	return "", nil
 }
 
+// composerLockJSON represents the platform requirements of a composer.lock file.
+type composerLockJSON struct {
+	// Platform are the platform requirements of composer.json when the lock file was generated.
+	Platform map[string]string `json:"platform"`
+	// PlatformOverrides are the platform packages of the config.platform setting of composer.json.
+	PlatformOverrides map[string]string `json:"platform-overrides"`
+}
+
+// readComposerLock returns the platform requirements of composer.lock, which are empty if the
+// application does not have a lock file.
+func readComposerLock(ctx *gcp.Context) (*composerLockJSON, error) {
+	lock := &composerLockJSON{}
+	path := filepath.Join(ctx.ApplicationRoot(), composerLock)
+	exists, err := ctx.FileExists(path)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return lock, nil
+	}
+	content, err := ctx.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	// The platform requirements of lock files are empty arrays when there are none.
+	var raw struct {
+		Platform          json.RawMessage `json:"platform"`
+		PlatformOverrides json.RawMessage `json:"platform-overrides"`
+	}
+	if err := json.Unmarshal(content, &raw); err != nil {
+		return nil, gcp.UserErrorf("unmarshalling %s: %v", composerLock, err)
+	}
+	for _, f := range []struct {
+		raw json.RawMessage
+		dst *map[string]string
+	}{{raw.Platform, &lock.Platform}, {raw.PlatformOverrides, &lock.PlatformOverrides}} {
+		if len(f.raw) == 0 || f.raw[0] != '{' {
+			continue
+		}
+		if err := json.Unmarshal(f.raw, f.dst); err != nil {
+			return nil, gcp.UserErrorf("unmarshalling the platform requirements of %s: %v", composerLock, err)
+		}
+	}
+	return lock, nil
+}
+
+// phpVersionFileVersion returns the version in the .php-version file, e.g. 8.2 or 8.2.3, or an
+// empty string if the application does not have the file.
+func phpVersionFileVersion(ctx *gcp.Context) (string, error) {
+	path := filepath.Join(ctx.ApplicationRoot(), phpVersionFile)
+	exists, err := ctx.FileExists(path)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", nil
+	}
+	content, err := ctx.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	v := strings.TrimPrefix(strings.TrimSpace(strings.SplitN(string(content), "\n", 2)[0]), "php-")
+	if v == "" {
+		return "", gcp.UserErrorf("%s exists but does not specify a version", phpVersionFile)
+	}
+	return v, nil
+}
+
 // composerFileVersion extracts the version number from composer.json. returns an error in
 // case the version cannot be read.
 func composerFileVersion(ctx *gcp.Context) (string, error) {
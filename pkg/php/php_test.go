@@ -74,6 +74,8 @@ func TestExtractVersion(t *testing.T) {
 		runtimeEnv   string
 		want         string
 		composerJSON string
+		composerLock string
+		phpVersion   string
 		wantErr      bool
 	}{
 		{
@@ -152,6 +154,52 @@ func TestExtractVersion(t *testing.T) {
 `),
 			want: ">= 7.1.3, < 7.4.4",
 		},
+		{
+			name:         "from .php-version",
+			phpVersion:   "8.2.3\n",
+			composerJSON: `{"require": {"php": "^8.1"}}`,
+			want:         "8.2.3",
+		},
+		{
+			name:       "environment takes precedence over .php-version",
+			runtimeEnv: "8.1.0",
+			phpVersion: "8.2.3",
+			want:       "8.1.0",
+		},
+		{
+			name:       "empty .php-version",
+			phpVersion: "\n",
+			wantErr:    true,
+		},
+		{
+			name:         "composer.lock platform-overrides takes precedence over composer.json",
+			composerJSON: `{"require": {"php": "^8.1"}, "config": {"platform": {"php": "8.1.2"}}}`,
+			composerLock: `{"platform": {"php": "^8.1"}, "platform-overrides": {"php": "8.1.2"}}`,
+			want:         "8.1.2",
+		},
+		{
+			name:         "composer.json takes precedence over composer.lock platform",
+			composerJSON: `{"require": {"php": "^8.2"}}`,
+			composerLock: `{"platform": {"php": "^8.1"}, "platform-dev": []}`,
+			want:         "^8.2",
+		},
+		{
+			name:         "composer.lock platform without composer.json version",
+			composerJSON: `{"require": {"myorg/mypackage": "^0.7"}}`,
+			composerLock: `{"platform": {"php": ">=8.0"}}`,
+			want:         ">=8.0",
+		},
+		{
+			name:         "composer.lock without platform requirements",
+			composerJSON: `{"require": {"myorg/mypackage": "^0.7"}}`,
+			composerLock: `{"platform": [], "platform-dev": []}`,
+			want:         "",
+		},
+		{
+			name:         "invalid composer.lock",
+			composerLock: `{"platform": `,
+			wantErr:      true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -171,6 +219,16 @@ func TestExtractVersion(t *testing.T) {
 					t.Fatalf("Failed to write composer.json: %v", err)
 				}
 			}
+			if len(tc.composerLock) > 0 {
+				if err := ioutil.WriteFile(filepath.Join(path, composerLock), []byte(tc.composerLock), 0644); err != nil {
+					t.Fatalf("Failed to write composer.lock: %v", err)
+				}
+			}
+			if len(tc.phpVersion) > 0 {
+				if err := ioutil.WriteFile(filepath.Join(path, phpVersionFile), []byte(tc.phpVersion), 0644); err != nil {
+					t.Fatalf("Failed to write .php-version: %v", err)
+				}
+			}
 
 			ctx := gcp.NewContext(gcp.WithApplicationRoot(path))
 			got, err := ExtractVersion(ctx)
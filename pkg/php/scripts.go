@@ -0,0 +1,116 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package php
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+// noScriptsFlag prevents composer install from running the scripts of composer.json.
+const noScriptsFlag = "--no-scripts"
+
+// secretEnvMarkers are the parts of the names of environment variables that likely hold secrets,
+// which are removed from the environment of composer scripts.
+var secretEnvMarkers = []string{"AUTH", "CREDENTIAL", "PASSWORD", "SECRET", "TOKEN"}
+
+// composerScripts returns the composer.json scripts requested with GOOGLE_COMPOSER_SCRIPTS,
+// separated by commas or whitespace.
+func composerScripts() []string {
+	return strings.FieldsFunc(os.Getenv(env.ComposerScripts), func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+}
+
+// withNoScripts returns the composer install flags with --no-scripts, so that the scripts of
+// composer.json run the same way whether the dependencies are installed or restored from the cache.
+func withNoScripts(flags []string) []string {
+	for _, f := range flags {
+		if f == noScriptsFlag {
+			return flags
+		}
+	}
+	return append(flags, noScriptsFlag)
+}
+
+// runComposerScripts runs the given composer.json scripts, in order, with the variables that
+// likely hold secrets removed from their environment. The scripts run the code of the application
+// and of its dependencies, e.g. php artisan package:discover for Laravel applications.
+func runComposerScripts(ctx *gcp.Context, scripts []string) error {
+	defined, err := definedScripts(ctx.ApplicationRoot())
+	if err != nil {
+		return err
+	}
+	for _, s := range scripts {
+		if !defined[s] {
+			return gcp.UserErrorf("script %q requested with %s is not defined in %s", s, env.ComposerScripts, composerJSON)
+		}
+	}
+	scrub := []string{"env"}
+	for _, name := range secretEnvNames(os.Environ()) {
+		scrub = append(scrub, "-u", name)
+	}
+	for _, s := range scripts {
+		ctx.Logf("Running composer script %s.", s)
+		cmd := append(append([]string{}, scrub...), "composer", "run-script", "--timeout=600", "--no-dev", "--no-interaction", s)
+		if _, err := ctx.Exec(cmd, gcp.WithUserAttribution); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// definedScripts returns the names of the scripts defined in composer.json in the given dir.
+func definedScripts(dir string) (map[string]bool, error) {
+	raw, err := os.ReadFile(filepath.Join(dir, composerJSON))
+	if err != nil {
+		return nil, gcp.InternalErrorf("reading %s: %v", composerJSON, err)
+	}
+	var cjs struct {
+		Scripts map[string]json.RawMessage `json:"scripts"`
+	}
+	if err := json.Unmarshal(raw, &cjs); err != nil {
+		return nil, gcp.UserErrorf("unmarshalling %s: %v", composerJSON, err)
+	}
+	defined := map[string]bool{}
+	for name := range cjs.Scripts {
+		defined[name] = true
+	}
+	return defined, nil
+}
+
+// secretEnvNames returns the sorted names of the variables of the environment that likely hold
+// secrets.
+func secretEnvNames(environ []string) []string {
+	var names []string
+	for _, kv := range environ {
+		name := strings.SplitN(kv, "=", 2)[0]
+		upper := strings.ToUpper(name)
+		for _, m := range secretEnvMarkers {
+			if strings.Contains(upper, m) {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,112 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package php
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+)
+
+func TestComposerScripts(t *testing.T) {
+	testCases := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{
+			name: "unset",
+			want: []string{},
+		},
+		{
+			name:  "single script",
+			value: "post-install-cmd",
+			want:  []string{"post-install-cmd"},
+		},
+		{
+			name:  "comma and space separated",
+			value: "post-autoload-dump, optimize  cache",
+			want:  []string{"post-autoload-dump", "optimize", "cache"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(env.ComposerScripts, tc.value)
+			if got := composerScripts(); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("composerScripts() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWithNoScripts(t *testing.T) {
+	testCases := []struct {
+		name  string
+		flags []string
+		want  []string
+	}{
+		{
+			name:  "adds flag",
+			flags: []string{"--no-dev"},
+			want:  []string{"--no-dev", "--no-scripts"},
+		},
+		{
+			name:  "flag already present",
+			flags: []string{"--no-scripts", "--no-dev"},
+			want:  []string{"--no-scripts", "--no-dev"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := withNoScripts(tc.flags); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("withNoScripts(%v) = %v, want %v", tc.flags, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSecretEnvNames(t *testing.T) {
+	environ := []string{
+		"PATH=/usr/bin",
+		"COMPOSER_AUTH={\"github-oauth\":{}}",
+		"GITHUB_TOKEN=abc",
+		"APP_ENV=production",
+		"DB_PASSWORD=x=y",
+		"client_secret=z",
+	}
+	want := []string{"COMPOSER_AUTH", "DB_PASSWORD", "GITHUB_TOKEN", "client_secret"}
+	if got := secretEnvNames(environ); !reflect.DeepEqual(got, want) {
+		t.Errorf("secretEnvNames() = %v, want %v", got, want)
+	}
+}
+
+func TestDefinedScripts(t *testing.T) {
+	dir := t.TempDir()
+	content := `{"scripts": {"post-autoload-dump": ["@php artisan package:discover --ansi"], "gcp-build": "echo"}}`
+	if err := os.WriteFile(filepath.Join(dir, composerJSON), []byte(content), 0644); err != nil {
+		t.Fatalf("writing %s: %v", composerJSON, err)
+	}
+	got, err := definedScripts(dir)
+	if err != nil {
+		t.Fatalf("definedScripts(%q) got error: %v", dir, err)
+	}
+	want := map[string]bool{"post-autoload-dump": true, "gcp-build": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("definedScripts(%q) = %v, want %v", dir, got, want)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+const (
+	// FunctionsFrameworkVersionEnv is an env var used to pin the version of functions-framework
+	// installed for functions that do not depend on it explicitly.
+	FunctionsFrameworkVersionEnv = env.PythonFunctionsFrameworkVersion
+
+	functionsFrameworkPackage = "functions-framework"
+)
+
+// FunctionsFrameworkConflicts returns the problems reported by `pip check` that involve
+// functions-framework, i.e. requirements of the framework that the installed packages do not
+// satisfy.
+func FunctionsFrameworkConflicts(pipCheck string) []string {
+	var conflicts []string
+	for _, line := range strings.Split(pipCheck, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(strings.ToLower(line), functionsFrameworkPackage+" ") {
+			conflicts = append(conflicts, line)
+		}
+	}
+	return conflicts
+}
+
+// FunctionsFrameworkConflictError returns a user error that explains how to resolve conflicts
+// between the application's dependencies and functions-framework.
+func FunctionsFrameworkConflictError(conflicts []string) error {
+	return gcp.UserErrorf("the dependencies of the function conflict with %s:\n  %s\nPin versions compatible with %s in requirements.txt, or select a different %s version with %s",
+		functionsFrameworkPackage, strings.Join(conflicts, "\n  "), functionsFrameworkPackage, functionsFrameworkPackage, FunctionsFrameworkVersionEnv)
+}
+
+// FunctionsFrameworkRequirement returns the requirements file content that installs the given
+// version of functions-framework.
+func FunctionsFrameworkRequirement(version string) string {
+	return fmt.Sprintf("%s==%s\n", functionsFrameworkPackage, version)
+}
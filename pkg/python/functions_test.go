@@ -0,0 +1,53 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFunctionsFrameworkConflicts(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pipCheck string
+		want     []string
+	}{
+		{
+			name:     "no conflicts with functions-framework",
+			pipCheck: "google-cloud-storage 2.7.0 has requirement google-api-core<3.0.0,>=1.31.5, but you have google-api-core 0.1.0.\n",
+		},
+		{
+			name: "functions-framework conflicts",
+			pipCheck: `functions-framework 3.0.0 has requirement flask<3.0,>=1.0, but you have flask 3.0.0.
+google-cloud-storage 2.7.0 has requirement google-api-core<3.0.0,>=1.31.5, but you have google-api-core 0.1.0.
+functions-framework 3.0.0 requires cloudevents, which is not installed.
+`,
+			want: []string{
+				"functions-framework 3.0.0 has requirement flask<3.0,>=1.0, but you have flask 3.0.0.",
+				"functions-framework 3.0.0 requires cloudevents, which is not installed.",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, FunctionsFrameworkConflicts(tc.pipCheck)); diff != "" {
+				t.Errorf("FunctionsFrameworkConflicts() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -17,15 +17,20 @@ package python
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/ar"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/cache"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/Masterminds/semver"
 	"github.com/buildpacks/libcnb"
 )
 
@@ -44,9 +49,47 @@ const (
 	// The requirements files are processed from left to right, with requirements from the next overriding any conflicts from the previous.
 	RequirementsFilesEnv = "GOOGLE_INTERNAL_REQUIREMENTS_FILES"
 
-	versionFile = ".python-version"
-	versionKey  = "version"
-	versionEnv  = "GOOGLE_PYTHON_VERSION"
+	// RequirementsEnv is an env var used to specify a comma-separated list of the application's
+	// requirements files, relative to the application root. The files are installed in order.
+	// Example: `requirements.txt,requirements-prod.txt`.
+	RequirementsEnv = "GOOGLE_PYTHON_REQUIREMENTS"
+	// ConstraintsEnv is an env var used to specify a pip constraints file that is applied when
+	// installing every requirements file.
+	// Example: `constraints.txt`.
+	ConstraintsEnv = "GOOGLE_PYTHON_CONSTRAINTS"
+
+	// PrecompileEnv is an env var used to enable precompilation of the application, its dependencies
+	// and the standard library into a dedicated bytecode launch layer.
+	// Example: `true`, `True`, `1` will enable precompilation.
+	PrecompileEnv = "GOOGLE_PYTHON_PRECOMPILE"
+
+	// ExtraIndexURLEnv is an env var used to specify a comma-separated list of additional package
+	// indexes that pip searches besides PyPI.
+	// Example: `https://download.pytorch.org/whl/cpu`.
+	ExtraIndexURLEnv = "GOOGLE_PYTHON_EXTRA_INDEX_URL"
+	// TorchVariantEnv is an env var used to select the PyTorch wheel variant, e.g. CPU-only wheels
+	// instead of the multi-GB CUDA wheels published on PyPI. The variant selects the matching
+	// PyTorch package index. Only PyTorch is covered: TensorFlow has no variant index, its CPU-only
+	// build is the tensorflow-cpu package, which applications list in requirements.txt instead of
+	// tensorflow.
+	// Example: `cpu`, `cu118`, `rocm5.4.2`.
+	TorchVariantEnv = "GOOGLE_PYTHON_TORCH_VARIANT"
+	// torchIndexURL is the PyTorch package index for a wheel variant.
+	torchIndexURL = "https://download.pytorch.org/whl/%s"
+	// pipExtraIndexURLEnv is read by pip as a space-separated list of additional package indexes.
+	pipExtraIndexURLEnv = "PIP_EXTRA_INDEX_URL"
+
+	defaultRequirementsFile = "requirements.txt"
+
+	// gunicornWorkerMemory is the memory budgeted for each gunicorn worker process.
+	gunicornWorkerMemory = 256 * 1024 * 1024
+	// gunicornTotalThreads is the target number of threads across all gunicorn workers.
+	gunicornTotalThreads = 8
+
+	versionFile   = ".python-version"
+	pyprojectFile = "pyproject.toml"
+	versionKey    = "version"
+	versionEnv    = "GOOGLE_PYTHON_VERSION"
 
 	// python37SharedLibDir is the location of the shared Python library when building the python37 runtime.
 	python37SharedLibDir = "/layers/google.python.runtime/python/lib/python3.7/config-3.7m-x86_64-linux-gnu"
@@ -55,6 +98,26 @@ const (
 )
 
 var (
+	// pycachePrefixMinVersion is the first Python version that supports PYTHONPYCACHEPREFIX.
+	pycachePrefixMinVersion = semver.MustParse("3.8.0")
+	pythonVersionRegexp     = regexp.MustCompile(`^Python (\d+\.\d+\.\d+)`)
+
+	// torchVariantRegexp matches the wheel variants published on the PyTorch package index.
+	torchVariantRegexp = regexp.MustCompile(`^(cpu|cu\d{2,3}|rocm\d+(\.\d+)*)$`)
+
+	// wheelsDirs are the directories, relative to the application root, searched for vendored wheels.
+	wheelsDirs = []string{"wheels", "vendor"}
+
+	// specifierRegexp matches a single PEP 440 version specifier clause.
+	specifierRegexp = regexp.MustCompile(`^(~=|===|==|!=|<=|>=|<|>)\s*(\d+(?:\.\d+)*(?:\.\*)?)$`)
+
+	// asgiAppRegexp matches the definition of an ASGI application object in main.py.
+	asgiAppRegexp = regexp.MustCompile(`(?m)^app\s*(:[^=]+)?=\s*(fastapi\.|starlette\.applications\.)?(FastAPI|Starlette)\(`)
+	// asgiEntrypointRegexp matches an entrypoint that serves the application with uvicorn.
+	asgiEntrypointRegexp = regexp.MustCompile(`\buvicorn\b`)
+	// asgiPackages are dependencies that suggest an ASGI application.
+	asgiPackages = []string{"fastapi", "starlette", "uvicorn"}
+
 	// RequirementsProvides denotes that the buildpack provides requirements.txt in the environment.
 	RequirementsProvides = []libcnb.BuildPlanProvide{{Name: "requirements.txt"}}
 	// RequirementsRequires denotes that the buildpack consumes requirements.txt from the environment.
@@ -74,8 +137,14 @@ func Version(ctx *gcp.Context) (string, error) {
 	return strings.TrimSpace(result.Stdout), nil
 }
 
-// RuntimeVersion validate and returns the customer requested Python version by inspecting the
-// environment variables and .python-version file.
+// RuntimeVersion validate and returns the customer requested Python version. The version is taken
+// from the first of the following sources that specifies one:
+//  1. The GOOGLE_PYTHON_VERSION environment variable.
+//  2. The GOOGLE_RUNTIME_VERSION environment variable.
+//  3. The .python-version file.
+//  4. The requires-python field of the [project] table in pyproject.toml.
+//
+// If none of them specify a version, the latest available version is used.
 func RuntimeVersion(ctx *gcp.Context, dir string) (string, error) {
 	if v := os.Getenv(versionEnv); v != "" {
 		ctx.Logf("Using Python version from %s: %s", versionEnv, v)
@@ -92,6 +161,13 @@ func RuntimeVersion(ctx *gcp.Context, dir string) (string, error) {
 	if v != "" {
 		return v, nil
 	}
+	v, err = versionFromPyproject(ctx, dir)
+	if err != nil {
+		return "", err
+	}
+	if v != "" {
+		return v, nil
+	}
 
 	// This will use the highest listed at https://dl.google.com/runtimes/python/version.json.
 	ctx.Logf("Python version not specified, using the latest available version.")
@@ -119,6 +195,272 @@ func versionFromFile(ctx *gcp.Context, dir string) (string, error) {
 	return "", nil
 }
 
+// pyproject represents the fields of pyproject.toml used by the buildpacks.
+type pyproject struct {
+	Project struct {
+		Name           string `toml:"name"`
+		RequiresPython string `toml:"requires-python"`
+	} `toml:"project"`
+}
+
+func versionFromPyproject(ctx *gcp.Context, dir string) (string, error) {
+	pf := filepath.Join(dir, pyprojectFile)
+	exists, err := ctx.FileExists(pf)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", nil
+	}
+	var p pyproject
+	if _, err := toml.DecodeFile(pf, &p); err != nil {
+		return "", gcp.UserErrorf("parsing %s: %v", pf, err)
+	}
+	spec := strings.TrimSpace(p.Project.RequiresPython)
+	if spec == "" {
+		return "", nil
+	}
+	v, err := specifierToConstraint(spec)
+	if err != nil {
+		return "", gcp.UserErrorf("invalid requires-python %q in %s: %v", spec, pf, err)
+	}
+	ctx.Logf("Using Python version from requires-python in %s: %s", pf, spec)
+	return v, nil
+}
+
+// specifierToConstraint converts a PEP 440 version specifier, e.g. `>=3.9,<3.12` or `~=3.10`, to
+// the equivalent semver constraint understood by the runtime version resolution.
+func specifierToConstraint(spec string) (string, error) {
+	var clauses []string
+	for _, clause := range strings.Split(spec, ",") {
+		clause = strings.TrimSpace(clause)
+		match := specifierRegexp.FindStringSubmatch(clause)
+		if match == nil {
+			return "", fmt.Errorf("unsupported version specifier %q", clause)
+		}
+		op, ver := match[1], match[2]
+		switch op {
+		case "~=":
+			// Compatible release: ~=3.10 is >=3.10,<4 and ~=3.10.2 is >=3.10.2,<3.11.
+			parts := strings.Split(ver, ".")
+			if len(parts) < 2 {
+				return "", fmt.Errorf("compatible release specifier %q requires at least two version segments", clause)
+			}
+			upper := parts[:len(parts)-1]
+			last, err := strconv.Atoi(upper[len(upper)-1])
+			if err != nil {
+				return "", fmt.Errorf("parsing %q: %v", clause, err)
+			}
+			upper[len(upper)-1] = strconv.Itoa(last + 1)
+			clauses = append(clauses, ">="+ver, "<"+strings.Join(upper, "."))
+		case "==", "===":
+			clauses = append(clauses, "="+ver)
+		default:
+			clauses = append(clauses, op+ver)
+		}
+	}
+	return strings.Join(clauses, ", "), nil
+}
+
+// UserRequirementsFiles returns the application's requirements files in the order in which they
+// should be installed. The files are read from GOOGLE_PYTHON_REQUIREMENTS if it is set, otherwise
+// requirements.txt is returned if it exists.
+func UserRequirementsFiles(ctx *gcp.Context) ([]string, error) {
+	val := os.Getenv(RequirementsEnv)
+	if val == "" {
+		exists, err := ctx.FileExists(ctx.ApplicationRoot(), defaultRequirementsFile)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return []string{defaultRequirementsFile}, nil
+		}
+		return nil, nil
+	}
+
+	var reqs []string
+	for _, r := range strings.Split(val, ",") {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		exists, err := ctx.FileExists(appPath(ctx, r))
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, gcp.UserErrorf("requirements file %q specified in %s does not exist", r, RequirementsEnv)
+		}
+		reqs = append(reqs, r)
+	}
+	if len(reqs) == 0 {
+		return nil, gcp.UserErrorf("%s is set but does not specify any requirements files", RequirementsEnv)
+	}
+	return reqs, nil
+}
+
+// constraintsFile returns the pip constraints file specified by GOOGLE_PYTHON_CONSTRAINTS, or an
+// empty string if it is not set.
+func constraintsFile(ctx *gcp.Context) (string, error) {
+	c := strings.TrimSpace(os.Getenv(ConstraintsEnv))
+	if c == "" {
+		return "", nil
+	}
+	exists, err := ctx.FileExists(appPath(ctx, c))
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", gcp.UserErrorf("constraints file %q specified in %s does not exist", c, ConstraintsEnv)
+	}
+	return c, nil
+}
+
+// vendoredWheels returns the first of the wheels/ and vendor/ directories in the application root
+// that contains prebuilt wheels, along with the wheel file names. It returns an empty directory if
+// no wheels are vendored.
+func vendoredWheels(ctx *gcp.Context) (string, []string, error) {
+	for _, d := range wheelsDirs {
+		dir := filepath.Join(ctx.ApplicationRoot(), d)
+		matches, err := ctx.Glob(filepath.Join(dir, "*.whl"))
+		if err != nil {
+			return "", nil, err
+		}
+		if len(matches) == 0 {
+			continue
+		}
+		var wheels []string
+		for _, m := range matches {
+			wheels = append(wheels, filepath.Base(m))
+		}
+		return dir, wheels, nil
+	}
+	return "", nil, nil
+}
+
+// extraIndexURLs returns the additional package indexes configured through
+// GOOGLE_PYTHON_EXTRA_INDEX_URL and GOOGLE_PYTHON_TORCH_VARIANT.
+func extraIndexURLs() ([]string, error) {
+	var urls []string
+	for _, u := range strings.Split(os.Getenv(ExtraIndexURLEnv), ",") {
+		if u = strings.TrimSpace(u); u == "" {
+			continue
+		}
+		// The URL is not included in the errors because it may contain credentials.
+		parsed, err := url.Parse(u)
+		if err != nil {
+			return nil, gcp.UserErrorf("invalid package index URL in %s", ExtraIndexURLEnv)
+		}
+		if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, gcp.UserErrorf("invalid package index URL %q in %s, must be an absolute http or https URL", parsed.Redacted(), ExtraIndexURLEnv)
+		}
+		urls = append(urls, u)
+	}
+	if v := strings.TrimSpace(os.Getenv(TorchVariantEnv)); v != "" {
+		if !torchVariantRegexp.MatchString(v) {
+			return nil, gcp.UserErrorf("invalid %s %q, must be cpu, a CUDA variant such as cu118 or a ROCm variant such as rocm5.4.2", TorchVariantEnv, v)
+		}
+		urls = append(urls, fmt.Sprintf(torchIndexURL, v))
+	}
+	return urls, nil
+}
+
+// pipSources holds the options that control where pip finds packages and which versions it may
+// install.
+type pipSources struct {
+	constraints    string
+	wheelsDir      string
+	wheels         []string
+	extraIndexURLs []string
+}
+
+// newPipSources returns the package sources configured for the application, so that every pip
+// install of the application uses the same constraints and indexes, or installs offline from
+// vendored wheels.
+func newPipSources(ctx *gcp.Context) (pipSources, error) {
+	constraints, err := constraintsFile(ctx)
+	if err != nil {
+		return pipSources{}, err
+	}
+	wheelsDir, wheels, err := vendoredWheels(ctx)
+	if err != nil {
+		return pipSources{}, err
+	}
+	indexURLs, err := extraIndexURLs()
+	if err != nil {
+		return pipSources{}, err
+	}
+	if strings.TrimSpace(os.Getenv(TorchVariantEnv)) == "cpu" {
+		tf, err := RequirementsContain(ctx, "tensorflow")
+		if err != nil {
+			return pipSources{}, err
+		}
+		if tf {
+			ctx.Warnf("%s=cpu only selects the CPU-only PyTorch wheels. To install the CPU-only TensorFlow build, replace tensorflow with tensorflow-cpu in requirements.txt.", TorchVariantEnv)
+		}
+	}
+	return pipSources{constraints: constraints, wheelsDir: wheelsDir, wheels: wheels, extraIndexURLs: indexURLs}, nil
+}
+
+// args returns the pip install flags for the sources.
+func (s pipSources) args() []string {
+	var args []string
+	if s.constraints != "" {
+		args = append(args, "--constraint", s.constraints)
+	}
+	if s.wheelsDir != "" {
+		return append(args, "--no-index", "--find-links", s.wheelsDir)
+	}
+	return args
+}
+
+// exec runs a pip install command that uses the sources. The extra package indexes are passed to
+// pip in PIP_EXTRA_INDEX_URL rather than as flags or with gcp.WithEnv, because both are written to
+// the build log and index URLs may contain credentials.
+func (s pipSources) exec(ctx *gcp.Context, cmd []string) error {
+	if s.wheelsDir == "" && len(s.extraIndexURLs) > 0 {
+		urls := strings.Join(s.extraIndexURLs, " ")
+		prev, ok := os.LookupEnv(pipExtraIndexURLEnv)
+		if prev = strings.TrimSpace(prev); prev != "" {
+			urls = prev + " " + urls
+		}
+		if err := os.Setenv(pipExtraIndexURLEnv, urls); err != nil {
+			return gcp.InternalErrorf("setting %s: %v", pipExtraIndexURLEnv, err)
+		}
+		defer func() {
+			if ok {
+				os.Setenv(pipExtraIndexURLEnv, prev)
+			} else {
+				os.Unsetenv(pipExtraIndexURLEnv)
+			}
+		}()
+	}
+	_, err := ctx.Exec(cmd, gcp.WithUserAttribution)
+	return err
+}
+
+// redactedURLs returns the URLs with any password replaced, for logging.
+func redactedURLs(urls []string) []string {
+	var redacted []string
+	for _, u := range urls {
+		parsed, err := url.Parse(u)
+		if err != nil {
+			redacted = append(redacted, "<invalid URL>")
+			continue
+		}
+		redacted = append(redacted, parsed.Redacted())
+	}
+	return redacted
+}
+
+// appPath resolves a user-specified path relative to the application root.
+func appPath(ctx *gcp.Context, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(ctx.ApplicationRoot(), path)
+}
+
 // InstallRequirements installs dependencies from the given requirements files in a virtual env.
 // It will install the files in order in which they are specified, so that dependencies specified
 // in later requirements files can override later ones.
@@ -129,8 +471,12 @@ func versionFromFile(ctx *gcp.Context, dir string) (string, error) {
 // accidentally override some builtin stdlib modules, e.g. typing, enum, etc., and cause both
 // build-time and run-time failures.
 func InstallRequirements(ctx *gcp.Context, l *libcnb.Layer, reqs ...string) error {
+	project, err := projectName(ctx)
+	if err != nil {
+		return err
+	}
 	// Defensive check, this should not happen in practice.
-	if len(reqs) == 0 {
+	if len(reqs) == 0 && project == "" {
 		ctx.Debugf("No requirements.txt to install, clearing layer.")
 		if err := ctx.ClearLayer(l); err != nil {
 			return fmt.Errorf("clearing layer %q: %w", l.Name, err)
@@ -138,19 +484,54 @@ func InstallRequirements(ctx *gcp.Context, l *libcnb.Layer, reqs ...string) erro
 		return nil
 	}
 
+	sources, err := newPipSources(ctx)
+	if err != nil {
+		return err
+	}
+	// All requirements files, the project metadata and the constraints file contribute to the cache
+	// key, so that code-only changes reuse the dependencies layer as is.
+	hashed := append([]string{}, reqs...)
+	if project != "" {
+		hashed = append(hashed, filepath.Join(ctx.ApplicationRoot(), pyprojectFile))
+	}
+	if sources.constraints != "" {
+		ctx.Logf("Using pip constraints from %s", sources.constraints)
+		hashed = append(hashed, sources.constraints)
+	}
+
+	cacheOpts := []cache.Option{cache.WithFiles(hashed...)}
+
+	if sources.wheelsDir != "" {
+		ctx.Logf("Installing dependencies offline from %d vendored wheels in %s.", len(sources.wheels), sources.wheelsDir)
+		if len(sources.extraIndexURLs) > 0 {
+			ctx.Warnf("Ignoring %s and %s, dependencies are installed from vendored wheels only.", ExtraIndexURLEnv, TorchVariantEnv)
+		}
+		// Wheel file names include the package version, so they identify the vendored packages.
+		cacheOpts = append(cacheOpts, cache.WithStrings(sources.wheels...))
+	} else if len(sources.extraIndexURLs) > 0 {
+		ctx.Logf("Using additional package indexes: %s", strings.Join(redactedURLs(sources.extraIndexURLs), ", "))
+		cacheOpts = append(cacheOpts, cache.WithStrings(sources.extraIndexURLs...))
+	}
+
 	// Check if we can use the cached-layer as is without reinstalling dependencies.
-	cached, err := checkCache(ctx, l, cache.WithFiles(reqs...))
+	cached, err := checkCache(ctx, l, cacheOpts...)
 	if err != nil {
 		return fmt.Errorf("checking cache: %w", err)
 	}
+	// HACK: For backwards compatibility with Python 3.7 and 3.8 on App Engine and Cloud Functions.
+	virtualEnv := requiresVirtualEnv()
 	if cached {
 		ctx.CacheHit(l.Name)
-		return nil
+		// The cached packages are only visible to this buildpack, to subsequent buildpacks and at
+		// launch once the layer is activated.
+		return activateLayer(ctx, l, virtualEnv)
 	}
 	ctx.CacheMiss(l.Name)
 
-	if err := ar.GeneratePythonConfig(ctx); err != nil {
-		return fmt.Errorf("generating Artifact Registry credentials: %w", err)
+	if sources.wheelsDir == "" {
+		if err := ar.GeneratePythonConfig(ctx); err != nil {
+			return fmt.Errorf("generating Artifact Registry credentials: %w", err)
+		}
 	}
 
 	// History of the logic below:
@@ -166,35 +547,8 @@ func InstallRequirements(ctx *gcp.Context, l *libcnb.Layer, reqs ...string) erro
 	// to specify conflicting dependencies (e.g. functions-framework pins package A at 1.2.0 but
 	// the user's requirements.txt file pins A at 1.4.0. The user should be able to override
 	// the functions-framework-pinned package).
-
-	// HACK: For backwards compatibility with Python 3.7 and 3.8 on App Engine and Cloud Functions.
-	virtualEnv := requiresVirtualEnv()
-	if virtualEnv {
-		// --without-pip and --system-site-packages allow us to use `pip` and other packages from the
-		// build image and avoid reinstalling them, saving about 10MB.
-		// TODO(b/140775593): Use virtualenv pip after FTL is no longer used and remove from build image.
-		if _, err := ctx.Exec([]string{"python3", "-m", "venv", "--without-pip", "--system-site-packages", l.Path}); err != nil {
-			return err
-		}
-		if err := copySharedLibs(ctx, l); err != nil {
-			return err
-		}
-
-		// The VIRTUAL_ENV variable is usually set by the virtual environment's activate script.
-		l.SharedEnvironment.Override("VIRTUAL_ENV", l.Path)
-		// Use the virtual environment python3 for all subsequent commands in this buildpack, for
-		// subsequent buildpacks, l.Path/bin will be added by lifecycle.
-		if err := ctx.Setenv("PATH", filepath.Join(l.Path, "bin")+string(os.PathListSeparator)+os.Getenv("PATH")); err != nil {
-			return err
-		}
-		if err := ctx.Setenv("VIRTUAL_ENV", l.Path); err != nil {
-			return err
-		}
-	} else {
-		l.SharedEnvironment.Default("PYTHONUSERBASE", l.Path)
-		if err := ctx.Setenv("PYTHONUSERBASE", l.Path); err != nil {
-			return err
-		}
+	if err := activateLayer(ctx, l, virtualEnv); err != nil {
+		return err
 	}
 
 	for _, req := range reqs {
@@ -210,11 +564,17 @@ func InstallRequirements(ctx *gcp.Context, l *libcnb.Layer, reqs ...string) erro
 			"--disable-pip-version-check", // If we were going to upgrade pip, we would have done it already in the runtime buildpack.
 			"--no-cache-dir",              // We used to save this to a layer, but it made builds slower because it includes http caching of pypi requests.
 		}
+		cmd = append(cmd, sources.args()...)
 		if !virtualEnv {
 			cmd = append(cmd, "--user") // Install into user site-packages directory.
 		}
-		if _, err := ctx.Exec(cmd,
-			gcp.WithUserAttribution); err != nil {
+		if err := sources.exec(ctx, cmd); err != nil {
+			return err
+		}
+	}
+
+	if project != "" {
+		if err := installProjectDependencies(ctx, project, virtualEnv, sources); err != nil {
 			return err
 		}
 	}
@@ -242,6 +602,292 @@ func InstallRequirements(ctx *gcp.Context, l *libcnb.Layer, reqs ...string) erro
 	return nil
 }
 
+// InstallProject installs the application itself from its pyproject.toml into layer l and adds
+// the layer to PYTHONPATH. Installing the project, rather than only copying its files, supports src
+// layouts and generates the console scripts of its entry points. The dependencies of the project
+// are installed by InstallRequirements into the cached dependencies layer, so that only this layer
+// changes when the application code changes.
+func InstallProject(ctx *gcp.Context, l *libcnb.Layer) error {
+	// The project is built with the same sources as its dependencies, so that builds with vendored
+	// wheels do not reach out to the package index for the build backend.
+	sources, err := newPipSources(ctx)
+	if err != nil {
+		return err
+	}
+	ctx.Logf("Installing application package from %s.", pyprojectFile)
+	cmd := []string{
+		"python3", "-m", "pip", "install",
+		"--no-deps", // Dependencies are installed in the dependencies layer.
+		"--target", l.Path,
+		"--no-warn-script-location",
+		"--disable-pip-version-check",
+		"--no-cache-dir",
+	}
+	cmd = append(cmd, sources.args()...)
+	cmd = append(cmd, ctx.ApplicationRoot())
+	if err := sources.exec(ctx, cmd); err != nil {
+		return err
+	}
+	l.SharedEnvironment.Prepend("PYTHONPATH", string(os.PathListSeparator), l.Path)
+	pythonPath := l.Path
+	if p := os.Getenv("PYTHONPATH"); p != "" {
+		pythonPath += string(os.PathListSeparator) + p
+	}
+	return ctx.Setenv("PYTHONPATH", pythonPath)
+}
+
+// installProjectDependencies installs the dependencies declared in pyproject.toml into the active
+// dependencies layer. pip cannot install only the dependencies of a project, so the project is
+// installed with its dependencies and then uninstalled again.
+func installProjectDependencies(ctx *gcp.Context, project string, virtualEnv bool, sources pipSources) error {
+	cmd := []string{
+		"python3", "-m", "pip", "install",
+		"--upgrade",
+		"--upgrade-strategy", "only-if-needed",
+		"--no-warn-script-location",
+		"--no-warn-conflicts",
+		"--no-compile",
+		"--disable-pip-version-check",
+		"--no-cache-dir",
+	}
+	cmd = append(cmd, sources.args()...)
+	if !virtualEnv {
+		cmd = append(cmd, "--user")
+	}
+	cmd = append(cmd, ctx.ApplicationRoot())
+	if err := sources.exec(ctx, cmd); err != nil {
+		return err
+	}
+	if _, err := ctx.Exec([]string{"python3", "-m", "pip", "uninstall", "--yes", "--disable-pip-version-check", project}, gcp.WithUserAttribution); err != nil {
+		return err
+	}
+	return nil
+}
+
+// IsInstallableProject returns true if the application has a pyproject.toml with a build system
+// and project metadata, which means it can be installed as a package with `pip install`.
+func IsInstallableProject(ctx *gcp.Context) (bool, error) {
+	name, err := projectName(ctx)
+	return name != "", err
+}
+
+// projectName returns the name of the installable project declared in pyproject.toml, or an empty
+// string if the application is not an installable project.
+func projectName(ctx *gcp.Context) (string, error) {
+	pf := filepath.Join(ctx.ApplicationRoot(), pyprojectFile)
+	exists, err := ctx.FileExists(pf)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", nil
+	}
+	var p pyproject
+	md, err := toml.DecodeFile(pf, &p)
+	if err != nil {
+		return "", gcp.UserErrorf("parsing %s: %v", pf, err)
+	}
+	if !md.IsDefined("build-system") {
+		return "", nil
+	}
+	return p.Project.Name, nil
+}
+
+// activateLayer makes layer l the installation target of pip for this and subsequent buildpacks,
+// either as a virtual environment or as the per-user site-packages directory.
+func activateLayer(ctx *gcp.Context, l *libcnb.Layer, virtualEnv bool) error {
+	if !virtualEnv {
+		l.SharedEnvironment.Default("PYTHONUSERBASE", l.Path)
+		return ctx.Setenv("PYTHONUSERBASE", l.Path)
+	}
+	if os.Getenv("VIRTUAL_ENV") == l.Path {
+		return nil
+	}
+	venvExists, err := ctx.FileExists(l.Path, "pyvenv.cfg")
+	if err != nil {
+		return err
+	}
+	if !venvExists {
+		// --without-pip and --system-site-packages allow us to use `pip` and other packages from the
+		// build image and avoid reinstalling them, saving about 10MB.
+		// TODO(b/140775593): Use virtualenv pip after FTL is no longer used and remove from build image.
+		if _, err := ctx.Exec([]string{"python3", "-m", "venv", "--without-pip", "--system-site-packages", l.Path}); err != nil {
+			return err
+		}
+		if err := copySharedLibs(ctx, l); err != nil {
+			return err
+		}
+	}
+
+	// The VIRTUAL_ENV variable is usually set by the virtual environment's activate script.
+	l.SharedEnvironment.Override("VIRTUAL_ENV", l.Path)
+	// Use the virtual environment python3 for all subsequent commands in this buildpack, for
+	// subsequent buildpacks, l.Path/bin will be added by lifecycle.
+	if err := ctx.Setenv("PATH", filepath.Join(l.Path, "bin")+string(os.PathListSeparator)+os.Getenv("PATH")); err != nil {
+		return err
+	}
+	return ctx.Setenv("VIRTUAL_ENV", l.Path)
+}
+
+// PrecompileBytecode compiles the standard library and the given directories into layer l, and
+// points PYTHONPYCACHEPREFIX at the layer at launch time. Keeping the bytecode in a separate layer
+// avoids writing __pycache__ directories into the application and spares the interpreter from
+// compiling modules on cold start.
+func PrecompileBytecode(ctx *gcp.Context, l *libcnb.Layer, dirs ...string) error {
+	pyVer, err := Version(ctx)
+	if err != nil {
+		return err
+	}
+	supported, err := supportsPycachePrefix(pyVer)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		ctx.Warnf("Skipping bytecode precompilation, %s requires Python %s or newer, found %q.", PrecompileEnv, pycachePrefixMinVersion, pyVer)
+		return nil
+	}
+
+	result, err := ctx.Exec([]string{"python3", "-c", "import sysconfig; print(sysconfig.get_path('stdlib'))"})
+	if err != nil {
+		return fmt.Errorf("determining standard library path: %w", err)
+	}
+	stdlib := strings.TrimSpace(result.Stdout)
+
+	ctx.Logf("Precompiling Python bytecode into the %s layer.", l.Name)
+	cmd := []string{
+		"python3", "-m", "compileall",
+		"--invalidation-mode", "unchecked-hash",
+		"-qq",     // Do not print any message (matches `pip install` behavior).
+		"-j", "0", // Use all available CPUs.
+		stdlib,
+	}
+	cmd = append(cmd, dirs...)
+	result, cerr := ctx.Exec(cmd, gcp.WithEnv("PYTHONPYCACHEPREFIX="+l.Path), gcp.WithUserAttribution)
+	if cerr != nil {
+		// Ignore file compilation errors (matches `pip install` behavior).
+		if result == nil || result.ExitCode != 1 {
+			return fmt.Errorf("compileall: %v", cerr)
+		}
+	}
+
+	l.LaunchEnvironment.Override("PYTHONPYCACHEPREFIX", l.Path)
+	return nil
+}
+
+// supportsPycachePrefix returns true if the output of `python3 --version` denotes a version that
+// supports PYTHONPYCACHEPREFIX.
+func supportsPycachePrefix(pyVer string) (bool, error) {
+	match := pythonVersionRegexp.FindStringSubmatch(pyVer)
+	if len(match) < 2 {
+		return false, gcp.InternalErrorf("unable to parse Python version from %q", pyVer)
+	}
+	v, err := semver.NewVersion(match[1])
+	if err != nil {
+		return false, gcp.InternalErrorf("parsing Python version %q: %v", match[1], err)
+	}
+	return !v.LessThan(pycachePrefixMinVersion), nil
+}
+
+// SatisfiesRequiresPython returns true if the Python version, as reported by `python3 --version`,
+// satisfies the PEP 440 requires-python specifier of a package.
+func SatisfiesRequiresPython(pyVer, spec string) (bool, error) {
+	match := pythonVersionRegexp.FindStringSubmatch(pyVer)
+	if len(match) < 2 {
+		return false, gcp.InternalErrorf("unable to parse Python version from %q", pyVer)
+	}
+	v, err := semver.NewVersion(match[1])
+	if err != nil {
+		return false, gcp.InternalErrorf("parsing Python version %q: %v", match[1], err)
+	}
+	constraint, err := specifierToConstraint(spec)
+	if err != nil {
+		return false, err
+	}
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return false, fmt.Errorf("parsing constraint %q: %v", constraint, err)
+	}
+	return c.Check(v), nil
+}
+
+// ASGIEntrypoint is the command used to serve ASGI applications with gunicorn and uvicorn workers.
+const ASGIEntrypoint = "gunicorn -b :$PORT -k uvicorn.workers.UvicornWorker main:app"
+
+// IsASGIApp returns true if the application is an ASGI application. An application is only
+// considered ASGI if the entrypoint in GOOGLE_ENTRYPOINT runs uvicorn, or if the `app`
+// object in main.py, which the default entrypoint serves, is a FastAPI or Starlette application.
+// ASGI dependencies alone are not enough, because WSGI applications commonly depend on them too.
+func IsASGIApp(ctx *gcp.Context) (bool, error) {
+	if ep := os.Getenv(env.Entrypoint); ep != "" {
+		return asgiEntrypointRegexp.MatchString(ep), nil
+	}
+	mainPy := filepath.Join(ctx.ApplicationRoot(), "main.py")
+	exists, err := ctx.FileExists(mainPy)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		content, err := ctx.ReadFile(mainPy)
+		if err != nil {
+			return false, err
+		}
+		if asgiAppRegexp.Match(content) {
+			ctx.Debugf("Found ASGI application object in %s.", mainPy)
+			return true, nil
+		}
+	}
+	for _, p := range asgiPackages {
+		found, err := RequirementsContain(ctx, p)
+		if err != nil {
+			return false, err
+		}
+		if found {
+			ctx.Warnf("Found ASGI dependency %s, but main.py does not define a FastAPI or Starlette `app` object. The default entrypoint serves main:app as a WSGI application; to serve an ASGI application, set %s, for example: %s", p, env.Entrypoint, ASGIEntrypoint)
+			return false, nil
+		}
+	}
+	return false, nil
+}
+
+// RequirementsContain returns true if any of the application's requirements files lists the
+// given package.
+func RequirementsContain(ctx *gcp.Context, pkg string) (bool, error) {
+	re := regexp.MustCompile(`(?mi)^` + regexp.QuoteMeta(pkg) + `\b([^-]|$)`)
+	reqs, err := UserRequirementsFiles(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, req := range reqs {
+		content, err := ctx.ReadFile(appPath(ctx, req))
+		if err != nil {
+			return false, err
+		}
+		if re.Match(content) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GunicornSettings returns the number of gunicorn workers and threads per worker for a container
+// with the given number of CPUs and memory in bytes. The worker count follows the gunicorn
+// recommendation of (2 x CPUs) + 1, limited by the memory budgeted for each worker. Threads are
+// added so that small instances can still serve concurrent I/O-bound requests.
+func GunicornSettings(cpus int, memory int64) (workers, threads int) {
+	workers = 2*cpus + 1
+	if byMemory := int(memory / gunicornWorkerMemory); byMemory < workers {
+		workers = byMemory
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	threads = gunicornTotalThreads / workers
+	if threads < 2 {
+		threads = 2
+	}
+	return workers, threads
+}
+
 // checkCache checks whether cached dependencies exist, match, and have not expired.
 func checkCache(ctx *gcp.Context, l *libcnb.Layer, opts ...cache.Option) (bool, error) {
 	currentPythonVersion, err := Version(ctx)
@@ -0,0 +1,148 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/sbom"
+	"github.com/buildpacks/libcnb"
+)
+
+var (
+	// cycloneDXHashAlgorithms maps hash names used by pip to CycloneDX algorithm names.
+	cycloneDXHashAlgorithms = map[string]string{
+		"md5":    "MD5",
+		"sha1":   "SHA-1",
+		"sha256": "SHA-256",
+		"sha384": "SHA-384",
+		"sha512": "SHA-512",
+	}
+	nameSeparatorRegexp = regexp.MustCompile(`[-_.]+`)
+)
+
+// pipInspectReport represents the fields of the `pip inspect` JSON report used by the buildpacks.
+type pipInspectReport struct {
+	Installed []struct {
+		Metadata struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"metadata"`
+		MetadataLocation string `json:"metadata_location"`
+		DirectURL        *struct {
+			ArchiveInfo *struct {
+				Hashes map[string]string `json:"hashes"`
+			} `json:"archive_info"`
+		} `json:"direct_url"`
+	} `json:"installed"`
+}
+
+// pipListEntry represents an entry of the `pip list --format=json` output.
+type pipListEntry struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// WriteSBOM attaches a CycloneDX SBOM listing the packages installed in layer l to the layer.
+// The package list is read from `pip inspect`, falling back to `pip list` for pip versions that do
+// not support it.
+func WriteSBOM(ctx *gcp.Context, l *libcnb.Layer) error {
+	scope := "--user"
+	if requiresVirtualEnv() {
+		scope = "--local"
+	}
+
+	var components []sbom.Component
+	result, err := ctx.Exec([]string{"python3", "-m", "pip", "inspect", scope, "--disable-pip-version-check"})
+	if err == nil {
+		var report pipInspectReport
+		if err := json.Unmarshal([]byte(result.Stdout), &report); err != nil {
+			return gcp.InternalErrorf("parsing pip inspect report: %v", err)
+		}
+		components, err = inspectComponents(report)
+		if err != nil {
+			return err
+		}
+	} else {
+		ctx.Debugf("pip inspect is not available, using pip list for the SBOM: %v", err)
+		result, err := ctx.Exec([]string{"python3", "-m", "pip", "list", scope, "--format=json", "--disable-pip-version-check"})
+		if err != nil {
+			return fmt.Errorf("listing installed packages: %w", err)
+		}
+		var entries []pipListEntry
+		if err := json.Unmarshal([]byte(result.Stdout), &entries); err != nil {
+			return gcp.InternalErrorf("parsing pip list output: %v", err)
+		}
+		for _, e := range entries {
+			components = append(components, pypiComponent(e.Name, e.Version))
+		}
+	}
+	return sbom.WriteLayerSBOM(ctx, l, components)
+}
+
+// inspectComponents converts a `pip inspect` report to SBOM components. Each component carries the
+// archive hashes recorded by pip or, when pip did not record any, the SHA-256 of the distribution's
+// RECORD file, which lists the hashes of all installed files.
+func inspectComponents(report pipInspectReport) ([]sbom.Component, error) {
+	var components []sbom.Component
+	for _, dist := range report.Installed {
+		c := pypiComponent(dist.Metadata.Name, dist.Metadata.Version)
+		if dist.DirectURL != nil && dist.DirectURL.ArchiveInfo != nil {
+			var algs []string
+			for alg := range dist.DirectURL.ArchiveInfo.Hashes {
+				algs = append(algs, alg)
+			}
+			sort.Strings(algs)
+			for _, alg := range algs {
+				if name, ok := cycloneDXHashAlgorithms[alg]; ok {
+					c.Hashes = append(c.Hashes, sbom.Hash{Algorithm: name, Content: dist.DirectURL.ArchiveInfo.Hashes[alg]})
+				}
+			}
+		}
+		if len(c.Hashes) == 0 && dist.MetadataLocation != "" {
+			record, err := os.ReadFile(filepath.Join(dist.MetadataLocation, "RECORD"))
+			if err != nil && !os.IsNotExist(err) {
+				return nil, gcp.InternalErrorf("reading RECORD of %s: %v", dist.Metadata.Name, err)
+			}
+			if err == nil {
+				sum := sha256.Sum256(record)
+				c.Hashes = append(c.Hashes, sbom.Hash{Algorithm: "SHA-256", Content: hex.EncodeToString(sum[:])})
+			}
+		}
+		components = append(components, c)
+	}
+	return components, nil
+}
+
+// pypiComponent returns an SBOM component with a package URL for a PyPI distribution.
+func pypiComponent(name, version string) sbom.Component {
+	// Package URLs use the normalized PyPI name, see https://peps.python.org/pep-0503/#normalized-names.
+	normalized := strings.ToLower(nameSeparatorRegexp.ReplaceAllString(name, "-"))
+	return sbom.Component{
+		Type:    "library",
+		Name:    name,
+		Version: version,
+		PURL:    fmt.Sprintf("pkg:pypi/%s@%s", normalized, version),
+	}
+}
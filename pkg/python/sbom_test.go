@@ -0,0 +1,115 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/sbom"
+	"github.com/buildpacks/libcnb"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestInspectComponents(t *testing.T) {
+	distInfo := filepath.Join(t.TempDir(), "Flask-2.2.3.dist-info")
+	if err := os.MkdirAll(distInfo, 0755); err != nil {
+		t.Fatalf("creating %s: %v", distInfo, err)
+	}
+	if err := os.WriteFile(filepath.Join(distInfo, "RECORD"), []byte("flask/__init__.py,sha256=abc,100\n"), 0644); err != nil {
+		t.Fatalf("writing RECORD: %v", err)
+	}
+	report := `{
+  "version": "1",
+  "installed": [
+    {
+      "metadata": {"name": "Flask", "version": "2.2.3"},
+      "metadata_location": "` + distInfo + `"
+    },
+    {
+      "metadata": {"name": "zope.interface", "version": "5.5.2"},
+      "direct_url": {"archive_info": {"hashes": {"sha256": "deadbeef", "blake2b": "ignored"}}}
+    },
+    {
+      "metadata": {"name": "typing_extensions", "version": "4.5.0"},
+      "metadata_location": "/does/not/exist"
+    }
+  ]
+}`
+	var r pipInspectReport
+	if err := json.Unmarshal([]byte(report), &r); err != nil {
+		t.Fatalf("unmarshalling report: %v", err)
+	}
+
+	got, err := inspectComponents(r)
+	if err != nil {
+		t.Fatalf("inspectComponents() got error: %v", err)
+	}
+	want := []sbom.Component{
+		{
+			Type:    "library",
+			Name:    "Flask",
+			Version: "2.2.3",
+			PURL:    "pkg:pypi/flask@2.2.3",
+			Hashes:  []sbom.Hash{{Algorithm: "SHA-256", Content: "0c90ca10e476241e89bf185ab5e550ddd2038218c36e320bffee8ac1d79b57b1"}},
+		},
+		{
+			Type:    "library",
+			Name:    "zope.interface",
+			Version: "5.5.2",
+			PURL:    "pkg:pypi/zope-interface@5.5.2",
+			Hashes:  []sbom.Hash{{Algorithm: "SHA-256", Content: "deadbeef"}},
+		},
+		{
+			Type:    "library",
+			Name:    "typing_extensions",
+			Version: "4.5.0",
+			PURL:    "pkg:pypi/typing-extensions@4.5.0",
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("inspectComponents() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteSBOMCachedRequirements(t *testing.T) {
+	ctx := gcp.NewContext(gcp.WithApplicationRoot(t.TempDir()))
+	l := installCachedRequirements(t, ctx, map[string]string{
+		"cachedpkg-1.0.dist-info/METADATA": "Metadata-Version: 2.1\nName: cachedpkg\nVersion: 1.0\n",
+		"cachedpkg-1.0.dist-info/RECORD":   "",
+	})
+
+	if err := WriteSBOM(ctx, l); err != nil {
+		t.Fatalf("WriteSBOM() got error: %v", err)
+	}
+	content, err := os.ReadFile(l.SBOMPath(libcnb.CycloneDXJSON))
+	if err != nil {
+		t.Fatalf("reading SBOM: %v", err)
+	}
+	var bom sbom.BOM
+	if err := json.Unmarshal(content, &bom); err != nil {
+		t.Fatalf("unmarshalling SBOM: %v", err)
+	}
+	var got []string
+	for _, c := range bom.Components {
+		got = append(got, c.PURL)
+	}
+	if want := []string{"pkg:pypi/cachedpkg@1.0"}; !cmp.Equal(got, want) {
+		t.Errorf("WriteSBOM() of a cached layer listed %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+const (
+	// VerifyImportEnv is an env var used to enable a build-time check that imports the application
+	// entrypoint, so that missing modules fail the build instead of the first request.
+	// Example: `true`, `True`, `1` will enable the check.
+	VerifyImportEnv = "GOOGLE_PYTHON_VERIFY_IMPORT"
+
+	// verifyImportScript imports a module, or a source file ending in .py, and resolves an optional
+	// dotted attribute of it.
+	verifyImportScript = `import importlib, importlib.util, sys
+src, attr = sys.argv[1], sys.argv[2]
+if src.endswith(".py"):
+    spec = importlib.util.spec_from_file_location("__verify_import__", src)
+    obj = importlib.util.module_from_spec(spec)
+    spec.loader.exec_module(obj)
+else:
+    obj = importlib.import_module(src)
+for part in filter(None, attr.split(".")):
+    obj = getattr(obj, part)
+`
+)
+
+// appSpecRegexp matches a WSGI/ASGI application spec such as `main:app` or
+// `myproject.wsgi:create_app()` in a server command line.
+var appSpecRegexp = regexp.MustCompile(`^([A-Za-z_][\w.]*):([A-Za-z_][\w.]*)(\(.*\))?$`)
+
+// ImportTarget returns the source, either a module name or a .py file, and the attribute that the
+// application serves. The target is derived from the function configuration, from the application
+// spec in GOOGLE_ENTRYPOINT, or defaults to `main:app`. An empty source means that the target
+// cannot be determined.
+func ImportTarget(ctx *gcp.Context) (source, attr string, err error) {
+	_, hasTarget := os.LookupEnv(env.FunctionTarget)
+	_, hasTargets := os.LookupEnv(env.FunctionTargets)
+	if hasTarget || hasTargets {
+		targets, err := gcp.FunctionTargets()
+		if err != nil {
+			return "", "", err
+		}
+		source := os.Getenv(env.FunctionSource)
+		if source == "" {
+			source = "main.py"
+		}
+		return source, targets[0], nil
+	}
+	if entrypoint := os.Getenv(env.Entrypoint); entrypoint != "" {
+		for _, field := range strings.Fields(entrypoint) {
+			if m := appSpecRegexp.FindStringSubmatch(strings.Trim(field, `'"`)); m != nil {
+				return m[1], m[2], nil
+			}
+		}
+		return "", "", nil
+	}
+	mainExists, err := ctx.FileExists(ctx.ApplicationRoot(), "main.py")
+	if err != nil || !mainExists {
+		return "", "", err
+	}
+	return "main", "app", nil
+}
+
+// VerifyImport imports the application target in a subprocess with the environment of the build
+// and returns a user error with the import traceback if it fails.
+func VerifyImport(ctx *gcp.Context) error {
+	source, attr, err := ImportTarget(ctx)
+	if err != nil {
+		return err
+	}
+	if source == "" {
+		ctx.Warnf("Skipping import verification, unable to determine the application module from %s.", env.Entrypoint)
+		return nil
+	}
+	target := source
+	if attr != "" {
+		target += ":" + attr
+	}
+	ctx.Logf("Verifying that %s can be imported.", target)
+	result, err := ctx.Exec([]string{"python3", "-c", verifyImportScript, source, attr}, gcp.WithWorkDir(ctx.ApplicationRoot()))
+	if err != nil {
+		if result != nil {
+			return gcp.UserErrorf("importing %s failed:\n%s", target, result.Stderr)
+		}
+		return gcp.InternalErrorf("importing %s: %v", target, err)
+	}
+	return nil
+}
@@ -25,8 +25,12 @@ import (
 	"github.com/Masterminds/semver"
 )
 
-// Match against ruby string example: ruby 2.6.7p450
-var rubyVersionRe = regexp.MustCompile(`^\s*ruby\s+([^p^\s]+)(p\d+)?\s*$`)
+// Match against ruby string examples: ruby 2.6.7p450, ruby 3.1.4p0 (jruby 9.4.5.0)
+var rubyVersionRe = regexp.MustCompile(`^\s*ruby\s+([^p^\s]+)(p\d+)?(?:\s+\((\w+)\s+([^\s)]+)\))?\s*$`)
+
+// Match against locked gem example: "    nokogiri (1.15.4-x86_64-linux)". Dependencies of the gems are
+// indented further and are not matched.
+var lockedGemRe = regexp.MustCompile(`^    ([\w.-]+) \(([^)]+)\)$`)
 
 // ParseRubyVersion extracts the version number from Gemfile.lock or gems.locked, returns an error in
 // case the version string is malformed.
@@ -47,6 +51,23 @@ func ParseRubyVersion(path string) (string, error) {
 	return "", gcp.UserErrorf("parsing ruby version %q", version)
 }
 
+// ParseRubyEngine extracts the Ruby engine and its version from Gemfile.lock or gems.locked, e.g.
+// jruby and 9.4.5.0. It returns empty strings for the default Ruby engine.
+func ParseRubyEngine(path string) (string, string, error) {
+	version, err := readLineAfter(path, "RUBY VERSION")
+	if err != nil {
+		return "", "", err
+	}
+	if version == "" {
+		return "", "", nil
+	}
+	matches := rubyVersionRe.FindStringSubmatch(version)
+	if matches == nil {
+		return "", "", gcp.UserErrorf("parsing ruby version %q", version)
+	}
+	return matches[3], matches[4], nil
+}
+
 // ParseBundlerVersion extacts the version of bundler from Gemfile.lock or gems.locked,
 // returns an error in case the version string is malformed.
 func ParseBundlerVersion(path string) (string, error) {
@@ -66,6 +87,29 @@ func ParseBundlerVersion(path string) (string, error) {
 	return fmt.Sprintf("%d.%d.%d", semver.Major(), semver.Minor(), semver.Patch()), nil
 }
 
+// ParseLockedGems returns the versions of the gems in the specs of Gemfile.lock or gems.locked,
+// keyed by gem name. Versions of platform-specific gems include the platform, e.g.
+// 1.15.4-x86_64-linux.
+func ParseLockedGems(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	gems := map[string]string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if m := lockedGemRe.FindStringSubmatch(scanner.Text()); m != nil {
+			gems[m[1]] = m[2]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return gems, nil
+}
+
 func readLineAfter(path string, token string) (string, error) {
 	file, err := os.Open(path)
 	if err != nil {
@@ -17,6 +17,7 @@ package ruby
 import (
 	"io/ioutil"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
@@ -55,7 +56,18 @@ RUBY VERSION
 		ruby 1.9.3 (jruby 1.6.7)
 `,
 			},
-			wantError: true,
+			want: "1.9.3",
+		},
+		{
+			name: "from Gemfile.lock with jruby patch level",
+			lockFile: lockFile{
+				name: "Gemfile.lock",
+				content: `
+RUBY VERSION
+   ruby 3.1.4p0 (jruby 9.4.5.0)
+`,
+			},
+			want: "3.1.4",
 		},
 		{
 			name: "invalid Gemfile.lock",
@@ -101,6 +113,52 @@ RUBY VERSION
 
 }
 
+func TestParseRubyEngine(t *testing.T) {
+	testCases := []struct {
+		name              string
+		content           string
+		wantEngine        string
+		wantEngineVersion string
+		wantError         bool
+	}{
+		{
+			name:              "jruby",
+			content:           "RUBY VERSION\n   ruby 3.1.4p0 (jruby 9.4.5.0)\n",
+			wantEngine:        "jruby",
+			wantEngineVersion: "9.4.5.0",
+		},
+		{
+			name:    "mri",
+			content: "RUBY VERSION\n   ruby 3.2.2p53\n",
+		},
+		{
+			name:    "no ruby version",
+			content: "BUNDLED WITH\n   2.4.19\n",
+		},
+		{
+			name:      "invalid",
+			content:   "RUBY VERSION\n   jruby\n",
+			wantError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "Gemfile.lock")
+			if err := ioutil.WriteFile(path, []byte(tc.content), 0644); err != nil {
+				t.Fatalf("writing file %s: %v", path, err)
+			}
+
+			engine, engineVersion, err := ParseRubyEngine(path)
+			if gotErr := err != nil; gotErr != tc.wantError {
+				t.Fatalf("ParseRubyEngine(%q) got error: %v, want error: %t", path, err, tc.wantError)
+			}
+			if engine != tc.wantEngine || engineVersion != tc.wantEngineVersion {
+				t.Errorf("ParseRubyEngine(%q) = (%q, %q), want (%q, %q)", path, engine, engineVersion, tc.wantEngine, tc.wantEngineVersion)
+			}
+		})
+	}
+}
+
 func TestParseBundlerVersion(t *testing.T) {
 
 	type lockFile struct {
@@ -188,3 +246,47 @@ BUNDLED WITH
 	}
 
 }
+
+func TestParseLockedGems(t *testing.T) {
+	lockFile := `GEM
+  remote: https://rubygems.org/
+  specs:
+    bootsnap (1.17.0)
+      msgpack (~> 1.2)
+    msgpack (1.7.2)
+    nokogiri (1.15.4-x86_64-linux)
+      racc (~> 1.4)
+    racc (1.7.1)
+
+PLATFORMS
+  x86_64-linux
+
+DEPENDENCIES
+  bootsnap
+  nokogiri (~> 1.15)
+
+RUBY VERSION
+   ruby 3.2.2p53
+
+BUNDLED WITH
+   2.4.19
+`
+	path := filepath.Join(t.TempDir(), "Gemfile.lock")
+	if err := ioutil.WriteFile(path, []byte(lockFile), 0644); err != nil {
+		t.Fatalf("writing file %s: %v", path, err)
+	}
+
+	got, err := ParseLockedGems(path)
+	if err != nil {
+		t.Fatalf("ParseLockedGems(%q) got error: %v", path, err)
+	}
+	want := map[string]string{
+		"bootsnap": "1.17.0",
+		"msgpack":  "1.7.2",
+		"nokogiri": "1.15.4-x86_64-linux",
+		"racc":     "1.7.1",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseLockedGems(%q) = %v, want %v", path, got, want)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
@@ -32,8 +33,57 @@ const defaultVersion = "3.1.*"
 // RubyVersionKey is the environment variable name used to store the Ruby version installed.
 const RubyVersionKey = "build_ruby_version"
 
-// DetectVersion detects ruby version from the environment, Gemfile.lock, gems.locked, or falls
-// back to a default version.
+// RubyEngineKey is the environment variable name used to store the Ruby engine installed, if it
+// is not the default Ruby engine.
+const RubyEngineKey = "build_ruby_engine"
+
+// JRuby is the name of the JRuby engine in the RUBY VERSION section of the lock files.
+const JRuby = "jruby"
+
+// DetectJRubyVersion returns the JRuby version declared in Gemfile.lock or gems.locked, e.g.
+// 9.4.5.0 for `ruby 3.1.4p0 (jruby 9.4.5.0)`, or an empty string if the application does not
+// declare JRuby.
+func DetectJRubyVersion(ctx *gcp.Context) (string, error) {
+	for _, lockFileName := range []string{"Gemfile.lock", "gems.locked"} {
+		path := filepath.Join(ctx.ApplicationRoot(), lockFileName)
+		exists, err := ctx.FileExists(path)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			continue
+		}
+		engine, engineVersion, err := ParseRubyEngine(path)
+		if err != nil {
+			return "", gcp.UserErrorf("Error %q in: %s", err, lockFileName)
+		}
+		if engine == JRuby {
+			return engineVersion, nil
+		}
+		if engine != "" {
+			return "", gcp.UserErrorf("Ruby engine %q in %s is not supported", engine, lockFileName)
+		}
+		return "", nil
+	}
+	return "", nil
+}
+
+// IsJRuby returns true if the build environment has JRuby installed.
+func IsJRuby() bool {
+	return os.Getenv(RubyEngineKey) == JRuby
+}
+
+// versionFiles are the files of Ruby version managers that may specify the Ruby version, in order
+// of precedence.
+var versionFiles = []string{".ruby-version", ".tool-versions"}
+
+// rubyVersionFileRe matches the version in a .ruby-version file, with an optional ruby- prefix,
+// e.g. 3.2.2 or ruby-3.2.2.
+var rubyVersionFileRe = regexp.MustCompile(`^(?:ruby-)?(\d+(?:\.\d+){0,2})(?:-p\d+)?$`)
+
+// DetectVersion detects ruby version from the environment, Gemfile.lock, gems.locked,
+// .ruby-version, .tool-versions, or falls back to a default version. The version in the lock files
+// takes precedence, since Bundler refuses to run with a different version.
 func DetectVersion(ctx *gcp.Context) (string, error) {
 	versionFromEnv := os.Getenv(env.RuntimeVersion)
 	// The two lock files have the same format for Ruby version
@@ -75,6 +125,8 @@ func DetectVersion(ctx *gcp.Context) (string, error) {
 					"Ruby version %q in %s can't be overriden to %q using %s environment variable",
 					lockedVersion, lockFileName, versionFromEnv, env.RuntimeVersion)
 			}
+			warnIfVersionFileDiffers(ctx, lockedVersion, lockFileName)
+			ctx.Logf("Using Ruby version %s from %s.", lockedVersion, lockFileName)
 			return lockedVersion, err
 		}
 	}
@@ -85,9 +137,98 @@ func DetectVersion(ctx *gcp.Context) (string, error) {
 		return versionFromEnv, nil
 	}
 
+	version, source, err := versionFromFiles(ctx)
+	if err != nil {
+		return "", err
+	}
+	if version != "" {
+		ctx.Logf("Using Ruby version %s from %s.", version, source)
+		return version, nil
+	}
+
 	return defaultVersion, nil
 }
 
+// versionFromFiles returns the Ruby version in the first version manager file that specifies one,
+// and the name of the file.
+func versionFromFiles(ctx *gcp.Context) (string, string, error) {
+	for _, name := range versionFiles {
+		path := filepath.Join(ctx.ApplicationRoot(), name)
+		exists, err := ctx.FileExists(path)
+		if err != nil {
+			return "", "", err
+		}
+		if !exists {
+			continue
+		}
+		content, err := ctx.ReadFile(path)
+		if err != nil {
+			return "", "", err
+		}
+		var version string
+		if name == ".tool-versions" {
+			version, err = parseToolVersions(string(content))
+		} else {
+			version, err = parseRubyVersionFile(string(content))
+		}
+		if err != nil {
+			return "", "", gcp.UserErrorf("parsing %s: %v", name, err)
+		}
+		if version != "" {
+			return version, name, nil
+		}
+	}
+	return "", "", nil
+}
+
+// warnIfVersionFileDiffers warns if a version manager file specifies a different Ruby version than
+// the lock file, which is used. Invalid version manager files are ignored, since they are not used.
+func warnIfVersionFileDiffers(ctx *gcp.Context, lockedVersion, lockFileName string) {
+	version, source, err := versionFromFiles(ctx)
+	if err != nil {
+		ctx.Debugf("Ignoring the Ruby version manager files: %v", err)
+		return
+	}
+	if version != "" && version != lockedVersion && !strings.HasPrefix(lockedVersion, strings.TrimSuffix(version, "*")) {
+		ctx.Warnf("Ruby version %q in %s differs from version %q in %s, using the version in %s.", version, source, lockedVersion, lockFileName, lockFileName)
+	}
+}
+
+// parseRubyVersionFile returns the version constraint for the version in a .ruby-version file,
+// e.g. 3.2.2 for ruby-3.2.2 and 3.2.* for 3.2.
+func parseRubyVersionFile(content string) (string, error) {
+	line := strings.TrimSpace(strings.SplitN(content, "\n", 2)[0])
+	if line == "" {
+		return "", nil
+	}
+	return versionConstraint(line)
+}
+
+// parseToolVersions returns the version constraint for the ruby entry of an asdf .tool-versions
+// file, e.g. `ruby 3.2.2`. The first version of the entry is used, the others are fallbacks for
+// asdf.
+func parseToolVersions(content string) (string, error) {
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(strings.SplitN(line, "#", 2)[0])
+		if len(fields) >= 2 && fields[0] == "ruby" {
+			return versionConstraint(fields[1])
+		}
+	}
+	return "", nil
+}
+
+// versionConstraint returns the version constraint for a Ruby version of a version manager.
+func versionConstraint(v string) (string, error) {
+	m := rubyVersionFileRe.FindStringSubmatch(v)
+	if m == nil {
+		return "", fmt.Errorf("unsupported Ruby version %q, it must be a version number such as 3.2.2, JRuby must be declared in the RUBY VERSION section of Gemfile.lock", v)
+	}
+	if strings.Count(m[1], ".") < 2 {
+		return m[1] + ".*", nil
+	}
+	return m[1], nil
+}
+
 // IsRuby25 returns true if the build environment has Ruby 2.5.x installed.
 func IsRuby25(ctx *gcp.Context) bool {
 	return strings.HasPrefix(os.Getenv(RubyVersionKey), "2.5")
@@ -54,6 +54,30 @@ RUBY VERSION
 			},
 			want: "2.5.7",
 		},
+		{
+			name: "from Gemfile.lock with jruby",
+			lockFiles: []lockFile{
+				lockFile{
+					name: "Gemfile.lock",
+					content: `
+RUBY VERSION
+   ruby 3.1.4p0 (jruby 9.4.5.0)
+`},
+			},
+			want: "3.1.4",
+		},
+		{
+			name: "from gems.locked with jruby",
+			lockFiles: []lockFile{
+				lockFile{
+					name: "gems.locked",
+					content: `
+RUBY VERSION
+		ruby 1.9.3 (jruby 1.6.7)
+`},
+			},
+			want: "1.9.3",
+		},
 		{
 			name:       "from Gemfile.lock with same version on env",
 			runtimeEnv: "3.0.1",
@@ -201,18 +225,6 @@ RUBY VERSION
 			},
 			errorContent: "Ruby version \"2.5.7\" in Gemfile.lock can't be overriden to \"3.0.1\" using GOOGLE_RUNTIME_VERSION environment variable",
 		},
-		{
-			name: "from Gemfile.lock with jruby",
-			lockFiles: []lockFile{
-				lockFile{
-					name: "Gemfile.lock",
-					content: `
-RUBY VERSION
-		ruby 1.9.3 (jruby 1.6.7)
-`,
-				},
-			},
-		},
 		{
 			name: "invalid Gemfile.lock",
 			lockFiles: []lockFile{
@@ -221,18 +233,6 @@ RUBY VERSION
 					content: `
 RUBY VERSION
 		809809ruby 2.5.7p206adasdada
-`,
-				},
-			},
-		},
-		{
-			name: "from gems.locked with jruby",
-			lockFiles: []lockFile{
-				lockFile{
-					name: "gems.locked",
-					content: `
-RUBY VERSION
-		ruby 1.9.3 (jruby 1.6.7)
 `,
 				},
 			},
@@ -282,6 +282,150 @@ RUBY VERSION
 	}
 }
 
+func TestDetectVersionFromVersionFiles(t *testing.T) {
+	testCases := []struct {
+		name       string
+		runtimeEnv string
+		files      map[string]string
+		want       string
+		wantError  bool
+	}{
+		{
+			name:  "ruby-version",
+			files: map[string]string{".ruby-version": "3.2.2\n"},
+			want:  "3.2.2",
+		},
+		{
+			name:  "ruby-version with prefix",
+			files: map[string]string{".ruby-version": "ruby-3.1.4"},
+			want:  "3.1.4",
+		},
+		{
+			name:  "ruby-version with minor version",
+			files: map[string]string{".ruby-version": "3.2"},
+			want:  "3.2.*",
+		},
+		{
+			name:  "tool-versions",
+			files: map[string]string{".tool-versions": "nodejs 20.9.0\nruby 3.2.2 3.1.4 # fallback\n"},
+			want:  "3.2.2",
+		},
+		{
+			name:  "tool-versions without ruby",
+			files: map[string]string{".tool-versions": "nodejs 20.9.0\n"},
+			want:  defaultVersion,
+		},
+		{
+			name:  "ruby-version takes precedence over tool-versions",
+			files: map[string]string{".ruby-version": "3.1.4", ".tool-versions": "ruby 3.2.2"},
+			want:  "3.1.4",
+		},
+		{
+			name:       "environment takes precedence over ruby-version",
+			runtimeEnv: "3.0.6",
+			files:      map[string]string{".ruby-version": "3.1.4"},
+			want:       "3.0.6",
+		},
+		{
+			name: "lock file takes precedence over ruby-version",
+			files: map[string]string{
+				"Gemfile.lock":  "RUBY VERSION\n   ruby 3.2.2p53\n",
+				".ruby-version": "3.1.4",
+			},
+			want: "3.2.2",
+		},
+		{
+			name: "invalid ruby-version is ignored with a lock file version",
+			files: map[string]string{
+				"Gemfile.lock":  "RUBY VERSION\n   ruby 3.2.2p53\n",
+				".ruby-version": "jruby-9.4.5.0",
+			},
+			want: "3.2.2",
+		},
+		{
+			name:      "invalid ruby-version",
+			files:     map[string]string{".ruby-version": "jruby-9.4.5.0"},
+			wantError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(env.RuntimeVersion, tc.runtimeEnv)
+			tempRoot := t.TempDir()
+			for name, content := range tc.files {
+				path := filepath.Join(tempRoot, name)
+				if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatalf("writing file %s: %v", path, err)
+				}
+			}
+
+			got, err := DetectVersion(gcp.NewContext(gcp.WithApplicationRoot(tempRoot)))
+			if gotErr := err != nil; gotErr != tc.wantError {
+				t.Fatalf("DetectVersion(ctx) got error: %v, want error: %t", err, tc.wantError)
+			}
+			if got != tc.want {
+				t.Errorf("DetectVersion(ctx) = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDetectJRubyVersion(t *testing.T) {
+	testCases := []struct {
+		name      string
+		lockFile  string
+		content   string
+		want      string
+		wantError bool
+	}{
+		{
+			name:     "jruby in Gemfile.lock",
+			lockFile: "Gemfile.lock",
+			content:  "RUBY VERSION\n   ruby 3.1.4p0 (jruby 9.4.5.0)\n",
+			want:     "9.4.5.0",
+		},
+		{
+			name:     "jruby in gems.locked",
+			lockFile: "gems.locked",
+			content:  "RUBY VERSION\n   ruby 2.6.8 (jruby 9.3.13.0)\n",
+			want:     "9.3.13.0",
+		},
+		{
+			name:     "mri",
+			lockFile: "Gemfile.lock",
+			content:  "RUBY VERSION\n   ruby 3.2.2p53\n",
+		},
+		{
+			name:      "unsupported engine",
+			lockFile:  "Gemfile.lock",
+			content:   "RUBY VERSION\n   ruby 3.0.2 (truffleruby 23.1.1)\n",
+			wantError: true,
+		},
+		{
+			name: "no lock file",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tempRoot := t.TempDir()
+			if tc.lockFile != "" {
+				path := filepath.Join(tempRoot, tc.lockFile)
+				if err := ioutil.WriteFile(path, []byte(tc.content), 0644); err != nil {
+					t.Fatalf("writing file %s: %v", path, err)
+				}
+			}
+
+			got, err := DetectJRubyVersion(gcp.NewContext(gcp.WithApplicationRoot(tempRoot)))
+			if gotErr := err != nil; gotErr != tc.wantError {
+				t.Fatalf("DetectJRubyVersion(ctx) got error: %v, want error: %t", err, tc.wantError)
+			}
+			if got != tc.want {
+				t.Errorf("DetectJRubyVersion(ctx) = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
 func TestSupportsBundler1(t *testing.T) {
 	testCases := []struct {
 		name        string
@@ -0,0 +1,241 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/fetch"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/buildpacks/libcnb"
+)
+
+var (
+	// dartArchiveURL is the directory of a release of the Dart SDK in a channel.
+	dartArchiveURL = "https://storage.googleapis.com/dart-archive/channels/%s/release/%s"
+	// dartReleasesURL lists the release directories of a channel of the Dart SDK archive.
+	dartReleasesURL = "https://storage.googleapis.com/storage/v1/b/dart-archive/o?delimiter=/&prefix=channels/%s/release/"
+
+	// dartVersionRegexp matches the versions of the Dart SDK, e.g. 3.1.5 and 3.2.0-210.3.beta.
+	dartVersionRegexp = regexp.MustCompile(`^\d+\.\d+\.\d+(-[0-9A-Za-z.]+)?$`)
+)
+
+const (
+	dartSDKArchive = "sdk/dartsdk-linux-x64-release.zip"
+	// maxNearVersions is the number of versions suggested when a version is not found.
+	maxNearVersions = 5
+)
+
+// dartReleaseList is a page of the listing of the release directories of a Dart SDK channel.
+type dartReleaseList struct {
+	Prefixes      []string `json:"prefixes"`
+	NextPageToken string   `json:"nextPageToken"`
+}
+
+// DartChannel returns the channel of the Dart SDK archive that a version is released on, e.g.
+// beta for 3.2.0-210.3.beta.
+func DartChannel(version string) string {
+	switch {
+	case strings.HasSuffix(version, ".beta"):
+		return "beta"
+	case strings.HasSuffix(version, ".dev"):
+		return "dev"
+	default:
+		return "stable"
+	}
+}
+
+// InstallDartSDK downloads a given version of the dart SDK to the specified layer. The version
+// must be released on its channel of the Dart SDK archive, and the download is verified with the
+// SHA256 checksum published with the release.
+func InstallDartSDK(ctx *gcp.Context, layer *libcnb.Layer, version string) error {
+	channel := DartChannel(version)
+	versions, err := dartVersions(channel)
+	if err != nil {
+		return err
+	}
+	if err := checkDartVersion(version, channel, versions); err != nil {
+		return err
+	}
+
+	if err := ctx.ClearLayer(layer); err != nil {
+		return fmt.Errorf("clearing layer %q: %w", layer.Name, err)
+	}
+	releaseURL := fmt.Sprintf(dartArchiveURL, channel, version)
+	sdkURL := releaseURL + "/" + dartSDKArchive
+
+	zip, err := ioutil.TempFile(layer.Path, "dart-sdk-*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(zip.Name())
+	defer zip.Close()
+
+	h := sha256.New()
+	if err := fetch.GetURL(sdkURL, io.MultiWriter(zip, h)); err != nil {
+		return err
+	}
+	if err := verifyDartSDK(sdkURL, hex.EncodeToString(h.Sum(nil))); err != nil {
+		return err
+	}
+
+	if _, err := ctx.Exec([]string{"unzip", "-q", zip.Name(), "-d", layer.Path}); err != nil {
+		return fmt.Errorf("extracting Dart SDK: %v", err)
+	}
+
+	// Once extracted the SDK contents are in a subdirectory called "dart-sdk". We move everything up
+	// one level so "bin" and "lib" end up in the layer path.
+	files, err := ioutil.ReadDir(path.Join(layer.Path, "dart-sdk"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		op := path.Join(layer.Path, "dart-sdk", file.Name())
+		np := path.Join(layer.Path, file.Name())
+		if err := os.Rename(op, np); err != nil {
+			return err
+		}
+	}
+
+	ctx.SetMetadata(layer, stackKey, ctx.StackID())
+	ctx.SetMetadata(layer, versionKey, version)
+
+	return nil
+}
+
+// verifyDartSDK compares the SHA256 checksum of the downloaded SDK archive to the one published in
+// the .sha256sum file next to it, e.g. `<checksum> *dartsdk-linux-x64-release.zip`.
+func verifyDartSDK(sdkURL, checksum string) error {
+	var b bytes.Buffer
+	if err := fetch.GetURL(sdkURL+".sha256sum", &b); err != nil {
+		return err
+	}
+	fields := strings.Fields(b.String())
+	if len(fields) == 0 {
+		return gcp.InternalErrorf("invalid checksum file for %s: %q", sdkURL, b.String())
+	}
+	if !strings.EqualFold(fields[0], checksum) {
+		return gcp.InternalErrorf("verifying Dart SDK archive %s: got SHA256 %s, want %s", sdkURL, checksum, fields[0])
+	}
+	return nil
+}
+
+// dartVersions returns the versions of the Dart SDK released on the channel.
+func dartVersions(channel string) ([]string, error) {
+	listURL := fmt.Sprintf(dartReleasesURL, url.QueryEscape(channel))
+	var versions []string
+	pageToken := ""
+	for {
+		u := listURL
+		if pageToken != "" {
+			u += "&pageToken=" + url.QueryEscape(pageToken)
+		}
+		var list dartReleaseList
+		if err := fetch.JSON(u, &list); err != nil {
+			return nil, err
+		}
+		for _, p := range list.Prefixes {
+			v := path.Base(p)
+			// The channels also contain directories named after SVN revisions and "latest".
+			if dartVersionRegexp.MatchString(v) {
+				versions = append(versions, v)
+			}
+		}
+		if list.NextPageToken == "" {
+			return versions, nil
+		}
+		pageToken = list.NextPageToken
+	}
+}
+
+// checkDartVersion returns an error listing the nearest released versions if the version is not
+// released on the channel.
+func checkDartVersion(version, channel string, versions []string) error {
+	for _, v := range versions {
+		if v == version {
+			return nil
+		}
+	}
+	near := nearVersions(version, versions)
+	if len(near) == 0 {
+		return gcp.UserErrorf("Dart SDK version %q not found in the %s channel, see https://dart.dev/get-dart/archive for the available versions", version, channel)
+	}
+	return gcp.UserErrorf("Dart SDK version %q not found in the %s channel, did you mean one of: %s", version, channel, strings.Join(near, ", "))
+}
+
+// nearVersions returns the versions that are the most similar to the requested version, by edit
+// distance, most similar first.
+func nearVersions(version string, versions []string) []string {
+	type candidate struct {
+		version  string
+		distance int
+	}
+	var candidates []candidate
+	for _, v := range versions {
+		// Versions that differ in more than half of their characters are not suggested.
+		if d := editDistance(version, v); d <= (len(version)+1)/2 {
+			candidates = append(candidates, candidate{version: v, distance: d})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].version < candidates[j].version
+	})
+	var near []string
+	for i := 0; i < len(candidates) && i < maxNearVersions; i++ {
+		near = append(near, candidates[i].version)
+	}
+	return near
+}
+
+// editDistance returns the Levenshtein distance between two strings.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if d := prev[j] + 1; d < cur[j] {
+				cur[j] = d
+			}
+			if d := cur[j-1] + 1; d < cur[j] {
+				cur[j] = d
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
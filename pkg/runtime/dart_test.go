@@ -0,0 +1,160 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/testdata"
+	"github.com/buildpacks/libcnb"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDartChannel(t *testing.T) {
+	testCases := []struct {
+		version string
+		want    string
+	}{
+		{version: "3.1.5", want: "stable"},
+		{version: "3.2.0-210.3.beta", want: "beta"},
+		{version: "3.3.0-1.0.dev", want: "dev"},
+	}
+	for _, tc := range testCases {
+		if got := DartChannel(tc.version); got != tc.want {
+			t.Errorf("DartChannel(%q) = %q, want %q", tc.version, got, tc.want)
+		}
+	}
+}
+
+func TestNearVersions(t *testing.T) {
+	versions := []string{"2.19.6", "3.0.0", "3.0.7", "3.1.0", "3.1.4", "3.1.5"}
+	testCases := []struct {
+		version string
+		want    []string
+	}{
+		{version: "3.1.50", want: []string{"3.1.0", "3.1.5", "3.0.0", "3.1.4", "3.0.7"}},
+		{version: "2.19.60", want: []string{"2.19.6", "3.1.0", "3.0.0", "3.1.4", "3.1.5"}},
+		{version: "31.5", want: []string{"3.1.5", "3.1.0", "3.1.4"}},
+		{version: "latest", want: nil},
+	}
+	for _, tc := range testCases {
+		if diff := cmp.Diff(tc.want, nearVersions(tc.version, versions)); diff != "" {
+			t.Errorf("nearVersions(%q) mismatch (-want +got):\n%s", tc.version, diff)
+		}
+	}
+}
+
+func TestInstallDartSDK(t *testing.T) {
+	sdk, err := ioutil.ReadFile(testdata.MustGetPath("testdata/dummy-dart-sdk.zip"))
+	if err != nil {
+		t.Fatalf("reading SDK archive: %v", err)
+	}
+	sum := sha256.Sum256(sdk)
+	checksum := hex.EncodeToString(sum[:])
+
+	testCases := []struct {
+		name      string
+		version   string
+		archive   []byte
+		checksum  string
+		wantFile  string
+		wantError bool
+	}{
+		{
+			name:     "successful install",
+			version:  "2.15.1",
+			archive:  sdk,
+			checksum: checksum,
+			wantFile: "lib/foo.txt",
+		},
+		{
+			name:      "unknown version",
+			version:   "2.15.10",
+			wantError: true,
+		},
+		{
+			name:      "checksum mismatch",
+			version:   "2.15.1",
+			archive:   sdk,
+			checksum:  "0123",
+			wantError: true,
+		},
+		{
+			name:      "corrupt zip file",
+			version:   "2.15.1",
+			archive:   []byte("corrupt"),
+			checksum:  fmt.Sprintf("%x", sha256.Sum256([]byte("corrupt"))),
+			wantError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/list", func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("pageToken") == "" {
+					fmt.Fprint(w, `{"prefixes": ["channels/stable/release/2.14.4/", "channels/stable/release/latest/"], "nextPageToken": "next"}`)
+					return
+				}
+				fmt.Fprint(w, `{"prefixes": ["channels/stable/release/2.15.1/", "channels/stable/release/30188/"]}`)
+			})
+			mux.HandleFunc("/channels/stable/release/2.15.1/sdk/dartsdk-linux-x64-release.zip", func(w http.ResponseWriter, r *http.Request) {
+				w.Write(tc.archive)
+			})
+			mux.HandleFunc("/channels/stable/release/2.15.1/sdk/dartsdk-linux-x64-release.zip.sha256sum", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, "%s *dartsdk-linux-x64-release.zip\n", tc.checksum)
+			})
+			svr := httptest.NewServer(mux)
+			t.Cleanup(svr.Close)
+			origArchive, origReleases := dartArchiveURL, dartReleasesURL
+			t.Cleanup(func() { dartArchiveURL, dartReleasesURL = origArchive, origReleases })
+			dartArchiveURL = svr.URL + "/channels/%s/release/%s"
+			dartReleasesURL = svr.URL + "/list?prefix=channels/%s/release/"
+
+			ctx := gcp.NewContext()
+			l := &libcnb.Layer{
+				Path:     t.TempDir(),
+				Metadata: map[string]interface{}{},
+			}
+			err := InstallDartSDK(ctx, l, tc.version)
+
+			if tc.wantError && err == nil {
+				t.Fatalf("Expecting error but got nil")
+			}
+			if !tc.wantError && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tc.wantFile != "" {
+				fp := filepath.Join(l.Path, tc.wantFile)
+				if _, err := os.Stat(fp); err != nil {
+					t.Errorf("Failed to extract. Missing file: %s (%v)", fp, err)
+				}
+				if l.Metadata["version"] != tc.version {
+					t.Errorf("Layer Metadata.version = %q, want %q", l.Metadata["version"], tc.version)
+				}
+			}
+		})
+	}
+}
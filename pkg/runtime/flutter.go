@@ -0,0 +1,129 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/fetch"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/buildpacks/libcnb"
+)
+
+var flutterReleasesURL = "https://storage.googleapis.com/flutter_infra_release/releases/releases_linux.json"
+
+// flutterStableChannel is the channel of the Flutter releases that are installed by default.
+const flutterStableChannel = "stable"
+
+// flutterReleases is the index of the Flutter SDK releases for Linux.
+type flutterReleases struct {
+	BaseURL        string            `json:"base_url"`
+	CurrentRelease map[string]string `json:"current_release"`
+	Releases       []flutterRelease  `json:"releases"`
+}
+
+// flutterRelease is a release of the Flutter SDK.
+type flutterRelease struct {
+	Hash    string `json:"hash"`
+	Channel string `json:"channel"`
+	Version string `json:"version"`
+	Archive string `json:"archive"`
+	SHA256  string `json:"sha256"`
+}
+
+// InstallFlutterSDKIfNotCached installs the given version of the Flutter SDK into the layer, or
+// the current stable release if the version is empty. The Flutter SDK includes the Dart SDK.
+// Returns the installed version and true if a cached layer is used.
+func InstallFlutterSDKIfNotCached(ctx *gcp.Context, layer *libcnb.Layer, version string) (string, bool, error) {
+	var releases flutterReleases
+	if err := fetch.JSON(flutterReleasesURL, &releases); err != nil {
+		return "", false, err
+	}
+	release, err := findFlutterRelease(releases, version)
+	if err != nil {
+		return "", false, err
+	}
+	ctx.AddBOMEntry(libcnb.BOMEntry{
+		Name:     "flutter",
+		Metadata: map[string]interface{}{"version": release.Version},
+		Build:    true,
+	})
+	if IsCached(ctx, layer, release.Version) {
+		return release.Version, true, nil
+	}
+	ctx.Logf("Installing Flutter SDK v%s.", release.Version)
+	if err := ctx.ClearLayer(layer); err != nil {
+		return "", false, fmt.Errorf("clearing layer %q: %w", layer.Name, err)
+	}
+
+	archive, err := ioutil.TempFile("", "flutter-sdk-*.tar.xz")
+	if err != nil {
+		return "", false, gcp.InternalErrorf("creating temporary file: %v", err)
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	// The SHA256 of the archive is published with the release, verify the archive while downloading.
+	h := sha256.New()
+	archiveURL := strings.TrimSuffix(releases.BaseURL, "/") + "/" + release.Archive
+	if err := fetch.GetURL(archiveURL, io.MultiWriter(archive, h)); err != nil {
+		return "", false, err
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(got, release.SHA256) {
+		return "", false, gcp.InternalErrorf("verifying Flutter SDK archive %s: got SHA256 %s, want %s", archiveURL, got, release.SHA256)
+	}
+
+	// The archive contains the SDK in a directory called "flutter", which is stripped so that "bin"
+	// ends up in the layer path.
+	if _, err := ctx.Exec([]string{"tar", "-xJf", archive.Name(), "-C", layer.Path, "--strip-components=1"}); err != nil {
+		return "", false, fmt.Errorf("extracting Flutter SDK: %w", err)
+	}
+
+	ctx.SetMetadata(layer, stackKey, ctx.StackID())
+	ctx.SetMetadata(layer, versionKey, release.Version)
+	return release.Version, false, nil
+}
+
+// findFlutterRelease returns the release of the given version, or the current stable release if
+// the version is empty.
+func findFlutterRelease(releases flutterReleases, version string) (flutterRelease, error) {
+	if version == "" {
+		hash := releases.CurrentRelease[flutterStableChannel]
+		for _, r := range releases.Releases {
+			if r.Hash == hash && r.Channel == flutterStableChannel {
+				return r, nil
+			}
+		}
+		return flutterRelease{}, gcp.InternalErrorf("finding the current stable Flutter SDK release %q", hash)
+	}
+	// Releases are listed from the newest, and a version can be released on several channels.
+	for _, r := range releases.Releases {
+		if r.Version == version && r.Channel == flutterStableChannel {
+			return r, nil
+		}
+	}
+	for _, r := range releases.Releases {
+		if r.Version == version {
+			return r, nil
+		}
+	}
+	return flutterRelease{}, gcp.UserErrorf("Flutter SDK version %q not found, see https://docs.flutter.dev/release/archive for the available versions", version)
+}
@@ -0,0 +1,140 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/buildpacks/internal/testserver"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/buildpacks/libcnb"
+)
+
+func TestFindFlutterRelease(t *testing.T) {
+	releases := flutterReleases{
+		CurrentRelease: map[string]string{"stable": "b", "beta": "c"},
+		Releases: []flutterRelease{
+			{Hash: "c", Channel: "beta", Version: "3.14.0-0.2.pre"},
+			{Hash: "b", Channel: "stable", Version: "3.13.9"},
+			{Hash: "b", Channel: "beta", Version: "3.13.9"},
+			{Hash: "a", Channel: "stable", Version: "3.13.8"},
+		},
+	}
+	testCases := []struct {
+		name        string
+		version     string
+		wantHash    string
+		wantChannel string
+		wantErr     bool
+	}{
+		{name: "current stable", wantHash: "b", wantChannel: "stable"},
+		{name: "stable version", version: "3.13.8", wantHash: "a", wantChannel: "stable"},
+		{name: "prefers stable channel", version: "3.13.9", wantHash: "b", wantChannel: "stable"},
+		{name: "beta version", version: "3.14.0-0.2.pre", wantHash: "c", wantChannel: "beta"},
+		{name: "unknown version", version: "3.13.10", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := findFlutterRelease(releases, tc.version)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("findFlutterRelease(%q) got error: %v, want error: %v", tc.version, err, tc.wantErr)
+			}
+			if got.Hash != tc.wantHash || got.Channel != tc.wantChannel {
+				t.Errorf("findFlutterRelease(%q) = %+v, want hash %q on channel %q", tc.version, got, tc.wantHash, tc.wantChannel)
+			}
+		})
+	}
+}
+
+func TestInstallFlutterSDKIfNotCached(t *testing.T) {
+	archive := flutterArchive(t)
+	content, err := ioutil.ReadFile(archive)
+	if err != nil {
+		t.Fatalf("reading archive: %v", err)
+	}
+	sum := sha256.Sum256(content)
+	testCases := []struct {
+		name       string
+		sha256     string
+		cached     string
+		wantCached bool
+		wantErr    bool
+	}{
+		{name: "successful install", sha256: hex.EncodeToString(sum[:])},
+		{name: "checksum mismatch", sha256: "0123", wantErr: true},
+		{name: "cached", sha256: hex.EncodeToString(sum[:]), cached: "3.13.9", wantCached: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			archiveServer := testserver.New(t, testserver.WithFile(archive))
+			testserver.New(t, testserver.WithJSON(fmt.Sprintf(`{
+				"base_url": %q,
+				"current_release": {"stable": "b"},
+				"releases": [{"hash": "b", "channel": "stable", "version": "3.13.9", "archive": "stable/linux/flutter_linux_3.13.9-stable.tar.xz", "sha256": %q}]
+			}`, archiveServer.URL, tc.sha256)), testserver.WithMockURL(&flutterReleasesURL))
+
+			ctx := gcp.NewContext()
+			l := &libcnb.Layer{Path: t.TempDir(), Metadata: map[string]interface{}{}}
+			if tc.cached != "" {
+				ctx.SetMetadata(l, versionKey, tc.cached)
+				ctx.SetMetadata(l, stackKey, ctx.StackID())
+			}
+
+			version, cached, err := InstallFlutterSDKIfNotCached(ctx, l, "")
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("InstallFlutterSDKIfNotCached() got error: %v, want error: %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if version != "3.13.9" {
+				t.Errorf("InstallFlutterSDKIfNotCached() version = %q, want %q", version, "3.13.9")
+			}
+			if cached != tc.wantCached {
+				t.Errorf("InstallFlutterSDKIfNotCached() cached = %v, want %v", cached, tc.wantCached)
+			}
+			if !tc.wantCached {
+				if _, err := os.Stat(filepath.Join(l.Path, "bin", "flutter")); err != nil {
+					t.Errorf("Failed to extract. Missing file bin/flutter: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// flutterArchive creates an archive that is laid out like a Flutter SDK release.
+func flutterArchive(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "flutter", "bin")
+	if err := os.MkdirAll(bin, 0755); err != nil {
+		t.Fatalf("creating %s: %v", bin, err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(bin, "flutter"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatalf("writing flutter: %v", err)
+	}
+	archive := filepath.Join(t.TempDir(), "flutter.tar.xz")
+	if out, err := exec.Command("tar", "-cJf", archive, "-C", dir, "flutter").CombinedOutput(); err != nil {
+		t.Fatalf("creating archive: %v\n%s", err, out)
+	}
+	return archive
+}
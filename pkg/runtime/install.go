@@ -16,9 +16,6 @@ package runtime
 
 import (
 	"fmt"
-	"io/ioutil"
-	"os"
-	"path"
 	"path/filepath"
 	"strings"
 
@@ -29,9 +26,9 @@ import (
 )
 
 var (
-	dartSdkURL         = "https://storage.googleapis.com/dart-archive/channels/stable/release/%s/sdk/dartsdk-linux-x64-release.zip"
 	googleTarballURL   = "https://dl.google.com/runtimes/%s/%[2]s/%[2]s-%s.tar.gz"
 	runtimeVersionsURL = "https://dl.google.com/runtimes/%s/%s/version.json"
+	jrubyURL           = "https://repo1.maven.org/maven2/org/jruby/jruby-dist/%[1]s/jruby-dist-%[1]s-bin.tar.gz"
 )
 
 // InstallableRuntime is used to hold runtimes information
@@ -92,48 +89,6 @@ func IsCached(ctx *gcp.Context, layer *libcnb.Layer, version string) bool {
 	return metaVersion == version && metaStack == ctx.StackID()
 }
 
-// InstallDartSDK downloads a given version of the dart SDK to the specified layer.
-func InstallDartSDK(ctx *gcp.Context, layer *libcnb.Layer, version string) error {
-	if err := ctx.ClearLayer(layer); err != nil {
-		return fmt.Errorf("clearing layer %q: %w", layer.Name, err)
-	}
-	sdkURL := fmt.Sprintf(dartSdkURL, version)
-
-	zip, err := ioutil.TempFile(layer.Path, "dart-sdk-*.zip")
-	if err != nil {
-		return err
-	}
-	defer os.Remove(zip.Name())
-
-	if err := fetch.GetURL(sdkURL, zip); err != nil {
-		ctx.Warnf("Failed to download Dart SDK from %s. You can specify the verison by setting the GOOGLE_RUNTIME_VERSION environment variable", sdkURL)
-		return err
-	}
-
-	if _, err := ctx.Exec([]string{"unzip", "-q", zip.Name(), "-d", layer.Path}); err != nil {
-		return fmt.Errorf("extracting Dart SDK: %v", err)
-	}
-
-	// Once extracted the SDK contents are in a subdirectory called "dart-sdk". We move everything up
-	// one level so "bin" and "lib" end up in the layer path.
-	files, err := ioutil.ReadDir(path.Join(layer.Path, "dart-sdk"))
-	if err != nil {
-		return err
-	}
-	for _, file := range files {
-		op := path.Join(layer.Path, "dart-sdk", file.Name())
-		np := path.Join(layer.Path, file.Name())
-		if err := os.Rename(op, np); err != nil {
-			return err
-		}
-	}
-
-	ctx.SetMetadata(layer, stackKey, ctx.StackID())
-	ctx.SetMetadata(layer, versionKey, version)
-
-	return nil
-}
-
 // InstallTarballIfNotCached installs a runtime tarball hosted on dl.google.com into the provided layer
 // with caching.
 // Returns true if a cached layer is used.
@@ -190,6 +145,37 @@ func InstallTarballIfNotCached(ctx *gcp.Context, runtime InstallableRuntime, ver
 	return false, nil
 }
 
+// InstallJRubyIfNotCached downloads a given version of JRuby from Maven Central into the provided
+// layer with caching. JRuby runs on the JVM, which must be installed separately.
+// Returns true if a cached layer is used.
+func InstallJRubyIfNotCached(ctx *gcp.Context, layer *libcnb.Layer, version string) (bool, error) {
+	ctx.AddBOMEntry(libcnb.BOMEntry{
+		Name:     "jruby",
+		Metadata: map[string]interface{}{"version": version},
+		Launch:   true,
+		Build:    true,
+	})
+	if layer.Cache {
+		if IsCached(ctx, layer, version) {
+			ctx.CacheHit("jruby")
+			ctx.Logf("JRuby v%s cache hit, skipping installation.", version)
+			return true, nil
+		}
+		ctx.CacheMiss("jruby")
+	}
+	if err := ctx.ClearLayer(layer); err != nil {
+		return false, gcp.InternalErrorf("clearing layer %q: %w", layer.Name, err)
+	}
+	ctx.Logf("Installing JRuby v%s.", version)
+	if err := fetch.Tarball(fmt.Sprintf(jrubyURL, version), layer.Path, 1); err != nil {
+		ctx.Warnf("Failed to download JRuby version %s. Check the JRuby version in the RUBY VERSION section of Gemfile.lock", version)
+		return false, err
+	}
+	ctx.SetMetadata(layer, stackKey, ctx.StackID())
+	ctx.SetMetadata(layer, versionKey, version)
+	return false, nil
+}
+
 // PinGemAndBundlerVersion pins the RubyGems versions for GAE and GCF runtime versions to prevent
 // unexpected behaviors with new versions. This is only expected to be called if the target
 // platform is GAE or GCF.
@@ -252,11 +238,9 @@ func ResolveVersion(runtime InstallableRuntime, verConstraint, os string) (strin
 		return verConstraint, nil
 	}
 
-	url := fmt.Sprintf(runtimeVersionsURL, os, runtime)
-
-	var versions []string
-	if err := fetch.JSON(url, &versions); err != nil {
-		return "", gcp.InternalErrorf("fetching %s versions %s os: %v", runtimeNames[runtime], os, err)
+	versions, err := fetchVersions(runtime, os)
+	if err != nil {
+		return "", err
 	}
 
 	v, err := version.ResolveVersion(verConstraint, versions)
@@ -265,3 +249,24 @@ func ResolveVersion(runtime InstallableRuntime, verConstraint, os string) (strin
 	}
 	return v, nil
 }
+
+// AvailableVersions returns the versions of a runtime hosted on dl.google.com for the OS of the
+// stack.
+func AvailableVersions(ctx *gcp.Context, runtime InstallableRuntime) ([]string, error) {
+	os, ok := stackToOS[ctx.StackID()]
+	if !ok {
+		ctx.Warnf("unknown stack ID %q, falling back to Ubuntu 18.04", ctx.StackID())
+		os = ubuntu1804
+	}
+	return fetchVersions(runtime, os)
+}
+
+// fetchVersions returns the versions of a runtime hosted on dl.google.com for the OS.
+func fetchVersions(runtime InstallableRuntime, os string) ([]string, error) {
+	url := fmt.Sprintf(runtimeVersionsURL, os, runtime)
+	var versions []string
+	if err := fetch.JSON(url, &versions); err != nil {
+		return nil, gcp.InternalErrorf("fetching %s versions %s os: %v", runtimeNames[runtime], os, err)
+	}
+	return versions, nil
+}
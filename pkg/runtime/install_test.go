@@ -32,66 +32,77 @@ import (
 	"github.com/buildpacks/libcnb"
 )
 
-func TestInstallDartSDK(t *testing.T) {
+func TestInstallJRubyIfNotCached(t *testing.T) {
 	testCases := []struct {
 		name         string
 		httpStatus   int
 		responseFile string
+		cachedVer    string
 		wantFile     string
+		wantCached   bool
 		wantError    bool
 	}{
 		{
 			name:         "successful install",
-			responseFile: "testdata/dummy-dart-sdk.zip",
+			responseFile: "testdata/dummy-ruby-runtime.tar.gz",
 			wantFile:     "lib/foo.txt",
 		},
 		{
-			name:       "invalid version",
-			httpStatus: http.StatusNotFound,
-			wantError:  true,
+			name:         "cached install",
+			responseFile: "testdata/dummy-ruby-runtime.tar.gz",
+			cachedVer:    "9.4.5.0",
+			wantCached:   true,
 		},
 		{
-			name:       "corrupt zip file",
-			httpStatus: http.StatusOK,
+			name:         "different cached version",
+			responseFile: "testdata/dummy-ruby-runtime.tar.gz",
+			cachedVer:    "9.3.13.0",
+			wantFile:     "bin/bar.txt",
+		},
+		{
+			name:       "not found",
+			httpStatus: http.StatusNotFound,
 			wantError:  true,
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			ctx := gcp.NewContext()
-			l := &libcnb.Layer{
-				Path:     t.TempDir(),
-				Metadata: map[string]interface{}{},
-			}
 			testserver.New(
 				t,
 				testserver.WithStatus(tc.httpStatus),
 				testserver.WithFile(testdata.MustGetPath(tc.responseFile)),
-				testserver.WithMockURL(&dartSdkURL))
+				testserver.WithMockURL(&jrubyURL))
 
-			version := "2.15.1"
-			err := InstallDartSDK(ctx, l, version)
-
-			if tc.wantError && err == nil {
-				t.Fatalf("Expecting error but got nil")
+			layer := &libcnb.Layer{
+				Path:     t.TempDir(),
+				Metadata: map[string]interface{}{},
 			}
-			if !tc.wantError && err != nil {
-				t.Fatalf("unexpected error: %v", err)
+			layer.Cache = true
+			ctx := gcp.NewContext(gcp.WithStackID("google.22"))
+			if tc.cachedVer != "" {
+				ctx.SetMetadata(layer, versionKey, tc.cachedVer)
+				ctx.SetMetadata(layer, stackKey, "google.22")
+			}
+			version := "9.4.5.0"
+			isCached, err := InstallJRubyIfNotCached(ctx, layer, version)
+			if tc.wantError == (err == nil) {
+				t.Fatalf("InstallJRubyIfNotCached(ctx, %q) got error: %v, want error? %v", version, err, tc.wantError)
+			}
+			if isCached != tc.wantCached {
+				t.Errorf("InstallJRubyIfNotCached(ctx, %q) = %t, want %t", version, isCached, tc.wantCached)
 			}
-
 			if tc.wantFile != "" {
-				fp := filepath.Join(l.Path, tc.wantFile)
+				fp := filepath.Join(layer.Path, tc.wantFile)
 				if _, err := os.Stat(fp); err != nil {
 					t.Errorf("Failed to extract. Missing file: %s (%v)", fp, err)
 				}
-				if l.Metadata["version"] != version {
-					t.Errorf("Layer Metadata.version = %q, want %q", l.Metadata["version"], version)
+				if layer.Metadata["version"] != version {
+					t.Errorf("Layer Metadata.version = %q, want %q", layer.Metadata["version"], version)
 				}
 			}
 		})
 	}
-
 }
 
 func TestInstallRuby(t *testing.T) {
@@ -0,0 +1,87 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sbom contains helpers to attach software bills of materials to buildpack layers.
+package sbom
+
+import (
+	"encoding/json"
+	"sort"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/buildpacks/libcnb"
+)
+
+const (
+	cycloneDXFormat      = "CycloneDX"
+	cycloneDXSpecVersion = "1.4"
+)
+
+// Component is a software component listed in a bill of materials.
+type Component struct {
+	Type       string     `json:"type"`
+	Name       string     `json:"name"`
+	Version    string     `json:"version,omitempty"`
+	PURL       string     `json:"purl,omitempty"`
+	Hashes     []Hash     `json:"hashes,omitempty"`
+	Properties []Property `json:"properties,omitempty"`
+}
+
+// Property is an ecosystem specific name-value pair of a component, e.g. a Go module checksum.
+type Property struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Hash is a cryptographic digest of a component.
+type Hash struct {
+	// Algorithm is a CycloneDX hash algorithm name, e.g. SHA-256.
+	Algorithm string `json:"alg"`
+	Content   string `json:"content"`
+}
+
+// BOM is a CycloneDX JSON bill of materials.
+type BOM struct {
+	BOMFormat   string      `json:"bomFormat"`
+	SpecVersion string      `json:"specVersion"`
+	Version     int         `json:"version"`
+	Components  []Component `json:"components"`
+}
+
+// NewBOM returns a CycloneDX bill of materials listing the given components sorted by name.
+func NewBOM(components []Component) BOM {
+	sorted := append([]Component{}, components...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return BOM{
+		BOMFormat:   cycloneDXFormat,
+		SpecVersion: cycloneDXSpecVersion,
+		Version:     1,
+		Components:  sorted,
+	}
+}
+
+// WriteLayerSBOM writes a CycloneDX bill of materials listing the given components for layer l.
+// Buildpacks writing an SBOM must declare the CycloneDX media type in their sbom-formats.
+func WriteLayerSBOM(ctx *gcp.Context, l *libcnb.Layer, components []Component) error {
+	content, err := json.MarshalIndent(NewBOM(components), "", "  ")
+	if err != nil {
+		return gcp.InternalErrorf("marshalling SBOM for layer %s: %v", l.Name, err)
+	}
+	path := l.SBOMPath(libcnb.CycloneDXJSON)
+	if err := ctx.WriteFile(path, content, 0644); err != nil {
+		return err
+	}
+	ctx.Debugf("Wrote SBOM with %d components to %s.", len(components), path)
+	return nil
+}
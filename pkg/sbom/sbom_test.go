@@ -0,0 +1,57 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/buildpacks/libcnb"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteLayerSBOM(t *testing.T) {
+	layers := t.TempDir()
+	l := &libcnb.Layer{Name: "deps", Path: filepath.Join(layers, "deps")}
+	components := []Component{
+		{Type: "library", Name: "zeta", Version: "1.0.0", PURL: "pkg:pypi/zeta@1.0.0"},
+		{Type: "library", Name: "alpha", Version: "2.0.0", Hashes: []Hash{{Algorithm: "SHA-256", Content: "abc"}}},
+	}
+
+	if err := WriteLayerSBOM(gcp.NewContext(), l, components); err != nil {
+		t.Fatalf("WriteLayerSBOM() got error: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(layers, "deps.sbom.cdx.json"))
+	if err != nil {
+		t.Fatalf("reading SBOM: %v", err)
+	}
+	var got BOM
+	if err := json.Unmarshal(content, &got); err != nil {
+		t.Fatalf("unmarshalling SBOM: %v", err)
+	}
+	want := BOM{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.4",
+		Version:     1,
+		Components:  []Component{components[1], components[0]},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("WriteLayerSBOM() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,281 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// optionalSuffix marks the optional buildpacks of the groups of a manifest.
+const optionalSuffix = "?"
+
+// manifest is the declarative description of a builder that builder.toml is generated from.
+type manifest struct {
+	Description string          `toml:"description"`
+	Stack       stack           `toml:"stack"`
+	Lifecycle   lifecycle       `toml:"lifecycle"`
+	Buildpacks  []buildpackRef  `toml:"buildpack"`
+	Groups      []manifestGroup `toml:"group"`
+}
+
+// manifestGroup is a detection group of a manifest. Its buildpacks are listed by ID, in order, with
+// a trailing `?` for the optional ones.
+type manifestGroup struct {
+	Comment    string   `toml:"comment"`
+	Buildpacks []string `toml:"buildpacks"`
+}
+
+// builderConfig is the content of a builder.toml file.
+type builderConfig struct {
+	Description string         `toml:"description"`
+	Buildpacks  []buildpackRef `toml:"buildpacks"`
+	Order       []orderEntry   `toml:"order"`
+	Stack       stack          `toml:"stack"`
+	Lifecycle   lifecycle      `toml:"lifecycle"`
+}
+
+type buildpackRef struct {
+	ID  string `toml:"id"`
+	URI string `toml:"uri"`
+}
+
+type orderEntry struct {
+	Group []groupEntry `toml:"group"`
+}
+
+type groupEntry struct {
+	ID       string `toml:"id"`
+	Optional bool   `toml:"optional"`
+}
+
+type stack struct {
+	ID         string `toml:"id"`
+	BuildImage string `toml:"build-image"`
+	RunImage   string `toml:"run-image"`
+}
+
+type lifecycle struct {
+	Version string `toml:"version"`
+	URI     string `toml:"uri"`
+}
+
+// parseManifest parses a manifest.
+func parseManifest(content string) (*manifest, error) {
+	var m manifest
+	md, err := toml.Decode(content, &m)
+	if err != nil {
+		return nil, fmt.Errorf("parsing manifest: %w", err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("unknown keys in manifest: %v", undecoded)
+	}
+	return &m, nil
+}
+
+// parseBuilderConfig parses a builder.toml file.
+func parseBuilderConfig(content string) (*builderConfig, error) {
+	var c builderConfig
+	if _, err := toml.Decode(content, &c); err != nil {
+		return nil, fmt.Errorf("parsing builder.toml: %w", err)
+	}
+	return &c, nil
+}
+
+// config returns the builder configuration of the manifest.
+func (m *manifest) config() *builderConfig {
+	c := &builderConfig{
+		Description: m.Description,
+		Buildpacks:  m.Buildpacks,
+		Stack:       m.Stack,
+		Lifecycle:   m.Lifecycle,
+	}
+	for _, g := range m.Groups {
+		var o orderEntry
+		for _, id := range g.Buildpacks {
+			o.Group = append(o.Group, groupEntry{
+				ID:       strings.TrimSuffix(id, optionalSuffix),
+				Optional: strings.HasSuffix(id, optionalSuffix),
+			})
+		}
+		c.Order = append(c.Order, o)
+	}
+	return c
+}
+
+// generate returns the builder.toml of the manifest, in the layout of the committed files.
+func (m *manifest) generate() string {
+	var b strings.Builder
+	if m.Description != "" {
+		fmt.Fprintf(&b, "description = %q\n\n", m.Description)
+	}
+	for _, bp := range m.Buildpacks {
+		fmt.Fprintf(&b, "[[buildpacks]]\n  id = %q\n  uri = %q\n\n", bp.ID, bp.URI)
+	}
+	for _, g := range m.Groups {
+		for _, line := range strings.Split(strings.TrimSpace(g.Comment), "\n") {
+			if line != "" {
+				fmt.Fprintf(&b, "# %s\n", line)
+			}
+		}
+		b.WriteString("[[order]]\n")
+		for _, id := range g.Buildpacks {
+			fmt.Fprintf(&b, "\n  [[order.group]]\n    id = %q\n", strings.TrimSuffix(id, optionalSuffix))
+			if strings.HasSuffix(id, optionalSuffix) {
+				b.WriteString("    optional = true\n")
+			}
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "[stack]\n  id = %q\n  build-image = %q\n  run-image = %q\n", m.Stack.ID, m.Stack.BuildImage, m.Stack.RunImage)
+	if m.Lifecycle.Version != "" || m.Lifecycle.URI != "" {
+		b.WriteString("\n[lifecycle]\n")
+		if m.Lifecycle.Version != "" {
+			fmt.Fprintf(&b, "  version = %q\n", m.Lifecycle.Version)
+		}
+		if m.Lifecycle.URI != "" {
+			fmt.Fprintf(&b, "  uri = %q\n", m.Lifecycle.URI)
+		}
+	}
+	return b.String()
+}
+
+// validate returns the problems of the builder configuration: buildpack ID and URI collisions,
+// groups that are empty, reference undeclared buildpacks or list a buildpack twice, identical
+// groups, declared buildpacks that no group uses, and a missing stack.
+func (c *builderConfig) validate() []string {
+	var problems []string
+	declared := map[string]bool{}
+	uris := map[string]string{}
+	for _, bp := range c.Buildpacks {
+		if declared[bp.ID] {
+			problems = append(problems, fmt.Sprintf("buildpack %s is declared more than once", bp.ID))
+		}
+		declared[bp.ID] = true
+		if other, ok := uris[bp.URI]; ok && other != bp.ID {
+			problems = append(problems, fmt.Sprintf("buildpacks %s and %s have the same uri %s", other, bp.ID, bp.URI))
+		}
+		uris[bp.URI] = bp.ID
+	}
+
+	used := map[string]bool{}
+	groups := map[string]int{}
+	for i, o := range c.Order {
+		n := i + 1
+		if len(o.Group) == 0 {
+			problems = append(problems, fmt.Sprintf("group %d is empty", n))
+			continue
+		}
+		seen := map[string]bool{}
+		for _, e := range o.Group {
+			if !declared[e.ID] {
+				problems = append(problems, fmt.Sprintf("group %d uses undeclared buildpack %s", n, e.ID))
+			}
+			if seen[e.ID] {
+				problems = append(problems, fmt.Sprintf("group %d lists buildpack %s more than once", n, e.ID))
+			}
+			seen[e.ID] = true
+			used[e.ID] = true
+		}
+		key := fmt.Sprint(o.Group)
+		if first, ok := groups[key]; ok {
+			problems = append(problems, fmt.Sprintf("group %d is identical to group %d", n, first))
+		} else {
+			groups[key] = n
+		}
+	}
+	for _, bp := range c.Buildpacks {
+		if !used[bp.ID] {
+			problems = append(problems, fmt.Sprintf("buildpack %s is not used by any group", bp.ID))
+		}
+	}
+	if c.Stack.ID == "" || c.Stack.BuildImage == "" || c.Stack.RunImage == "" {
+		problems = append(problems, "stack must declare id, build-image and run-image")
+	}
+	return problems
+}
+
+// diff returns the differences of the committed builder configuration from the generated one.
+func diff(generated, committed *builderConfig) []string {
+	var diffs []string
+	if generated.Description != committed.Description {
+		diffs = append(diffs, fmt.Sprintf("description: generated %q, committed %q", generated.Description, committed.Description))
+	}
+	gen, com := buildpackURIs(generated), buildpackURIs(committed)
+	for _, id := range sortedKeys(gen) {
+		if uri, ok := com[id]; !ok {
+			diffs = append(diffs, fmt.Sprintf("buildpack %s is missing from the committed file", id))
+		} else if uri != gen[id] {
+			diffs = append(diffs, fmt.Sprintf("buildpack %s: generated uri %s, committed uri %s", id, gen[id], uri))
+		}
+	}
+	for _, id := range sortedKeys(com) {
+		if _, ok := gen[id]; !ok {
+			diffs = append(diffs, fmt.Sprintf("buildpack %s is not in the manifest", id))
+		}
+	}
+	for i := 0; i < len(generated.Order) || i < len(committed.Order); i++ {
+		switch {
+		case i >= len(committed.Order):
+			diffs = append(diffs, fmt.Sprintf("group %d is missing from the committed file: %s", i+1, groupString(generated.Order[i])))
+		case i >= len(generated.Order):
+			diffs = append(diffs, fmt.Sprintf("group %d is not in the manifest: %s", i+1, groupString(committed.Order[i])))
+		default:
+			if g, c := groupString(generated.Order[i]), groupString(committed.Order[i]); g != c {
+				diffs = append(diffs, fmt.Sprintf("group %d: generated %s, committed %s", i+1, g, c))
+			}
+		}
+	}
+	if generated.Stack != committed.Stack {
+		diffs = append(diffs, fmt.Sprintf("stack: generated %+v, committed %+v", generated.Stack, committed.Stack))
+	}
+	if generated.Lifecycle != committed.Lifecycle {
+		diffs = append(diffs, fmt.Sprintf("lifecycle: generated %+v, committed %+v", generated.Lifecycle, committed.Lifecycle))
+	}
+	return diffs
+}
+
+// groupString returns the buildpacks of a group in the notation of manifests.
+func groupString(o orderEntry) string {
+	var ids []string
+	for _, e := range o.Group {
+		id := e.ID
+		if e.Optional {
+			id += optionalSuffix
+		}
+		ids = append(ids, id)
+	}
+	return "[" + strings.Join(ids, ", ") + "]"
+}
+
+func buildpackURIs(c *builderConfig) map[string]string {
+	uris := map[string]string{}
+	for _, bp := range c.Buildpacks {
+		uris[bp.ID] = bp.URI
+	}
+	return uris
+}
+
+func sortedKeys(m map[string]string) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
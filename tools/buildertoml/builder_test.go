@@ -0,0 +1,264 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testManifest = `
+description = "Test builder"
+
+[stack]
+  id = "google"
+  build-image = "gcr.io/buildpacks/gcp/build"
+  run-image = "gcr.io/buildpacks/gcp/run"
+
+[lifecycle]
+  version = "0.16.0"
+
+[[buildpack]]
+  id = "google.go.runtime"
+  uri = "runtime.tgz"
+
+[[buildpack]]
+  id = "google.go.build"
+  uri = "build.tgz"
+
+[[buildpack]]
+  id = "google.utils.label-image"
+  uri = "label.tgz"
+
+[[group]]
+  comment = "Go"
+  buildpacks = ["google.go.runtime", "google.go.build", "google.utils.label-image?"]
+`
+
+const testBuilder = `description = "Test builder"
+
+[[buildpacks]]
+  id = "google.go.runtime"
+  uri = "runtime.tgz"
+
+[[buildpacks]]
+  id = "google.go.build"
+  uri = "build.tgz"
+
+[[buildpacks]]
+  id = "google.utils.label-image"
+  uri = "label.tgz"
+
+# Go
+[[order]]
+
+  [[order.group]]
+    id = "google.go.runtime"
+
+  [[order.group]]
+    id = "google.go.build"
+
+  [[order.group]]
+    id = "google.utils.label-image"
+    optional = true
+
+[stack]
+  id = "google"
+  build-image = "gcr.io/buildpacks/gcp/build"
+  run-image = "gcr.io/buildpacks/gcp/run"
+
+[lifecycle]
+  version = "0.16.0"
+`
+
+func TestGenerate(t *testing.T) {
+	m, err := parseManifest(testManifest)
+	if err != nil {
+		t.Fatalf("parseManifest() got error: %v", err)
+	}
+	if diff := cmp.Diff(testBuilder, m.generate()); diff != "" {
+		t.Errorf("generate() mismatch (-want +got):\n%s", diff)
+	}
+	c, err := parseBuilderConfig(m.generate())
+	if err != nil {
+		t.Fatalf("parseBuilderConfig() got error: %v", err)
+	}
+	if diff := cmp.Diff(m.config(), c); diff != "" {
+		t.Errorf("parsed generated builder.toml mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseManifestUnknownKey(t *testing.T) {
+	if _, err := parseManifest(testManifest + "\n[[group]]\n  buildpack = [\"google.go.runtime\"]\n"); err == nil {
+		t.Error("parseManifest() with unknown key succeeded, want error")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testStack := stack{ID: "google", BuildImage: "build", RunImage: "run"}
+	testCases := []struct {
+		name   string
+		config builderConfig
+		want   []string
+	}{
+		{
+			name: "valid",
+			config: builderConfig{
+				Buildpacks: []buildpackRef{{ID: "a", URI: "a.tgz"}, {ID: "b", URI: "b.tgz"}},
+				Order: []orderEntry{
+					{Group: []groupEntry{{ID: "a"}, {ID: "b"}}},
+					{Group: []groupEntry{{ID: "b"}, {ID: "a"}}},
+				},
+				Stack: testStack,
+			},
+		},
+		{
+			name: "id and uri collisions",
+			config: builderConfig{
+				Buildpacks: []buildpackRef{{ID: "a", URI: "a.tgz"}, {ID: "a", URI: "a2.tgz"}, {ID: "b", URI: "a.tgz"}},
+				Order:      []orderEntry{{Group: []groupEntry{{ID: "a"}, {ID: "b"}}}},
+				Stack:      testStack,
+			},
+			want: []string{
+				"buildpack a is declared more than once",
+				"buildpacks a and b have the same uri a.tgz",
+			},
+		},
+		{
+			name: "invalid groups",
+			config: builderConfig{
+				Buildpacks: []buildpackRef{{ID: "a", URI: "a.tgz"}, {ID: "b", URI: "b.tgz"}},
+				Order: []orderEntry{
+					{Group: []groupEntry{{ID: "a"}, {ID: "c"}, {ID: "a"}}},
+					{},
+					{Group: []groupEntry{{ID: "a"}, {ID: "c"}, {ID: "a"}}},
+				},
+				Stack: testStack,
+			},
+			want: []string{
+				"group 1 uses undeclared buildpack c",
+				"group 1 lists buildpack a more than once",
+				"group 2 is empty",
+				"group 3 uses undeclared buildpack c",
+				"group 3 lists buildpack a more than once",
+				"group 3 is identical to group 1",
+				"buildpack b is not used by any group",
+			},
+		},
+		{
+			name: "optional differs",
+			config: builderConfig{
+				Buildpacks: []buildpackRef{{ID: "a", URI: "a.tgz"}},
+				Order: []orderEntry{
+					{Group: []groupEntry{{ID: "a"}}},
+					{Group: []groupEntry{{ID: "a", Optional: true}}},
+				},
+				Stack: testStack,
+			},
+		},
+		{
+			name: "missing stack",
+			config: builderConfig{
+				Buildpacks: []buildpackRef{{ID: "a", URI: "a.tgz"}},
+				Order:      []orderEntry{{Group: []groupEntry{{ID: "a"}}}},
+				Stack:      stack{ID: "google"},
+			},
+			want: []string{"stack must declare id, build-image and run-image"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, tc.config.validate()); diff != "" {
+				t.Errorf("validate() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	m, err := parseManifest(testManifest)
+	if err != nil {
+		t.Fatalf("parseManifest() got error: %v", err)
+	}
+	testCases := []struct {
+		name      string
+		committed string
+		want      []string
+	}{
+		{
+			name:      "same",
+			committed: testBuilder,
+		},
+		{
+			name: "different",
+			committed: `
+description = "Test builder"
+
+[[buildpacks]]
+  id = "google.go.runtime"
+  uri = "go-runtime.tgz"
+
+[[buildpacks]]
+  id = "google.go.build"
+  uri = "build.tgz"
+
+[[buildpacks]]
+  id = "google.go.clear-source"
+  uri = "clear.tgz"
+
+[[order]]
+  [[order.group]]
+    id = "google.go.runtime"
+  [[order.group]]
+    id = "google.go.build"
+  [[order.group]]
+    id = "google.go.clear-source"
+    optional = true
+
+[[order]]
+  [[order.group]]
+    id = "google.go.runtime"
+
+[stack]
+  id = "google"
+  build-image = "gcr.io/buildpacks/gcp/build"
+  run-image = "gcr.io/buildpacks/gcp/run"
+
+[lifecycle]
+  version = "0.17.0"
+`,
+			want: []string{
+				"buildpack google.go.runtime: generated uri runtime.tgz, committed uri go-runtime.tgz",
+				"buildpack google.utils.label-image is missing from the committed file",
+				"buildpack google.go.clear-source is not in the manifest",
+				"group 1: generated [google.go.runtime, google.go.build, google.utils.label-image?], committed [google.go.runtime, google.go.build, google.go.clear-source?]",
+				"group 2 is not in the manifest: [google.go.runtime]",
+				"lifecycle: generated {Version:0.16.0 URI:}, committed {Version:0.17.0 URI:}",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			committed, err := parseBuilderConfig(tc.committed)
+			if err != nil {
+				t.Fatalf("parseBuilderConfig() got error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, diff(m.config(), committed)); diff != "" {
+				t.Errorf("diff() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
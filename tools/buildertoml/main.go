@@ -0,0 +1,107 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The main binary generates builder.toml files from declarative manifests of buildpack groups and
+// stack images, validates them, and diffs them against the committed files.
+//
+// Usage:
+//
+//	buildertoml -manifest builder.manifest.toml -out builder.toml
+//	buildertoml -manifest builder.manifest.toml -check builder.toml
+//	buildertoml -validate builder.toml
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+var (
+	manifestPath = flag.String("manifest", "", "Path of the manifest to generate builder.toml from.")
+	outPath      = flag.String("out", "", "Path to write the generated builder.toml to; stdout if not set.")
+	checkPath    = flag.String("check", "", "Path of a committed builder.toml to diff against the generated one.")
+	validatePath = flag.String("validate", "", "Path of a builder.toml to validate without a manifest.")
+)
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+}
+
+func run() error {
+	if *validatePath != "" {
+		c, err := readBuilderConfig(*validatePath)
+		if err != nil {
+			return err
+		}
+		return problemsError(*validatePath, c.validate())
+	}
+	if *manifestPath == "" {
+		return fmt.Errorf("one of -manifest or -validate is required")
+	}
+	content, err := os.ReadFile(*manifestPath)
+	if err != nil {
+		return fmt.Errorf("reading manifest: %w", err)
+	}
+	m, err := parseManifest(string(content))
+	if err != nil {
+		return err
+	}
+	generated := m.config()
+	if err := problemsError(*manifestPath, generated.validate()); err != nil {
+		return err
+	}
+
+	if *checkPath != "" {
+		committed, err := readBuilderConfig(*checkPath)
+		if err != nil {
+			return err
+		}
+		if diffs := diff(generated, committed); len(diffs) > 0 {
+			return fmt.Errorf("%s differs from %s, regenerate it with -out:\n  %s", *checkPath, *manifestPath, strings.Join(diffs, "\n  "))
+		}
+		log.Printf("%s matches %s", *checkPath, *manifestPath)
+		return nil
+	}
+
+	out := m.generate()
+	if *outPath == "" {
+		fmt.Print(out)
+		return nil
+	}
+	if err := os.WriteFile(*outPath, []byte(out), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", *outPath, err)
+	}
+	return nil
+}
+
+func readBuilderConfig(path string) (*builderConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return parseBuilderConfig(string(content))
+}
+
+func problemsError(path string, problems []string) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s is invalid:\n  %s", path, strings.Join(problems, "\n  "))
+}
@@ -22,6 +22,10 @@
 #
 # Produces a builder Docker image tagged <name> with the last directory in the
 # <source tar> path and writes the image sha to <sha file> path.
+#
+# If BUILDER_ARCH is set to an architecture other than amd64, e.g. arm64, the
+# builder uses the stack images tagged with the architecture as a suffix, e.g.
+# gcr.io/buildpacks/gcp/run:v1-arm64.
 
 set -euox pipefail
 
@@ -29,6 +33,7 @@ readonly name="${1:?image name missing}"
 readonly tar="${2:?tar path missing}"
 readonly descriptor="${3:?descriptor path missing}"
 readonly sha="${4:?sha path missing}"
+readonly arch="${BUILDER_ARCH:-amd64}"
 
 
 # Blaze does not set $HOME which is required by pack.
@@ -40,6 +45,12 @@ mkdir "$HOME"
 echo "Extracting builder tar:"
 tar xvf "$tar" -C "$temp"
 
+if [[ "${arch}" != "amd64" ]]; then
+  echo "Using ${arch} stack images:"
+  sed -i -E 's/^(\s*(build-image|run-image)\s*=\s*"[^"@]*:[^"/@]*)"/\1-'"${arch}"'"/' "${temp}/${descriptor}"
+  grep -E '^\s*(build-image|run-image)' "${temp}/${descriptor}"
+fi
+
 echo "Creating builder:"
 pack builder create "$name" --config="${temp}/${descriptor}" --pull-policy=never
 docker inspect --format='{{index .Id}}' "$name" > "$sha"
@@ -0,0 +1,166 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The main binary scaffolds a new buildpack under cmd/<lang>/<name>, with its BUILD file, unit
+// tests and an acceptance test stub for the gcp/base builder.
+//
+// Usage:
+//
+//	bazel run tools/create-buildpack:main -- -root=$PWD -lang=php -name=symfony -file=symfony.lock
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+var (
+	rootFlag        = flag.String("root", ".", "Root directory of the buildpacks repository.")
+	langFlag        = flag.String("lang", "", "Language of the buildpack, e.g. php; cmd/<lang> must exist.")
+	nameFlag        = flag.String("name", "", "Name of the buildpack, e.g. composer_install.")
+	fileFlag        = flag.String("file", "", "File whose presence in the application opts in to the buildpack; the buildpack always opts in if not set.")
+	descriptionFlag = flag.String("description", "", "Description of what the buildpack does, completing `The <name> buildpack ...`.")
+	versionFlag     = flag.String("version", "0.0.1", "Initial version of the buildpack.")
+)
+
+// nameRegexp matches the names of buildpacks, which are the names of their Bazel packages.
+var nameRegexp = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+// scaffold describes the buildpack to generate.
+type scaffold struct {
+	Year        int
+	Lang        string
+	Name        string
+	File        string
+	Description string
+	Version     string
+}
+
+// DashedName returns the name of the buildpack as it appears in its ID.
+func (s scaffold) DashedName() string {
+	return strings.ReplaceAll(s.Name, "_", "-")
+}
+
+// ID returns the ID of the buildpack, as assigned by the buildpack Bazel rule.
+func (s scaffold) ID() string {
+	return fmt.Sprintf("google.%s.%s", s.Lang, s.DashedName())
+}
+
+func main() {
+	flag.Parse()
+	s := scaffold{
+		Year:        time.Now().Year(),
+		Lang:        *langFlag,
+		Name:        *nameFlag,
+		File:        *fileFlag,
+		Description: *descriptionFlag,
+		Version:     *versionFlag,
+	}
+	if s.Description == "" {
+		s.Description = "TODO: describe what the buildpack does"
+	}
+	files, err := create(*rootFlag, s)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	log.Printf("Created buildpack %s:\n  %s", s.ID(), strings.Join(files, "\n  "))
+	log.Printf("Next, add the buildpack to builders/gcp/base (see tools/buildertoml) and the acceptance test application to builders/testdata/%s/generic/%s.", s.Lang, s.Name)
+}
+
+// create generates the files of the buildpack in the repository at root, and returns their paths
+// relative to root. It does not overwrite existing files.
+func create(root string, s scaffold) ([]string, error) {
+	if !nameRegexp.MatchString(s.Name) {
+		return nil, fmt.Errorf("invalid buildpack name %q, must match %s", s.Name, nameRegexp)
+	}
+	if s.Lang == "" {
+		return nil, fmt.Errorf("-lang is required")
+	}
+	if fi, err := os.Stat(filepath.Join(root, "cmd", s.Lang)); err != nil || !fi.IsDir() {
+		return nil, fmt.Errorf("unknown language %q, cmd/%s must be a directory of %s", s.Lang, s.Lang, root)
+	}
+	bpDir := filepath.Join("cmd", s.Lang, s.Name)
+	acceptanceDir := filepath.Join("builders", "gcp", "base", "acceptance")
+	acceptanceTest := filepath.Join(acceptanceDir, s.Lang+"_"+s.Name+"_test.go")
+	outputs := []struct {
+		path, tmpl string
+		goSource   bool
+	}{
+		{filepath.Join(bpDir, "main.go"), mainTemplate, true},
+		{filepath.Join(bpDir, "main_test.go"), mainTestTemplate, true},
+		{filepath.Join(bpDir, "BUILD.bazel"), buildTemplate, false},
+		{acceptanceTest, acceptanceTemplate, true},
+	}
+	for _, o := range outputs {
+		if _, err := os.Stat(filepath.Join(root, o.path)); err == nil {
+			return nil, fmt.Errorf("%s already exists", o.path)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Join(root, bpDir), 0755); err != nil {
+		return nil, fmt.Errorf("creating %s: %w", bpDir, err)
+	}
+	var created []string
+	for _, o := range outputs {
+		content, err := render(o.tmpl, s)
+		if err != nil {
+			return nil, err
+		}
+		if o.goSource {
+			if content, err = format.Source(content); err != nil {
+				return nil, fmt.Errorf("formatting %s: %w", o.path, err)
+			}
+		}
+		if err := os.WriteFile(filepath.Join(root, o.path), content, 0644); err != nil {
+			return nil, fmt.Errorf("writing %s: %w", o.path, err)
+		}
+		created = append(created, o.path)
+	}
+
+	target, err := render(acceptanceBuildTemplate, s)
+	if err != nil {
+		return nil, err
+	}
+	acceptanceBuild := filepath.Join(acceptanceDir, "BUILD.bazel")
+	f, err := os.OpenFile(filepath.Join(root, acceptanceBuild), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", acceptanceBuild, err)
+	}
+	defer f.Close()
+	if _, err := f.Write(target); err != nil {
+		return nil, fmt.Errorf("writing %s: %w", acceptanceBuild, err)
+	}
+	return append(created, acceptanceBuild), nil
+}
+
+func render(tmpl string, s scaffold) ([]byte, error) {
+	t, err := template.New("").Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("parsing template: %w", err)
+	}
+	var b bytes.Buffer
+	if err := t.Execute(&b, s); err != nil {
+		return nil, fmt.Errorf("executing template: %w", err)
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func setupRoot(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, dir := range []string{"cmd/php", "builders/gcp/base/acceptance"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatalf("creating %s: %v", dir, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "builders/gcp/base/acceptance/BUILD.bazel"), []byte("# Acceptance tests.\n"), 0644); err != nil {
+		t.Fatalf("writing BUILD.bazel: %v", err)
+	}
+	return root
+}
+
+func TestCreate(t *testing.T) {
+	testCases := []struct {
+		name         string
+		file         string
+		wantContains map[string][]string
+	}{
+		{
+			name: "with file",
+			file: "symfony.lock",
+			wantContains: map[string][]string{
+				"cmd/php/symfony_app/main.go":                          {"// The symfony_app buildpack installs Symfony.", `gcp.OptInFileFound("symfony.lock")`},
+				"cmd/php/symfony_app/main_test.go":                     {`"symfony.lock": ""`},
+				"cmd/php/symfony_app/BUILD.bazel":                      {`name = "symfony_app"`, `prefix = "php"`, `"//builders:php_builders"`},
+				"builders/gcp/base/acceptance/php_symfony_app_test.go": {`MustUse:   []string{"google.php.symfony-app"}`},
+				"builders/gcp/base/acceptance/BUILD.bazel":             {"# Acceptance tests.\n", `srcs = ["php_symfony_app_test.go"]`, "gcpbase-php-symfony-app-test-"},
+			},
+		},
+		{
+			name: "without file",
+			wantContains: map[string][]string{
+				"cmd/php/symfony_app/main.go":      {"return gcp.OptInAlways(), nil"},
+				"cmd/php/symfony_app/main_test.go": {`name:  "always"`},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := setupRoot(t)
+			s := scaffold{Year: 2023, Lang: "php", Name: "symfony_app", File: tc.file, Description: "installs Symfony", Version: "0.0.1"}
+
+			got, err := create(root, s)
+			if err != nil {
+				t.Fatalf("create() got error: %v", err)
+			}
+
+			want := []string{
+				"cmd/php/symfony_app/main.go",
+				"cmd/php/symfony_app/main_test.go",
+				"cmd/php/symfony_app/BUILD.bazel",
+				"builders/gcp/base/acceptance/php_symfony_app_test.go",
+				"builders/gcp/base/acceptance/BUILD.bazel",
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("create() mismatch (-want +got):\n%s", diff)
+			}
+			for path, substrs := range tc.wantContains {
+				content, err := os.ReadFile(filepath.Join(root, path))
+				if err != nil {
+					t.Fatalf("reading %s: %v", path, err)
+				}
+				for _, s := range substrs {
+					if !strings.Contains(string(content), s) {
+						t.Errorf("%s does not contain %q:\n%s", path, s, content)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCreateErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		lang   string
+		bpName string
+		exists bool
+	}{
+		{
+			name:   "invalid name",
+			lang:   "php",
+			bpName: "Symfony-App",
+		},
+		{
+			name:   "unknown language",
+			lang:   "cobol",
+			bpName: "runtime",
+		},
+		{
+			name:   "existing buildpack",
+			lang:   "php",
+			bpName: "runtime",
+			exists: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := setupRoot(t)
+			if tc.exists {
+				dir := filepath.Join(root, "cmd", tc.lang, tc.bpName)
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					t.Fatalf("creating %s: %v", dir, err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644); err != nil {
+					t.Fatalf("writing main.go: %v", err)
+				}
+			}
+
+			if _, err := create(root, scaffold{Year: 2023, Lang: tc.lang, Name: tc.bpName}); err == nil {
+				t.Errorf("create() succeeded, want error")
+			}
+		})
+	}
+}
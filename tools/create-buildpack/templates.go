@@ -0,0 +1,225 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+const license = `// Copyright {{.Year}} Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+`
+
+const mainTemplate = license + `
+// Implements {{.Lang}}/{{.Name}} buildpack.
+// The {{.Name}} buildpack {{.Description}}.
+package main
+
+import (
+	"fmt"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+const layerName = "{{.Name}}"
+
+func main() {
+	gcp.Main(detectFn, buildFn)
+}
+
+func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
+{{- if .File}}
+	exists, err := ctx.FileExists("{{.File}}")
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return gcp.OptOutFileNotFound("{{.File}}"), nil
+	}
+	return gcp.OptInFileFound("{{.File}}"), nil
+{{- else}}
+	return gcp.OptInAlways(), nil
+{{- end}}
+}
+
+func buildFn(ctx *gcp.Context) error {
+	l, err := ctx.Layer(layerName, gcp.BuildLayer, gcp.CacheLayer, gcp.LaunchLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", layerName, err)
+	}
+	ctx.Logf("Using layer %s", l.Path)
+	return nil
+}
+`
+
+const mainTestTemplate = license + `
+package main
+
+import (
+	"testing"
+
+	bpt "github.com/GoogleCloudPlatform/buildpacks/internal/buildpacktest"
+)
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		want  int
+	}{
+{{- if .File}}
+		{
+			name: "with {{.File}}",
+			files: map[string]string{
+				"{{.File}}": "",
+			},
+			want: 0,
+		},
+		{
+			name:  "without {{.File}}",
+			files: map[string]string{},
+			want:  100,
+		},
+{{- else}}
+		{
+			name:  "always",
+			files: map[string]string{},
+			want:  0,
+		},
+{{- end}}
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bpt.TestDetect(t, detectFn, tc.name, tc.files, []string{}, tc.want)
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	result, err := bpt.RunBuild(t, buildFn, bpt.WithTestName("build"))
+	if err != nil {
+		t.Fatalf("error running build: %v, result: %#v", err, result)
+	}
+	if result.ExitCode != 0 {
+		t.Errorf("build exit code mismatch, got: %d, want: 0", result.ExitCode)
+	}
+}
+`
+
+const buildTemplate = `load("@io_bazel_rules_go//go:def.bzl", "go_binary", "go_test")
+load("//tools:defs.bzl", "buildpack")
+
+licenses(["notice"])
+
+buildpack(
+    name = "{{.Name}}",
+    executables = [
+        ":main",
+    ],
+    prefix = "{{.Lang}}",
+    version = "{{.Version}}",
+    visibility = [
+        "//builders:{{.Lang}}_builders",
+    ],
+)
+
+go_binary(
+    name = "main",
+    srcs = ["main.go"],
+    # Strip debugging information to reduce binary size.
+    gc_linkopts = [
+        "-s",
+        "-w",
+    ],
+    deps = ["//pkg/gcpbuildpack"],
+)
+
+go_test(
+    name = "main_test",
+    size = "small",
+    srcs = ["main_test.go"],
+    embed = [":main"],
+    rundir = ".",
+    deps = ["//internal/buildpacktest"],
+)
+`
+
+const acceptanceTemplate = license + `
+package acceptance
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/buildpacks/internal/acceptance"
+)
+
+func init() {
+	acceptance.DefineFlags()
+}
+
+func TestAcceptance(t *testing.T) {
+	imageCtx, cleanup := acceptance.ProvisionImages(t)
+	t.Cleanup(cleanup)
+
+	testCases := []acceptance.Test{
+		{
+			// The application is in builders/testdata/{{.Lang}}/generic/{{.Name}}.
+			Name:      "{{.Name}}",
+			App:       "{{.Name}}",
+			MustMatch: "PASS",
+			MustUse:   []string{"{{.ID}}"},
+		},
+	}
+	for _, tc := range acceptance.FilterTests(t, imageCtx, testCases) {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			acceptance.TestApp(t, imageCtx, tc)
+		})
+	}
+}
+`
+
+const acceptanceBuildTemplate = `
+go_test(
+    name = "{{.Lang}}_{{.Name}}_test",
+    size = "enormous",
+    srcs = ["{{.Lang}}_{{.Name}}_test.go"],
+    args = [
+        "-test-data=$(location //builders/testdata/{{.Lang}}:generic)",
+        "-structure-test-config=$(location :config.yaml)",
+        "-builder-source=$(location //builders/gcp/base:builder.tar)",
+        "-builder-prefix=gcpbase-{{.Lang}}-{{.DashedName}}-test-",
+    ],
+    data = [
+        ":config.yaml",
+        "//builders/gcp/base:builder.tar",
+        "//builders/testdata/{{.Lang}}:generic",
+    ],
+    embed = [":acceptance"],
+    rundir = ".",
+    tags = [
+        "local",
+    ],
+    deps = ["//internal/acceptance"],
+)
+`
@@ -5,7 +5,7 @@ load("@io_bazel_rules_go//go:def.bzl", "go_test")
 load("@rules_pkg//pkg:mappings.bzl", "pkg_mklink")
 load("@rules_pkg//pkg:tar.bzl", "pkg_tar")
 
-def buildpack(name, executables, prefix, version, api = "0.8", srcs = None, extension = "tgz", strip_prefix = ".", visibility = None):
+def buildpack(name, executables, prefix, version, api = "0.8", srcs = None, extension = "tgz", strip_prefix = ".", sbom_formats = None, visibility = None):
     """Macro to create a single buildpack as a tgz or tar archive.
 
     The result is a tar or tgz archive with a buildpack descriptor
@@ -23,6 +23,7 @@ def buildpack(name, executables, prefix, version, api = "0.8", srcs = None, exte
       executables: list of labels of buildpack binaries
       strip_prefix: by default preserves the paths of srcs
       extension: tgz by default
+      sbom_formats: list of SBOM media types the buildpack writes for its layers
       visibility: the visibility
     """
 
@@ -45,6 +46,7 @@ def buildpack(name, executables, prefix, version, api = "0.8", srcs = None, exte
         version = version,
         prefix = prefix,
         bp_name = name,
+        sbom_formats = sbom_formats or [],
         output = "buildpack.toml",
     )
 
@@ -79,6 +81,7 @@ def _buildpack_descriptor_impl(ctx):
                 prefix = _pretty_prefix(ctx.attr.prefix),
                 name = ctx.attr.bp_name.replace("_", " ").title(),
             ),
+            "${SBOM_FORMATS}": _sbom_formats(ctx.attr.sbom_formats),
         },
         template = ctx.file._template,
     )
@@ -90,6 +93,7 @@ _buildpack_descriptor = rule(
         "version": attr.string(mandatory = True),
         "bp_name": attr.string(mandatory = True),
         "prefix": attr.string(mandatory = True),
+        "sbom_formats": attr.string_list(),
         "output": attr.output(mandatory = True),
         "_template": attr.label(
             default = ":buildpack.toml.template",
@@ -114,6 +118,16 @@ def _pretty_prefix(prefix):
         return "C++"
     return prefix.title()
 
+def _sbom_formats(formats):
+    """Helper function to render the sbom-formats key of a buildpack descriptor.
+
+    Args:
+      formats: list of SBOM media types, may be empty.
+    """
+    if not formats:
+        return ""
+    return "sbom-formats = [{}]".format(", ".join(['"{}"'.format(f) for f in formats]))
+
 def builder(name, image, descriptor = "builder.toml", buildpacks = None, groups = None, visibility = None):
     """Macro to create a set of targets for a builder with specified buildpacks.
 
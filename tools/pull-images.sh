@@ -18,14 +18,21 @@
 # Note: This script is meant to be invoked using Blaze/Bazel.
 #
 # Usage:
-#   ./pull-images <product> <runtime>
+#   ./pull-images <product> <runtime> [<arch>]
 #
-# Pulls or builds stack images required by builders/<product>/<runtime>.
+# Pulls or builds stack images required by builders/<product>/<runtime>. The
+# stack images of architectures other than amd64 are tagged with the
+# architecture as a suffix, e.g. latest-arm64.
 
 readonly product="${1:?product name missing}"
 readonly runtime="${2:?runtime name missing}"
+readonly arch="${3:-amd64}"
 readonly project="gae-runtimes"
-readonly candidate="latest"
+if [[ "${arch}" == "amd64" ]]; then
+  readonly candidate="latest"
+else
+  readonly candidate="latest-${arch}"
+fi
 
 echo "Pulling stack images for ${product}/${runtime}"
 if [[ "${product}" == "gcp" ]]; then